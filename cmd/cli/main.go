@@ -0,0 +1,178 @@
+// cli 运维命令行工具
+//
+// 用法：cli <命令> [参数]
+//
+//	bench    向目标实例发送价格、交易量和BSC查询的混合压测请求，输出各类请求的延迟分位数
+//	restore  解密 state_backup 任务生成的备份文件，恢复到配置文件中的Redis
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"crypto-info/internal/bench"
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+)
+
+// commands 子命令
+var commands = map[string]func(args []string) error{
+	"bench":   runBench,
+	"restore": runRestore,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := command(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// usage 输出用法
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: cli <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	fmt.Fprintln(os.Stderr, "  bench    Generate synthetic price/volume/BSC load against an instance and report latency percentiles")
+	fmt.Fprintln(os.Stderr, "  restore  Decrypt a state backup and restore it into the configured Redis")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Run 'cli <command> -h' for command flags.")
+}
+
+// headerFlags 可重复的请求头参数，格式为 Name: Value
+type headerFlags http.Header
+
+// String 实现flag.Value接口
+func (h headerFlags) String() string {
+	parts := make([]string, 0, len(h))
+	for name, values := range h {
+		parts = append(parts, name+": "+strings.Join(values, ","))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Set 实现flag.Value接口
+func (h headerFlags) Set(value string) error {
+	name, v, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid header %q, expected Name: Value", value)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(v))
+	return nil
+}
+
+// runBench 运行压测，Ctrl+C时提前结束并输出已完成请求的统计
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	target := fs.String("target", "http://localhost:8080", "目标实例地址")
+	duration := fs.Duration("duration", 30*time.Second, "压测时长")
+	rate := fs.Float64("rate", 50, "每秒请求数")
+	concurrency := fs.Int("concurrency", 64, "最大并发请求数，达到上限时丢弃新请求")
+	timeout := fs.Duration("timeout", 10*time.Second, "单个请求超时")
+	mix := fs.String("mix", "price=6,volume=3,bsc=1", "各场景的请求比例")
+	symbols := fs.String("symbols", "BTC,ETH", "随机使用的币种，逗号分隔")
+	jsonOutput := fs.Bool("json", false, "以JSON输出报告")
+	header := headerFlags{}
+	fs.Var(header, "H", "附加的请求头，如 -H 'X-Tenant-ID: bench'，可重复")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	weights, err := bench.ParseMix(*mix)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Benchmarking %s for %s at %.1f req/s...\n", *target, *duration, *rate)
+	report, err := bench.Run(ctx, bench.Options{
+		Target:      *target,
+		Duration:    *duration,
+		Rate:        *rate,
+		Concurrency: *concurrency,
+		Timeout:     *timeout,
+		Mix:         weights,
+		Symbols:     strings.Split(*symbols, ","),
+		Header:      http.Header(header),
+	})
+	if err != nil {
+		return err
+	}
+
+	if *jsonOutput {
+		return report.WriteJSON(os.Stdout)
+	}
+	return report.WriteText(os.Stdout)
+}
+
+// runRestore 使用配置中的backup.key解密备份文件，写入配置中的Redis(按当前的key前缀)和MySQL，输出恢复结果
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	configPath := fs.String("config", "", "配置文件路径，备份密钥和Redis、MySQL连接取自该配置")
+	file := fs.String("file", "", "备份文件路径，本地不存在时作为名称从backup.storage配置的存储下载")
+	overwrite := fs.Bool("overwrite", false, "覆盖Redis中已存在的key，默认跳过")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.Log.Output = "stderr"
+	logger.Init(&cfg.Log)
+
+	redisClient, err := database.NewRedisClient(&cfg.Database.Redis, cfg.CacheKeyPrefix())
+	if err != nil {
+		return fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	defer redisClient.Close()
+
+	// 备份中的MySQL表(如notifier.rules.storage为mysql时的告警规则)写入配置中的MySQL
+	var mysqlClient database.MySQLClient
+	if cfg.Database.MySQL.Enabled {
+		mysqlClient, err = database.NewMySQLClient(&cfg.Database.MySQL)
+		if err != nil {
+			return fmt.Errorf("failed to connect to MySQL: %w", err)
+		}
+		defer mysqlClient.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := service.NewBackupService(redisClient, mysqlClient, cfg).RestoreBackup(ctx, *file, *overwrite)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
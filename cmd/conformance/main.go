@@ -0,0 +1,123 @@
+// conformance 对内置行情数据源运行符合性检查，以录制文件模拟上游，输出JSON报告，任一检查失败时以非零状态退出
+//
+// 新增数据源时在targets中注册，并在录制目录下提交该提供方的录制文件(见 external_api.fixtures 的record模式)。
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/binance"
+	"crypto-info/internal/pkg/coinbase"
+	"crypto-info/internal/pkg/huobi"
+	"crypto-info/internal/pkg/kraken"
+	"crypto-info/internal/pkg/okx"
+	"crypto-info/internal/pkg/provider"
+	"crypto-info/internal/pkg/provider/providertest"
+)
+
+var (
+	fixtureDir  = flag.String("fixtures", "testdata/fixtures", "录制文件目录")
+	only        = flag.String("provider", "", "只检查指定的数据源，为空时检查全部")
+	symbols     = flag.String("symbols", "BTC,ETH", "已录制的币种，逗号分隔")
+	unsupported = flag.String("unsupported", "FOO", "已录制的不受支持币种")
+	days        = flag.Int("days", 7, "已录制的交易量天数")
+	timeout     = flag.Duration("timeout", 200*time.Millisecond, "截止时间检查使用的超时时间")
+)
+
+// clientTimeout 数据源客户端的请求超时，需大于截止时间检查的超时，以验证ctx的截止时间被遵守
+const clientTimeout = 5 * time.Second
+
+// targets 内置数据源，客户端不重试以便错误响应检查及时完成
+var targets = []providertest.Target{
+	{
+		Name: "binance",
+		NewPrice: func(baseURL string) provider.PriceProvider {
+			return binance.NewProvider(binance.NewClient(apiConfig(baseURL)))
+		},
+		NewVolume: func(baseURL string) provider.VolumeProvider {
+			return binance.NewProvider(binance.NewClient(apiConfig(baseURL)))
+		},
+	},
+	{
+		Name: "huobi",
+		NewPrice: func(baseURL string) provider.PriceProvider {
+			return huobi.NewProvider(huobi.NewClient(apiConfig(baseURL)))
+		},
+		NewVolume: func(baseURL string) provider.VolumeProvider {
+			return huobi.NewProvider(huobi.NewClient(apiConfig(baseURL)))
+		},
+	},
+	{
+		Name: "okx",
+		NewPrice: func(baseURL string) provider.PriceProvider {
+			return okx.NewProvider(okx.NewClient(apiConfig(baseURL)))
+		},
+		NewVolume: func(baseURL string) provider.VolumeProvider {
+			return okx.NewProvider(okx.NewClient(apiConfig(baseURL)))
+		},
+	},
+	{
+		Name: "coinbase",
+		NewPrice: func(baseURL string) provider.PriceProvider {
+			return coinbase.NewProvider(coinbase.NewClient(apiConfig(baseURL)))
+		},
+		NewVolume: func(baseURL string) provider.VolumeProvider {
+			return coinbase.NewProvider(coinbase.NewClient(apiConfig(baseURL)))
+		},
+	},
+	{
+		Name: "kraken",
+		NewPrice: func(baseURL string) provider.PriceProvider {
+			return kraken.NewProvider(kraken.NewClient(apiConfig(baseURL)))
+		},
+		NewVolume: func(baseURL string) provider.VolumeProvider {
+			return kraken.NewProvider(kraken.NewClient(apiConfig(baseURL)))
+		},
+	},
+}
+
+func main() {
+	flag.Parse()
+
+	suite := providertest.Suite{
+		FixtureDir:  *fixtureDir,
+		Symbols:     strings.Split(*symbols, ","),
+		Unsupported: *unsupported,
+		Days:        *days,
+		Timeout:     *timeout,
+	}
+
+	passed, found := true, false
+	for _, target := range targets {
+		if *only != "" && target.Name != *only {
+			continue
+		}
+		found = true
+
+		report := providertest.Run(context.Background(), suite, target)
+		if err := report.Write(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+			os.Exit(1)
+		}
+		passed = passed && report.Passed()
+	}
+
+	if !found {
+		fmt.Fprintf(os.Stderr, "Unknown provider: %s\n", *only)
+		os.Exit(2)
+	}
+	if !passed {
+		os.Exit(1)
+	}
+}
+
+// apiConfig 指向模拟上游的数据源配置
+func apiConfig(baseURL string) *config.APIConfig {
+	return &config.APIConfig{BaseURL: baseURL, Timeout: clientTimeout, Proxy: "direct"}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"crypto-info/internal/pkg/provider/providertest"
+)
+
+// TestBuiltinProviders 内置数据源以仓库中的录制文件通过全部符合性检查
+func TestBuiltinProviders(t *testing.T) {
+	suite := providertest.Suite{
+		FixtureDir:  "../../testdata/fixtures",
+		Symbols:     []string{"BTC", "ETH"},
+		Unsupported: "FOO",
+		Days:        7,
+	}
+
+	for _, target := range targets {
+		t.Run(target.Name, func(t *testing.T) {
+			report := providertest.Run(context.Background(), suite, target)
+			for _, check := range report.Checks {
+				switch check.Status {
+				case providertest.StatusFail:
+					t.Errorf("%s failed: %s", check.Name, check.Error)
+				case providertest.StatusSkip:
+					t.Errorf("%s skipped, built-in providers implement both price and volume", check.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"crypto-info/internal/bootstrap"
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/server"
+)
+
+func main() {
+	// 初始化配置
+	cfg, err := config.Load("")
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 初始化日志
+	logger.Init(&cfg.Log)
+	appLogger := logger.GetLogger()
+
+	// 创建依赖容器，Redis和MySQL连接失败时继续运行
+	container, err := bootstrap.New(cfg, appLogger)
+	if err != nil {
+		appLogger.Fatalf("Failed to create application container: %v", err)
+	}
+
+	// 启动后台任务
+	container.StartWorkers(context.Background())
+
+	// 创建Hertz服务器
+	hertzServer := server.NewHertzServer(container)
+
+	// 启动服务器
+	go func() {
+		if err := hertzServer.Start(); err != nil {
+			appLogger.Fatalf("Failed to start Hertz server: %v", err)
+		}
+	}()
+
+	appLogger.Info("Crypto Info Service (Hertz) started successfully")
+	serverInfos := []bootstrap.ServerInfo{{Name: "hertz", Addr: cfg.GetHTTPAddr()}}
+
+	// 启动探针与指标服务，/metrics(请求限流、调用预算、熔断等指标)只在该端口输出
+	var sidecarServer *server.SidecarServer
+	if cfg.Monitoring.Metrics.Port <= 0 || cfg.Monitoring.Metrics.Port > 65535 {
+		appLogger.Warnf("Sidecar server disabled: invalid monitoring.metrics.port %d", cfg.Monitoring.Metrics.Port)
+	} else {
+		sidecarServer = server.NewSidecarServer(container, nil)
+		serverInfos = append(serverInfos, bootstrap.ServerInfo{Name: "sidecar", Addr: cfg.GetMetricsAddr()})
+		go func() {
+			if err := sidecarServer.Start(); err != nil {
+				appLogger.Errorf("Sidecar server error: %v", err)
+			}
+		}()
+	}
+	container.LogStartupSummary(serverInfos, bootstrap.DependencyDisabled)
+
+	// 等待中断信号
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	appLogger.Info("Shutting down server...")
+
+	// 优雅关闭
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := hertzServer.Shutdown(ctx); err != nil {
+		appLogger.Errorf("Server forced to shutdown: %v", err)
+	}
+	if sidecarServer != nil {
+		if err := sidecarServer.Shutdown(ctx); err != nil {
+			appLogger.Errorf("Sidecar server forced to shutdown: %v", err)
+		}
+	}
+
+	// 停止后台任务并关闭数据库连接
+	container.Close()
+
+	appLogger.Info("Server exited")
+}
\ No newline at end of file
@@ -10,10 +10,11 @@ import (
 	"syscall"
 	"time"
 
+	"crypto-info/internal/bootstrap"
 	"crypto-info/internal/config"
-	"crypto-info/internal/pkg/database"
 	"crypto-info/internal/pkg/logger"
 	"crypto-info/internal/pkg/mq"
+	"crypto-info/internal/selftest"
 	"crypto-info/internal/server"
 	"crypto-info/internal/service"
 )
@@ -25,7 +26,9 @@ func main() {
 		enableHertz   = flag.Bool("hertz", false, "Enable Hertz server")
 		enableGRPC    = flag.Bool("grpc", false, "Enable gRPC server (Kitex)")
 		enableRocketMQ = flag.Bool("mq", false, "Enable RocketMQ message service")
-		configPath    = flag.String("config", "configs/config.yaml", "Config file path")
+		selfTest      = flag.Bool("selftest", false, "Run startup self-test, print a JSON report and exit non-zero on failure")
+		enableSidecar = flag.Bool("sidecar", true, "Enable HTTP /healthz and /metrics listener on monitoring.metrics.port")
+		configPath    = flag.String("config", "", "Config file path, searched in ./configs when empty")
 	)
 	flag.Parse()
 
@@ -35,25 +38,46 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// 自检模式下日志输出到stderr，保证stdout只有JSON报告
+	if *selfTest {
+		cfg.Log.Output = "stderr"
+	}
+
 	// 初始化日志
 	logger.Init(&cfg.Log)
 	appLogger := logger.GetLogger()
 
-	// 初始化Redis客户端
-	var redisClient database.RedisClient
-	redisClient, err = database.NewRedisClient(&cfg.Database.Redis)
+	// 运行自检后退出
+	if *selfTest {
+		report := selftest.Run(context.Background(), cfg)
+		if err := report.Write(os.Stdout); err != nil {
+			appLogger.Errorf("Failed to write self-test report: %v", err)
+		}
+		if !report.Passed() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 创建依赖容器，所有服务器共享同一组服务实例、数据库连接和后台任务
+	container, err := bootstrap.New(cfg, appLogger)
 	if err != nil {
-		appLogger.Warnf("Failed to connect to Redis: %v, continuing without cache", err)
-		redisClient = nil
+		appLogger.Fatalf("Failed to create application container: %v", err)
 	}
 
+	// 启动后台任务，只启动一次，gRPC-only部署同样运行
+	container.StartWorkers(context.Background())
+
 	var wg sync.WaitGroup
 	var servers []interface{ Shutdown(context.Context) error }
+	var serverInfos []bootstrap.ServerInfo
 
 	// 初始化RocketMQ客户端
 	var mqClient *mq.RocketMQClient
 	var messageService *service.MessageService
+	mqStatus := bootstrap.DependencyDisabled
 	if *enableRocketMQ && cfg.RocketMQ.Enabled {
+		mqStatus = bootstrap.DependencyUnavailable
 		// 获取logrus.Logger实例
 		logrusLogger := logger.GetLogrusLogger()
 		mqClient, err = mq.NewRocketMQClient(&cfg.RocketMQ, logrusLogger)
@@ -67,10 +91,15 @@ func main() {
 				mqClient = nil
 			} else {
 				// 初始化消息服务
-				messageService = service.NewMessageService(mqClient, logrusLogger)
+				messageService = service.NewMessageService(mqClient, logrusLogger, container.Services.Webhooks)
 				if err := messageService.Start(); err != nil {
 					appLogger.Warnf("Failed to start message service: %v", err)
 				} else {
+					// 条件告警触发时发布价格告警消息
+					container.Services.AlertRules.SetPublisher(messageService)
+					// 使用统计写入时发布使用事件
+					container.Services.Analytics.SetPublisher(messageService)
+					mqStatus = bootstrap.DependencyConnected
 					appLogger.Info("RocketMQ message service started successfully")
 				}
 			}
@@ -79,11 +108,9 @@ func main() {
 
 	// 启动HTTP服务器 (Gin)
 	if *enableHTTP {
-		httpServer, err := server.NewHTTPServer(cfg, redisClient)
-		if err != nil {
-			appLogger.Fatalf("Failed to create HTTP server: %v", err)
-		}
+		httpServer := server.NewHTTPServer(container)
 		servers = append(servers, httpServer)
+		serverInfos = append(serverInfos, bootstrap.ServerInfo{Name: "http", Addr: cfg.GetHTTPAddr()})
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -98,8 +125,9 @@ func main() {
 	if *enableHertz {
 		// 修改端口避免冲突
 		cfg.Server.HTTP.Port = 8081
-		hertzServer := server.NewHertzServer(cfg, appLogger, redisClient)
+		hertzServer := server.NewHertzServer(container)
 		servers = append(servers, hertzServer)
+		serverInfos = append(serverInfos, bootstrap.ServerInfo{Name: "hertz", Addr: cfg.GetHTTPAddr()})
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -111,9 +139,11 @@ func main() {
 	}
 
 	// 启动gRPC服务器 (Kitex)
+	var grpcServer *server.GRPCServer
 	if *enableGRPC {
-		grpcServer := server.NewGRPCServer(cfg, appLogger, redisClient)
+		grpcServer = server.NewGRPCServer(container)
 		servers = append(servers, grpcServer)
+		serverInfos = append(serverInfos, bootstrap.ServerInfo{Name: "grpc", Addr: cfg.GetGRPCAddr()})
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -128,7 +158,27 @@ func main() {
 		appLogger.Fatal("No servers enabled. Use -http, -hertz, or -grpc flags.")
 	}
 
+	// 启动探针与指标服务，gRPC-only部署时没有HTTP服务器，探针和Prometheus依赖该端口
+	if *enableSidecar {
+		if cfg.Monitoring.Metrics.Port <= 0 || cfg.Monitoring.Metrics.Port > 65535 {
+			appLogger.Warnf("Sidecar server disabled: invalid monitoring.metrics.port %d", cfg.Monitoring.Metrics.Port)
+		} else {
+			sidecarServer := server.NewSidecarServer(container, grpcServer)
+			servers = append(servers, sidecarServer)
+			serverInfos = append(serverInfos, bootstrap.ServerInfo{Name: "sidecar", Addr: cfg.GetMetricsAddr()})
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := sidecarServer.Start(); err != nil {
+					appLogger.Errorf("Sidecar server error: %v", err)
+				}
+			}()
+			appLogger.Infof("Sidecar server started on port %d", cfg.Monitoring.Metrics.Port)
+		}
+	}
+
 	appLogger.Info("Crypto Info Service started successfully")
+	container.LogStartupSummary(serverInfos, mqStatus)
 
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
@@ -174,4 +224,7 @@ func main() {
 	case <-ctx.Done():
 		appLogger.Warn("Shutdown timeout, forcing exit")
 	}
+
+	// 停止后台任务并关闭数据库连接
+	container.Close()
 }
@@ -10,9 +10,11 @@ import (
 	"syscall"
 	"time"
 
+	"crypto-info/internal/bootstrap"
 	"crypto-info/internal/config"
-	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/buildinfo"
 	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/selftest"
 	"crypto-info/internal/server"
 
 	"github.com/gin-gonic/gin"
@@ -27,6 +29,7 @@ var (
 	// 命令行参数
 	configPath = flag.String("config", "", "配置文件路径")
 	version    = flag.Bool("version", false, "显示版本信息")
+	selfTest   = flag.Bool("selftest", false, "运行启动自检(Redis、RocketMQ、BSC节点、上游API)，输出JSON报告，失败时以非零状态退出")
 )
 
 func main() {
@@ -48,10 +51,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	// 自检模式下日志输出到stderr，保证stdout只有JSON报告
+	if *selfTest {
+		cfg.Log.Output = "stderr"
+	}
+
 	// 初始化日志
 	logger.Init(&cfg.Log)
 	log := logger.GetLogger()
 
+	// 运行自检后退出
+	if *selfTest {
+		report := selftest.Run(context.Background(), cfg)
+		if err := report.Write(os.Stdout); err != nil {
+			log.Errorf("Failed to write self-test report: %v", err)
+		}
+		if !report.Passed() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	buildinfo.Set(Version, BuildTime, GitCommit)
 	log.Infof("Starting crypto-info server, version: %s, build time: %s", Version, BuildTime)
 
 	// 设置Gin模式
@@ -61,21 +82,17 @@ func main() {
 		gin.SetMode(gin.DebugMode)
 	}
 
-	// 初始化数据库连接
-	var redisClient database.RedisClient
-	if cfg.Database.Redis.Host != "" {
-		redisClient, err = database.NewRedisClient(&cfg.Database.Redis)
-		if err != nil {
-			log.Errorf("Failed to connect to Redis: %v", err)
-			// Redis连接失败不退出程序，使用内存缓存
-		}
+	// 创建依赖容器，Redis和MySQL连接失败不退出程序
+	container, err := bootstrap.New(cfg, log)
+	if err != nil {
+		log.Fatalf("Failed to create application container: %v", err)
 	}
 
+	// 启动后台任务
+	container.StartWorkers(context.Background())
+
 	// 创建HTTP服务器
-	httpServer, err := server.NewHTTPServer(cfg, redisClient)
-	if err != nil {
-		log.Fatalf("Failed to create HTTP server: %v", err)
-	}
+	httpServer := server.NewHTTPServer(container)
 
 	// 启动HTTP服务器
 	go func() {
@@ -84,6 +101,22 @@ func main() {
 			log.Fatalf("HTTP server failed to start: %v", err)
 		}
 	}()
+	serverInfos := []bootstrap.ServerInfo{{Name: "http", Addr: cfg.GetHTTPAddr()}}
+
+	// 启动探针与指标服务，/metrics(请求限流、调用预算、熔断等指标)只在该端口输出
+	var sidecarServer *server.SidecarServer
+	if cfg.Monitoring.Metrics.Port <= 0 || cfg.Monitoring.Metrics.Port > 65535 {
+		log.Warnf("Sidecar server disabled: invalid monitoring.metrics.port %d", cfg.Monitoring.Metrics.Port)
+	} else {
+		sidecarServer = server.NewSidecarServer(container, nil)
+		serverInfos = append(serverInfos, bootstrap.ServerInfo{Name: "sidecar", Addr: cfg.GetMetricsAddr()})
+		go func() {
+			if err := sidecarServer.Start(); err != nil {
+				log.Errorf("Sidecar server error: %v", err)
+			}
+		}()
+	}
+	container.LogStartupSummary(serverInfos, bootstrap.DependencyDisabled)
 
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
@@ -102,13 +135,14 @@ func main() {
 	} else {
 		log.Info("HTTP server shutdown gracefully")
 	}
-
-	// 关闭数据库连接
-	if redisClient != nil {
-		if err := redisClient.Close(); err != nil {
-			log.Errorf("Failed to close Redis connection: %v", err)
+	if sidecarServer != nil {
+		if err := sidecarServer.Shutdown(ctx); err != nil {
+			log.Errorf("Sidecar server forced to shutdown: %v", err)
 		}
 	}
 
+	// 停止后台任务并关闭数据库连接
+	container.Close()
+
 	log.Info("Server shutdown complete")
 }
\ No newline at end of file
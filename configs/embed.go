@@ -0,0 +1,9 @@
+// Package configs 内置的配置文件，找不到配置文件时作为默认配置，容器中可只通过环境变量配置
+package configs
+
+import "embed"
+
+// Files 内置的基础配置 config.yaml 和各环境配置 {env}.yaml
+//
+//go:embed *.yaml
+var Files embed.FS
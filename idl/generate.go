@@ -0,0 +1,15 @@
+// Package idl 对外发布的RPC接口定义，供其他语言生成客户端
+//
+// 修改IDL后执行 go generate ./idl/... (或 make idl) 重新生成 kitex_gen，需要安装与 kitex_info.yaml 一致的 kitex 版本。
+package idl
+
+import "embed"
+
+//go:generate sh -c "cd .. && kitex -module crypto-info -I idl idl/crypto/v1/crypto_service.proto"
+//go:generate sh -c "cd .. && kitex -module crypto-info -I idl idl/grpc/health/v1/health.proto"
+//go:generate sh -c "cd .. && kitex -module crypto-info -I idl idl/grpc/reflection/v1alpha/reflection.proto"
+
+// Files 内嵌的IDL源文件，路径相对于idl目录，gRPC服务反射据此返回文件描述
+//
+//go:embed crypto/v1/*.proto grpc/health/v1/*.proto grpc/reflection/v1alpha/*.proto
+var Files embed.FS
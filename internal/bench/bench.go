@@ -0,0 +1,409 @@
+// Package bench 压测：按固定速率向目标实例发送价格、交易量和BSC查询的混合请求，统计各类请求的延迟分位数
+//
+// 采用开放模型：请求按速率发出，不等待前一个请求完成；并发达到上限时新请求被丢弃并计入dropped，
+// 此时压测客户端本身已成为瓶颈，应提高并发上限或降低速率。结果用于估算部署规模。
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 默认参数
+const (
+	defaultRate        = 50
+	defaultConcurrency = 64
+	defaultTimeout     = 10 * time.Second
+	defaultDays        = 7
+)
+
+// 场景名称
+const (
+	ScenarioPrice  = "price"
+	ScenarioVolume = "volume"
+	ScenarioBSC    = "bsc"
+)
+
+// DefaultMix 默认请求比例
+var DefaultMix = map[string]int{ScenarioPrice: 6, ScenarioVolume: 3, ScenarioBSC: 1}
+
+// bscPaths BSC场景轮流请求的接口
+var bscPaths = []string{"/api/v1/bsc/status", "/api/v1/bsc/block/latest", "/api/v1/bsc/tvl"}
+
+// Options 压测参数
+type Options struct {
+	Target      string         // 目标实例地址，如 http://localhost:8080
+	Duration    time.Duration  // 压测时长
+	Rate        float64        // 每秒请求数
+	Concurrency int            // 最大并发请求数
+	Timeout     time.Duration  // 单个请求超时
+	Mix         map[string]int // 各场景的请求比例
+	Symbols     []string       // 价格和交易量请求随机使用的币种
+	Header      http.Header    // 附加的请求头，如X-Tenant-ID
+}
+
+// Stats 单个场景的统计
+type Stats struct {
+	Scenario string         `json:"scenario"`
+	Requests int            `json:"requests"`
+	Errors   int            `json:"errors"`   // 网络错误和非2xx响应
+	Statuses map[string]int `json:"statuses"` // 按状态码统计，网络错误计为error
+	P50Ms    float64        `json:"p50_ms"`
+	P90Ms    float64        `json:"p90_ms"`
+	P95Ms    float64        `json:"p95_ms"`
+	P99Ms    float64        `json:"p99_ms"`
+	MaxMs    float64        `json:"max_ms"`
+
+	latencies []time.Duration
+}
+
+// Report 压测报告
+type Report struct {
+	Target      string    `json:"target"`
+	StartedAt   time.Time `json:"started_at"`
+	DurationMs  int64     `json:"duration_ms"`
+	Rate        float64   `json:"rate"`
+	Concurrency int       `json:"concurrency"`
+	Sent        int       `json:"sent"`
+	Dropped     int       `json:"dropped"` // 并发达到上限未发出的请求
+	Throughput  float64   `json:"throughput"`
+	Total       *Stats    `json:"total"`
+	Scenarios   []*Stats  `json:"scenarios"`
+}
+
+// request 一次请求的场景和路径
+type request struct {
+	scenario string
+	path     string
+}
+
+// result 一次请求的结果
+type result struct {
+	scenario string
+	status   string
+	latency  time.Duration
+	failed   bool
+}
+
+// Run 按参数运行压测，ctx取消时提前结束并返回已完成请求的统计
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+
+	picker := newPicker(opts.Mix)
+	client := &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: &http.Transport{MaxIdleConnsPerHost: opts.Concurrency, MaxConnsPerHost: opts.Concurrency},
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	report := &Report{Target: opts.Target, StartedAt: time.Now(), Rate: opts.Rate, Concurrency: opts.Concurrency}
+	results := make(chan result, opts.Concurrency)
+	collected := make(chan map[string]*Stats)
+	go func() {
+		collected <- collect(results)
+	}()
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, opts.Concurrency)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+
+		req := picker.next(rng, opts)
+		select {
+		case slots <- struct{}{}:
+		default:
+			report.Dropped++
+			continue
+		}
+		report.Sent++
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results <- send(client, opts, req)
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+	stats := <-collected
+
+	elapsed := time.Since(report.StartedAt)
+	report.DurationMs = elapsed.Milliseconds()
+	report.Total = &Stats{Scenario: "total", Statuses: map[string]int{}}
+	for _, name := range picker.names {
+		st, ok := stats[name]
+		if !ok {
+			continue
+		}
+		st.summarize()
+		report.Scenarios = append(report.Scenarios, st)
+		report.Total.merge(st)
+	}
+	report.Total.summarize()
+	report.Throughput = float64(report.Total.Requests) / elapsed.Seconds()
+	return report, nil
+}
+
+// normalize 补全默认参数并校验
+func (o *Options) normalize() error {
+	u, err := url.Parse(o.Target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid target %q, expected http(s)://host:port", o.Target)
+	}
+	o.Target = strings.TrimRight(o.Target, "/")
+
+	if o.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+	if o.Rate <= 0 {
+		o.Rate = defaultRate
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultConcurrency
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+	if len(o.Mix) == 0 {
+		o.Mix = DefaultMix
+	}
+	total := 0
+	for name, weight := range o.Mix {
+		switch name {
+		case ScenarioPrice, ScenarioVolume, ScenarioBSC:
+		default:
+			return fmt.Errorf("unknown scenario %q", name)
+		}
+		if weight < 0 {
+			return fmt.Errorf("invalid weight %d for scenario %q", weight, name)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return errors.New("mix must contain at least one positive weight")
+	}
+	if len(o.Symbols) == 0 {
+		o.Symbols = []string{"BTC", "ETH"}
+	}
+	return nil
+}
+
+// ParseMix 解析场景比例，格式为 price=6,volume=3,bsc=1
+func ParseMix(value string) (map[string]int, error) {
+	mix := make(map[string]int)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weight, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mix entry %q, expected name=weight", part)
+		}
+		n, err := strconv.Atoi(weight)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mix weight %q: %w", part, err)
+		}
+		mix[strings.TrimSpace(name)] = n
+	}
+	return mix, nil
+}
+
+// picker 按比例随机选择场景
+type picker struct {
+	names   []string
+	weights []int
+	total   int
+	bscNext int
+}
+
+// newPicker 创建场景选择器，场景按名称排序以保证报告顺序稳定
+func newPicker(mix map[string]int) *picker {
+	p := &picker{}
+	for name := range mix {
+		p.names = append(p.names, name)
+	}
+	sort.Strings(p.names)
+	for _, name := range p.names {
+		p.weights = append(p.weights, mix[name])
+		p.total += mix[name]
+	}
+	return p
+}
+
+// next 选择下一个请求
+func (p *picker) next(rng *rand.Rand, opts Options) request {
+	n := rng.Intn(p.total)
+	scenario := p.names[len(p.names)-1]
+	for i, weight := range p.weights {
+		if n < weight {
+			scenario = p.names[i]
+			break
+		}
+		n -= weight
+	}
+
+	symbol := url.QueryEscape(opts.Symbols[rng.Intn(len(opts.Symbols))])
+	switch scenario {
+	case ScenarioPrice:
+		return request{scenario: scenario, path: "/api/v1/crypto/price?symbol=" + symbol}
+	case ScenarioVolume:
+		return request{scenario: scenario, path: fmt.Sprintf("/api/v1/crypto/volume/analysis?symbol=%s&days=%d", symbol, defaultDays)}
+	default:
+		path := bscPaths[p.bscNext%len(bscPaths)]
+		p.bscNext++
+		return request{scenario: scenario, path: path}
+	}
+}
+
+// send 发送请求并计时，读完响应体以复用连接
+func send(client *http.Client, opts Options, r request) result {
+	res := result{scenario: r.scenario, status: "error", failed: true}
+
+	req, err := http.NewRequest(http.MethodGet, opts.Target+r.path, nil)
+	if err != nil {
+		return res
+	}
+	for name, values := range opts.Header {
+		req.Header[name] = values
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	res.latency = time.Since(start)
+	if err != nil {
+		return res
+	}
+
+	res.status = strconv.Itoa(resp.StatusCode)
+	res.failed = resp.StatusCode < 200 || resp.StatusCode >= 300
+	return res
+}
+
+// collect 按场景汇总请求结果
+func collect(results <-chan result) map[string]*Stats {
+	stats := make(map[string]*Stats)
+	for res := range results {
+		st, ok := stats[res.scenario]
+		if !ok {
+			st = &Stats{Scenario: res.scenario, Statuses: map[string]int{}}
+			stats[res.scenario] = st
+		}
+		st.Requests++
+		st.Statuses[res.status]++
+		if res.failed {
+			st.Errors++
+		}
+		st.latencies = append(st.latencies, res.latency)
+	}
+	return stats
+}
+
+// merge 合并其他场景的统计
+func (s *Stats) merge(other *Stats) {
+	s.Requests += other.Requests
+	s.Errors += other.Errors
+	for status, n := range other.Statuses {
+		s.Statuses[status] += n
+	}
+	s.latencies = append(s.latencies, other.latencies...)
+}
+
+// summarize 计算延迟分位数
+func (s *Stats) summarize() {
+	if len(s.latencies) == 0 {
+		return
+	}
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+	s.P50Ms = s.percentile(0.50)
+	s.P90Ms = s.percentile(0.90)
+	s.P95Ms = s.percentile(0.95)
+	s.P99Ms = s.percentile(0.99)
+	s.MaxMs = milliseconds(s.latencies[len(s.latencies)-1])
+}
+
+// percentile 已排序延迟的分位数(最近秩法)
+func (s *Stats) percentile(p float64) float64 {
+	idx := int(float64(len(s.latencies))*p+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(s.latencies) {
+		idx = len(s.latencies) - 1
+	}
+	return milliseconds(s.latencies[idx])
+}
+
+// milliseconds 转换为保留两位小数的毫秒数
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()/10) / 100
+}
+
+// WriteJSON 以缩进JSON输出报告
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText 以表格输出报告
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Target:      %s\n", r.Target)
+	fmt.Fprintf(&b, "Duration:    %s\n", time.Duration(r.DurationMs)*time.Millisecond)
+	fmt.Fprintf(&b, "Rate:        %.1f req/s (concurrency %d)\n", r.Rate, r.Concurrency)
+	fmt.Fprintf(&b, "Sent:        %d, dropped: %d\n", r.Sent, r.Dropped)
+	fmt.Fprintf(&b, "Throughput:  %.1f req/s\n\n", r.Throughput)
+
+	fmt.Fprintf(&b, "%-8s %9s %8s %9s %9s %9s %9s %9s  %s\n", "SCENARIO", "REQUESTS", "ERRORS", "P50(ms)", "P90(ms)", "P95(ms)", "P99(ms)", "MAX(ms)", "STATUSES")
+	for _, st := range append(r.Scenarios, r.Total) {
+		fmt.Fprintf(&b, "%-8s %9d %8d %9.2f %9.2f %9.2f %9.2f %9.2f  %s\n",
+			st.Scenario, st.Requests, st.Errors, st.P50Ms, st.P90Ms, st.P95Ms, st.P99Ms, st.MaxMs, formatStatuses(st.Statuses))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatStatuses 按状态码排序输出，如 200=95 503=5
+func formatStatuses(statuses map[string]int) string {
+	codes := make([]string, 0, len(statuses))
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = fmt.Sprintf("%s=%d", code, statuses[code])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,153 @@
+// Package bootstrap 组合根，统一创建服务、处理器和后台任务
+//
+// 所有入口(cmd/server、cmd/hertz、cmd/multi)通过 New 获取同一套依赖，HTTP、Hertz和gRPC服务器只负责协议适配，
+// 构造函数签名变化时只需修改 providers.go。
+package bootstrap
+
+import (
+	"context"
+	"sync"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/breaker"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/chaos"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/fixture"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/middleware"
+	"crypto-info/internal/pkg/popularity"
+	"crypto-info/internal/pkg/precision"
+	"crypto-info/internal/pkg/ratelimit"
+	"crypto-info/internal/pkg/session"
+)
+
+// Worker 随进程启动和关闭的后台任务
+type Worker interface {
+	Start(ctx context.Context) error
+	Stop() error
+}
+
+// Container 应用依赖容器，每个进程创建一次，多个服务器共享
+type Container struct {
+	Config         *config.Config
+	Logger         logger.Logger
+	Redis          database.RedisClient // Redis不可用时为空
+	MySQL          database.MySQLClient // 未启用MySQL或连接失败时为空
+	Services       *Services
+	Handlers       *Handlers
+	SessionManager *session.Manager               // 未启用session时为空
+	RateLimiter    *ratelimit.Limiter             // 未启用限流时为空
+	Concurrency    *middleware.ConcurrencyLimiter // 未启用并发限制时为空
+	LoadShedder    *middleware.LoadShedder        // 未启用负载降级时为空
+
+	workers   []Worker
+	startOnce sync.Once
+	stopOnce  sync.Once
+	closeOnce sync.Once
+}
+
+// New 创建依赖容器，按配置连接Redis和MySQL，连接失败时对应客户端为空，由Close关闭
+func New(cfg *config.Config, log logger.Logger) (*Container, error) {
+	redisClient := provideRedisClient(cfg, log)
+	mysqlClient := provideMySQLClient(cfg, log)
+
+	budget.Init(&cfg.ExternalAPI.Budget)
+	breaker.Init(&cfg.ExternalAPI.Breaker)
+	httpclient.ConfigureDNS(&cfg.ExternalAPI.DNS)
+	precision.Init(&cfg.Business.Precision)
+	popularity.Init(&cfg.Cache.Adaptive)
+	chaos.Init(&cfg.Chaos)
+	if injector := chaos.Default(); injector != nil {
+		log.Warnf("Chaos mode enabled, injecting faults into %d targets", len(cfg.Chaos.Faults))
+		if redisClient != nil {
+			redisClient.GetClient().AddHook(chaos.RedisHook(injector))
+		}
+	}
+	fixture.Init(&cfg.ExternalAPI.Fixtures)
+	if recorder := fixture.Default(); recorder != nil {
+		log.Warnf("Upstream API fixtures in %s mode, directory: %s", recorder.Mode(), cfg.ExternalAPI.Fixtures.Dir)
+	}
+
+	sessionManager, err := provideSessionManager(cfg, redisClient, log)
+	if err != nil {
+		closeClients(log, redisClient, mysqlClient)
+		return nil, err
+	}
+
+	rateLimiter, err := provideRateLimiter(cfg)
+	if err != nil {
+		closeClients(log, redisClient, mysqlClient)
+		return nil, err
+	}
+
+	services := provideServices(cfg, redisClient, mysqlClient, log)
+	providePanicReporter(cfg, services)
+
+	workers := provideWorkers(services)
+	if services.BSC != nil && cfg.BSC.Enabled && cfg.BSC.Monitoring.Enabled {
+		workers = append(workers, services.BSC)
+	}
+	if rateLimiter != nil {
+		workers = append(workers, rateLimiter)
+	}
+
+	return &Container{
+		Config:         cfg,
+		Logger:         log,
+		Redis:          redisClient,
+		MySQL:          mysqlClient,
+		Services:       services,
+		Handlers:       provideHandlers(cfg, services, sessionManager),
+		SessionManager: sessionManager,
+		RateLimiter:    rateLimiter,
+		Concurrency:    provideConcurrencyLimiter(cfg),
+		LoadShedder:    provideLoadShedder(cfg, services.Health),
+		workers:        workers,
+	}, nil
+}
+
+// StartWorkers 启动后台任务，由入口在创建服务器前调用一次，与启用了哪些服务器无关；单个任务启动失败不影响服务
+func (c *Container) StartWorkers(ctx context.Context) {
+	c.startOnce.Do(func() {
+		for _, w := range c.workers {
+			if err := w.Start(ctx); err != nil {
+				c.Logger.Errorf("Failed to start background worker: %v", err)
+			}
+		}
+	})
+}
+
+// StopWorkers 停止后台任务，Close会先调用它
+func (c *Container) StopWorkers() {
+	c.stopOnce.Do(func() {
+		for _, w := range c.workers {
+			if err := w.Stop(); err != nil {
+				c.Logger.Errorf("Failed to stop background worker: %v", err)
+			}
+		}
+	})
+}
+
+// Close 停止后台任务并关闭Redis和MySQL连接，由入口在所有服务器关闭后调用
+func (c *Container) Close() {
+	c.closeOnce.Do(func() {
+		c.StopWorkers()
+		closeClients(c.Logger, c.Redis, c.MySQL)
+	})
+}
+
+// closeClients 关闭数据库连接，客户端为空时跳过
+func closeClients(log logger.Logger, redisClient database.RedisClient, mysqlClient database.MySQLClient) {
+	if redisClient != nil {
+		if err := redisClient.Close(); err != nil {
+			log.Errorf("Failed to close Redis connection: %v", err)
+		}
+	}
+	if mysqlClient != nil {
+		if err := mysqlClient.Close(); err != nil {
+			log.Errorf("Failed to close MySQL connection: %v", err)
+		}
+	}
+}
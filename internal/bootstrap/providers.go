@@ -0,0 +1,272 @@
+package bootstrap
+
+import (
+	"fmt"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/handler"
+	"crypto-info/internal/pkg/breaker"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/middleware"
+	"crypto-info/internal/pkg/panics"
+	"crypto-info/internal/pkg/ratelimit"
+	"crypto-info/internal/pkg/session"
+	"crypto-info/internal/service"
+)
+
+// Services 服务层依赖，所有入口共享同一组实例
+type Services struct {
+	Token       service.TokenService
+	BSC         service.BSCService
+	History     service.HistoryService
+	Price       service.PriceService
+	Volume      service.VolumeService
+	Kline       service.KlineService
+	MarketData  service.MarketDataService
+	Portfolio   service.PortfolioService
+	Ingest      service.IngestService
+	TokenSync   service.TokenSyncService
+	TokenSafety service.TokenSafetyService
+	Bridge      service.BridgeService
+	Farm        service.FarmService
+	Notifier    service.Notifier
+	Webhooks    service.WebhookService
+	TVL         service.TVLService
+	Liquidity   service.LiquidityService
+	Discovery   service.PairDiscoveryService
+	Events      service.EventFilterService
+	Snapshot    service.SnapshotService
+	Name        service.NameService
+	Activity    service.ActivityService
+	Health      service.HealthService
+	Stream      service.StreamService
+	Compare     service.CompareService
+	Scheduled   service.ScheduledAlertService
+	AlertRules  service.AlertRuleService
+	Version     service.VersionService
+	SLA         service.SLAService
+	Jobs        service.JobService
+	Backup      service.BackupService
+	Analytics   service.AnalyticsService
+}
+
+// Handlers HTTP处理器，Gin和Hertz路由共用
+type Handlers struct {
+	Price     *handler.PriceHandler
+	History   *handler.HistoryHandler
+	Volume    *handler.VolumeHandler
+	Kline     *handler.KlineHandler
+	Market    *handler.MarketHandler
+	Compare   *handler.CompareHandler
+	Portfolio *handler.PortfolioHandler
+	Token     *handler.TokenHandler
+	Bridge    *handler.BridgeHandler
+	Farm      *handler.FarmHandler
+	TVL       *handler.TVLHandler
+	Liquidity *handler.LiquidityHandler
+	Events    *handler.EventFilterHandler
+	Snapshot  *handler.SnapshotHandler
+	Activity  *handler.ActivityHandler
+	Name      *handler.NameHandler
+	Alert     *handler.AlertHandler
+	Webhook   *handler.WebhookHandler
+	Health    *handler.HealthHandler
+	Budget    *handler.BudgetHandler
+	Config    *handler.ConfigHandler
+	Jobs      *handler.JobHandler
+	Backup    *handler.BackupHandler
+	Analytics *handler.AnalyticsHandler
+	Version   *handler.VersionHandler
+	Status    *handler.StatusHandler
+	Stream    *handler.StreamHandler
+	BSC       *handler.BSCHandler     // BSC服务创建失败时为空
+	Session   *handler.SessionHandler // 未启用session时为空
+}
+
+// provideServices 按依赖顺序创建服务层，新增服务或修改构造函数只需改这里
+//
+// BSC服务创建失败时只记录错误，依赖它的服务按原有逻辑降级运行。
+func provideServices(cfg *config.Config, redisClient database.RedisClient, mysqlClient database.MySQLClient, log logger.Logger) *Services {
+	s := &Services{}
+
+	s.Stream = service.NewStreamService(redisClient, cfg)
+	s.Token = service.NewTokenService(redisClient, cfg)
+	s.Webhooks = service.NewWebhookService(redisClient, cfg)
+	s.Notifier = service.NewNotifier(redisClient, cfg, s.Stream, s.Webhooks)
+	bscService, err := service.NewBSCService(cfg, redisClient, s.Token, s.Notifier)
+	if err != nil {
+		log.Errorf("Failed to create BSC service: %v", err)
+	}
+	s.BSC = bscService
+	s.History = service.NewHistoryService(redisClient, mysqlClient, cfg)
+	s.Price = service.NewPriceService(redisClient, cfg, s.BSC, s.History, s.Stream)
+	s.Volume = service.NewVolumeService(redisClient, cfg)
+	s.Kline = service.NewKlineService(redisClient, cfg)
+	s.MarketData = service.NewMarketDataService(redisClient, cfg)
+	s.Compare = service.NewCompareService(cfg, s.Price, s.Volume, s.History)
+	s.Portfolio = service.NewPortfolioService(redisClient, cfg, s.Price, s.History)
+	s.Ingest = service.NewIngestService(redisClient, cfg, s.Token, s.Portfolio)
+	s.TokenSync = service.NewTokenSyncService(redisClient, cfg, s.Token, s.BSC)
+	s.TokenSafety = service.NewTokenSafetyService(redisClient, cfg, s.Token, s.BSC)
+	s.Bridge = service.NewBridgeService(redisClient, cfg, s.Price)
+	s.Farm = service.NewFarmService(redisClient, cfg, s.BSC, s.Price)
+	s.Scheduled = service.NewScheduledAlertService(redisClient, cfg, s.Price, s.Notifier)
+	s.AlertRules = service.NewAlertRuleService(redisClient, mysqlClient, cfg, s.Price, s.Volume, s.History, s.Notifier)
+	s.TVL = service.NewTVLService(redisClient, cfg, s.BSC, s.Price, s.Notifier)
+	s.Discovery = service.NewPairDiscoveryService(redisClient, cfg, s.BSC)
+	s.Liquidity = service.NewLiquidityService(redisClient, cfg, s.BSC, s.Price, s.Notifier, s.Discovery)
+	s.Events = service.NewEventFilterService(redisClient, cfg, s.BSC, s.Stream, s.Webhooks)
+	s.Snapshot = service.NewSnapshotService(redisClient, cfg, s.BSC)
+	s.Name = service.NewNameService(redisClient, cfg, s.BSC)
+	s.Activity = service.NewActivityService(redisClient, cfg, s.Token, s.Name)
+	s.Health = service.NewHealthService(redisClient, mysqlClient, cfg, s.BSC)
+	s.Version = service.NewVersionService(cfg)
+	s.SLA = service.NewSLAService(redisClient, cfg, s.Health)
+	s.Backup = service.NewBackupService(redisClient, mysqlClient, cfg)
+	s.Analytics = service.NewAnalyticsService(redisClient, cfg)
+	s.Jobs = service.NewJobService(redisClient, cfg, s.Price, s.History, s.Notifier, s.Backup)
+
+	return s
+}
+
+// provideRedisClient 连接Redis，未配置地址或连接失败时返回空，缓存等功能退回内存实现
+func provideRedisClient(cfg *config.Config, log logger.Logger) database.RedisClient {
+	if cfg.Database.Redis.Host == "" {
+		return nil
+	}
+	client, err := database.NewRedisClient(&cfg.Database.Redis, cfg.CacheKeyPrefix())
+	if err != nil {
+		log.Warnf("Failed to connect to Redis: %v, continuing without cache", err)
+		return nil
+	}
+	return client
+}
+
+// provideMySQLClient 连接MySQL，未启用或连接失败时返回空，依赖MySQL的功能不可用
+func provideMySQLClient(cfg *config.Config, log logger.Logger) database.MySQLClient {
+	if !cfg.Database.MySQL.Enabled {
+		return nil
+	}
+	client, err := database.NewMySQLClient(&cfg.Database.MySQL)
+	if err != nil {
+		log.Warnf("Failed to connect to MySQL: %v, continuing without persistence", err)
+		return nil
+	}
+	return client
+}
+
+// provideSessionManager 创建session管理器，未启用session时返回nil
+func provideSessionManager(cfg *config.Config, redisClient database.RedisClient, log logger.Logger) (*session.Manager, error) {
+	if !cfg.Security.Session.Enabled {
+		return nil, nil
+	}
+
+	var (
+		manager *session.Manager
+		err     error
+	)
+	if redisClient != nil {
+		manager, err = session.NewManager(&cfg.Security.Session, redisClient.GetClient(), cfg.CacheKeyPrefix(), log)
+	} else {
+		manager, err = session.NewManager(&cfg.Security.Session, nil, cfg.CacheKeyPrefix(), log)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session manager: %w", err)
+	}
+	log.Info("Session manager initialized")
+	return manager, nil
+}
+
+// provideRateLimiter 创建按客户端IP的请求限流器，未启用限流时返回空
+func provideRateLimiter(cfg *config.Config) (*ratelimit.Limiter, error) {
+	if !cfg.RateLimit.Enabled {
+		return nil, nil
+	}
+	limiter, err := ratelimit.New(&cfg.RateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate limiter: %w", err)
+	}
+	return limiter, nil
+}
+
+// provideConcurrencyLimiter 创建HTTP并发限制器，多个HTTP服务器共享名额，未启用时返回空
+func provideConcurrencyLimiter(cfg *config.Config) *middleware.ConcurrencyLimiter {
+	if !cfg.Server.HTTP.Concurrency.Enabled {
+		return nil
+	}
+	return middleware.NewConcurrencyLimiter(&cfg.Server.HTTP.Concurrency)
+}
+
+// provideLoadShedder 创建负载降级器，依赖状态取自健康探测服务的最近一次结果，未启用时返回空
+func provideLoadShedder(cfg *config.Config, health service.HealthService) *middleware.LoadShedder {
+	if !cfg.Server.HTTP.LoadShedding.Enabled {
+		return nil
+	}
+	return middleware.NewLoadShedder(&cfg.Server.HTTP.LoadShedding, func() map[string]string {
+		snapshot := health.Snapshot()
+		if snapshot == nil {
+			return nil
+		}
+		status := make(map[string]string, len(snapshot.Dependencies))
+		for _, dep := range snapshot.Dependencies {
+			status[dep.Name] = dep.Status
+		}
+		return status
+	})
+}
+
+// providePanicReporter 将Recovery中间件和后台任务捕获的panic转为告警，未启用时只记录日志
+func providePanicReporter(cfg *config.Config, s *Services) {
+	if !cfg.Notifier.Panics.Enabled {
+		panics.SetHandler(nil)
+		return
+	}
+	panics.SetHandler(service.NewPanicReporter(cfg, s.Notifier))
+}
+
+// provideHandlers 创建HTTP处理器
+func provideHandlers(cfg *config.Config, s *Services, sessionManager *session.Manager) *Handlers {
+	h := &Handlers{
+		Price:     handler.NewPriceHandler(s.Price),
+		History:   handler.NewHistoryHandler(s.History),
+		Volume:    handler.NewVolumeHandler(s.Volume),
+		Kline:     handler.NewKlineHandler(s.Kline),
+		Market:    handler.NewMarketHandler(s.MarketData),
+		Compare:   handler.NewCompareHandler(s.Compare),
+		Portfolio: handler.NewPortfolioHandler(s.Portfolio),
+		Token:     handler.NewTokenHandler(s.Token, s.Ingest, s.TokenSync, s.TokenSafety),
+		Bridge:    handler.NewBridgeHandler(s.Bridge),
+		Farm:      handler.NewFarmHandler(s.Farm),
+		TVL:       handler.NewTVLHandler(s.TVL),
+		Liquidity: handler.NewLiquidityHandler(s.Liquidity),
+		Events:    handler.NewEventFilterHandler(s.Events),
+		Snapshot:  handler.NewSnapshotHandler(s.Snapshot),
+		Activity:  handler.NewActivityHandler(s.Activity),
+		Name:      handler.NewNameHandler(s.Name),
+		Alert:     handler.NewAlertHandler(s.Notifier, s.Scheduled, s.AlertRules),
+		Webhook:   handler.NewWebhookHandler(s.Webhooks),
+		Health:    handler.NewHealthHandler(s.Health),
+		Budget:    handler.NewBudgetHandler(budget.Default()),
+		Config:    handler.NewConfigHandler(cfg),
+		Jobs:      handler.NewJobHandler(s.Jobs),
+		Backup:    handler.NewBackupHandler(s.Backup),
+		Analytics: handler.NewAnalyticsHandler(s.Analytics),
+		Version:   handler.NewVersionHandler(s.Version),
+		Status:    handler.NewStatusHandler(s.SLA, breaker.Default()),
+		Stream:    handler.NewStreamHandler(s.Stream, &cfg.Stream),
+	}
+	if s.BSC != nil {
+		h.BSC = handler.NewBSCHandler(s.BSC)
+	}
+	if sessionManager != nil {
+		h.Session = handler.NewSessionHandler(sessionManager)
+	}
+	return h
+}
+
+// provideWorkers 随进程启动和关闭的后台任务
+func provideWorkers(s *Services) []Worker {
+	return []Worker{s.Stream, s.Ingest, s.TokenSync, s.Bridge, s.TVL, s.Discovery, s.Liquidity, s.Events, s.Snapshot, s.Scheduled, s.AlertRules, s.Health, s.SLA, s.Jobs, s.Analytics}
+}
@@ -0,0 +1,176 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/buildinfo"
+)
+
+// 外部依赖状态
+const (
+	DependencyConnected   = "connected"   // 启动时连接成功
+	DependencyEnabled     = "enabled"     // 已启用，启动时不检查连接
+	DependencyUnavailable = "unavailable" // 已启用但连接失败，服务降级运行
+	DependencyDisabled    = "disabled"    // 未配置或未启用
+)
+
+// startupSummaryEvent 启动摘要日志的event字段，便于日志管道筛选
+const startupSummaryEvent = "startup_summary"
+
+// ServerInfo 已启动的服务器和监听地址
+type ServerInfo struct {
+	Name string `json:"name"` // http、hertz、grpc、sidecar
+	Addr string `json:"addr"`
+}
+
+// DependencyInfo 外部依赖的状态和地址，地址不含凭据
+type DependencyInfo struct {
+	Status string `json:"status"`
+	Addr   string `json:"addr,omitempty"`
+}
+
+// ProviderInfo 行情数据源
+type ProviderInfo struct {
+	PriceSource     string   `json:"price_source"`
+	PriceFallbacks  []string `json:"price_fallbacks"`
+	USDPriceSources []string `json:"usd_price_sources"`
+	KlineSources    []string `json:"kline_sources"`
+	MockData        bool     `json:"mock_data"` // 所有数据源失败时是否回退到模拟数据
+	Fixtures        string   `json:"fixtures,omitempty"`
+}
+
+// StartupSummary 启动摘要，进程启动完成后作为一条日志输出，用于核对部署结果
+type StartupSummary struct {
+	App           string                    `json:"app"`
+	Env           string                    `json:"env"`
+	Version       string                    `json:"version"`
+	BuildTime     string                    `json:"build_time"`
+	GitCommit     string                    `json:"git_commit"`
+	GoVersion     string                    `json:"go_version"`
+	ConfigSources []config.Source           `json:"config_sources"`
+	Servers       []ServerInfo              `json:"servers"`
+	Providers     ProviderInfo              `json:"providers"`
+	Dependencies  map[string]DependencyInfo `json:"dependencies"`
+	Features      map[string]bool           `json:"features"`
+}
+
+// StartupSummary 汇总已启动的服务器、数据源、依赖状态和功能开关
+//
+// RocketMQ由入口程序按需启动，状态由调用方传入。
+func (c *Container) StartupSummary(servers []ServerInfo, rocketMQ string) *StartupSummary {
+	cfg := c.Config
+	build := buildinfo.Get()
+
+	summary := &StartupSummary{
+		App:       cfg.App.Name,
+		Env:       cfg.App.Env,
+		Version:   build.Version,
+		BuildTime: build.BuildTime,
+		GitCommit: build.GitCommit,
+		GoVersion: build.GoVersion,
+		Servers:   servers,
+		Providers: ProviderInfo{
+			PriceSource:     cfg.Business.PriceSource,
+			PriceFallbacks:  cfg.Business.PriceFallbacks,
+			USDPriceSources: cfg.Business.USDPriceSources,
+			KlineSources:    cfg.Business.KlineSources,
+			MockData:        cfg.Business.MockDataEnabled,
+			Fixtures:        cfg.ExternalAPI.Fixtures.Mode,
+		},
+		Dependencies: map[string]DependencyInfo{
+			"redis":    c.redisStatus(),
+			"mysql":    c.mysqlStatus(),
+			"rocketmq": {Status: rocketMQ, Addr: strings.Join(cfg.RocketMQ.NameServers, ",")},
+			"bsc":      c.bscStatus(),
+		},
+		Features: map[string]bool{
+			"rate_limit":      c.RateLimiter != nil,
+			"session":         c.SessionManager != nil,
+			"concurrency":     c.Concurrency != nil,
+			"load_shedding":   c.LoadShedder != nil,
+			"response_cache":  cfg.Server.HTTP.ResponseCache.Enabled,
+			"stream":          cfg.Stream.Enabled,
+			"history":         cfg.History.Enabled,
+			"ingest":          cfg.Ingest.Enabled,
+			"names":           cfg.Names.Enabled,
+			"alert_rules":     cfg.Notifier.Rules.Enabled,
+			"scheduled":       cfg.Notifier.Scheduled.Enabled,
+			"panic_alerts":    cfg.Notifier.Panics.Enabled,
+			"telegram":        cfg.Notifier.Telegram.Enabled,
+			"email":           cfg.Notifications.Email.Enabled,
+			"discord":         cfg.Notifications.Discord.Enabled,
+			"budget":          cfg.ExternalAPI.Budget.Enabled,
+			"circuit_breaker": cfg.ExternalAPI.Breaker.Enabled,
+			"jobs":            cfg.Jobs.Enabled,
+			"backup":          cfg.Backup.Enabled,
+			"analytics":       cfg.Analytics.Enabled,
+			"chaos":           cfg.Chaos.Enabled,
+		},
+	}
+	if profile := cfg.Profile(); profile != nil {
+		summary.ConfigSources = profile.Sources
+	}
+	if summary.Servers == nil {
+		summary.Servers = []ServerInfo{}
+	}
+	return summary
+}
+
+// LogStartupSummary 以一条日志输出启动摘要，JSON日志中摘要为嵌套对象，文本日志中为JSON字符串
+func (c *Container) LogStartupSummary(servers []ServerInfo, rocketMQ string) {
+	summary := c.StartupSummary(servers, rocketMQ)
+
+	var value interface{} = summary
+	if c.Config.Log.Format != "json" {
+		data, err := json.Marshal(summary)
+		if err != nil {
+			c.Logger.Errorf("Failed to encode startup summary: %v", err)
+			return
+		}
+		value = string(data)
+	}
+	c.Logger.WithFields(map[string]interface{}{
+		"event":   startupSummaryEvent,
+		"summary": value,
+	}).Info("Startup summary")
+}
+
+// redisStatus Redis连接失败时入口程序以空客户端继续运行
+func (c *Container) redisStatus() DependencyInfo {
+	redis := c.Config.Database.Redis
+	if redis.Host == "" {
+		return DependencyInfo{Status: DependencyDisabled}
+	}
+	info := DependencyInfo{Status: DependencyConnected, Addr: fmt.Sprintf("%s:%d", redis.Host, redis.Port)}
+	if c.Redis == nil {
+		info.Status = DependencyUnavailable
+	}
+	return info
+}
+
+// bscStatus BSC节点按需连接，只区分是否启用和客户端是否创建成功
+func (c *Container) bscStatus() DependencyInfo {
+	if !c.Config.BSC.Enabled {
+		return DependencyInfo{Status: DependencyDisabled}
+	}
+	if c.Services.BSC == nil {
+		return DependencyInfo{Status: DependencyUnavailable}
+	}
+	return DependencyInfo{Status: DependencyEnabled}
+}
+
+// mysqlStatus MySQL连接失败时入口程序以空客户端继续运行
+func (c *Container) mysqlStatus() DependencyInfo {
+	mysql := c.Config.Database.MySQL
+	if !mysql.Enabled {
+		return DependencyInfo{Status: DependencyDisabled}
+	}
+	info := DependencyInfo{Status: DependencyConnected, Addr: fmt.Sprintf("%s:%d", mysql.Host, mysql.Port)}
+	if c.MySQL == nil {
+		info.Status = DependencyUnavailable
+	}
+	return info
+}
@@ -1,29 +1,47 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	htmltemplate "html/template"
+	"net"
+	"net/mail"
+	"net/url"
 	"strings"
+	"text/template"
 	"time"
 
-	"github.com/spf13/viper"
+	"crypto-info/pkg/rpcclient"
 )
 
 // Config 应用配置结构
 type Config struct {
-	App        App        `mapstructure:"app"`
-	Server     Server     `mapstructure:"server"`
-	Log        Log        `mapstructure:"log"`
-	Database   Database   `mapstructure:"database"`
-	ExternalAPI ExternalAPI `mapstructure:"external_api"`
-	Cache      Cache      `mapstructure:"cache"`
-	Monitoring Monitoring `mapstructure:"monitoring"`
-	RateLimit  RateLimit  `mapstructure:"rate_limit"`
-	Security   Security   `mapstructure:"security"`
-	Business   Business   `mapstructure:"business"`
-	BSC        BSC        `mapstructure:"bsc"`
-	RocketMQ   RocketMQ   `mapstructure:"rocketmq"`
+	App           App              `mapstructure:"app"`
+	Server        Server           `mapstructure:"server"`
+	RPCClient     rpcclient.Config `mapstructure:"rpc_client"`
+	Log           Log              `mapstructure:"log"`
+	Database      Database         `mapstructure:"database"`
+	ExternalAPI   ExternalAPI      `mapstructure:"external_api"`
+	Cache         Cache            `mapstructure:"cache"`
+	History       History          `mapstructure:"history"`
+	Ingest        Ingest           `mapstructure:"ingest"`
+	Notifier      Notifier         `mapstructure:"notifier"`
+	Notifications Notifications    `mapstructure:"notifications"`
+	Names         Names            `mapstructure:"names"`
+	Stream        Stream           `mapstructure:"stream"`
+	Monitoring    Monitoring       `mapstructure:"monitoring"`
+	RateLimit     RateLimit        `mapstructure:"rate_limit"`
+	Security      Security         `mapstructure:"security"`
+	Business      Business         `mapstructure:"business"`
+	BSC           BSC              `mapstructure:"bsc"`
+	RocketMQ      RocketMQ         `mapstructure:"rocketmq"`
+	Chaos         Chaos            `mapstructure:"chaos"`
+	Jobs          Jobs             `mapstructure:"jobs"`
+	Backup        Backup           `mapstructure:"backup"`
+	Analytics     Analytics        `mapstructure:"analytics"`
+
+	profile *Profile // 加载过程，只在 Load 中设置
 }
 
 // App 应用配置
@@ -49,13 +67,110 @@ type HTTPServer struct {
 	WriteTimeout   time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout    time.Duration `mapstructure:"idle_timeout"`
 	MaxHeaderBytes int           `mapstructure:"max_header_bytes"`
+	RequestTimeout time.Duration `mapstructure:"request_timeout"` // 单个请求的处理时间上限，X-Request-Timeout请求头只能缩短
+	ResponseCache  ResponseCache `mapstructure:"response_cache"`
+	Concurrency    Concurrency   `mapstructure:"concurrency"`
+	LoadShedding   LoadShedding  `mapstructure:"load_shedding"`
+	TrustedProxies []string      `mapstructure:"trusted_proxies"` // 可信反向代理的IP或CIDR，只有来自这些地址的X-Forwarded-For/X-Real-IP才被采信；为空时使用连接地址
+}
+
+// LoadShedding 依赖异常时拒绝低优先级请求，依赖状态来自后台健康探测(monitoring.health_check.interval)
+type LoadShedding struct {
+	Enabled    bool             `mapstructure:"enabled"`
+	RetryAfter time.Duration    `mapstructure:"retry_after"` // 503响应建议的重试间隔
+	Policies   []SheddingPolicy `mapstructure:"policies"`
+}
+
+// SheddingPolicy 降级策略，任一依赖处于指定状态时拒绝匹配路径前缀的请求
+type SheddingPolicy struct {
+	Name         string   `mapstructure:"name"`
+	Dependencies []string `mapstructure:"dependencies"` // 依赖名称(redis、mysql、huobi、binance、okx、coinbase、kraken、bsc)
+	Statuses     []string `mapstructure:"statuses"`     // 触发降级的依赖状态(degraded、down)，为空时为down
+	Prefixes     []string `mapstructure:"prefixes"`     // 被拒绝的请求路径前缀
+}
+
+// Concurrency 单实例同时处理的请求数上限，超出时返回503和Retry-After
+type Concurrency struct {
+	Enabled     bool               `mapstructure:"enabled"`
+	MaxInFlight int                `mapstructure:"max_in_flight"` // 所有请求的并发上限，0表示不限制
+	RetryAfter  time.Duration      `mapstructure:"retry_after"`   // 503响应建议的重试间隔
+	Groups      []ConcurrencyGroup `mapstructure:"groups"`        // 重量级接口的单独上限，同时计入全局上限
+}
+
+// ConcurrencyGroup 按路径前缀划分的路由分组
+type ConcurrencyGroup struct {
+	Name        string   `mapstructure:"name"`
+	Prefixes    []string `mapstructure:"prefixes"` // 请求路径前缀，匹配第一个满足的分组
+	MaxInFlight int      `mapstructure:"max_in_flight"`
+}
+
+// ResponseCache HTTP响应缓存配置，只缓存GET请求的200响应
+type ResponseCache struct {
+	Enabled    bool                     `mapstructure:"enabled"`
+	MaxEntries int                      `mapstructure:"max_entries"` // 单实例缓存条目上限
+	Routes     map[string]time.Duration `mapstructure:"routes"`      // 路由模板到缓存时间，如 /api/v1/crypto/price: 5s
 }
 
 // GRPCServer GRPC服务器配置
 type GRPCServer struct {
-	Host    string        `mapstructure:"host"`
-	Port    int           `mapstructure:"port"`
-	Timeout time.Duration `mapstructure:"timeout"`
+	Host       string        `mapstructure:"host"`
+	Port       int           `mapstructure:"port"`
+	Timeout    time.Duration `mapstructure:"timeout"`    // 单个调用的处理时间上限，客户端deadline更早时以客户端为准
+	Reflection bool          `mapstructure:"reflection"` // 是否开启gRPC服务反射(grpcurl等工具使用)
+}
+
+// Chaos 故障注入配置，用于在开发和测试环境演练降级，生产环境禁止启用
+type Chaos struct {
+	Enabled bool                  `mapstructure:"enabled"`
+	Seed    int64                 `mapstructure:"seed"`   // 随机数种子，便于复现同一序列，0表示随机
+	Faults  map[string]ChaosFault `mapstructure:"faults"` // key为注入目标：redis、http、grpc或外部提供方名称(bsc_rpc、bscscan、binance、huobi、okx、coinbase、kraken、token_sync)
+}
+
+// ChaosFault 单个目标注入的故障
+type ChaosFault struct {
+	Latency   time.Duration `mapstructure:"latency"`    // 每次调用增加的固定延迟
+	Jitter    time.Duration `mapstructure:"jitter"`     // 在固定延迟上再增加[0, jitter)的随机延迟
+	ErrorRate float64       `mapstructure:"error_rate"` // 调用失败的比例，0到1
+}
+
+// Jobs 进程内定时任务，singleton任务在多实例部署时每次计划执行经Redis锁只在一个实例运行
+type Jobs struct {
+	Enabled     bool                 `mapstructure:"enabled"`      // 关闭时内置任务不按计划执行，仍可通过管理接口手动触发
+	Timezone    string               `mapstructure:"timezone"`     // 计算cron表达式的时区，为空时使用UTC
+	HistorySize int                  `mapstructure:"history_size"` // 每个任务保留的执行记录条数
+	Tasks       map[string]JobConfig `mapstructure:"tasks"`        // key为任务名称：price_refresh、daily_report、history_cleanup、alert_digest、state_backup
+}
+
+// JobConfig 单个定时任务，未配置的字段使用内置默认值
+type JobConfig struct {
+	Disabled bool          `mapstructure:"disabled"` // 不注册该任务
+	Schedule string        `mapstructure:"schedule"` // cron表达式、@daily等预定义表达式或@every 5m形式的固定间隔
+	Jitter   time.Duration `mapstructure:"jitter"`   // 每次执行前的最大随机延迟
+	Timeout  time.Duration `mapstructure:"timeout"`  // 单次执行超时
+}
+
+// Backup 用户数据的定时加密备份，由state_backup任务执行，备份文件可用 cli restore 恢复
+type Backup struct {
+	Enabled    bool          `mapstructure:"enabled"`    // 注册state_backup定时任务
+	Dir        string        `mapstructure:"dir"`        // 备份文件目录，可挂载对象存储
+	Key        string        `mapstructure:"key"`        // base64编码的32字节AES-256-GCM密钥，建议通过环境变量 CRYPTO_BACKUP_KEY 配置
+	Retention  time.Duration `mapstructure:"retention"`  // 备份文件保留时长，0表示不清理
+	Categories []string      `mapstructure:"categories"` // 备份的状态分类，为空时备份所有用户数据：alerts、event_filters、portfolios、subscriptions
+	Storage    string        `mapstructure:"storage"`    // 备份存储：dir(默认，写入dir目录)或s3(S3兼容的对象存储)
+	S3         ObjectStorage `mapstructure:"s3"`         // storage为s3时使用
+}
+
+// ObjectStorage S3兼容的对象存储(AWS S3、MinIO、Cloudflare R2等)
+type ObjectStorage struct {
+	Endpoint        string        `mapstructure:"endpoint"`          // 服务地址，如 https://s3.us-east-1.amazonaws.com、http://minio:9000
+	Region          string        `mapstructure:"region"`            // 签名使用的区域，默认us-east-1
+	Bucket          string        `mapstructure:"bucket"`            // 存储桶
+	Prefix          string        `mapstructure:"prefix"`            // 对象key前缀，如 crypto-info/backups/
+	AccessKeyID     string        `mapstructure:"access_key_id"`     // 访问密钥ID
+	SecretAccessKey string        `mapstructure:"secret_access_key"` // 访问密钥，建议通过环境变量 CRYPTO_BACKUP_S3_SECRET_ACCESS_KEY 配置
+	PathStyle       bool          `mapstructure:"path_style"`        // 使用 endpoint/bucket/key 形式的地址，MinIO等通常需要开启
+	Timeout         time.Duration `mapstructure:"timeout"`           // 单次请求超时
+	Proxy           string        `mapstructure:"proxy"`             // 出站代理，格式同external_api的proxy
 }
 
 // Log 日志配置
@@ -93,6 +208,7 @@ type RedisConfig struct {
 
 // MySQLConfig MySQL配置
 type MySQLConfig struct {
+	Enabled         bool          `mapstructure:"enabled"` // 启用后启动时连接，连接失败时依赖MySQL的功能不可用
 	Host            string        `mapstructure:"host"`
 	Port            int           `mapstructure:"port"`
 	Username        string        `mapstructure:"username"`
@@ -104,12 +220,58 @@ type MySQLConfig struct {
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"` // 空闲连接的最长保留时间，0表示不限
+	DialTimeout     time.Duration `mapstructure:"dial_timeout"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
 }
 
 // ExternalAPI 外部API配置
 type ExternalAPI struct {
-	Huobi   APIConfig `mapstructure:"huobi"`
-	Binance APIConfig `mapstructure:"binance"`
+	Huobi     APIConfig `mapstructure:"huobi"`
+	Binance   APIConfig `mapstructure:"binance"`
+	OKX       APIConfig `mapstructure:"okx"`
+	Coinbase  APIConfig `mapstructure:"coinbase"`
+	Kraken    APIConfig `mapstructure:"kraken"`
+	CoinGecko APIConfig `mapstructure:"coingecko"` // 市值和流通量数据源
+	BscScan   APIConfig `mapstructure:"bscscan"`
+	Budget    Budget    `mapstructure:"budget"`
+	Breaker   Breaker   `mapstructure:"circuit_breaker"`
+	DNS       DNSConfig `mapstructure:"dns"`
+	Fixtures  Fixtures  `mapstructure:"fixtures"`
+}
+
+// Fixtures 外部API响应录制与回放，用于确定性的集成测试和离线演示环境
+type Fixtures struct {
+	Mode      string   `mapstructure:"mode"`      // 为空时关闭；record：请求真实API并保存响应；replay：只从录制文件返回响应，不访问网络
+	Dir       string   `mapstructure:"dir"`       // 录制文件目录，按提供方分子目录
+	Providers []string `mapstructure:"providers"` // 录制和回放的提供方(huobi、binance等)，为空时包括所有计入预算的提供方
+}
+
+// DNSConfig 上游域名解析配置
+type DNSConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // 缓存解析结果，解析失败时继续使用过期结果
+	CacheTTL      time.Duration `mapstructure:"cache_ttl"`      // 解析结果缓存时间
+	FallbackDelay time.Duration `mapstructure:"fallback_delay"` // 首选地址族未连接成功时，并行尝试另一地址族前的等待时间
+}
+
+// Budget 外部API调用预算，超出软预算后该提供方进入只读缓存模式
+type Budget struct {
+	Enabled   bool                      `mapstructure:"enabled"`
+	Providers map[string]ProviderBudget `mapstructure:"providers"` // key为提供方名称：bsc_rpc、bscscan、binance、huobi、okx、coinbase、kraken、token_sync
+}
+
+// Breaker 价格数据源熔断配置，数据源连续失败后在一段时间内直接跳过，改用缓存或其他数据源
+type Breaker struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	FailureThreshold int           `mapstructure:"failure_threshold"` // 连续失败该次数后熔断
+	OpenTimeout      time.Duration `mapstructure:"open_timeout"`      // 熔断持续时间，之后放行一次探测请求，成功则恢复
+}
+
+// ProviderBudget 单个提供方的调用预算，0表示不限制
+type ProviderBudget struct {
+	Hourly int64 `mapstructure:"hourly"` // 每小时调用次数上限
+	Daily  int64 `mapstructure:"daily"`  // 每天(UTC)调用次数上限
 }
 
 // APIConfig API配置
@@ -118,13 +280,232 @@ type APIConfig struct {
 	Timeout       time.Duration `mapstructure:"timeout"`
 	RetryTimes    int           `mapstructure:"retry_times"`
 	RetryInterval time.Duration `mapstructure:"retry_interval"`
+	APIKey        string        `mapstructure:"api_key"`    // API密钥，可通过环境变量配置
+	APIKeys       []string      `mapstructure:"api_keys"`   // 多个API密钥轮换使用，与api_key合并，环境变量以逗号分隔
+	RateLimit     float64       `mapstructure:"rate_limit"` // 单个API密钥每秒请求数上限
+	Proxy         string        `mapstructure:"proxy"`      // 出站代理(http://、socks5://)，为空时使用HTTP_PROXY等环境变量，direct表示直连
+}
+
+// Keys 合并api_key和api_keys，去除空值和重复值
+func (c *APIConfig) Keys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, key := range append([]string{c.APIKey}, c.APIKeys...) {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
 }
 
 // Cache 缓存配置
 type Cache struct {
-	PriceTTL   time.Duration `mapstructure:"price_ttl"`
-	VolumeTTL  time.Duration `mapstructure:"volume_ttl"`
-	DefaultTTL time.Duration `mapstructure:"default_ttl"`
+	PriceTTL      time.Duration `mapstructure:"price_ttl"`
+	PriceStaleTTL time.Duration `mapstructure:"price_stale_ttl"` // 价格过期后仍可返回旧值的时间窗口
+	VolumeTTL     time.Duration `mapstructure:"volume_ttl"`
+	TopVolumeTTL  time.Duration `mapstructure:"top_volume_ttl"`
+	KlineTTL      time.Duration `mapstructure:"kline_ttl"`      // K线缓存时间，不超过K线周期
+	MarketCapTTL  time.Duration `mapstructure:"market_cap_ttl"` // 市值和流通量缓存时间
+	GlobalTTL     time.Duration `mapstructure:"global_ttl"`     // 全市场总市值、成交额和市值占比缓存时间
+	DefaultTTL    time.Duration `mapstructure:"default_ttl"`
+	NegativeTTL   time.Duration `mapstructure:"negative_ttl"` // 不支持/失败查询的负缓存时间
+	KeyPrefix     string        `mapstructure:"key_prefix"`   // 缓存key前缀，支持{env}占位符
+	Adaptive      AdaptiveTTL   `mapstructure:"adaptive"`
+}
+
+// AdaptiveTTL 按币种的请求热度调整价格和交易量缓存时间，热门币种缩短、冷门币种延长，请求次数只在当前进程内统计
+type AdaptiveTTL struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Window       time.Duration `mapstructure:"window"`        // 统计请求次数的滑动窗口
+	HotRequests  int           `mapstructure:"hot_requests"`  // 窗口内请求次数达到该值的币种使用min_factor
+	ColdRequests int           `mapstructure:"cold_requests"` // 窗口内请求次数不超过该值的币种使用max_factor，两者之间按请求次数的对数插值
+	MinFactor    float64       `mapstructure:"min_factor"`    // 热门币种的缓存时间系数，如0.5为配置值的一半
+	MaxFactor    float64       `mapstructure:"max_factor"`    // 冷门币种的缓存时间系数
+	MinTTL       time.Duration `mapstructure:"min_ttl"`       // 调整后缓存时间的下限，0表示不限
+	MaxTTL       time.Duration `mapstructure:"max_ttl"`       // 调整后缓存时间的上限，0表示不限
+}
+
+// History 价格历史配置
+type History struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Retention time.Duration `mapstructure:"retention"`  // 历史数据保留时间
+	MaxPoints int           `mapstructure:"max_points"` // 单次查询最大数据点数
+	MaxGap    time.Duration `mapstructure:"max_gap"`    // 按时间查询价格时允许的最大采样间隔
+	Storage   string        `mapstructure:"storage"`    // 存储位置: redis(默认)或mysql，mysql需启用database.mysql
+}
+
+// Ingest 数据文件导入配置
+type Ingest struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Dir          string        `mapstructure:"dir"`           // 监听目录，目前仅支持本地目录
+	Interval     time.Duration `mapstructure:"interval"`      // 定时扫描间隔
+	SettleTime   time.Duration `mapstructure:"settle_time"`   // 文件最后修改后等待的时间，避免读取未写完的文件
+	MaxFileSize  int64         `mapstructure:"max_file_size"` // 单个文件大小上限(字节)
+	LogRetention time.Duration `mapstructure:"log_retention"` // 导入日志保留时间
+}
+
+// Notifier 告警通知配置
+type Notifier struct {
+	Retention time.Duration `mapstructure:"retention"` // 告警记录保留时间
+	Cooldown  time.Duration `mapstructure:"cooldown"`  // 同一事件重复告警的最小间隔
+
+	Scheduled ScheduledAlerts `mapstructure:"scheduled"`
+	Rules     AlertRules      `mapstructure:"rules"`
+	Webhooks  Webhooks        `mapstructure:"webhooks"`
+	RateLimit NotifyRateLimit `mapstructure:"rate_limit"`
+	Panics    PanicAlerts     `mapstructure:"panics"`
+	Telegram  Telegram        `mapstructure:"telegram"`
+}
+
+// Notifications 需要外部账号的通知渠道
+type Notifications struct {
+	Email   EmailNotifications   `mapstructure:"email"`
+	Discord DiscordNotifications `mapstructure:"discord"`
+}
+
+// EmailNotifications 通过SMTP发送HTML邮件告警
+type EmailNotifications struct {
+	Enabled       bool              `mapstructure:"enabled"`
+	Host          string            `mapstructure:"host"`
+	Port          int               `mapstructure:"port"`
+	Username      string            `mapstructure:"username"` // 为空时不认证
+	Password      string            `mapstructure:"password"`
+	TLS           string            `mapstructure:"tls"`            // starttls(默认)、tls(隐式TLS，通常为465端口)或none
+	From          string            `mapstructure:"from"`           // 发件人，如 "Crypto Info <alerts@example.com>"
+	To            []string          `mapstructure:"to"`             // 收件人
+	SubjectPrefix string            `mapstructure:"subject_prefix"` // 邮件标题前缀
+	Timeout       time.Duration     `mapstructure:"timeout"`        // 连接和发送的总超时
+	Types         []string          `mapstructure:"types"`          // 只发送这些类型的告警，为空时发送全部
+	Templates     map[string]string `mapstructure:"templates"`      // 按告警类型的邮件正文模板(html/template，数据为告警)，default用于未配置的类型
+}
+
+// DiscordNotifications 通过Discord频道的webhook推送嵌入(embed)格式的告警
+type DiscordNotifications struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	WebhookURL  string        `mapstructure:"webhook_url"`  // 频道设置中创建的webhook地址，包含令牌
+	Username    string        `mapstructure:"username"`     // 覆盖webhook的显示名称，为空时使用webhook设置
+	AvatarURL   string        `mapstructure:"avatar_url"`   // 覆盖webhook的头像
+	ExplorerURL string        `mapstructure:"explorer_url"` // 链上告警中交易和地址的区块浏览器地址，为空时使用 https://bscscan.com
+	Timeout     time.Duration `mapstructure:"timeout"`      // 单次发送超时
+	Types       []string      `mapstructure:"types"`        // 只推送这些类型的告警，为空时推送全部
+}
+
+// Analytics 匿名使用统计，需显式启用；只记录路由模板和币种，不记录IP、用户、会话和其他参数
+type Analytics struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // 进程内计数写入Redis并发布使用事件的间隔
+	Retention     time.Duration `mapstructure:"retention"`      // 小时统计的保留时间，也是查询的最大范围
+	Topic         string        `mapstructure:"topic"`          // 发布使用事件的RocketMQ主题，为空时只写入Redis
+	MaxSymbols    int           `mapstructure:"max_symbols"`    // 每个写入周期最多记录的不同币种数，超出的币种不计
+}
+
+// Telegram 通过Telegram机器人把告警推送到指定聊天
+type Telegram struct {
+	Enabled   bool              `mapstructure:"enabled"`
+	BotToken  string            `mapstructure:"bot_token"`
+	ChatID    string            `mapstructure:"chat_id"`    // 接收告警的聊天ID，群组和频道为负数或@用户名
+	APIURL    string            `mapstructure:"api_url"`    // Bot API地址，为空时使用 https://api.telegram.org
+	Timeout   time.Duration     `mapstructure:"timeout"`    // 单次发送超时
+	ParseMode string            `mapstructure:"parse_mode"` // 为空时发送纯文本，可选HTML、MarkdownV2
+	Types     []string          `mapstructure:"types"`      // 只推送这些类型的告警，为空时推送全部
+	Templates map[string]string `mapstructure:"templates"`  // 按告警类型的消息模板(text/template，数据为告警)，default用于未配置的类型
+}
+
+// PanicAlerts 请求处理或后台任务发生panic时发送critical告警，附带调用栈
+type PanicAlerts struct {
+	Enabled    bool `mapstructure:"enabled"`
+	StackLimit int  `mapstructure:"stack_limit"` // 告警中调用栈的最大字节数
+}
+
+// NotifyRateLimit 告警发送频率限制，超出限制的告警合并为摘要定时发送
+type NotifyRateLimit struct {
+	Enabled        bool           `mapstructure:"enabled"`
+	Window         time.Duration  `mapstructure:"window"`          // 计数窗口
+	Channels       map[string]int `mapstructure:"channels"`        // 每个通知渠道在窗口内的发送上限，未配置的渠道不限制
+	PerUser        int            `mapstructure:"per_user"`        // 每个用户在窗口内的告警上限，0表示不限制
+	DigestInterval time.Duration  `mapstructure:"digest_interval"` // 摘要发送间隔
+}
+
+// Webhooks 告警webhook投递配置
+type Webhooks struct {
+	Timeout      time.Duration `mapstructure:"timeout"`       // 单次投递超时
+	LogRetention time.Duration `mapstructure:"log_retention"` // 投递记录保留时间
+	Endpoints    []Webhook     `mapstructure:"endpoints"`
+	MaxPerUser   int           `mapstructure:"max_per_user"` // 每个用户可注册的webhook上限
+	Retry        WebhookRetry  `mapstructure:"retry"`        // 用户webhook投递失败时的重试
+}
+
+// WebhookRetry webhook投递重试，网络错误、5xx和429时按指数退避重试
+type WebhookRetry struct {
+	MaxAttempts    int           `mapstructure:"max_attempts"`    // 最多投递次数，含首次
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // 首次重试前的等待时间，之后每次翻倍
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`     // 单次等待时间上限
+}
+
+// Webhook 接收告警的webhook
+type Webhook struct {
+	Name   string   `mapstructure:"name"`   // 名称，用于投递记录和筛选
+	URL    string   `mapstructure:"url"`    // 接收地址，告警以JSON POST
+	Secret string   `mapstructure:"secret"` // 设置后请求带X-Signature头(请求体的HMAC-SHA256)
+	Types  []string `mapstructure:"types"`  // 只投递这些类型的告警，为空时投递全部
+}
+
+// AlertRules 用户条件告警配置
+type AlertRules struct {
+	Enabled    bool              `mapstructure:"enabled"`      // 是否定时评估规则，关闭时仍可管理规则
+	Interval   time.Duration     `mapstructure:"interval"`     // 评估间隔
+	MaxPerUser int               `mapstructure:"max_per_user"` // 每个用户的规则上限
+	Cooldown   time.Duration     `mapstructure:"cooldown"`     // 规则未指定冷却时间时使用
+	Storage    string            `mapstructure:"storage"`      // 规则存储: redis(默认)或mysql，mysql需启用database.mysql，评估状态仍保存在Redis
+	Sharding   AlertRuleSharding `mapstructure:"sharding"`
+}
+
+// AlertRuleSharding 多实例按币种分片评估条件告警，需要Redis
+type AlertRuleSharding struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	VirtualNodes int           `mapstructure:"virtual_nodes"` // 每个实例在一致性哈希环上的虚拟节点数
+	MemberTTL    time.Duration `mapstructure:"member_ttl"`    // 实例超过该时间未评估时视为下线，其币种由其他实例接管
+}
+
+// ScheduledAlerts 用户定时通知配置
+type ScheduledAlerts struct {
+	Enabled         bool          `mapstructure:"enabled"`          // 是否执行到期的定时通知，关闭时仍可管理规则
+	PollInterval    time.Duration `mapstructure:"poll_interval"`    // 检查到期规则的间隔
+	MaxPerUser      int           `mapstructure:"max_per_user"`     // 每个用户的规则上限
+	MaxDelay        time.Duration `mapstructure:"max_delay"`        // 超过该时间未执行的通知直接跳过，避免停机恢复后补发过期内容
+	DefaultTimezone string        `mapstructure:"default_timezone"` // 请求未指定时区时使用
+}
+
+// Stream WebSocket推送配置，多实例部署时事件经Redis pub/sub分发
+type Stream struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	Channel          string        `mapstructure:"channel"`           // Redis pub/sub频道，自动添加缓存key前缀
+	BufferSize       int           `mapstructure:"buffer_size"`       // 单个连接的待发送事件上限，超出后丢弃
+	MaxConnections   int           `mapstructure:"max_connections"`   // 单实例连接上限，0表示不限制
+	PingInterval     time.Duration `mapstructure:"ping_interval"`     // 心跳间隔
+	SubscriptionTTL  time.Duration `mapstructure:"subscription_ttl"`  // 命名订阅未被使用时的保留时间，连接或更新时顺延
+	SnapshotInterval time.Duration `mapstructure:"snapshot_interval"` // 增量推送时每个币种推送完整数据的间隔
+	ReplaySize       int           `mapstructure:"replay_size"`       // 保留最近事件的条数，连接时可通过replay参数补发，0表示不保留
+}
+
+// Names 链上域名解析配置
+type Names struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	CacheTTL    time.Duration `mapstructure:"cache_ttl"`    // 解析结果缓存时间
+	NegativeTTL time.Duration `mapstructure:"negative_ttl"` // 未注册名称或地址的缓存时间
+	SpaceID     NameRegistry  `mapstructure:"space_id"`
+	ENS         NameRegistry  `mapstructure:"ens"`
+}
+
+// NameRegistry ENS风格的域名注册表
+type NameRegistry struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	TLD      string `mapstructure:"tld"`      // 顶级域名，如bnb、eth
+	Registry string `mapstructure:"registry"` // 注册表合约地址
+	RPCURL   string `mapstructure:"rpc_url"`  // 注册表所在链的RPC地址，为空时使用BSC节点
 }
 
 // Monitoring 监控配置
@@ -132,13 +513,24 @@ type Monitoring struct {
 	Metrics     MetricsConfig     `mapstructure:"metrics"`
 	Tracing     TracingConfig     `mapstructure:"tracing"`
 	HealthCheck HealthCheckConfig `mapstructure:"health_check"`
+	SLA         SLAConfig         `mapstructure:"sla"`
+}
+
+// SLAConfig 请求成功率和依赖可用性统计，按分钟和小时聚合保存在Redis，多个实例共享
+type SLAConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Objective      float64       `mapstructure:"objective"`       // 请求成功率目标(%)，如99.9
+	FlushInterval  time.Duration `mapstructure:"flush_interval"`  // 进程内请求计数写入Redis的间隔
+	SampleInterval time.Duration `mapstructure:"sample_interval"` // 依赖可用性采样间隔
+	CacheTTL       time.Duration `mapstructure:"cache_ttl"`       // /status/sla结果缓存时间
 }
 
 // MetricsConfig 指标配置
 type MetricsConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
 	Path    string `mapstructure:"path"`
-	Port    int    `mapstructure:"port"`
+	Host    string `mapstructure:"host"` // 探针与指标监听地址，为空时监听所有地址
+	Port    int    `mapstructure:"port"` // 探针与指标独立端口，gRPC-only部署时也会监听
 }
 
 // TracingConfig 链路追踪配置
@@ -151,17 +543,20 @@ type TracingConfig struct {
 
 // HealthCheckConfig 健康检查配置
 type HealthCheckConfig struct {
-	Enabled  bool          `mapstructure:"enabled"`
-	Path     string        `mapstructure:"path"`
-	Interval time.Duration `mapstructure:"interval"`
+	Enabled       bool          `mapstructure:"enabled"`
+	Path          string        `mapstructure:"path"`
+	Interval      time.Duration `mapstructure:"interval"`
+	ProbeTimeout  time.Duration `mapstructure:"probe_timeout"`  // /readyz单个依赖的探测超时
+	LatencyBudget time.Duration `mapstructure:"latency_budget"` // 依赖延迟预算，超过后标记为degraded
+	CacheTTL      time.Duration `mapstructure:"cache_ttl"`      // 探测结果缓存时间，避免探针频繁请求上游
 }
 
-// RateLimit 限流配置
+// RateLimit 限流配置，按客户端IP的令牌桶，仅在当前实例内计数
 type RateLimit struct {
 	Enabled           bool          `mapstructure:"enabled"`
-	RequestsPerSecond int           `mapstructure:"requests_per_second"`
-	Burst             int           `mapstructure:"burst"`
-	CleanupInterval   time.Duration `mapstructure:"cleanup_interval"`
+	RequestsPerSecond int           `mapstructure:"requests_per_second"` // 每个客户端每秒补充的请求数
+	Burst             int           `mapstructure:"burst"`               // 允许的突发请求数，为0时等于requests_per_second
+	CleanupInterval   time.Duration `mapstructure:"cleanup_interval"`    // 清理空闲客户端的间隔，实际间隔上下浮动20%
 }
 
 // Security 安全配置
@@ -169,6 +564,12 @@ type Security struct {
 	CORS    CORSConfig    `mapstructure:"cors"`
 	JWT     JWTConfig     `mapstructure:"jwt"`
 	Session SessionConfig `mapstructure:"session"`
+	Admin   AdminConfig   `mapstructure:"admin"`
+}
+
+// AdminConfig 管理接口(/api/v1/admin/*)配置
+type AdminConfig struct {
+	Token string `mapstructure:"token"` // 请求需带Authorization: Bearer <token>或X-Admin-Token头，为空时关闭管理接口
 }
 
 // CORSConfig CORS配置
@@ -205,11 +606,25 @@ type SessionConfig struct {
 
 // Business 业务配置
 type Business struct {
-	SupportedSymbols    []string `mapstructure:"supported_symbols"`
-	DefaultSymbol       string   `mapstructure:"default_symbol"`
-	MaxAnalysisDays     int      `mapstructure:"max_analysis_days"`
-	DefaultAnalysisDays int      `mapstructure:"default_analysis_days"`
-	MockDataEnabled     bool     `mapstructure:"mock_data_enabled"`
+	SupportedSymbols    []string          `mapstructure:"supported_symbols"`
+	DefaultSymbol       string            `mapstructure:"default_symbol"`
+	MaxAnalysisDays     int               `mapstructure:"max_analysis_days"`
+	DefaultAnalysisDays int               `mapstructure:"default_analysis_days"`
+	MockDataEnabled     bool              `mapstructure:"mock_data_enabled"`
+	PriceSource         string            `mapstructure:"price_source"`      // 价格数据源：bsc(链上流动性，默认)、binance、huobi或okx
+	PriceFallbacks      []string          `mapstructure:"price_fallbacks"`   // 主数据源失败时依次尝试的数据源，都失败时回退到模拟数据
+	USDPriceSources     []string          `mapstructure:"usd_price_sources"` // quote_currency=USD时依次尝试的美元计价数据源：coinbase、kraken
+	KlineSources        []string          `mapstructure:"kline_sources"`     // K线依次尝试的数据源：binance、huobi、okx
+	CoinGeckoIDs        map[string]string `mapstructure:"coingecko_ids"`     // 币种对应的CoinGecko币种ID，覆盖内置映射
+	Precision           Precision         `mapstructure:"precision"`
+}
+
+// Precision 价格显示精度，用于JSON响应和通知中的价格
+type Precision struct {
+	SignificantDigits int            `mapstructure:"significant_digits"` // 未单独配置的币种按有效数字位数确定小数位
+	MinDecimals       int            `mapstructure:"min_decimals"`
+	MaxDecimals       int            `mapstructure:"max_decimals"`
+	Symbols           map[string]int `mapstructure:"symbols"` // 币种固定小数位，如 BTC: 2
 }
 
 // RocketMQ 消息队列配置
@@ -222,10 +637,10 @@ type RocketMQ struct {
 
 // Producer 生产者配置
 type Producer struct {
-	GroupName       string        `mapstructure:"group_name"`
-	RetryTimes      int           `mapstructure:"retry_times"`
-	SendMsgTimeout  time.Duration `mapstructure:"send_msg_timeout"`
-	CompressLevel   int           `mapstructure:"compress_level"`
+	GroupName      string        `mapstructure:"group_name"`
+	RetryTimes     int           `mapstructure:"retry_times"`
+	SendMsgTimeout time.Duration `mapstructure:"send_msg_timeout"`
+	CompressLevel  int           `mapstructure:"compress_level"`
 }
 
 // Consumer 消费者配置
@@ -239,30 +654,176 @@ type Consumer struct {
 
 // BSC BSC链上数据监控配置
 type BSC struct {
-	Enabled        bool               `mapstructure:"enabled"`
-	RPCURL         string             `mapstructure:"rpc_url"`
-	WebSocketURL   string             `mapstructure:"websocket_url"`
-	ChainID        int64              `mapstructure:"chain_id"`
-	BlockConfirmation int             `mapstructure:"block_confirmation"`
-	Monitoring     BSCMonitoring      `mapstructure:"monitoring"`
-	Contracts      BSCContracts       `mapstructure:"contracts"`
-	Events         BSCEvents          `mapstructure:"events"`
-	Cache          BSCCache           `mapstructure:"cache"`
+	Enabled           bool           `mapstructure:"enabled"`
+	RPCURL            string         `mapstructure:"rpc_url"`
+	WebSocketURL      string         `mapstructure:"websocket_url"`
+	Proxy             string         `mapstructure:"proxy"` // RPC和WebSocket的出站代理，规则同external_api.*.proxy
+	ChainID           int64          `mapstructure:"chain_id"`
+	BlockConfirmation int            `mapstructure:"block_confirmation"`
+	Monitoring        BSCMonitoring  `mapstructure:"monitoring"`
+	Contracts         BSCContracts   `mapstructure:"contracts"`
+	Events            BSCEvents      `mapstructure:"events"`
+	Cache             BSCCache       `mapstructure:"cache"`
+	TokenSync         TokenSync      `mapstructure:"token_sync"`
+	Fallback          BSCFallback    `mapstructure:"fallback"`
+	Bridges           BridgeTracking `mapstructure:"bridges"`
+	Farms             FarmTracking   `mapstructure:"farms"`
+	TVL               TVLTracking    `mapstructure:"tvl"`
+	Liquidity         LiquidityWatch `mapstructure:"liquidity"`
+	Snapshot          HolderSnapshot `mapstructure:"snapshot"`
+	EventFilters      EventFilters   `mapstructure:"event_filters"`
+	Congestion        BSCCongestion  `mapstructure:"congestion"`
+	Validators        BSCValidators  `mapstructure:"validators"`
+}
+
+// BSCValidators 出块验证者统计配置，按最近若干个epoch的区块统计各验证者的出块数和漏块估计
+type BSCValidators struct {
+	EpochLength   int           `mapstructure:"epoch_length"`   // 每个epoch的区块数，与链上参数一致
+	DefaultEpochs int           `mapstructure:"default_epochs"` // 未指定epochs参数时统计的epoch数
+	MaxEpochs     int           `mapstructure:"max_epochs"`     // 单次请求最多统计的epoch数
+	RecentBlocks  int           `mapstructure:"recent_blocks"`  // 每个验证者返回的最近出块区块号数量
+	CacheTTL      time.Duration `mapstructure:"cache_ttl"`      // 统计结果在进程内的缓存时间
+}
+
+// BSCCongestion 网络拥堵指标配置，按最近区块的利用率、Gas价格和待处理交易积压计算拥堵评分
+type BSCCongestion struct {
+	SampleBlocks     int           `mapstructure:"sample_blocks"`      // 采样的最近区块数
+	CacheTTL         time.Duration `mapstructure:"cache_ttl"`          // 计算结果在进程内的缓存时间，API和指标共用
+	BaselineGasPrice float64       `mapstructure:"baseline_gas_price"` // 网络空闲时的Gas价格(Gwei)，平均价格超出越多评分越高
+}
+
+// EventFilters 用户自定义事件过滤配置，按合约地址、事件签名和topic匹配链上日志
+type EventFilters struct {
+	Enabled    bool          `mapstructure:"enabled"`      // 是否定时扫描，关闭时仍可管理过滤器
+	Interval   time.Duration `mapstructure:"interval"`     // 扫描间隔
+	MaxBlocks  uint64        `mapstructure:"max_blocks"`   // 单次扫描的最大区块跨度
+	MaxPerUser int           `mapstructure:"max_per_user"` // 每个用户的过滤器上限
+	MaxMatches int           `mapstructure:"max_matches"`  // 每个过滤器保留的匹配记录数
+}
+
+// HolderSnapshot 持币快照导出配置
+type HolderSnapshot struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Dir       string        `mapstructure:"dir"`        // 导出文件目录
+	ChunkSize uint64        `mapstructure:"chunk_size"` // 单次查询转账日志的区块跨度
+	MaxJobs   int           `mapstructure:"max_jobs"`   // 同时执行的快照任务数
+	MaxBlocks uint64        `mapstructure:"max_blocks"` // 单个任务允许回放的最大区块跨度，0表示不限制
+	Retention time.Duration `mapstructure:"retention"`  // 任务记录与导出文件保留时长
+}
+
+// LiquidityWatch 流动性撤出监控配置
+type LiquidityWatch struct {
+	Enabled        bool           `mapstructure:"enabled"`
+	Interval       time.Duration  `mapstructure:"interval"`        // 扫描间隔
+	Retention      time.Duration  `mapstructure:"retention"`       // 事件保留时长
+	ShareThreshold float64        `mapstructure:"share_threshold"` // 单笔撤出或解锁占流动性的比例阈值(0-1)
+	MaxBlocks      uint64         `mapstructure:"max_blocks"`      // 单次扫描的最大区块跨度
+	Lockers        []string       `mapstructure:"lockers"`         // LP锁仓合约地址
+	Pairs          []PairContract `mapstructure:"pairs"`           // 监控的交易对，为空时使用tvl.pairs
+	Discovery      PairDiscovery  `mapstructure:"discovery"`
+}
+
+// PairDiscovery 交易对自动发现配置，通过Factory查找关注代币的交易对并加入流动性监控
+type PairDiscovery struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Interval    time.Duration `mapstructure:"interval"`     // 重新发现的间隔
+	MaxPairs    int           `mapstructure:"max_pairs"`    // 每个代币加入监控的交易对数量，按代币在池中的储备量取前几个
+	Tokens      []string      `mapstructure:"tokens"`       // 关注的代币地址
+	QuoteTokens []string      `mapstructure:"quote_tokens"` // 与关注代币组成交易对的代币地址，为空时使用contracts中的WBNB、USDT和BUSD
+}
+
+// TVLTracking 交易对锁仓价值跟踪配置
+type TVLTracking struct {
+	Enabled       bool           `mapstructure:"enabled"`
+	Interval      time.Duration  `mapstructure:"interval"`       // 刷新间隔
+	Retention     time.Duration  `mapstructure:"retention"`      // 时间序列保留时长
+	DropThreshold float64        `mapstructure:"drop_threshold"` // 触发告警的下跌比例(0-1)
+	DropWindow    time.Duration  `mapstructure:"drop_window"`    // 下跌比较的时间窗口
+	Pairs         []PairContract `mapstructure:"pairs"`          // 跟踪的交易对
+}
+
+// PairContract 交易对合约
+type PairContract struct {
+	Name    string `mapstructure:"name"`
+	Address string `mapstructure:"address"`
+}
+
+// FarmTracking 流动性挖矿APR配置
+type FarmTracking struct {
+	Enabled       bool           `mapstructure:"enabled"`
+	CacheTTL      time.Duration  `mapstructure:"cache_ttl"`       // 计算结果缓存时间
+	BlocksPerYear int64          `mapstructure:"blocks_per_year"` // 每年出块数，用于年化奖励
+	MaxPools      int            `mapstructure:"max_pools"`       // 每个合约最多读取的池数量
+	Concurrency   int            `mapstructure:"concurrency"`     // 读取池信息的并发数
+	Chefs         []FarmContract `mapstructure:"chefs"`           // MasterChef风格的挖矿合约
+}
+
+// FarmContract MasterChef风格的挖矿合约
+type FarmContract struct {
+	Name         string `mapstructure:"name"`
+	Address      string `mapstructure:"address"`
+	RewardToken  string `mapstructure:"reward_token"`  // 奖励代币地址
+	RewardMethod string `mapstructure:"reward_method"` // 每区块奖励的读取方法，默认cakePerBlock
+}
+
+// BridgeTracking 跨链桥活动跟踪配置
+type BridgeTracking struct {
+	Enabled   bool             `mapstructure:"enabled"`
+	Interval  time.Duration    `mapstructure:"interval"`  // 扫描间隔
+	Retention time.Duration    `mapstructure:"retention"` // 记录保留时长
+	PageSize  int              `mapstructure:"page_size"` // 每次从BscScan拉取的转账条数
+	Contracts []BridgeContract `mapstructure:"contracts"` // 跟踪的跨链桥合约
+}
+
+// BridgeContract 跨链桥合约
+type BridgeContract struct {
+	Name    string `mapstructure:"name"`
+	Address string `mapstructure:"address"`
+}
+
+// BSCFallback 本地索引未就绪时回退到BscScan的配置
+type BSCFallback struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// TokenSync 代币列表同步配置
+type TokenSync struct {
+	Enabled       bool              `mapstructure:"enabled"`
+	Interval      time.Duration     `mapstructure:"interval"`       // 同步间隔
+	Timeout       time.Duration     `mapstructure:"timeout"`        // 下载列表超时时间
+	VerifyOnChain bool              `mapstructure:"verify_onchain"` // 是否在链上确认合约存在
+	Concurrency   int               `mapstructure:"concurrency"`    // 链上校验并发数
+	Sources       []TokenListSource `mapstructure:"sources"`        // 可信代币列表
+}
+
+// TokenListSource 代币列表来源
+type TokenListSource struct {
+	Name string `mapstructure:"name"`
+	URL  string `mapstructure:"url"`
 }
 
 // BSCMonitoring BSC监控配置
 type BSCMonitoring struct {
-	Enabled   bool          `mapstructure:"enabled"`
-	Interval  time.Duration `mapstructure:"interval"`
-	BatchSize int           `mapstructure:"batch_size"`
+	Enabled    bool          `mapstructure:"enabled"`
+	Interval   time.Duration `mapstructure:"interval"`
+	BatchSize  int           `mapstructure:"batch_size"`  // 每轮最多处理的区块数，落后时分多轮追赶
+	MaxCatchup int           `mapstructure:"max_catchup"` // 重启或恢复后最多补处理的区块数，超出时跳过更早的区块，0表示不限制
+	LagAlert   BSCLagAlert   `mapstructure:"lag_alert"`
+}
+
+// BSCLagAlert 区块监控滞后告警配置，超出任一阈值时发送系统告警并将就绪状态标记为degraded
+type BSCLagAlert struct {
+	MaxLagBlocks   int `mapstructure:"max_lag_blocks"`  // 最新区块与检查点之间允许落后的区块数，0表示不检查
+	StallIntervals int `mapstructure:"stall_intervals"` // 连续多少个监控周期没有处理新区块视为停滞，0表示不检查
 }
 
 // BSCContracts BSC合约地址配置
 type BSCContracts struct {
-	PancakeRouter string `mapstructure:"pancake_router"`
-	WBNB          string `mapstructure:"wbnb"`
-	USDT          string `mapstructure:"usdt"`
-	BUSD          string `mapstructure:"busd"`
+	PancakeRouter  string `mapstructure:"pancake_router"`
+	PancakeFactory string `mapstructure:"pancake_factory"` // 交易对自动发现使用的Factory，为空时使用PancakeSwap V2
+	WBNB           string `mapstructure:"wbnb"`
+	USDT           string `mapstructure:"usdt"`
+	BUSD           string `mapstructure:"busd"`
 }
 
 // BSCEvents BSC事件监控配置
@@ -280,42 +841,11 @@ type BSCCache struct {
 	Prefix  string        `mapstructure:"prefix"`
 }
 
-// Load 加载配置
+// Load 加载配置，优先级从低到高为基础配置文件、{app.env}.yaml 和 CRYPTO_ 前缀的环境变量，见 Precedence
 func Load(configPath string) (*Config, error) {
-	v := viper.New()
-
-	// 设置配置文件路径
-	if configPath != "" {
-		v.SetConfigFile(configPath)
-	} else {
-		// 默认配置文件路径
-		v.SetConfigName("config")
-		v.SetConfigType("yaml")
-		v.AddConfigPath("./configs")
-		v.AddConfigPath("../configs")
-		v.AddConfigPath("/etc/crypto-info")
-	}
-
-	// 环境变量支持
-	v.SetEnvPrefix("CRYPTO")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
-
-	// 读取配置文件
-	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	// 根据环境加载对应的配置文件
-	env := v.GetString("app.env")
-	if env != "" {
-		envConfigPath := filepath.Join(filepath.Dir(v.ConfigFileUsed()), env+".yaml")
-		if _, err := os.Stat(envConfigPath); err == nil {
-			v.SetConfigFile(envConfigPath)
-			if err := v.MergeInConfig(); err != nil {
-				return nil, fmt.Errorf("failed to merge env config: %w", err)
-			}
-		}
+	v, profile, err := loadProfile(configPath)
+	if err != nil {
+		return nil, err
 	}
 
 	// 解析配置
@@ -329,9 +859,15 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
 
+	config.profile = profile
 	return &config, nil
 }
 
+// Profile 配置的来源和合并结果，敏感值已隐藏
+func (c *Config) Profile() *Profile {
+	return c.profile
+}
+
 // validate 验证配置
 func validate(config *Config) error {
 	if config.App.Name == "" {
@@ -346,6 +882,342 @@ func validate(config *Config) error {
 		return fmt.Errorf("invalid grpc port: %d", config.Server.GRPC.Port)
 	}
 
+	if _, err := config.TrustedProxyNets(); err != nil {
+		return err
+	}
+
+	if err := validateMySQL(&config.Database.MySQL); err != nil {
+		return err
+	}
+	if err := validateAdaptiveTTL(&config.Cache.Adaptive); err != nil {
+		return err
+	}
+	if err := validateBackup(&config.Backup); err != nil {
+		return err
+	}
+	switch config.History.Storage {
+	case "", "redis":
+	case "mysql":
+		if !config.Database.MySQL.Enabled {
+			return fmt.Errorf("history.storage mysql requires database.mysql.enabled")
+		}
+	default:
+		return fmt.Errorf("invalid history.storage: %s", config.History.Storage)
+	}
+	switch config.Notifier.Rules.Storage {
+	case "", "redis":
+	case "mysql":
+		if !config.Database.MySQL.Enabled {
+			return fmt.Errorf("notifier.rules.storage mysql requires database.mysql.enabled")
+		}
+	default:
+		return fmt.Errorf("invalid notifier.rules.storage: %s", config.Notifier.Rules.Storage)
+	}
+
+	proxies := map[string]string{
+		"external_api.huobi.proxy":     config.ExternalAPI.Huobi.Proxy,
+		"external_api.binance.proxy":   config.ExternalAPI.Binance.Proxy,
+		"external_api.okx.proxy":       config.ExternalAPI.OKX.Proxy,
+		"external_api.coinbase.proxy":  config.ExternalAPI.Coinbase.Proxy,
+		"external_api.kraken.proxy":    config.ExternalAPI.Kraken.Proxy,
+		"external_api.coingecko.proxy": config.ExternalAPI.CoinGecko.Proxy,
+		"external_api.bscscan.proxy":   config.ExternalAPI.BscScan.Proxy,
+		"bsc.proxy":                    config.BSC.Proxy,
+	}
+	for name, proxy := range proxies {
+		if err := validateProxy(proxy); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	switch config.Business.PriceSource {
+	case "", "bsc", "binance", "huobi", "okx":
+	default:
+		return fmt.Errorf("invalid business.price_source: %s", config.Business.PriceSource)
+	}
+	for _, source := range config.Business.PriceFallbacks {
+		switch source {
+		case "bsc", "binance", "huobi", "okx":
+		default:
+			return fmt.Errorf("invalid business.price_fallbacks: %s", source)
+		}
+	}
+	for _, source := range config.Business.USDPriceSources {
+		switch source {
+		case "coinbase", "kraken":
+		default:
+			return fmt.Errorf("invalid business.usd_price_sources: %s", source)
+		}
+	}
+	for _, source := range config.Business.KlineSources {
+		switch source {
+		case "binance", "huobi", "okx":
+		default:
+			return fmt.Errorf("invalid business.kline_sources: %s", source)
+		}
+	}
+
+	if config.BSC.Liquidity.Discovery.MaxPairs < 0 {
+		return fmt.Errorf("invalid bsc.liquidity.discovery.max_pairs: %d", config.BSC.Liquidity.Discovery.MaxPairs)
+	}
+
+	if config.BSC.EventFilters.MaxPerUser < 0 || config.BSC.EventFilters.MaxMatches < 0 {
+		return fmt.Errorf("invalid bsc.event_filters: max_per_user and max_matches must not be negative")
+	}
+
+	if config.BSC.Congestion.SampleBlocks < 0 || config.BSC.Congestion.SampleBlocks > 1024 || config.BSC.Congestion.BaselineGasPrice < 0 {
+		return fmt.Errorf("invalid bsc.congestion: sample_blocks must be between 0 and 1024 and baseline_gas_price must not be negative")
+	}
+
+	if config.BSC.Validators.EpochLength < 0 || config.BSC.Validators.DefaultEpochs < 0 || config.BSC.Validators.MaxEpochs < 0 || config.BSC.Validators.RecentBlocks < 0 {
+		return fmt.Errorf("invalid bsc.validators: epoch_length, default_epochs, max_epochs and recent_blocks must not be negative")
+	}
+
+	if config.BSC.Monitoring.LagAlert.MaxLagBlocks < 0 || config.BSC.Monitoring.LagAlert.StallIntervals < 0 {
+		return fmt.Errorf("invalid bsc.monitoring.lag_alert: max_lag_blocks and stall_intervals must not be negative")
+	}
+
+	if config.Backup.Enabled {
+		if config.Backup.Dir == "" {
+			return fmt.Errorf("backup.dir is required when backup is enabled")
+		}
+		if key, err := base64.StdEncoding.DecodeString(config.Backup.Key); err != nil || len(key) != 32 {
+			return fmt.Errorf("backup.key must be a base64 encoded 32-byte key when backup is enabled")
+		}
+	}
+	if config.Backup.Retention < 0 {
+		return fmt.Errorf("backup.retention must not be negative")
+	}
+
+	retry := config.Notifier.Webhooks.Retry
+	if config.Notifier.Webhooks.MaxPerUser < 0 || retry.MaxAttempts < 0 || retry.InitialBackoff < 0 || retry.MaxBackoff < 0 {
+		return fmt.Errorf("invalid notifier.webhooks: max_per_user and retry settings must not be negative")
+	}
+	if err := validateTelegram(&config.Notifier.Telegram); err != nil {
+		return err
+	}
+	if err := validateEmail(&config.Notifications.Email); err != nil {
+		return err
+	}
+	if err := validateDiscord(&config.Notifications.Discord); err != nil {
+		return err
+	}
+	if config.Analytics.FlushInterval < 0 || config.Analytics.Retention < 0 || config.Analytics.MaxSymbols < 0 {
+		return fmt.Errorf("invalid analytics: flush_interval, retention and max_symbols must not be negative")
+	}
+
+	if config.ExternalAPI.Breaker.FailureThreshold < 0 || config.ExternalAPI.Breaker.OpenTimeout < 0 {
+		return fmt.Errorf("invalid external_api.circuit_breaker: failure_threshold and open_timeout must not be negative")
+	}
+
+	switch config.ExternalAPI.Fixtures.Mode {
+	case "":
+	case "record", "replay":
+		if config.IsProduction() {
+			return fmt.Errorf("external_api.fixtures.mode must not be set in production")
+		}
+		if config.ExternalAPI.Fixtures.Dir == "" {
+			return fmt.Errorf("external_api.fixtures.dir is required in %s mode", config.ExternalAPI.Fixtures.Mode)
+		}
+	default:
+		return fmt.Errorf("invalid external_api.fixtures.mode: %s", config.ExternalAPI.Fixtures.Mode)
+	}
+
+	if config.Chaos.Enabled {
+		if config.IsProduction() {
+			return fmt.Errorf("chaos.enabled must not be set in production")
+		}
+		for target, fault := range config.Chaos.Faults {
+			if fault.ErrorRate < 0 || fault.ErrorRate > 1 {
+				return fmt.Errorf("invalid chaos.faults.%s.error_rate: %v", target, fault.ErrorRate)
+			}
+			if fault.Latency < 0 || fault.Jitter < 0 {
+				return fmt.Errorf("invalid chaos.faults.%s: latency and jitter must not be negative", target)
+			}
+		}
+	}
+
+	if config.Jobs.Timezone != "" {
+		if _, err := time.LoadLocation(config.Jobs.Timezone); err != nil {
+			return fmt.Errorf("invalid jobs.timezone: %w", err)
+		}
+	}
+	for name, job := range config.Jobs.Tasks {
+		if job.Jitter < 0 || job.Timeout < 0 {
+			return fmt.Errorf("invalid jobs.tasks.%s: jitter and timeout must not be negative", name)
+		}
+	}
+
+	return nil
+}
+
+// validateProxy 验证代理地址，为空或direct时不使用配置的代理
+func validateProxy(proxy string) error {
+	if proxy == "" || proxy == "direct" {
+		return nil
+	}
+
+	// 代理地址可能包含账号密码，错误信息中不回显原值
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return errors.New("malformed proxy url")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("proxy url has no host")
+	}
+	return nil
+}
+
+// validateBackup 检查备份存储，使用对象存储时需要地址、存储桶和访问密钥
+func validateBackup(backup *Backup) error {
+	switch backup.Storage {
+	case "", "dir":
+		return nil
+	case "s3":
+	default:
+		return fmt.Errorf("invalid backup.storage: %s", backup.Storage)
+	}
+
+	s3 := &backup.S3
+	u, err := url.Parse(s3.Endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("backup.s3.endpoint must be an http(s) url")
+	}
+	if s3.Bucket == "" {
+		return fmt.Errorf("backup.s3.bucket is required")
+	}
+	if s3.AccessKeyID == "" || s3.SecretAccessKey == "" {
+		return fmt.Errorf("backup.s3.access_key_id and backup.s3.secret_access_key are required")
+	}
+	if err := validateProxy(s3.Proxy); err != nil {
+		return fmt.Errorf("backup.s3.proxy: %w", err)
+	}
+	return nil
+}
+
+// validateMySQL 启用MySQL时检查地址、数据库名、时区和连接池参数
+func validateMySQL(mysql *MySQLConfig) error {
+	if !mysql.Enabled {
+		return nil
+	}
+	if mysql.Host == "" || mysql.Port <= 0 || mysql.Port > 65535 || mysql.Database == "" {
+		return fmt.Errorf("database.mysql.host, a valid port and database are required when mysql is enabled")
+	}
+	if mysql.Loc != "" {
+		if _, err := time.LoadLocation(mysql.Loc); err != nil {
+			return fmt.Errorf("invalid database.mysql.loc: %w", err)
+		}
+	}
+	if mysql.MaxOpenConns < 0 || mysql.MaxIdleConns < 0 || mysql.ConnMaxLifetime < 0 || mysql.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("database.mysql pool settings must not be negative")
+	}
+	if mysql.DialTimeout < 0 || mysql.ReadTimeout < 0 || mysql.WriteTimeout < 0 {
+		return fmt.Errorf("database.mysql timeouts must not be negative")
+	}
+	return nil
+}
+
+// validateAdaptiveTTL 启用缓存时间调整时检查阈值和系数，系数需包含1，使配置的缓存时间处于调整范围内
+func validateAdaptiveTTL(adaptive *AdaptiveTTL) error {
+	if !adaptive.Enabled {
+		return nil
+	}
+	if adaptive.Window <= 0 {
+		return fmt.Errorf("cache.adaptive.window must be positive")
+	}
+	if adaptive.ColdRequests < 0 || adaptive.HotRequests <= adaptive.ColdRequests {
+		return fmt.Errorf("cache.adaptive.hot_requests must be greater than cold_requests")
+	}
+	if adaptive.MinFactor <= 0 || adaptive.MinFactor > 1 || adaptive.MaxFactor < 1 {
+		return fmt.Errorf("cache.adaptive factors must satisfy 0 < min_factor <= 1 <= max_factor")
+	}
+	if adaptive.MinTTL < 0 || adaptive.MaxTTL < 0 || (adaptive.MaxTTL > 0 && adaptive.MaxTTL < adaptive.MinTTL) {
+		return fmt.Errorf("invalid cache.adaptive min_ttl/max_ttl")
+	}
+	return nil
+}
+
+// validateTelegram 启用Telegram推送时检查机器人令牌、聊天ID和消息模板
+func validateTelegram(telegram *Telegram) error {
+	if !telegram.Enabled {
+		return nil
+	}
+	if telegram.BotToken == "" || telegram.ChatID == "" {
+		return fmt.Errorf("notifier.telegram.bot_token and chat_id are required when telegram is enabled")
+	}
+	switch telegram.ParseMode {
+	case "", "HTML", "MarkdownV2":
+	default:
+		return fmt.Errorf("invalid notifier.telegram.parse_mode: %s", telegram.ParseMode)
+	}
+	if telegram.Timeout < 0 {
+		return fmt.Errorf("notifier.telegram.timeout must not be negative")
+	}
+	for name, text := range telegram.Templates {
+		if _, err := template.New(name).Parse(text); err != nil {
+			return fmt.Errorf("invalid notifier.telegram.templates.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateEmail 启用邮件告警时检查SMTP服务器、收发件人和邮件模板
+func validateEmail(email *EmailNotifications) error {
+	if !email.Enabled {
+		return nil
+	}
+	if email.Host == "" || email.Port <= 0 || email.Port > 65535 {
+		return fmt.Errorf("notifications.email.host and a valid port are required when email is enabled")
+	}
+	if _, err := mail.ParseAddress(email.From); err != nil {
+		return fmt.Errorf("invalid notifications.email.from: %w", err)
+	}
+	if len(email.To) == 0 {
+		return fmt.Errorf("notifications.email.to must not be empty when email is enabled")
+	}
+	for _, to := range email.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid notifications.email.to %q: %w", to, err)
+		}
+	}
+	switch email.TLS {
+	case "", "starttls", "tls", "none":
+	default:
+		return fmt.Errorf("invalid notifications.email.tls: %s", email.TLS)
+	}
+	if email.Timeout < 0 {
+		return fmt.Errorf("notifications.email.timeout must not be negative")
+	}
+	for name, text := range email.Templates {
+		if _, err := htmltemplate.New(name).Parse(text); err != nil {
+			return fmt.Errorf("invalid notifications.email.templates.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateDiscord 启用Discord推送时检查webhook地址
+func validateDiscord(discord *DiscordNotifications) error {
+	if !discord.Enabled {
+		return nil
+	}
+	u, err := url.Parse(discord.WebhookURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("notifications.discord.webhook_url must be an https url when discord is enabled")
+	}
+	if discord.ExplorerURL != "" {
+		if u, err := url.Parse(discord.ExplorerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid notifications.discord.explorer_url: %s", discord.ExplorerURL)
+		}
+	}
+	if discord.Timeout < 0 {
+		return fmt.Errorf("notifications.discord.timeout must not be negative")
+	}
 	return nil
 }
 
@@ -354,11 +1226,73 @@ func (c *Config) GetHTTPAddr() string {
 	return fmt.Sprintf("%s:%d", c.Server.HTTP.Host, c.Server.HTTP.Port)
 }
 
+// GetMetricsAddr 获取探针与指标服务地址
+func (c *Config) GetMetricsAddr() string {
+	return fmt.Sprintf("%s:%d", c.Monitoring.Metrics.Host, c.Monitoring.Metrics.Port)
+}
+
 // GetGRPCAddr 获取GRPC服务地址
 func (c *Config) GetGRPCAddr() string {
 	return fmt.Sprintf("%s:%d", c.Server.GRPC.Host, c.Server.GRPC.Port)
 }
 
+// TrustedProxyNets 解析可信反向代理列表，单个IP视为只包含该地址的网段
+func (c *Config) TrustedProxyNets() ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(c.Server.HTTP.TrustedProxies))
+	for _, proxy := range c.Server.HTTP.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid server.http.trusted_proxies entry: %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server.http.trusted_proxies entry: %q", proxy)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// defaultCacheKeyPrefix 默认缓存key前缀
+const defaultCacheKeyPrefix = "crypto-info:{env}:"
+
+// CacheKeyPrefix 获取解析后的缓存key前缀
+func (c *Config) CacheKeyPrefix() string {
+	prefix := c.Cache.KeyPrefix
+	if prefix == "" {
+		prefix = defaultCacheKeyPrefix
+	}
+	return strings.ReplaceAll(prefix, "{env}", c.App.Env)
+}
+
+// defaultRequestTimeout 默认请求处理时间上限
+const defaultRequestTimeout = 30 * time.Second
+
+// HTTPRequestTimeout 获取HTTP请求处理时间上限
+func (c *Config) HTTPRequestTimeout() time.Duration {
+	if c.Server.HTTP.RequestTimeout > 0 {
+		return c.Server.HTTP.RequestTimeout
+	}
+	return defaultRequestTimeout
+}
+
+// GRPCRequestTimeout 获取gRPC调用处理时间上限
+func (c *Config) GRPCRequestTimeout() time.Duration {
+	if c.Server.GRPC.Timeout > 0 {
+		return c.Server.GRPC.Timeout
+	}
+	return defaultRequestTimeout
+}
+
 // IsProduction 是否为生产环境
 func (c *Config) IsProduction() bool {
 	return c.App.Env == "production"
@@ -367,4 +1301,4 @@ func (c *Config) IsProduction() bool {
 // IsDevelopment 是否为开发环境
 func (c *Config) IsDevelopment() bool {
 	return c.App.Env == "development"
-}
\ No newline at end of file
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"crypto-info/configs"
+
+	"github.com/spf13/viper"
+)
+
+// 配置来源类型
+const (
+	SourceFile     = "file"     // 基础配置文件
+	SourceProfile  = "profile"  // 环境配置文件 {env}.yaml
+	SourceEmbedded = "embedded" // 找不到配置文件时使用的内置配置，路径为 configs 目录中的文件名
+	SourceEnv      = "env"      // CRYPTO_ 前缀的环境变量
+)
+
+// envPrefix 覆盖配置的环境变量前缀，配置项 a.b_c 对应 CRYPTO_A_B_C
+const envPrefix = "CRYPTO"
+
+// redactedValue 敏感配置在导出时的替代值
+const redactedValue = "******"
+
+// 未指定配置文件时的查找目录和基础配置文件名，按顺序使用第一个存在的文件；都不存在时使用内置的 config.yaml
+var (
+	configSearchDirs = []string{"./configs", "../configs", "/etc/crypto-info"}
+	baseConfigNames  = []string{"base.yaml", "config.yaml"}
+)
+
+// Precedence 配置优先级说明，从低到高
+var Precedence = []string{
+	SourceFile + ": 基础配置文件(-config 指定，或配置目录中的 base.yaml/config.yaml)，都不存在时使用内置的 config.yaml(" + SourceEmbedded + ")",
+	SourceProfile + ": 与基础配置文件同目录的 {app.env}.yaml，覆盖基础配置；使用内置配置时为内置的 {app.env}.yaml",
+	SourceEnv + ": CRYPTO_ 前缀的环境变量，覆盖所有配置文件，CRYPTO_APP_ENV 同时决定加载的环境配置文件",
+}
+
+// sensitiveKeys 导出时隐藏值的配置项名称
+var sensitiveKeys = map[string]bool{
+	"password":          true,
+	"secret":            true,
+	"key":               true,
+	"api_key":           true,
+	"api_keys":          true,
+	"token":             true,
+	"bot_token":         true,
+	"webhook_url":       true,
+	"secret_access_key": true,
+}
+
+// Source 一层配置来源
+type Source struct {
+	Kind string   `json:"kind"`           // file/profile/embedded/env
+	Path string   `json:"path,omitempty"` // 配置文件的绝对路径，内置配置为文件名
+	Vars []string `json:"vars,omitempty"` // 生效的环境变量名，不含值
+}
+
+// Profile 配置的加载过程，用于排查配置来源
+type Profile struct {
+	Env        string                 `json:"env"`        // 选择环境配置文件使用的环境名
+	Precedence []string               `json:"precedence"` // 优先级说明，从低到高
+	Sources    []Source               `json:"sources"`    // 实际生效的来源，从低到高
+	Settings   map[string]interface{} `json:"settings"`   // 合并后的配置，敏感值已隐藏
+}
+
+// resolveBasePath 确定基础配置文件，指定路径时直接使用，否则在配置目录中查找，找不到时返回空
+func resolveBasePath(configPath string) (string, error) {
+	if configPath != "" {
+		if _, err := os.Stat(configPath); err != nil {
+			return "", fmt.Errorf("failed to read config file: %w", err)
+		}
+		return filepath.Abs(configPath)
+	}
+
+	for _, dir := range configSearchDirs {
+		for _, name := range baseConfigNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return filepath.Abs(path)
+			}
+		}
+	}
+	return "", nil
+}
+
+// loadProfile 按优先级加载基础配置、环境配置文件和环境变量
+//
+// 环境配置文件总是从基础配置文件所在目录查找，与工作目录和查找顺序无关；
+// 环境名取合并环境变量后的 app.env，因此 CRYPTO_APP_ENV 可以切换环境配置文件。
+// 未指定配置文件且配置目录中没有配置文件时使用内置配置，所有配置项都可以通过环境变量覆盖。
+func loadProfile(configPath string) (*viper.Viper, *Profile, error) {
+	basePath, err := resolveBasePath(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := viper.New()
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	// AutomaticEnv只对配置文件中出现过的配置项生效，逐项绑定使文件中没有的配置项也能通过环境变量设置
+	bindEnvKeys(v, reflect.TypeOf(Config{}), "")
+	v.SetConfigType("yaml")
+
+	profile := &Profile{Precedence: Precedence}
+	if basePath == "" {
+		if err := loadEmbedded(v, profile); err != nil {
+			return nil, nil, err
+		}
+	} else if err := loadFiles(v, profile, basePath); err != nil {
+		return nil, nil, err
+	}
+
+	if vars := envOverrides(v); len(vars) > 0 {
+		profile.Sources = append(profile.Sources, Source{Kind: SourceEnv, Vars: vars})
+	}
+	profile.Settings = redactSettings(v.AllSettings())
+	return v, profile, nil
+}
+
+// loadFiles 读取基础配置文件和同目录的环境配置文件
+func loadFiles(v *viper.Viper, profile *Profile, basePath string) error {
+	v.SetConfigFile(basePath)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	profile.Sources = append(profile.Sources, Source{Kind: SourceFile, Path: basePath})
+
+	profile.Env = v.GetString("app.env")
+	if profile.Env == "" {
+		return nil
+	}
+	envPath := filepath.Join(filepath.Dir(basePath), profile.Env+".yaml")
+	if _, err := os.Stat(envPath); err != nil || envPath == basePath {
+		return nil
+	}
+	v.SetConfigFile(envPath)
+	if err := v.MergeInConfig(); err != nil {
+		return fmt.Errorf("failed to merge env config %s: %w", envPath, err)
+	}
+	profile.Sources = append(profile.Sources, Source{Kind: SourceProfile, Path: envPath})
+	return nil
+}
+
+// loadEmbedded 读取内置的基础配置和环境配置
+func loadEmbedded(v *viper.Viper, profile *Profile) error {
+	const baseName = "config.yaml"
+	data, err := configs.Files.ReadFile(baseName)
+	if err != nil {
+		return fmt.Errorf("failed to read embedded config: %w", err)
+	}
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to read embedded config: %w", err)
+	}
+	profile.Sources = append(profile.Sources, Source{Kind: SourceEmbedded, Path: baseName})
+
+	profile.Env = v.GetString("app.env")
+	if profile.Env == "" {
+		return nil
+	}
+	envName := profile.Env + ".yaml"
+	data, err = configs.Files.ReadFile(envName)
+	if err != nil || envName == baseName {
+		return nil
+	}
+	if err := v.MergeConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to merge embedded env config %s: %w", envName, err)
+	}
+	profile.Sources = append(profile.Sources, Source{Kind: SourceEmbedded, Path: envName})
+	return nil
+}
+
+// bindEnvKeys 按mapstructure标签遍历配置结构，为每个配置项绑定 CRYPTO_ 前缀的环境变量
+// 结构体列表和map无法用单个环境变量表示，不做绑定；基本类型的列表使用逗号分隔
+func bindEnvKeys(v *viper.Viper, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		key := prefix + name
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			bindEnvKeys(v, field.Type, key+".")
+		case reflect.Map:
+		case reflect.Slice:
+			if field.Type.Elem().Kind() != reflect.Struct {
+				v.BindEnv(key)
+			}
+		default:
+			v.BindEnv(key)
+		}
+	}
+}
+
+// envOverrides 覆盖了配置项的环境变量名，按名称排序
+func envOverrides(v *viper.Viper) []string {
+	var vars []string
+	for _, key := range v.AllKeys() {
+		name := envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if _, ok := os.LookupEnv(name); ok {
+			vars = append(vars, name)
+		}
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// redactSettings 复制配置并隐藏密码、密钥和URL中可能包含的凭据
+func redactSettings(settings map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		switch {
+		case sensitiveKeys[key]:
+			if !isEmptySetting(value) {
+				value = redactedValue
+			}
+		case key == "url" || strings.HasSuffix(key, "_url"):
+			if s, ok := value.(string); ok {
+				value = redactURL(s)
+			}
+		default:
+			value = redactValue(value)
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// redactValue 递归处理嵌套配置和列表
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactSettings(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = redactValue(item)
+		}
+		return items
+	default:
+		return value
+	}
+}
+
+// redactURL 只保留URL的协议和主机，路径、参数和用户信息中常带有令牌
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		if raw == "" {
+			return raw
+		}
+		return redactedValue
+	}
+	if u.User == nil && u.RawQuery == "" && (u.Path == "" || u.Path == "/") {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host + "/" + redactedValue
+}
+
+// isEmptySetting 未配置的敏感项原样导出，便于确认是否已设置
+func isEmptySetting(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfigDir 在临时目录中写入配置文件，返回基础配置文件路径
+func writeConfigDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return filepath.Join(dir, "base.yaml")
+}
+
+const testBaseConfig = `
+app:
+  name: "crypto-info"
+  env: "staging"
+server:
+  http:
+    port: 8080
+  grpc:
+    port: 9090
+log:
+  level: "debug"
+  format: "text"
+  output: "stdout"
+`
+
+// TestProfilePrecedence 基础配置 < {env}.yaml < CRYPTO_ 环境变量
+func TestProfilePrecedence(t *testing.T) {
+	basePath := writeConfigDir(t, map[string]string{
+		"base.yaml": testBaseConfig,
+		"staging.yaml": `
+log:
+  level: "info"
+  format: "json"
+`,
+	})
+	t.Setenv("CRYPTO_LOG_LEVEL", "warn")
+
+	cfg, err := Load(basePath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if cfg.Log.Output != "stdout" {
+		t.Errorf("log.output = %q, want base value stdout", cfg.Log.Output)
+	}
+	if cfg.Log.Format != "json" {
+		t.Errorf("log.format = %q, want staging.yaml value json", cfg.Log.Format)
+	}
+	if cfg.Log.Level != "warn" {
+		t.Errorf("log.level = %q, want env value warn", cfg.Log.Level)
+	}
+
+	profile := cfg.Profile()
+	if profile.Env != "staging" {
+		t.Errorf("profile env = %q, want staging", profile.Env)
+	}
+	kinds := make([]string, 0, len(profile.Sources))
+	for _, source := range profile.Sources {
+		kinds = append(kinds, source.Kind)
+	}
+	want := []string{SourceFile, SourceProfile, SourceEnv}
+	if len(kinds) != len(want) {
+		t.Fatalf("sources = %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("sources = %v, want %v", kinds, want)
+		}
+	}
+	if vars := profile.Sources[2].Vars; len(vars) != 1 || vars[0] != "CRYPTO_LOG_LEVEL" {
+		t.Errorf("env vars = %v, want [CRYPTO_LOG_LEVEL]", vars)
+	}
+}
+
+// TestProfileEnvSwitch CRYPTO_APP_ENV 决定加载的环境配置文件
+func TestProfileEnvSwitch(t *testing.T) {
+	basePath := writeConfigDir(t, map[string]string{
+		"base.yaml": testBaseConfig,
+		"staging.yaml": `
+log:
+  level: "info"
+`,
+		"production.yaml": `
+log:
+  level: "error"
+`,
+	})
+	t.Setenv("CRYPTO_APP_ENV", "production")
+
+	cfg, err := Load(basePath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if cfg.App.Env != "production" {
+		t.Errorf("app.env = %q, want production", cfg.App.Env)
+	}
+	if cfg.Log.Level != "error" {
+		t.Errorf("log.level = %q, want production.yaml value error", cfg.Log.Level)
+	}
+	profile := cfg.Profile()
+	if profile.Env != "production" {
+		t.Errorf("profile env = %q, want production", profile.Env)
+	}
+	for _, source := range profile.Sources {
+		if source.Kind == SourceProfile && filepath.Base(source.Path) != "production.yaml" {
+			t.Errorf("loaded env config %s, want production.yaml", source.Path)
+		}
+	}
+}
+
+// TestProfileEnvWithoutFileKey 配置文件中没有写出的配置项也能通过环境变量设置
+func TestProfileEnvWithoutFileKey(t *testing.T) {
+	basePath := writeConfigDir(t, map[string]string{"base.yaml": testBaseConfig})
+	t.Setenv("CRYPTO_EXTERNAL_API_BINANCE_API_KEY", "binance-key")
+	t.Setenv("CRYPTO_EXTERNAL_API_COINGECKO_API_KEYS", "key-a,key-b")
+
+	cfg, err := Load(basePath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if cfg.ExternalAPI.Binance.APIKey != "binance-key" {
+		t.Errorf("external_api.binance.api_key = %q, want binance-key", cfg.ExternalAPI.Binance.APIKey)
+	}
+	if keys := cfg.ExternalAPI.CoinGecko.APIKeys; len(keys) != 2 || keys[0] != "key-a" || keys[1] != "key-b" {
+		t.Errorf("external_api.coingecko.api_keys = %v, want [key-a key-b]", keys)
+	}
+}
@@ -0,0 +1,132 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	healthv1 "crypto-info/kitex_gen/grpc/health/v1"
+
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/codes"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/status"
+)
+
+// HealthServiceImpl 标准gRPC健康检查实现(grpc.health.v1.Health)，供grpc_health_probe、Kubernetes探针和服务网格使用
+type HealthServiceImpl struct {
+	mu       sync.RWMutex
+	statuses map[string]healthv1.HealthCheckResponse_ServingStatus
+	watchers map[string]map[chan healthv1.HealthCheckResponse_ServingStatus]struct{}
+	shutdown bool
+}
+
+// NewHealthService 创建健康检查实现，空服务名代表整个服务器，初始状态为SERVING
+func NewHealthService() *HealthServiceImpl {
+	return &HealthServiceImpl{
+		statuses: map[string]healthv1.HealthCheckResponse_ServingStatus{
+			"": healthv1.HealthCheckResponse_SERVING,
+		},
+		watchers: make(map[string]map[chan healthv1.HealthCheckResponse_ServingStatus]struct{}),
+	}
+}
+
+// Check 查询服务状态，未注册的服务返回NotFound
+func (s *HealthServiceImpl) Check(ctx context.Context, req *healthv1.HealthCheckRequest) (*healthv1.HealthCheckResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	servingStatus, ok := s.statuses[req.GetService()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &healthv1.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+// Watch 订阅服务状态，立即推送当前状态，之后每次变化推送一次，未注册的服务推送SERVICE_UNKNOWN
+func (s *HealthServiceImpl) Watch(req *healthv1.HealthCheckRequest, stream healthv1.Health_WatchServer) error {
+	service := req.GetService()
+	// 容量为1，只保留最新状态，慢速订阅者不会阻塞状态更新
+	updates := make(chan healthv1.HealthCheckResponse_ServingStatus, 1)
+
+	s.mu.Lock()
+	if s.watchers[service] == nil {
+		s.watchers[service] = make(map[chan healthv1.HealthCheckResponse_ServingStatus]struct{})
+	}
+	s.watchers[service][updates] = struct{}{}
+	current, ok := s.statuses[service]
+	if !ok {
+		current = healthv1.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+	updates <- current
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers[service], updates)
+		if len(s.watchers[service]) == 0 {
+			delete(s.watchers, service)
+		}
+		s.mu.Unlock()
+	}()
+
+	var last healthv1.HealthCheckResponse_ServingStatus = -1
+	for {
+		select {
+		case servingStatus := <-updates:
+			if servingStatus == last {
+				continue
+			}
+			if err := stream.Send(&healthv1.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+			last = servingStatus
+		case <-stream.Context().Done():
+			return status.Errorf(codes.Canceled, "stream has ended")
+		}
+	}
+}
+
+// Statuses 返回所有服务的当前状态
+func (s *HealthServiceImpl) Statuses() map[string]healthv1.HealthCheckResponse_ServingStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make(map[string]healthv1.HealthCheckResponse_ServingStatus, len(s.statuses))
+	for service, servingStatus := range s.statuses {
+		statuses[service] = servingStatus
+	}
+	return statuses
+}
+
+// SetServingStatus 设置服务状态并通知订阅者，关闭后忽略除NOT_SERVING外的更新
+func (s *HealthServiceImpl) SetServingStatus(service string, servingStatus healthv1.HealthCheckResponse_ServingStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shutdown && servingStatus != healthv1.HealthCheckResponse_NOT_SERVING {
+		return
+	}
+	s.setServingStatusLocked(service, servingStatus)
+}
+
+// Shutdown 将所有服务置为NOT_SERVING，让负载均衡在服务器停止前摘除流量
+func (s *HealthServiceImpl) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.shutdown = true
+	for service := range s.statuses {
+		s.setServingStatusLocked(service, healthv1.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// setServingStatusLocked 更新状态并推送给订阅者，调用方需持有写锁
+func (s *HealthServiceImpl) setServingStatusLocked(service string, servingStatus healthv1.HealthCheckResponse_ServingStatus) {
+	s.statuses[service] = servingStatus
+	for updates := range s.watchers[service] {
+		// 丢弃尚未发送的旧状态，保证订阅者收到最新状态
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- servingStatus
+	}
+}
@@ -0,0 +1,170 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"crypto-info/idl"
+	reflectionv1alpha "crypto-info/kitex_gen/grpc/reflection/v1alpha"
+
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/codes"
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+)
+
+// ReflectionServiceImpl 标准gRPC服务反射实现(grpc.reflection.v1alpha.ServerReflection)，供grpcurl等工具查询接口定义
+//
+// Kitex生成的代码不注册protobuf描述符，文件描述在启动时由内嵌的IDL源文件解析得到。
+type ReflectionServiceImpl struct {
+	files    map[string]*desc.FileDescriptor
+	services []string
+}
+
+// NewReflectionService 解析内嵌IDL并创建反射实现，services为服务器上注册的服务全名
+func NewReflectionService(services ...string) (*ReflectionServiceImpl, error) {
+	var names []string
+	err := fs.WalkDir(idl.Files, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".proto") {
+			names = append(names, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list idl files: %w", err)
+	}
+
+	parser := protoparse.Parser{
+		Accessor: func(filename string) (io.ReadCloser, error) {
+			return idl.Files.Open(filename)
+		},
+	}
+	parsed, err := parser.ParseFiles(names...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse idl files: %w", err)
+	}
+
+	s := &ReflectionServiceImpl{
+		files:    make(map[string]*desc.FileDescriptor, len(parsed)),
+		services: services,
+	}
+	for _, fd := range parsed {
+		s.files[fd.GetName()] = fd
+	}
+	for _, service := range services {
+		if s.fileContainingSymbol(service) == nil {
+			return nil, fmt.Errorf("service %s is not defined in idl", service)
+		}
+	}
+	return s, nil
+}
+
+// ServerReflectionInfo 处理反射请求流，每个请求对应一个响应
+func (s *ReflectionServiceImpl) ServerReflectionInfo(stream reflectionv1alpha.ServerReflection_ServerReflectionInfoServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp := &reflectionv1alpha.ServerReflectionResponse{
+			ValidHost:       req.GetHost(),
+			OriginalRequest: req,
+		}
+		switch r := req.GetMessageRequest().(type) {
+		case *reflectionv1alpha.ServerReflectionRequest_FileByFilename:
+			s.setFileDescriptorResponse(resp, s.files[r.FileByFilename], fmt.Sprintf("file %s not found", r.FileByFilename))
+		case *reflectionv1alpha.ServerReflectionRequest_FileContainingSymbol:
+			s.setFileDescriptorResponse(resp, s.fileContainingSymbol(r.FileContainingSymbol), fmt.Sprintf("symbol %s not found", r.FileContainingSymbol))
+		case *reflectionv1alpha.ServerReflectionRequest_FileContainingExtension:
+			// IDL未定义扩展字段
+			resp.MessageResponse = errorResponse(codes.NotFound, "extension %d of %s not found",
+				r.FileContainingExtension.GetExtensionNumber(), r.FileContainingExtension.GetContainingType())
+		case *reflectionv1alpha.ServerReflectionRequest_AllExtensionNumbersOfType:
+			if fd := s.fileContainingSymbol(r.AllExtensionNumbersOfType); fd == nil || fd.FindMessage(r.AllExtensionNumbersOfType) == nil {
+				resp.MessageResponse = errorResponse(codes.NotFound, "type %s not found", r.AllExtensionNumbersOfType)
+			} else {
+				resp.MessageResponse = &reflectionv1alpha.ServerReflectionResponse_AllExtensionNumbersResponse{
+					AllExtensionNumbersResponse: &reflectionv1alpha.ExtensionNumberResponse{BaseTypeName: r.AllExtensionNumbersOfType},
+				}
+			}
+		case *reflectionv1alpha.ServerReflectionRequest_ListServices:
+			list := &reflectionv1alpha.ListServiceResponse{}
+			for _, service := range s.services {
+				list.Service = append(list.Service, &reflectionv1alpha.ServiceResponse{Name: service})
+			}
+			resp.MessageResponse = &reflectionv1alpha.ServerReflectionResponse_ListServicesResponse{ListServicesResponse: list}
+		default:
+			resp.MessageResponse = errorResponse(codes.InvalidArgument, "invalid message_request %v", req.GetMessageRequest())
+		}
+
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// fileContainingSymbol 查找定义了指定全名(包、服务、方法、消息或枚举)的文件
+func (s *ReflectionServiceImpl) fileContainingSymbol(symbol string) *desc.FileDescriptor {
+	symbol = strings.TrimPrefix(symbol, ".")
+	if symbol == "" {
+		return nil
+	}
+	for _, fd := range s.files {
+		if fd.FindSymbol(symbol) != nil {
+			return fd
+		}
+	}
+	return nil
+}
+
+// setFileDescriptorResponse 返回文件及其全部依赖的序列化描述，fd为空时返回NotFound
+func (s *ReflectionServiceImpl) setFileDescriptorResponse(resp *reflectionv1alpha.ServerReflectionResponse, fd *desc.FileDescriptor, notFound string) {
+	if fd == nil {
+		resp.MessageResponse = errorResponse(codes.NotFound, "%s", notFound)
+		return
+	}
+
+	var encoded [][]byte
+	seen := make(map[string]bool)
+	queue := []*desc.FileDescriptor{fd}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if seen[current.GetName()] {
+			continue
+		}
+		seen[current.GetName()] = true
+
+		b, err := proto.Marshal(current.AsFileDescriptorProto())
+		if err != nil {
+			resp.MessageResponse = errorResponse(codes.Internal, "failed to encode %s: %v", current.GetName(), err)
+			return
+		}
+		encoded = append(encoded, b)
+		queue = append(queue, current.GetDependencies()...)
+	}
+
+	resp.MessageResponse = &reflectionv1alpha.ServerReflectionResponse_FileDescriptorResponse{
+		FileDescriptorResponse: &reflectionv1alpha.FileDescriptorResponse{FileDescriptorProto: encoded},
+	}
+}
+
+// errorResponse 构造反射错误响应
+func errorResponse(code codes.Code, format string, args ...interface{}) *reflectionv1alpha.ServerReflectionResponse_ErrorResponse {
+	return &reflectionv1alpha.ServerReflectionResponse_ErrorResponse{
+		ErrorResponse: &reflectionv1alpha.ErrorResponse{
+			ErrorCode:    int32(code),
+			ErrorMessage: fmt.Sprintf(format, args...),
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActivityHandler 地址动态处理器
+type ActivityHandler struct {
+	activityService service.ActivityService
+}
+
+// NewActivityHandler 创建地址动态处理器
+func NewActivityHandler(activityService service.ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		activityService: activityService,
+	}
+}
+
+// GetActivity 获取地址动态
+// @Summary 获取地址动态
+// @Description 合并BNB转账、代币转账、兑换和授权，按时间倒序分页返回带可读描述的动态
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Param address path string true "地址或域名，如alice.bnb"
+// @Param type query string false "动态类型，逗号分隔(native_transfer,token_transfer,swap,approval,contract_call)"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} model.AddressActivityResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/bsc/address/{address}/activity [get]
+func (h *ActivityHandler) GetActivity(c *gin.Context) {
+	address := c.Param("address")
+	log := logger.From(c)
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid page")
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid page_size")
+		return
+	}
+
+	var types []string
+	if typeStr := c.Query("type"); typeStr != "" {
+		for _, t := range strings.Split(typeStr, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+	}
+
+	activity, err := h.activityService.GetActivity(c.Request.Context(), address, types, page, pageSize)
+	if err != nil {
+		log.Errorf("Failed to get address activity: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取地址动态失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, activity)
+}
+
+// respondWithSuccess 成功响应
+func (h *ActivityHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *ActivityHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,480 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AlertHandler 告警处理器
+type AlertHandler struct {
+	notifier        service.Notifier
+	scheduledAlerts service.ScheduledAlertService
+	rules           service.AlertRuleService
+}
+
+// NewAlertHandler 创建告警处理器
+func NewAlertHandler(notifier service.Notifier, scheduledAlerts service.ScheduledAlertService, rules service.AlertRuleService) *AlertHandler {
+	return &AlertHandler{
+		notifier:        notifier,
+		scheduledAlerts: scheduledAlerts,
+		rules:           rules,
+	}
+}
+
+// ListAlerts 获取最近的告警
+// @Summary 获取最近的告警
+// @Description 获取系统产生的最近告警，按时间倒序
+// @Tags 告警
+// @Accept json
+// @Produce json
+// @Param type query string false "告警类型，如tvl_drop"
+// @Param limit query int false "返回数量" default(100)
+// @Success 200 {object} model.AlertListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/alerts/recent [get]
+func (h *AlertHandler) ListAlerts(c *gin.Context) {
+	alertType := c.Query("type")
+	log := logger.From(c)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid limit")
+		return
+	}
+
+	alerts, err := h.notifier.ListAlerts(c.Request.Context(), alertType, limit)
+	if err != nil {
+		log.Errorf("Failed to list alerts: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取告警失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, alerts)
+}
+
+// CreateScheduledAlert 创建定时通知
+// @Summary 创建定时通知
+// @Description 按cron表达式(分 时 日 月 周)定时推送币种价格，如每天9点: "0 9 * * *"，通知通过告警通道发送
+// @Tags 告警
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param request body model.ScheduledAlertRequest true "通知设置"
+// @Success 201 {object} model.ScheduledAlert
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/alerts/scheduled [post]
+func (h *AlertHandler) CreateScheduledAlert(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	var req model.ScheduledAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	alert, err := h.scheduledAlerts.CreateScheduledAlert(c.Request.Context(), userID, &req)
+	if err != nil {
+		logger.From(c).Errorf("Failed to create scheduled alert: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "创建定时通知失败", err.Error())
+		return
+	}
+
+	h.respondWithStatus(c, http.StatusCreated, alert)
+}
+
+// ListScheduledAlerts 获取定时通知列表
+// @Summary 获取定时通知列表
+// @Description 获取当前用户的定时通知，按创建时间排序
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Success 200 {object} model.ScheduledAlertListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/alerts/scheduled [get]
+func (h *AlertHandler) ListScheduledAlerts(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	alerts, err := h.scheduledAlerts.ListScheduledAlerts(c.Request.Context(), userID)
+	if err != nil {
+		logger.From(c).Errorf("Failed to list scheduled alerts: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取定时通知失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, alerts)
+}
+
+// GetScheduledAlert 获取定时通知
+// @Summary 获取定时通知
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "通知ID"
+// @Success 200 {object} model.ScheduledAlert
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/alerts/scheduled/{id} [get]
+func (h *AlertHandler) GetScheduledAlert(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	alert, err := h.scheduledAlerts.GetScheduledAlert(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		logger.From(c).Errorf("Failed to get scheduled alert: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取定时通知失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, alert)
+}
+
+// UpdateScheduledAlert 更新定时通知
+// @Summary 更新定时通知
+// @Description 替换通知的设置并重新计算下次执行时间，enabled为false时停用
+// @Tags 告警
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "通知ID"
+// @Param request body model.ScheduledAlertRequest true "通知设置"
+// @Success 200 {object} model.ScheduledAlert
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/alerts/scheduled/{id} [put]
+func (h *AlertHandler) UpdateScheduledAlert(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	var req model.ScheduledAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	alert, err := h.scheduledAlerts.UpdateScheduledAlert(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		logger.From(c).Errorf("Failed to update scheduled alert: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "更新定时通知失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, alert)
+}
+
+// DeleteScheduledAlert 删除定时通知
+// @Summary 删除定时通知
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "通知ID"
+// @Success 200 {object} model.APIResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/alerts/scheduled/{id} [delete]
+func (h *AlertHandler) DeleteScheduledAlert(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.scheduledAlerts.DeleteScheduledAlert(c.Request.Context(), userID, id); err != nil {
+		logger.From(c).Errorf("Failed to delete scheduled alert: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "删除定时通知失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, gin.H{"id": id})
+}
+
+// CreateRule 创建告警规则
+// @Summary 创建告警规则
+// @Description 条件为组合多个指标的表达式，支持AND、OR、NOT、比较和四则运算，如 price > 70000 AND (volume_ratio > 2 OR rsi < 30)。
+// @Description 可用指标: price、change_24h(%)、volume、volume_ratio(当天与前7天日均之比)、rsi(1小时K线RSI14)。条件由不成立变为成立时触发告警
+// @Tags 告警
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param request body model.AlertRuleRequest true "告警规则"
+// @Success 201 {object} model.AlertRule
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/alerts [post]
+func (h *AlertHandler) CreateRule(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	var req model.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	rule, err := h.rules.CreateRule(c.Request.Context(), userID, &req)
+	if err != nil {
+		logger.From(c).Errorf("Failed to create alert rule: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "创建告警规则失败", err.Error())
+		return
+	}
+
+	h.respondWithStatus(c, http.StatusCreated, rule)
+}
+
+// ListRules 获取告警规则列表
+// @Summary 获取告警规则列表
+// @Description 获取当前用户的告警规则及最近一次评估的结果
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Success 200 {object} model.AlertRuleListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/alerts [get]
+func (h *AlertHandler) ListRules(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	rules, err := h.rules.ListRules(c.Request.Context(), userID)
+	if err != nil {
+		logger.From(c).Errorf("Failed to list alert rules: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取告警规则失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, rules)
+}
+
+// GetRule 获取告警规则
+// @Summary 获取告警规则
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "规则ID"
+// @Success 200 {object} model.AlertRule
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/alerts/{id} [get]
+func (h *AlertHandler) GetRule(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	rule, err := h.rules.GetRule(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		logger.From(c).Errorf("Failed to get alert rule: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取告警规则失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, rule)
+}
+
+// UpdateRule 更新告警规则
+// @Summary 更新告警规则
+// @Description 替换规则的设置，条件或币种变化时重置触发状态
+// @Tags 告警
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "规则ID"
+// @Param request body model.AlertRuleRequest true "告警规则"
+// @Success 200 {object} model.AlertRule
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/alerts/{id} [put]
+func (h *AlertHandler) UpdateRule(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	var req model.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	rule, err := h.rules.UpdateRule(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		logger.From(c).Errorf("Failed to update alert rule: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "更新告警规则失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, rule)
+}
+
+// DeleteRule 删除告警规则
+// @Summary 删除告警规则
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "规则ID"
+// @Success 200 {object} model.APIResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/alerts/{id} [delete]
+func (h *AlertHandler) DeleteRule(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.rules.DeleteRule(c.Request.Context(), userID, id); err != nil {
+		logger.From(c).Errorf("Failed to delete alert rule: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "删除告警规则失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, gin.H{"id": id})
+}
+
+// TestRule 试运行告警规则
+// @Summary 试运行告警规则
+// @Description 按当前数据评估规则并发送一条测试通知，用于确认通知渠道可用，不改变规则的触发状态
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "规则ID"
+// @Success 200 {object} model.AlertRuleTestResult
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/alerts/{id}/test [post]
+func (h *AlertHandler) TestRule(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	result, err := h.rules.TestRule(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		logger.From(c).Errorf("Failed to test alert rule: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "试运行告警规则失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, result)
+}
+
+// GetRuleHistory 获取告警规则的触发记录
+// @Summary 获取告警规则的触发记录
+// @Description 按时间倒序返回规则每次触发(含试运行)时的指标、通知渠道和投递结果
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "规则ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量，最大100" default(20)
+// @Success 200 {object} model.AlertFiringListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/alerts/{id}/history [get]
+func (h *AlertHandler) GetRuleHistory(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid page")
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid page_size")
+		return
+	}
+
+	history, err := h.rules.GetHistory(c.Request.Context(), userID, c.Param("id"), page, pageSize)
+	if err != nil {
+		logger.From(c).Errorf("Failed to get alert rule history: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取告警触发记录失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, history)
+}
+
+// respondWithSuccess 成功响应
+func (h *AlertHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithStatus 以指定状态码返回成功响应
+func (h *AlertHandler) respondWithStatus(c *gin.Context, statusCode int, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
+
+// respondWithError 错误响应
+func (h *AlertHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsHandler 使用统计处理器
+type AnalyticsHandler struct {
+	analyticsService service.AnalyticsService
+}
+
+// NewAnalyticsHandler 创建使用统计处理器
+func NewAnalyticsHandler(analyticsService service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// GetUsage 获取请求最多的币种和接口
+// @Summary 获取使用统计
+// @Description 获取最近hours小时内请求次数最多的币种和接口(路由模板)，统计为所有实例合计，只计入成功的请求；需在配置中启用analytics
+// @Tags 管理
+// @Produce json
+// @Param hours query int false "统计的小时数，含当前小时，默认24，不超过保留时间"
+// @Param limit query int false "返回的币种和接口数，默认20，最大200"
+// @Success 200 {object} model.UsageReport
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/admin/analytics/usage [get]
+func (h *AnalyticsHandler) GetUsage(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "0"))
+	if err != nil || hours < 0 {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid hours")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid limit")
+		return
+	}
+
+	report, err := h.analyticsService.TopUsage(c.Request.Context(), hours, limit)
+	if err != nil {
+		logger.From(c).Errorf("Failed to get usage analytics: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取使用统计失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, report)
+}
+
+// respondWithSuccess 成功响应
+func (h *AnalyticsHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *AnalyticsHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSnapshotSize 恢复时上传的快照大小上限
+const maxSnapshotSize = 64 << 20
+
+// BackupHandler 状态快照导出和恢复处理器
+type BackupHandler struct {
+	backupService service.BackupService
+}
+
+// NewBackupHandler 创建状态快照导出和恢复处理器
+func NewBackupHandler(backupService service.BackupService) *BackupHandler {
+	return &BackupHandler{
+		backupService: backupService,
+	}
+}
+
+// ExportState 导出Redis中的关键状态
+// @Summary 导出状态快照
+// @Description 导出代币注册表、告警、事件过滤器、交易记录、命名订阅和扫描进度等无法从上游重新获取的状态，返回的JSON可直接用于恢复
+// @Tags 管理
+// @Produce json
+// @Param categories query string false "逗号分隔的分类：tokens、alerts、event_filters、portfolios、subscriptions、checkpoints，为空时导出全部"
+// @Success 200 {object} model.StateSnapshot
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/admin/state/export [get]
+func (h *BackupHandler) ExportState(c *gin.Context) {
+	var categories []string
+	if categoriesStr := c.Query("categories"); categoriesStr != "" {
+		for _, category := range strings.Split(categoriesStr, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				categories = append(categories, category)
+			}
+		}
+	}
+
+	snapshot, err := h.backupService.Export(c.Request.Context(), categories)
+	if err != nil {
+		logger.From(c).Errorf("Failed to export state: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "导出状态快照失败", err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("crypto-info-state-%s.json", snapshot.CreatedAt.UTC().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// RestoreState 将状态快照恢复到当前Redis
+// @Summary 恢复状态快照
+// @Description 将导出的状态快照写入当前Redis(按当前的key前缀)，默认跳过已存在的key，overwrite=true时覆盖；快照中不属于所在分类的key会被拒绝
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Param overwrite query bool false "是否覆盖已存在的key" default(false)
+// @Param snapshot body model.StateSnapshot true "导出的状态快照"
+// @Success 200 {object} model.StateRestoreResult
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/admin/state/restore [post]
+func (h *BackupHandler) RestoreState(c *gin.Context) {
+	overwrite, err := strconv.ParseBool(c.DefaultQuery("overwrite", "false"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid overwrite")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSnapshotSize)
+	var snapshot model.StateSnapshot
+	if err := c.ShouldBindJSON(&snapshot); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	result, err := h.backupService.Restore(c.Request.Context(), &snapshot, overwrite)
+	if err != nil {
+		logger.From(c).Errorf("Failed to restore state: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "恢复状态快照失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, result)
+}
+
+// respondWithSuccess 成功响应
+func (h *BackupHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *BackupHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
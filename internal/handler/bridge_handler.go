@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultBridgeRange 未指定from时默认查询的时间范围
+const defaultBridgeRange = 24 * time.Hour
+
+// BridgeHandler 跨链桥活动处理器
+type BridgeHandler struct {
+	bridgeService service.BridgeService
+}
+
+// NewBridgeHandler 创建跨链桥活动处理器
+func NewBridgeHandler(bridgeService service.BridgeService) *BridgeHandler {
+	return &BridgeHandler{
+		bridgeService: bridgeService,
+	}
+}
+
+// GetActivity 获取跨链桥活动
+// @Summary 获取跨链桥活动
+// @Description 获取经由已知BSC跨链桥的转入/转出记录，以及各跨链桥的笔数和美元金额统计
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Param bridge query string false "跨链桥名称，为空时返回全部"
+// @Param direction query string false "方向(outbound,inbound)"
+// @Param from query string false "开始时间，RFC3339或Unix秒" default(24小时前)
+// @Param to query string false "结束时间，RFC3339或Unix秒" default(当前时间)
+// @Param limit query int false "返回的转账记录数" default(100)
+// @Success 200 {object} model.BridgeActivityResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/bsc/bridges/activity [get]
+func (h *BridgeHandler) GetActivity(c *gin.Context) {
+	bridge := c.Query("bridge")
+	direction := c.Query("direction")
+	log := logger.From(c)
+
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := parseTimeParam(toStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "无效的结束时间", err.Error())
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultBridgeRange)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := parseTimeParam(fromStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "无效的开始时间", err.Error())
+			return
+		}
+		from = parsed
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid limit")
+		return
+	}
+
+	log.Infof("Getting bridge activity for bridge: %s, direction: %s, from: %s, to: %s", bridge, direction, from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	activity, err := h.bridgeService.GetActivity(c.Request.Context(), bridge, direction, from, to, limit)
+	if err != nil {
+		log.Errorf("Failed to get bridge activity: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取跨链桥活动失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, activity)
+}
+
+// respondWithSuccess 成功响应
+func (h *BridgeHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *BridgeHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -4,6 +4,7 @@ import (
 	"math/big"
 	"net/http"
 	"strconv"
+	"time"
 
 	"crypto-info/internal/model"
 	"crypto-info/internal/pkg/logger"
@@ -13,17 +14,21 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// 处理量历史的默认时间范围
+const (
+	defaultBSCStatsHourRange = 24 * time.Hour
+	defaultBSCStatsDayRange  = 30 * 24 * time.Hour
+)
+
 // BSCHandler BSC处理器
 type BSCHandler struct {
 	bscService service.BSCService
-	logger     logger.Logger
 }
 
 // NewBSCHandler 创建BSC处理器
 func NewBSCHandler(bscService service.BSCService) *BSCHandler {
 	return &BSCHandler{
 		bscService: bscService,
-		logger:     logger.GetLogger(),
 	}
 }
 
@@ -37,9 +42,9 @@ func NewBSCHandler(bscService service.BSCService) *BSCHandler {
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/bsc/status [get]
 func (h *BSCHandler) GetStatus(c *gin.Context) {
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 
-	h.logger.WithField("request_id", requestID).Info("Getting BSC monitoring status")
+	log.Info("Getting BSC monitoring status")
 
 	status := h.bscService.GetStatus()
 	h.respondWithSuccess(c, status)
@@ -55,13 +60,13 @@ func (h *BSCHandler) GetStatus(c *gin.Context) {
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/bsc/block/latest [get]
 func (h *BSCHandler) GetLatestBlock(c *gin.Context) {
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 
-	h.logger.WithField("request_id", requestID).Info("Getting latest BSC block")
+	log.Info("Getting latest BSC block")
 
 	block, err := h.bscService.GetLatestBlock(c.Request.Context())
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get latest block: %v", err)
+		log.Errorf("Failed to get latest block: %v", err)
 		h.respondWithError(c, http.StatusInternalServerError, "获取最新区块失败", err.Error())
 		return
 	}
@@ -69,13 +74,14 @@ func (h *BSCHandler) GetLatestBlock(c *gin.Context) {
 	h.respondWithSuccess(c, block)
 }
 
-// GetTransactions 获取区块交易信息
-// @Summary 获取区块交易信息
-// @Description 获取指定区块的交易信息
+// GetTransactions 获取区块交易信息或地址交易历史
+// @Summary 获取区块交易信息或地址交易历史
+// @Description 获取指定区块的交易信息；提供address时返回该地址的交易历史，本地索引未就绪时回退到BscScan（source字段标明数据来源）
 // @Tags BSC
 // @Accept json
 // @Produce json
-// @Param block_number query string true "区块号"
+// @Param block_number query string false "区块号，与address二选一"
+// @Param address query string false "地址，与block_number二选一"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(20)
 // @Success 200 {object} model.BSCTransactionResponse
@@ -83,19 +89,14 @@ func (h *BSCHandler) GetLatestBlock(c *gin.Context) {
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/bsc/transactions [get]
 func (h *BSCHandler) GetTransactions(c *gin.Context) {
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 	blockNumberStr := c.Query("block_number")
+	addressStr := c.Query("address")
 	pageStr := c.DefaultQuery("page", "1")
 	pageSizeStr := c.DefaultQuery("page_size", "20")
 
-	if blockNumberStr == "" {
-		h.respondWithError(c, http.StatusBadRequest, "参数错误", "block_number is required")
-		return
-	}
-
-	blockNumber, ok := new(big.Int).SetString(blockNumberStr, 10)
-	if !ok {
-		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid block_number")
+	if blockNumberStr == "" && addressStr == "" {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "block_number or address is required")
 		return
 	}
 
@@ -109,12 +110,28 @@ func (h *BSCHandler) GetTransactions(c *gin.Context) {
 		pageSize = 20
 	}
 
-	h.logger.WithField("request_id", requestID).Infof("Getting transactions for block %s, page %d, pageSize %d", blockNumberStr, page, pageSize)
-
-	transactions, err := h.bscService.GetTransactions(c.Request.Context(), blockNumber, page, pageSize)
+	var transactions *model.BSCTransactionResponse
+	if blockNumberStr != "" {
+		blockNumber, ok := new(big.Int).SetString(blockNumberStr, 10)
+		if !ok {
+			h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid block_number")
+			return
+		}
+
+		log.Infof("Getting transactions for block %s, page %d, pageSize %d", blockNumberStr, page, pageSize)
+		transactions, err = h.bscService.GetTransactions(c.Request.Context(), blockNumber, page, pageSize)
+	} else {
+		if !common.IsHexAddress(addressStr) {
+			h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid address")
+			return
+		}
+
+		log.Infof("Getting transactions for address %s, page %d, pageSize %d", addressStr, page, pageSize)
+		transactions, err = h.bscService.GetAddressTransactions(c.Request.Context(), common.HexToAddress(addressStr), page, pageSize)
+	}
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get transactions: %v", err)
-		h.respondWithError(c, http.StatusInternalServerError, "获取交易信息失败", err.Error())
+		log.Errorf("Failed to get transactions: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取交易信息失败", err.Error())
 		return
 	}
 
@@ -123,7 +140,7 @@ func (h *BSCHandler) GetTransactions(c *gin.Context) {
 
 // GetTokenTransfers 获取代币转账记录
 // @Summary 获取代币转账记录
-// @Description 获取指定代币的转账记录
+// @Description 获取指定代币的转账记录，本地索引未就绪时回退到BscScan（source字段标明数据来源）
 // @Tags BSC
 // @Accept json
 // @Produce json
@@ -135,7 +152,7 @@ func (h *BSCHandler) GetTransactions(c *gin.Context) {
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/bsc/token/transfers [get]
 func (h *BSCHandler) GetTokenTransfers(c *gin.Context) {
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 	tokenAddressStr := c.Query("token_address")
 	pageStr := c.DefaultQuery("page", "1")
 	pageSizeStr := c.DefaultQuery("page_size", "20")
@@ -162,12 +179,12 @@ func (h *BSCHandler) GetTokenTransfers(c *gin.Context) {
 		pageSize = 20
 	}
 
-	h.logger.WithField("request_id", requestID).Infof("Getting token transfers for %s, page %d, pageSize %d", tokenAddressStr, page, pageSize)
+	log.Infof("Getting token transfers for %s, page %d, pageSize %d", tokenAddressStr, page, pageSize)
 
 	transfers, err := h.bscService.GetTokenTransfers(c.Request.Context(), tokenAddress, page, pageSize)
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get token transfers: %v", err)
-		h.respondWithError(c, http.StatusInternalServerError, "获取代币转账记录失败", err.Error())
+		log.Errorf("Failed to get token transfers: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取代币转账记录失败", err.Error())
 		return
 	}
 
@@ -188,7 +205,7 @@ func (h *BSCHandler) GetTokenTransfers(c *gin.Context) {
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/bsc/swap/events [get]
 func (h *BSCHandler) GetSwapEvents(c *gin.Context) {
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 	pairAddressStr := c.Query("pair_address")
 	pageStr := c.DefaultQuery("page", "1")
 	pageSizeStr := c.DefaultQuery("page_size", "20")
@@ -215,11 +232,11 @@ func (h *BSCHandler) GetSwapEvents(c *gin.Context) {
 		pageSize = 20
 	}
 
-	h.logger.WithField("request_id", requestID).Infof("Getting swap events for %s, page %d, pageSize %d", pairAddressStr, page, pageSize)
+	log.Infof("Getting swap events for %s, page %d, pageSize %d", pairAddressStr, page, pageSize)
 
 	swaps, err := h.bscService.GetSwapEvents(c.Request.Context(), pairAddress, page, pageSize)
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get swap events: %v", err)
+		log.Errorf("Failed to get swap events: %v", err)
 		h.respondWithError(c, http.StatusInternalServerError, "获取交换事件失败", err.Error())
 		return
 	}
@@ -239,7 +256,7 @@ func (h *BSCHandler) GetSwapEvents(c *gin.Context) {
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/bsc/pair/info [get]
 func (h *BSCHandler) GetPairInfo(c *gin.Context) {
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 	pairAddressStr := c.Query("pair_address")
 
 	if pairAddressStr == "" {
@@ -254,11 +271,11 @@ func (h *BSCHandler) GetPairInfo(c *gin.Context) {
 
 	pairAddress := common.HexToAddress(pairAddressStr)
 
-	h.logger.WithField("request_id", requestID).Infof("Getting pair info for %s", pairAddressStr)
+	log.Infof("Getting pair info for %s", pairAddressStr)
 
 	pairInfo, err := h.bscService.GetPairInfo(c.Request.Context(), pairAddress)
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get pair info: %v", err)
+		log.Errorf("Failed to get pair info: %v", err)
 		h.respondWithError(c, http.StatusInternalServerError, "获取交易对信息失败", err.Error())
 		return
 	}
@@ -266,9 +283,122 @@ func (h *BSCHandler) GetPairInfo(c *gin.Context) {
 	h.respondWithSuccess(c, pairInfo)
 }
 
+// GetStatsHistory 获取区块监控处理量历史
+// @Summary 获取区块监控处理量历史
+// @Description 按小时或天(UTC)返回区块监控处理的区块、交易、转账、兑换和流动性事件数量，用于容量规划和异常回溯；小时数据保留7天，天数据保留一年
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Param granularity query string false "时间粒度(hour/day)" default(hour)
+// @Param from query string false "开始时间，RFC3339或Unix秒" default(hour为24小时前，day为30天前)
+// @Param to query string false "结束时间，RFC3339或Unix秒" default(当前时间)
+// @Success 200 {object} model.BSCStatsHistoryResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/bsc/stats/history [get]
+func (h *BSCHandler) GetStatsHistory(c *gin.Context) {
+	log := logger.From(c)
+
+	granularity := c.DefaultQuery("granularity", service.BSCStatsGranularityHour)
+
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := parseTimeParam(toStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "无效的结束时间", err.Error())
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultBSCStatsHourRange)
+	if granularity == service.BSCStatsGranularityDay {
+		from = to.Add(-defaultBSCStatsDayRange)
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := parseTimeParam(fromStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "无效的开始时间", err.Error())
+			return
+		}
+		from = parsed
+	}
+
+	log.Infof("Getting BSC stats history, granularity: %s, from: %s, to: %s", granularity, from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	history, err := h.bscService.GetStatsHistory(c.Request.Context(), granularity, from, to)
+	if err != nil {
+		log.Errorf("Failed to get BSC stats history: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取处理量历史失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, history)
+}
+
+// GetCongestion 获取网络拥堵情况
+// @Summary 获取BSC网络拥堵情况
+// @Description 按最近区块的gasUsed/gasLimit利用率、平均Gas价格和待处理交易积压计算0~100的拥堵评分和等级(low/moderate/high/severe)；结果缓存bsc.congestion.cache_ttl，与/metrics中的crypto_info_bsc_congestion_*指标共用
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.BSCCongestion
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/bsc/network/congestion [get]
+func (h *BSCHandler) GetCongestion(c *gin.Context) {
+	log := logger.From(c)
+
+	log.Info("Getting BSC network congestion")
+
+	congestion, err := h.bscService.GetCongestion(c.Request.Context())
+	if err != nil {
+		log.Errorf("Failed to get BSC network congestion: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取网络拥堵情况失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, congestion)
+}
+
+// GetValidators 获取出块验证者统计
+// @Summary 获取出块验证者统计
+// @Description 统计包含最新区块的epoch及之前共epochs个epoch内各验证者(区块miner)的出块数、占比和最近出块的区块号；按活跃验证者平均分配估算期望出块数和漏块数，out_of_turn_blocks为非轮值验证者补出的区块数
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Param epochs query int false "统计的epoch数，不超过bsc.validators.max_epochs" default(1)
+// @Success 200 {object} model.BSCValidatorsResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/bsc/validators [get]
+func (h *BSCHandler) GetValidators(c *gin.Context) {
+	log := logger.From(c)
+
+	epochs := 0
+	if epochsStr := c.Query("epochs"); epochsStr != "" {
+		parsed, err := strconv.Atoi(epochsStr)
+		if err != nil || parsed < 1 {
+			h.respondWithError(c, http.StatusBadRequest, "参数错误", "epochs must be a positive integer")
+			return
+		}
+		epochs = parsed
+	}
+
+	log.Infof("Getting BSC validator stats, epochs: %d", epochs)
+
+	stats, err := h.bscService.GetValidatorStats(c.Request.Context(), epochs)
+	if err != nil {
+		log.Errorf("Failed to get BSC validator stats: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取验证者统计失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, stats)
+}
+
 // StartMonitoring 启动BSC监控
 // @Summary 启动BSC监控
-// @Description 启动BSC链上数据监控服务
+// @Description 启动或恢复BSC链上数据监控服务，从上次保存的检查点的下一个区块继续处理
 // @Tags BSC
 // @Accept json
 // @Produce json
@@ -276,26 +406,27 @@ func (h *BSCHandler) GetPairInfo(c *gin.Context) {
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/bsc/monitoring/start [post]
 func (h *BSCHandler) StartMonitoring(c *gin.Context) {
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 
-	h.logger.WithField("request_id", requestID).Info("Starting BSC monitoring")
+	log.Info("Starting BSC monitoring")
 
-	err := h.bscService.Start(c.Request.Context())
+	err := h.bscService.Resume(c.Request.Context())
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to start BSC monitoring: %v", err)
+		log.Errorf("Failed to start BSC monitoring: %v", err)
 		h.respondWithError(c, http.StatusInternalServerError, "启动BSC监控失败", err.Error())
 		return
 	}
 
 	h.respondWithSuccess(c, map[string]interface{}{
-		"message": "BSC monitoring started successfully",
-		"status":  "running",
+		"message":    "BSC monitoring started successfully",
+		"status":     "running",
+		"checkpoint": h.bscService.GetStatus().Stats.Checkpoint,
 	})
 }
 
 // StopMonitoring 停止BSC监控
 // @Summary 停止BSC监控
-// @Description 停止BSC链上数据监控服务
+// @Description 暂停BSC链上数据监控服务，等待当前批次处理完并保存检查点，进程重启后保持暂停，直到再次启动
 // @Tags BSC
 // @Accept json
 // @Produce json
@@ -303,20 +434,21 @@ func (h *BSCHandler) StartMonitoring(c *gin.Context) {
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/bsc/monitoring/stop [post]
 func (h *BSCHandler) StopMonitoring(c *gin.Context) {
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 
-	h.logger.WithField("request_id", requestID).Info("Stopping BSC monitoring")
+	log.Info("Stopping BSC monitoring")
 
-	err := h.bscService.Stop()
+	err := h.bscService.Pause()
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to stop BSC monitoring: %v", err)
+		log.Errorf("Failed to stop BSC monitoring: %v", err)
 		h.respondWithError(c, http.StatusInternalServerError, "停止BSC监控失败", err.Error())
 		return
 	}
 
 	h.respondWithSuccess(c, map[string]interface{}{
-		"message": "BSC monitoring stopped successfully",
-		"status":  "stopped",
+		"message":    "BSC monitoring paused successfully",
+		"status":     "paused",
+		"checkpoint": h.bscService.GetStatus().Stats.Checkpoint,
 	})
 }
 
@@ -332,4 +464,4 @@ func (h *BSCHandler) respondWithError(c *gin.Context, statusCode int, errorType,
 		Message: message,
 		Code:    statusCode,
 	})
-}
\ No newline at end of file
+}
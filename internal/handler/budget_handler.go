@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/budget"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BudgetHandler 外部API调用预算处理器
+type BudgetHandler struct {
+	tracker *budget.Tracker
+}
+
+// NewBudgetHandler 创建外部API调用预算处理器
+func NewBudgetHandler(tracker *budget.Tracker) *BudgetHandler {
+	return &BudgetHandler{
+		tracker: tracker,
+	}
+}
+
+// GetUsage 获取各外部提供方的调用次数和预算
+// @Summary 获取外部API调用预算
+// @Description 获取当前实例对各外部提供方的每小时、每天调用次数和预算，exceeded为true的提供方只返回缓存数据
+// @Tags 管理
+// @Produce json
+// @Success 200 {object} model.ProviderBudgetResponse
+// @Router /api/v1/admin/budgets [get]
+func (h *BudgetHandler) GetUsage(c *gin.Context) {
+	h.respondWithSuccess(c, &model.ProviderBudgetResponse{
+		Enabled:   h.tracker.Enabled(),
+		Providers: h.tracker.Usage(),
+	})
+}
+
+// respondWithSuccess 成功响应
+func (h *BudgetHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompareHandler 多币种对比处理器
+type CompareHandler struct {
+	compareService service.CompareService
+}
+
+// NewCompareHandler 创建多币种对比处理器
+func NewCompareHandler(compareService service.CompareService) *CompareHandler {
+	return &CompareHandler{
+		compareService: compareService,
+	}
+}
+
+// Compare 多币种指标对比
+// @Summary 多币种指标对比
+// @Description 一次返回多个加密货币的价格、交易量、波动率和收益率相关系数矩阵，单个币种失败时在errors中列出
+// @Tags 价格
+// @Accept json
+// @Produce json
+// @Param symbols query string true "加密货币符号列表，逗号分隔，最多20个" default(BTC,ETH)
+// @Param metrics query string false "对比指标，逗号分隔(price,volume,volatility,correlation)，默认全部"
+// @Param days query int false "统计天数" default(7)
+// @Param interval query string false "计算波动率和相关性的K线间隔(1m,5m,15m,30m,1h,4h,1d)，默认按天数选择"
+// @Success 200 {object} model.CompareResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/crypto/compare [get]
+func (h *CompareHandler) Compare(c *gin.Context) {
+	symbols := splitList(c.Query("symbols"), true)
+	metrics := splitList(c.Query("metrics"), false)
+	interval := c.Query("interval")
+	log := logger.From(c)
+
+	if len(symbols) == 0 {
+		h.respondWithError(c, http.StatusBadRequest, "缺少加密货币符号", "symbols is required")
+		return
+	}
+
+	days := 0 // 使用服务默认值
+	if daysStr := c.Query("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed <= 0 {
+			h.respondWithError(c, http.StatusBadRequest, "无效的统计天数", "days must be a positive integer")
+			return
+		}
+		days = parsed
+	}
+
+	log.Infof("Comparing symbols: %v, metrics: %v, days: %d, interval: %s", symbols, metrics, days, interval)
+
+	comparison, err := h.compareService.Compare(c.Request.Context(), symbols, metrics, days, interval)
+	if err != nil {
+		log.Errorf("Failed to compare symbols: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "多币种对比失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, comparison)
+}
+
+// respondWithSuccess 成功响应
+func (h *CompareHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *CompareHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigHandler 配置查看处理器
+type ConfigHandler struct {
+	config *config.Config
+}
+
+// NewConfigHandler 创建配置查看处理器
+func NewConfigHandler(cfg *config.Config) *ConfigHandler {
+	return &ConfigHandler{
+		config: cfg,
+	}
+}
+
+// GetConfig 获取生效的配置及其来源
+// @Summary 获取生效配置
+// @Description 返回合并后的配置、优先级说明和实际生效的配置文件与环境变量，密码、密钥和URL中的凭据已隐藏
+// @Tags 管理
+// @Produce json
+// @Success 200 {object} config.Profile
+// @Router /api/v1/admin/config [get]
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	profile := h.config.Profile()
+	if profile == nil {
+		// 未通过配置文件加载(如嵌入调用)时没有来源信息
+		profile = &config.Profile{Env: h.config.App.Env, Precedence: config.Precedence}
+	}
+	h.respondWithSuccess(c, profile)
+}
+
+// respondWithSuccess 成功响应
+func (h *ConfigHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorStatus 根据服务层错误确定HTTP状态码，命中负缓存时设置X-Cache响应头
+func errorStatus(c *gin.Context, err error) int {
+	var negErr *service.NegativeCacheError
+	if errors.As(err, &negErr) {
+		c.Header("X-Cache", "NEGATIVE")
+	}
+
+	switch {
+	case errors.Is(err, service.ErrInvalidParameter):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrUnsupportedSymbol), errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUpstreamUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// userIDFrom 获取当前请求的用户ID，来自X-User-ID请求头或会话
+func userIDFrom(c *gin.Context) string {
+	value, exists := c.Get("user_id")
+	if !exists || value == nil {
+		return ""
+	}
+	if userID, ok := value.(string); ok {
+		return userID
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventFilterHandler 用户事件过滤器处理器
+type EventFilterHandler struct {
+	filters service.EventFilterService
+}
+
+// NewEventFilterHandler 创建用户事件过滤器处理器
+func NewEventFilterHandler(filters service.EventFilterService) *EventFilterHandler {
+	return &EventFilterHandler{
+		filters: filters,
+	}
+}
+
+// CreateFilter 创建事件过滤器
+// @Summary 创建事件过滤器
+// @Description 订阅合约发出的指定事件：event为规范形式的事件签名(如 Transfer(address,address,uint256))或其32字节哈希，
+// @Description topics依次为topic1~topic3可接受的值(地址或32字节十六进制)，空数组表示不限制。
+// @Description 匹配的日志推送到该用户的WebSocket/SSE连接(事件类型event)，并POST到webhook_url(设置webhook_secret时带X-Signature头)
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param request body model.EventFilterRequest true "事件过滤器"
+// @Success 201 {object} model.EventFilter
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/bsc/filters [post]
+func (h *EventFilterHandler) CreateFilter(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	var req model.EventFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	filter, err := h.filters.CreateFilter(c.Request.Context(), userID, &req)
+	if err != nil {
+		logger.From(c).Errorf("Failed to create event filter: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "创建事件过滤器失败", err.Error())
+		return
+	}
+
+	h.respondWithStatus(c, http.StatusCreated, filter)
+}
+
+// ListFilters 获取事件过滤器列表
+// @Summary 获取事件过滤器列表
+// @Description 获取当前用户的事件过滤器及匹配次数，不返回webhook密钥
+// @Tags BSC
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Success 200 {object} model.EventFilterListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/bsc/filters [get]
+func (h *EventFilterHandler) ListFilters(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	filters, err := h.filters.ListFilters(c.Request.Context(), userID)
+	if err != nil {
+		logger.From(c).Errorf("Failed to list event filters: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取事件过滤器失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, filters)
+}
+
+// GetFilter 获取事件过滤器
+// @Summary 获取事件过滤器
+// @Tags BSC
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "过滤器ID"
+// @Success 200 {object} model.EventFilter
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/bsc/filters/{id} [get]
+func (h *EventFilterHandler) GetFilter(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	filter, err := h.filters.GetFilter(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		logger.From(c).Errorf("Failed to get event filter: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取事件过滤器失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, filter)
+}
+
+// UpdateFilter 更新事件过滤器
+// @Summary 更新事件过滤器
+// @Description 替换过滤器的设置，未带webhook_secret且webhook_url不变时保留原密钥
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "过滤器ID"
+// @Param request body model.EventFilterRequest true "事件过滤器"
+// @Success 200 {object} model.EventFilter
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/bsc/filters/{id} [put]
+func (h *EventFilterHandler) UpdateFilter(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	var req model.EventFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	filter, err := h.filters.UpdateFilter(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		logger.From(c).Errorf("Failed to update event filter: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "更新事件过滤器失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, filter)
+}
+
+// DeleteFilter 删除事件过滤器
+// @Summary 删除事件过滤器
+// @Tags BSC
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "过滤器ID"
+// @Success 200 {object} model.APIResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/bsc/filters/{id} [delete]
+func (h *EventFilterHandler) DeleteFilter(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.filters.DeleteFilter(c.Request.Context(), userID, id); err != nil {
+		logger.From(c).Errorf("Failed to delete event filter: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "删除事件过滤器失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, gin.H{"id": id})
+}
+
+// GetMatches 获取事件过滤器的匹配记录
+// @Summary 获取事件过滤器的匹配记录
+// @Description 获取过滤器最近匹配的日志，按时间倒序，每个过滤器保留 bsc.event_filters.max_matches 条
+// @Tags BSC
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "过滤器ID"
+// @Param limit query int false "返回数量" default(50)
+// @Success 200 {object} model.EventMatchListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/bsc/filters/{id}/matches [get]
+func (h *EventFilterHandler) GetMatches(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid limit")
+		return
+	}
+
+	matches, err := h.filters.GetMatches(c.Request.Context(), userID, c.Param("id"), limit)
+	if err != nil {
+		logger.From(c).Errorf("Failed to get event filter matches: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取匹配记录失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, matches)
+}
+
+// respondWithSuccess 成功响应
+func (h *EventFilterHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithStatus 以指定状态码返回成功响应
+func (h *EventFilterHandler) respondWithStatus(c *gin.Context, statusCode int, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
+
+// respondWithError 错误响应
+func (h *EventFilterHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FarmHandler 流动性挖矿处理器
+type FarmHandler struct {
+	farmService service.FarmService
+}
+
+// NewFarmHandler 创建流动性挖矿处理器
+func NewFarmHandler(farmService service.FarmService) *FarmHandler {
+	return &FarmHandler{
+		farmService: farmService,
+	}
+}
+
+// GetFarms 获取挖矿池APR
+// @Summary 获取挖矿池APR
+// @Description 读取MasterChef风格的挖矿合约，根据奖励发放速度和池TVL计算APR，支持按APR、TVL或权重排序
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Param chef query string false "挖矿合约名称，为空时返回全部"
+// @Param sort query string false "排序字段(apr,tvl,weight)" default(apr)
+// @Param order query string false "排序方向(asc,desc)" default(desc)
+// @Param limit query int false "返回数量" default(50)
+// @Success 200 {object} model.FarmListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/bsc/farms [get]
+func (h *FarmHandler) GetFarms(c *gin.Context) {
+	chef := c.Query("chef")
+	sortBy := c.Query("sort")
+	order := c.Query("order")
+	log := logger.From(c)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid limit")
+		return
+	}
+
+	log.Infof("Getting farms for chef: %s, sort: %s, order: %s", chef, sortBy, order)
+
+	farms, err := h.farmService.GetFarms(c.Request.Context(), chef, sortBy, order, limit)
+	if err != nil {
+		log.Errorf("Failed to get farms: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取挖矿池信息失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, farms)
+}
+
+// respondWithSuccess 成功响应
+func (h *FarmHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *FarmHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
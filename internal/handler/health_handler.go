@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler 就绪探针处理器
+type HealthHandler struct {
+	healthService service.HealthService
+}
+
+// NewHealthHandler 创建就绪探针处理器
+func NewHealthHandler(healthService service.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// Readyz 就绪探针
+// @Summary 就绪探针
+// @Description 探测Redis、火币、币安和BSC节点的可用性与延迟。ok和degraded返回200，负载均衡可按X-Health-Status调整权重；行情来源全部不可用时返回503
+// @Tags 健康检查
+// @Produce json
+// @Success 200 {object} model.ReadinessResponse
+// @Failure 503 {object} model.ReadinessResponse
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	readiness := h.healthService.Readiness(c.Request.Context())
+
+	statusCode := http.StatusOK
+	if readiness.Status == model.HealthStatusDown {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	c.Header("X-Health-Status", readiness.Status)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(statusCode, readiness)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultHistoryRange 未指定from时默认查询的时间范围
+const defaultHistoryRange = 24 * time.Hour
+
+// HistoryHandler 价格历史处理器
+type HistoryHandler struct {
+	historyService service.HistoryService
+}
+
+// NewHistoryHandler 创建价格历史处理器
+func NewHistoryHandler(historyService service.HistoryService) *HistoryHandler {
+	return &HistoryHandler{
+		historyService: historyService,
+	}
+}
+
+// GetPriceHistory 获取价格历史
+// @Summary 获取价格历史
+// @Description 获取指定加密货币按间隔聚合的历史价格，用于绘制走势图
+// @Tags 价格
+// @Accept json
+// @Produce json
+// @Param symbol query string false "加密货币符号" default(BTC)
+// @Param interval query string false "聚合间隔(1m,5m,15m,30m,1h,4h,1d)" default(5m)
+// @Param from query string false "开始时间，RFC3339或Unix秒" default(24小时前)
+// @Param to query string false "结束时间，RFC3339或Unix秒" default(当前时间)
+// @Success 200 {object} model.PriceHistoryResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/crypto/price/history [get]
+func (h *HistoryHandler) GetPriceHistory(c *gin.Context) {
+	symbol := c.Query("symbol")
+	interval := c.DefaultQuery("interval", "5m")
+	log := logger.From(c)
+
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := parseTimeParam(toStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "无效的结束时间", err.Error())
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultHistoryRange)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := parseTimeParam(fromStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "无效的开始时间", err.Error())
+			return
+		}
+		from = parsed
+	}
+
+	log.Infof("Getting price history for symbol: %s, interval: %s, from: %s, to: %s", symbol, interval, from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	history, err := h.historyService.GetPriceHistory(c.Request.Context(), symbol, interval, from, to)
+	if err != nil {
+		log.Errorf("Failed to get price history: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取价格历史失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, history)
+}
+
+// GetPriceAt 获取指定时间的价格
+// @Summary 获取指定时间的价格
+// @Description 根据已存储的价格历史解析指定时间的价格，支持最近邻和线性插值
+// @Tags 价格
+// @Accept json
+// @Produce json
+// @Param symbol query string false "加密货币符号" default(BTC)
+// @Param time query string true "查询时间，RFC3339或Unix秒"
+// @Param method query string false "解析方式(nearest,interpolate)" default(nearest)
+// @Success 200 {object} model.PriceAtResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/crypto/price/at [get]
+func (h *HistoryHandler) GetPriceAt(c *gin.Context) {
+	symbol := c.Query("symbol")
+	method := c.Query("method")
+	log := logger.From(c)
+
+	timeStr := c.Query("time")
+	if timeStr == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少查询时间", "time is required")
+		return
+	}
+	at, err := parseTimeParam(timeStr)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "无效的查询时间", err.Error())
+		return
+	}
+
+	log.Infof("Getting price at %s for symbol: %s, method: %s", at.Format(time.RFC3339), symbol, method)
+
+	price, err := h.historyService.GetPriceAt(c.Request.Context(), symbol, at, method)
+	if err != nil {
+		log.Errorf("Failed to get price at time: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取指定时间价格失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, price)
+}
+
+// parseTimeParam 解析时间参数，支持RFC3339和Unix秒
+func parseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected RFC3339 or unix seconds", value)
+}
+
+// respondWithSuccess 成功响应
+func (h *HistoryHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *HistoryHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
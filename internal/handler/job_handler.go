@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler 定时任务管理处理器
+type JobHandler struct {
+	jobService service.JobService
+}
+
+// NewJobHandler 创建定时任务管理处理器
+func NewJobHandler(jobService service.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// ListJobs 获取定时任务列表
+// @Summary 获取定时任务列表
+// @Description 获取已注册的定时任务、执行计划、下次执行时间和最近一次执行结果
+// @Tags 管理
+// @Produce json
+// @Success 200 {object} model.JobListResponse
+// @Router /api/v1/admin/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	h.respondWithStatus(c, http.StatusOK, h.jobService.ListJobs(c.Request.Context()))
+}
+
+// RunJob 手动触发定时任务
+// @Summary 手动触发定时任务
+// @Description 立即在当前实例执行任务，默认在后台执行并返回202，执行结果见任务列表的last_run或执行记录；wait=true时等待执行结束并返回最终的执行记录，请求超时前未结束时仍返回202
+// @Tags 管理
+// @Produce json
+// @Param name path string true "任务名称"
+// @Param wait query bool false "是否等待执行结束" default(false)
+// @Success 200 {object} model.JobRun
+// @Success 202 {object} model.JobRun
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Router /api/v1/admin/jobs/{name}/run [post]
+func (h *JobHandler) RunJob(c *gin.Context) {
+	log := logger.From(c)
+
+	wait, err := strconv.ParseBool(c.DefaultQuery("wait", "false"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid wait")
+		return
+	}
+
+	run, err := h.jobService.RunJob(c.Request.Context(), c.Param("name"), wait)
+	if err != nil {
+		log.Errorf("Failed to run job: %v", err)
+		status := errorStatus(c, err)
+		if errors.Is(err, service.ErrJobRunning) {
+			status = http.StatusConflict
+		}
+		h.respondWithError(c, status, "触发定时任务失败", err.Error())
+		return
+	}
+
+	if run.Status == model.JobRunRunning {
+		h.respondWithStatus(c, http.StatusAccepted, run)
+		return
+	}
+	h.respondWithStatus(c, http.StatusOK, run)
+}
+
+// ListJobRuns 获取定时任务执行记录
+// @Summary 获取定时任务执行记录
+// @Description 获取任务最近的执行记录(触发方式、实例、状态、耗时和错误)，新记录在前，最多保留jobs.history_size条
+// @Tags 管理
+// @Produce json
+// @Param name path string true "任务名称"
+// @Param limit query int false "返回数量，0表示全部保留的记录" default(0)
+// @Success 200 {object} model.JobHistoryResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/admin/jobs/{name}/runs [get]
+func (h *JobHandler) ListJobRuns(c *gin.Context) {
+	log := logger.From(c)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid limit")
+		return
+	}
+
+	history, err := h.jobService.JobHistory(c.Request.Context(), c.Param("name"), limit)
+	if err != nil {
+		log.Errorf("Failed to get job history: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取定时任务执行记录失败", err.Error())
+		return
+	}
+
+	h.respondWithStatus(c, http.StatusOK, history)
+}
+
+// respondWithStatus 成功响应
+func (h *JobHandler) respondWithStatus(c *gin.Context, statusCode int, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
+
+// respondWithError 错误响应
+func (h *JobHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KlineHandler K线处理器
+type KlineHandler struct {
+	klineService service.KlineService
+}
+
+// NewKlineHandler 创建K线处理器
+func NewKlineHandler(klineService service.KlineService) *KlineHandler {
+	return &KlineHandler{
+		klineService: klineService,
+	}
+}
+
+// GetKlines 获取K线
+// @Summary 获取K线
+// @Description 获取指定加密货币对USDT的历史K线(开高低收和成交量)，数据来自交易所，按开盘时间升序
+// @Tags 价格
+// @Accept json
+// @Produce json
+// @Param symbol query string false "加密货币符号" default(BTC)
+// @Param interval query string false "K线周期(1m,5m,15m,30m,1h,4h,1d,1w)" default(1h)
+// @Param limit query int false "K线数量，最多1000" default(500)
+// @Success 200 {object} model.KlineResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/crypto/klines [get]
+func (h *KlineHandler) GetKlines(c *gin.Context) {
+	symbol := c.Query("symbol")
+	interval := c.DefaultQuery("interval", "1h")
+	log := logger.From(c)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid limit")
+		return
+	}
+
+	log.Infof("Getting klines for symbol: %s, interval: %s, limit: %d", symbol, interval, limit)
+
+	klines, err := h.klineService.GetKlines(c.Request.Context(), symbol, interval, limit)
+	if err != nil {
+		log.Errorf("Failed to get klines: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取K线失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, klines)
+}
+
+// respondWithSuccess 成功响应
+func (h *KlineHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *KlineHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LiquidityHandler 流动性事件处理器
+type LiquidityHandler struct {
+	liquidityService service.LiquidityService
+}
+
+// NewLiquidityHandler 创建流动性事件处理器
+func NewLiquidityHandler(liquidityService service.LiquidityService) *LiquidityHandler {
+	return &LiquidityHandler{
+		liquidityService: liquidityService,
+	}
+}
+
+// GetEvents 获取可疑流动性事件
+// @Summary 获取可疑流动性事件
+// @Description 获取监控交易对最近的大额流动性撤出和LP解锁事件，按时间倒序
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Param pair query string false "交易对地址，为空时返回全部"
+// @Param limit query int false "返回数量" default(100)
+// @Success 200 {object} model.LiquidityEventListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/bsc/liquidity/events [get]
+func (h *LiquidityHandler) GetEvents(c *gin.Context) {
+	pair := c.Query("pair")
+	log := logger.From(c)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid limit")
+		return
+	}
+
+	events, err := h.liquidityService.GetEvents(c.Request.Context(), pair, limit)
+	if err != nil {
+		log.Errorf("Failed to get liquidity events: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取流动性事件失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, events)
+}
+
+// GetPairs 获取流动性监控中的交易对
+// @Summary 获取流动性监控中的交易对
+// @Description 获取流动性撤出监控的交易对，包括配置的交易对和通过Factory为关注代币自动发现的交易对(source为discovery)
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.MonitoredPairListResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/bsc/liquidity/pairs [get]
+func (h *LiquidityHandler) GetPairs(c *gin.Context) {
+	log := logger.From(c)
+
+	pairs, err := h.liquidityService.GetPairs(c.Request.Context())
+	if err != nil {
+		log.Errorf("Failed to get monitored pairs: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取监控交易对失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, pairs)
+}
+
+// respondWithSuccess 成功响应
+func (h *LiquidityHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *LiquidityHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MarketHandler 市值处理器
+type MarketHandler struct {
+	marketDataService service.MarketDataService
+}
+
+// NewMarketHandler 创建市值处理器
+func NewMarketHandler(marketDataService service.MarketDataService) *MarketHandler {
+	return &MarketHandler{
+		marketDataService: marketDataService,
+	}
+}
+
+// GetMarketCap 获取市值
+// @Summary 获取市值和流通量
+// @Description 获取加密货币的美元市值、流通量、总供应量和市值排名，数据来自CoinGecko并按币种缓存；部分币种获取失败时在errors中列出
+// @Tags 价格
+// @Accept json
+// @Produce json
+// @Param symbols query string false "加密货币符号列表，逗号分隔，最多50个，为空时返回所有支持的币种" default(BTC,ETH)
+// @Success 200 {object} model.MarketCapResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/crypto/marketcap [get]
+func (h *MarketHandler) GetMarketCap(c *gin.Context) {
+	symbols := splitList(c.Query("symbols"), true)
+	log := logger.From(c)
+
+	log.Infof("Getting market caps for symbols: %v", symbols)
+
+	marketCaps, err := h.marketDataService.GetMarketCaps(c.Request.Context(), symbols)
+	if err != nil {
+		log.Errorf("Failed to get market caps: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取市值失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, marketCaps)
+}
+
+// GetGlobal 获取全市场数据
+// @Summary 获取全市场总市值和BTC/ETH市值占比
+// @Description 获取全市场美元总市值、24小时总成交额、总市值24小时涨跌幅以及BTC和ETH的市值占比，数据来自CoinGecko，缓存cache.global_ttl
+// @Tags 价格
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.GlobalMarketData
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/crypto/global [get]
+func (h *MarketHandler) GetGlobal(c *gin.Context) {
+	log := logger.From(c)
+
+	log.Info("Getting global market data")
+
+	global, err := h.marketDataService.GetGlobal(c.Request.Context())
+	if err != nil {
+		log.Errorf("Failed to get global market data: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取全市场数据失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, global)
+}
+
+// respondWithSuccess 成功响应
+func (h *MarketHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *MarketHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"net/http"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NameHandler 域名解析处理器
+type NameHandler struct {
+	nameService service.NameService
+}
+
+// NewNameHandler 创建域名解析处理器
+func NewNameHandler(nameService service.NameService) *NameHandler {
+	return &NameHandler{
+		nameService: nameService,
+	}
+}
+
+// Resolve 解析域名
+// @Summary 解析域名
+// @Description 将Space ID(.bnb)或ENS(.eth)域名解析为地址
+// @Tags 域名
+// @Accept json
+// @Produce json
+// @Param name query string true "域名，如alice.bnb"
+// @Success 200 {object} model.NameRecord
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/names/resolve [get]
+func (h *NameHandler) Resolve(c *gin.Context) {
+	name := c.Query("name")
+	log := logger.From(c)
+
+	if name == "" {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "name is required")
+		return
+	}
+
+	record, err := h.nameService.Resolve(c.Request.Context(), name)
+	if err != nil {
+		log.Errorf("Failed to resolve name %s: %v", name, err)
+		h.respondWithError(c, errorStatus(c, err), "域名解析失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, record)
+}
+
+// Reverse 反向解析地址
+// @Summary 反向解析地址
+// @Description 查询地址设置的主域名，只返回能正向解析回该地址的域名
+// @Tags 域名
+// @Accept json
+// @Produce json
+// @Param address path string true "地址"
+// @Success 200 {object} model.NameRecord
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/names/reverse/{address} [get]
+func (h *NameHandler) Reverse(c *gin.Context) {
+	address := c.Param("address")
+	log := logger.From(c)
+
+	record, err := h.nameService.Reverse(c.Request.Context(), address)
+	if err != nil {
+		log.Errorf("Failed to reverse resolve %s: %v", address, err)
+		h.respondWithError(c, errorStatus(c, err), "地址反向解析失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, record)
+}
+
+// respondWithSuccess 成功响应
+func (h *NameHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *NameHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
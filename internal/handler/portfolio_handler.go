@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize 导入文件大小上限
+const maxImportFileSize = 10 << 20
+
+// PortfolioHandler 投资组合处理器
+type PortfolioHandler struct {
+	portfolioService service.PortfolioService
+}
+
+// NewPortfolioHandler 创建投资组合处理器
+func NewPortfolioHandler(portfolioService service.PortfolioService) *PortfolioHandler {
+	return &PortfolioHandler{
+		portfolioService: portfolioService,
+	}
+}
+
+// CalculatePnL 计算成本与盈亏
+// @Summary 计算成本与盈亏
+// @Description 根据买卖记录按FIFO/LIFO计算持仓成本、已实现和未实现盈亏，未提供成交价的交易使用历史价格；未提供交易记录时使用已导入的交易
+// @Tags 投资组合
+// @Accept json
+// @Produce json
+// @Param request body model.PnLRequest true "交易记录"
+// @Success 200 {object} model.PnLResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/portfolio/pnl [post]
+func (h *PortfolioHandler) CalculatePnL(c *gin.Context) {
+	log := logger.From(c)
+
+	var req model.PnLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "请求参数无效", err.Error())
+		return
+	}
+
+	log.Infof("Calculating P&L for %d trades, method: %s", len(req.Trades), req.Method)
+
+	pnl, err := h.portfolioService.CalculatePnL(c.Request.Context(), userIDFrom(c), &req)
+	if err != nil {
+		log.Errorf("Failed to calculate P&L: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "计算盈亏失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, pnl)
+}
+
+// ImportTrades 导入交易所交易记录
+// @Summary 导入交易所交易记录
+// @Description 上传币安/火币导出的成交记录CSV，标准化后保存到投资组合，重复导入的记录会被跳过
+// @Tags 投资组合
+// @Accept multipart/form-data
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param file formData file true "CSV文件"
+// @Param format formData string false "文件格式(binance,huobi)，为空时自动识别"
+// @Success 200 {object} model.ImportReport
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/portfolio/trades/import [post]
+func (h *PortfolioHandler) ImportTrades(c *gin.Context) {
+	log := logger.From(c)
+
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "请上传CSV文件", err.Error())
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "无法读取上传文件", err.Error())
+		return
+	}
+	defer file.Close()
+
+	format := strings.ToLower(c.PostForm("format"))
+	log.Infof("Importing trades from %s (format: %s) for user %s", fileHeader.Filename, format, userID)
+
+	report, err := h.portfolioService.ImportTrades(c.Request.Context(), userID, format, file)
+	if err != nil {
+		log.Errorf("Failed to import trades: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "导入交易记录失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, report)
+}
+
+// ListTrades 获取已导入的交易记录
+// @Summary 获取已导入的交易记录
+// @Description 获取当前用户已导入的交易记录，按成交时间排序
+// @Tags 投资组合
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Success 200 {array} model.Trade
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/portfolio/trades [get]
+func (h *PortfolioHandler) ListTrades(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	trades, err := h.portfolioService.ListTrades(c.Request.Context(), userID)
+	if err != nil {
+		logger.From(c).Errorf("Failed to list trades: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取交易记录失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, trades)
+}
+
+// ExportAccounting 导出会计报表
+// @Summary 导出会计报表
+// @Description 导出期间内的买入、卖出明细或按周期汇总的已实现盈亏CSV，公允价格取自价格历史
+// @Tags 投资组合
+// @Produce text/csv
+// @Param X-User-ID header string true "用户ID"
+// @Param from query string false "开始时间，RFC3339或Unix秒" default(当年1月1日)
+// @Param to query string false "结束时间，RFC3339或Unix秒" default(当前时间)
+// @Param method query string false "成本核算方式(fifo,lifo)" default(fifo)
+// @Param period query string false "汇总周期(month,quarter,year)" default(year)
+// @Param type query string false "导出内容(transactions,summary)" default(transactions)
+// @Param format query string false "输出格式(csv,json)" default(csv)
+// @Success 200 {object} model.AccountingReport
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/portfolio/export [get]
+func (h *PortfolioHandler) ExportAccounting(c *gin.Context) {
+	log := logger.From(c)
+
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	now := time.Now().UTC()
+	to := now
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := parseTimeParam(toStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "无效的结束时间", err.Error())
+			return
+		}
+		to = parsed
+	}
+	from := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := parseTimeParam(fromStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "无效的开始时间", err.Error())
+			return
+		}
+		from = parsed
+	}
+
+	exportType := c.DefaultQuery("type", "transactions")
+	if exportType != "transactions" && exportType != "summary" {
+		h.respondWithError(c, http.StatusBadRequest, "无效的导出内容", "type must be transactions or summary")
+		return
+	}
+
+	log.Infof("Exporting accounting report for user %s from %s to %s", userID, from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	report, err := h.portfolioService.ExportAccounting(c.Request.Context(), userID, c.Query("method"), c.Query("period"), from, to)
+	if err != nil {
+		log.Errorf("Failed to export accounting report: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "导出会计报表失败", err.Error())
+		return
+	}
+
+	if c.DefaultQuery("format", "csv") == "json" {
+		h.respondWithSuccess(c, report)
+		return
+	}
+
+	filename := fmt.Sprintf("crypto-%s-%s-%s.csv", exportType, from.Format("20060102"), to.Format("20060102"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	if exportType == "summary" {
+		writeAccountingSummary(w, report)
+	} else {
+		writeAccountingEntries(w, report)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Errorf("Failed to write accounting csv: %v", err)
+	}
+}
+
+// writeAccountingEntries 写入会计明细CSV
+func writeAccountingEntries(w *csv.Writer, report *model.AccountingReport) {
+	_ = w.Write([]string{
+		"Date", "Type", "Symbol", "Quantity", "Unit Price", "Fair Value", "Fair Value Source",
+		"Cost Basis", "Proceeds", "Gain", "Date Acquired", "Holding Days", "Term", "Trade ID", "Exchange",
+	})
+	for _, e := range report.Entries {
+		acquired := ""
+		if e.AcquiredAt != nil {
+			acquired = e.AcquiredAt.UTC().Format(time.RFC3339)
+		}
+		_ = w.Write([]string{
+			e.Date.UTC().Format(time.RFC3339),
+			e.Type,
+			e.Symbol,
+			formatAmount(e.Quantity),
+			formatAmount(e.UnitPrice),
+			formatAmount(e.FairValue),
+			e.FairValueSource,
+			formatAmount(e.CostBasis),
+			formatAmount(e.Proceeds),
+			formatAmount(e.Gain),
+			acquired,
+			strconv.Itoa(e.HoldingDays),
+			e.Term,
+			e.TradeID,
+			e.Exchange,
+		})
+	}
+}
+
+// writeAccountingSummary 写入期间汇总CSV
+func writeAccountingSummary(w *csv.Writer, report *model.AccountingReport) {
+	_ = w.Write([]string{
+		"Period", "Acquisitions", "Acquisition Cost", "Disposals", "Proceeds", "Cost Basis",
+		"Gain", "Short Term Gain", "Long Term Gain",
+	})
+	for _, p := range report.Periods {
+		_ = w.Write([]string{
+			p.Period,
+			strconv.Itoa(p.Acquired),
+			formatAmount(p.Acquisitions),
+			strconv.Itoa(p.Disposals),
+			formatAmount(p.Proceeds),
+			formatAmount(p.CostBasis),
+			formatAmount(p.Gain),
+			formatAmount(p.ShortTerm),
+			formatAmount(p.LongTerm),
+		})
+	}
+}
+
+// formatAmount 格式化金额，避免科学计数法
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// respondWithSuccess 成功响应
+func (h *PortfolioHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *PortfolioHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -2,9 +2,12 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
 	"crypto-info/internal/model"
 	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/precision"
 	"crypto-info/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -13,14 +16,12 @@ import (
 // PriceHandler 价格处理器
 type PriceHandler struct {
 	priceService service.PriceService
-	logger       logger.Logger
 }
 
 // NewPriceHandler 创建价格处理器
 func NewPriceHandler(priceService service.PriceService) *PriceHandler {
 	return &PriceHandler{
 		priceService: priceService,
-		logger:       logger.GetLogger(),
 	}
 }
 
@@ -31,23 +32,61 @@ func NewPriceHandler(priceService service.PriceService) *PriceHandler {
 // @Accept json
 // @Produce json
 // @Param symbol query string false "加密货币符号" default(BTC)
+// @Param quote_currency query string false "计价币种：USDT(交易所和链上数据源)或USD(Coinbase、Kraken美元报价)" default(USDT)
+// @Param min_confirmations query int false "只使用BSC链上价格，且价格所在区块至少有该数量的确认，最大1000"
+// @Param locale query string false "按locale格式化价格，结果在formatted字段，如zh-CN、de-DE"
 // @Success 200 {object} model.PriceResponse
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/crypto/price [get]
 func (h *PriceHandler) GetPrice(c *gin.Context) {
 	symbol := c.Query("symbol")
-	requestID := c.GetString("request_id")
+	currency := c.Query("quote_currency")
+	log := logger.From(c)
 
-	h.logger.WithField("request_id", requestID).Infof("Getting price for symbol: %s", symbol)
+	if locale := c.Query("locale"); locale != "" && !precision.SupportedLocale(locale) {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "unsupported locale "+locale)
+		return
+	}
+
+	confirmationsStr := c.Query("min_confirmations")
+	if confirmationsStr == "" {
+		log.Infof("Getting price for symbol: %s, quote currency: %s", symbol, currency)
+		price, err := h.priceService.GetQuote(c.Request.Context(), symbol, currency)
+		h.respondWithPrice(c, price, err)
+		return
+	}
 
-	price, err := h.priceService.GetPrice(c.Request.Context(), symbol)
+	// 要求确认数时只使用BSC链上价格，链上价格以USDT计价
+	minConfirmations, err := strconv.ParseUint(confirmationsStr, 10, 64)
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get price: %v", err)
-		h.respondWithError(c, http.StatusInternalServerError, "获取价格失败", err.Error())
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "min_confirmations must be a non-negative integer")
+		return
+	}
+	if currency != "" && !strings.EqualFold(currency, "USDT") {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "min_confirmations only supports quote_currency USDT")
 		return
 	}
 
+	log.Infof("Getting price for symbol: %s, min confirmations: %d", symbol, minConfirmations)
+	price, err := h.priceService.GetConfirmedPrice(c.Request.Context(), symbol, minConfirmations)
+	h.respondWithPrice(c, price, err)
+}
+
+// respondWithPrice 输出价格查询结果
+func (h *PriceHandler) respondWithPrice(c *gin.Context, price *model.PriceResponse, err error) {
+	if err != nil {
+		logger.From(c).Errorf("Failed to get price: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取价格失败", err.Error())
+		return
+	}
+
+	// 价格结果可能来自共享的缓存，复制后再填充格式化字段
+	if locale := c.Query("locale"); locale != "" {
+		localized := *price
+		localized.Formatted = precision.FormatLocale(price.Symbol, price.Price, locale)
+		price = &localized
+	}
 	h.respondWithSuccess(c, price)
 }
 
@@ -61,14 +100,14 @@ func (h *PriceHandler) GetPrice(c *gin.Context) {
 // @Failure 500 {object} model.ErrorResponse
 // @Router /api/v1/crypto/btc-price [get]
 func (h *PriceHandler) GetBTCPrice(c *gin.Context) {
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 
-	h.logger.WithField("request_id", requestID).Info("Getting BTC price")
+	log.Info("Getting BTC price")
 
 	price, err := h.priceService.GetBTCPrice(c.Request.Context())
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get BTC price: %v", err)
-		h.respondWithError(c, http.StatusInternalServerError, "获取BTC价格失败", err.Error())
+		log.Errorf("Failed to get BTC price: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取BTC价格失败", err.Error())
 		return
 	}
 
@@ -109,4 +148,4 @@ func (h *PriceHandler) respondWithError(c *gin.Context, statusCode int, message,
 	}
 
 	c.JSON(statusCode, response)
-}
\ No newline at end of file
+}
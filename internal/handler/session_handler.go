@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"crypto-info/internal/pkg/precision"
 	"crypto-info/internal/pkg/session"
 
 	"github.com/gin-gonic/gin"
@@ -197,4 +200,84 @@ func (h *SessionHandler) SessionStatus(c *gin.Context) {
 		"data_count":       len(sess.Data),
 		"is_expired":       timeToExpire <= 0,
 	})
-}
\ No newline at end of file
+}
+// 显示偏好的限制
+const (
+	maxPreferenceSymbols = 20
+	maxSymbolLength      = 20
+)
+
+// GetPreferences 获取当前session的显示偏好
+func (h *SessionHandler) GetPreferences(c *gin.Context) {
+	prefs, ok := session.GetPreferences(c)
+	if !ok {
+		prefs = &session.Preferences{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"preferences": prefs,
+	})
+}
+
+// SetPreferences 设置显示偏好，价格和交易量接口未指定quote_currency、locale、symbol(s)时使用，全部为空时清除
+func (h *SessionHandler) SetPreferences(c *gin.Context) {
+	var prefs session.Preferences
+	if err := c.ShouldBindJSON(&prefs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "请求参数无效: " + err.Error(),
+			"code":    400,
+		})
+		return
+	}
+	if err := normalizePreferences(&prefs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid preferences",
+			"message": "显示偏好无效: " + err.Error(),
+			"code":    400,
+		})
+		return
+	}
+
+	if err := session.SetPreferences(c, &prefs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to set preferences",
+			"message": "设置显示偏好失败: " + err.Error(),
+			"code":    500,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Preferences saved successfully",
+		"preferences": prefs,
+	})
+}
+
+// normalizePreferences 统一大小写并校验计价币种、locale和币种
+func normalizePreferences(prefs *session.Preferences) error {
+	prefs.Currency = strings.ToUpper(strings.TrimSpace(prefs.Currency))
+	switch prefs.Currency {
+	case "", "USDT", "USD":
+	default:
+		return fmt.Errorf("unsupported currency %s, must be USDT or USD", prefs.Currency)
+	}
+
+	prefs.Locale = strings.TrimSpace(prefs.Locale)
+	if prefs.Locale != "" && !precision.SupportedLocale(prefs.Locale) {
+		return fmt.Errorf("unsupported locale %s", prefs.Locale)
+	}
+
+	symbols := splitList(strings.Join(prefs.Symbols, ","), true)
+	if len(symbols) > maxPreferenceSymbols {
+		return fmt.Errorf("at most %d symbols", maxPreferenceSymbols)
+	}
+	for _, symbol := range symbols {
+		if len(symbol) > maxSymbolLength || strings.ContainsFunc(symbol, func(r rune) bool {
+			return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+		}) {
+			return fmt.Errorf("invalid symbol %s", symbol)
+		}
+	}
+	prefs.Symbols = symbols
+	return nil
+}
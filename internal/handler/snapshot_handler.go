@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SnapshotHandler 持币快照处理器
+type SnapshotHandler struct {
+	snapshotService service.SnapshotService
+}
+
+// NewSnapshotHandler 创建持币快照处理器
+func NewSnapshotHandler(snapshotService service.SnapshotService) *SnapshotHandler {
+	return &SnapshotHandler{
+		snapshotService: snapshotService,
+	}
+}
+
+// CreateSnapshot 创建持币快照
+// @Summary 创建持币快照
+// @Description 回放代币转账计算指定区块的全部持币余额，异步导出CSV，可用于空投和治理快照
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Param request body model.SnapshotRequest true "快照参数"
+// @Success 202 {object} model.SnapshotJob
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/bsc/token/snapshot [post]
+func (h *SnapshotHandler) CreateSnapshot(c *gin.Context) {
+	log := logger.From(c)
+
+	var req model.SnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	job, err := h.snapshotService.CreateSnapshot(c.Request.Context(), &req)
+	if err != nil {
+		log.Errorf("Failed to create snapshot: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "创建持币快照失败", err.Error())
+		return
+	}
+
+	h.respondWithStatus(c, http.StatusAccepted, job)
+}
+
+// GetSnapshot 获取持币快照任务
+// @Summary 获取持币快照任务
+// @Description 获取快照任务的状态和进度，完成后返回下载地址
+// @Tags BSC
+// @Produce json
+// @Param id path string true "任务ID"
+// @Success 200 {object} model.SnapshotJob
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/bsc/token/snapshot/{id} [get]
+func (h *SnapshotHandler) GetSnapshot(c *gin.Context) {
+	log := logger.From(c)
+
+	job, err := h.snapshotService.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		log.Errorf("Failed to get snapshot: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取持币快照失败", err.Error())
+		return
+	}
+
+	h.respondWithStatus(c, http.StatusOK, job)
+}
+
+// DownloadSnapshot 下载持币快照
+// @Summary 下载持币快照
+// @Description 下载已完成快照的CSV，按余额倒序，包含地址、按精度换算的余额和原始余额
+// @Tags BSC
+// @Produce text/csv
+// @Param id path string true "任务ID"
+// @Success 200 {file} file
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/bsc/token/snapshot/{id}/download [get]
+func (h *SnapshotHandler) DownloadSnapshot(c *gin.Context) {
+	log := logger.From(c)
+
+	path, job, err := h.snapshotService.ResultPath(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		log.Errorf("Failed to download snapshot: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "下载持币快照失败", err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("%s-holders-%d.csv", job.Symbol, job.Block)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.FileAttachment(path, filename)
+}
+
+// respondWithStatus 成功响应
+func (h *SnapshotHandler) respondWithStatus(c *gin.Context, statusCode int, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
+
+// respondWithError 错误响应
+func (h *SnapshotHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
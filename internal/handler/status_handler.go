@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/breaker"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusHandler 服务状态处理器
+type StatusHandler struct {
+	slaService service.SLAService
+	breakers   *breaker.Set
+}
+
+// NewStatusHandler 创建服务状态处理器
+func NewStatusHandler(slaService service.SLAService, breakers *breaker.Set) *StatusHandler {
+	return &StatusHandler{
+		slaService: slaService,
+		breakers:   breakers,
+	}
+}
+
+// GetSLA 获取请求成功率和依赖可用性
+// @Summary 获取SLA统计
+// @Description 获取最近1小时、24小时和30天的API请求成功率(非5xx)、依赖可用性，以及成功率是否达到目标，统计为所有实例合计
+// @Tags 健康检查
+// @Produce json
+// @Success 200 {object} model.SLAStatusResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/status/sla [get]
+func (h *StatusHandler) GetSLA(c *gin.Context) {
+	status, err := h.slaService.Status(c.Request.Context())
+	if err != nil {
+		logger.From(c).Errorf("Failed to get sla status: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取SLA统计失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, status)
+}
+
+// GetBreakers 获取价格数据源的熔断状态
+// @Summary 获取数据源熔断状态
+// @Description 获取当前实例各价格数据源的熔断状态(closed、open、half_open)、连续失败次数和熔断次数，熔断中的数据源被跳过，改用缓存或下一个数据源
+// @Tags 健康检查
+// @Produce json
+// @Success 200 {object} model.BreakerStatusResponse
+// @Router /api/v1/status/breakers [get]
+func (h *StatusHandler) GetBreakers(c *gin.Context) {
+	h.respondWithSuccess(c, h.breakers.Status())
+}
+
+// respondWithSuccess 成功响应
+func (h *StatusHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *StatusHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"crypto-info/internal/model"
+)
+
+// streamDefaultSnapshotInterval 增量推送时推送完整数据的默认间隔
+const streamDefaultSnapshotInterval = 30 * time.Second
+
+// streamEncoder 按连接的推送方式处理价格事件：限制每个币种的推送频率，并转换为增量帧
+// 告警事件原样推送，不限频也不做增量。非并发安全，每个连接使用一个
+type streamEncoder struct {
+	options          model.StreamOptions
+	minInterval      time.Duration
+	snapshotInterval time.Duration
+	states           map[string]*symbolStreamState
+}
+
+// symbolStreamState 单个币种的推送状态
+type symbolStreamState struct {
+	fields       map[string]json.RawMessage // 客户端当前持有的数据
+	lastSent     time.Time
+	lastSnapshot time.Time
+	pending      *model.StreamEvent // 限频期间收到的最新事件
+}
+
+// newStreamEncoder 创建推送编码器
+func newStreamEncoder(options model.StreamOptions, snapshotInterval time.Duration) *streamEncoder {
+	if snapshotInterval <= 0 {
+		snapshotInterval = streamDefaultSnapshotInterval
+	}
+	e := &streamEncoder{
+		options:          options,
+		snapshotInterval: snapshotInterval,
+		states:           make(map[string]*symbolStreamState),
+	}
+	if options.MaxRate > 0 {
+		e.minInterval = time.Duration(float64(time.Second) / options.MaxRate)
+	}
+	return e
+}
+
+// passthrough 是否不需要处理，直接推送原始事件
+func (e *streamEncoder) passthrough() bool {
+	return !e.options.Delta && e.minInterval == 0
+}
+
+// flushInterval 检查限频期间待推送事件的间隔，不限频时为0
+func (e *streamEncoder) flushInterval() time.Duration {
+	if e.minInterval == 0 {
+		return 0
+	}
+	// 检查间隔越短延迟越小，但不低于50ms
+	if e.minInterval/2 < 50*time.Millisecond {
+		return 50 * time.Millisecond
+	}
+	return e.minInterval / 2
+}
+
+// Add 处理新事件，返回需要立即推送的帧，限频期间只保留最新事件等待Flush
+func (e *streamEncoder) Add(event *model.StreamEvent, now time.Time) *model.StreamEvent {
+	if e.passthrough() || event.Type != model.StreamEventPrice || event.Symbol == "" {
+		return event
+	}
+
+	state := e.state(event.Symbol)
+	if e.minInterval > 0 && now.Sub(state.lastSent) < e.minInterval {
+		state.pending = event
+		return nil
+	}
+	state.pending = nil
+	return e.encode(state, event, now)
+}
+
+// Flush 返回限频间隔已过的待推送帧
+func (e *streamEncoder) Flush(now time.Time) []*model.StreamEvent {
+	var frames []*model.StreamEvent
+	for _, state := range e.states {
+		if state.pending == nil || now.Sub(state.lastSent) < e.minInterval {
+			continue
+		}
+		event := state.pending
+		state.pending = nil
+		if frame := e.encode(state, event, now); frame != nil {
+			frames = append(frames, frame)
+		}
+	}
+	return frames
+}
+
+// encode 生成推送帧，数据与客户端持有的一致时返回nil
+func (e *streamEncoder) encode(state *symbolStreamState, event *model.StreamEvent, now time.Time) *model.StreamEvent {
+	if !e.options.Delta {
+		state.lastSent = now
+		return event
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(event.Data, &fields); err != nil {
+		// 数据不是JSON对象时无法计算增量，原样推送
+		state.lastSent = now
+		return event
+	}
+
+	frame := *event
+	if state.fields == nil || now.Sub(state.lastSnapshot) >= e.snapshotInterval {
+		frame.Mode = model.StreamModeSnapshot
+		state.lastSnapshot = now
+	} else {
+		changed := diffFields(state.fields, fields)
+		if len(changed) == 0 {
+			return nil
+		}
+		data, err := json.Marshal(changed)
+		if err != nil {
+			return event
+		}
+		frame.Mode = model.StreamModeDelta
+		frame.Data = data
+	}
+
+	state.fields = fields
+	state.lastSent = now
+	return &frame
+}
+
+// state 获取币种的推送状态
+func (e *streamEncoder) state(symbol string) *symbolStreamState {
+	state, ok := e.states[symbol]
+	if !ok {
+		state = &symbolStreamState{}
+		e.states[symbol] = state
+	}
+	return state
+}
+
+// diffFields 返回新增或变化的字段，删除的字段值为null
+func diffFields(previous, current map[string]json.RawMessage) map[string]json.RawMessage {
+	changed := make(map[string]json.RawMessage)
+	for key, value := range current {
+		if old, ok := previous[key]; !ok || !bytes.Equal(old, value) {
+			changed[key] = value
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			changed[key] = json.RawMessage("null")
+		}
+	}
+	return changed
+}
@@ -0,0 +1,491 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket连接参数
+const (
+	streamWriteTimeout        = 10 * time.Second
+	streamDefaultPingInterval = 30 * time.Second
+	streamMaxMessageSize      = 4096
+)
+
+// streamUpgrader 推送的是公开行情数据，不限制来源
+var streamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// StreamHandler WebSocket推送处理器
+type StreamHandler struct {
+	streamService    service.StreamService
+	pingInterval     time.Duration
+	snapshotInterval time.Duration
+}
+
+// NewStreamHandler 创建WebSocket推送处理器
+func NewStreamHandler(streamService service.StreamService, cfg *config.Stream) *StreamHandler {
+	pingInterval := cfg.PingInterval
+	if pingInterval <= 0 {
+		pingInterval = streamDefaultPingInterval
+	}
+	return &StreamHandler{
+		streamService:    streamService,
+		pingInterval:     pingInterval,
+		snapshotInterval: cfg.SnapshotInterval,
+	}
+}
+
+// Connect 建立WebSocket连接并推送价格和告警事件
+// @Summary 订阅实时推送
+// @Description 升级为WebSocket连接，推送价格更新和告警。多实例部署时事件经Redis pub/sub分发，连接到任一实例都能收到全部事件
+// @Tags 推送
+// @Param subscription query string false "命名订阅ID，指定后忽略types、symbols、delta和max_rate"
+// @Param types query string false "事件类型，逗号分隔(price,alert,event)，为空时推送全部"
+// @Param symbols query string false "币种，逗号分隔，为空时推送全部"
+// @Param delta query bool false "价格事件只推送变化的字段，并定期推送完整数据"
+// @Param max_rate query number false "每个币种每秒最多推送的价格更新数，0表示不限制"
+// @Param replay query int false "实时推送前先补发最近的N条匹配事件，最多stream.replay_size条"
+// @Success 101 {object} model.StreamEvent
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/stream/ws [get]
+func (h *StreamHandler) Connect(c *gin.Context) {
+	log := logger.From(c)
+
+	stream, ok := h.subscribe(c)
+	if !ok {
+		return
+	}
+	defer stream.sub.Close()
+
+	conn, err := streamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade失败时已写出错误响应
+		log.Warnf("Failed to upgrade websocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// 客户端不需要发送数据，读取循环只用于处理控制帧和检测断开
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(streamMaxMessageSize)
+		conn.SetReadDeadline(time.Now().Add(2 * h.pingInterval))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * h.pingInterval))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(event *model.StreamEvent) error {
+		conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		return conn.WriteJSON(event)
+	}
+	ping := func() error {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout))
+	}
+	if h.pump(stream, closed, send, ping) {
+		// 服务关闭
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+			time.Now().Add(streamWriteTimeout))
+	}
+}
+
+// Events 以Server-Sent Events推送价格和告警事件
+// @Summary 订阅实时推送(SSE)
+// @Description 以text/event-stream推送价格更新和告警，事件名为事件类型，参数与WebSocket推送一致
+// @Tags 推送
+// @Produce text/event-stream
+// @Param subscription query string false "命名订阅ID，指定后忽略types、symbols、delta和max_rate"
+// @Param types query string false "事件类型，逗号分隔(price,alert,event)，为空时推送全部"
+// @Param symbols query string false "币种，逗号分隔，为空时推送全部"
+// @Param delta query bool false "价格事件只推送变化的字段，并定期推送完整数据"
+// @Param max_rate query number false "每个币种每秒最多推送的价格更新数，0表示不限制"
+// @Param replay query int false "实时推送前先补发最近的N条匹配事件，最多stream.replay_size条"
+// @Success 200 {object} model.StreamEvent
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/stream/sse [get]
+func (h *StreamHandler) Events(c *gin.Context) {
+	stream, ok := h.subscribe(c)
+	if !ok {
+		return
+	}
+	defer stream.sub.Close()
+
+	// 长连接不受服务端写超时限制
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logger.From(c).Warnf("Failed to clear write deadline: %v", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	send := func(event *model.StreamEvent) error {
+		c.SSEvent(event.Type, event)
+		c.Writer.Flush()
+		return nil
+	}
+	ping := func() error {
+		// 注释行作为心跳，避免代理因空闲断开连接
+		if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+	h.pump(stream, c.Request.Context().Done(), send, ping)
+}
+
+// CreateSubscription 创建命名订阅
+// @Summary 创建推送订阅
+// @Description 保存一组过滤条件，WebSocket和SSE连接通过subscription参数引用，断线重连时复用同一订阅。订阅在最后一次连接或更新后保留stream.subscription_ttl
+// @Tags 推送
+// @Accept json
+// @Produce json
+// @Param request body model.StreamSubscriptionRequest true "订阅条件"
+// @Success 201 {object} model.StreamSubscription
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/stream/subscriptions [post]
+func (h *StreamHandler) CreateSubscription(c *gin.Context) {
+	var req model.StreamSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	sub, err := h.streamService.CreateSubscription(c.Request.Context(), &req)
+	if err != nil {
+		logger.From(c).Errorf("Failed to create stream subscription: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "创建推送订阅失败", err.Error())
+		return
+	}
+
+	h.respondWithStatus(c, http.StatusCreated, sub)
+}
+
+// GetSubscription 获取命名订阅
+// @Summary 获取推送订阅
+// @Tags 推送
+// @Produce json
+// @Param id path string true "订阅ID"
+// @Success 200 {object} model.StreamSubscription
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/stream/subscriptions/{id} [get]
+func (h *StreamHandler) GetSubscription(c *gin.Context) {
+	sub, err := h.streamService.GetSubscription(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		logger.From(c).Errorf("Failed to get stream subscription: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取推送订阅失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, sub)
+}
+
+// UpdateSubscription 更新命名订阅
+// @Summary 更新推送订阅
+// @Description 替换订阅的名称和过滤条件，已连接的客户端重连后生效
+// @Tags 推送
+// @Accept json
+// @Produce json
+// @Param id path string true "订阅ID"
+// @Param request body model.StreamSubscriptionRequest true "订阅条件"
+// @Success 200 {object} model.StreamSubscription
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/stream/subscriptions/{id} [put]
+func (h *StreamHandler) UpdateSubscription(c *gin.Context) {
+	var req model.StreamSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	sub, err := h.streamService.UpdateSubscription(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		logger.From(c).Errorf("Failed to update stream subscription: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "更新推送订阅失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, sub)
+}
+
+// DeleteSubscription 删除命名订阅
+// @Summary 删除推送订阅
+// @Description 删除订阅，已连接的客户端不受影响
+// @Tags 推送
+// @Produce json
+// @Param id path string true "订阅ID"
+// @Success 200 {object} model.APIResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/stream/subscriptions/{id} [delete]
+func (h *StreamHandler) DeleteSubscription(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.streamService.DeleteSubscription(c.Request.Context(), id); err != nil {
+		logger.From(c).Errorf("Failed to delete stream subscription: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "删除推送订阅失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, gin.H{"id": id})
+}
+
+// GetStats 获取各实例的推送连接数
+// @Summary 获取推送连接统计
+// @Description 获取所有实例的WebSocket连接数，实例每15秒上报一次
+// @Tags 推送
+// @Produce json
+// @Success 200 {object} model.StreamStatsResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/stream/stats [get]
+func (h *StreamHandler) GetStats(c *gin.Context) {
+	stats, err := h.streamService.Stats(c.Request.Context())
+	if err != nil {
+		logger.From(c).Errorf("Failed to get stream stats: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取推送统计失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, stats)
+}
+
+// streamConn 一个推送连接的订阅者、推送方式和待补发的历史事件
+type streamConn struct {
+	sub     *service.StreamSubscriber
+	options model.StreamOptions
+	replay  []*model.StreamEvent
+}
+
+// pump 先补发历史事件，再推送订阅者收到的事件直到连接关闭，订阅因服务关闭而结束时返回true
+func (h *StreamHandler) pump(stream *streamConn, closed <-chan struct{}, send func(*model.StreamEvent) error, ping func() error) bool {
+	// 补发的事件可能在订阅后又实时收到一次，按事件标识去重
+	replayed := make(map[string]bool, len(stream.replay))
+	for _, event := range stream.replay {
+		frame := *event
+		frame.Replay = true
+		if err := send(&frame); err != nil {
+			return false
+		}
+		replayed[streamEventKey(event)] = true
+	}
+
+	encoder := newStreamEncoder(stream.options, h.snapshotInterval)
+
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+
+	// 不限频时flush为nil，不会触发
+	var flush <-chan time.Time
+	if interval := encoder.flushInterval(); interval > 0 {
+		flushTicker := time.NewTicker(interval)
+		defer flushTicker.Stop()
+		flush = flushTicker.C
+	}
+
+	for {
+		select {
+		case <-closed:
+			return false
+		case event, ok := <-stream.sub.Events():
+			if !ok {
+				return true
+			}
+			if len(replayed) > 0 {
+				if key := streamEventKey(event); replayed[key] {
+					delete(replayed, key)
+					continue
+				}
+			}
+			if frame := encoder.Add(event, time.Now()); frame != nil {
+				if err := send(frame); err != nil {
+					return false
+				}
+			}
+		case now := <-flush:
+			for _, frame := range encoder.Flush(now) {
+				if err := send(frame); err != nil {
+					return false
+				}
+			}
+		case <-ticker.C:
+			if err := ping(); err != nil {
+				return false
+			}
+		}
+	}
+}
+
+// streamEventKey 事件标识，由产生事件的实例、类型、币种和时间组成
+func streamEventKey(event *model.StreamEvent) string {
+	return event.Node + "|" + event.Type + "|" + event.Symbol + "|" + strconv.FormatInt(event.Timestamp.UnixNano(), 10)
+}
+
+// subscribe 按subscription参数或types、symbols等参数注册订阅者，并读取replay参数要求补发的历史事件
+// 失败时已写出错误响应
+func (h *StreamHandler) subscribe(c *gin.Context) (*streamConn, bool) {
+	log := logger.From(c)
+
+	if !h.streamService.Enabled() {
+		h.respondWithError(c, http.StatusServiceUnavailable, "推送未启用", "stream disabled")
+		return nil, false
+	}
+
+	replay, err := strconv.Atoi(c.DefaultQuery("replay", "0"))
+	if err != nil || replay < 0 {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid replay")
+		return nil, false
+	}
+
+	stream := &streamConn{}
+	if id := c.Query("subscription"); id != "" {
+		var subscription *model.StreamSubscription
+		stream.sub, subscription, err = h.streamService.SubscribeByID(c.Request.Context(), id, userIDFrom(c))
+		if err == nil {
+			stream.options = subscription.StreamOptions
+		}
+	} else {
+		stream.options, err = parseStreamOptions(c)
+		if err == nil {
+			stream.sub, err = h.streamService.Subscribe(model.StreamFilter{
+				Types:   splitList(c.Query("types"), false),
+				Symbols: splitList(c.Query("symbols"), true),
+				UserID:  userIDFrom(c),
+			})
+		}
+	}
+	if err != nil {
+		log.Warnf("Failed to subscribe stream: %v", err)
+		if errors.Is(err, service.ErrTooManyConnections) {
+			h.respondWithError(c, http.StatusServiceUnavailable, "连接数已达上限", err.Error())
+		} else {
+			h.respondWithError(c, errorStatus(c, err), "订阅推送失败", err.Error())
+		}
+		return nil, false
+	}
+
+	// 先订阅再读取历史事件，避免两者之间的事件丢失
+	if replay > 0 {
+		stream.replay, err = h.streamService.Replay(c.Request.Context(), stream.sub.Filter(), replay)
+		if err != nil {
+			// 补发失败不影响实时推送
+			log.Warnf("Failed to load stream replay: %v", err)
+		}
+	}
+	return stream, true
+}
+
+// parseStreamOptions 解析delta和max_rate参数
+func parseStreamOptions(c *gin.Context) (model.StreamOptions, error) {
+	var options model.StreamOptions
+	if value := c.Query("delta"); value != "" {
+		delta, err := strconv.ParseBool(value)
+		if err != nil {
+			return options, fmt.Errorf("%w: invalid delta", service.ErrInvalidParameter)
+		}
+		options.Delta = delta
+	}
+	if value := c.Query("max_rate"); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return options, fmt.Errorf("%w: invalid max_rate", service.ErrInvalidParameter)
+		}
+		options.MaxRate = rate
+	}
+	return options, service.ValidateStreamOptions(options)
+}
+
+// splitList 解析逗号分隔的参数，去除空值
+func splitList(value string, upper bool) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if upper {
+			item = strings.ToUpper(item)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// respondWithSuccess 成功响应
+func (h *StreamHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithStatus 以指定状态码返回成功响应
+func (h *StreamHandler) respondWithStatus(c *gin.Context, statusCode int, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
+
+// respondWithError 错误响应
+func (h *StreamHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandler 代币元数据处理器
+type TokenHandler struct {
+	tokenService     service.TokenService
+	ingestService    service.IngestService
+	tokenSyncService service.TokenSyncService
+	safetyService    service.TokenSafetyService
+}
+
+// NewTokenHandler 创建代币元数据处理器
+func NewTokenHandler(tokenService service.TokenService, ingestService service.IngestService, tokenSyncService service.TokenSyncService, safetyService service.TokenSafetyService) *TokenHandler {
+	return &TokenHandler{
+		tokenService:     tokenService,
+		ingestService:    ingestService,
+		tokenSyncService: tokenSyncService,
+		safetyService:    safetyService,
+	}
+}
+
+// ListTokens 获取代币列表
+// @Summary 获取代币列表
+// @Description 获取代币注册表中的代币元数据，可按符号过滤
+// @Tags 代币
+// @Produce json
+// @Param symbol query string false "代币符号"
+// @Success 200 {object} model.TokenListResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/tokens [get]
+func (h *TokenHandler) ListTokens(c *gin.Context) {
+	tokens, err := h.tokenService.ListTokens(c.Request.Context(), c.Query("symbol"))
+	if err != nil {
+		logger.From(c).Errorf("Failed to list tokens: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取代币列表失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, &model.TokenListResponse{Tokens: tokens, Total: len(tokens)})
+}
+
+// GetToken 获取代币元数据
+// @Summary 获取代币元数据
+// @Description 根据合约地址获取代币元数据
+// @Tags 代币
+// @Produce json
+// @Param address path string true "合约地址"
+// @Success 200 {object} model.TokenMetadata
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/tokens/{address} [get]
+func (h *TokenHandler) GetToken(c *gin.Context) {
+	token, err := h.tokenService.GetToken(c.Request.Context(), c.Param("address"))
+	if err != nil {
+		h.respondWithError(c, errorStatus(c, err), "获取代币信息失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, token)
+}
+
+// GetTokenSafety 获取代币安全检查报告
+// @Summary 获取代币安全检查报告
+// @Description 检查代币合约是否部署、源码是否在BscScan验证、是否在可信代币列表中以及地址标签，返回风险提示
+// @Tags 代币
+// @Produce json
+// @Param address query string true "合约地址"
+// @Success 200 {object} model.TokenSafetyReport
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/bsc/token/safety [get]
+func (h *TokenHandler) GetTokenSafety(c *gin.Context) {
+	address := c.Query("address")
+	log := logger.From(c)
+
+	log.Infof("Checking token safety for %s", address)
+
+	report, err := h.safetyService.GetTokenSafety(c.Request.Context(), address)
+	if err != nil {
+		log.Errorf("Failed to check token safety: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取代币安全信息失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, report)
+}
+
+// GetLabel 获取地址标签
+// @Summary 获取地址标签
+// @Description 获取地址对应的标签，如交易所、跨链桥等
+// @Tags 代币
+// @Produce json
+// @Param address path string true "地址"
+// @Success 200 {object} model.AddressLabel
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/labels/{address} [get]
+func (h *TokenHandler) GetLabel(c *gin.Context) {
+	label, err := h.tokenService.GetLabel(c.Request.Context(), c.Param("address"))
+	if err != nil {
+		h.respondWithError(c, errorStatus(c, err), "获取地址标签失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, label)
+}
+
+// GetSyncStatus 获取代币列表同步状态
+// @Summary 获取代币列表同步状态
+// @Description 获取各可信代币列表来源的最近同步结果
+// @Tags 代币
+// @Produce json
+// @Success 200 {array} model.TokenSyncStatus
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/tokens/sync [get]
+func (h *TokenHandler) GetSyncStatus(c *gin.Context) {
+	statuses, err := h.tokenSyncService.GetStatus(c.Request.Context())
+	if err != nil {
+		logger.From(c).Errorf("Failed to get token sync status: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取代币同步状态失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, statuses)
+}
+
+// TriggerSync 立即同步代币列表
+// @Summary 立即同步代币列表
+// @Description 立即从所有可信来源同步代币列表并在链上校验合约
+// @Tags 管理
+// @Produce json
+// @Success 200 {array} model.TokenSyncStatus
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/admin/tokens/sync [post]
+func (h *TokenHandler) TriggerSync(c *gin.Context) {
+	logger.From(c).Info("Triggering token list sync")
+
+	statuses, err := h.tokenSyncService.SyncNow(c.Request.Context())
+	if err != nil {
+		logger.From(c).Errorf("Failed to sync token lists: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "同步代币列表失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, statuses)
+}
+
+// GetIngestLog 获取数据文件导入日志
+// @Summary 获取数据文件导入日志
+// @Description 获取监听目录中数据文件的导入记录，按时间倒序
+// @Tags 代币
+// @Produce json
+// @Param limit query int false "返回数量" default(50)
+// @Success 200 {array} model.IngestRecord
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/ingest/log [get]
+func (h *TokenHandler) GetIngestLog(c *gin.Context) {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	records, err := h.ingestService.GetLog(c.Request.Context(), limit)
+	if err != nil {
+		logger.From(c).Errorf("Failed to get ingest log: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取导入日志失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, records)
+}
+
+// TriggerIngest 立即扫描导入目录
+// @Summary 立即扫描导入目录
+// @Description 立即扫描监听目录并导入已写入完成的文件
+// @Tags 管理
+// @Produce json
+// @Success 200 {array} model.IngestRecord
+// @Failure 400 {object} model.ErrorResponse
+// @Router /api/v1/admin/ingest/scan [post]
+func (h *TokenHandler) TriggerIngest(c *gin.Context) {
+	records, err := h.ingestService.ScanNow(c.Request.Context())
+	if err != nil {
+		logger.From(c).Errorf("Failed to scan ingest directory: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "扫描导入目录失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, records)
+}
+
+// respondWithSuccess 成功响应
+func (h *TokenHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *TokenHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
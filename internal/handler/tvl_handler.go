@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultTVLRange 未指定from时默认查询的时间范围
+const defaultTVLRange = 24 * time.Hour
+
+// TVLHandler 锁仓价值处理器
+type TVLHandler struct {
+	tvlService service.TVLService
+}
+
+// NewTVLHandler 创建锁仓价值处理器
+func NewTVLHandler(tvlService service.TVLService) *TVLHandler {
+	return &TVLHandler{
+		tvlService: tvlService,
+	}
+}
+
+// GetOverview 获取锁仓价值概览
+// @Summary 获取锁仓价值概览
+// @Description 获取跟踪交易对及其代币最近一次计算的锁仓价值(储备量×价格)
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.TVLOverview
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/bsc/tvl [get]
+func (h *TVLHandler) GetOverview(c *gin.Context) {
+	log := logger.From(c)
+
+	overview, err := h.tvlService.GetOverview(c.Request.Context())
+	if err != nil {
+		log.Errorf("Failed to get TVL overview: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取锁仓价值失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, overview)
+}
+
+// GetHistory 获取锁仓价值历史
+// @Summary 获取锁仓价值历史
+// @Description 获取交易对或代币的锁仓价值时间序列，pair与token二选一
+// @Tags BSC
+// @Accept json
+// @Produce json
+// @Param pair query string false "交易对地址"
+// @Param token query string false "代币地址"
+// @Param from query string false "开始时间，RFC3339或Unix秒" default(24小时前)
+// @Param to query string false "结束时间，RFC3339或Unix秒" default(当前时间)
+// @Success 200 {object} model.TVLHistoryResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/bsc/tvl/history [get]
+func (h *TVLHandler) GetHistory(c *gin.Context) {
+	log := logger.From(c)
+
+	kind, address := service.TVLKindPair, c.Query("pair")
+	if address == "" {
+		kind, address = service.TVLKindToken, c.Query("token")
+	}
+	if address == "" {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "pair or token is required")
+		return
+	}
+
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := parseTimeParam(toStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "无效的结束时间", err.Error())
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultTVLRange)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := parseTimeParam(fromStr)
+		if err != nil {
+			h.respondWithError(c, http.StatusBadRequest, "无效的开始时间", err.Error())
+			return
+		}
+		from = parsed
+	}
+
+	log.Infof("Getting TVL history for %s %s, from: %s, to: %s", kind, address, from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	history, err := h.tvlService.GetHistory(c.Request.Context(), kind, address, from, to)
+	if err != nil {
+		log.Errorf("Failed to get TVL history: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取锁仓价值历史失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, history)
+}
+
+// respondWithSuccess 成功响应
+func (h *TVLHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithError 错误响应
+func (h *TVLHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
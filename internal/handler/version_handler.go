@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VersionHandler 实例构建信息处理器
+type VersionHandler struct {
+	versionService service.VersionService
+}
+
+// NewVersionHandler 创建实例构建信息处理器
+func NewVersionHandler(versionService service.VersionService) *VersionHandler {
+	return &VersionHandler{
+		versionService: versionService,
+	}
+}
+
+// GetVersion 获取构建信息和已启用的功能
+// @Summary 获取版本信息
+// @Description 获取实例的版本号、构建时间、提交哈希、已启用的功能和数据提供方，用于确认实例支持的能力
+// @Tags 管理
+// @Produce json
+// @Success 200 {object} model.VersionResponse
+// @Router /api/v1/version [get]
+func (h *VersionHandler) GetVersion(c *gin.Context) {
+	h.respondWithSuccess(c, h.versionService.Version())
+}
+
+// respondWithSuccess 成功响应
+func (h *VersionHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
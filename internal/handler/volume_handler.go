@@ -15,14 +15,12 @@ import (
 // VolumeHandler 交易量处理器
 type VolumeHandler struct {
 	volumeService service.VolumeService
-	logger        logger.Logger
 }
 
 // NewVolumeHandler 创建交易量处理器
 func NewVolumeHandler(volumeService service.VolumeService) *VolumeHandler {
 	return &VolumeHandler{
 		volumeService: volumeService,
-		logger:        logger.GetLogger(),
 	}
 }
 
@@ -41,7 +39,7 @@ func NewVolumeHandler(volumeService service.VolumeService) *VolumeHandler {
 func (h *VolumeHandler) GetVolumeAnalysis(c *gin.Context) {
 	symbol := c.Query("symbol")
 	daysStr := c.Query("days")
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 
 	days := 10 // 默认值
 	if daysStr != "" {
@@ -50,12 +48,12 @@ func (h *VolumeHandler) GetVolumeAnalysis(c *gin.Context) {
 		}
 	}
 
-	h.logger.WithField("request_id", requestID).Infof("Getting volume analysis for symbol: %s, days: %d", symbol, days)
+	log.Infof("Getting volume analysis for symbol: %s, days: %d", symbol, days)
 
 	analysis, err := h.volumeService.GetVolumeAnalysis(c.Request.Context(), symbol, days)
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get volume analysis: %v", err)
-		h.respondWithError(c, http.StatusInternalServerError, "获取交易量分析失败", err.Error())
+		log.Errorf("Failed to get volume analysis: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取交易量分析失败", err.Error())
 		return
 	}
 
@@ -77,7 +75,7 @@ func (h *VolumeHandler) GetVolumeAnalysis(c *gin.Context) {
 func (h *VolumeHandler) GetMarketVolumeFluctuation(c *gin.Context) {
 	symbol := c.Query("symbol")
 	daysStr := c.Query("days")
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 
 	days := 10 // 默认值
 	if daysStr != "" {
@@ -86,11 +84,11 @@ func (h *VolumeHandler) GetMarketVolumeFluctuation(c *gin.Context) {
 		}
 	}
 
-	h.logger.WithField("request_id", requestID).Infof("Getting market volume fluctuation for symbol: %s, days: %d", symbol, days)
+	log.Infof("Getting market volume fluctuation for symbol: %s, days: %d", symbol, days)
 
 	fluctuation, err := h.volumeService.GetMarketVolumeFluctuation(c.Request.Context(), symbol, days)
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get market volume fluctuation: %v", err)
+		log.Errorf("Failed to get market volume fluctuation: %v", err)
 		h.respondWithError(c, http.StatusInternalServerError, "获取市场交易量波动失败", err.Error())
 		return
 	}
@@ -113,7 +111,7 @@ func (h *VolumeHandler) GetMarketVolumeFluctuation(c *gin.Context) {
 func (h *VolumeHandler) GetVolumeComparison(c *gin.Context) {
 	symbolsStr := c.Query("symbols")
 	daysStr := c.Query("days")
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 
 	var symbols []string
 	if symbolsStr != "" {
@@ -133,11 +131,11 @@ func (h *VolumeHandler) GetVolumeComparison(c *gin.Context) {
 		}
 	}
 
-	h.logger.WithField("request_id", requestID).Infof("Getting volume comparison for symbols: %v, days: %d", symbols, days)
+	log.Infof("Getting volume comparison for symbols: %v, days: %d", symbols, days)
 
 	comparison, err := h.volumeService.GetVolumeComparison(c.Request.Context(), symbols, days)
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get volume comparison: %v", err)
+		log.Errorf("Failed to get volume comparison: %v", err)
 		h.respondWithError(c, http.StatusInternalServerError, "获取交易量对比失败", err.Error())
 		return
 	}
@@ -160,7 +158,7 @@ func (h *VolumeHandler) GetVolumeComparison(c *gin.Context) {
 func (h *VolumeHandler) GetTopVolumeCoins(c *gin.Context) {
 	daysStr := c.Query("days")
 	limitStr := c.Query("limit")
-	requestID := c.GetString("request_id")
+	log := logger.From(c)
 
 	days := 10 // 默认值
 	if daysStr != "" {
@@ -176,11 +174,11 @@ func (h *VolumeHandler) GetTopVolumeCoins(c *gin.Context) {
 		}
 	}
 
-	h.logger.WithField("request_id", requestID).Infof("Getting top volume coins for days: %d, limit: %d", days, limit)
+	log.Infof("Getting top volume coins for days: %d, limit: %d", days, limit)
 
 	topCoins, err := h.volumeService.GetTopVolumeCoins(c.Request.Context(), days, limit)
 	if err != nil {
-		h.logger.WithField("request_id", requestID).Errorf("Failed to get top volume coins: %v", err)
+		log.Errorf("Failed to get top volume coins: %v", err)
 		h.respondWithError(c, http.StatusInternalServerError, "获取交易量排行失败", err.Error())
 		return
 	}
@@ -222,4 +220,4 @@ func (h *VolumeHandler) respondWithError(c *gin.Context, statusCode int, message
 	}
 
 	c.JSON(statusCode, response)
-}
\ No newline at end of file
+}
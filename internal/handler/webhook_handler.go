@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler 告警webhook投递记录和用户webhook处理器
+type WebhookHandler struct {
+	webhooks service.WebhookService
+}
+
+// NewWebhookHandler 创建webhook处理器
+func NewWebhookHandler(webhooks service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhooks: webhooks,
+	}
+}
+
+// ListDeliveries 获取webhook投递记录
+// @Summary 获取webhook投递记录
+// @Description 按时间倒序返回每次投递的状态码、耗时和响应片段，status=failed只返回未成功重新投递的失败记录
+// @Tags 管理
+// @Produce json
+// @Param webhook query string false "webhook名称"
+// @Param status query string false "投递状态(failed/succeeded)"
+// @Param limit query int false "返回数量，最大200" default(50)
+// @Success 200 {object} model.WebhookDeliveryListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/admin/webhooks/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid limit")
+		return
+	}
+
+	deliveries, err := h.webhooks.ListDeliveries(c.Request.Context(), c.Query("webhook"), c.Query("status"), limit)
+	if err != nil {
+		logger.From(c).Errorf("Failed to list webhook deliveries: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取投递记录失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, deliveries)
+}
+
+// GetDelivery 获取webhook投递记录详情
+// @Summary 获取webhook投递记录详情
+// @Description 返回投递记录及投递的请求体
+// @Tags 管理
+// @Produce json
+// @Param id path string true "投递记录ID"
+// @Success 200 {object} model.WebhookDelivery
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/admin/webhooks/deliveries/{id} [get]
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	delivery, err := h.webhooks.GetDelivery(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		logger.From(c).Errorf("Failed to get webhook delivery: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取投递记录失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, delivery)
+}
+
+// RedriveDelivery 重新投递
+// @Summary 重新投递webhook
+// @Description 将原请求体重新投递到同名webhook的当前地址，返回新的投递记录；接收方返回非2xx时同样返回200，结果见success字段
+// @Tags 管理
+// @Produce json
+// @Param id path string true "投递记录ID"
+// @Success 200 {object} model.WebhookDelivery
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/admin/webhooks/deliveries/{id}/redrive [post]
+func (h *WebhookHandler) RedriveDelivery(c *gin.Context) {
+	delivery, err := h.webhooks.Redrive(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		logger.From(c).Errorf("Failed to redrive webhook delivery: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "重新投递失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, delivery)
+}
+
+// CreateUserWebhook 注册webhook
+// @Summary 注册webhook
+// @Description 注册接收当前用户价格告警的webhook，告警以JSON POST并带 X-Signature: sha256=<HMAC-SHA256>，失败时按指数退避重试。未指定secret时自动生成，只在本次响应中返回
+// @Tags 告警
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param request body model.UserWebhookRequest true "webhook"
+// @Success 201 {object} model.UserWebhook
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) CreateUserWebhook(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	var req model.UserWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	webhook, err := h.webhooks.CreateUserWebhook(c.Request.Context(), userID, &req)
+	if err != nil {
+		logger.From(c).Errorf("Failed to create webhook: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "注册webhook失败", err.Error())
+		return
+	}
+
+	h.respondWithStatus(c, http.StatusCreated, webhook)
+}
+
+// ListUserWebhooks 获取webhook列表
+// @Summary 获取webhook列表
+// @Description 获取当前用户注册的webhook，不含签名密钥
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Success 200 {object} model.UserWebhookListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) ListUserWebhooks(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	webhooks, err := h.webhooks.ListUserWebhooks(c.Request.Context(), userID)
+	if err != nil {
+		logger.From(c).Errorf("Failed to list webhooks: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取webhook失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, webhooks)
+}
+
+// DeleteUserWebhook 删除webhook
+// @Summary 删除webhook
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "webhook ID"
+// @Success 200 {object} model.APIResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteUserWebhook(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.webhooks.DeleteUserWebhook(c.Request.Context(), userID, id); err != nil {
+		logger.From(c).Errorf("Failed to delete webhook: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "删除webhook失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, gin.H{"id": id})
+}
+
+// ListUserDeliveries 获取webhook的投递记录
+// @Summary 获取webhook的投递记录
+// @Description 按时间倒序返回当前用户webhook的投递记录，attempts为含重试的投递次数
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "webhook ID"
+// @Param limit query int false "返回数量，最大200" default(50)
+// @Success 200 {object} model.WebhookDeliveryListResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListUserDeliveries(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "参数错误", "invalid limit")
+		return
+	}
+
+	deliveries, err := h.webhooks.ListUserDeliveries(c.Request.Context(), userID, c.Param("id"), limit)
+	if err != nil {
+		logger.From(c).Errorf("Failed to list webhook deliveries: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "获取投递记录失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, deliveries)
+}
+
+// RedriveUserDelivery 重新投递webhook的投递记录
+// @Summary 重新投递用户webhook
+// @Description 将原请求体重新投递到webhook的当前地址，返回新的投递记录，只能重新投递当前用户的记录
+// @Tags 告警
+// @Produce json
+// @Param X-User-ID header string true "用户ID"
+// @Param id path string true "webhook ID"
+// @Param delivery_id path string true "投递记录ID"
+// @Success 200 {object} model.WebhookDelivery
+// @Failure 404 {object} model.ErrorResponse
+// @Router /api/v1/webhooks/{id}/deliveries/{delivery_id}/redrive [post]
+func (h *WebhookHandler) RedriveUserDelivery(c *gin.Context) {
+	userID := userIDFrom(c)
+	if userID == "" {
+		h.respondWithError(c, http.StatusBadRequest, "缺少用户标识", "X-User-ID header or session user is required")
+		return
+	}
+
+	delivery, err := h.webhooks.RedriveUserDelivery(c.Request.Context(), userID, c.Param("id"), c.Param("delivery_id"))
+	if err != nil {
+		logger.From(c).Errorf("Failed to redrive webhook delivery: %v", err)
+		h.respondWithError(c, errorStatus(c, err), "重新投递失败", err.Error())
+		return
+	}
+
+	h.respondWithSuccess(c, delivery)
+}
+
+// respondWithSuccess 成功响应
+func (h *WebhookHandler) respondWithSuccess(c *gin.Context, data interface{}) {
+	h.respondWithStatus(c, http.StatusOK, data)
+}
+
+// respondWithStatus 指定状态码的成功响应
+func (h *WebhookHandler) respondWithStatus(c *gin.Context, statusCode int, data interface{}) {
+	response := model.APIResponse{
+		Success: true,
+		Data:    data,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
+
+// respondWithError 错误响应
+func (h *WebhookHandler) respondWithError(c *gin.Context, statusCode int, message, detail string) {
+	errorResp := &model.ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	response := model.APIResponse{
+		Success: false,
+		Error:   errorResp,
+		Meta: &model.Meta{
+			RequestID: c.GetString("request_id"),
+			Timestamp: c.GetTime("timestamp"),
+			Version:   "v1",
+		},
+	}
+
+	c.JSON(statusCode, response)
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// 地址动态类型
+const (
+	ActivityNativeTransfer = "native_transfer" // BNB转账，包括合约内部转账
+	ActivityTokenTransfer  = "token_transfer"  // BEP20代币转账
+	ActivitySwap           = "swap"            // 代币兑换
+	ActivityApproval       = "approval"        // 代币授权
+	ActivityContractCall   = "contract_call"   // 其他合约调用
+)
+
+// 资金流向
+const (
+	ActivityDirectionIn   = "in"
+	ActivityDirectionOut  = "out"
+	ActivityDirectionSelf = "self"
+)
+
+// ActivityItem 地址动态
+type ActivityItem struct {
+	Type              string        `json:"type"`                         // 动态类型
+	TxHash            string        `json:"tx_hash"`                      // 交易哈希
+	BlockNumber       uint64        `json:"block_number"`                 // 区块号
+	Timestamp         time.Time     `json:"timestamp"`                    // 区块时间
+	Direction         string        `json:"direction,omitempty"`          // 相对于查询地址的流向
+	Counterparty      string        `json:"counterparty,omitempty"`       // 对手方地址
+	CounterpartyLabel string        `json:"counterparty_label,omitempty"` // 对手方标签
+	CounterpartyName  string        `json:"counterparty_name,omitempty"`  // 对手方域名
+	Method            string        `json:"method,omitempty"`             // 调用的合约方法
+	Legs              []ActivityLeg `json:"legs,omitempty"`               // 资产变动
+	Description       string        `json:"description"`                  // 可读描述
+	Success           bool          `json:"success"`                      // 交易是否成功
+}
+
+// ActivityLeg 单项资产变动
+type ActivityLeg struct {
+	Token     string `json:"token"`     // 代币合约地址，BNB为空
+	Symbol    string `json:"symbol"`    // 代币符号
+	Amount    string `json:"amount"`    // 按精度换算的数量，授权时为授权额度，精度未知时为空
+	Direction string `json:"direction"` // 流向
+}
+
+// AddressActivityResponse 地址动态响应
+type AddressActivityResponse struct {
+	Address  string         `json:"address"`        // 查询地址
+	Name     string         `json:"name,omitempty"` // 查询地址的域名
+	Items    []ActivityItem `json:"items"`          // 按时间倒序的动态
+	Page     int            `json:"page"`           // 页码
+	PageSize int            `json:"page_size"`      // 每页数量
+	HasMore  bool           `json:"has_more"`       // 是否还有下一页
+	Source   string         `json:"source"`         // 数据来源
+}
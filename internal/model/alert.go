@@ -0,0 +1,182 @@
+package model
+
+import "time"
+
+// 告警级别
+const (
+	AlertSeverityInfo     = "info"
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+)
+
+// Alert 告警事件
+type Alert struct {
+	ID        string                 `json:"id"`                // 告警ID
+	Type      string                 `json:"type"`              // 告警类型，如tvl_drop
+	Severity  string                 `json:"severity"`          // 级别(info/warning/critical)
+	Title     string                 `json:"title"`             // 标题
+	Message   string                 `json:"message"`           // 详细描述
+	Subject   string                 `json:"subject,omitempty"` // 相关对象，如交易对地址
+	UserID    string                 `json:"user_id,omitempty"` // 接收用户，系统告警为空
+	DedupKey  string                 `json:"-"`                 // 冷却期内相同键的告警只发送一次
+	Data      map[string]interface{} `json:"data,omitempty"`    // 附加数据
+	CreatedAt time.Time              `json:"created_at"`        // 产生时间
+}
+
+// AlertListResponse 告警列表响应
+type AlertListResponse struct {
+	Alerts []Alert `json:"alerts"`
+	Total  int     `json:"total"`
+}
+
+// 告警通知渠道
+const (
+	AlertChannelLog      = "log"      // 服务日志
+	AlertChannelStream   = "stream"   // 实时推送(WebSocket/SSE)
+	AlertChannelWebhook  = "webhook"  // 配置的webhook
+	AlertChannelTelegram = "telegram" // Telegram机器人
+	AlertChannelEmail    = "email"    // SMTP邮件
+	AlertChannelDiscord  = "discord"  // Discord频道webhook
+)
+
+// AlertDelivery 告警在单个通知渠道的投递结果
+type AlertDelivery struct {
+	Channel    string `json:"channel"`
+	Target     string `json:"target,omitempty"` // 渠道内的投递目标，如webhook名称
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	LatencyMs  int64  `json:"latency_ms"`
+	DeliveryID string `json:"delivery_id,omitempty"` // webhook投递记录ID
+	Digested   bool   `json:"digested,omitempty"`    // 超出发送频率限制，合并到摘要中稍后发送
+}
+
+// 告警类型
+const (
+	AlertTypeScheduled = "scheduled" // 定时通知
+	AlertTypeRule      = "rule"      // 用户条件告警
+	AlertTypeDigest    = "digest"    // 超出发送频率限制的告警摘要
+	AlertTypeReport    = "report"    // 定时任务发送的行情日报
+)
+
+// 告警条件可使用的指标
+const (
+	AlertSignalPrice       = "price"        // 当前价格
+	AlertSignalChange24h   = "change_24h"   // 24小时涨跌幅(%)
+	AlertSignalVolume      = "volume"       // 当天交易量
+	AlertSignalVolumeRatio = "volume_ratio" // 当天交易量与前7天日均交易量之比，大于2通常视为放量
+	AlertSignalRSI         = "rsi"          // 1小时K线的14周期RSI
+)
+
+// 告警规则的阈值类型，创建规则时可代替条件表达式
+const (
+	AlertThresholdAbove         = "above"          // 价格高于阈值
+	AlertThresholdBelow         = "below"          // 价格低于阈值
+	AlertThresholdPercentChange = "percent_change" // 24小时涨跌幅达到阈值(%)，负数表示跌幅
+)
+
+// ScheduledAlert 定时通知规则，按cron表达式定时推送币种价格
+type ScheduledAlert struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Name      string     `json:"name,omitempty"`
+	Schedule  string     `json:"schedule"` // cron表达式(分 时 日 月 周)，如"0 9 * * *"
+	Timezone  string     `json:"timezone"` // 计算执行时间的时区，如Asia/Shanghai
+	Symbols   []string   `json:"symbols"`
+	Enabled   bool       `json:"enabled"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"` // 下次执行时间，停用时为空
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"` // 最近一次执行失败的原因
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ScheduledAlertRequest 创建或更新定时通知的请求
+type ScheduledAlertRequest struct {
+	Name     string   `json:"name"`
+	Schedule string   `json:"schedule" binding:"required"`
+	Timezone string   `json:"timezone"` // 为空时使用配置的默认时区
+	Symbols  []string `json:"symbols" binding:"required"`
+	Enabled  *bool    `json:"enabled"` // 为空时启用
+}
+
+// ScheduledAlertListResponse 定时通知列表响应
+type ScheduledAlertListResponse struct {
+	Alerts []ScheduledAlert `json:"alerts"`
+	Total  int              `json:"total"`
+}
+
+// AlertRule 用户条件告警规则，条件为组合多个指标的表达式，由不成立变为成立时触发
+type AlertRule struct {
+	ID              string             `json:"id"`
+	UserID          string             `json:"user_id"`
+	Name            string             `json:"name,omitempty"`
+	Symbol          string             `json:"symbol"`
+	Condition       string             `json:"condition"`           // 如 price > 70000 AND (volume_ratio > 2 OR rsi < 30)
+	Type            string             `json:"type,omitempty"`      // 按阈值类型创建时的类型，条件由类型和阈值生成
+	Threshold       *float64           `json:"threshold,omitempty"` // 按阈值类型创建时的阈值
+	CooldownSeconds int                `json:"cooldown_seconds"`    // 两次触发的最小间隔
+	Enabled         bool               `json:"enabled"`
+	Triggered       bool               `json:"triggered"`             // 最近一次评估时条件是否成立
+	LastValues      map[string]float64 `json:"last_values,omitempty"` // 最近一次评估使用的指标
+	LastEvaluatedAt *time.Time         `json:"last_evaluated_at,omitempty"`
+	LastTriggeredAt *time.Time         `json:"last_triggered_at,omitempty"`
+	LastError       string             `json:"last_error,omitempty"` // 最近一次评估失败的原因
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// AlertRuleRequest 创建或更新告警规则的请求
+type AlertRuleRequest struct {
+	Name            string   `json:"name"`
+	Symbol          string   `json:"symbol" binding:"required"`
+	Condition       string   `json:"condition"`        // 条件表达式，与type二选一
+	Type            string   `json:"type"`             // 阈值类型: above/below/percent_change
+	Threshold       *float64 `json:"threshold"`        // 阈值，指定type时必填
+	CooldownSeconds int      `json:"cooldown_seconds"` // 为0时使用配置的默认冷却时间
+	Enabled         *bool    `json:"enabled"`          // 为空时启用
+}
+
+// AlertRuleListResponse 告警规则列表响应
+type AlertRuleListResponse struct {
+	Rules []AlertRule `json:"rules"`
+	Total int         `json:"total"`
+}
+
+// AlertRuleTestResult 告警规则试运行结果，试运行不改变规则的触发状态
+type AlertRuleTestResult struct {
+	RuleID      string             `json:"rule_id"`
+	Symbol      string             `json:"symbol"`
+	Condition   string             `json:"condition"`
+	Matched     bool               `json:"matched"`          // 当前数据下条件是否成立
+	Values      map[string]float64 `json:"values,omitempty"` // 评估使用的指标
+	Error       string             `json:"error,omitempty"`  // 指标获取或求值失败的原因
+	Notified    bool               `json:"notified"`         // 测试通知是否发送成功
+	NotifyError string             `json:"notify_error,omitempty"`
+	Alert       *Alert             `json:"alert,omitempty"` // 发送的测试通知
+	EvaluatedAt time.Time          `json:"evaluated_at"`
+}
+
+// AlertFiring 告警规则的一次触发记录
+type AlertFiring struct {
+	ID         string             `json:"id"`
+	RuleID     string             `json:"rule_id"`
+	AlertID    string             `json:"alert_id,omitempty"` // 发送的告警ID，重复告警被忽略时为空
+	Condition  string             `json:"condition"`          // 触发时的条件
+	Values     map[string]float64 `json:"values,omitempty"`   // 触发时的指标
+	Matched    bool               `json:"matched"`            // 条件是否成立，试运行时可能不成立
+	Test       bool               `json:"test,omitempty"`     // 是否为试运行
+	Channels   []string           `json:"channels"`           // 投递的通知渠道
+	Deliveries []AlertDelivery    `json:"deliveries"`         // 各渠道的投递结果
+	Error      string             `json:"error,omitempty"`    // 发送失败的原因
+	FiredAt    time.Time          `json:"fired_at"`
+}
+
+// AlertFiringListResponse 告警触发记录分页响应
+type AlertFiringListResponse struct {
+	RuleID   string        `json:"rule_id"`
+	Items    []AlertFiring `json:"items"`     // 按时间倒序的触发记录
+	Page     int           `json:"page"`      // 页码
+	PageSize int           `json:"page_size"` // 每页数量
+	Total    int           `json:"total"`     // 记录总数
+	HasMore  bool          `json:"has_more"`  // 是否还有下一页
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// UsageCount 单个币种或接口的请求次数
+type UsageCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// UsageReport 一段时间内所有实例合计的使用统计
+type UsageReport struct {
+	Hours         int          `json:"hours"`          // 统计的小时数，含当前小时
+	From          time.Time    `json:"from"`           // 第一个小时桶的开始时间(UTC)
+	TotalRequests int64        `json:"total_requests"` // API请求总数
+	Symbols       []UsageCount `json:"symbols"`        // 请求次数最多的币种，按次数倒序
+	Endpoints     []UsageCount `json:"endpoints"`      // 请求次数最多的接口(路由模板)，按次数倒序
+}
+
+// UsageEvent 发布到消息队列的使用事件，内容为一个写入周期内本实例的合计计数
+type UsageEvent struct {
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Symbols   map[string]int64 `json:"symbols"`
+	Endpoints map[string]int64 `json:"endpoints"`
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 状态快照中Redis key的数据类型
+const (
+	StateTypeString = "string"
+	StateTypeHash   = "hash"
+	StateTypeList   = "list"
+	StateTypeSet    = "set"
+	StateTypeZSet   = "zset"
+)
+
+// StateSnapshot Redis中关键状态的快照，key不含key前缀，可恢复到使用其他前缀的Redis；保存在MySQL中的用户数据在Tables中
+type StateSnapshot struct {
+	Version    int          `json:"version"`
+	CreatedAt  time.Time    `json:"created_at"`
+	Categories []string     `json:"categories"`        // 快照包含的分类
+	Secrets    string       `json:"secrets,omitempty"` // webhook签名密钥的处理方式：encrypted或redacted，加密备份文件中为空(明文)
+	Entries    []StateEntry `json:"entries"`
+	Tables     []StateTable `json:"tables,omitempty"`
+}
+
+// 导出接口中webhook签名密钥的处理方式
+const (
+	StateSecretsEncrypted = "encrypted" // 用backup.key加密，恢复时需要相同的密钥
+	StateSecretsRedacted  = "redacted"  // 未配置backup.key时删除，恢复后需重新设置
+)
+
+// StateTable 快照中的MySQL表，每行为一个JSON对象，字段为表的列名
+type StateTable struct {
+	Category string            `json:"category"`
+	Table    string            `json:"table"`
+	Rows     []json.RawMessage `json:"rows"`
+}
+
+// StateEntry 快照中的单个key，按type只有对应的值字段有数据
+type StateEntry struct {
+	Category string            `json:"category"`
+	Key      string            `json:"key"`
+	Type     string            `json:"type"`
+	TTL      int64             `json:"ttl_ms,omitempty"` // 导出时的剩余过期时间(毫秒)，0表示不过期
+	String   *string           `json:"string,omitempty"`
+	Hash     map[string]string `json:"hash,omitempty"`
+	List     []string          `json:"list,omitempty"`
+	Set      []string          `json:"set,omitempty"`
+	ZSet     []StateZMember    `json:"zset,omitempty"`
+}
+
+// StateZMember 有序集合成员
+type StateZMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// StateRestoreResult 状态恢复结果
+type StateRestoreResult struct {
+	Restored     int            `json:"restored"`   // 写入的key数量
+	Skipped      int            `json:"skipped"`    // 目标Redis中已存在且未要求覆盖的key数量
+	Categories   map[string]int `json:"categories"` // 各分类写入的key数量
+	SkippedKeys  []string       `json:"skipped_keys,omitempty"`
+	RestoredRows int            `json:"restored_rows,omitempty"` // 写入MySQL的行数
+	SkippedRows  int            `json:"skipped_rows,omitempty"`  // MySQL中主键已存在且未要求覆盖的行数
+}
+
+// BackupFile 加密备份文件
+type BackupFile struct {
+	Name       string    `json:"name"`
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	Keys       int       `json:"keys"`           // 备份的key数量
+	Rows       int       `json:"rows,omitempty"` // 备份的MySQL行数
+	Categories []string  `json:"categories"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"    // 正常放行
+	BreakerOpen     = "open"      // 熔断中，请求直接跳过
+	BreakerHalfOpen = "half_open" // 熔断到期，放行一次探测请求
+)
+
+// BreakerState 单个数据源的熔断器状态
+type BreakerState struct {
+	Source              string     `json:"source"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Successes           int64      `json:"successes"` // 进程启动以来的成功次数
+	Failures            int64      `json:"failures"`  // 进程启动以来计入熔断的失败次数
+	Rejected            int64      `json:"rejected"`  // 熔断期间被跳过的请求数
+	Trips               int64      `json:"trips"`     // 进入熔断的次数
+	LastError           string     `json:"last_error,omitempty"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"` // 熔断中时，下一次放行探测请求的时间
+}
+
+// BreakerStatusResponse 价格数据源熔断状态响应
+type BreakerStatusResponse struct {
+	Enabled          bool           `json:"enabled"` // 未启用时只统计不熔断
+	FailureThreshold int            `json:"failure_threshold"`
+	OpenTimeout      string         `json:"open_timeout"`
+	Sources          []BreakerState `json:"sources"`
+}
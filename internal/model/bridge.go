@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// 跨链转账方向
+const (
+	BridgeDirectionOutbound = "outbound" // 存入跨链桥，资产离开BSC
+	BridgeDirectionInbound  = "inbound"  // 从跨链桥提取，资产进入BSC
+)
+
+// BridgeTransfer 跨链转账记录，同一交易中同方向同代币的转账合并为一条
+type BridgeTransfer struct {
+	ID          string    `json:"id"`                  // 记录ID(交易哈希:方向:代币)
+	Bridge      string    `json:"bridge"`              // 跨链桥名称
+	Direction   string    `json:"direction"`           // 方向(outbound/inbound)
+	TxHash      string    `json:"tx_hash"`             // 交易哈希
+	BlockNumber uint64    `json:"block_number"`        // 区块号
+	Account     string    `json:"account"`             // 用户地址
+	Token       string    `json:"token"`               // 代币合约地址
+	Symbol      string    `json:"symbol"`              // 代币符号
+	Amount      float64   `json:"amount"`              // 数量(已按精度换算)
+	ValueUSD    float64   `json:"value_usd,omitempty"` // 美元价值，无法定价时为空
+	Timestamp   time.Time `json:"timestamp"`           // 区块时间
+}
+
+// BridgeStats 单个跨链桥的活动统计
+type BridgeStats struct {
+	Bridge              string     `json:"bridge"`                  // 跨链桥名称
+	Address             string     `json:"address"`                 // 合约地址
+	Deposits            int        `json:"deposits"`                // 转出笔数
+	Withdrawals         int        `json:"withdrawals"`             // 转入笔数
+	DepositVolumeUSD    float64    `json:"deposit_volume_usd"`      // 转出金额(美元)
+	WithdrawalVolumeUSD float64    `json:"withdrawal_volume_usd"`   // 转入金额(美元)
+	NetFlowUSD          float64    `json:"net_flow_usd"`            // 净流入BSC金额(美元)
+	Accounts            int        `json:"accounts"`                // 参与地址数
+	LastActivity        *time.Time `json:"last_activity,omitempty"` // 最近活动时间
+}
+
+// BridgeActivityResponse 跨链桥活动查询响应
+type BridgeActivityResponse struct {
+	From      time.Time        `json:"from"`      // 开始时间
+	To        time.Time        `json:"to"`        // 结束时间
+	Bridges   []BridgeStats    `json:"bridges"`   // 各跨链桥统计
+	Transfers []BridgeTransfer `json:"transfers"` // 最近的跨链转账，按时间倒序
+	Total     int              `json:"total"`     // 时间范围内的转账总数
+}
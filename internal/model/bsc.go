@@ -26,7 +26,8 @@ type BSCTransaction struct {
 	BlockNumber *big.Int       `json:"block_number"`
 	From        common.Address `json:"from"`
 	To          *common.Address `json:"to"`
-	Value       *big.Int       `json:"value"`
+	ValueRaw    *big.Int       `json:"value_raw"` // 原始数量(wei)
+	Value       string         `json:"value"`     // 按BNB精度换算的数量
 	GasPrice    *big.Int       `json:"gas_price"`
 	GasUsed     uint64         `json:"gas_used"`
 	Status      uint64         `json:"status"`
@@ -41,7 +42,9 @@ type BSCTokenTransfer struct {
 	Token       common.Address `json:"token"`
 	From        common.Address `json:"from"`
 	To          common.Address `json:"to"`
-	Amount      *big.Int       `json:"amount"`
+	AmountRaw   *big.Int       `json:"amount_raw"`         // 原始数量(最小单位)
+	Amount      string         `json:"amount,omitempty"`   // 按代币精度换算的数量，精度未知时为空
+	Decimals    *uint8         `json:"decimals,omitempty"` // 代币精度
 	Timestamp   time.Time      `json:"timestamp"`
 }
 
@@ -53,10 +56,14 @@ type BSCSwapEvent struct {
 	Pair         common.Address `json:"pair"`
 	Sender       common.Address `json:"sender"`
 	To           common.Address `json:"to"`
-	Amount0In    *big.Int       `json:"amount0_in"`
-	Amount1In    *big.Int       `json:"amount1_in"`
-	Amount0Out   *big.Int       `json:"amount0_out"`
-	Amount1Out   *big.Int       `json:"amount1_out"`
+	Amount0InRaw  *big.Int      `json:"amount0_in_raw"`
+	Amount1InRaw  *big.Int      `json:"amount1_in_raw"`
+	Amount0OutRaw *big.Int      `json:"amount0_out_raw"`
+	Amount1OutRaw *big.Int      `json:"amount1_out_raw"`
+	Amount0In    string         `json:"amount0_in,omitempty"` // 按token0精度换算，精度未知时为空
+	Amount1In    string         `json:"amount1_in,omitempty"` // 按token1精度换算
+	Amount0Out   string         `json:"amount0_out,omitempty"`
+	Amount1Out   string         `json:"amount1_out,omitempty"`
 	Timestamp    time.Time      `json:"timestamp"`
 }
 
@@ -89,8 +96,10 @@ type BSCPairInfo struct {
 	Address    common.Address `json:"address"`
 	Token0     common.Address `json:"token0"`
 	Token1     common.Address `json:"token1"`
-	Reserve0   *big.Int       `json:"reserve0"`
-	Reserve1   *big.Int       `json:"reserve1"`
+	Reserve0Raw *big.Int      `json:"reserve0_raw"`
+	Reserve1Raw *big.Int      `json:"reserve1_raw"`
+	Reserve0   string         `json:"reserve0,omitempty"` // 按token0精度换算的储备量，精度未知时为空
+	Reserve1   string         `json:"reserve1,omitempty"` // 按token1精度换算的储备量
 	TotalSupply *big.Int      `json:"total_supply"`
 	Price0     decimal.Decimal `json:"price0"`
 	Price1     decimal.Decimal `json:"price1"`
@@ -101,6 +110,13 @@ type BSCPairInfo struct {
 type BSCMonitoringStats struct {
 	LatestBlock      *big.Int  `json:"latest_block"`
 	ProcessedBlocks  uint64    `json:"processed_blocks"`
+	Checkpoint       uint64    `json:"checkpoint"`             // 已处理的最后一个区块，重启或恢复后从下一个区块继续
+	ResumedFrom      uint64    `json:"resumed_from,omitempty"` // 本次启动时的检查点
+	LagBlocks        uint64    `json:"lag_blocks"`             // 最新区块与检查点之间尚未处理的区块数
+	SkippedBlocks    uint64    `json:"skipped_blocks"`         // 超出max_catchup而跳过的区块数
+	StalledIntervals int       `json:"stalled_intervals"`      // 连续没有处理新区块的监控周期数
+	Lagging          bool      `json:"lagging"`                // 落后或停滞超出lag_alert阈值
+	LagReason        string    `json:"lag_reason,omitempty"`   // 滞后原因
 	TotalTransactions uint64   `json:"total_transactions"`
 	TotalTransfers   uint64    `json:"total_transfers"`
 	TotalSwaps       uint64    `json:"total_swaps"`
@@ -117,12 +133,19 @@ type BSCMonitoringResponse struct {
 	Message string              `json:"message"`
 }
 
+// 链上数据来源
+const (
+	DataSourceLocal   = "local"
+	DataSourceBscScan = "bscscan"
+)
+
 // BSCTransactionResponse BSC交易查询响应
 type BSCTransactionResponse struct {
 	Transactions []BSCTransaction `json:"transactions"`
 	Total        int              `json:"total"`
 	Page         int              `json:"page"`
 	PageSize     int              `json:"page_size"`
+	Source       string           `json:"source,omitempty"` // 数据来源：local或bscscan
 }
 
 // BSCTokenTransferResponse BSC代币转账查询响应
@@ -131,6 +154,7 @@ type BSCTokenTransferResponse struct {
 	Total     int                `json:"total"`
 	Page      int                `json:"page"`
 	PageSize  int                `json:"page_size"`
+	Source    string             `json:"source,omitempty"` // 数据来源：local或bscscan
 }
 
 // BSCSwapEventResponse BSC交换事件查询响应
@@ -145,4 +169,90 @@ type BSCSwapEventResponse struct {
 type BSCPairInfoResponse struct {
 	Pairs []BSCPairInfo `json:"pairs"`
 	Total int           `json:"total"`
-}
\ No newline at end of file
+}
+// BSCStatsCounts 区块监控处理量
+type BSCStatsCounts struct {
+	Blocks       uint64 `json:"blocks"`       // 处理的区块数
+	Transactions uint64 `json:"transactions"` // 区块中的交易数
+	Transfers    uint64 `json:"transfers"`    // 代币转账事件数
+	Swaps        uint64 `json:"swaps"`        // 兑换事件数
+	Liquidity    uint64 `json:"liquidity"`    // 添加和移除流动性事件数
+}
+
+// BSCStatsBucket 单个小时或天的处理量
+type BSCStatsBucket struct {
+	Start time.Time `json:"start"` // 桶的开始时间(UTC)
+	BSCStatsCounts
+}
+
+// BSCStatsHistoryResponse 区块监控处理量历史响应
+type BSCStatsHistoryResponse struct {
+	Granularity string           `json:"granularity"` // hour或day
+	From        time.Time        `json:"from"`
+	To          time.Time        `json:"to"`
+	Buckets     []BSCStatsBucket `json:"buckets"` // 按时间升序
+	Total       BSCStatsCounts   `json:"total"`   // 范围内的合计
+}
+
+// 网络拥堵等级
+const (
+	BSCCongestionLow      = "low"
+	BSCCongestionModerate = "moderate"
+	BSCCongestionHigh     = "high"
+	BSCCongestionSevere   = "severe"
+)
+
+// BSCBlockUtilization 单个区块的Gas使用情况
+type BSCBlockUtilization struct {
+	Number       uint64  `json:"number"`
+	Utilization  float64 `json:"utilization"`    // gasUsed/gasLimit，0~1
+	BaseFeeGwei  float64 `json:"base_fee_gwei"`  // 区块基础费用
+	GasPriceGwei float64 `json:"gas_price_gwei"` // 基础费用加小费中位数
+	Transactions int     `json:"transactions"`   // 查询失败时为-1
+}
+
+// BSCCongestion 最近区块的利用率、Gas价格和待处理交易积压汇总的网络拥堵情况
+type BSCCongestion struct {
+	Score                 float64               `json:"score"` // 拥堵评分，0~100
+	Level                 string                `json:"level"` // low/moderate/high/severe
+	LatestBlock           uint64                `json:"latest_block"`
+	SampleBlocks          int                   `json:"sample_blocks"`
+	AvgUtilization        float64               `json:"avg_utilization"` // 采样区块gasUsed/gasLimit的平均值
+	MaxUtilization        float64               `json:"max_utilization"`
+	FullBlocks            int                   `json:"full_blocks"` // 利用率达到95%的区块数
+	AvgGasPriceGwei       float64               `json:"avg_gas_price_gwei"`
+	SuggestedGasPriceGwei float64               `json:"suggested_gas_price_gwei"` // 节点eth_gasPrice的建议值
+	AvgTxsPerBlock        float64               `json:"avg_txs_per_block"`
+	PendingTxs            *uint64               `json:"pending_txs,omitempty"`    // 节点未提供时为空
+	PendingSource         string                `json:"pending_source,omitempty"` // txpool或pending_block
+	BacklogBlocks         *float64              `json:"backlog_blocks,omitempty"` // 按平均每块交易数估算清空待处理交易需要的区块数
+	Blocks                []BSCBlockUtilization `json:"blocks"`                   // 按区块号升序
+	UpdatedAt             time.Time             `json:"updated_at"`
+}
+
+// BSCValidatorStats 单个验证者在统计范围内的出块情况
+type BSCValidatorStats struct {
+	Address         common.Address `json:"address"`
+	Blocks          int            `json:"blocks"`             // 出块数
+	Share           float64        `json:"share"`              // 出块占比，0~1
+	ExpectedBlocks  float64        `json:"expected_blocks"`    // 按活跃验证者平均分配的期望出块数
+	MissedEstimate  int            `json:"missed_estimate"`    // 期望出块数减实际出块数，不小于0
+	OutOfTurnBlocks int            `json:"out_of_turn_blocks"` // 替其他验证者补出的区块数(difficulty为1)
+	FirstBlock      uint64         `json:"first_block"`
+	LastBlock       uint64         `json:"last_block"`
+	RecentBlocks    []uint64       `json:"recent_blocks"` // 最近出块的区块号，按区块号降序
+}
+
+// BSCValidatorsResponse 最近若干个epoch的出块验证者分布
+type BSCValidatorsResponse struct {
+	FromBlock        uint64              `json:"from_block"`
+	ToBlock          uint64              `json:"to_block"`
+	Epochs           int                 `json:"epochs"`
+	EpochLength      int                 `json:"epoch_length"`
+	TotalBlocks      int                 `json:"total_blocks"`       // 成功获取的区块数
+	FailedBlocks     int                 `json:"failed_blocks"`      // 获取失败、未计入统计的区块数
+	ActiveValidators int                 `json:"active_validators"`  // 范围内出过块的验证者数
+	OutOfTurnBlocks  int                 `json:"out_of_turn_blocks"` // 非轮值验证者出的区块数，反映轮值验证者漏块
+	Validators       []BSCValidatorStats `json:"validators"`         // 按出块数降序
+	UpdatedAt        time.Time           `json:"updated_at"`
+}
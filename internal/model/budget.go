@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// ProviderUsage 单个外部提供方的调用统计
+type ProviderUsage struct {
+	Provider     string    `json:"provider"`
+	HourlyCalls  int64     `json:"hourly_calls"`
+	HourlyBudget int64     `json:"hourly_budget,omitempty"` // 0表示不限制
+	DailyCalls   int64     `json:"daily_calls"`
+	DailyBudget  int64     `json:"daily_budget,omitempty"`
+	TotalCalls   int64     `json:"total_calls"` // 进程启动以来的调用次数
+	Rejected     int64     `json:"rejected"`    // 因超出预算被拒绝的次数
+	Exceeded     bool      `json:"exceeded"`    // 是否处于只读缓存模式
+	HourStart    time.Time `json:"hour_start"`
+	DayStart     time.Time `json:"day_start"`
+}
+
+// ProviderBudgetResponse 外部API调用预算响应
+type ProviderBudgetResponse struct {
+	Enabled   bool            `json:"enabled"` // 未启用时只统计不限制
+	Providers []ProviderUsage `json:"providers"`
+}
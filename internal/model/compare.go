@@ -0,0 +1,47 @@
+package model
+
+import "encoding/json"
+
+// 对比指标
+const (
+	CompareMetricPrice       = "price"       // 当前价格
+	CompareMetricVolume      = "volume"      // 周期总交易量
+	CompareMetricVolatility  = "volatility"  // 周期内收益率标准差(%)
+	CompareMetricCorrelation = "correlation" // 收益率相关系数矩阵
+)
+
+// CompareRow 单个币种的对比指标，未选择或无法计算的指标不返回
+type CompareRow struct {
+	Symbol     string   `json:"symbol"`
+	Price      *float64 `json:"price,omitempty"`
+	Precision  int      `json:"precision,omitempty"` // 价格小数位
+	Volume     *float64 `json:"volume,omitempty"`
+	Volatility *float64 `json:"volatility,omitempty"`
+	Samples    int      `json:"samples,omitempty"` // 计算波动率和相关性使用的K线数
+}
+
+// MarshalJSON 按Precision输出价格
+func (r CompareRow) MarshalJSON() ([]byte, error) {
+	type alias CompareRow
+	var price *json.Number
+	if r.Price != nil {
+		number := decimalNumber(*r.Price, r.Precision)
+		price = &number
+	}
+	return json.Marshal(struct {
+		alias
+		Price *json.Number `json:"price,omitempty"`
+	}{alias(r), price})
+}
+
+// CompareResponse 多币种对比响应
+type CompareResponse struct {
+	Symbols     []string      `json:"symbols"`               // 对比的币种，与相关系数矩阵的行列顺序一致
+	Metrics     []string      `json:"metrics"`               // 选择的指标
+	Days        int           `json:"days"`                  // 统计天数
+	Interval    string        `json:"interval"`              // 计算波动率和相关性的K线间隔
+	Rows        []CompareRow  `json:"rows"`                  // 各币种的指标
+	Correlation [][]*float64  `json:"correlation,omitempty"` // 收益率相关系数矩阵，数据不足时为null
+	Errors      []SymbolError `json:"errors,omitempty"`      // 获取失败的币种或指标
+	GeneratedAt string        `json:"generated_at"`          // 生成时间
+}
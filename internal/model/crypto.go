@@ -9,6 +9,68 @@ type PriceResponse struct {
 	Source    string  `json:"source"`     // 数据源
 	UpdatedAt string  `json:"updated_at"` // 更新时间
 	Currency  string  `json:"currency"`   // 货币单位
+	Precision int     `json:"precision"`  // 价格小数位，JSON中的price按此位数输出
+
+	Formatted string `json:"formatted,omitempty"` // 按locale参数格式化的价格，未指定locale时为空
+
+	Stats24h *PriceStats24h `json:"stats_24h,omitempty"` // 交易所行情中的24小时统计，数据源不提供时为空
+	Block    *PriceBlock    `json:"block,omitempty"`     // BSC链上价格计算时使用的区块，其他数据源为空
+	Cache    *CacheInfo     `json:"cache,omitempty"`     // 缓存新鲜度信息
+}
+
+// PriceBlock 链上价格所在的区块
+type PriceBlock struct {
+	Number        uint64 `json:"number"`        // 读取流动性池储备量的区块高度
+	LatestBlock   uint64 `json:"latest_block"`  // 计算时的最新区块高度
+	Confirmations uint64 `json:"confirmations"` // 计算时该区块之后的区块数
+}
+
+// PriceStats24h 24小时行情统计
+type PriceStats24h struct {
+	ChangePct   float64 `json:"change_pct"`   // 24小时涨跌幅(%)
+	Open        float64 `json:"open"`         // 24小时前的开盘价
+	High        float64 `json:"high"`         // 24小时最高价
+	Low         float64 `json:"low"`          // 24小时最低价
+	Volume      float64 `json:"volume"`       // 24小时成交量(币种)
+	QuoteVolume float64 `json:"quote_volume"` // 24小时成交额(计价币种)
+}
+
+// MarketCapResponse 市值响应结构
+type MarketCapResponse struct {
+	Currency    string          `json:"currency"`         // 计价货币
+	Source      string          `json:"source"`           // 数据源
+	Coins       []MarketCapData `json:"coins"`            // 各币种市值，按市值排名升序
+	Errors      []SymbolError   `json:"errors,omitempty"` // 获取失败的币种
+	GeneratedAt string          `json:"generated_at"`     // 生成时间
+}
+
+// MarketCapData 单个币种的市值和供应量
+type MarketCapData struct {
+	Symbol            string    `json:"symbol"`                        // 加密货币符号
+	Name              string    `json:"name"`                          // 币种名称
+	Rank              int       `json:"rank"`                          // 市值排名，0表示数据源未排名
+	Price             float64   `json:"price"`                         // 价格
+	MarketCap         float64   `json:"market_cap"`                    // 市值(价格×流通量)
+	CirculatingSupply float64   `json:"circulating_supply"`            // 流通量
+	TotalSupply       *float64  `json:"total_supply,omitempty"`        // 总供应量，数据源不提供时为空
+	MaxSupply         *float64  `json:"max_supply,omitempty"`          // 最大供应量，无上限或数据源不提供时为空
+	FullyDilutedValue *float64  `json:"fully_diluted_value,omitempty"` // 完全稀释估值
+	Volume24h         float64   `json:"volume_24h"`                    // 24小时成交额
+	UpdatedAt         time.Time `json:"updated_at"`                    // 数据源更新时间
+}
+
+// GlobalMarketData 全市场汇总数据
+type GlobalMarketData struct {
+	Currency               string    `json:"currency"`                // 计价货币
+	Source                 string    `json:"source"`                  // 数据源
+	TotalMarketCap         float64   `json:"total_market_cap"`        // 总市值
+	TotalVolume24h         float64   `json:"total_volume_24h"`        // 24小时总成交额
+	MarketCapChange24h     float64   `json:"market_cap_change_24h"`   // 总市值24小时涨跌幅(百分比)
+	BTCDominance           float64   `json:"btc_dominance"`           // BTC市值占比(百分比)
+	ETHDominance           float64   `json:"eth_dominance"`           // ETH市值占比(百分比)
+	ActiveCryptocurrencies int       `json:"active_cryptocurrencies"` // 数据源跟踪的币种数
+	Markets                int       `json:"markets"`                 // 数据源跟踪的交易所数
+	UpdatedAt              time.Time `json:"updated_at"`              // 数据源更新时间
 }
 
 // VolumeData 交易量数据结构
@@ -20,24 +82,35 @@ type VolumeData struct {
 
 // VolumeAnalysisResponse 交易量分析响应结构
 type VolumeAnalysisResponse struct {
-	Symbol      string        `json:"symbol"`       // 加密货币符号
-	Period      string        `json:"period"`       // 分析周期
-	Data        []VolumeData  `json:"data"`         // 历史交易量数据
-	AvgVolume   float64       `json:"avg_volume"`   // 平均交易量
-	MaxVolume   float64       `json:"max_volume"`   // 最大交易量
-	MinVolume   float64       `json:"min_volume"`   // 最小交易量
-	Volatility  float64       `json:"volatility"`   // 波动率
-	Trend       string        `json:"trend"`        // 趋势
-	Source      string        `json:"source"`       // 数据源
-	GeneratedAt string        `json:"generated_at"` // 生成时间
+	Symbol          string       `json:"symbol"`           // 加密货币符号
+	Period          string       `json:"period"`           // 分析周期
+	Data            []VolumeData `json:"data"`             // 历史交易量数据
+	AvgVolume       float64      `json:"avg_volume"`       // 平均交易量
+	TotalVolume     float64      `json:"total_volume"`     // 周期总交易量
+	MaxVolume       float64      `json:"max_volume"`       // 最大交易量
+	MinVolume       float64      `json:"min_volume"`       // 最小交易量
+	Volatility      float64      `json:"volatility"`       // 波动率
+	Trend           string       `json:"trend"`            // 趋势
+	TrendSlope      float64      `json:"trend_slope"`      // 趋势斜率（每日交易量变化）
+	TrendConfidence float64      `json:"trend_confidence"` // 趋势置信度 (0-1)
+	TrendStrength   string       `json:"trend_strength"`   // 趋势强度: strong_up/weak_up/flat/weak_down/strong_down
+	Source          string       `json:"source"`           // 数据源
+	GeneratedAt     string       `json:"generated_at"`     // 生成时间
 }
 
 // VolumeComparisonResponse 交易量对比响应结构
 type VolumeComparisonResponse struct {
-	Symbols     []string                 `json:"symbols"`      // 对比的加密货币符号
-	Period      string                   `json:"period"`       // 分析周期
-	Comparison  []VolumeAnalysisResponse `json:"comparison"`   // 对比数据
-	GeneratedAt string                   `json:"generated_at"` // 生成时间
+	Symbols     []string                 `json:"symbols"`          // 对比的加密货币符号
+	Period      string                   `json:"period"`           // 分析周期
+	Comparison  []VolumeAnalysisResponse `json:"comparison"`       // 对比数据
+	Errors      []SymbolError            `json:"errors,omitempty"` // 获取失败的币种
+	GeneratedAt string                   `json:"generated_at"`     // 生成时间
+}
+
+// SymbolError 单个币种的错误信息
+type SymbolError struct {
+	Symbol string `json:"symbol"` // 加密货币符号
+	Error  string `json:"error"`  // 错误信息
 }
 
 // TopVolumeCoinsResponse 交易量排行响应结构
@@ -64,10 +137,10 @@ type HealthResponse struct {
 
 // APIResponse 通用API响应结构
 type APIResponse struct {
-	Success bool        `json:"success"`   // 是否成功
-	Data    interface{} `json:"data"`      // 数据
+	Success bool           `json:"success"`         // 是否成功
+	Data    interface{}    `json:"data"`            // 数据
 	Error   *ErrorResponse `json:"error,omitempty"` // 错误信息
-	Meta    *Meta       `json:"meta,omitempty"`  // 元数据
+	Meta    *Meta          `json:"meta,omitempty"`  // 元数据
 }
 
 // Meta 元数据结构
@@ -90,4 +163,5 @@ type CacheInfo struct {
 	Cached    bool      `json:"cached"`     // 是否来自缓存
 	CachedAt  time.Time `json:"cached_at"`  // 缓存时间
 	ExpiresAt time.Time `json:"expires_at"` // 过期时间
-}
\ No newline at end of file
+	Stale     bool      `json:"stale"`      // 是否已过期，正在后台刷新
+}
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// EventFilter 用户自定义的链上事件过滤器，匹配合约发出的指定事件，topic条件为空的位置匹配任意值
+type EventFilter struct {
+	ID            string     `json:"id"`
+	UserID        string     `json:"user_id"`
+	Name          string     `json:"name,omitempty"`
+	Contract      string     `json:"contract"`                 // 合约地址
+	Event         string     `json:"event"`                    // 事件签名，如 Transfer(address,address,uint256)
+	Topic0        string     `json:"topic0"`                   // 事件签名的keccak256哈希
+	Topics        [][]string `json:"topics,omitempty"`         // 依次为topic1~topic3可接受的值，满足其中之一即可
+	WebhookURL    string     `json:"webhook_url,omitempty"`    // 匹配的事件以JSON POST到该地址
+	WebhookSecret string     `json:"webhook_secret,omitempty"` // 设置后请求带X-Signature头，响应中不返回
+	WebhookSigned bool       `json:"webhook_signed,omitempty"` // 是否设置了webhook签名密钥
+	Stream        bool       `json:"stream"`                   // 是否推送到该用户的WebSocket/SSE连接
+	Enabled       bool       `json:"enabled"`
+	MatchCount    int64      `json:"match_count"` // 累计匹配次数
+	LastMatchedAt *time.Time `json:"last_matched_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// EventFilterRequest 创建或更新事件过滤器的请求
+type EventFilterRequest struct {
+	Name          string     `json:"name"`
+	Contract      string     `json:"contract" binding:"required"`
+	Event         string     `json:"event" binding:"required"` // 事件签名或其32字节哈希
+	Topics        [][]string `json:"topics"`                   // 最多3个位置，值为地址或32字节十六进制
+	WebhookURL    string     `json:"webhook_url"`
+	WebhookSecret string     `json:"webhook_secret"`
+	Stream        *bool      `json:"stream"`  // 为空时推送
+	Enabled       *bool      `json:"enabled"` // 为空时启用
+}
+
+// EventFilterListResponse 事件过滤器列表响应
+type EventFilterListResponse struct {
+	Filters []EventFilter `json:"filters"`
+	Total   int           `json:"total"`
+}
+
+// EventMatch 过滤器匹配到的一条日志
+type EventMatch struct {
+	ID          string    `json:"id"` // 交易哈希:日志序号
+	FilterID    string    `json:"filter_id"`
+	FilterName  string    `json:"filter_name,omitempty"`
+	Contract    string    `json:"contract"`
+	Event       string    `json:"event"`
+	BlockNumber uint64    `json:"block_number"`
+	BlockHash   string    `json:"block_hash"`
+	TxHash      string    `json:"tx_hash"`
+	LogIndex    uint      `json:"log_index"`
+	Topics      []string  `json:"topics"` // 原始topic，第一个为事件签名哈希
+	Data        string    `json:"data"`   // 非indexed参数的ABI编码(十六进制)
+	MatchedAt   time.Time `json:"matched_at"`
+}
+
+// EventMatchListResponse 匹配记录列表响应
+type EventMatchListResponse struct {
+	FilterID string       `json:"filter_id"`
+	Matches  []EventMatch `json:"matches"` // 按时间倒序
+	Total    int          `json:"total"`   // 保留的记录总数
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// FarmPool 流动性挖矿池
+type FarmPool struct {
+	Chef          string   `json:"chef"`             // 挖矿合约名称
+	PID           int      `json:"pid"`              // 池ID
+	Name          string   `json:"name"`             // 池名称，如CAKE-WBNB
+	StakeToken    string   `json:"stake_token"`      // 质押代币(LP)地址
+	Token0        string   `json:"token0,omitempty"` // LP中的代币0
+	Token1        string   `json:"token1,omitempty"` // LP中的代币1
+	AllocPoint    uint64   `json:"alloc_point"`      // 分配权重
+	Weight        float64  `json:"weight"`           // 占总奖励的比例
+	RewardPerYear float64  `json:"reward_per_year"`  // 每年奖励代币数量
+	RewardPrice   float64  `json:"reward_price"`     // 奖励代币价格(美元)
+	TVL           float64  `json:"tvl_usd"`          // 锁仓价值(美元)，无法定价时为0
+	APR           *float64 `json:"apr,omitempty"`    // 年化收益率(%)，无法定价时为空
+	Priced        bool     `json:"priced"`           // 是否成功定价
+}
+
+// FarmListResponse 挖矿池列表响应
+type FarmListResponse struct {
+	Farms     []FarmPool `json:"farms"`      // 挖矿池
+	Total     int        `json:"total"`      // 池总数
+	SortBy    string     `json:"sort_by"`    // 排序字段
+	Order     string     `json:"order"`      // 排序方向
+	UpdatedAt time.Time  `json:"updated_at"` // 计算时间，多个合约时取最早的
+}
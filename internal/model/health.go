@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// 依赖健康状态
+const (
+	HealthStatusOK       = "ok"       // 正常
+	HealthStatusDegraded = "degraded" // 可用但延迟超出预算或部分依赖不可用
+	HealthStatusDown     = "down"     // 不可用
+)
+
+// DependencyHealth 单个依赖的探测结果
+type DependencyHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	BudgetMs  int64  `json:"budget_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReadinessResponse 就绪探针响应
+type ReadinessResponse struct {
+	Status       string             `json:"status"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+	CheckedAt    time.Time          `json:"checked_at"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// PricePoint 价格历史数据点
+type PricePoint struct {
+	Timestamp time.Time `json:"timestamp"` // 区间开始时间
+	Open      float64   `json:"open"`      // 区间首个价格
+	High      float64   `json:"high"`      // 区间最高价
+	Low       float64   `json:"low"`       // 区间最低价
+	Close     float64   `json:"close"`     // 区间最后价格
+	Samples   int       `json:"samples"`   // 区间内采样数
+}
+
+// PriceHistoryResponse 价格历史响应结构
+type PriceHistoryResponse struct {
+	Symbol    string       `json:"symbol"`    // 加密货币符号
+	Interval  string       `json:"interval"`  // 聚合间隔
+	From      time.Time    `json:"from"`      // 开始时间
+	To        time.Time    `json:"to"`        // 结束时间
+	Points    []PricePoint `json:"points"`    // 数据点
+	Precision int          `json:"precision"` // 价格小数位
+}
+
+// PriceSample 原始价格采样
+type PriceSample struct {
+	Timestamp time.Time `json:"timestamp"`        // 采样时间
+	Price     float64   `json:"price"`            // 价格
+	Source    string    `json:"source,omitempty"` // 数据源
+}
+
+// PriceAtResponse 指定时间价格响应结构
+type PriceAtResponse struct {
+	Symbol    string       `json:"symbol"`           // 加密货币符号
+	Time      time.Time    `json:"time"`             // 查询时间
+	Price     float64      `json:"price"`            // 解析出的价格
+	Method    string       `json:"method"`           // 实际使用的解析方式
+	Before    *PriceSample `json:"before,omitempty"` // 查询时间之前最近的采样
+	After     *PriceSample `json:"after,omitempty"`  // 查询时间之后最近的采样
+	Precision int          `json:"precision"`        // 价格小数位
+}
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// 定时任务执行状态
+const (
+	JobRunRunning = "running"
+	JobRunSuccess = "success"
+	JobRunFailed  = "failed"
+)
+
+// 定时任务触发方式
+const (
+	JobTriggerSchedule = "schedule" // 按计划触发
+	JobTriggerManual   = "manual"   // 管理接口手动触发
+)
+
+// JobRun 定时任务的一次执行记录
+type JobRun struct {
+	Job        string    `json:"job"`
+	Trigger    string    `json:"trigger"`  // schedule或manual
+	Status     string    `json:"status"`   // running、success或failed
+	Instance   string    `json:"instance"` // 执行任务的实例(主机名)
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// JobInfo 定时任务及其最近一次执行
+type JobInfo struct {
+	Name      string     `json:"name"`
+	Schedule  string     `json:"schedule"`              // cron表达式或@every间隔
+	Jitter    string     `json:"jitter,omitempty"`      // 每次执行前的最大随机延迟
+	Timeout   string     `json:"timeout,omitempty"`     // 单次执行超时
+	Singleton bool       `json:"singleton"`             // 多实例部署时每次计划执行只在一个实例运行
+	Running   bool       `json:"running"`               // 当前实例是否正在执行
+	NextRunAt *time.Time `json:"next_run_at,omitempty"` // 调度器未启动时为空
+	LastRun   *JobRun    `json:"last_run,omitempty"`
+}
+
+// JobListResponse 定时任务列表响应
+type JobListResponse struct {
+	Enabled bool      `json:"enabled"` // 未启用时任务不按计划执行，仍可手动触发
+	Jobs    []JobInfo `json:"jobs"`
+}
+
+// JobHistoryResponse 定时任务执行记录响应，新记录在前
+type JobHistoryResponse struct {
+	Job  string   `json:"job"`
+	Runs []JobRun `json:"runs"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Kline K线数据
+type Kline struct {
+	OpenTime    time.Time `json:"open_time"`    // 开盘时间
+	CloseTime   time.Time `json:"close_time"`   // 收盘时间(不含)
+	Open        float64   `json:"open"`         // 开盘价
+	High        float64   `json:"high"`         // 最高价
+	Low         float64   `json:"low"`          // 最低价
+	Close       float64   `json:"close"`        // 收盘价，未完结的K线为最新价
+	Volume      float64   `json:"volume"`       // 以币种计的成交量
+	QuoteVolume float64   `json:"quote_volume"` // 以计价币种计的成交额
+}
+
+// KlineResponse K线响应结构
+type KlineResponse struct {
+	Symbol    string     `json:"symbol"`          // 加密货币符号
+	Interval  string     `json:"interval"`        // K线周期
+	Currency  string     `json:"currency"`        // 计价币种
+	Source    string     `json:"source"`          // 数据来源
+	Klines    []Kline    `json:"klines"`          // K线，按开盘时间升序
+	Precision int        `json:"precision"`       // 价格小数位
+	Cache     *CacheInfo `json:"cache,omitempty"` // 缓存信息，实时获取时为空
+}
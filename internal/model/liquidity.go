@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// 流动性事件类型
+const (
+	LiquidityEventRemoval = "liquidity_removal" // 撤出流动性(Burn)
+	LiquidityEventUnlock  = "lp_unlock"         // LP从锁仓合约转出
+)
+
+// LiquidityEvent 可疑的流动性事件
+type LiquidityEvent struct {
+	ID          string    `json:"id"`                  // 事件ID(交易哈希:日志序号)
+	Type        string    `json:"type"`                // 事件类型
+	Pair        string    `json:"pair"`                // 交易对地址
+	PairName    string    `json:"pair_name"`           // 交易对名称
+	TxHash      string    `json:"tx_hash"`             // 交易哈希
+	BlockNumber uint64    `json:"block_number"`        // 区块号
+	Account     string    `json:"account"`             // 接收方地址
+	Locker      string    `json:"locker,omitempty"`    // 锁仓合约地址，仅LP解锁
+	Amount0     float64   `json:"amount0,omitempty"`   // 撤出的代币0数量
+	Amount1     float64   `json:"amount1,omitempty"`   // 撤出的代币1数量
+	LPAmount    float64   `json:"lp_amount,omitempty"` // LP数量，仅LP解锁
+	SharePct    float64   `json:"share_pct"`           // 占交易对流动性的百分比
+	ValueUSD    float64   `json:"value_usd,omitempty"` // 美元价值，无法定价时为空
+	DetectedAt  time.Time `json:"detected_at"`         // 发现时间
+}
+
+// LiquidityEventListResponse 流动性事件列表响应
+type LiquidityEventListResponse struct {
+	Events []LiquidityEvent `json:"events"`
+	Total  int              `json:"total"`
+}
+
+// 监控交易对的来源
+const (
+	MonitoredPairSourceConfig    = "config"    // 配置文件中的交易对
+	MonitoredPairSourceDiscovery = "discovery" // 通过Factory自动发现
+)
+
+// DiscoveredPair 自动发现的交易对
+type DiscoveredPair struct {
+	Name         string    `json:"name"`          // 交易对名称，如CAKE-WBNB
+	Address      string    `json:"address"`       // 交易对地址
+	Token        string    `json:"token"`         // 关注的代币地址
+	QuoteToken   string    `json:"quote_token"`   // 组成交易对的另一种代币地址
+	Reserve      float64   `json:"reserve"`       // 关注代币在池中的储备量
+	DiscoveredAt time.Time `json:"discovered_at"` // 发现时间
+}
+
+// MonitoredPair 流动性监控中的交易对
+type MonitoredPair struct {
+	Name      string          `json:"name"`
+	Address   string          `json:"address"`
+	Source    string          `json:"source"`              // config或discovery
+	Discovery *DiscoveredPair `json:"discovery,omitempty"` // 自动发现的详情
+}
+
+// MonitoredPairListResponse 监控交易对列表响应
+type MonitoredPairListResponse struct {
+	Pairs []MonitoredPair `json:"pairs"`
+	Total int             `json:"total"`
+}
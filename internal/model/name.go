@@ -0,0 +1,14 @@
+package model
+
+// 域名服务
+const (
+	NameServiceSpaceID = "spaceid"
+	NameServiceENS     = "ens"
+)
+
+// NameRecord 域名与地址的对应关系
+type NameRecord struct {
+	Name    string `json:"name"`    // 域名，如alice.bnb
+	Address string `json:"address"` // 地址
+	Service string `json:"service"` // 域名服务
+}
@@ -0,0 +1,131 @@
+package model
+
+import "time"
+
+// 交易方向
+const (
+	TradeSideBuy  = "buy"
+	TradeSideSell = "sell"
+)
+
+// 成本核算方式
+const (
+	CostBasisFIFO = "fifo"
+	CostBasisLIFO = "lifo"
+)
+
+// Trade 交易记录
+type Trade struct {
+	ID        string    `json:"id,omitempty"`                           // 交易ID
+	Symbol    string    `json:"symbol" binding:"required"`              // 加密货币符号
+	Side      string    `json:"side" binding:"required,oneof=buy sell"` // 交易方向
+	Quantity  float64   `json:"quantity" binding:"required,gt=0"`       // 数量
+	Price     float64   `json:"price,omitempty" binding:"gte=0"`        // 成交单价，为空时使用历史价格
+	Fee       float64   `json:"fee,omitempty" binding:"gte=0"`          // 手续费，以计价货币计
+	Timestamp time.Time `json:"timestamp" binding:"required"`           // 成交时间
+	Exchange  string    `json:"exchange,omitempty"`                     // 交易所
+}
+
+// PnLRequest 盈亏计算请求，未提供交易记录时使用已导入的交易
+type PnLRequest struct {
+	Method string  `json:"method" binding:"omitempty,oneof=fifo lifo"` // 成本核算方式
+	Trades []Trade `json:"trades" binding:"dive"`                      // 交易记录
+}
+
+// ImportRowError 导入行级错误
+type ImportRowError struct {
+	Row     int    `json:"row"`     // 行号
+	Message string `json:"message"` // 错误信息
+}
+
+// ImportReport 交易导入报告
+type ImportReport struct {
+	Format     string           `json:"format"`     // 文件格式
+	Rows       int              `json:"rows"`       // 数据行数
+	Imported   int              `json:"imported"`   // 新导入数量
+	Duplicates int              `json:"duplicates"` // 重复跳过数量
+	Errors     []ImportRowError `json:"errors"`     // 解析失败的行
+	Warnings   []ImportRowError `json:"warnings"`   // 警告信息
+}
+
+// Disposal 卖出批次明细
+type Disposal struct {
+	TradeID    string    `json:"trade_id,omitempty"` // 卖出交易ID
+	Symbol     string    `json:"symbol"`             // 加密货币符号
+	Quantity   float64   `json:"quantity"`           // 数量
+	AcquiredAt time.Time `json:"acquired_at"`        // 买入时间
+	DisposedAt time.Time `json:"disposed_at"`        // 卖出时间
+	CostBasis  float64   `json:"cost_basis"`         // 成本
+	Proceeds   float64   `json:"proceeds"`           // 卖出所得
+	Gain       float64   `json:"gain"`               // 已实现盈亏
+}
+
+// SymbolPnL 单个币种盈亏
+type SymbolPnL struct {
+	Symbol        string     `json:"symbol"`         // 加密货币符号
+	Quantity      float64    `json:"quantity"`       // 持仓数量
+	CostBasis     float64    `json:"cost_basis"`     // 持仓成本
+	AverageCost   float64    `json:"average_cost"`   // 持仓均价
+	MarketPrice   float64    `json:"market_price"`   // 当前价格
+	MarketValue   float64    `json:"market_value"`   // 持仓市值
+	RealizedPnL   float64    `json:"realized_pnl"`   // 已实现盈亏
+	UnrealizedPnL float64    `json:"unrealized_pnl"` // 未实现盈亏
+	Disposals     []Disposal `json:"disposals"`      // 卖出明细
+}
+
+// PnLResponse 盈亏计算响应
+type PnLResponse struct {
+	Method          string      `json:"method"`           // 成本核算方式
+	Symbols         []SymbolPnL `json:"symbols"`          // 各币种盈亏
+	TotalCostBasis  float64     `json:"total_cost_basis"` // 总持仓成本
+	TotalRealized   float64     `json:"total_realized"`   // 总已实现盈亏
+	TotalUnrealized float64     `json:"total_unrealized"` // 总未实现盈亏
+}
+
+// 会计导出记录类型
+const (
+	AccountingAcquisition = "acquisition"
+	AccountingDisposal    = "disposal"
+)
+
+// AccountingEntry 会计导出明细
+type AccountingEntry struct {
+	Date            time.Time  `json:"date"`                  // 发生时间
+	Type            string     `json:"type"`                  // 记录类型
+	Symbol          string     `json:"symbol"`                // 加密货币符号
+	Quantity        float64    `json:"quantity"`              // 数量
+	UnitPrice       float64    `json:"unit_price"`            // 成交单价
+	FairValue       float64    `json:"fair_value"`            // 发生时的公允价格
+	FairValueSource string     `json:"fair_value_source"`     // 公允价格来源(history/trade)
+	CostBasis       float64    `json:"cost_basis"`            // 成本
+	Proceeds        float64    `json:"proceeds"`              // 卖出所得
+	Gain            float64    `json:"gain"`                  // 已实现盈亏
+	AcquiredAt      *time.Time `json:"acquired_at,omitempty"` // 买入时间
+	HoldingDays     int        `json:"holding_days"`          // 持有天数
+	Term            string     `json:"term,omitempty"`        // 持有期限(short/long)
+	TradeID         string     `json:"trade_id,omitempty"`    // 交易ID
+	Exchange        string     `json:"exchange,omitempty"`    // 交易所
+}
+
+// AccountingPeriodSummary 会计期间汇总
+type AccountingPeriodSummary struct {
+	Period       string  `json:"period"`       // 期间
+	Acquisitions float64 `json:"acquisitions"` // 买入总成本
+	Proceeds     float64 `json:"proceeds"`     // 卖出总所得
+	CostBasis    float64 `json:"cost_basis"`   // 卖出部分成本
+	Gain         float64 `json:"gain"`         // 已实现盈亏
+	ShortTerm    float64 `json:"short_term"`   // 短期盈亏
+	LongTerm     float64 `json:"long_term"`    // 长期盈亏
+	Disposals    int     `json:"disposals"`    // 卖出笔数
+	Acquired     int     `json:"acquired"`     // 买入笔数
+}
+
+// AccountingReport 会计导出报告
+type AccountingReport struct {
+	Method  string                    `json:"method"`  // 成本核算方式
+	Period  string                    `json:"period"`  // 汇总周期
+	From    time.Time                 `json:"from"`    // 开始时间
+	To      time.Time                 `json:"to"`      // 结束时间
+	Entries []AccountingEntry         `json:"entries"` // 明细
+	Periods []AccountingPeriodSummary `json:"periods"` // 期间汇总
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// decimalNumber 按小数位格式化价格，decimals为0时使用最短表示，均不使用科学计数法
+func decimalNumber(value float64, decimals int) json.Number {
+	if decimals <= 0 {
+		decimals = -1
+	}
+	return json.Number(strconv.FormatFloat(value, 'f', decimals, 64))
+}
+
+// MarshalJSON 按Precision输出价格
+func (p PriceResponse) MarshalJSON() ([]byte, error) {
+	type alias PriceResponse
+	return json.Marshal(struct {
+		alias
+		Price json.Number `json:"price"`
+	}{alias(p), decimalNumber(p.Price, p.Precision)})
+}
+
+// pricePointJSON 按精度输出的价格历史数据点
+type pricePointJSON struct {
+	PricePoint
+	Open  json.Number `json:"open"`
+	High  json.Number `json:"high"`
+	Low   json.Number `json:"low"`
+	Close json.Number `json:"close"`
+}
+
+// MarshalJSON 按Precision输出数据点价格
+func (r PriceHistoryResponse) MarshalJSON() ([]byte, error) {
+	type alias PriceHistoryResponse
+	points := make([]pricePointJSON, len(r.Points))
+	for i, p := range r.Points {
+		points[i] = pricePointJSON{
+			PricePoint: p,
+			Open:       decimalNumber(p.Open, r.Precision),
+			High:       decimalNumber(p.High, r.Precision),
+			Low:        decimalNumber(p.Low, r.Precision),
+			Close:      decimalNumber(p.Close, r.Precision),
+		}
+	}
+	return json.Marshal(struct {
+		alias
+		Points []pricePointJSON `json:"points"`
+	}{alias(r), points})
+}
+
+// priceSampleJSON 按精度输出的价格采样
+type priceSampleJSON struct {
+	PriceSample
+	Price json.Number `json:"price"`
+}
+
+// MarshalJSON 按Precision输出解析出的价格和前后采样
+func (r PriceAtResponse) MarshalJSON() ([]byte, error) {
+	type alias PriceAtResponse
+	sample := func(s *PriceSample) *priceSampleJSON {
+		if s == nil {
+			return nil
+		}
+		return &priceSampleJSON{PriceSample: *s, Price: decimalNumber(s.Price, r.Precision)}
+	}
+	return json.Marshal(struct {
+		alias
+		Price  json.Number      `json:"price"`
+		Before *priceSampleJSON `json:"before,omitempty"`
+		After  *priceSampleJSON `json:"after,omitempty"`
+	}{alias(r), decimalNumber(r.Price, r.Precision), sample(r.Before), sample(r.After)})
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// SLAStatusResponse 请求成功率和依赖可用性统计
+type SLAStatusResponse struct {
+	Objective     float64     `json:"objective"`      // 请求成功率目标(%)
+	Windows       []SLAWindow `json:"windows"`        // 1h、24h、30d滚动窗口
+	StartedAt     time.Time   `json:"started_at"`     // 当前实例启动时间
+	UptimeSeconds int64       `json:"uptime_seconds"` // 当前实例运行时长
+	GeneratedAt   time.Time   `json:"generated_at"`
+}
+
+// SLAWindow 单个滚动窗口的统计，1h按分钟聚合，24h和30d按小时聚合
+type SLAWindow struct {
+	Window         string          `json:"window"`
+	Requests       int64           `json:"requests"`        // API请求数，所有实例合计
+	Errors         int64           `json:"errors"`          // 返回5xx的请求数
+	SuccessRate    float64         `json:"success_rate"`    // 请求成功率(%)，无请求时为100
+	Availability   float64         `json:"availability"`    // 依赖探测中服务未处于down状态的比例(%)，无采样时为100
+	MeetsObjective bool            `json:"meets_objective"` // 成功率是否达到目标
+	Dependencies   []SLADependency `json:"dependencies"`
+}
+
+// SLADependency 单个依赖在窗口内的可用性
+type SLADependency struct {
+	Name         string  `json:"name"`
+	Samples      int64   `json:"samples"`      // 采样次数
+	Availability float64 `json:"availability"` // 未处于down状态的比例(%)
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// 快照任务状态
+const (
+	SnapshotStatusPending   = "pending"
+	SnapshotStatusRunning   = "running"
+	SnapshotStatusCompleted = "completed"
+	SnapshotStatusFailed    = "failed"
+)
+
+// SnapshotRequest 持币快照请求
+type SnapshotRequest struct {
+	Token      string `json:"token" binding:"required"` // 代币合约地址
+	Block      uint64 `json:"block"`                    // 快照区块高度，0表示最新区块
+	StartBlock uint64 `json:"start_block"`              // 开始回放的区块，通常为代币部署区块
+}
+
+// SnapshotJob 持币快照任务
+type SnapshotJob struct {
+	ID          string     `json:"id"`                     // 任务ID
+	Token       string     `json:"token"`                  // 代币合约地址
+	Symbol      string     `json:"symbol"`                 // 代币符号
+	Decimals    int        `json:"decimals"`               // 代币精度
+	Block       uint64     `json:"block"`                  // 快照区块高度
+	StartBlock  uint64     `json:"start_block"`            // 开始回放的区块
+	Status      string     `json:"status"`                 // 任务状态
+	Progress    float64    `json:"progress"`               // 回放进度(%)
+	Transfers   int        `json:"transfers"`              // 已回放的转账数
+	Holders     int        `json:"holders"`                // 持币地址数，完成后有效
+	TotalSupply string     `json:"total_supply,omitempty"` // 快照时持币总量
+	Error       string     `json:"error,omitempty"`        // 失败原因
+	DownloadURL string     `json:"download_url,omitempty"` // 导出文件下载地址，完成后有效
+	CreatedAt   time.Time  `json:"created_at"`             // 创建时间
+	CompletedAt *time.Time `json:"completed_at,omitempty"` // 完成时间
+}
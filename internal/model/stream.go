@@ -0,0 +1,107 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 推送事件类型
+const (
+	StreamEventPrice = "price" // 价格更新
+	StreamEventAlert = "alert" // 告警
+	StreamEventChain = "event" // 用户事件过滤器匹配的链上事件，只推送给该用户
+)
+
+// 增量推送的帧类型，未启用增量推送时事件不带mode
+const (
+	StreamModeSnapshot = "snapshot" // 完整数据
+	StreamModeDelta    = "delta"    // 只包含相对上一帧变化的字段，删除的字段为null
+)
+
+// StreamEvent 推送给WebSocket客户端的事件，经Redis pub/sub分发到所有实例
+type StreamEvent struct {
+	Type      string          `json:"type"`
+	Symbol    string          `json:"symbol,omitempty"`
+	Data      json.RawMessage `json:"data"`
+	Mode      string          `json:"mode,omitempty"`    // 增量推送时为snapshot或delta
+	Replay    bool            `json:"replay,omitempty"`  // 连接时补发的历史事件
+	UserID    string          `json:"user_id,omitempty"` // 设置后只推送给该用户的连接
+	Node      string          `json:"node"`              // 产生事件的实例
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// StreamFilter 连接的事件过滤条件，为空表示不过滤
+type StreamFilter struct {
+	Types   []string `json:"types,omitempty"`
+	Symbols []string `json:"symbols,omitempty"`
+	UserID  string   `json:"user_id,omitempty"` // 连接的用户，用于接收指定用户的事件
+}
+
+// Match 事件是否满足过滤条件，没有币种的事件(如部分告警)不受币种过滤限制，指定用户的事件只匹配该用户的连接
+func (f StreamFilter) Match(event *StreamEvent) bool {
+	if event.UserID != "" && event.UserID != f.UserID {
+		return false
+	}
+	if len(f.Types) > 0 && !containsString(f.Types, event.Type) {
+		return false
+	}
+	if len(f.Symbols) > 0 && event.Symbol != "" && !containsString(f.Symbols, event.Symbol) {
+		return false
+	}
+	return true
+}
+
+// containsString 切片中是否包含指定字符串
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// StreamOptions 连接的推送方式
+type StreamOptions struct {
+	Delta   bool    `json:"delta"`    // 价格事件只推送变化的字段，并定期推送完整数据
+	MaxRate float64 `json:"max_rate"` // 每个币种每秒最多推送的价格更新数，超出时合并为最新一条，0表示不限制
+}
+
+// StreamNodeStats 单个实例的推送连接统计
+type StreamNodeStats struct {
+	Node        string    `json:"node"`
+	Connections int       `json:"connections"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// StreamStatsResponse 推送连接统计响应
+type StreamStatsResponse struct {
+	Node             string            `json:"node"` // 处理本次请求的实例
+	TotalConnections int               `json:"total_connections"`
+	Nodes            []StreamNodeStats `json:"nodes"`
+}
+
+// StreamSubscription 命名推送订阅，WebSocket和SSE连接通过ID复用同一组过滤条件，断线重连后无需重新指定
+type StreamSubscription struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name,omitempty"`
+	Types   []string `json:"types,omitempty"`
+	Symbols []string `json:"symbols,omitempty"`
+	StreamOptions
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ExpiresAt time.Time `json:"expires_at"` // 连接或更新时顺延
+}
+
+// Filter 订阅对应的事件过滤条件
+func (s *StreamSubscription) Filter() StreamFilter {
+	return StreamFilter{Types: s.Types, Symbols: s.Symbols}
+}
+
+// StreamSubscriptionRequest 创建或更新推送订阅请求
+type StreamSubscriptionRequest struct {
+	Name    string   `json:"name"`
+	Types   []string `json:"types"`   // price、alert、event，为空时推送全部类型
+	Symbols []string `json:"symbols"` // 为空时推送全部币种
+	StreamOptions
+}
@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+// TokenMetadata 代币元数据
+type TokenMetadata struct {
+	Address   string    `json:"address"`            // 合约地址(校验和格式)
+	ChainID   int64     `json:"chain_id"`           // 链ID
+	Symbol    string    `json:"symbol"`             // 代币符号
+	Name      string    `json:"name"`               // 代币名称
+	Decimals  uint8     `json:"decimals"`           // 精度
+	LogoURI   string    `json:"logo_uri,omitempty"` // 图标地址
+	Tags      []string  `json:"tags,omitempty"`     // 标签
+	OnChain   bool      `json:"on_chain"`           // 是否已在链上确认合约存在
+	Sources   []string  `json:"sources"`            // 数据来源
+	UpdatedAt time.Time `json:"updated_at"`         // 更新时间
+}
+
+// AddressLabel 地址标签
+type AddressLabel struct {
+	Address   string    `json:"address"`            // 地址(校验和格式)
+	Label     string    `json:"label"`              // 标签名称
+	Category  string    `json:"category,omitempty"` // 分类，如exchange、bridge、scam
+	Source    string    `json:"source,omitempty"`   // 数据来源
+	UpdatedAt time.Time `json:"updated_at"`         // 更新时间
+}
+
+// TokenListResponse 代币列表响应
+type TokenListResponse struct {
+	Tokens []TokenMetadata `json:"tokens"` // 代币列表
+	Total  int             `json:"total"`  // 总数
+}
+
+// IngestRecord 数据文件导入记录
+type IngestRecord struct {
+	File       string    `json:"file"`             // 文件路径
+	Kind       string    `json:"kind"`             // 数据类型(tokens/labels/trades)
+	Status     string    `json:"status"`           // 导入状态(success/partial/failed)
+	Records    int       `json:"records"`          // 记录数
+	Loaded     int       `json:"loaded"`           // 成功加载数
+	Errors     []string  `json:"errors,omitempty"` // 错误信息
+	StartedAt  time.Time `json:"started_at"`       // 开始时间
+	DurationMs int64     `json:"duration_ms"`      // 耗时(毫秒)
+}
+
+// TokenSyncStatus 代币列表同步状态
+type TokenSyncStatus struct {
+	Source     string    `json:"source"`          // 来源名称
+	URL        string    `json:"url"`             // 列表地址
+	LastSyncAt time.Time `json:"last_sync_at"`    // 最近同步时间
+	Tokens     int       `json:"tokens"`          // 列表中本链代币数
+	Saved      int       `json:"saved"`           // 写入注册表数量
+	Rejected   int       `json:"rejected"`        // 校验失败数量
+	Error      string    `json:"error,omitempty"` // 同步错误
+	DurationMs int64     `json:"duration_ms"`     // 耗时(毫秒)
+}
+
+// ContractVerification 合约源码验证信息
+type ContractVerification struct {
+	Status           string    `json:"status"`                     // 验证状态(verified/unverified/unknown)
+	ContractName     string    `json:"contract_name,omitempty"`    // 合约名称
+	CompilerVersion  string    `json:"compiler_version,omitempty"` // 编译器版本
+	LicenseType      string    `json:"license_type,omitempty"`     // 开源协议
+	OptimizationUsed bool      `json:"optimization_used"`          // 是否开启优化
+	Runs             int       `json:"runs,omitempty"`             // 优化次数
+	Proxy            bool      `json:"proxy"`                      // 是否为代理合约
+	Implementation   string    `json:"implementation,omitempty"`   // 代理合约的实现地址
+	Source           string    `json:"source"`                     // 数据来源
+	CheckedAt        time.Time `json:"checked_at"`                 // 检查时间
+	Error            string    `json:"error,omitempty"`            // 查询失败原因
+}
+
+// TokenSafetyReport 代币安全检查报告
+type TokenSafetyReport struct {
+	Address      string                `json:"address"`               // 合约地址
+	IsContract   *bool                 `json:"is_contract,omitempty"` // 地址上是否部署了合约，未知时为空
+	Token        *TokenMetadata        `json:"token,omitempty"`       // 注册表中的代币信息
+	Label        *AddressLabel         `json:"label,omitempty"`       // 地址标签
+	Verification *ContractVerification `json:"verification"`          // 合约源码验证信息
+	Risks        []string              `json:"risks"`                 // 风险提示
+	CheckedAt    time.Time             `json:"checked_at"`            // 检查时间
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// PairTVL 交易对锁仓价值
+type PairTVL struct {
+	Name      string    `json:"name"`       // 交易对名称
+	Address   string    `json:"address"`    // 交易对地址
+	Token0    string    `json:"token0"`     // 代币0地址
+	Token1    string    `json:"token1"`     // 代币1地址
+	Symbol0   string    `json:"symbol0"`    // 代币0符号
+	Symbol1   string    `json:"symbol1"`    // 代币1符号
+	Reserve0  float64   `json:"reserve0"`   // 代币0储备量
+	Reserve1  float64   `json:"reserve1"`   // 代币1储备量
+	Price0    float64   `json:"price0"`     // 代币0价格(美元)
+	Price1    float64   `json:"price1"`     // 代币1价格(美元)
+	TVL       float64   `json:"tvl_usd"`    // 锁仓价值(美元)
+	UpdatedAt time.Time `json:"updated_at"` // 计算时间
+}
+
+// TokenTVL 代币在跟踪交易对中的锁仓价值
+type TokenTVL struct {
+	Token  string  `json:"token"`   // 代币地址
+	Symbol string  `json:"symbol"`  // 代币符号
+	Amount float64 `json:"amount"`  // 锁仓数量
+	TVL    float64 `json:"tvl_usd"` // 锁仓价值(美元)
+	Pairs  int     `json:"pairs"`   // 所在交易对数量
+}
+
+// TVLOverview 锁仓价值概览
+type TVLOverview struct {
+	Pairs     []PairTVL  `json:"pairs"`
+	Tokens    []TokenTVL `json:"tokens"`
+	TotalTVL  float64    `json:"total_tvl_usd"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TVLPoint 锁仓价值时间序列点
+type TVLPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	TVL       float64   `json:"tvl_usd"`
+}
+
+// TVLHistoryResponse 锁仓价值历史响应
+type TVLHistoryResponse struct {
+	Kind      string     `json:"kind"`       // pair或token
+	Address   string     `json:"address"`    // 交易对或代币地址
+	From      time.Time  `json:"from"`       // 开始时间
+	To        time.Time  `json:"to"`         // 结束时间
+	Points    []TVLPoint `json:"points"`     // 按时间升序
+	ChangePct float64    `json:"change_pct"` // 区间首尾变化百分比
+}
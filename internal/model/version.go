@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// VersionResponse 实例的构建信息和已启用的功能
+type VersionResponse struct {
+	Version   string          `json:"version"`
+	BuildTime string          `json:"build_time"`
+	GitCommit string          `json:"git_commit"`
+	GoVersion string          `json:"go_version"`
+	Env       string          `json:"env"`
+	StartedAt time.Time       `json:"started_at"`
+	Features  map[string]bool `json:"features"`  // 功能开关，false表示该实例未启用
+	Providers []string        `json:"providers"` // 已配置的行情和链上数据提供方
+}
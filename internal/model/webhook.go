@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 投递记录状态筛选
+const (
+	WebhookDeliveryFailed    = "failed"
+	WebhookDeliverySucceeded = "succeeded"
+)
+
+// WebhookDelivery 一次webhook投递尝试的记录
+type WebhookDelivery struct {
+	ID         string          `json:"id"`
+	Webhook    string          `json:"webhook"`           // 配置中的webhook名称
+	UserID     string          `json:"user_id,omitempty"` // 投递到用户注册的webhook时为所属用户
+	URL        string          `json:"url"`               // 投递地址，不含查询参数以免泄露token
+	AlertID    string          `json:"alert_id"`          // 投递的告警
+	AlertType  string          `json:"alert_type"`
+	Success    bool            `json:"success"`
+	StatusCode int             `json:"status_code,omitempty"` // 接收方返回的状态码，请求未完成时为空
+	LatencyMs  int64           `json:"latency_ms"`
+	Response   string          `json:"response,omitempty"` // 响应体开头部分，便于排查接收方问题
+	Error      string          `json:"error,omitempty"`
+	Attempts   int             `json:"attempts,omitempty"`    // 用户webhook的投递次数，含重试
+	RedriveOf  string          `json:"redrive_of,omitempty"`  // 重新投递时为原投递记录ID
+	RedrivenBy string          `json:"redriven_by,omitempty"` // 已被重新投递时为新投递记录ID
+	Payload    json.RawMessage `json:"payload,omitempty"`     // 投递的请求体，重新投递时原样发送
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// WebhookDeliveryListResponse 投递记录列表响应
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"` // 按时间倒序，不含请求体
+	Total      int               `json:"total"`      // 满足条件的记录总数
+}
+
+// UserWebhook 用户注册的webhook，用户的价格告警消息签名后POST到该地址
+type UserWebhook struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name,omitempty"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"` // 签名密钥，只在创建时返回
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserWebhookRequest 注册webhook的请求
+type UserWebhookRequest struct {
+	Name   string `json:"name"`
+	URL    string `json:"url" binding:"required"`
+	Secret string `json:"secret"` // 为空时自动生成
+}
+
+// UserWebhookListResponse 用户webhook列表响应，不含签名密钥
+type UserWebhookListResponse struct {
+	Webhooks []UserWebhook `json:"webhooks"`
+	Total    int           `json:"total"`
+}
@@ -0,0 +1,235 @@
+// Package apikey 上游API Key池，同一提供方配置多个Key时轮换使用并按Key限流
+//
+// 每个Key独立统计固定窗口内的请求数，接近限额时暂停该Key，上游返回限流时按冷却时间暂停；
+// 所有Key都不可用时 Acquire 等待最早恢复的Key。同一提供方的池在进程内共享，见 Shared。
+package apikey
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// nearLimitRatio 单个Key在窗口内使用到限额的该比例即暂停，给其他进程或时钟误差留出余量
+const nearLimitRatio = 0.8
+
+// ErrEmptyPool 池中没有可用的Key
+var ErrEmptyPool = errors.New("api key pool is empty")
+
+// Options Key池配置
+type Options struct {
+	Keys      []string // 为空时池中只有一个空Key，仍按RateLimit限流
+	RateLimit float64  // 单个Key每秒请求数上限
+}
+
+// KeyStats 单个Key的使用统计，Key已脱敏
+type KeyStats struct {
+	Key         string    `json:"key"`
+	Requests    int64     `json:"requests"`     // 累计请求数
+	RateLimited int64     `json:"rate_limited"` // 上游返回限流的次数
+	PausedUntil time.Time `json:"paused_until,omitempty"`
+}
+
+// Pool 单个提供方的Key池
+type Pool struct {
+	name   string
+	window time.Duration // 限流统计窗口
+	budget int           // 窗口内单个Key可用的请求数
+
+	mu   sync.Mutex
+	keys []*keyState
+	next int
+}
+
+// keyState 单个Key的限流状态
+type keyState struct {
+	key         string
+	windowStart time.Time
+	used        int
+	pausedUntil time.Time
+	requests    int64
+	rateLimited int64
+}
+
+// NewPool 创建Key池，rateLimit不大于0时不限流
+func NewPool(name string, opts Options) *Pool {
+	p := &Pool{name: name}
+
+	switch {
+	case opts.RateLimit <= 0:
+		p.window = time.Second
+		p.budget = 0
+	case opts.RateLimit < 1:
+		// 每秒不足一次时把窗口放大到恰好容纳一次请求
+		p.window = time.Duration(float64(time.Second) / opts.RateLimit)
+		p.budget = 1
+	default:
+		p.window = time.Second
+		p.budget = int(opts.RateLimit * nearLimitRatio)
+		if p.budget < 1 {
+			p.budget = 1
+		}
+	}
+
+	keys := opts.Keys
+	if len(keys) == 0 {
+		keys = []string{""}
+	}
+	for _, key := range keys {
+		p.keys = append(p.keys, &keyState{key: key})
+	}
+	return p
+}
+
+// Name 提供方名称
+func (p *Pool) Name() string {
+	return p.name
+}
+
+// Len Key数量
+func (p *Pool) Len() int {
+	return len(p.keys)
+}
+
+// Acquire 按轮询顺序取一个未暂停且未接近限额的Key，全部不可用时等待
+func (p *Pool) Acquire(ctx context.Context) (string, error) {
+	if len(p.keys) == 0 {
+		return "", ErrEmptyPool
+	}
+
+	for {
+		key, wait := p.tryAcquire(time.Now())
+		if wait <= 0 {
+			return key, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// tryAcquire 取一个可用的Key，没有可用Key时返回需要等待的时间
+func (p *Pool) tryAcquire(now time.Time) (string, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var earliest time.Time
+	for i := 0; i < len(p.keys); i++ {
+		idx := (p.next + i) % len(p.keys)
+		k := p.keys[idx]
+
+		readyAt := k.readyAt(now, p.window, p.budget)
+		if !readyAt.After(now) {
+			k.used++
+			k.requests++
+			p.next = (idx + 1) % len(p.keys)
+			return k.key, 0
+		}
+		if earliest.IsZero() || readyAt.Before(earliest) {
+			earliest = readyAt
+		}
+	}
+	return "", earliest.Sub(now)
+}
+
+// readyAt 该Key下次可用的时间，窗口过期时重置计数
+func (k *keyState) readyAt(now time.Time, window time.Duration, budget int) time.Time {
+	if now.Before(k.pausedUntil) {
+		return k.pausedUntil
+	}
+	if now.Sub(k.windowStart) >= window {
+		k.windowStart = now
+		k.used = 0
+	}
+	if budget > 0 && k.used >= budget {
+		return k.windowStart.Add(window)
+	}
+	return now
+}
+
+// ReportRateLimited 上游返回限流时暂停该Key，期间请求轮换到其他Key
+func (p *Pool) ReportRateLimited(key string, cooldown time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, k := range p.keys {
+		if k.key != key {
+			continue
+		}
+		k.rateLimited++
+		if until := time.Now().Add(cooldown); k.pausedUntil.Before(until) {
+			k.pausedUntil = until
+		}
+		return
+	}
+}
+
+// Stats 各Key的使用统计
+func (p *Pool) Stats() []KeyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	stats := make([]KeyStats, 0, len(p.keys))
+	for _, k := range p.keys {
+		s := KeyStats{
+			Key:         Mask(k.key),
+			Requests:    k.requests,
+			RateLimited: k.rateLimited,
+		}
+		if now.Before(k.pausedUntil) {
+			s.PausedUntil = k.pausedUntil
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// Mask 脱敏Key，只保留末4位用于区分
+func Mask(key string) string {
+	if key == "" {
+		return "none"
+	}
+	if len(key) <= 4 {
+		return "***"
+	}
+	return "***" + key[len(key)-4:]
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Pool)
+)
+
+// Shared 获取进程内共享的Key池，同名提供方只创建一次，多个客户端实例共用限额
+func Shared(name string, opts Options) *Pool {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if p, ok := registry[name]; ok {
+		return p
+	}
+	p := NewPool(name, opts)
+	registry[name] = p
+	return p
+}
+
+// All 所有共享Key池，按名称排序
+func All() []*Pool {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	pools := make([]*Pool, 0, len(registry))
+	for _, p := range registry {
+		pools = append(pools, p)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].name < pools[j].name })
+	return pools
+}
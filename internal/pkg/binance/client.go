@@ -0,0 +1,236 @@
+// Package binance 币安现货REST API客户端
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+)
+
+// defaultBaseURL 币安API默认地址
+const defaultBaseURL = "https://api.binance.com"
+
+// codeInvalidSymbol 交易对不存在的错误码
+const codeInvalidSymbol = -1121
+
+// ErrInvalidSymbol 交易对不存在
+var ErrInvalidSymbol = errors.New("binance: invalid symbol")
+
+// Client 币安API客户端
+type Client struct {
+	baseURL    string
+	retrier    *httpclient.Retrier
+	httpClient *http.Client
+}
+
+// TickerPrice 交易对最新成交价
+type TickerPrice struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+}
+
+// Ticker24h 交易对24小时滚动窗口行情
+type Ticker24h struct {
+	LastPrice   float64 // 最新成交价
+	OpenPrice   float64 // 24小时前的开盘价
+	HighPrice   float64 // 24小时最高价
+	LowPrice    float64 // 24小时最低价
+	Volume      float64 // 24小时成交量
+	QuoteVolume float64 // 24小时成交额
+}
+
+// ticker24h 币安24小时行情响应，数值均为字符串
+type ticker24h struct {
+	Symbol      string `json:"symbol"`
+	LastPrice   string `json:"lastPrice"`
+	OpenPrice   string `json:"openPrice"`
+	HighPrice   string `json:"highPrice"`
+	LowPrice    string `json:"lowPrice"`
+	Volume      string `json:"volume"`
+	QuoteVolume string `json:"quoteVolume"`
+}
+
+// Kline K线
+type Kline struct {
+	OpenTime    time.Time
+	Open        float64
+	High        float64
+	Low         float64
+	Close       float64
+	Volume      float64 // 成交量
+	QuoteVolume float64 // 成交额
+}
+
+// apiError 币安错误响应
+type apiError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// NewClient 创建币安客户端，按配置的地址、超时、代理和重试次数访问API
+func NewClient(cfg *config.APIConfig) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		retrier:    httpclient.NewRetrier(budget.ProviderBinance, cfg.RetryTimes, cfg.RetryInterval, retryable),
+		httpClient: httpclient.New(httpclient.Options{Timeout: cfg.Timeout, Provider: budget.ProviderBinance, Proxy: cfg.Proxy}),
+	}
+}
+
+// GetPrice 获取交易对(如BTCUSDT)的最新成交价
+func (c *Client) GetPrice(ctx context.Context, pair string) (float64, error) {
+	params := url.Values{}
+	params.Set("symbol", strings.ToUpper(pair))
+
+	var ticker TickerPrice
+	if err := c.get(ctx, "/api/v3/ticker/price", params, &ticker); err != nil {
+		return 0, err
+	}
+
+	price, err := strconv.ParseFloat(ticker.Price, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid binance price %q for %s: %w", ticker.Price, pair, err)
+	}
+	return price, nil
+}
+
+// GetTicker24h 获取交易对(如BTCUSDT)的最新成交价和24小时统计
+func (c *Client) GetTicker24h(ctx context.Context, pair string) (*Ticker24h, error) {
+	params := url.Values{}
+	params.Set("symbol", strings.ToUpper(pair))
+
+	var t ticker24h
+	if err := c.get(ctx, "/api/v3/ticker/24hr", params, &t); err != nil {
+		return nil, err
+	}
+
+	fields := []string{t.LastPrice, t.OpenPrice, t.HighPrice, t.LowPrice, t.Volume, t.QuoteVolume}
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid binance 24h ticker for %s: %w", pair, err)
+		}
+		values[i] = v
+	}
+	return &Ticker24h{
+		LastPrice:   values[0],
+		OpenPrice:   values[1],
+		HighPrice:   values[2],
+		LowPrice:    values[3],
+		Volume:      values[4],
+		QuoteVolume: values[5],
+	}, nil
+}
+
+// GetKlines 获取交易对最近limit根K线，interval为币安K线周期(如1d)，按时间升序
+func (c *Client) GetKlines(ctx context.Context, pair, interval string, limit int) ([]Kline, error) {
+	params := url.Values{}
+	params.Set("symbol", strings.ToUpper(pair))
+	params.Set("interval", interval)
+	params.Set("limit", strconv.Itoa(limit))
+
+	var rows [][]interface{}
+	if err := c.get(ctx, "/api/v3/klines", params, &rows); err != nil {
+		return nil, err
+	}
+
+	klines := make([]Kline, 0, len(rows))
+	for _, row := range rows {
+		kline, err := parseKline(row)
+		if err != nil {
+			return nil, fmt.Errorf("invalid binance kline for %s: %w", pair, err)
+		}
+		klines = append(klines, kline)
+	}
+	return klines, nil
+}
+
+// parseKline 解析K线数组：[开盘时间, 开, 高, 低, 收, 成交量, 收盘时间, 成交额, ...]，价格和数量为字符串
+func parseKline(row []interface{}) (Kline, error) {
+	if len(row) < 8 {
+		return Kline{}, fmt.Errorf("expected at least 8 fields, got %d", len(row))
+	}
+	openTime, ok := row[0].(float64)
+	if !ok {
+		return Kline{}, fmt.Errorf("invalid open time %v", row[0])
+	}
+
+	// 开、高、低、收和成交量依次位于第1到5个字段
+	var values [5]float64
+	for i := range values {
+		value, err := parseNumber(row[i+1])
+		if err != nil {
+			return Kline{}, err
+		}
+		values[i] = value
+	}
+	quoteVolume, err := parseNumber(row[7])
+	if err != nil {
+		return Kline{}, err
+	}
+
+	return Kline{
+		OpenTime:    time.UnixMilli(int64(openTime)).UTC(),
+		Open:        values[0],
+		High:        values[1],
+		Low:         values[2],
+		Close:       values[3],
+		Volume:      values[4],
+		QuoteVolume: quoteVolume,
+	}, nil
+}
+
+// parseNumber 解析以字符串表示的数值
+func parseNumber(v interface{}) (float64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("expected numeric string, got %v", v)
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// get 发送GET请求，网络错误、限流和5xx响应按配置的次数指数退避重试
+func (c *Client) get(ctx context.Context, path string, params url.Values, result interface{}) error {
+	endpoint := c.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	return c.retrier.Do(ctx, func() error {
+		return convertError(httpclient.GetJSON(ctx, c.httpClient, endpoint, result))
+	})
+}
+
+// convertError 将交易对不存在的错误响应转换为ErrInvalidSymbol
+func convertError(err error) error {
+	var statusErr *httpclient.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		return err
+	}
+
+	var apiErr apiError
+	if json.Unmarshal([]byte(statusErr.Body), &apiErr) == nil && apiErr.Code == codeInvalidSymbol {
+		return fmt.Errorf("%w: %s", ErrInvalidSymbol, apiErr.Msg)
+	}
+	return err
+}
+
+// retryable 是否值得重试，交易对不存在时不重试，其余按httpclient.Retryable判断
+func retryable(err error) bool {
+	return !errors.Is(err, ErrInvalidSymbol) && httpclient.Retryable(err)
+}
@@ -0,0 +1,163 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/provider"
+)
+
+// providerName 数据源名称
+const providerName = "binance"
+
+// QuoteAsset 价格和成交额使用的计价币种
+const QuoteAsset = "USDT"
+
+// maxKlineLimit 单次请求的最大K线数量
+const maxKlineLimit = 1000
+
+// validSymbol 币种名称只包含字母和数字
+var validSymbol = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// Provider 币安价格和交易量数据源，币种以对USDT的交易对查询
+type Provider struct {
+	client *Client
+}
+
+// NewProvider 创建币安数据源
+func NewProvider(client *Client) *Provider {
+	return &Provider{client: client}
+}
+
+// Name 实现provider.PriceProvider接口
+func (p *Provider) Name() string {
+	return providerName
+}
+
+// GetPrice 实现provider.PriceProvider接口，价格和24小时统计来自同一次24小时行情请求
+func (p *Provider) GetPrice(ctx context.Context, symbol string) (*provider.Quote, error) {
+	pair, symbol, err := p.pair(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker, err := p.client.GetTicker24h(ctx, pair)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	if ticker.LastPrice <= 0 {
+		return nil, fmt.Errorf("%w: invalid binance price %v for %s", provider.ErrUnavailable, ticker.LastPrice, pair)
+	}
+	return &provider.Quote{
+		Symbol:   symbol,
+		Price:    ticker.LastPrice,
+		Currency: QuoteAsset,
+		Time:     time.Now(),
+		Stats: &provider.Stats24h{
+			Open:        ticker.OpenPrice,
+			High:        ticker.HighPrice,
+			Low:         ticker.LowPrice,
+			Volume:      ticker.Volume,
+			QuoteVolume: ticker.QuoteVolume,
+		},
+	}, nil
+}
+
+// GetDailyVolumes 实现provider.VolumeProvider接口
+func (p *Provider) GetDailyVolumes(ctx context.Context, symbol string, days int) ([]provider.DailyVolume, error) {
+	pair, _, err := p.pair(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 || days > maxKlineLimit {
+		return nil, fmt.Errorf("invalid days %d, must be between 1 and %d", days, maxKlineLimit)
+	}
+
+	klines, err := p.client.GetKlines(ctx, pair, "1d", days)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	volumes := make([]provider.DailyVolume, len(klines))
+	for i, kline := range klines {
+		volumes[i] = provider.DailyVolume{Date: kline.OpenTime, Volume: kline.Volume, QuoteVolume: kline.QuoteVolume}
+	}
+	return volumes, nil
+}
+
+// klineIntervals 支持的K线周期对应的币安周期代码
+var klineIntervals = map[time.Duration]string{
+	time.Minute:        "1m",
+	5 * time.Minute:    "5m",
+	15 * time.Minute:   "15m",
+	30 * time.Minute:   "30m",
+	time.Hour:          "1h",
+	4 * time.Hour:      "4h",
+	24 * time.Hour:     "1d",
+	7 * 24 * time.Hour: "1w",
+}
+
+// GetCandles 实现provider.CandleProvider接口
+func (p *Provider) GetCandles(ctx context.Context, symbol string, interval time.Duration, limit int) ([]provider.Candle, error) {
+	pair, _, err := p.pair(symbol)
+	if err != nil {
+		return nil, err
+	}
+	code, ok := klineIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported interval %s", interval)
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+	limit = min(limit, maxKlineLimit)
+
+	klines, err := p.client.GetKlines(ctx, pair, code, limit)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	candles := make([]provider.Candle, len(klines))
+	for i, kline := range klines {
+		candles[i] = provider.Candle{
+			OpenTime:    kline.OpenTime,
+			Open:        kline.Open,
+			High:        kline.High,
+			Low:         kline.Low,
+			Close:       kline.Close,
+			Volume:      kline.Volume,
+			QuoteVolume: kline.QuoteVolume,
+		}
+	}
+	return candles, nil
+}
+
+// pair 将币种转换为对USDT的交易对，返回交易对和统一后的币种
+func (p *Provider) pair(symbol string) (string, string, error) {
+	symbol = provider.NormalizeSymbol(symbol)
+	if !validSymbol.MatchString(symbol) || symbol == QuoteAsset {
+		return "", "", fmt.Errorf("%w: %s", provider.ErrUnsupportedSymbol, symbol)
+	}
+	return symbol + QuoteAsset, symbol, nil
+}
+
+// typedError 将客户端错误归类为provider包的错误，ctx的取消和超时原样返回
+func typedError(err error) error {
+	var statusErr *httpclient.StatusError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, ErrInvalidSymbol):
+		return fmt.Errorf("%w: %w", provider.ErrUnsupportedSymbol, err)
+	case errors.Is(err, budget.ErrExceeded):
+		return fmt.Errorf("%w: %w", provider.ErrRateLimited, err)
+	case errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode == http.StatusTeapot):
+		return fmt.Errorf("%w: %w", provider.ErrRateLimited, err)
+	default:
+		return fmt.Errorf("%w: %w", provider.ErrUnavailable, err)
+	}
+}
@@ -0,0 +1,233 @@
+// Package breaker 价格数据源熔断器，按数据源统计连续失败次数
+//
+// 数据源连续失败达到阈值后进入熔断，open_timeout内的请求直接跳过(ErrOpen)，服务层改用缓存或下一个数据源；
+// 到期后只放行一次探测请求，成功则恢复，失败则重新熔断。请求取消、超出调用预算、4xx(限流除外)
+// 以及不支持的币种等调用方错误不计为失败。
+// 未启用时只统计不熔断，状态只在当前进程内有效。
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/provider"
+)
+
+// 熔断默认配置
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+)
+
+// ErrOpen 数据源处于熔断中
+var ErrOpen = errors.New("circuit breaker open")
+
+// clientErrors 由调用方参数引起的错误，不说明数据源异常，见 RegisterClientErrors
+var (
+	clientErrorsMu sync.RWMutex
+	clientErrors   = []error{provider.ErrUnsupportedSymbol}
+)
+
+// RegisterClientErrors 注册不计为失败的调用方错误，如服务层的不支持币种和参数错误
+func RegisterClientErrors(errs ...error) {
+	clientErrorsMu.Lock()
+	defer clientErrorsMu.Unlock()
+	clientErrors = append(clientErrors, errs...)
+}
+
+// Set 按数据源划分的熔断器
+type Set struct {
+	mu          sync.Mutex
+	enabled     bool
+	threshold   int
+	openTimeout time.Duration
+	breakers    map[string]*breaker
+}
+
+// breaker 单个数据源的熔断状态
+type breaker struct {
+	state       string
+	consecutive int
+	probing     bool // 半开状态下探测请求是否已放行
+	openedAt    time.Time
+	lastError   string
+
+	successes int64
+	failures  int64
+	rejected  int64
+	trips     int64
+}
+
+var defaultSet = New(nil)
+
+// Init 按配置初始化全局熔断器，已有的状态保留
+func Init(cfg *config.Breaker) {
+	defaultSet.configure(cfg)
+}
+
+// Default 全局熔断器
+func Default() *Set {
+	return defaultSet
+}
+
+// New 创建熔断器，cfg为空时只统计不熔断
+func New(cfg *config.Breaker) *Set {
+	s := &Set{breakers: make(map[string]*breaker)}
+	s.configure(cfg)
+	return s
+}
+
+// configure 更新熔断配置，阈值和熔断时间未配置时使用默认值
+func (s *Set) configure(cfg *config.Breaker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enabled = cfg != nil && cfg.Enabled
+	s.threshold = defaultFailureThreshold
+	s.openTimeout = defaultOpenTimeout
+	if cfg != nil && cfg.FailureThreshold > 0 {
+		s.threshold = cfg.FailureThreshold
+	}
+	if cfg != nil && cfg.OpenTimeout > 0 {
+		s.openTimeout = cfg.OpenTimeout
+	}
+}
+
+// Allow 请求数据源前调用，熔断中时返回ErrOpen；放行后必须调用 Record 记录结果
+func (s *Set) Allow(source string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.breakerLocked(source)
+	if !s.enabled {
+		return nil
+	}
+
+	switch b.state {
+	case model.BreakerOpen:
+		if time.Since(b.openedAt) < s.openTimeout {
+			b.rejected++
+			return fmt.Errorf("%w: %s", ErrOpen, source)
+		}
+		b.state = model.BreakerHalfOpen
+		b.probing = true
+	case model.BreakerHalfOpen:
+		if b.probing {
+			b.rejected++
+			return fmt.Errorf("%w: %s", ErrOpen, source)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record 记录一次请求结果，err为空时恢复，计为失败的错误累计到阈值或探测失败时进入熔断
+func (s *Set) Record(source string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.breakerLocked(source)
+	probe := b.probing
+	b.probing = false
+
+	if err == nil {
+		b.successes++
+		b.consecutive = 0
+		b.state = model.BreakerClosed
+		return
+	}
+	if !Countable(err) {
+		return
+	}
+
+	b.failures++
+	b.consecutive++
+	b.lastError = err.Error()
+	if !s.enabled {
+		return
+	}
+	if probe || (b.state == model.BreakerClosed && b.consecutive >= s.threshold) {
+		b.state = model.BreakerOpen
+		b.openedAt = time.Now()
+		b.trips++
+	}
+}
+
+// Open 数据源是否处于熔断中且未到探测时间，不改变状态，服务层据此跳过后台刷新等非必要调用
+func (s *Set) Open(source string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.breakerLocked(source)
+	return s.enabled && b.state == model.BreakerOpen && time.Since(b.openedAt) < s.openTimeout
+}
+
+// Status 熔断配置和所有出现过的数据源的状态，按数据源名称排序
+func (s *Set) Status() *model.BreakerStatusResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := &model.BreakerStatusResponse{
+		Enabled:          s.enabled,
+		FailureThreshold: s.threshold,
+		OpenTimeout:      s.openTimeout.String(),
+		Sources:          make([]model.BreakerState, 0, len(s.breakers)),
+	}
+	for name, b := range s.breakers {
+		state := model.BreakerState{
+			Source:              name,
+			State:               b.state,
+			ConsecutiveFailures: b.consecutive,
+			Successes:           b.successes,
+			Failures:            b.failures,
+			Rejected:            b.rejected,
+			Trips:               b.trips,
+			LastError:           b.lastError,
+		}
+		if b.state != model.BreakerClosed {
+			openedAt := b.openedAt
+			retryAt := openedAt.Add(s.openTimeout)
+			state.OpenedAt = &openedAt
+			state.RetryAt = &retryAt
+		}
+		status.Sources = append(status.Sources, state)
+	}
+	sort.Slice(status.Sources, func(i, j int) bool { return status.Sources[i].Source < status.Sources[j].Source })
+	return status
+}
+
+// breakerLocked 获取数据源的熔断状态，首次出现时为关闭状态
+func (s *Set) breakerLocked(source string) *breaker {
+	b, ok := s.breakers[source]
+	if !ok {
+		b = &breaker{state: model.BreakerClosed}
+		s.breakers[source] = b
+	}
+	return b
+}
+
+// Countable 错误是否计为数据源失败：调用方错误不计，其余与重试的判断一致，请求取消、超出调用预算和4xx(限流除外)不计
+func Countable(err error) bool {
+	if errors.Is(err, ErrOpen) || isClientError(err) {
+		return false
+	}
+	return httpclient.Retryable(err)
+}
+
+// isClientError 是否为注册的调用方错误
+func isClientError(err error) bool {
+	clientErrorsMu.RLock()
+	defer clientErrorsMu.RUnlock()
+	for _, target := range clientErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
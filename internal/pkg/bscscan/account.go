@@ -0,0 +1,114 @@
+package bscscan
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// TokenTransfer BEP20代币转账记录
+type TokenTransfer struct {
+	BlockNumber     string `json:"blockNumber"`
+	TimeStamp       string `json:"timeStamp"`
+	Hash            string `json:"hash"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Value           string `json:"value"`
+	ContractAddress string `json:"contractAddress"`
+	TokenName       string `json:"tokenName"`
+	TokenSymbol     string `json:"tokenSymbol"`
+	TokenDecimal    string `json:"tokenDecimal"`
+	LogIndex        string `json:"logIndex"`
+}
+
+// Transaction 普通交易记录
+type Transaction struct {
+	BlockNumber     string `json:"blockNumber"`
+	TimeStamp       string `json:"timeStamp"`
+	Hash            string `json:"hash"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Value           string `json:"value"`
+	GasPrice        string `json:"gasPrice"`
+	GasUsed         string `json:"gasUsed"`
+	IsError         string `json:"isError"`
+	TxReceiptStatus string `json:"txreceipt_status"`
+	ContractAddress string `json:"contractAddress"`
+	Input           string `json:"input"`
+	MethodID        string `json:"methodId"`
+	FunctionName    string `json:"functionName"`
+}
+
+// InternalTransaction 合约内部转账记录
+type InternalTransaction struct {
+	BlockNumber string `json:"blockNumber"`
+	TimeStamp   string `json:"timeStamp"`
+	Hash        string `json:"hash"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value"`
+	Type        string `json:"type"`
+	IsError     string `json:"isError"`
+}
+
+// GetTokenTransfers 获取BEP20代币转账记录，contract与address至少提供一个，按区块倒序返回
+func (c *Client) GetTokenTransfers(ctx context.Context, contract, address string, page, offset int) ([]TokenTransfer, error) {
+	params := pageParams("tokentx", page, offset)
+	if contract != "" {
+		params.Set("contractaddress", contract)
+	}
+	if address != "" {
+		params.Set("address", address)
+	}
+
+	var result []TokenTransfer
+	if err := c.call(ctx, params, &result); err != nil {
+		if errors.Is(err, ErrNoResult) {
+			return []TokenTransfer{}, nil
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTransactions 获取地址的普通交易记录，按区块倒序返回
+func (c *Client) GetTransactions(ctx context.Context, address string, page, offset int) ([]Transaction, error) {
+	params := pageParams("txlist", page, offset)
+	params.Set("address", address)
+
+	var result []Transaction
+	if err := c.call(ctx, params, &result); err != nil {
+		if errors.Is(err, ErrNoResult) {
+			return []Transaction{}, nil
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetInternalTransactions 获取地址的合约内部转账记录，按区块倒序返回
+func (c *Client) GetInternalTransactions(ctx context.Context, address string, page, offset int) ([]InternalTransaction, error) {
+	params := pageParams("txlistinternal", page, offset)
+	params.Set("address", address)
+
+	var result []InternalTransaction
+	if err := c.call(ctx, params, &result); err != nil {
+		if errors.Is(err, ErrNoResult) {
+			return []InternalTransaction{}, nil
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// pageParams 构造account模块的分页查询参数
+func pageParams(action string, page, offset int) url.Values {
+	params := url.Values{}
+	params.Set("module", "account")
+	params.Set("action", action)
+	params.Set("page", strconv.Itoa(page))
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("sort", "desc")
+	return params
+}
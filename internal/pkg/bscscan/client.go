@@ -0,0 +1,137 @@
+package bscscan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/apikey"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+)
+
+// defaultBaseURL BscScan API默认地址
+const defaultBaseURL = "https://api.bscscan.com/api"
+
+// defaultRateLimit 单个API Key默认每秒请求数，与免费API Key的限制一致
+const defaultRateLimit = 5
+
+// poolName 共享API Key池名称
+const poolName = "bscscan"
+
+// rateLimitCooldown 触发限流后暂停该API Key的时间
+const rateLimitCooldown = 2 * time.Second
+
+var (
+	// ErrRateLimited 触发BscScan限流
+	ErrRateLimited = errors.New("bscscan rate limit reached")
+	// ErrNoResult 查询结果为空
+	ErrNoResult = errors.New("bscscan returned no result")
+)
+
+// Client BscScan API客户端
+type Client struct {
+	baseURL    string
+	keys       *apikey.Pool // 进程内所有BscScan客户端共享，按Key轮换和限流
+	httpClient *http.Client
+	retrier    *httpclient.Retrier
+}
+
+// response BscScan通用响应
+type response struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// NewClient 创建BscScan客户端，API Key池在首次创建时按配置初始化
+func NewClient(cfg *config.APIConfig) *Client {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	rps := cfg.RateLimit
+	if rps <= 0 {
+		rps = defaultRateLimit
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		keys:       apikey.Shared(poolName, apikey.Options{Keys: cfg.Keys(), RateLimit: rps}),
+		httpClient: httpclient.New(httpclient.Options{Timeout: cfg.Timeout, Provider: budget.ProviderBscScan, Proxy: cfg.Proxy}),
+		retrier:    httpclient.NewRetrier(budget.ProviderBscScan, cfg.RetryTimes, cfg.RetryInterval, nil),
+	}
+}
+
+// call 调用BscScan API并解析result字段，网络错误、5xx和限流按配置的次数指数退避重试，限流时换用其他API Key
+func (c *Client) call(ctx context.Context, params url.Values, result interface{}) error {
+	var resp response
+	if err := c.retrier.Do(ctx, func() error {
+		resp = response{}
+		return c.do(ctx, params, &resp)
+	}); err != nil {
+		return err
+	}
+
+	if resp.Status != "1" {
+		var message string
+		_ = json.Unmarshal(resp.Result, &message)
+		if strings.HasPrefix(resp.Message, "No ") {
+			return ErrNoResult
+		}
+		return fmt.Errorf("bscscan error: %s %s", resp.Message, message)
+	}
+
+	return json.Unmarshal(resp.Result, result)
+}
+
+// do 取一个API Key发送一次请求，触发限流时暂停该Key并返回ErrRateLimited
+func (c *Client) do(ctx context.Context, params url.Values, resp *response) error {
+	key, err := c.keys.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		params.Set("apikey", key)
+	}
+
+	if err := httpclient.GetJSON(ctx, c.httpClient, c.baseURL+"?"+params.Encode(), resp); err != nil {
+		// 请求地址包含API Key，避免写入日志
+		if key != "" {
+			return &maskedError{message: strings.ReplaceAll(err.Error(), key, apikey.Mask(key)), err: err}
+		}
+		return err
+	}
+
+	if resp.Status != "1" {
+		var message string
+		_ = json.Unmarshal(resp.Result, &message)
+		if strings.Contains(strings.ToLower(message), "rate limit") {
+			c.keys.ReportRateLimited(key, rateLimitCooldown)
+			return ErrRateLimited
+		}
+	}
+	return nil
+}
+
+// maskedError 隐藏了API Key的错误信息，保留原错误供重试判断
+type maskedError struct {
+	message string
+	err     error
+}
+
+// Error 实现error接口
+func (e *maskedError) Error() string {
+	return e.message
+}
+
+// Unwrap 返回原错误以支持errors.Is/As
+func (e *maskedError) Unwrap() error {
+	return e.err
+}
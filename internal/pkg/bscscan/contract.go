@@ -0,0 +1,50 @@
+package bscscan
+
+import (
+	"context"
+	"net/url"
+)
+
+// ContractSource 合约源码信息
+type ContractSource struct {
+	SourceCode           string `json:"SourceCode"`
+	ABI                  string `json:"ABI"`
+	ContractName         string `json:"ContractName"`
+	CompilerVersion      string `json:"CompilerVersion"`
+	OptimizationUsed     string `json:"OptimizationUsed"`
+	Runs                 string `json:"Runs"`
+	ConstructorArguments string `json:"ConstructorArguments"`
+	EVMVersion           string `json:"EVMVersion"`
+	Library              string `json:"Library"`
+	LicenseType          string `json:"LicenseType"`
+	Proxy                string `json:"Proxy"`
+	Implementation       string `json:"Implementation"`
+	SwarmSource          string `json:"SwarmSource"`
+}
+
+// Verified 合约源码是否已验证
+func (s *ContractSource) Verified() bool {
+	return s.SourceCode != ""
+}
+
+// IsProxy 是否为代理合约
+func (s *ContractSource) IsProxy() bool {
+	return s.Proxy == "1"
+}
+
+// GetSourceCode 获取合约源码及验证信息
+func (c *Client) GetSourceCode(ctx context.Context, address string) (*ContractSource, error) {
+	params := url.Values{}
+	params.Set("module", "contract")
+	params.Set("action", "getsourcecode")
+	params.Set("address", address)
+
+	var result []ContractSource
+	if err := c.call(ctx, params, &result); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, ErrNoResult
+	}
+	return &result[0], nil
+}
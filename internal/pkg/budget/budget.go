@@ -0,0 +1,200 @@
+// Package budget 外部API调用预算，按提供方统计每小时和每天的调用次数
+//
+// 调用次数始终统计；启用预算后，提供方超出软预算时请求在发出前即被拒绝(ErrExceeded)，
+// 服务层据此只返回缓存数据，直到下一个小时或自然日(UTC)重新计数。统计只在当前进程内有效。
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+)
+
+// 外部提供方名称，与 external_api.budget.providers 的key一致
+const (
+	ProviderBSCRPC    = "bsc_rpc"
+	ProviderBscScan   = "bscscan"
+	ProviderBinance   = "binance"
+	ProviderHuobi     = "huobi"
+	ProviderOKX       = "okx"
+	ProviderCoinbase  = "coinbase"
+	ProviderKraken    = "kraken"
+	ProviderCoinGecko = "coingecko"
+	ProviderTokenSync = "token_sync"
+)
+
+// ErrExceeded 提供方超出调用预算
+var ErrExceeded = errors.New("provider budget exceeded")
+
+// Tracker 调用预算统计器
+type Tracker struct {
+	mu       sync.Mutex
+	enabled  bool
+	limits   map[string]config.ProviderBudget
+	counters map[string]*counter
+}
+
+// counter 单个提供方的计数
+type counter struct {
+	hourStart time.Time
+	hourly    int64
+	dayStart  time.Time
+	daily     int64
+	total     int64
+	rejected  int64
+}
+
+var defaultTracker = NewTracker(nil)
+
+// Init 按配置初始化全局统计器的预算，已有的计数保留
+func Init(cfg *config.Budget) {
+	defaultTracker.configure(cfg)
+}
+
+// Default 全局统计器
+func Default() *Tracker {
+	return defaultTracker
+}
+
+// NewTracker 创建统计器，cfg为空时只统计不限制
+func NewTracker(cfg *config.Budget) *Tracker {
+	t := &Tracker{counters: make(map[string]*counter)}
+	t.configure(cfg)
+	return t
+}
+
+// configure 更新预算配置
+func (t *Tracker) configure(cfg *config.Budget) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.enabled = cfg != nil && cfg.Enabled
+	t.limits = make(map[string]config.ProviderBudget)
+	if cfg != nil {
+		for name, limit := range cfg.Providers {
+			t.limits[name] = limit
+		}
+	}
+}
+
+// Acquire 记录一次调用，启用预算且已超出时拒绝并返回ErrExceeded
+func (t *Tracker) Acquire(provider string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c := t.counterLocked(provider, time.Now())
+	if t.exceededLocked(provider, c) {
+		c.rejected++
+		return fmt.Errorf("%w: %s", ErrExceeded, provider)
+	}
+	c.hourly++
+	c.daily++
+	c.total++
+	return nil
+}
+
+// Enabled 是否启用预算限制
+func (t *Tracker) Enabled() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.enabled
+}
+
+// Exceeded 提供方是否超出预算，服务层据此跳过后台刷新等非必要调用
+func (t *Tracker) Exceeded(provider string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.exceededLocked(provider, t.counterLocked(provider, time.Now()))
+}
+
+// Usage 所有出现过或配置了预算的提供方统计，按名称排序
+func (t *Tracker) Usage() []model.ProviderUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for name := range t.limits {
+		t.counterLocked(name, now)
+	}
+
+	usage := make([]model.ProviderUsage, 0, len(t.counters))
+	for name, c := range t.counters {
+		t.counterLocked(name, now)
+		limit := t.limits[name]
+		usage = append(usage, model.ProviderUsage{
+			Provider:     name,
+			HourlyCalls:  c.hourly,
+			HourlyBudget: limit.Hourly,
+			DailyCalls:   c.daily,
+			DailyBudget:  limit.Daily,
+			TotalCalls:   c.total,
+			Rejected:     c.rejected,
+			Exceeded:     t.exceededLocked(name, c),
+			HourStart:    c.hourStart,
+			DayStart:     c.dayStart,
+		})
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Provider < usage[j].Provider })
+	return usage
+}
+
+// counterLocked 获取提供方计数，跨小时或自然日时重置对应计数
+func (t *Tracker) counterLocked(provider string, now time.Time) *counter {
+	c, ok := t.counters[provider]
+	if !ok {
+		c = &counter{}
+		t.counters[provider] = c
+	}
+
+	now = now.UTC()
+	if hour := now.Truncate(time.Hour); !hour.Equal(c.hourStart) {
+		c.hourStart = hour
+		c.hourly = 0
+	}
+	if day := now.Truncate(24 * time.Hour); !day.Equal(c.dayStart) {
+		c.dayStart = day
+		c.daily = 0
+	}
+	return c
+}
+
+// exceededLocked 计数是否达到预算
+func (t *Tracker) exceededLocked(provider string, c *counter) bool {
+	if !t.enabled {
+		return false
+	}
+	limit := t.limits[provider]
+	return (limit.Hourly > 0 && c.hourly >= limit.Hourly) || (limit.Daily > 0 && c.daily >= limit.Daily)
+}
+
+// Transport 包装http.RoundTripper，每个请求发出前计入全局统计器，超出预算时不发出请求
+func Transport(provider string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{provider: provider, base: base}
+}
+
+// transport 计入调用预算的RoundTripper
+type transport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+// RoundTrip 实现http.RoundTripper接口
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := defaultTracker.Acquire(t.provider); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,82 @@
+// Package buildinfo 进程的构建信息
+//
+// 版本号、构建时间和提交哈希由入口程序通过ldflags注入后调用 Set 写入，未注入提交哈希时使用go build记录的VCS信息。
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// 未通过ldflags注入时的默认值
+const (
+	defaultVersion = "dev"
+	unknown        = "unknown"
+)
+
+// Info 构建信息
+type Info struct {
+	Version   string
+	BuildTime string
+	GitCommit string
+	GoVersion string
+}
+
+var (
+	mu   sync.RWMutex
+	info = Info{Version: defaultVersion, BuildTime: unknown, GitCommit: unknown}
+)
+
+// Set 设置入口程序注入的构建信息，空值保持默认
+func Set(version, buildTime, gitCommit string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if version != "" {
+		info.Version = version
+	}
+	if buildTime != "" {
+		info.BuildTime = buildTime
+	}
+	if gitCommit != "" {
+		info.GitCommit = gitCommit
+	}
+}
+
+// Get 当前构建信息
+func Get() Info {
+	mu.RLock()
+	result := info
+	mu.RUnlock()
+
+	result.GoVersion = runtime.Version()
+	if result.GitCommit == unknown {
+		if revision := vcsRevision(); revision != "" {
+			result.GitCommit = revision
+		}
+	}
+	return result
+}
+
+// vcsRevision go build记录的提交哈希，工作区有未提交修改时追加-dirty
+func vcsRevision() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var revision string
+	dirty := false
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			dirty = setting.Value == "true"
+		}
+	}
+	if revision != "" && dirty {
+		revision += "-dirty"
+	}
+	return revision
+}
@@ -0,0 +1,236 @@
+// Package chaos 故障注入，用于演练依赖变慢或出错时的降级行为
+//
+// 启用后按目标(redis、http、bsc_rpc、grpc等)在调用前注入延迟，并按比例返回ErrInjected。
+// 只允许在非生产环境启用(见config.validate)，未启用时各注入点直接放行。
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-info/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 注入目标名称，与 chaos.faults 的key一致
+const (
+	TargetRedis = "redis" // Redis命令
+	TargetHTTP  = "http"  // 未单独配置的外部HTTP调用，按提供方名称(如bsc_rpc、bscscan)配置时优先
+	TargetGRPC  = "grpc"  // gRPC服务端处理
+)
+
+// ErrInjected 注入的故障
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Injector 故障注入器
+type Injector struct {
+	faults map[string]config.ChaosFault
+	stats  map[string]*targetStats
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// targetStats 单个目标的注入统计
+type targetStats struct {
+	calls   atomic.Uint64
+	delayed atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// Stats 单个目标的注入统计
+type Stats struct {
+	Target  string
+	Calls   uint64 // 经过注入点的调用数
+	Delayed uint64 // 注入了延迟的调用数
+	Failed  uint64 // 注入了错误的调用数
+}
+
+var defaultInjector atomic.Pointer[Injector]
+
+// Init 按配置设置全局注入器，未启用时清除
+func Init(cfg *config.Chaos) {
+	if !cfg.Enabled {
+		defaultInjector.Store(nil)
+		return
+	}
+	defaultInjector.Store(New(cfg))
+}
+
+// Default 全局注入器，未启用时为空
+func Default() *Injector {
+	return defaultInjector.Load()
+}
+
+// New 按配置创建注入器，seed为0时使用当前时间
+func New(cfg *config.Chaos) *Injector {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	i := &Injector{
+		faults: make(map[string]config.ChaosFault, len(cfg.Faults)),
+		stats:  make(map[string]*targetStats, len(cfg.Faults)),
+		rand:   rand.New(rand.NewSource(seed)),
+	}
+	for target, fault := range cfg.Faults {
+		i.faults[target] = fault
+		i.stats[target] = &targetStats{}
+	}
+	return i
+}
+
+// Has 是否为目标配置了故障
+func (i *Injector) Has(target string) bool {
+	if i == nil {
+		return false
+	}
+	_, ok := i.faults[target]
+	return ok
+}
+
+// Inject 按目标的配置等待注入的延迟，并按错误比例返回ErrInjected；ctx先结束时返回ctx的错误
+// 注入器为空或目标未配置时直接返回nil
+func (i *Injector) Inject(ctx context.Context, target string) error {
+	if i == nil {
+		return nil
+	}
+	fault, ok := i.faults[target]
+	if !ok {
+		return nil
+	}
+	stats := i.stats[target]
+	stats.calls.Add(1)
+
+	delay, fail := i.roll(fault)
+	if delay > 0 {
+		stats.delayed.Add(1)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if fail {
+		stats.failed.Add(1)
+		return fmt.Errorf("%w: %s", ErrInjected, target)
+	}
+	return nil
+}
+
+// Stats 各目标的注入统计，按目标名称排序
+func (i *Injector) Stats() []Stats {
+	if i == nil {
+		return nil
+	}
+	stats := make([]Stats, 0, len(i.stats))
+	for target, st := range i.stats {
+		stats = append(stats, Stats{
+			Target:  target,
+			Calls:   st.calls.Load(),
+			Delayed: st.delayed.Load(),
+			Failed:  st.failed.Load(),
+		})
+	}
+	sort.Slice(stats, func(a, b int) bool { return stats[a].Target < stats[b].Target })
+	return stats
+}
+
+// roll 计算本次调用的延迟和是否出错，延迟为latency加上[0, jitter)的随机值
+func (i *Injector) roll(fault config.ChaosFault) (time.Duration, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delay := fault.Latency
+	if fault.Jitter > 0 {
+		delay += time.Duration(i.rand.Int63n(int64(fault.Jitter)))
+	}
+	fail := fault.ErrorRate > 0 && i.rand.Float64() < fault.ErrorRate
+	return delay, fail
+}
+
+// Transport 在外部HTTP调用前注入故障，provider已配置时使用provider的故障，否则使用http
+func Transport(provider string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{provider: provider, base: base}
+}
+
+// transport 注入故障的RoundTripper，每次请求读取全局注入器
+type transport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+// RoundTrip 实现http.RoundTripper接口
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	injector := Default()
+	if injector == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	target := TargetHTTP
+	if t.provider != "" && injector.Has(t.provider) {
+		target = t.provider
+	}
+	if err := injector.Inject(req.Context(), target); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// RedisHook 在Redis命令和pipeline执行前注入故障的go-redis钩子
+func RedisHook(injector *Injector) redis.Hook {
+	return redisHook{injector: injector}
+}
+
+// redisHook 注入故障的go-redis钩子，连接建立不注入
+type redisHook struct {
+	injector *Injector
+}
+
+// DialHook 实现redis.Hook接口
+func (h redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook 实现redis.Hook接口
+func (h redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.injector.Inject(ctx, TargetRedis); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+// ProcessPipelineHook 实现redis.Hook接口，整个pipeline只注入一次
+func (h redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := h.injector.Inject(ctx, TargetRedis); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		return next(ctx, cmds)
+	}
+}
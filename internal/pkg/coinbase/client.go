@@ -0,0 +1,155 @@
+// Package coinbase Coinbase Exchange公开行情REST API客户端
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+)
+
+// defaultBaseURL Coinbase Exchange API默认地址
+const defaultBaseURL = "https://api.exchange.coinbase.com"
+
+// ErrInvalidProduct 交易对(产品)不存在
+var ErrInvalidProduct = errors.New("coinbase: invalid product")
+
+// Client Coinbase Exchange API客户端
+type Client struct {
+	baseURL    string
+	retrier    *httpclient.Retrier
+	httpClient *http.Client
+}
+
+// Ticker 交易对最新成交
+type Ticker struct {
+	Price  float64   // 最新成交价
+	Volume float64   // 24小时成交量(交易货币)
+	Time   time.Time // 成交时间
+}
+
+// Candle K线
+type Candle struct {
+	Time   time.Time // 开盘时间
+	Low    float64
+	High   float64
+	Open   float64
+	Close  float64
+	Volume float64 // 成交量(交易货币)
+}
+
+// ticker Coinbase行情响应，数值均为字符串
+type ticker struct {
+	Price  string    `json:"price"`
+	Volume string    `json:"volume"`
+	Time   time.Time `json:"time"`
+}
+
+// apiError Coinbase错误响应
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// NewClient 创建Coinbase客户端，按配置的地址、超时、代理和重试次数访问API
+func NewClient(cfg *config.APIConfig) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		retrier:    httpclient.NewRetrier(budget.ProviderCoinbase, cfg.RetryTimes, cfg.RetryInterval, retryable),
+		httpClient: httpclient.New(httpclient.Options{Timeout: cfg.Timeout, Provider: budget.ProviderCoinbase, Proxy: cfg.Proxy}),
+	}
+}
+
+// GetTicker 获取交易对(如BTC-USD)的最新成交价
+func (c *Client) GetTicker(ctx context.Context, productID string) (*Ticker, error) {
+	var t ticker
+	if err := c.get(ctx, "/products/"+url.PathEscape(strings.ToUpper(productID))+"/ticker", nil, &t); err != nil {
+		return nil, err
+	}
+
+	price, err := strconv.ParseFloat(t.Price, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coinbase price %q for %s: %w", t.Price, productID, err)
+	}
+	volume, err := strconv.ParseFloat(t.Volume, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coinbase volume %q for %s: %w", t.Volume, productID, err)
+	}
+	return &Ticker{Price: price, Volume: volume, Time: t.Time}, nil
+}
+
+// GetCandles 获取交易对最近的K线(最多300根)，granularity为K线周期，按时间倒序
+func (c *Client) GetCandles(ctx context.Context, productID string, granularity time.Duration) ([]Candle, error) {
+	params := url.Values{}
+	params.Set("granularity", strconv.Itoa(int(granularity.Seconds())))
+
+	var rows [][]float64
+	if err := c.get(ctx, "/products/"+url.PathEscape(strings.ToUpper(productID))+"/candles", params, &rows); err != nil {
+		return nil, err
+	}
+
+	candles := make([]Candle, 0, len(rows))
+	for _, row := range rows {
+		// [开盘时间(秒), 低, 高, 开, 收, 成交量]
+		if len(row) < 6 {
+			return nil, fmt.Errorf("invalid coinbase candle for %s: expected 6 fields, got %d", productID, len(row))
+		}
+		candles = append(candles, Candle{
+			Time:   time.Unix(int64(row[0]), 0).UTC(),
+			Low:    row[1],
+			High:   row[2],
+			Open:   row[3],
+			Close:  row[4],
+			Volume: row[5],
+		})
+	}
+	return candles, nil
+}
+
+// get 发送GET请求，网络错误、限流和5xx响应按配置的次数指数退避重试
+func (c *Client) get(ctx context.Context, path string, params url.Values, result interface{}) error {
+	endpoint := c.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	return c.retrier.Do(ctx, func() error {
+		return convertError(httpclient.GetJSON(ctx, c.httpClient, endpoint, result))
+	})
+}
+
+// convertError 将产品不存在的响应(404或400 Invalid product)转换为ErrInvalidProduct
+func convertError(err error) error {
+	var statusErr *httpclient.StatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+
+	var apiErr apiError
+	_ = json.Unmarshal([]byte(statusErr.Body), &apiErr)
+	switch {
+	case statusErr.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrInvalidProduct, apiErr.Message)
+	case statusErr.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(apiErr.Message), "product"):
+		return fmt.Errorf("%w: %s", ErrInvalidProduct, apiErr.Message)
+	}
+	return err
+}
+
+// retryable 是否值得重试，产品不存在时不重试，其余按httpclient.Retryable判断
+func retryable(err error) bool {
+	return !errors.Is(err, ErrInvalidProduct) && httpclient.Retryable(err)
+}
@@ -0,0 +1,115 @@
+package coinbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/provider"
+)
+
+// providerName 数据源名称
+const providerName = "coinbase"
+
+// QuoteAsset 价格和成交额使用的计价币种，Coinbase现货以美元计价
+const QuoteAsset = "USD"
+
+// maxCandles 单次请求返回的最大K线数量
+const maxCandles = 300
+
+// validSymbol 币种名称只包含字母和数字
+var validSymbol = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// Provider Coinbase价格和交易量数据源，币种以对美元的交易对(如BTC-USD)查询
+type Provider struct {
+	client *Client
+}
+
+// NewProvider 创建Coinbase数据源
+func NewProvider(client *Client) *Provider {
+	return &Provider{client: client}
+}
+
+// Name 实现provider.PriceProvider接口
+func (p *Provider) Name() string {
+	return providerName
+}
+
+// GetPrice 实现provider.PriceProvider接口，Coinbase行情不含24小时统计
+func (p *Provider) GetPrice(ctx context.Context, symbol string) (*provider.Quote, error) {
+	productID, symbol, err := p.productID(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker, err := p.client.GetTicker(ctx, productID)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	if ticker.Price <= 0 {
+		return nil, fmt.Errorf("%w: invalid coinbase price %v for %s", provider.ErrUnavailable, ticker.Price, productID)
+	}
+	quoteTime := ticker.Time
+	if quoteTime.IsZero() {
+		quoteTime = time.Now()
+	}
+	return &provider.Quote{Symbol: symbol, Price: ticker.Price, Currency: QuoteAsset, Time: quoteTime}, nil
+}
+
+// GetDailyVolumes 实现provider.VolumeProvider接口，按UTC自然日统计，成交额以当日收盘价估算，
+// Coinbase按时间倒序返回，转换为升序
+func (p *Provider) GetDailyVolumes(ctx context.Context, symbol string, days int) ([]provider.DailyVolume, error) {
+	productID, _, err := p.productID(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 || days > maxCandles {
+		return nil, fmt.Errorf("invalid days %d, must be between 1 and %d", days, maxCandles)
+	}
+
+	candles, err := p.client.GetCandles(ctx, productID, 24*time.Hour)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	if len(candles) > days {
+		candles = candles[:days]
+	}
+	volumes := make([]provider.DailyVolume, len(candles))
+	for i, candle := range candles {
+		volumes[i] = provider.DailyVolume{Date: candle.Time, Volume: candle.Volume, QuoteVolume: candle.Volume * candle.Close}
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Date.Before(volumes[j].Date) })
+	return volumes, nil
+}
+
+// productID 将币种转换为对美元的交易对，返回交易对和统一后的币种
+func (p *Provider) productID(symbol string) (string, string, error) {
+	symbol = provider.NormalizeSymbol(symbol)
+	if !validSymbol.MatchString(symbol) || symbol == QuoteAsset {
+		return "", "", fmt.Errorf("%w: %s", provider.ErrUnsupportedSymbol, symbol)
+	}
+	return symbol + "-" + QuoteAsset, symbol, nil
+}
+
+// typedError 将客户端错误归类为provider包的错误，ctx的取消和超时原样返回
+func typedError(err error) error {
+	var statusErr *httpclient.StatusError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, ErrInvalidProduct):
+		return fmt.Errorf("%w: %w", provider.ErrUnsupportedSymbol, err)
+	case errors.Is(err, budget.ErrExceeded):
+		return fmt.Errorf("%w: %w", provider.ErrRateLimited, err)
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", provider.ErrRateLimited, err)
+	default:
+		return fmt.Errorf("%w: %w", provider.ErrUnavailable, err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package coingecko CoinGecko公开市场数据REST API客户端
+package coingecko
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+)
+
+// defaultBaseURL CoinGecko API默认地址(公开/Demo套餐)
+const defaultBaseURL = "https://api.coingecko.com/api/v3"
+
+// maxIDsPerRequest 单次请求查询的最大币种数量
+const maxIDsPerRequest = 250
+
+// DefaultIDs 常用币种对应的CoinGecko币种ID，币种符号在CoinGecko中不唯一，需要按ID查询
+var DefaultIDs = map[string]string{
+	"BTC":  "bitcoin",
+	"ETH":  "ethereum",
+	"LTC":  "litecoin",
+	"BCH":  "bitcoin-cash",
+	"ADA":  "cardano",
+	"DOT":  "polkadot",
+	"LINK": "chainlink",
+	"XRP":  "ripple",
+	"BNB":  "binancecoin",
+	"USDT": "tether",
+	"USDC": "usd-coin",
+	"SOL":  "solana",
+	"DOGE": "dogecoin",
+	"TRX":  "tron",
+	"CAKE": "pancakeswap-token",
+}
+
+// Client CoinGecko API客户端
+type Client struct {
+	baseURL    string
+	apiKey     string
+	retrier    *httpclient.Retrier
+	httpClient *http.Client
+}
+
+// Market 币种市场数据，CoinGecko未提供的字段为nil
+type Market struct {
+	ID                string    `json:"id"`
+	Symbol            string    `json:"symbol"`
+	Name              string    `json:"name"`
+	CurrentPrice      *float64  `json:"current_price"`
+	MarketCap         *float64  `json:"market_cap"`
+	MarketCapRank     *int      `json:"market_cap_rank"`
+	FullyDilutedValue *float64  `json:"fully_diluted_valuation"`
+	TotalVolume       *float64  `json:"total_volume"`
+	CirculatingSupply *float64  `json:"circulating_supply"`
+	TotalSupply       *float64  `json:"total_supply"`
+	MaxSupply         *float64  `json:"max_supply"`
+	LastUpdated       time.Time `json:"last_updated"`
+}
+
+// Global 全市场汇总数据，金额和占比按计价币种或币种分列
+type Global struct {
+	ActiveCryptocurrencies          int                `json:"active_cryptocurrencies"`
+	Markets                         int                `json:"markets"`
+	TotalMarketCap                  map[string]float64 `json:"total_market_cap"`      // 计价币种(小写)到总市值
+	TotalVolume                     map[string]float64 `json:"total_volume"`          // 计价币种(小写)到24小时成交额
+	MarketCapPercentage             map[string]float64 `json:"market_cap_percentage"` // 币种符号(小写)到市值占比(百分比)
+	MarketCapChangePercentage24hUSD float64            `json:"market_cap_change_percentage_24h_usd"`
+	UpdatedAt                       int64              `json:"updated_at"` // Unix秒
+}
+
+// NewClient 创建CoinGecko客户端，按配置的地址、超时、代理和重试次数访问API，配置了api_key时随请求发送
+func NewClient(cfg *config.APIConfig) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		apiKey:     cfg.APIKey,
+		retrier:    httpclient.NewRetrier(budget.ProviderCoinGecko, cfg.RetryTimes, cfg.RetryInterval, nil),
+		httpClient: httpclient.New(httpclient.Options{Timeout: cfg.Timeout, Provider: budget.ProviderCoinGecko, Proxy: cfg.Proxy}),
+	}
+}
+
+// GetMarkets 按币种ID批量获取市场数据，vsCurrency为计价币种(如usd)，不存在的ID不出现在结果中
+func (c *Client) GetMarkets(ctx context.Context, ids []string, vsCurrency string) ([]Market, error) {
+	var markets []Market
+	for start := 0; start < len(ids); start += maxIDsPerRequest {
+		end := min(start+maxIDsPerRequest, len(ids))
+
+		params := url.Values{}
+		params.Set("vs_currency", strings.ToLower(vsCurrency))
+		params.Set("ids", strings.Join(ids[start:end], ","))
+		params.Set("per_page", strconv.Itoa(maxIDsPerRequest))
+
+		var page []Market
+		if err := c.get(ctx, "/coins/markets", params, &page); err != nil {
+			return nil, err
+		}
+		markets = append(markets, page...)
+	}
+	return markets, nil
+}
+
+// GetGlobal 获取全市场总市值、24小时成交额和各币种市值占比
+func (c *Client) GetGlobal(ctx context.Context) (*Global, error) {
+	var resp struct {
+		Data Global `json:"data"`
+	}
+	if err := c.get(ctx, "/global", url.Values{}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// get 发送GET请求，网络错误、限流和5xx响应按配置的次数指数退避重试
+func (c *Client) get(ctx context.Context, path string, params url.Values, result interface{}) error {
+	if c.apiKey != "" {
+		params.Set(c.apiKeyParam(), c.apiKey)
+	}
+	endpoint := c.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	return c.retrier.Do(ctx, func() error {
+		return httpclient.GetJSON(ctx, c.httpClient, endpoint, result)
+	})
+}
+
+// apiKeyParam 付费套餐(pro-api地址)和Demo套餐使用不同的API Key参数
+func (c *Client) apiKeyParam() string {
+	if strings.Contains(c.baseURL, "pro-api.") {
+		return "x_cg_pro_api_key"
+	}
+	return "x_cg_demo_api_key"
+}
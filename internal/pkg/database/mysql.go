@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// MySQL默认配置
+const (
+	defaultMySQLCharset      = "utf8mb4"
+	defaultMySQLDialTimeout  = 5 * time.Second
+	defaultMySQLMaxOpenConns = 20
+	defaultMySQLMaxIdleConns = 5
+)
+
+// MySQLClient MySQL客户端接口
+type MySQLClient interface {
+	// DB 底层连接池，用于查询和事务
+	DB() *sqlx.DB
+	// Stats 连接池统计
+	Stats() sql.DBStats
+	Close() error
+	Ping(ctx context.Context) error
+}
+
+// mysqlClient MySQL客户端实现
+type mysqlClient struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+// NewMySQLClient 创建MySQL客户端，按配置设置连接池并测试连接
+func NewMySQLClient(cfg *config.MySQLConfig) (MySQLClient, error) {
+	log := logger.GetLogger()
+
+	dsn, err := mysqlDSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sqlx.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mysql: %w", err)
+	}
+
+	maxOpen := cfg.MaxOpenConns
+	if maxOpen <= 0 {
+		maxOpen = defaultMySQLMaxOpenConns
+	}
+	maxIdle := cfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultMySQLMaxIdleConns
+	}
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	// 测试连接
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to mysql: %w", err)
+	}
+
+	log.Info("MySQL connected successfully")
+
+	return &mysqlClient{
+		db:     db,
+		logger: log,
+	}, nil
+}
+
+// mysqlDSN 由配置生成连接串，由驱动负责转义用户名和密码
+func mysqlDSN(cfg *config.MySQLConfig) (string, error) {
+	dsn := mysql.NewConfig()
+	dsn.User = cfg.Username
+	dsn.Passwd = cfg.Password
+	dsn.Net = "tcp"
+	dsn.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	dsn.DBName = cfg.Database
+	dsn.ParseTime = cfg.ParseTime
+	dsn.Timeout = cfg.DialTimeout
+	if dsn.Timeout <= 0 {
+		dsn.Timeout = defaultMySQLDialTimeout
+	}
+	dsn.ReadTimeout = cfg.ReadTimeout
+	dsn.WriteTimeout = cfg.WriteTimeout
+
+	charset := cfg.Charset
+	if charset == "" {
+		charset = defaultMySQLCharset
+	}
+	dsn.Params = map[string]string{"charset": charset}
+
+	if cfg.Loc != "" {
+		loc, err := time.LoadLocation(cfg.Loc)
+		if err != nil {
+			return "", fmt.Errorf("invalid mysql loc %s: %w", cfg.Loc, err)
+		}
+		dsn.Loc = loc
+	}
+	return dsn.FormatDSN(), nil
+}
+
+// DB 获取底层连接池
+func (m *mysqlClient) DB() *sqlx.DB {
+	return m.db
+}
+
+// Stats 获取连接池统计
+func (m *mysqlClient) Stats() sql.DBStats {
+	return m.db.Stats()
+}
+
+// Close 关闭连接池
+func (m *mysqlClient) Close() error {
+	err := m.db.Close()
+	if err != nil {
+		m.logger.Errorf("MySQL close error: %v", err)
+		return err
+	}
+	m.logger.Info("MySQL connection closed")
+	return nil
+}
+
+// Ping 测试连接
+func (m *mysqlClient) Ping(ctx context.Context) error {
+	err := m.db.PingContext(ctx)
+	if err != nil {
+		m.logger.Errorf("MySQL ping error: %v", err)
+		return err
+	}
+	return nil
+}
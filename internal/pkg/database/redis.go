@@ -23,7 +23,12 @@ type RedisClient interface {
 	HSet(ctx context.Context, key string, values ...interface{}) error
 	HDel(ctx context.Context, key string, fields ...string) error
 	HExists(ctx context.Context, key, field string) (bool, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	ZAdd(ctx context.Context, key string, score float64, member string) error
+	ZRangeByScore(ctx context.Context, key, min, max string) ([]string, error)
+	ZRemRangeByScore(ctx context.Context, key, min, max string) error
 	GetClient() *redis.Client
+	KeyPrefix() string
 	Close() error
 	Ping(ctx context.Context) error
 }
@@ -32,17 +37,18 @@ type RedisClient interface {
 type redisClient struct {
 	client *redis.Client
 	logger logger.Logger
+	prefix string
 }
 
-// NewRedisClient 创建Redis客户端
-func NewRedisClient(cfg *config.RedisConfig) (RedisClient, error) {
+// NewRedisClient 创建Redis客户端，所有key自动添加keyPrefix前缀
+func NewRedisClient(cfg *config.RedisConfig, keyPrefix string) (RedisClient, error) {
 	log := logger.GetLogger()
 
 	// 创建Redis客户端
 	rdb := redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		Password:     cfg.Password,
-		DB:           cfg.DB,
+		Addr:            fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password:        cfg.Password,
+		DB:              cfg.DB,
 		PoolSize:        cfg.PoolSize,
 		MinIdleConns:    cfg.MinIdleConns,
 		DialTimeout:     cfg.DialTimeout,
@@ -65,12 +71,13 @@ func NewRedisClient(cfg *config.RedisConfig) (RedisClient, error) {
 	return &redisClient{
 		client: rdb,
 		logger: log,
+		prefix: keyPrefix,
 	}, nil
 }
 
 // Get 获取值
 func (r *redisClient) Get(ctx context.Context, key string) (string, error) {
-	result, err := r.client.Get(ctx, key).Result()
+	result, err := r.client.Get(ctx, r.key(key)).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return "", nil
@@ -83,7 +90,7 @@ func (r *redisClient) Get(ctx context.Context, key string) (string, error) {
 
 // Set 设置值
 func (r *redisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	err := r.client.Set(ctx, key, value, expiration).Err()
+	err := r.client.Set(ctx, r.key(key), value, expiration).Err()
 	if err != nil {
 		r.logger.Errorf("Redis SET error for key %s: %v", key, err)
 		return err
@@ -93,7 +100,7 @@ func (r *redisClient) Set(ctx context.Context, key string, value interface{}, ex
 
 // Del 删除键
 func (r *redisClient) Del(ctx context.Context, keys ...string) error {
-	err := r.client.Del(ctx, keys...).Err()
+	err := r.client.Del(ctx, r.keys(keys)...).Err()
 	if err != nil {
 		r.logger.Errorf("Redis DEL error for keys %v: %v", keys, err)
 		return err
@@ -103,7 +110,7 @@ func (r *redisClient) Del(ctx context.Context, keys ...string) error {
 
 // Exists 检查键是否存在
 func (r *redisClient) Exists(ctx context.Context, keys ...string) (int64, error) {
-	result, err := r.client.Exists(ctx, keys...).Result()
+	result, err := r.client.Exists(ctx, r.keys(keys)...).Result()
 	if err != nil {
 		r.logger.Errorf("Redis EXISTS error for keys %v: %v", keys, err)
 		return 0, err
@@ -113,7 +120,7 @@ func (r *redisClient) Exists(ctx context.Context, keys ...string) (int64, error)
 
 // Expire 设置过期时间
 func (r *redisClient) Expire(ctx context.Context, key string, expiration time.Duration) error {
-	err := r.client.Expire(ctx, key, expiration).Err()
+	err := r.client.Expire(ctx, r.key(key), expiration).Err()
 	if err != nil {
 		r.logger.Errorf("Redis EXPIRE error for key %s: %v", key, err)
 		return err
@@ -123,7 +130,7 @@ func (r *redisClient) Expire(ctx context.Context, key string, expiration time.Du
 
 // TTL 获取过期时间
 func (r *redisClient) TTL(ctx context.Context, key string) (time.Duration, error) {
-	result, err := r.client.TTL(ctx, key).Result()
+	result, err := r.client.TTL(ctx, r.key(key)).Result()
 	if err != nil {
 		r.logger.Errorf("Redis TTL error for key %s: %v", key, err)
 		return 0, err
@@ -133,7 +140,7 @@ func (r *redisClient) TTL(ctx context.Context, key string) (time.Duration, error
 
 // HGet 获取哈希字段值
 func (r *redisClient) HGet(ctx context.Context, key, field string) (string, error) {
-	result, err := r.client.HGet(ctx, key, field).Result()
+	result, err := r.client.HGet(ctx, r.key(key), field).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return "", nil
@@ -146,7 +153,7 @@ func (r *redisClient) HGet(ctx context.Context, key, field string) (string, erro
 
 // HSet 设置哈希字段值
 func (r *redisClient) HSet(ctx context.Context, key string, values ...interface{}) error {
-	err := r.client.HSet(ctx, key, values...).Err()
+	err := r.client.HSet(ctx, r.key(key), values...).Err()
 	if err != nil {
 		r.logger.Errorf("Redis HSET error for key %s: %v", key, err)
 		return err
@@ -156,7 +163,7 @@ func (r *redisClient) HSet(ctx context.Context, key string, values ...interface{
 
 // HDel 删除哈希字段
 func (r *redisClient) HDel(ctx context.Context, key string, fields ...string) error {
-	err := r.client.HDel(ctx, key, fields...).Err()
+	err := r.client.HDel(ctx, r.key(key), fields...).Err()
 	if err != nil {
 		r.logger.Errorf("Redis HDEL error for key %s fields %v: %v", key, fields, err)
 		return err
@@ -166,7 +173,7 @@ func (r *redisClient) HDel(ctx context.Context, key string, fields ...string) er
 
 // HExists 检查哈希字段是否存在
 func (r *redisClient) HExists(ctx context.Context, key, field string) (bool, error) {
-	result, err := r.client.HExists(ctx, key, field).Result()
+	result, err := r.client.HExists(ctx, r.key(key), field).Result()
 	if err != nil {
 		r.logger.Errorf("Redis HEXISTS error for key %s field %s: %v", key, field, err)
 		return false, err
@@ -174,6 +181,46 @@ func (r *redisClient) HExists(ctx context.Context, key, field string) (bool, err
 	return result, nil
 }
 
+// HGetAll 获取哈希所有字段
+func (r *redisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	result, err := r.client.HGetAll(ctx, r.key(key)).Result()
+	if err != nil {
+		r.logger.Errorf("Redis HGETALL error for key %s: %v", key, err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// ZAdd 向有序集合添加成员
+func (r *redisClient) ZAdd(ctx context.Context, key string, score float64, member string) error {
+	err := r.client.ZAdd(ctx, r.key(key), redis.Z{Score: score, Member: member}).Err()
+	if err != nil {
+		r.logger.Errorf("Redis ZADD error for key %s: %v", key, err)
+		return err
+	}
+	return nil
+}
+
+// ZRangeByScore 按分数范围获取有序集合成员
+func (r *redisClient) ZRangeByScore(ctx context.Context, key, min, max string) ([]string, error) {
+	result, err := r.client.ZRangeByScore(ctx, r.key(key), &redis.ZRangeBy{Min: min, Max: max}).Result()
+	if err != nil {
+		r.logger.Errorf("Redis ZRANGEBYSCORE error for key %s: %v", key, err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// ZRemRangeByScore 按分数范围删除有序集合成员
+func (r *redisClient) ZRemRangeByScore(ctx context.Context, key, min, max string) error {
+	err := r.client.ZRemRangeByScore(ctx, r.key(key), min, max).Err()
+	if err != nil {
+		r.logger.Errorf("Redis ZREMRANGEBYSCORE error for key %s: %v", key, err)
+		return err
+	}
+	return nil
+}
+
 // Close 关闭连接
 func (r *redisClient) Close() error {
 	err := r.client.Close()
@@ -198,4 +245,23 @@ func (r *redisClient) Ping(ctx context.Context) error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// KeyPrefix 获取key前缀
+func (r *redisClient) KeyPrefix() string {
+	return r.prefix
+}
+
+// key 为key添加前缀
+func (r *redisClient) key(key string) string {
+	return r.prefix + key
+}
+
+// keys 为多个key添加前缀
+func (r *redisClient) keys(keys []string) []string {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefix + key
+	}
+	return prefixed
+}
@@ -0,0 +1,69 @@
+// Package deadline 请求截止时间的解析与传递
+//
+// 客户端通过 X-Request-Timeout 请求头或gRPC deadline声明愿意等待的时间，服务端据此派生子上下文，
+// 下游的Redis、HTTP、Kitex和RocketMQ调用随之取消，避免慢上游在客户端放弃后继续占用goroutine。
+package deadline
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header 客户端声明超时时间的请求头，值为Go时长(如 1.5s、500ms)或整数毫秒
+const Header = "X-Request-Timeout"
+
+// Parse 解析请求头的值，空值返回0；非正数或无法解析时返回错误
+func Parse(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	var (
+		timeout time.Duration
+		err     error
+	)
+	if ms, convErr := strconv.ParseInt(value, 10, 64); convErr == nil {
+		timeout = time.Duration(ms) * time.Millisecond
+	} else if timeout, err = time.ParseDuration(value); err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", Header, value, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be positive", Header, value)
+	}
+	return timeout, nil
+}
+
+// Derive 派生带截止时间的子上下文，取客户端超时、服务端上限和父上下文截止时间中最早的一个
+// requested或limit不大于0时表示未设置，两者都未设置时仍返回可取消的子上下文
+func Derive(parent context.Context, requested, limit time.Duration) (context.Context, context.CancelFunc) {
+	timeout := Effective(requested, limit)
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// Effective 客户端超时与服务端上限中较小的有效值，都未设置时返回0
+func Effective(requested, limit time.Duration) time.Duration {
+	switch {
+	case requested <= 0:
+		return limit
+	case limit <= 0 || requested < limit:
+		return requested
+	default:
+		return limit
+	}
+}
+
+// Remaining 上下文剩余的时间，未设置截止时间时返回false
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(d), true
+}
@@ -0,0 +1,437 @@
+// Package expr 告警条件使用的简单表达式求值
+//
+// 支持数字、变量、算术运算(+ - * /)、比较(> >= < <= == !=)、
+// 逻辑运算(&& || !，也可写作AND OR NOT)和括号，例如：
+//
+//	price > 70000 AND (volume_ratio >= 2 OR rsi < 30)
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 表达式限制
+const (
+	MaxLength = 512 // 表达式最大长度
+	maxDepth  = 32  // 最大嵌套层数
+)
+
+// ErrMissingVariable 求值时缺少变量
+var ErrMissingVariable = errors.New("missing variable")
+
+// Expr 编译后的表达式
+type Expr struct {
+	source string
+	root   node
+	vars   []string
+}
+
+// Compile 解析表达式，表达式的结果必须是布尔值
+func Compile(source string) (*Expr, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+	if len(source) > MaxLength {
+		return nil, fmt.Errorf("expression exceeds %d characters", MaxLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, vars: make(map[string]bool)}
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tokens[p.pos].text, p.tokens[p.pos].pos)
+	}
+	if !root.boolean() {
+		return nil, fmt.Errorf("expression must be a condition, e.g. price > 100")
+	}
+
+	vars := make([]string, 0, len(p.vars))
+	for name := range p.vars {
+		vars = append(vars, name)
+	}
+	sort.Strings(vars)
+	return &Expr{source: source, root: root, vars: vars}, nil
+}
+
+// String 返回表达式原文
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Vars 返回表达式引用的变量，按名称排序
+func (e *Expr) Vars() []string {
+	return e.vars
+}
+
+// Eval 使用给定的变量求值，缺少变量时返回ErrMissingVariable
+func (e *Expr) Eval(vars map[string]float64) (bool, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	return v.b, nil
+}
+
+// value 求值结果，数字或布尔值
+type value struct {
+	n float64
+	b bool
+}
+
+// node 语法树节点
+type node interface {
+	// eval 求值
+	eval(vars map[string]float64) (value, error)
+	// boolean 结果是否为布尔值，编译时用于类型检查
+	boolean() bool
+}
+
+// numberNode 数字常量
+type numberNode float64
+
+func (n numberNode) eval(map[string]float64) (value, error) {
+	return value{n: float64(n)}, nil
+}
+
+func (n numberNode) boolean() bool {
+	return false
+}
+
+// varNode 变量
+type varNode string
+
+func (n varNode) eval(vars map[string]float64) (value, error) {
+	v, ok := vars[string(n)]
+	if !ok {
+		return value{}, fmt.Errorf("%w: %s", ErrMissingVariable, string(n))
+	}
+	return value{n: v}, nil
+}
+
+func (n varNode) boolean() bool {
+	return false
+}
+
+// unaryNode 取负或逻辑非
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(vars map[string]float64) (value, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return value{}, err
+	}
+	if n.op == "!" {
+		return value{b: !v.b}, nil
+	}
+	return value{n: -v.n}, nil
+}
+
+func (n *unaryNode) boolean() bool {
+	return n.op == "!"
+}
+
+// binaryNode 二元运算
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(vars map[string]float64) (value, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return value{}, err
+	}
+	// 逻辑运算短路求值
+	switch n.op {
+	case "&&":
+		if !l.b {
+			return value{b: false}, nil
+		}
+		r, err := n.right.eval(vars)
+		return value{b: r.b}, err
+	case "||":
+		if l.b {
+			return value{b: true}, nil
+		}
+		r, err := n.right.eval(vars)
+		return value{b: r.b}, err
+	}
+
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return value{}, err
+	}
+	switch n.op {
+	case "+":
+		return value{n: l.n + r.n}, nil
+	case "-":
+		return value{n: l.n - r.n}, nil
+	case "*":
+		return value{n: l.n * r.n}, nil
+	case "/":
+		if r.n == 0 {
+			return value{}, fmt.Errorf("division by zero")
+		}
+		return value{n: l.n / r.n}, nil
+	case ">":
+		return value{b: l.n > r.n}, nil
+	case ">=":
+		return value{b: l.n >= r.n}, nil
+	case "<":
+		return value{b: l.n < r.n}, nil
+	case "<=":
+		return value{b: l.n <= r.n}, nil
+	case "==":
+		return value{b: l.n == r.n}, nil
+	case "!=":
+		return value{b: l.n != r.n}, nil
+	}
+	return value{}, fmt.Errorf("unknown operator %q", n.op)
+}
+
+func (n *binaryNode) boolean() bool {
+	switch n.op {
+	case "+", "-", "*", "/":
+		return false
+	}
+	return true
+}
+
+// token 词法单元
+type token struct {
+	kind int
+	text string
+	pos  int
+}
+
+// 词法单元类型
+const (
+	tokenNumber = iota
+	tokenIdent
+	tokenOperator
+)
+
+// keywords 逻辑运算关键字，不区分大小写
+var keywords = map[string]string{
+	"AND": "&&",
+	"OR":  "||",
+	"NOT": "!",
+}
+
+// tokenize 词法分析
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			start := i
+			for i < len(source) && (unicode.IsDigit(rune(source[i])) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:i], pos: start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(source) && (unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i])) || source[i] == '_') {
+				i++
+			}
+			word := source[start:i]
+			if op, ok := keywords[strings.ToUpper(word)]; ok {
+				tokens = append(tokens, token{kind: tokenOperator, text: op, pos: start})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: strings.ToLower(word), pos: start})
+			}
+		default:
+			if i+1 < len(source) {
+				switch two := source[i : i+2]; two {
+				case ">=", "<=", "==", "!=", "&&", "||":
+					tokens = append(tokens, token{kind: tokenOperator, text: two, pos: i})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("+-*/<>!()", c) {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: string(c), pos: i})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// parser 递归下降解析器，优先级从低到高: || && ! 比较 加减 乘除 取负
+type parser struct {
+	tokens []token
+	pos    int
+	vars   map[string]bool
+}
+
+// peek 当前运算符，不是运算符时返回空字符串
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOperator {
+		return p.tokens[p.pos].text
+	}
+	return ""
+}
+
+func (p *parser) parseOr(depth int) (node, error) {
+	return p.parseBinary(depth, []string{"||"}, p.parseAnd, true)
+}
+
+func (p *parser) parseAnd(depth int) (node, error) {
+	return p.parseBinary(depth, []string{"&&"}, p.parseNot, true)
+}
+
+func (p *parser) parseNot(depth int) (node, error) {
+	if p.peek() == "!" {
+		if depth > maxDepth {
+			return nil, fmt.Errorf("expression is nested too deeply")
+		}
+		p.pos++
+		operand, err := p.parseNot(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if !operand.boolean() {
+			return nil, fmt.Errorf("NOT requires a condition")
+		}
+		return &unaryNode{op: "!", operand: operand}, nil
+	}
+	return p.parseComparison(depth)
+}
+
+func (p *parser) parseComparison(depth int) (node, error) {
+	left, err := p.parseSum(depth)
+	if err != nil {
+		return nil, err
+	}
+	switch op := p.peek(); op {
+	case ">", ">=", "<", "<=", "==", "!=":
+		p.pos++
+		right, err := p.parseSum(depth)
+		if err != nil {
+			return nil, err
+		}
+		if left.boolean() || right.boolean() {
+			return nil, fmt.Errorf("operator %s requires numbers", op)
+		}
+		return &binaryNode{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseSum(depth int) (node, error) {
+	return p.parseBinary(depth, []string{"+", "-"}, p.parseTerm, false)
+}
+
+func (p *parser) parseTerm(depth int) (node, error) {
+	return p.parseBinary(depth, []string{"*", "/"}, p.parseUnary, false)
+}
+
+// parseBinary 解析左结合的二元运算，logical表示操作数必须是布尔值
+func (p *parser) parseBinary(depth int, ops []string, next func(int) (node, error), logical bool) (node, error) {
+	left, err := next(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if !containsOp(ops, op) {
+			return left, nil
+		}
+		p.pos++
+		right, err := next(depth)
+		if err != nil {
+			return nil, err
+		}
+		if left.boolean() != logical || right.boolean() != logical {
+			if logical {
+				return nil, fmt.Errorf("operator %s requires conditions", op)
+			}
+			return nil, fmt.Errorf("operator %s requires numbers", op)
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	if p.peek() == "-" {
+		if depth > maxDepth {
+			return nil, fmt.Errorf("expression is nested too deeply")
+		}
+		p.pos++
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if operand.boolean() {
+			return nil, fmt.Errorf("operator - requires a number")
+		}
+		return &unaryNode{op: "-", operand: operand}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case tokenNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return numberNode(n), nil
+	case tokenIdent:
+		p.vars[tok.text] = true
+		return varNode(tok.text), nil
+	}
+
+	if tok.text != "(" {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	if depth > maxDepth {
+		return nil, fmt.Errorf("expression is nested too deeply")
+	}
+	inner, err := p.parseOr(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != ")" {
+		return nil, fmt.Errorf("missing closing parenthesis for position %d", tok.pos)
+	}
+	p.pos++
+	return inner, nil
+}
+
+// containsOp 运算符是否在列表中
+func containsOp(ops []string, op string) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
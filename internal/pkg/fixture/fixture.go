@@ -0,0 +1,303 @@
+// Package fixture 外部API响应的录制与回放
+//
+// record模式下请求照常发往上游，响应(状态码、响应头和响应体)按提供方保存为JSON文件；
+// replay模式下不访问网络，按请求方法、路径、查询参数和请求体找到录制文件返回，未录制的请求返回ErrNotRecorded。
+// 查询参数中的API Key和签名不参与匹配，也不写入文件。
+package fixture
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"crypto-info/internal/config"
+)
+
+// 录制模式，与 external_api.fixtures.mode 一致
+const (
+	ModeRecord = "record"
+	ModeReplay = "replay"
+)
+
+// ErrNotRecorded 回放模式下请求没有对应的录制文件
+var ErrNotRecorded = errors.New("fixture: response not recorded")
+
+// secretParams 不参与匹配、也不写入录制文件的查询参数
+var secretParams = []string{"apikey", "api_key", "signature", "timestamp", "recvwindow", "x_cg_demo_api_key", "x_cg_pro_api_key"}
+
+// unsafeChars 文件名中替换为下划线的字符
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// Recorder 录制与回放器
+type Recorder struct {
+	mode      string
+	dir       string
+	providers map[string]bool // 为空时包括所有提供方
+}
+
+// Fixture 一次录制的请求和响应
+type Fixture struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"` // 已去除API Key和签名
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+var defaultRecorder atomic.Pointer[Recorder]
+
+// Init 按配置设置全局录制器，mode为空时清除
+func Init(cfg *config.Fixtures) {
+	if cfg.Mode == "" {
+		defaultRecorder.Store(nil)
+		return
+	}
+	defaultRecorder.Store(New(cfg))
+}
+
+// Default 全局录制器，未启用时为空
+func Default() *Recorder {
+	return defaultRecorder.Load()
+}
+
+// New 按配置创建录制器
+func New(cfg *config.Fixtures) *Recorder {
+	r := &Recorder{mode: cfg.Mode, dir: cfg.Dir}
+	if len(cfg.Providers) > 0 {
+		r.providers = make(map[string]bool, len(cfg.Providers))
+		for _, provider := range cfg.Providers {
+			r.providers[provider] = true
+		}
+	}
+	return r
+}
+
+// Mode 录制模式
+func (r *Recorder) Mode() string {
+	return r.mode
+}
+
+// Handles 是否录制或回放该提供方的请求，未命名提供方的请求不处理
+func (r *Recorder) Handles(provider string) bool {
+	if r == nil || provider == "" {
+		return false
+	}
+	return r.providers == nil || r.providers[provider]
+}
+
+// Transport 按全局录制器录制或回放provider的请求，未启用或不包括该提供方时直接发出
+func Transport(provider string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{provider: provider, base: base}
+}
+
+// transport 录制或回放的RoundTripper，每次请求读取全局录制器
+type transport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+// RoundTrip 实现http.RoundTripper接口
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := Default()
+	if !recorder.Handles(t.provider) {
+		return t.base.RoundTrip(req)
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	path := recorder.path(t.provider, req, body)
+
+	if recorder.mode == ModeReplay {
+		fixture, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		return fixture.response(req), nil
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	fixture := &Fixture{
+		Method: req.Method,
+		URL:    redactedURL(req.URL),
+		Status: resp.StatusCode,
+		Header: header,
+		Body:   string(respBody),
+	}
+	if err := save(path, fixture); err != nil {
+		return nil, fmt.Errorf("failed to record fixture %s: %w", path, err)
+	}
+	return resp, nil
+}
+
+// path 请求对应的录制文件路径：<dir>/<provider>/<method>_<path>_<hash>.json
+// hash由方法、路径、去除密钥后的查询参数和请求体计算，不包含域名，更换上游地址(如备用域名)后仍能回放
+func (r *Recorder) path(provider string, req *http.Request, body []byte) string {
+	u := *req.URL
+	u.Scheme, u.Host = "", ""
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + redactedURL(&u) + "\n"))
+	h.Write(body)
+	sum := hex.EncodeToString(h.Sum(nil))[:12]
+
+	name := strings.Trim(unsafeChars.ReplaceAllString(req.URL.Path, "_"), "_")
+	if name == "" {
+		name = "root"
+	}
+	return filepath.Join(r.dir, provider, fmt.Sprintf("%s_%s_%s.json", strings.ToLower(req.Method), name, sum))
+}
+
+// readBody 读取请求体用于匹配，并恢复请求体供后续发送
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// redactedURL 去除密钥参数并按参数名排序后的URL
+func redactedURL(u *url.URL) string {
+	query := u.Query()
+	for name := range query {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				query.Del(name)
+			}
+		}
+	}
+
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+// load 读取录制文件
+func load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotRecorded, path)
+		}
+		return nil, err
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// save 写入录制文件，先写临时文件再重命名，避免并发请求读到不完整的文件
+func save(path string, fixture *Fixture) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(fixture); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fixture-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// response 由录制内容构造响应
+func (f *Fixture) response(req *http.Request) *http.Response {
+	header := f.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}
+
+// Handler 以HTTP服务回放provider的录制响应，供符合性检查和离线演示使用，未录制的请求返回404
+func Handler(dir, provider string) http.Handler {
+	recorder := New(&config.Fixtures{Mode: ModeReplay, Dir: dir})
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := readBody(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		fixture, err := load(recorder.path(provider, req, body))
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrNotRecorded) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		for name, values := range fixture.Header {
+			if name == "Content-Length" {
+				continue
+			}
+			w.Header()[name] = values
+		}
+		w.WriteHeader(fixture.Status)
+		io.WriteString(w, fixture.Body)
+	})
+}
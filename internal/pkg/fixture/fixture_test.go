@@ -0,0 +1,138 @@
+package fixture
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crypto-info/internal/config"
+)
+
+// useRecorder 设置全局录制器，测试结束后清除
+func useRecorder(t *testing.T, cfg *config.Fixtures) {
+	t.Helper()
+	Init(cfg)
+	t.Cleanup(func() { Init(&config.Fixtures{}) })
+}
+
+// get 通过录制transport发送GET请求，返回状态码和响应体
+func get(t *testing.T, provider, rawURL string) (int, string, error) {
+	t.Helper()
+	client := &http.Client{Transport: Transport(provider, nil)}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// TestRecordThenReplay record模式保存的响应在replay模式下离线返回，密钥不写入文件也不参与匹配
+func TestRecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+	upstreamCalls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalls++
+		w.Header().Set("Content-Type", "application/json")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-cookie"})
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"symbol":"`+r.URL.Query().Get("symbol")+`","price":"42000.5"}`)
+	}))
+
+	useRecorder(t, &config.Fixtures{Mode: ModeRecord, Dir: dir})
+	status, body, err := get(t, "binance", upstream.URL+"/api/v3/ticker/price?symbol=BTCUSDT&apiKey=secret-key&signature=abc")
+	if err != nil {
+		t.Fatalf("record request failed: %v", err)
+	}
+	if status != http.StatusAccepted || body != `{"symbol":"BTCUSDT","price":"42000.5"}` {
+		t.Fatalf("record got %d %s, want upstream response", status, body)
+	}
+	if upstreamCalls != 1 {
+		t.Fatalf("upstream called %d times while recording, want 1", upstreamCalls)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "binance", "get_api_v3_ticker_price_*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("recorded files = %v (err %v), want one file", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	for _, secret := range []string{"secret-key", "signature", "secret-cookie"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("fixture contains %q:\n%s", secret, data)
+		}
+	}
+
+	// 关闭上游后回放，换用其他域名和API Key仍能命中录制文件
+	upstream.Close()
+	useRecorder(t, &config.Fixtures{Mode: ModeReplay, Dir: dir})
+	status, body, err = get(t, "binance", "https://api-backup.example.com/api/v3/ticker/price?signature=def&symbol=BTCUSDT&apiKey=other-key")
+	if err != nil {
+		t.Fatalf("replay request failed: %v", err)
+	}
+	if status != http.StatusAccepted || body != `{"symbol":"BTCUSDT","price":"42000.5"}` {
+		t.Errorf("replay got %d %s, want recorded response", status, body)
+	}
+	if upstreamCalls != 1 {
+		t.Errorf("upstream called %d times, replay must not hit the network", upstreamCalls)
+	}
+
+	// 未录制的请求不访问网络，返回ErrNotRecorded
+	_, _, err = get(t, "binance", "https://api.example.com/api/v3/ticker/price?symbol=ETHUSDT")
+	if !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unrecorded request error = %v, want ErrNotRecorded", err)
+	}
+
+	// 回放服务返回同一录制文件，未录制的请求返回404
+	server := httptest.NewServer(Handler(dir, "binance"))
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/api/v3/ticker/price?symbol=BTCUSDT")
+	if err != nil {
+		t.Fatalf("handler request failed: %v", err)
+	}
+	handlerBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || string(handlerBody) != body {
+		t.Errorf("handler got %d %s, want recorded response", resp.StatusCode, handlerBody)
+	}
+	resp, err = http.Get(server.URL + "/api/v3/ticker/price?symbol=ETHUSDT")
+	if err != nil {
+		t.Fatalf("handler request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("handler unrecorded status = %d, want 404", resp.StatusCode)
+	}
+}
+
+// TestProvidersFilter 只录制和回放配置中的提供方，其他提供方的请求直接发出
+func TestProvidersFilter(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "live")
+	}))
+	defer upstream.Close()
+
+	useRecorder(t, &config.Fixtures{Mode: ModeReplay, Dir: t.TempDir(), Providers: []string{"huobi"}})
+
+	if _, _, err := get(t, "huobi", upstream.URL+"/market/detail/merged?symbol=btcusdt"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("huobi error = %v, want ErrNotRecorded", err)
+	}
+	_, body, err := get(t, "binance", upstream.URL+"/api/v3/ticker/price?symbol=BTCUSDT")
+	if err != nil || body != "live" {
+		t.Errorf("binance got %q (err %v), want live upstream response", body, err)
+	}
+	if Default().Handles("") {
+		t.Error("requests without a provider name must not be recorded")
+	}
+}
@@ -0,0 +1,120 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/chaos"
+	"crypto-info/internal/pkg/fixture"
+)
+
+// defaultTimeout 默认请求超时时间
+const defaultTimeout = 15 * time.Second
+
+// maxErrorBody 错误响应体保留的最大长度
+const maxErrorBody = 512
+
+// userAgent 对外请求使用的User-Agent
+const userAgent = "crypto-info/1.0"
+
+// proxyDirect 不使用代理，忽略HTTP_PROXY等环境变量
+const proxyDirect = "direct"
+
+// errInvalidProxy 代理地址无效，不回显原值以免泄露代理账号密码
+var errInvalidProxy = errors.New("invalid proxy url")
+
+// Options HTTP客户端配置
+type Options struct {
+	Timeout  time.Duration // 请求超时时间
+	Provider string        // 外部提供方名称，设置后请求计入调用预算，见budget包
+	Proxy    string        // 代理地址(http、https、socks5)，为空时使用HTTP_PROXY等环境变量，direct表示直连
+}
+
+// StatusError 非2xx响应错误
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+// Error 实现error接口
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s: %s", e.StatusCode, e.URL, e.Body)
+}
+
+// New 创建访问外部API的HTTP客户端
+func New(opts Options) *http.Client {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: NewTransport(opts),
+	}
+}
+
+// NewTransport 创建带代理、DNS缓存和调用预算的Transport，供需要自行管理超时的客户端(如RPC)使用
+// 启用故障注入时请求在计入预算后、发出前注入故障；启用录制或回放时最内层录制响应或从录制文件返回
+func NewTransport(opts Options) http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 10
+	transport.Proxy = proxyFunc(opts.Proxy)
+	transport.DialContext = dialContext
+
+	rt := chaos.Transport(opts.Provider, fixture.Transport(opts.Provider, transport))
+	if opts.Provider != "" {
+		return budget.Transport(opts.Provider, rt)
+	}
+	return rt
+}
+
+// proxyFunc 按配置选择代理，地址无效时所有请求失败，避免绕过代理直连
+func proxyFunc(proxy string) func(*http.Request) (*url.URL, error) {
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment
+	case proxyDirect:
+		return nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return func(*http.Request) (*url.URL, error) { return nil, errInvalidProxy }
+	}
+	return http.ProxyURL(u)
+}
+
+// GetJSON 发送GET请求并将JSON响应解析到v
+func GetJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &StatusError{URL: url, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-info/internal/config"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// 上游连接默认配置，与http.DefaultTransport一致
+const (
+	defaultDialTimeout   = 30 * time.Second
+	defaultKeepAlive     = 30 * time.Second
+	defaultDNSCacheTTL   = time.Minute
+	defaultFallbackDelay = 300 * time.Millisecond
+)
+
+// baseDialer 未启用DNS缓存时使用的拨号器，自带按地址族的并行拨号
+var baseDialer = &net.Dialer{
+	Timeout:   defaultDialTimeout,
+	KeepAlive: defaultKeepAlive,
+}
+
+// activeDialer 启用DNS缓存后的拨号器，为空时使用baseDialer
+var activeDialer atomic.Pointer[cachingDialer]
+
+// ConfigureDNS 按配置启用或关闭上游域名的DNS缓存，对已创建的客户端同样生效
+func ConfigureDNS(cfg *config.DNSConfig) {
+	if cfg == nil || !cfg.Enabled {
+		activeDialer.Store(nil)
+		return
+	}
+
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultDNSCacheTTL
+	}
+	fallbackDelay := cfg.FallbackDelay
+	if fallbackDelay <= 0 {
+		fallbackDelay = defaultFallbackDelay
+	}
+	activeDialer.Store(&cachingDialer{
+		dialer:        baseDialer,
+		resolver:      net.DefaultResolver,
+		ttl:           ttl,
+		fallbackDelay: fallbackDelay,
+		entries:       make(map[string]dnsEntry),
+	})
+}
+
+// dialContext Transport使用的拨号函数，每次拨号时读取当前配置
+func dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if d := activeDialer.Load(); d != nil {
+		return d.DialContext(ctx, network, addr)
+	}
+	return baseDialer.DialContext(ctx, network, addr)
+}
+
+// cachingDialer 缓存DNS解析结果，并对IPv4/IPv6地址并行拨号(Happy Eyeballs)
+type cachingDialer struct {
+	dialer        *net.Dialer
+	resolver      *net.Resolver
+	ttl           time.Duration
+	fallbackDelay time.Duration
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+	group   singleflight.Group
+}
+
+// dnsEntry 单个域名的解析结果
+type dnsEntry struct {
+	addrs     []netip.Addr
+	expiresAt time.Time
+}
+
+// dialResult 单个地址族的拨号结果
+type dialResult struct {
+	conn net.Conn
+	err  error
+}
+
+// DialContext 解析域名后拨号，首选地址族在fallbackDelay内未连接成功时并行尝试另一地址族
+func (d *cachingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	primaries, fallbacks := partitionAddrs(addrs, network)
+	if len(primaries) == 0 {
+		return nil, &net.DNSError{Err: "no suitable address found", Name: host, IsNotFound: true}
+	}
+	if len(fallbacks) == 0 {
+		return d.dialSerial(ctx, network, primaries, port)
+	}
+	return d.dialParallel(ctx, network, primaries, fallbacks, port)
+}
+
+// dialParallel 先拨首选地址族，超过fallbackDelay或失败后启动另一地址族，返回最先成功的连接
+func (d *cachingDialer) dialParallel(ctx context.Context, network string, primaries, fallbacks []netip.Addr, port string) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult, 2)
+	start := func(addrs []netip.Addr) {
+		go func() {
+			conn, err := d.dialSerial(ctx, network, addrs, port)
+			results <- dialResult{conn: conn, err: err}
+		}()
+	}
+
+	start(primaries)
+	pending, fallbackStarted := 1, false
+	timer := time.NewTimer(d.fallbackDelay)
+	defer timer.Stop()
+
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			if !fallbackStarted {
+				start(fallbacks)
+				fallbackStarted = true
+				pending++
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				// 另一地址族可能随后也连接成功，关闭多余的连接
+				go closeLate(results, pending)
+				return r.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			if !fallbackStarted {
+				start(fallbacks)
+				fallbackStarted = true
+				pending++
+			} else if pending == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
+
+// closeLate 接收剩余的拨号结果并关闭连接
+func closeLate(results <-chan dialResult, pending int) {
+	for i := 0; i < pending; i++ {
+		if r := <-results; r.conn != nil {
+			r.conn.Close()
+		}
+	}
+}
+
+// dialSerial 依次拨号同一地址族的地址，返回第一个成功的连接
+func (d *cachingDialer) dialSerial(ctx context.Context, network string, addrs []netip.Addr, port string) (net.Conn, error) {
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// lookup 返回缓存的解析结果，过期后重新解析，解析失败时继续使用过期结果
+func (d *cachingDialer) lookup(ctx context.Context, host string) ([]netip.Addr, error) {
+	d.mu.Lock()
+	entry, ok := d.entries[host]
+	d.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.addrs, nil
+	}
+
+	// 同一域名的并发解析合并为一次，解析不随单个请求取消
+	ch := d.group.DoChan(host, func() (interface{}, error) {
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultDialTimeout)
+		defer cancel()
+		return d.resolver.LookupNetIP(lookupCtx, "ip", host)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		if r.Err != nil {
+			if ok {
+				return entry.addrs, nil
+			}
+			return nil, r.Err
+		}
+		addrs := r.Val.([]netip.Addr)
+		if len(addrs) == 0 {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		d.mu.Lock()
+		d.entries[host] = dnsEntry{addrs: addrs, expiresAt: time.Now().Add(d.ttl)}
+		d.mu.Unlock()
+		return addrs, nil
+	}
+}
+
+// partitionAddrs 按network过滤地址，并以第一个地址的地址族为首选拆分为两组
+func partitionAddrs(addrs []netip.Addr, network string) (primaries, fallbacks []netip.Addr) {
+	for _, addr := range addrs {
+		addr = addr.Unmap()
+		if (network == "tcp4" && !addr.Is4()) || (network == "tcp6" && addr.Is4()) {
+			continue
+		}
+		if len(primaries) == 0 || addr.Is4() == primaries[0].Is4() {
+			primaries = append(primaries, addr)
+		} else {
+			fallbacks = append(fallbacks, addr)
+		}
+	}
+	return primaries, fallbacks
+}
@@ -0,0 +1,78 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+)
+
+// ErrNonPublicAddress 目标地址不是公网地址
+var ErrNonPublicAddress = errors.New("destination is not a public address")
+
+// nonPublicPrefixes net/netip未归类为内网但同样不应访问的地址段
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"), // 运营商级NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF协议分配
+	netip.MustParsePrefix("198.18.0.0/15"), // 基准测试
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64，可映射到内网IPv4
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// IsPublicAddr 是否为可访问的公网单播地址
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewPublic 创建只访问公网地址的HTTP客户端，用于请求用户提供的URL(如用户webhook)
+//
+// 地址在DNS解析后、建立连接前检查，解析到内网、回环或链路本地地址的域名同样被拒绝；
+// 不跟随重定向，3xx响应原样返回；不使用代理，否则检查的是代理地址而不是目标地址。
+func NewPublic(opts Options) *http.Client {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: defaultKeepAlive,
+		Control:   publicOnlyControl,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 10
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicOnlyControl 拨号前检查解析后的目标地址
+func publicOnlyControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, address)
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+	}
+	return nil
+}
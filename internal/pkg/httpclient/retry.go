@@ -0,0 +1,135 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-info/internal/pkg/budget"
+)
+
+// 重试等待时间
+const (
+	defaultRetryInterval = time.Second      // 未配置retry_interval时首次重试前的等待时间
+	maxRetryInterval     = 30 * time.Second // 指数退避的单次等待上限
+)
+
+// Retrier 按APIConfig的retry_times和retry_interval重试外部API调用
+//
+// 第n次重试前等待interval*2^(n-1)，上限30秒，实际等待时间在其一半到全部之间随机，
+// 避免多个实例在上游恢复时同时重试。请求、重试和最终失败次数按数据源统计，见 AllRetryStats。
+type Retrier struct {
+	times     int
+	interval  time.Duration
+	retryable func(error) bool
+	counters  *retryCounters
+}
+
+// RetryStats 单个数据源的调用统计
+type RetryStats struct {
+	Source   string
+	Calls    uint64 // 调用次数，包含重试的一次调用计为1次
+	Attempts uint64 // 实际发出的请求次数
+	Retries  uint64 // 重试次数
+	Failures uint64 // 重试后仍失败的调用次数
+}
+
+// retryCounters 数据源的累计计数
+type retryCounters struct {
+	calls, attempts, retries, failures atomic.Uint64
+}
+
+// retryRegistry 进程内各数据源的计数，同一数据源的多个客户端共享
+var retryRegistry sync.Map // source -> *retryCounters
+
+// NewRetrier 创建重试器，times为失败后的最大重试次数，interval不大于0时使用1秒；retryable判断错误是否值得重试，为空时使用 Retryable
+func NewRetrier(source string, times int, interval time.Duration, retryable func(error) bool) *Retrier {
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+	if retryable == nil {
+		retryable = Retryable
+	}
+	counters, _ := retryRegistry.LoadOrStore(source, &retryCounters{})
+
+	return &Retrier{
+		times:     max(times, 0),
+		interval:  interval,
+		retryable: retryable,
+		counters:  counters.(*retryCounters),
+	}
+}
+
+// Do 执行fn，失败且错误可重试时退避后再次执行，直到成功、重试次数用完或ctx结束，返回最后一次的错误
+func (r *Retrier) Do(ctx context.Context, fn func() error) error {
+	r.counters.calls.Add(1)
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		r.counters.attempts.Add(1)
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		if attempt >= r.times || !r.retryable(err) {
+			r.counters.failures.Add(1)
+			return err
+		}
+
+		timer := time.NewTimer(r.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			r.counters.failures.Add(1)
+			return err
+		case <-timer.C:
+		}
+		r.counters.retries.Add(1)
+	}
+}
+
+// backoff 第attempt+1次重试前的等待时间
+func (r *Retrier) backoff(attempt int) time.Duration {
+	wait := r.interval
+	for i := 0; i < attempt && wait < maxRetryInterval; i++ {
+		wait *= 2
+	}
+	wait = min(wait, maxRetryInterval)
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// Retryable 默认的重试判断：超出调用预算、请求取消和4xx(限流除外)不重试，网络错误和5xx重试
+func Retryable(err error) bool {
+	if errors.Is(err, budget.ErrExceeded) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// AllRetryStats 返回各数据源的调用统计，按数据源名称排序
+func AllRetryStats() []RetryStats {
+	var stats []RetryStats
+	retryRegistry.Range(func(key, value any) bool {
+		counters := value.(*retryCounters)
+		stats = append(stats, RetryStats{
+			Source:   key.(string),
+			Calls:    counters.calls.Load(),
+			Attempts: counters.attempts.Load(),
+			Retries:  counters.retries.Load(),
+			Failures: counters.failures.Load(),
+		})
+		return true
+	})
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Source < stats[j].Source })
+	return stats
+}
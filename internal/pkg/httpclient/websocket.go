@@ -0,0 +1,23 @@
+package httpclient
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// defaultHandshakeTimeout WebSocket握手默认超时时间
+const defaultHandshakeTimeout = 45 * time.Second
+
+// WebsocketDialer 创建与HTTP客户端代理规则一致的WebSocket拨号器，Timeout为握手超时，Provider不生效
+func WebsocketDialer(opts Options) websocket.Dialer {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultHandshakeTimeout
+	}
+
+	return websocket.Dialer{
+		Proxy:            proxyFunc(opts.Proxy),
+		HandshakeTimeout: timeout,
+	}
+}
@@ -0,0 +1,137 @@
+// Package huobi 火币现货行情REST API客户端
+package huobi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+)
+
+// defaultBaseURL 火币API默认地址
+const defaultBaseURL = "https://api.huobi.pro"
+
+// errCodeInvalidParameter 参数错误的错误码，交易对不存在时返回
+const errCodeInvalidParameter = "invalid-parameter"
+
+// ErrInvalidSymbol 交易对不存在
+var ErrInvalidSymbol = errors.New("huobi: invalid symbol")
+
+// Client 火币API客户端
+type Client struct {
+	baseURL    string
+	retrier    *httpclient.Retrier
+	httpClient *http.Client
+}
+
+// Tick 交易对聚合行情
+type Tick struct {
+	Close  float64 `json:"close"`  // 最新成交价
+	Open   float64 `json:"open"`   // 24小时前开盘价
+	High   float64 `json:"high"`   // 24小时最高价
+	Low    float64 `json:"low"`    // 24小时最低价
+	Amount float64 `json:"amount"` // 24小时成交量
+	Vol    float64 `json:"vol"`    // 24小时成交额
+}
+
+// Kline K线
+type Kline struct {
+	ID     int64   `json:"id"` // 开盘时间(Unix秒)
+	Open   float64 `json:"open"`
+	Close  float64 `json:"close"`
+	Low    float64 `json:"low"`
+	High   float64 `json:"high"`
+	Amount float64 `json:"amount"` // 成交量
+	Vol    float64 `json:"vol"`    // 成交额
+}
+
+// response 火币通用响应，data或tick字段由调用方解析
+type response struct {
+	Status  string          `json:"status"`
+	ErrCode string          `json:"err-code"`
+	ErrMsg  string          `json:"err-msg"`
+	Tick    json.RawMessage `json:"tick"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// NewClient 创建火币客户端，按配置的地址、超时、代理和重试次数访问API
+func NewClient(cfg *config.APIConfig) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		retrier:    httpclient.NewRetrier(budget.ProviderHuobi, cfg.RetryTimes, cfg.RetryInterval, nil),
+		httpClient: httpclient.New(httpclient.Options{Timeout: cfg.Timeout, Provider: budget.ProviderHuobi, Proxy: cfg.Proxy}),
+	}
+}
+
+// GetMerged 获取交易对(如btcusdt)的聚合行情
+func (c *Client) GetMerged(ctx context.Context, pair string) (*Tick, error) {
+	params := url.Values{}
+	params.Set("symbol", strings.ToLower(pair))
+
+	var resp response
+	if err := c.get(ctx, "/market/detail/merged", params, &resp); err != nil {
+		return nil, err
+	}
+
+	var tick Tick
+	if err := json.Unmarshal(resp.Tick, &tick); err != nil {
+		return nil, fmt.Errorf("invalid huobi tick for %s: %w", pair, err)
+	}
+	return &tick, nil
+}
+
+// GetKlines 获取交易对最近size根K线，period为火币K线周期(如1day)，按时间倒序
+func (c *Client) GetKlines(ctx context.Context, pair, period string, size int) ([]Kline, error) {
+	params := url.Values{}
+	params.Set("symbol", strings.ToLower(pair))
+	params.Set("period", period)
+	params.Set("size", strconv.Itoa(size))
+
+	var resp response
+	if err := c.get(ctx, "/market/history/kline", params, &resp); err != nil {
+		return nil, err
+	}
+
+	var klines []Kline
+	if err := json.Unmarshal(resp.Data, &klines); err != nil {
+		return nil, fmt.Errorf("invalid huobi klines for %s: %w", pair, err)
+	}
+	return klines, nil
+}
+
+// get 发送GET请求并检查响应状态，网络错误、限流和5xx响应按配置的次数指数退避重试，业务错误不重试
+func (c *Client) get(ctx context.Context, path string, params url.Values, resp *response) error {
+	endpoint := c.baseURL + path + "?" + params.Encode()
+
+	if err := c.retrier.Do(ctx, func() error {
+		return httpclient.GetJSON(ctx, c.httpClient, endpoint, resp)
+	}); err != nil {
+		return err
+	}
+	return checkStatus(resp)
+}
+
+// checkStatus 检查响应状态，交易对不存在时返回ErrInvalidSymbol
+// 火币的业务错误以HTTP 200和status为error返回
+func checkStatus(resp *response) error {
+	if resp.Status == "ok" {
+		return nil
+	}
+	if resp.ErrCode == errCodeInvalidParameter && strings.Contains(strings.ToLower(resp.ErrMsg), "symbol") {
+		return fmt.Errorf("%w: %s", ErrInvalidSymbol, resp.ErrMsg)
+	}
+	return fmt.Errorf("huobi error: %s %s", resp.ErrCode, resp.ErrMsg)
+}
@@ -0,0 +1,161 @@
+package huobi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/provider"
+)
+
+// providerName 数据源名称
+const providerName = "huobi"
+
+// QuoteAsset 价格和成交额使用的计价币种
+const QuoteAsset = "USDT"
+
+// maxKlineSize 单次请求的最大K线数量
+const maxKlineSize = 2000
+
+// validSymbol 币种名称只包含字母和数字
+var validSymbol = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// Provider 火币价格和交易量数据源，币种以对USDT的小写交易对(如btcusdt)查询
+type Provider struct {
+	client *Client
+}
+
+// NewProvider 创建火币数据源
+func NewProvider(client *Client) *Provider {
+	return &Provider{client: client}
+}
+
+// Name 实现provider.PriceProvider接口
+func (p *Provider) Name() string {
+	return providerName
+}
+
+// GetPrice 实现provider.PriceProvider接口
+func (p *Provider) GetPrice(ctx context.Context, symbol string) (*provider.Quote, error) {
+	pair, symbol, err := p.pair(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	tick, err := p.client.GetMerged(ctx, pair)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	if tick.Close <= 0 {
+		return nil, fmt.Errorf("%w: invalid huobi price %v for %s", provider.ErrUnavailable, tick.Close, pair)
+	}
+	return &provider.Quote{
+		Symbol:   symbol,
+		Price:    tick.Close,
+		Currency: QuoteAsset,
+		Time:     time.Now(),
+		Stats:    &provider.Stats24h{Open: tick.Open, High: tick.High, Low: tick.Low, Volume: tick.Amount, QuoteVolume: tick.Vol},
+	}, nil
+}
+
+// GetDailyVolumes 实现provider.VolumeProvider接口，火币按时间倒序返回，转换为升序
+func (p *Provider) GetDailyVolumes(ctx context.Context, symbol string, days int) ([]provider.DailyVolume, error) {
+	pair, _, err := p.pair(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 || days > maxKlineSize {
+		return nil, fmt.Errorf("invalid days %d, must be between 1 and %d", days, maxKlineSize)
+	}
+
+	klines, err := p.client.GetKlines(ctx, pair, "1day", days)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	volumes := make([]provider.DailyVolume, len(klines))
+	for i, kline := range klines {
+		volumes[i] = provider.DailyVolume{Date: time.Unix(kline.ID, 0).UTC(), Volume: kline.Amount, QuoteVolume: kline.Vol}
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Date.Before(volumes[j].Date) })
+	return volumes, nil
+}
+
+// klinePeriods 支持的K线周期对应的火币周期代码
+var klinePeriods = map[time.Duration]string{
+	time.Minute:        "1min",
+	5 * time.Minute:    "5min",
+	15 * time.Minute:   "15min",
+	30 * time.Minute:   "30min",
+	time.Hour:          "60min",
+	4 * time.Hour:      "4hour",
+	24 * time.Hour:     "1day",
+	7 * 24 * time.Hour: "1week",
+}
+
+// GetCandles 实现provider.CandleProvider接口，火币按时间倒序返回，转换为升序
+func (p *Provider) GetCandles(ctx context.Context, symbol string, interval time.Duration, limit int) ([]provider.Candle, error) {
+	pair, _, err := p.pair(symbol)
+	if err != nil {
+		return nil, err
+	}
+	period, ok := klinePeriods[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported interval %s", interval)
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+	limit = min(limit, maxKlineSize)
+
+	klines, err := p.client.GetKlines(ctx, pair, period, limit)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	candles := make([]provider.Candle, len(klines))
+	for i, kline := range klines {
+		candles[i] = provider.Candle{
+			OpenTime:    time.Unix(kline.ID, 0).UTC(),
+			Open:        kline.Open,
+			High:        kline.High,
+			Low:         kline.Low,
+			Close:       kline.Close,
+			Volume:      kline.Amount,
+			QuoteVolume: kline.Vol,
+		}
+	}
+	sort.Slice(candles, func(i, j int) bool { return candles[i].OpenTime.Before(candles[j].OpenTime) })
+	return candles, nil
+}
+
+// pair 将币种转换为对USDT的小写交易对，返回交易对和统一后的币种
+func (p *Provider) pair(symbol string) (string, string, error) {
+	symbol = provider.NormalizeSymbol(symbol)
+	if !validSymbol.MatchString(symbol) || symbol == QuoteAsset {
+		return "", "", fmt.Errorf("%w: %s", provider.ErrUnsupportedSymbol, symbol)
+	}
+	return strings.ToLower(symbol + QuoteAsset), symbol, nil
+}
+
+// typedError 将客户端错误归类为provider包的错误，ctx的取消和超时原样返回
+func typedError(err error) error {
+	var statusErr *httpclient.StatusError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, ErrInvalidSymbol):
+		return fmt.Errorf("%w: %w", provider.ErrUnsupportedSymbol, err)
+	case errors.Is(err, budget.ErrExceeded):
+		return fmt.Errorf("%w: %w", provider.ErrRateLimited, err)
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", provider.ErrRateLimited, err)
+	default:
+		return fmt.Errorf("%w: %w", provider.ErrUnavailable, err)
+	}
+}
@@ -0,0 +1,30 @@
+package importer
+
+import "crypto-info/internal/model"
+
+// binanceParser 币安现货交易历史导出解析器
+//
+// 支持新版格式: Date(UTC),Pair,Side,Price,Executed,Amount,Fee
+// 以及旧版格式: Date(UTC),Market,Type,Price,Amount,Total,Fee,Fee Coin
+type binanceParser struct{}
+
+// Name 格式名称
+func (p *binanceParser) Name() string {
+	return "binance"
+}
+
+// Detect 根据表头判断是否为币安导出格式
+func (p *binanceParser) Detect(header []string) bool {
+	return hasHeaders(header, "Date(UTC)", "Pair", "Side", "Price", "Executed") ||
+		hasHeaders(header, "Date(UTC)", "Market", "Type", "Price", "Amount", "Fee Coin")
+}
+
+// ParseRow 解析单行记录
+func (p *binanceParser) ParseRow(row Row) (model.Trade, []string, error) {
+	if pair := row.Get("Pair"); pair != "" {
+		return buildTrade(pair, row.Get("Side"), row.Get("Date(UTC)"),
+			row.Get("Price"), row.Get("Executed"), row.Get("Fee"), "")
+	}
+	return buildTrade(row.Get("Market"), row.Get("Type"), row.Get("Date(UTC)"),
+		row.Get("Price"), row.Get("Amount"), row.Get("Fee"), row.Get("Fee Coin"))
+}
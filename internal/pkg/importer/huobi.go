@@ -0,0 +1,24 @@
+package importer
+
+import "crypto-info/internal/model"
+
+// huobiParser 火币现货成交记录导出解析器
+//
+// 格式: Time,Pair,Side,Price,Amount,Total,Fee,Fee Currency
+type huobiParser struct{}
+
+// Name 格式名称
+func (p *huobiParser) Name() string {
+	return "huobi"
+}
+
+// Detect 根据表头判断是否为火币导出格式
+func (p *huobiParser) Detect(header []string) bool {
+	return hasHeaders(header, "Time", "Pair", "Side", "Price", "Amount", "Fee")
+}
+
+// ParseRow 解析单行记录
+func (p *huobiParser) ParseRow(row Row) (model.Trade, []string, error) {
+	return buildTrade(row.Get("Pair"), row.Get("Side"), row.Get("Time"),
+		row.Get("Price"), row.Get("Amount"), row.Get("Fee"), row.Get("Fee Currency", "Fee Coin"))
+}
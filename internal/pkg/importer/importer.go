@@ -0,0 +1,198 @@
+package importer
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"crypto-info/internal/model"
+)
+
+// ErrUnknownFormat 无法识别的文件格式
+var ErrUnknownFormat = errors.New("unknown trade export format")
+
+// utf8BOM Excel等工具导出CSV时可能带有的BOM
+const utf8BOM = "\ufeff"
+
+// Parser 交易所导出文件解析器
+type Parser interface {
+	// Name 格式名称
+	Name() string
+	// Detect 根据表头判断是否为该格式
+	Detect(header []string) bool
+	// ParseRow 解析单行记录，返回标准化的交易及警告信息
+	ParseRow(row Row) (model.Trade, []string, error)
+}
+
+// Row CSV行，按表头名称访问字段
+type Row struct {
+	index  map[string]int
+	record []string
+}
+
+// Get 获取字段值，表头名称不区分大小写
+func (r Row) Get(names ...string) string {
+	for _, name := range names {
+		if i, ok := r.index[normalizeHeader(name)]; ok && i < len(r.record) {
+			return strings.TrimSpace(r.record[i])
+		}
+	}
+	return ""
+}
+
+// RowError 行级错误
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// Result 解析结果
+type Result struct {
+	Format   string        `json:"format"`
+	Rows     int           `json:"rows"`
+	Trades   []model.Trade `json:"-"`
+	Errors   []RowError    `json:"errors"`
+	Warnings []RowError    `json:"warnings"`
+}
+
+var parsers = []Parser{
+	&binanceParser{},
+	&huobiParser{},
+}
+
+// Formats 返回支持的格式名称
+func Formats() []string {
+	names := make([]string, 0, len(parsers))
+	for _, p := range parsers {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+// Parse 解析交易所导出的CSV，format为空时根据表头自动识别
+func Parse(r io.Reader, format string) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	parser, err := selectParser(header, format)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[normalizeHeader(name)] = i
+	}
+
+	result := &Result{
+		Format:   parser.Name(),
+		Trades:   make([]model.Trade, 0),
+		Errors:   make([]RowError, 0),
+		Warnings: make([]RowError, 0),
+	}
+
+	// 表头为第1行
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Row: line, Error: err.Error()})
+			continue
+		}
+		if isBlank(record) {
+			continue
+		}
+		result.Rows++
+
+		trade, warnings, err := parser.ParseRow(Row{index: index, record: record})
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Row: line, Error: err.Error()})
+			continue
+		}
+		for _, w := range warnings {
+			result.Warnings = append(result.Warnings, RowError{Row: line, Error: w})
+		}
+
+		trade.Exchange = parser.Name()
+		if trade.ID == "" {
+			trade.ID = TradeID(parser.Name(), record)
+		}
+		result.Trades = append(result.Trades, trade)
+	}
+
+	return result, nil
+}
+
+// TradeID 根据格式和原始行内容生成稳定的交易ID，用于重复导入去重
+func TradeID(format string, record []string) string {
+	h := sha1.New()
+	h.Write([]byte(format))
+	for _, field := range record {
+		h.Write([]byte{0})
+		h.Write([]byte(strings.TrimSpace(field)))
+	}
+	return format + "-" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// selectParser 选择解析器
+func selectParser(header []string, format string) (Parser, error) {
+	if format != "" {
+		for _, p := range parsers {
+			if strings.EqualFold(p.Name(), format) {
+				if !p.Detect(header) {
+					return nil, fmt.Errorf("%w: header does not match %s export", ErrUnknownFormat, p.Name())
+				}
+				return p, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+
+	for _, p := range parsers {
+		if p.Detect(header) {
+			return p, nil
+		}
+	}
+	return nil, ErrUnknownFormat
+}
+
+// hasHeaders 判断表头是否包含所有字段
+func hasHeaders(header []string, names ...string) bool {
+	set := make(map[string]bool, len(header))
+	for _, h := range header {
+		set[normalizeHeader(h)] = true
+	}
+	for _, name := range names {
+		if !set[normalizeHeader(name)] {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeHeader 标准化表头名称
+func normalizeHeader(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, utf8BOM)))
+}
+
+// isBlank 判断是否为空行
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
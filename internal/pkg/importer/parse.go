@@ -0,0 +1,148 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"crypto-info/internal/model"
+)
+
+// quoteAssets 常见计价货币，按长度降序匹配
+var quoteAssets = []string{"FDUSD", "USDT", "BUSD", "USDC", "HUSD", "TUSD", "USD", "BTC", "ETH", "BNB", "HT"}
+
+// timeLayouts 支持的时间格式
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006/01/02 15:04:05",
+	"2006-01-02 15:04",
+	"01/02/2006 15:04:05",
+}
+
+// splitPair 将交易对拆分为基础货币和计价货币
+func splitPair(pair string) (base, quote string, err error) {
+	pair = strings.ToUpper(strings.TrimSpace(pair))
+	for _, sep := range []string{"/", "-", "_"} {
+		if parts := strings.Split(pair, sep); len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+			return parts[0], parts[1], nil
+		}
+	}
+	for _, q := range quoteAssets {
+		if strings.HasSuffix(pair, q) && len(pair) > len(q) {
+			return strings.TrimSuffix(pair, q), q, nil
+		}
+	}
+	return "", "", fmt.Errorf("unrecognized trading pair %q", pair)
+}
+
+// parseSide 解析交易方向
+func parseSide(side string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(side)) {
+	case "buy", "买入":
+		return model.TradeSideBuy, nil
+	case "sell", "卖出":
+		return model.TradeSideSell, nil
+	default:
+		return "", fmt.Errorf("unrecognized side %q", side)
+	}
+}
+
+// parseTime 解析成交时间，无时区信息时按UTC处理
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if ms > 1e12 {
+			return time.UnixMilli(ms).UTC(), nil
+		}
+		return time.Unix(ms, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", value)
+}
+
+// parseAmount 解析带可选币种后缀的数量，如"0.001BTC"
+func parseAmount(value string) (float64, string, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+	if value == "" {
+		return 0, "", nil
+	}
+	end := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.' && r != '-' && r != 'e' && r != 'E' && r != '+'
+	})
+	number, unit := value, ""
+	if end >= 0 {
+		number, unit = value[:end], strings.ToUpper(strings.TrimSpace(value[end:]))
+	}
+	amount, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, unit, nil
+}
+
+// buildTrade 根据解析出的字段构造标准化交易，手续费统一换算为计价货币
+func buildTrade(pair, side, timestamp, price, quantity, fee, feeAsset string) (model.Trade, []string, error) {
+	var warnings []string
+
+	base, quote, err := splitPair(pair)
+	if err != nil {
+		return model.Trade{}, nil, err
+	}
+	tradeSide, err := parseSide(side)
+	if err != nil {
+		return model.Trade{}, nil, err
+	}
+	ts, err := parseTime(timestamp)
+	if err != nil {
+		return model.Trade{}, nil, err
+	}
+	unitPrice, _, err := parseAmount(price)
+	if err != nil {
+		return model.Trade{}, nil, err
+	}
+	qty, _, err := parseAmount(quantity)
+	if err != nil {
+		return model.Trade{}, nil, err
+	}
+	if qty <= 0 {
+		return model.Trade{}, nil, fmt.Errorf("quantity must be positive, got %q", quantity)
+	}
+	if unitPrice < 0 {
+		return model.Trade{}, nil, fmt.Errorf("price must not be negative, got %q", price)
+	}
+
+	feeAmount, feeUnit, err := parseAmount(fee)
+	if err != nil {
+		return model.Trade{}, nil, err
+	}
+	if feeAsset != "" {
+		feeUnit = strings.ToUpper(strings.TrimSpace(feeAsset))
+	}
+
+	var quoteFee float64
+	switch {
+	case feeAmount == 0:
+	case feeUnit == "" || feeUnit == quote:
+		quoteFee = feeAmount
+	case feeUnit == base:
+		quoteFee = feeAmount * unitPrice
+	default:
+		warnings = append(warnings, fmt.Sprintf("fee %g %s cannot be converted to %s and was ignored", feeAmount, feeUnit, quote))
+	}
+
+	return model.Trade{
+		Symbol:    base,
+		Side:      tradeSide,
+		Quantity:  qty,
+		Price:     unitPrice,
+		Fee:       quoteFee,
+		Timestamp: ts,
+	}, warnings, nil
+}
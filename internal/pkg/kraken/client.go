@@ -0,0 +1,228 @@
+// Package kraken Kraken公开行情REST API客户端
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+)
+
+// defaultBaseURL Kraken API默认地址
+const defaultBaseURL = "https://api.kraken.com"
+
+var (
+	// ErrInvalidPair 交易对不存在
+	ErrInvalidPair = errors.New("kraken: unknown asset pair")
+	// ErrRateLimited 触发Kraken限流
+	ErrRateLimited = errors.New("kraken: rate limited")
+)
+
+// Client Kraken API客户端
+type Client struct {
+	baseURL    string
+	retrier    *httpclient.Retrier
+	httpClient *http.Client
+}
+
+// Ticker 交易对最新成交价和24小时统计
+type Ticker struct {
+	Last      float64 // 最新成交价
+	Open      float64 // 当日(UTC)开盘价
+	High24h   float64 // 24小时最高价
+	Low24h    float64 // 24小时最低价
+	Volume24h float64 // 24小时成交量(交易货币)
+	VWAP24h   float64 // 24小时成交量加权均价
+}
+
+// OHLC K线
+type OHLC struct {
+	Time   time.Time // 开盘时间
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	VWAP   float64 // 成交量加权均价
+	Volume float64 // 成交量(交易货币)
+}
+
+// response Kraken通用响应，业务错误在error数组中返回，HTTP状态码为200
+type response struct {
+	Error  []string                   `json:"error"`
+	Result map[string]json.RawMessage `json:"result"`
+}
+
+// ticker Kraken行情响应，数值均为字符串，数组第二项为24小时统计
+type ticker struct {
+	Close  []string `json:"c"` // [价格, 成交量]
+	Volume []string `json:"v"` // [今日, 24小时]
+	VWAP   []string `json:"p"` // [今日, 24小时]
+	Low    []string `json:"l"` // [今日, 24小时]
+	High   []string `json:"h"` // [今日, 24小时]
+	Open   string   `json:"o"`
+}
+
+// NewClient 创建Kraken客户端，按配置的地址、超时、代理和重试次数访问API
+func NewClient(cfg *config.APIConfig) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		retrier:    httpclient.NewRetrier(budget.ProviderKraken, cfg.RetryTimes, cfg.RetryInterval, retryable),
+		httpClient: httpclient.New(httpclient.Options{Timeout: cfg.Timeout, Provider: budget.ProviderKraken, Proxy: cfg.Proxy}),
+	}
+}
+
+// GetTicker 获取交易对(如XBTUSD)的最新成交价和24小时统计
+func (c *Client) GetTicker(ctx context.Context, pair string) (*Ticker, error) {
+	params := url.Values{}
+	params.Set("pair", strings.ToUpper(pair))
+
+	var raw json.RawMessage
+	if err := c.get(ctx, "/0/public/Ticker", params, &raw); err != nil {
+		return nil, err
+	}
+	var t ticker
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("invalid kraken ticker for %s: %w", pair, err)
+	}
+	if len(t.Close) < 1 || len(t.Volume) < 2 || len(t.VWAP) < 2 || len(t.Low) < 2 || len(t.High) < 2 {
+		return nil, fmt.Errorf("invalid kraken ticker for %s: missing fields", pair)
+	}
+
+	values, err := parseNumbers(t.Close[0], t.Open, t.High[1], t.Low[1], t.Volume[1], t.VWAP[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid kraken ticker for %s: %w", pair, err)
+	}
+	return &Ticker{
+		Last:      values[0],
+		Open:      values[1],
+		High24h:   values[2],
+		Low24h:    values[3],
+		Volume24h: values[4],
+		VWAP24h:   values[5],
+	}, nil
+}
+
+// GetOHLC 获取交易对最近的K线(最多720根)，interval为K线周期，按时间升序
+func (c *Client) GetOHLC(ctx context.Context, pair string, interval time.Duration) ([]OHLC, error) {
+	params := url.Values{}
+	params.Set("pair", strings.ToUpper(pair))
+	params.Set("interval", strconv.Itoa(int(interval.Minutes())))
+
+	var rows [][]interface{}
+	if err := c.get(ctx, "/0/public/OHLC", params, &rows); err != nil {
+		return nil, err
+	}
+
+	candles := make([]OHLC, 0, len(rows))
+	for _, row := range rows {
+		// [开盘时间(秒), 开, 高, 低, 收, 加权均价, 成交量, 成交笔数]，价格和数量为字符串
+		if len(row) < 7 {
+			return nil, fmt.Errorf("invalid kraken ohlc for %s: expected at least 7 fields, got %d", pair, len(row))
+		}
+		openTime, ok := row[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid kraken ohlc for %s: invalid time %v", pair, row[0])
+		}
+		fields := make([]string, 6)
+		for i := range fields {
+			s, ok := row[i+1].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid kraken ohlc for %s: expected numeric string, got %v", pair, row[i+1])
+			}
+			fields[i] = s
+		}
+		values, err := parseNumbers(fields...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kraken ohlc for %s: %w", pair, err)
+		}
+		candles = append(candles, OHLC{
+			Time:   time.Unix(int64(openTime), 0).UTC(),
+			Open:   values[0],
+			High:   values[1],
+			Low:    values[2],
+			Close:  values[3],
+			VWAP:   values[4],
+			Volume: values[5],
+		})
+	}
+	return candles, nil
+}
+
+// parseNumbers 解析以字符串表示的数值
+func parseNumbers(values ...string) ([]float64, error) {
+	numbers := make([]float64, len(values))
+	for i, v := range values {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// get 发送GET请求并解析result中交易对对应的数据，网络错误、限流和5xx响应按配置的次数指数退避重试
+func (c *Client) get(ctx context.Context, path string, params url.Values, result interface{}) error {
+	endpoint := c.baseURL + path + "?" + params.Encode()
+
+	var resp response
+	err := c.retrier.Do(ctx, func() error {
+		resp = response{}
+		if err := httpclient.GetJSON(ctx, c.httpClient, endpoint, &resp); err != nil {
+			return err
+		}
+		return checkErrors(resp.Error)
+	})
+	if err != nil {
+		return err
+	}
+	return decodeResult(resp.Result, result)
+}
+
+// decodeResult 解析result中唯一的交易对数据，Kraken以规范名称(如XXBTZUSD)作为key，OHLC的last字段忽略
+func decodeResult(result map[string]json.RawMessage, v interface{}) error {
+	for key, data := range result {
+		if key == "last" {
+			continue
+		}
+		return json.Unmarshal(data, v)
+	}
+	return fmt.Errorf("%w: empty result", ErrInvalidPair)
+}
+
+// checkErrors 检查Kraken业务错误，如 EQuery:Unknown asset pair
+func checkErrors(errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	msg := strings.Join(errs, "; ")
+	switch {
+	case strings.Contains(msg, "Unknown asset pair"):
+		return fmt.Errorf("%w: %s", ErrInvalidPair, msg)
+	case strings.Contains(msg, "Rate limit exceeded"), strings.Contains(msg, "Too many requests"):
+		return fmt.Errorf("%w: %s", ErrRateLimited, msg)
+	}
+	return fmt.Errorf("kraken error: %s", msg)
+}
+
+// retryable 是否值得重试，交易对不存在时不重试，业务错误表示的限流重试，其余按httpclient.Retryable判断
+func retryable(err error) bool {
+	if errors.Is(err, ErrInvalidPair) {
+		return false
+	}
+	return errors.Is(err, ErrRateLimited) || httpclient.Retryable(err)
+}
@@ -0,0 +1,131 @@
+package kraken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/provider"
+)
+
+// providerName 数据源名称
+const providerName = "kraken"
+
+// QuoteAsset 价格和成交额使用的计价币种，Kraken现货以美元计价
+const QuoteAsset = "USD"
+
+// maxOHLC 单次请求返回的最大K线数量
+const maxOHLC = 720
+
+// validSymbol 币种名称只包含字母和数字
+var validSymbol = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// assetAliases Kraken交易对中与通用名称不同的币种代码
+var assetAliases = map[string]string{
+	"BTC":  "XBT",
+	"DOGE": "XDG",
+}
+
+// Provider Kraken价格和交易量数据源，币种以对美元的交易对(如XBTUSD)查询
+type Provider struct {
+	client *Client
+}
+
+// NewProvider 创建Kraken数据源
+func NewProvider(client *Client) *Provider {
+	return &Provider{client: client}
+}
+
+// Name 实现provider.PriceProvider接口
+func (p *Provider) Name() string {
+	return providerName
+}
+
+// GetPrice 实现provider.PriceProvider接口，Kraken行情不带时间，以获取时间作为价格时间；
+// Kraken不提供24小时前的开盘价，统计中的开盘价为当日(UTC)开盘价，成交额为成交量乘以加权均价
+func (p *Provider) GetPrice(ctx context.Context, symbol string) (*provider.Quote, error) {
+	pair, symbol, err := p.pair(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker, err := p.client.GetTicker(ctx, pair)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	if ticker.Last <= 0 {
+		return nil, fmt.Errorf("%w: invalid kraken price %v for %s", provider.ErrUnavailable, ticker.Last, pair)
+	}
+	return &provider.Quote{
+		Symbol:   symbol,
+		Price:    ticker.Last,
+		Currency: QuoteAsset,
+		Time:     time.Now(),
+		Stats: &provider.Stats24h{
+			Open:        ticker.Open,
+			High:        ticker.High24h,
+			Low:         ticker.Low24h,
+			Volume:      ticker.Volume24h,
+			QuoteVolume: ticker.Volume24h * ticker.VWAP24h,
+		},
+	}, nil
+}
+
+// GetDailyVolumes 实现provider.VolumeProvider接口，按UTC自然日统计，成交额为成交量乘以加权均价
+func (p *Provider) GetDailyVolumes(ctx context.Context, symbol string, days int) ([]provider.DailyVolume, error) {
+	pair, _, err := p.pair(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 || days > maxOHLC {
+		return nil, fmt.Errorf("invalid days %d, must be between 1 and %d", days, maxOHLC)
+	}
+
+	candles, err := p.client.GetOHLC(ctx, pair, 24*time.Hour)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	if len(candles) > days {
+		candles = candles[len(candles)-days:]
+	}
+	volumes := make([]provider.DailyVolume, len(candles))
+	for i, candle := range candles {
+		volumes[i] = provider.DailyVolume{Date: candle.Time, Volume: candle.Volume, QuoteVolume: candle.Volume * candle.VWAP}
+	}
+	return volumes, nil
+}
+
+// pair 将币种转换为对美元的交易对，返回交易对和统一后的币种
+func (p *Provider) pair(symbol string) (string, string, error) {
+	symbol = provider.NormalizeSymbol(symbol)
+	if !validSymbol.MatchString(symbol) || symbol == QuoteAsset {
+		return "", "", fmt.Errorf("%w: %s", provider.ErrUnsupportedSymbol, symbol)
+	}
+	asset := symbol
+	if alias, ok := assetAliases[symbol]; ok {
+		asset = alias
+	}
+	return asset + QuoteAsset, symbol, nil
+}
+
+// typedError 将客户端错误归类为provider包的错误，ctx的取消和超时原样返回
+func typedError(err error) error {
+	var statusErr *httpclient.StatusError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, ErrInvalidPair):
+		return fmt.Errorf("%w: %w", provider.ErrUnsupportedSymbol, err)
+	case errors.Is(err, ErrRateLimited), errors.Is(err, budget.ErrExceeded):
+		return fmt.Errorf("%w: %w", provider.ErrRateLimited, err)
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", provider.ErrRateLimited, err)
+	default:
+		return fmt.Errorf("%w: %w", provider.ErrUnavailable, err)
+	}
+}
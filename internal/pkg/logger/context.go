@@ -0,0 +1,29 @@
+package logger
+
+import "context"
+
+// ContextKey 在gin.Context中存储请求级日志实例的key
+const ContextKey = "logger"
+
+// contextKey 在context.Context中存储日志实例的key
+type contextKey struct{}
+
+// NewContext 返回携带日志实例的上下文
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// From 从上下文获取请求级日志实例，不存在时返回全局日志实例
+// 同时支持context.Context和gin.Context（通过其Value方法读取Keys）
+func From(ctx context.Context) Logger {
+	if ctx == nil {
+		return GetLogger()
+	}
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok && l != nil {
+		return l
+	}
+	if l, ok := ctx.Value(ContextKey).(Logger); ok && l != nil {
+		return l
+	}
+	return GetLogger()
+}
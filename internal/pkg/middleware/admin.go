@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenHeader 管理接口令牌请求头，也可使用Authorization: Bearer <token>
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminStatus 校验管理接口令牌，返回0表示通过；未配置令牌时管理接口关闭，返回403，令牌缺失或错误返回401
+func AdminStatus(token, authorization, adminHeader string) int {
+	if token == "" {
+		return http.StatusForbidden
+	}
+	provided := adminHeader
+	if bearer, ok := strings.CutPrefix(authorization, "Bearer "); ok && provided == "" {
+		provided = strings.TrimSpace(bearer)
+	}
+	if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		return http.StatusUnauthorized
+	}
+	return 0
+}
+
+// AdminAuth 管理接口鉴权中间件，导出状态、修改状态和手动触发任务的接口都需要令牌
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := AdminStatus(token, c.GetHeader("Authorization"), c.GetHeader(AdminTokenHeader))
+		if status == 0 {
+			c.Next()
+			return
+		}
+
+		logger.From(c).WithField("client_ip", c.ClientIP()).Warn("Admin API request rejected")
+		message := "管理接口未启用"
+		if status == http.StatusUnauthorized {
+			message = "管理接口令牌无效"
+		}
+		c.JSON(status, gin.H{
+			"error":   http.StatusText(status),
+			"message": message,
+			"code":    status,
+		})
+		c.Abort()
+	}
+}
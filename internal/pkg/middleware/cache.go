@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultMaxCacheEntries 未配置max_entries时的缓存条目上限
+const defaultMaxCacheEntries = 10000
+
+// ResponseCache 幂等GET接口的响应缓存，按路由模板和规范化后的查询参数缓存200响应
+type ResponseCache struct {
+	routes     map[string]time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+// cachedResponse 缓存的响应
+type cachedResponse struct {
+	contentType string
+	body        []byte
+	storedAt    time.Time
+	expiresAt   time.Time
+}
+
+// cacheWriter 记录处理器写出的响应体
+type cacheWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write 写出响应并保留副本
+func (w *cacheWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写出响应并保留副本
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// NewResponseCache 创建响应缓存，routes为路由模板(如/api/v1/tokens/:address)到缓存时间的映射
+func NewResponseCache(routes map[string]time.Duration, maxEntries int) *ResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxCacheEntries
+	}
+	return &ResponseCache{
+		routes:     routes,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*cachedResponse),
+	}
+}
+
+// Middleware 响应缓存中间件，命中时返回缓存内容，响应头X-Cache为HIT或MISS
+// 命中时响应信封中的meta.request_id和meta.timestamp替换为当前请求的值，不重放首次请求的ID
+// 请求头Cache-Control: no-cache时跳过缓存读取并刷新缓存
+func (rc *ResponseCache) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ttl, ok := rc.routes[c.FullPath()]
+		if !ok || ttl <= 0 || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		key := cacheKey(c.Request.URL)
+		if !strings.Contains(c.GetHeader("Cache-Control"), "no-cache") {
+			if entry := rc.get(key); entry != nil {
+				c.Header("X-Cache", "HIT")
+				c.Header("Age", strconv.Itoa(int(time.Since(entry.storedAt).Seconds())))
+				body := entry.body
+				if strings.HasPrefix(entry.contentType, "application/json") {
+					body = refreshMeta(body, c.GetString("request_id"), c.GetTime("timestamp"))
+				}
+				c.Data(http.StatusOK, entry.contentType, body)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Header("X-Cache", "MISS")
+		writer := &cacheWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		// 只缓存成功响应，处理器标记的负缓存等其他结果不缓存
+		if writer.Status() != http.StatusOK || writer.Header().Get("X-Cache") != "MISS" {
+			return
+		}
+		now := time.Now()
+		rc.set(key, &cachedResponse{
+			contentType: writer.Header().Get("Content-Type"),
+			body:        writer.body.Bytes(),
+			storedAt:    now,
+			expiresAt:   now.Add(ttl),
+		})
+	}
+}
+
+// get 获取未过期的缓存
+func (rc *ResponseCache) get(key string) *cachedResponse {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(rc.entries, key)
+		return nil
+	}
+	return entry
+}
+
+// set 写入缓存，达到上限时先清理过期条目，仍然已满则不写入
+func (rc *ResponseCache) set(key string, entry *cachedResponse) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if _, exists := rc.entries[key]; !exists && len(rc.entries) >= rc.maxEntries {
+		now := time.Now()
+		for k, e := range rc.entries {
+			if now.After(e.expiresAt) {
+				delete(rc.entries, k)
+			}
+		}
+		if len(rc.entries) >= rc.maxEntries {
+			return
+		}
+	}
+	rc.entries[key] = entry
+}
+
+// refreshMeta 替换响应信封meta中的request_id和timestamp，data等其他字段原样保留；不是信封格式时返回原响应
+func refreshMeta(body []byte, requestID string, timestamp time.Time) []byte {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return body
+	}
+	var meta map[string]json.RawMessage
+	if raw, ok := envelope["meta"]; !ok || json.Unmarshal(raw, &meta) != nil || meta == nil {
+		return body
+	}
+
+	if requestID != "" {
+		meta["request_id"], _ = json.Marshal(requestID)
+	} else {
+		delete(meta, "request_id")
+	}
+	meta["timestamp"], _ = json.Marshal(timestamp)
+
+	var err error
+	if envelope["meta"], err = json.Marshal(meta); err != nil {
+		return body
+	}
+	refreshed, err := json.Marshal(envelope)
+	if err != nil {
+		return body
+	}
+	return refreshed
+}
+
+// cacheKey 按路径和规范化后的查询参数生成缓存key
+// 参数按名称排序，同名参数保留原有顺序，参数顺序不同的请求共享缓存
+func cacheKey(u *url.URL) string {
+	return u.Path + "?" + u.Query().Encode()
+}
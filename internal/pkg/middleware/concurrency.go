@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 并发限制默认配置
+const (
+	defaultConcurrencyRetryAfter = time.Second
+	concurrencyGroupAll          = "all" // 实例全局限制的分组名
+)
+
+// ConcurrencyLimiter 限制单实例同时处理的请求数，超出时立即返回503而不排队
+type ConcurrencyLimiter struct {
+	global     *concurrencyGroup
+	groups     []*concurrencyGroup
+	retryAfter time.Duration
+}
+
+// concurrencyGroup 共享同一并发上限的一组路由
+type concurrencyGroup struct {
+	name     string
+	prefixes []string
+	slots    chan struct{}
+	inFlight atomic.Int64
+	rejected atomic.Uint64
+}
+
+// ConcurrencyStats 分组的并发统计
+type ConcurrencyStats struct {
+	Group       string
+	InFlight    int64  // 正在处理的请求数
+	MaxInFlight int    // 并发上限
+	Rejected    uint64 // 累计因超出上限被拒绝的请求数
+}
+
+// NewConcurrencyLimiter 按配置创建并发限制器，未设置上限的分组不生效
+func NewConcurrencyLimiter(cfg *config.Concurrency) *ConcurrencyLimiter {
+	l := &ConcurrencyLimiter{retryAfter: cfg.RetryAfter}
+	if l.retryAfter <= 0 {
+		l.retryAfter = defaultConcurrencyRetryAfter
+	}
+	if cfg.MaxInFlight > 0 {
+		l.global = newConcurrencyGroup(concurrencyGroupAll, nil, cfg.MaxInFlight)
+	}
+	for _, group := range cfg.Groups {
+		if group.MaxInFlight > 0 && len(group.Prefixes) > 0 {
+			l.groups = append(l.groups, newConcurrencyGroup(group.Name, group.Prefixes, group.MaxInFlight))
+		}
+	}
+	return l
+}
+
+// newConcurrencyGroup 创建分组
+func newConcurrencyGroup(name string, prefixes []string, maxInFlight int) *concurrencyGroup {
+	return &concurrencyGroup{
+		name:     name,
+		prefixes: prefixes,
+		slots:    make(chan struct{}, maxInFlight),
+	}
+}
+
+// Acquire 为请求路径占用并发名额，成功时返回释放函数；失败时返回已满的分组名
+// 路径先占用全局名额，再占用匹配的第一个分组的名额
+func (l *ConcurrencyLimiter) Acquire(path string) (func(), string, bool) {
+	var held []*concurrencyGroup
+	release := func() {
+		for _, g := range held {
+			g.release()
+		}
+	}
+
+	if l.global != nil {
+		if !l.global.tryAcquire() {
+			return nil, l.global.name, false
+		}
+		held = append(held, l.global)
+	}
+	if g := l.match(path); g != nil {
+		if !g.tryAcquire() {
+			release()
+			return nil, g.name, false
+		}
+		held = append(held, g)
+	}
+	return release, "", true
+}
+
+// RetryAfter 503响应建议的重试间隔
+func (l *ConcurrencyLimiter) RetryAfter() time.Duration {
+	return l.retryAfter
+}
+
+// Stats 各分组的并发统计，全局分组在前
+func (l *ConcurrencyLimiter) Stats() []ConcurrencyStats {
+	groups := l.groups
+	if l.global != nil {
+		groups = append([]*concurrencyGroup{l.global}, groups...)
+	}
+	stats := make([]ConcurrencyStats, 0, len(groups))
+	for _, g := range groups {
+		stats = append(stats, ConcurrencyStats{
+			Group:       g.name,
+			InFlight:    g.inFlight.Load(),
+			MaxInFlight: cap(g.slots),
+			Rejected:    g.rejected.Load(),
+		})
+	}
+	return stats
+}
+
+// Middleware 并发限制中间件，WebSocket和SSE长连接不占用名额
+func (l *ConcurrencyLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isStreamRequest(c) {
+			c.Next()
+			return
+		}
+
+		release, group, ok := l.Acquire(c.Request.URL.Path)
+		if !ok {
+			logger.From(c).WithField("group", group).Warn("Concurrency limit exceeded")
+			c.Header("Retry-After", strconv.Itoa(RetryAfterSeconds(l.retryAfter)))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Service Unavailable",
+				"message": "服务繁忙，请稍后再试",
+				"code":    503,
+			})
+			return
+		}
+		defer release()
+
+		c.Next()
+	}
+}
+
+// match 路径匹配的第一个分组
+func (l *ConcurrencyLimiter) match(path string) *concurrencyGroup {
+	for _, g := range l.groups {
+		for _, prefix := range g.prefixes {
+			if strings.HasPrefix(path, prefix) {
+				return g
+			}
+		}
+	}
+	return nil
+}
+
+// tryAcquire 不等待地占用名额
+func (g *concurrencyGroup) tryAcquire() bool {
+	select {
+	case g.slots <- struct{}{}:
+		g.inFlight.Add(1)
+		return true
+	default:
+		g.rejected.Add(1)
+		return false
+	}
+}
+
+// release 释放名额
+func (g *concurrencyGroup) release() {
+	g.inFlight.Add(-1)
+	<-g.slots
+}
+
+// RetryAfterSeconds Retry-After响应头的秒数，不足1秒按1秒
+func RetryAfterSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSparseFields fields参数最多包含的字段数
+const maxSparseFields = 50
+
+// fieldsWriter 暂存处理器写出的响应，过滤后再写出
+type fieldsWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write 暂存响应
+func (w *fieldsWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+// WriteString 暂存响应
+func (w *fieldsWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// SparseFields 字段选择中间件，请求带 fields=symbol,price 时只返回响应data中的指定字段
+// 数组中的每个元素分别过滤；嵌套字段用点号指定，如 fields=transactions.hash,total
+// 只处理成功的JSON响应，success、meta等响应信封字段始终保留
+func SparseFields() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fields := parseFields(c.Query("fields"))
+		if len(fields) == 0 || isStreamRequest(c) {
+			c.Next()
+			return
+		}
+
+		writer := &fieldsWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		body := writer.body.Bytes()
+		if writer.Status() == http.StatusOK && strings.HasPrefix(writer.Header().Get("Content-Type"), "application/json") {
+			if filtered, ok := filterEnvelope(body, fields); ok {
+				body = filtered
+			}
+		}
+		writer.ResponseWriter.Write(body)
+	}
+}
+
+// parseFields 解析fields参数为字段树，叶子节点为nil表示保留整个字段
+func parseFields(value string) map[string]interface{} {
+	if value == "" {
+		return nil
+	}
+	tree := make(map[string]interface{})
+	for i, field := range strings.Split(value, ",") {
+		if i >= maxSparseFields {
+			break
+		}
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		node := tree
+		parts := strings.Split(field, ".")
+		for j, part := range parts {
+			if part == "" {
+				break
+			}
+			if j == len(parts)-1 {
+				node[part] = nil
+				break
+			}
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				if _, whole := node[part]; whole {
+					// 已选择整个字段
+					break
+				}
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// filterEnvelope 过滤响应信封中的data字段，响应不是信封格式时返回false
+func filterEnvelope(body []byte, fields map[string]interface{}) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// 保留数字的原始表示，避免价格等数值精度变化
+	decoder.UseNumber()
+
+	var envelope map[string]interface{}
+	if err := decoder.Decode(&envelope); err != nil {
+		return nil, false
+	}
+	data, ok := envelope["data"]
+	if !ok {
+		return nil, false
+	}
+	envelope["data"] = filterValue(data, fields)
+
+	filtered, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, false
+	}
+	return filtered, true
+}
+
+// filterValue 按字段树过滤对象，数组逐个元素过滤，其他类型原样返回
+func filterValue(value interface{}, fields map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for i := range v {
+			v[i] = filterValue(v[i], fields)
+		}
+		return v
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(fields))
+		for name, sub := range fields {
+			field, ok := v[name]
+			if !ok {
+				continue
+			}
+			if children, nested := sub.(map[string]interface{}); nested {
+				result[name] = filterValue(field, children)
+			} else {
+				result[name] = field
+			}
+		}
+		return result
+	default:
+		return value
+	}
+}
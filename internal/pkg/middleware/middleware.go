@@ -1,18 +1,22 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"crypto-info/internal/pkg/deadline"
 	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
 	"crypto-info/internal/pkg/session"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-// RequestID 请求ID中间件
+// RequestID 请求ID中间件，同时记录请求时间，作为响应meta中的request_id和timestamp
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.GetHeader("X-Request-ID")
@@ -21,6 +25,31 @@ func RequestID() gin.HandlerFunc {
 		}
 		c.Header("X-Request-ID", requestID)
 		c.Set("request_id", requestID)
+		c.Set("timestamp", time.Now())
+		c.Next()
+	}
+}
+
+// RequestLogger 请求级日志中间件
+// 预先绑定request_id、tenant和user字段，处理器通过logger.From(c)获取
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fields := map[string]interface{}{
+			"request_id": c.GetString("request_id"),
+		}
+		if tenantID := c.GetHeader("X-Tenant-ID"); tenantID != "" {
+			fields["tenant"] = tenantID
+			c.Set("tenant_id", tenantID)
+		}
+		if userID := c.GetHeader("X-User-ID"); userID != "" {
+			fields["user"] = userID
+			c.Set("user_id", userID)
+		}
+
+		reqLogger := logger.GetLogger().WithFields(fields)
+		c.Set(logger.ContextKey, reqLogger)
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), reqLogger))
+
 		c.Next()
 	}
 }
@@ -29,7 +58,7 @@ func RequestID() gin.HandlerFunc {
 func Logger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		log := logger.GetLogger()
-		
+
 		fields := map[string]interface{}{
 			"timestamp":    param.TimeStamp.Format(time.RFC3339),
 			"status":       param.StatusCode,
@@ -40,40 +69,84 @@ func Logger() gin.HandlerFunc {
 			"user_agent":   param.Request.UserAgent(),
 			"request_size": param.Request.ContentLength,
 		}
-		
+
 		if requestID := param.Request.Header.Get("X-Request-ID"); requestID != "" {
 			fields["request_id"] = requestID
 		}
-		
+
 		if param.ErrorMessage != "" {
 			fields["error"] = param.ErrorMessage
 			log.WithFields(fields).Error("HTTP request completed with error")
 		} else {
 			log.WithFields(fields).Info("HTTP request completed")
 		}
-		
+
 		return ""
 	})
 }
 
+// RequestRecorder 记录请求的响应状态码，用于统计成功率
+type RequestRecorder interface {
+	RecordRequest(status int)
+}
+
+// SLA 请求成功率统计中间件，只统计/api/路径，WebSocket和SSE长连接不计入
+// 需注册在Recovery之前，使panic转成的500也被统计
+func SLA(recorder RequestRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.HasPrefix(c.Request.URL.Path, "/api/") || isStreamRequest(c) {
+			c.Next()
+			return
+		}
+		defer func() {
+			recorder.RecordRequest(c.Writer.Status())
+		}()
+		c.Next()
+	}
+}
+
+// UsageRecorder 记录API请求的路由模板和请求的币种，用于匿名使用统计
+type UsageRecorder interface {
+	RecordUsage(route string, symbols []string)
+}
+
+// Analytics 匿名使用统计中间件，只统计成功的/api/请求，不记录IP、会话和其他参数
+// 币种在请求处理完后读取，包含Preferences中间件按session偏好补全的币种
+func Analytics(recorder UsageRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.HasPrefix(c.Request.URL.Path, "/api/") || isStreamRequest(c) {
+			c.Next()
+			return
+		}
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		query := c.Request.URL.Query()
+		var symbols []string
+		if symbol := query.Get("symbol"); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+		if list := query.Get("symbols"); list != "" {
+			symbols = append(symbols, strings.Split(list, ",")...)
+		}
+		recorder.RecordUsage(route, symbols)
+	}
+}
+
 // Recovery 恢复中间件
+// panic连同调用栈记录日志并上报告警
 func Recovery() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		log := logger.GetLogger()
-		
-		fields := map[string]interface{}{
-			"panic":     recovered,
-			"method":    c.Request.Method,
-			"path":      c.Request.URL.Path,
-			"client_ip": c.ClientIP(),
-		}
-		
-		if requestID := c.GetString("request_id"); requestID != "" {
-			fields["request_id"] = requestID
-		}
-		
-		log.WithFields(fields).Error("Panic recovered")
-		
+		panics.Capture(&panics.Report{
+			Source:    "http",
+			RequestID: c.GetString("request_id"),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+		}, recovered)
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Internal Server Error",
 			"message": "服务器内部错误",
@@ -86,7 +159,7 @@ func Recovery() gin.HandlerFunc {
 func CORS(allowedOrigins []string, allowedMethods []string, allowedHeaders []string, allowCredentials bool, maxAge int) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
-		
+
 		// 检查允许的源
 		allowed := false
 		for _, allowedOrigin := range allowedOrigins {
@@ -95,24 +168,24 @@ func CORS(allowedOrigins []string, allowedMethods []string, allowedHeaders []str
 				break
 			}
 		}
-		
+
 		if allowed {
 			c.Header("Access-Control-Allow-Origin", origin)
 		}
-		
+
 		c.Header("Access-Control-Allow-Methods", joinStrings(allowedMethods, ", "))
 		c.Header("Access-Control-Allow-Headers", joinStrings(allowedHeaders, ", "))
 		c.Header("Access-Control-Max-Age", strconv.Itoa(maxAge))
-		
+
 		if allowCredentials {
 			c.Header("Access-Control-Allow-Credentials", "true")
 		}
-		
+
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
-		
+
 		c.Next()
 	}
 }
@@ -125,11 +198,11 @@ type RateLimiter interface {
 func RateLimit(limiter RateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		key := c.ClientIP()
-		
+
 		if !limiter.Allow(key) {
 			log := logger.GetLogger()
 			log.WithField("client_ip", key).Warn("Rate limit exceeded")
-			
+
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":   "Too Many Requests",
 				"message": "请求过于频繁，请稍后再试",
@@ -138,34 +211,66 @@ func RateLimit(limiter RateLimiter) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		
+
 		c.Next()
 	}
 }
 
 // Timeout 超时中间件
+// 为请求上下文设置截止时间，下游的Redis、HTTP、RPC和消息队列调用会随之取消
+// 客户端可通过X-Request-Timeout请求头缩短截止时间，超过timeout的值按timeout处理
+// WebSocket和SSE长连接不设置截止时间
 func Timeout(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 设置超时上下文
-		ctx := c.Request.Context()
-		select {
-		case <-time.After(timeout):
-			c.JSON(http.StatusRequestTimeout, gin.H{
+		req := c.Request
+		if isStreamRequest(c) {
+			c.Next()
+			return
+		}
+		requested, err := deadline.Parse(req.Header.Get(deadline.Header))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": "无效的X-Request-Timeout请求头",
+				"code":    400,
+			})
+			return
+		}
+		ctx, cancel := deadline.Derive(req.Context(), requested, timeout)
+		defer cancel()
+
+		c.Request = req.WithContext(ctx)
+		c.Next()
+		// 恢复原始请求，避免上游中间件在请求结束后使用已取消的上下文
+		c.Request = req
+
+		if ctx.Err() == context.DeadlineExceeded && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusRequestTimeout, gin.H{
 				"error":   "Request Timeout",
 				"message": "请求超时",
 				"code":    408,
 			})
-			c.Abort()
-			return
-		case <-ctx.Done():
-			c.Abort()
-			return
-		default:
-			c.Next()
 		}
 	}
 }
 
+// streamRoutes WebSocket和SSE长连接的路由模板
+var streamRoutes = map[string]bool{
+	"/api/v1/stream/ws":  true,
+	"/api/v1/stream/sse": true,
+}
+
+// IsStreamRoute 路由模板是否为WebSocket或SSE长连接
+// 按匹配到的路由而不是客户端可控的Upgrade/Accept请求头判断，普通接口无法通过伪造请求头绕过并发限制和超时
+func IsStreamRoute(route string) bool {
+	return streamRoutes[route]
+}
+
+// isStreamRequest 是否为WebSocket或SSE长连接请求
+func isStreamRequest(c *gin.Context) bool {
+	return IsStreamRoute(c.FullPath())
+}
+
 // Security 安全头中间件
 func Security() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -208,10 +313,10 @@ func joinStrings(strs []string, sep string) string {
 	if len(strs) == 1 {
 		return strs[0]
 	}
-	
+
 	result := strs[0]
 	for i := 1; i < len(strs); i++ {
 		result += sep + strs[i]
 	}
 	return result
-}
\ No newline at end of file
+}
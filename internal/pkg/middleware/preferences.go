@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"strings"
+
+	"crypto-info/internal/pkg/session"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 偏好对应的查询参数
+const (
+	paramQuoteCurrency = "quote_currency"
+	paramLocale        = "locale"
+	paramSymbol        = "symbol"
+	paramSymbols       = "symbols"
+)
+
+// preferenceRoutes 使用session偏好的路由模板及其接受的参数
+var preferenceRoutes = map[string][]string{
+	"/api/v1/crypto/price":              {paramQuoteCurrency, paramLocale, paramSymbol},
+	"/api/v1/crypto/price/history":      {paramSymbol},
+	"/api/v1/crypto/price/at":           {paramSymbol},
+	"/api/v1/crypto/klines":             {paramSymbol},
+	"/api/v1/crypto/marketcap":          {paramSymbols},
+	"/api/v1/crypto/compare":            {paramSymbols},
+	"/api/v1/crypto/volume/analysis":    {paramSymbol},
+	"/api/v1/crypto/volume/fluctuation": {paramSymbol},
+	"/api/v1/crypto/volume/comparison":  {paramSymbols},
+}
+
+// Preferences 请求未指定计价币种、locale或币种时使用session中的显示偏好
+//
+// 偏好直接写入请求的查询参数，处理器无需感知session，响应缓存也按补全后的参数区分；
+// 响应头X-Preferences-Applied列出补全的参数。需放在Session中间件之后、其他读取查询参数的中间件之前。
+func Preferences() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params, ok := preferenceRoutes[c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+		prefs, ok := session.GetPreferences(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		query := c.Request.URL.Query()
+		var applied []string
+		for _, param := range params {
+			if query.Get(param) != "" {
+				continue
+			}
+			value := preferenceValue(prefs, param)
+			if value == "" {
+				continue
+			}
+			query.Set(param, value)
+			applied = append(applied, param)
+		}
+		if len(applied) > 0 {
+			c.Request.URL.RawQuery = query.Encode()
+			c.Header("X-Preferences-Applied", strings.Join(applied, ","))
+		}
+		c.Next()
+	}
+}
+
+// preferenceValue 参数对应的偏好值，未设置时为空
+func preferenceValue(prefs *session.Preferences, param string) string {
+	switch param {
+	case paramQuoteCurrency:
+		return prefs.Currency
+	case paramLocale:
+		return prefs.Locale
+	case paramSymbol:
+		if len(prefs.Symbols) > 0 {
+			return prefs.Symbols[0]
+		}
+	case paramSymbols:
+		return strings.Join(prefs.Symbols, ",")
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 负载降级默认配置
+const (
+	defaultSheddingRetryAfter = 30 * time.Second
+	sheddingStatusDown        = "down"
+)
+
+// DependencyStatusFunc 返回各依赖最近一次探测的状态(依赖名称 -> ok/degraded/down)，尚未探测时返回空
+type DependencyStatusFunc func() map[string]string
+
+// LoadShedder 依赖异常时按策略拒绝低优先级请求，未列入策略的路径(如价格查询)始终放行
+type LoadShedder struct {
+	policies   []*sheddingPolicy
+	status     DependencyStatusFunc
+	retryAfter time.Duration
+}
+
+// sheddingPolicy 单条降级策略
+type sheddingPolicy struct {
+	name         string
+	dependencies []string
+	statuses     map[string]bool
+	prefixes     []string
+	shed         atomic.Uint64
+}
+
+// SheddingStats 降级策略统计
+type SheddingStats struct {
+	Policy string
+	Active bool   // 当前是否处于降级状态
+	Shed   uint64 // 累计被拒绝的请求数
+}
+
+// NewLoadShedder 按配置创建负载降级器，没有依赖或路径前缀的策略不生效
+func NewLoadShedder(cfg *config.LoadShedding, status DependencyStatusFunc) *LoadShedder {
+	s := &LoadShedder{status: status, retryAfter: cfg.RetryAfter}
+	if s.retryAfter <= 0 {
+		s.retryAfter = defaultSheddingRetryAfter
+	}
+	for _, policy := range cfg.Policies {
+		if len(policy.Dependencies) == 0 || len(policy.Prefixes) == 0 {
+			continue
+		}
+		statuses := make(map[string]bool)
+		for _, st := range policy.Statuses {
+			statuses[strings.ToLower(st)] = true
+		}
+		if len(statuses) == 0 {
+			statuses[sheddingStatusDown] = true
+		}
+		s.policies = append(s.policies, &sheddingPolicy{
+			name:         policy.Name,
+			dependencies: policy.Dependencies,
+			statuses:     statuses,
+			prefixes:     policy.Prefixes,
+		})
+	}
+	return s
+}
+
+// Check 判断请求路径是否应被拒绝，返回触发降级的策略名；依赖状态未知时不降级
+func (s *LoadShedder) Check(path string) (string, bool) {
+	var status map[string]string
+	for _, p := range s.policies {
+		if !p.matches(path) {
+			continue
+		}
+		if status == nil {
+			if status = s.status(); status == nil {
+				return "", false
+			}
+		}
+		if p.active(status) {
+			p.shed.Add(1)
+			return p.name, true
+		}
+	}
+	return "", false
+}
+
+// RetryAfter 503响应建议的重试间隔
+func (s *LoadShedder) RetryAfter() time.Duration {
+	return s.retryAfter
+}
+
+// Stats 各策略的降级统计
+func (s *LoadShedder) Stats() []SheddingStats {
+	status := s.status()
+	stats := make([]SheddingStats, 0, len(s.policies))
+	for _, p := range s.policies {
+		stats = append(stats, SheddingStats{
+			Policy: p.name,
+			Active: status != nil && p.active(status),
+			Shed:   p.shed.Load(),
+		})
+	}
+	return stats
+}
+
+// Middleware 负载降级中间件，被拒绝的请求返回503并在X-Load-Shed头中给出策略名
+func (s *LoadShedder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, shed := s.Check(c.Request.URL.Path)
+		if !shed {
+			c.Next()
+			return
+		}
+
+		logger.From(c).WithField("policy", policy).Warn("Request shed due to degraded dependency")
+		c.Header("Retry-After", strconv.Itoa(RetryAfterSeconds(s.retryAfter)))
+		c.Header("X-Load-Shed", policy)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Service Unavailable",
+			"message": "服务降级中，请稍后再试",
+			"code":    503,
+		})
+	}
+}
+
+// matches 路径是否匹配策略的任一前缀
+func (p *sheddingPolicy) matches(path string) bool {
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// active 任一依赖处于触发状态时策略生效
+func (p *sheddingPolicy) active(status map[string]string) bool {
+	for _, dep := range p.dependencies {
+		if p.statuses[status[dep]] {
+			return true
+		}
+	}
+	return false
+}
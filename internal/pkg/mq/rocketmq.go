@@ -3,6 +3,7 @@ package mq
 import (
 	"context"
 	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/deadline"
 	"fmt"
 	"sync"
 
@@ -140,8 +141,8 @@ func (c *RocketMQClient) Stop() error {
 	return nil
 }
 
-// SendMessage 发送消息
-func (c *RocketMQClient) SendMessage(topic, tag string, body []byte) error {
+// SendMessage 发送消息，超时取send_msg_timeout和ctx截止时间中较早的一个
+func (c *RocketMQClient) SendMessage(ctx context.Context, topic, tag string, body []byte) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -158,7 +159,7 @@ func (c *RocketMQClient) SendMessage(topic, tag string, body []byte) error {
 		msg.WithTag(tag)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.config.Producer.SendMsgTimeout)
+	ctx, cancel := deadline.Derive(ctx, 0, c.config.Producer.SendMsgTimeout)
 	defer cancel()
 
 	result, err := c.producer.SendSync(ctx, msg)
@@ -170,8 +171,8 @@ func (c *RocketMQClient) SendMessage(topic, tag string, body []byte) error {
 	return nil
 }
 
-// SendAsyncMessage 异步发送消息
-func (c *RocketMQClient) SendAsyncMessage(topic, tag string, body []byte, callback func(context.Context, *primitive.SendResult, error)) error {
+// SendAsyncMessage 异步发送消息，超时规则同SendMessage
+func (c *RocketMQClient) SendAsyncMessage(ctx context.Context, topic, tag string, body []byte, callback func(context.Context, *primitive.SendResult, error)) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -188,7 +189,7 @@ func (c *RocketMQClient) SendAsyncMessage(topic, tag string, body []byte, callba
 		msg.WithTag(tag)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.config.Producer.SendMsgTimeout)
+	ctx, cancel := deadline.Derive(ctx, 0, c.config.Producer.SendMsgTimeout)
 	defer cancel()
 
 	return c.producer.SendAsync(ctx, callback, msg)
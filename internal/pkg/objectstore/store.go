@@ -0,0 +1,100 @@
+// Package objectstore 备份文件的存储，支持本地目录和S3兼容的对象存储
+//
+// 对象按名称存取，名称不含目录；本地目录实现先写临时文件再重命名，S3实现使用AWS Signature V4签名，
+// 适用于AWS S3、MinIO、Cloudflare R2等兼容服务，不依赖SDK。
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotExist 对象不存在
+var ErrNotExist = errors.New("object does not exist")
+
+// Store 对象存储
+type Store interface {
+	// Put 写入对象，同名对象被覆盖
+	Put(ctx context.Context, name string, data []byte) error
+	// Get 读取对象，不存在时返回ErrNotExist
+	Get(ctx context.Context, name string) ([]byte, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(ctx context.Context, name string) error
+	// List 列出名称以prefix开头的对象，按名称排序
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Location 对象的位置，用于日志和接口返回，如文件路径或s3://bucket/key
+	Location(name string) string
+}
+
+// dirStore 本地目录，可挂载网络存储
+type dirStore struct {
+	dir string
+}
+
+// NewDir 创建本地目录存储，目录在首次写入时创建
+func NewDir(dir string) Store {
+	return &dirStore{dir: dir}
+}
+
+// Put 先写临时文件再重命名，避免中断时留下不完整的文件
+func (d *dirStore) Put(_ context.Context, name string, data []byte) error {
+	if err := os.MkdirAll(d.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create dir: %w", err)
+	}
+	path := d.Location(name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Get 读取文件
+func (d *dirStore) Get(_ context.Context, name string) ([]byte, error) {
+	data, err := os.ReadFile(d.Location(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotExist, name)
+	}
+	return data, err
+}
+
+// Delete 删除文件
+func (d *dirStore) Delete(_ context.Context, name string) error {
+	if err := os.Remove(d.Location(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List 列出目录中的文件，目录不存在时返回空
+func (d *dirStore) List(_ context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Location 文件路径
+func (d *dirStore) Location(name string) string {
+	return filepath.Join(d.dir, name)
+}
@@ -0,0 +1,241 @@
+// Package okx OKX现货行情REST API客户端
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/httpclient"
+)
+
+// defaultBaseURL OKX API默认地址
+const defaultBaseURL = "https://www.okx.com"
+
+// OKX错误码
+const (
+	codeOK            = "0"
+	codeInvalidInstID = "51001" // 产品ID不存在
+	codeRateLimited   = "50011" // 请求频率过高
+)
+
+var (
+	// ErrInvalidSymbol 产品(交易对)不存在
+	ErrInvalidSymbol = errors.New("okx: invalid instrument")
+	// ErrRateLimited 触发OKX限流
+	ErrRateLimited = errors.New("okx: rate limited")
+)
+
+// Client OKX API客户端
+type Client struct {
+	baseURL    string
+	retrier    *httpclient.Retrier
+	httpClient *http.Client
+}
+
+// Ticker 现货产品行情，包含最新成交价和24小时统计
+type Ticker struct {
+	InstID    string    // 产品ID，如BTC-USDT
+	Last      float64   // 最新成交价
+	Open24h   float64   // 24小时开盘价
+	High24h   float64   // 24小时最高价
+	Low24h    float64   // 24小时最低价
+	Vol24h    float64   // 24小时成交量(交易货币)
+	VolCcy24h float64   // 24小时成交额(计价货币)
+	Time      time.Time // 行情时间
+}
+
+// Change24h 24小时涨跌幅(百分比)，开盘价为0时返回0
+func (t *Ticker) Change24h() float64 {
+	if t.Open24h == 0 {
+		return 0
+	}
+	return (t.Last - t.Open24h) / t.Open24h * 100
+}
+
+// Candle K线
+type Candle struct {
+	Time        time.Time // 开盘时间
+	Open        float64
+	High        float64
+	Low         float64
+	Close       float64
+	Volume      float64 // 成交量(交易货币)
+	QuoteVolume float64 // 成交额(计价货币)
+}
+
+// response OKX通用响应
+type response struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// ticker OKX行情响应，数值均为字符串
+type ticker struct {
+	InstID    string `json:"instId"`
+	Last      string `json:"last"`
+	Open24h   string `json:"open24h"`
+	High24h   string `json:"high24h"`
+	Low24h    string `json:"low24h"`
+	Vol24h    string `json:"vol24h"`
+	VolCcy24h string `json:"volCcy24h"`
+	Ts        string `json:"ts"`
+}
+
+// NewClient 创建OKX客户端，按配置的地址、超时、代理和重试次数访问API
+func NewClient(cfg *config.APIConfig) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		retrier:    httpclient.NewRetrier(budget.ProviderOKX, cfg.RetryTimes, cfg.RetryInterval, retryable),
+		httpClient: httpclient.New(httpclient.Options{Timeout: cfg.Timeout, Provider: budget.ProviderOKX, Proxy: cfg.Proxy}),
+	}
+}
+
+// GetTicker 获取现货产品(如BTC-USDT)的最新成交价和24小时统计
+func (c *Client) GetTicker(ctx context.Context, instID string) (*Ticker, error) {
+	params := url.Values{}
+	params.Set("instId", strings.ToUpper(instID))
+
+	var tickers []ticker
+	if err := c.get(ctx, "/api/v5/market/ticker", params, &tickers); err != nil {
+		return nil, err
+	}
+	if len(tickers) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSymbol, instID)
+	}
+
+	t := tickers[0]
+	values, err := parseNumbers(t.Last, t.Open24h, t.High24h, t.Low24h, t.Vol24h, t.VolCcy24h, t.Ts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid okx ticker for %s: %w", instID, err)
+	}
+	return &Ticker{
+		InstID:    t.InstID,
+		Last:      values[0],
+		Open24h:   values[1],
+		High24h:   values[2],
+		Low24h:    values[3],
+		Vol24h:    values[4],
+		VolCcy24h: values[5],
+		Time:      time.UnixMilli(int64(values[6])),
+	}, nil
+}
+
+// GetCandles 获取现货产品最近limit根K线，bar为OKX K线周期(如1Dutc)，按时间倒序
+func (c *Client) GetCandles(ctx context.Context, instID, bar string, limit int) ([]Candle, error) {
+	params := url.Values{}
+	params.Set("instId", strings.ToUpper(instID))
+	params.Set("bar", bar)
+	params.Set("limit", strconv.Itoa(limit))
+
+	var rows [][]string
+	if err := c.get(ctx, "/api/v5/market/candles", params, &rows); err != nil {
+		return nil, err
+	}
+
+	candles := make([]Candle, 0, len(rows))
+	for _, row := range rows {
+		// [开盘时间, 开, 高, 低, 收, 成交量, 成交量(币), 成交额, 是否完结]
+		if len(row) < 8 {
+			return nil, fmt.Errorf("invalid okx candle for %s: expected at least 8 fields, got %d", instID, len(row))
+		}
+		values, err := parseNumbers(row[0], row[1], row[2], row[3], row[4], row[5], row[7])
+		if err != nil {
+			return nil, fmt.Errorf("invalid okx candle for %s: %w", instID, err)
+		}
+		candles = append(candles, Candle{
+			Time:        time.UnixMilli(int64(values[0])).UTC(),
+			Open:        values[1],
+			High:        values[2],
+			Low:         values[3],
+			Close:       values[4],
+			Volume:      values[5],
+			QuoteVolume: values[6],
+		})
+	}
+	return candles, nil
+}
+
+// parseNumbers 解析以字符串表示的数值
+func parseNumbers(values ...string) ([]float64, error) {
+	numbers := make([]float64, len(values))
+	for i, v := range values {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// get 发送GET请求并解析data字段，网络错误、限流和5xx响应按配置的次数指数退避重试
+func (c *Client) get(ctx context.Context, path string, params url.Values, result interface{}) error {
+	endpoint := c.baseURL + path + "?" + params.Encode()
+
+	var resp response
+	err := c.retrier.Do(ctx, func() error {
+		if err := httpclient.GetJSON(ctx, c.httpClient, endpoint, &resp); err != nil {
+			return convertError(err)
+		}
+		return checkCode(resp.Code, resp.Msg)
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(resp.Data, result)
+}
+
+// checkCode 检查OKX业务错误码
+func checkCode(code, msg string) error {
+	switch code {
+	case codeOK:
+		return nil
+	case codeInvalidInstID:
+		return fmt.Errorf("%w: %s", ErrInvalidSymbol, msg)
+	case codeRateLimited:
+		return fmt.Errorf("%w: %s", ErrRateLimited, msg)
+	}
+	return fmt.Errorf("okx error: %s %s", code, msg)
+}
+
+// convertError 解析非2xx响应中的业务错误码，OKX对参数错误和限流也可能返回4xx
+func convertError(err error) error {
+	var statusErr *httpclient.StatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+
+	var resp response
+	if json.Unmarshal([]byte(statusErr.Body), &resp) == nil && resp.Code != "" && resp.Code != codeOK {
+		if codeErr := checkCode(resp.Code, resp.Msg); errors.Is(codeErr, ErrInvalidSymbol) || errors.Is(codeErr, ErrRateLimited) {
+			return codeErr
+		}
+	}
+	if statusErr.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	}
+	return err
+}
+
+// retryable 是否值得重试，产品不存在时不重试，业务错误码表示的限流重试，其余按httpclient.Retryable判断
+func retryable(err error) bool {
+	if errors.Is(err, ErrInvalidSymbol) {
+		return false
+	}
+	return errors.Is(err, ErrRateLimited) || httpclient.Retryable(err)
+}
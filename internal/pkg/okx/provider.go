@@ -0,0 +1,155 @@
+package okx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/provider"
+)
+
+// providerName 数据源名称
+const providerName = "okx"
+
+// QuoteAsset 价格和成交额使用的计价币种
+const QuoteAsset = "USDT"
+
+// maxCandleLimit 单次请求的最大K线数量
+const maxCandleLimit = 300
+
+// validSymbol 币种名称只包含字母和数字
+var validSymbol = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// Provider OKX价格和交易量数据源，币种以对USDT的现货产品(如BTC-USDT)查询
+type Provider struct {
+	client *Client
+}
+
+// NewProvider 创建OKX数据源
+func NewProvider(client *Client) *Provider {
+	return &Provider{client: client}
+}
+
+// Name 实现provider.PriceProvider接口
+func (p *Provider) Name() string {
+	return providerName
+}
+
+// GetPrice 实现provider.PriceProvider接口
+func (p *Provider) GetPrice(ctx context.Context, symbol string) (*provider.Quote, error) {
+	instID, symbol, err := p.instID(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker, err := p.client.GetTicker(ctx, instID)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	if ticker.Last <= 0 {
+		return nil, fmt.Errorf("%w: invalid okx price %v for %s", provider.ErrUnavailable, ticker.Last, instID)
+	}
+	return &provider.Quote{
+		Symbol:   symbol,
+		Price:    ticker.Last,
+		Currency: QuoteAsset,
+		Time:     ticker.Time,
+		Stats:    &provider.Stats24h{Open: ticker.Open24h, High: ticker.High24h, Low: ticker.Low24h, Volume: ticker.Vol24h, QuoteVolume: ticker.VolCcy24h},
+	}, nil
+}
+
+// GetDailyVolumes 实现provider.VolumeProvider接口，按UTC自然日统计，OKX按时间倒序返回，转换为升序
+func (p *Provider) GetDailyVolumes(ctx context.Context, symbol string, days int) ([]provider.DailyVolume, error) {
+	instID, _, err := p.instID(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 || days > maxCandleLimit {
+		return nil, fmt.Errorf("invalid days %d, must be between 1 and %d", days, maxCandleLimit)
+	}
+
+	candles, err := p.client.GetCandles(ctx, instID, "1Dutc", days)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	volumes := make([]provider.DailyVolume, len(candles))
+	for i, candle := range candles {
+		volumes[i] = provider.DailyVolume{Date: candle.Time, Volume: candle.Volume, QuoteVolume: candle.QuoteVolume}
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Date.Before(volumes[j].Date) })
+	return volumes, nil
+}
+
+// candleBars 支持的K线周期对应的OKX周期代码，日线和周线按UTC对齐
+var candleBars = map[time.Duration]string{
+	time.Minute:        "1m",
+	5 * time.Minute:    "5m",
+	15 * time.Minute:   "15m",
+	30 * time.Minute:   "30m",
+	time.Hour:          "1H",
+	4 * time.Hour:      "4H",
+	24 * time.Hour:     "1Dutc",
+	7 * 24 * time.Hour: "1Wutc",
+}
+
+// GetCandles 实现provider.CandleProvider接口，OKX按时间倒序返回，转换为升序
+func (p *Provider) GetCandles(ctx context.Context, symbol string, interval time.Duration, limit int) ([]provider.Candle, error) {
+	instID, _, err := p.instID(symbol)
+	if err != nil {
+		return nil, err
+	}
+	bar, ok := candleBars[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported interval %s", interval)
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+	limit = min(limit, maxCandleLimit)
+
+	rows, err := p.client.GetCandles(ctx, instID, bar, limit)
+	if err != nil {
+		return nil, typedError(err)
+	}
+	candles := make([]provider.Candle, len(rows))
+	for i, row := range rows {
+		candles[i] = provider.Candle{
+			OpenTime:    row.Time,
+			Open:        row.Open,
+			High:        row.High,
+			Low:         row.Low,
+			Close:       row.Close,
+			Volume:      row.Volume,
+			QuoteVolume: row.QuoteVolume,
+		}
+	}
+	sort.Slice(candles, func(i, j int) bool { return candles[i].OpenTime.Before(candles[j].OpenTime) })
+	return candles, nil
+}
+
+// instID 将币种转换为对USDT的现货产品ID，返回产品ID和统一后的币种
+func (p *Provider) instID(symbol string) (string, string, error) {
+	symbol = provider.NormalizeSymbol(symbol)
+	if !validSymbol.MatchString(symbol) || symbol == QuoteAsset {
+		return "", "", fmt.Errorf("%w: %s", provider.ErrUnsupportedSymbol, symbol)
+	}
+	return symbol + "-" + QuoteAsset, symbol, nil
+}
+
+// typedError 将客户端错误归类为provider包的错误，ctx的取消和超时原样返回
+func typedError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, ErrInvalidSymbol):
+		return fmt.Errorf("%w: %w", provider.ErrUnsupportedSymbol, err)
+	case errors.Is(err, ErrRateLimited), errors.Is(err, budget.ErrExceeded):
+		return fmt.Errorf("%w: %w", provider.ErrRateLimited, err)
+	default:
+		return fmt.Errorf("%w: %w", provider.ErrUnavailable, err)
+	}
+}
@@ -0,0 +1,203 @@
+// Package panics 捕获请求处理和后台goroutine中的panic并上报
+//
+// Recovery中间件和Go启动的后台任务捕获panic后记录错误日志，并把包含调用栈的Report交给SetHandler注册的处理函数，
+// 由启动流程将其转为告警发送到运维通知渠道。处理函数在独立goroutine中执行，不阻塞请求的500响应。
+//
+// 常驻的后台循环使用Supervise启动，panic后按退避时间重新启动，并在Workers中标记为不健康，由就绪探针上报。
+package panics
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"crypto-info/internal/pkg/logger"
+)
+
+// Report 一次被捕获的panic
+type Report struct {
+	Source    string    // 来源，如http、hertz或后台任务名称
+	Value     string    // panic的值
+	Stack     string    // 调用栈
+	RequestID string    // 请求ID，后台任务为空
+	Method    string    // 请求方法，后台任务为空
+	Path      string    // 请求路径，后台任务为空
+	Time      time.Time // 捕获时间
+}
+
+// Handler panic上报处理函数
+type Handler func(report *Report)
+
+var (
+	handlerMu sync.RWMutex
+	handler   Handler
+)
+
+// SetHandler 设置上报处理函数，传入空时只记录日志
+func SetHandler(h Handler) {
+	handlerMu.Lock()
+	defer handlerMu.Unlock()
+	handler = h
+}
+
+// Capture 记录panic并异步上报，必须在recover所在的defer中调用以保留panic现场的调用栈
+// report的Source、RequestID、Method和Path由调用方填写
+func Capture(report *Report, recovered interface{}) {
+	report.Value = fmt.Sprint(recovered)
+	report.Stack = string(debug.Stack())
+	report.Time = time.Now()
+
+	fields := map[string]interface{}{
+		"panic":  report.Value,
+		"source": report.Source,
+		"stack":  report.Stack,
+	}
+	if report.RequestID != "" {
+		fields["request_id"] = report.RequestID
+	}
+	if report.Path != "" {
+		fields["method"] = report.Method
+		fields["path"] = report.Path
+	}
+	logger.GetLogger().WithFields(fields).Error("Panic recovered")
+
+	handlerMu.RLock()
+	h := handler
+	handlerMu.RUnlock()
+	if h != nil {
+		go h(report)
+	}
+}
+
+// Recover 在defer中使用，捕获后台goroutine的panic并上报，goroutine正常结束而不是让进程退出
+func Recover(source string) {
+	if recovered := recover(); recovered != nil {
+		Capture(&Report{Source: source}, recovered)
+	}
+}
+
+// Go 启动一次性的后台goroutine，fn发生panic时上报而不是让进程退出；常驻循环使用Supervise
+func Go(source string, fn func()) {
+	go func() {
+		defer Recover(source)
+		fn()
+	}()
+}
+
+// 后台循环重启配置
+const (
+	minRestartBackoff = time.Second
+	maxRestartBackoff = time.Minute
+	// unhealthyWindow 最近一次panic后的这段时间内后台循环视为不健康，期间正常运行则恢复
+	unhealthyWindow = 5 * time.Minute
+)
+
+// WorkerStatus 发生过panic的后台循环状态
+type WorkerStatus struct {
+	Source     string    `json:"source"`
+	Panics     int       `json:"panics"`     // 累计panic次数
+	LastPanic  time.Time `json:"last_panic"` // 最近一次panic的时间
+	LastError  string    `json:"last_error"` // 最近一次panic的值
+	Restarting bool      `json:"restarting"` // 正在等待退避后重新启动
+	Healthy    bool      `json:"healthy"`
+}
+
+var (
+	workersMu sync.Mutex
+	workers   = make(map[string]*WorkerStatus)
+)
+
+// Supervise 启动常驻的后台循环，run发生panic时上报，按指数退避重新启动，直到ctx结束或run正常返回
+// done不为空时在循环最终退出后关闭，run内部不应再关闭它，否则重新启动时会重复关闭
+func Supervise(ctx context.Context, source string, run func(ctx context.Context), done chan struct{}) {
+	go func() {
+		if done != nil {
+			defer close(done)
+		}
+
+		backoff := minRestartBackoff
+		for {
+			started := time.Now()
+			if !runGuarded(ctx, source, run) {
+				return
+			}
+			// 稳定运行一段时间后才panic的，从最小退避重新开始
+			if time.Since(started) >= unhealthyWindow {
+				backoff = minRestartBackoff
+			}
+
+			setRestarting(source, true)
+			logger.GetLogger().Warnf("Background worker %s panicked, restarting in %s", source, backoff)
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				setRestarting(source, false)
+				return
+			case <-timer.C:
+			}
+			setRestarting(source, false)
+
+			backoff *= 2
+			if backoff > maxRestartBackoff {
+				backoff = maxRestartBackoff
+			}
+		}
+	}()
+}
+
+// runGuarded 执行一次run，返回是否发生了panic
+func runGuarded(ctx context.Context, source string, run func(ctx context.Context)) (panicked bool) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			Capture(&Report{Source: source}, recovered)
+			recordPanic(source, recovered)
+			panicked = true
+		}
+	}()
+	run(ctx)
+	return false
+}
+
+// recordPanic 记录后台循环的panic
+func recordPanic(source string, recovered interface{}) {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+
+	w, ok := workers[source]
+	if !ok {
+		w = &WorkerStatus{Source: source}
+		workers[source] = w
+	}
+	w.Panics++
+	w.LastPanic = time.Now()
+	w.LastError = fmt.Sprint(recovered)
+}
+
+// setRestarting 标记后台循环是否在等待重新启动
+func setRestarting(source string, restarting bool) {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+
+	if w, ok := workers[source]; ok {
+		w.Restarting = restarting
+	}
+}
+
+// Workers 发生过panic的后台循环，按名称排序；等待重新启动或最近一次panic未超过unhealthyWindow的标记为不健康
+func Workers() []WorkerStatus {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+
+	result := make([]WorkerStatus, 0, len(workers))
+	for _, w := range workers {
+		status := *w
+		status.Healthy = !w.Restarting && time.Since(w.LastPanic) >= unhealthyWindow
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Source < result[j].Source })
+	return result
+}
@@ -0,0 +1,146 @@
+// Package popularity 按币种统计请求热度并据此调整缓存时间
+//
+// 请求次数按固定窗口计数，用上一窗口按剩余比例加权近似滑动窗口。热门币种的缓存时间缩短以保证新鲜度，
+// 冷门币种延长以减少上游调用，调整范围由 cache.adaptive 配置。统计只在当前进程内有效。
+package popularity
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+)
+
+// Tracker 请求热度统计器，未启用时不统计，缓存时间按配置值返回
+type Tracker struct {
+	mu          sync.Mutex
+	config      config.AdaptiveTTL
+	windowStart time.Time
+	current     map[string]int64
+	previous    map[string]int64
+}
+
+var defaultTracker = NewTracker(nil)
+
+// Init 按配置初始化全局统计器，已有的计数清空
+func Init(cfg *config.AdaptiveTTL) {
+	defaultTracker.configure(cfg)
+}
+
+// Default 全局统计器
+func Default() *Tracker {
+	return defaultTracker
+}
+
+// NewTracker 创建统计器，cfg为空或未启用时不调整缓存时间
+func NewTracker(cfg *config.AdaptiveTTL) *Tracker {
+	t := &Tracker{}
+	t.configure(cfg)
+	return t
+}
+
+// configure 更新配置并清空计数
+func (t *Tracker) configure(cfg *config.AdaptiveTTL) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.config = config.AdaptiveTTL{}
+	if cfg != nil {
+		t.config = *cfg
+	}
+	t.windowStart = time.Now()
+	t.current = make(map[string]int64)
+	t.previous = make(map[string]int64)
+}
+
+// Record 记录一次币种请求，调用方应只传入已校验的币种
+func (t *Tracker) Record(symbol string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.config.Enabled {
+		return
+	}
+	t.rotateLocked(time.Now())
+	t.current[symbol]++
+}
+
+// Requests 最近一个窗口内的请求次数估计值
+func (t *Tracker) Requests(symbol string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.config.Enabled {
+		return 0
+	}
+	return t.requestsLocked(symbol, time.Now())
+}
+
+// TTL 按币种热度调整后的缓存时间，未启用或base不为正时返回base
+func (t *Tracker) TTL(symbol string, base time.Duration) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.config.Enabled || base <= 0 {
+		return base
+	}
+	return t.clampLocked(time.Duration(float64(base) * t.factorLocked(t.requestsLocked(symbol, time.Now()))))
+}
+
+// MaxTTL 任意币种可能使用的最长缓存时间，用于设置缓存key的过期时间
+func (t *Tracker) MaxTTL(base time.Duration) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.config.Enabled || base <= 0 {
+		return base
+	}
+	return t.clampLocked(time.Duration(float64(base) * t.config.MaxFactor))
+}
+
+// rotateLocked 进入新窗口时当前计数变为上一窗口，超过两个窗口没有请求时全部清空
+func (t *Tracker) rotateLocked(now time.Time) {
+	elapsed := now.Sub(t.windowStart)
+	if elapsed < t.config.Window {
+		return
+	}
+	if elapsed < 2*t.config.Window {
+		t.previous = t.current
+	} else {
+		t.previous = make(map[string]int64)
+	}
+	t.current = make(map[string]int64)
+	t.windowStart = now
+}
+
+// requestsLocked 当前窗口的计数加上一窗口计数中仍落在滑动窗口内的部分
+func (t *Tracker) requestsLocked(symbol string, now time.Time) float64 {
+	t.rotateLocked(now)
+	overlap := 1 - float64(now.Sub(t.windowStart))/float64(t.config.Window)
+	return float64(t.current[symbol]) + float64(t.previous[symbol])*overlap
+}
+
+// factorLocked 请求次数不超过cold_requests时为max_factor，达到hot_requests时为min_factor，之间按对数插值
+func (t *Tracker) factorLocked(requests float64) float64 {
+	cold, hot := float64(t.config.ColdRequests), float64(t.config.HotRequests)
+	switch {
+	case requests <= cold:
+		return t.config.MaxFactor
+	case requests >= hot:
+		return t.config.MinFactor
+	}
+	position := (math.Log1p(requests) - math.Log1p(cold)) / (math.Log1p(hot) - math.Log1p(cold))
+	return t.config.MaxFactor * math.Pow(t.config.MinFactor/t.config.MaxFactor, position)
+}
+
+// clampLocked 限制在min_ttl和max_ttl之间
+func (t *Tracker) clampLocked(ttl time.Duration) time.Duration {
+	if t.config.MinTTL > 0 && ttl < t.config.MinTTL {
+		ttl = t.config.MinTTL
+	}
+	if t.config.MaxTTL > 0 && ttl > t.config.MaxTTL {
+		ttl = t.config.MaxTTL
+	}
+	return ttl
+}
@@ -0,0 +1,147 @@
+// Package precision 价格显示精度规则
+//
+// 配置了固定小数位的币种(如BTC保留2位)按配置输出，其余按有效数字位数确定小数位，
+// 小币种价格(如0.000000123)因此保留足够的小数位，不会被截断为0或输出为科学计数法。
+package precision
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"crypto-info/internal/config"
+)
+
+// 未配置时的默认规则
+const (
+	defaultSignificantDigits = 6
+	defaultMinDecimals       = 2
+	defaultMaxDecimals       = 12
+)
+
+// rules 精度规则
+type rules struct {
+	significantDigits int
+	minDecimals       int
+	maxDecimals       int
+	symbols           map[string]int
+}
+
+var current atomic.Pointer[rules]
+
+func init() {
+	Init(nil)
+}
+
+// Init 按配置设置全局精度规则，cfg为空时使用默认规则
+func Init(cfg *config.Precision) {
+	r := &rules{
+		significantDigits: defaultSignificantDigits,
+		minDecimals:       defaultMinDecimals,
+		maxDecimals:       defaultMaxDecimals,
+		symbols:           make(map[string]int),
+	}
+	if cfg != nil {
+		if cfg.SignificantDigits > 0 {
+			r.significantDigits = cfg.SignificantDigits
+		}
+		if cfg.MinDecimals > 0 {
+			r.minDecimals = cfg.MinDecimals
+		}
+		if cfg.MaxDecimals > 0 {
+			r.maxDecimals = cfg.MaxDecimals
+		}
+		// 配置文件的map key会被转为小写，统一按大写币种匹配
+		for symbol, decimals := range cfg.Symbols {
+			r.symbols[strings.ToUpper(symbol)] = decimals
+		}
+	}
+	if r.minDecimals > r.maxDecimals {
+		r.minDecimals = r.maxDecimals
+	}
+	current.Store(r)
+}
+
+// Decimals 币种价格的小数位数
+func Decimals(symbol string, value float64) int {
+	r := current.Load()
+	if decimals, ok := r.symbols[strings.ToUpper(symbol)]; ok {
+		return decimals
+	}
+
+	abs := math.Abs(value)
+	if abs == 0 || math.IsNaN(abs) || math.IsInf(abs, 0) {
+		return r.minDecimals
+	}
+	// 整数部分位数，小于1时为负数，表示小数点后的前导零个数
+	magnitude := int(math.Floor(math.Log10(abs))) + 1
+	decimals := r.significantDigits - magnitude
+	if decimals < r.minDecimals {
+		return r.minDecimals
+	}
+	if decimals > r.maxDecimals {
+		return r.maxDecimals
+	}
+	return decimals
+}
+
+// Format 按币种精度格式化价格，不使用科学计数法
+func Format(symbol string, value float64) string {
+	return strconv.FormatFloat(value, 'f', Decimals(symbol, value), 64)
+}
+
+// localeSeparators 按语言的千位分隔符和小数点，地区后缀(如de-AT)按语言处理
+var localeSeparators = map[string][2]string{
+	"en": {",", "."},
+	"zh": {",", "."},
+	"ja": {",", "."},
+	"ko": {",", "."},
+	"de": {".", ","},
+	"es": {".", ","},
+	"it": {".", ","},
+	"pt": {".", ","},
+	"fr": {" ", ","},
+	"ru": {" ", ","},
+}
+
+// SupportedLocale locale是否支持，格式为语言或语言-地区，如zh-CN
+func SupportedLocale(locale string) bool {
+	_, ok := localeSeparators[localeLanguage(locale)]
+	return ok
+}
+
+// FormatLocale 按币种精度和locale的分隔符格式化价格，不支持的locale按Format输出
+func FormatLocale(symbol string, value float64, locale string) string {
+	formatted := Format(symbol, value)
+	separators, ok := localeSeparators[localeLanguage(locale)]
+	if !ok {
+		return formatted
+	}
+
+	sign := ""
+	if strings.HasPrefix(formatted, "-") {
+		sign, formatted = "-", formatted[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(formatted, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(separators[0])
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		b.WriteString(separators[1])
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// localeLanguage 取locale的语言部分，支持-和_分隔
+func localeLanguage(locale string) string {
+	language, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	return strings.ToLower(language)
+}
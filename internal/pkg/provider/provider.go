@@ -0,0 +1,96 @@
+// Package provider 行情数据源的统一接口
+//
+// 各交易所客户端通过实现PriceProvider和VolumeProvider接入价格和交易量服务。实现需满足providertest中的符合性检查：
+// 币种名称不区分大小写和首尾空格、返回的币种统一为大写；调用遵守ctx的截止时间和取消；
+// 失败时返回可用errors.Is判断的错误(ErrUnsupportedSymbol、ErrRateLimited、ErrUnavailable或ctx的错误)。
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnsupportedSymbol 数据源不支持该币种
+	ErrUnsupportedSymbol = errors.New("provider: unsupported symbol")
+	// ErrRateLimited 触发数据源限流
+	ErrRateLimited = errors.New("provider: rate limited")
+	// ErrUnavailable 数据源不可用(网络错误、5xx或无法解析的响应)
+	ErrUnavailable = errors.New("provider: upstream unavailable")
+)
+
+// Quote 币种最新价格
+type Quote struct {
+	Symbol   string    // 币种，大写，如BTC
+	Price    float64   // 最新价格
+	Currency string    // 计价币种，如USDT
+	Time     time.Time // 价格时间
+	Stats    *Stats24h // 行情中的24小时统计，数据源不提供时为nil
+}
+
+// Stats24h 24小时行情统计
+type Stats24h struct {
+	Open        float64 // 24小时前的开盘价
+	High        float64 // 24小时最高价
+	Low         float64 // 24小时最低价
+	Volume      float64 // 以币种计的成交量
+	QuoteVolume float64 // 以计价币种计的成交额
+}
+
+// ChangePct 最新价格相对开盘价的涨跌幅(百分比)，开盘价为0时返回0
+func (s *Stats24h) ChangePct(price float64) float64 {
+	if s.Open == 0 {
+		return 0
+	}
+	return (price - s.Open) / s.Open * 100
+}
+
+// DailyVolume 单日交易量
+type DailyVolume struct {
+	Date        time.Time // 当日零点(UTC)
+	Volume      float64   // 以币种计的成交量
+	QuoteVolume float64   // 以计价币种计的成交额
+}
+
+// Candle K线
+type Candle struct {
+	OpenTime    time.Time // 开盘时间(UTC)
+	Open        float64
+	High        float64
+	Low         float64
+	Close       float64
+	Volume      float64 // 以币种计的成交量
+	QuoteVolume float64 // 以计价币种计的成交额
+}
+
+// PriceProvider 价格数据源
+type PriceProvider interface {
+	// Name 数据源名称，与 external_api 下的配置名称一致
+	Name() string
+	// GetPrice 获取币种(如BTC)的最新价格
+	GetPrice(ctx context.Context, symbol string) (*Quote, error)
+}
+
+// VolumeProvider 交易量数据源
+type VolumeProvider interface {
+	// Name 数据源名称，与 external_api 下的配置名称一致
+	Name() string
+	// GetDailyVolumes 获取币种最近days天(含当天)的日交易量，按日期升序
+	GetDailyVolumes(ctx context.Context, symbol string, days int) ([]DailyVolume, error)
+}
+
+// CandleProvider K线数据源
+type CandleProvider interface {
+	// Name 数据源名称，与 external_api 下的配置名称一致
+	Name() string
+	// GetCandles 获取币种最近limit根K线(含未完结的当前K线)，interval为K线周期，按开盘时间升序；
+	// limit超过数据源单次请求上限时只返回上限数量
+	GetCandles(ctx context.Context, symbol string, interval time.Duration, limit int) ([]Candle, error)
+}
+
+// NormalizeSymbol 统一币种名称：去除首尾空格并转为大写
+func NormalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,367 @@
+// Package providertest 行情数据源的符合性检查
+//
+// 每个PriceProvider/VolumeProvider实现都应通过同一组检查：以录制文件(见fixture包)模拟上游，
+// 验证币种名称统一、返回值完整、不支持的币种返回ErrUnsupportedSymbol，并用慢响应和错误响应的上游
+// 验证截止时间和取消被遵守、限流和故障返回ErrRateLimited和ErrUnavailable。
+// 检查结果以与启动自检相同的JSON报告输出，见 cmd/conformance。
+package providertest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"crypto-info/internal/pkg/fixture"
+	"crypto-info/internal/pkg/provider"
+)
+
+// 检查结果状态
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip" // 数据源未实现对应接口
+)
+
+// defaultTimeout 截止时间检查默认使用的超时时间
+const defaultTimeout = 200 * time.Millisecond
+
+// Suite 符合性检查配置
+type Suite struct {
+	FixtureDir  string        // 录制文件目录，按提供方分子目录
+	Symbols     []string      // 已录制价格和交易量的币种
+	Unsupported string        // 已录制的不受支持币种
+	Days        int           // 已录制的交易量天数
+	Timeout     time.Duration // 截止时间检查使用的超时时间，为0时使用200ms
+}
+
+// Target 被检查的数据源，NewPrice和NewVolume以上游地址创建实例，未实现的接口为空
+// 创建的实例不应重试或只短暂重试，否则错误响应的检查可能超出截止时间
+type Target struct {
+	Name      string // 提供方名称，与录制目录一致
+	NewPrice  func(baseURL string) provider.PriceProvider
+	NewVolume func(baseURL string) provider.VolumeProvider
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report 单个数据源的检查报告
+type Report struct {
+	Provider   string        `json:"provider"`
+	Status     string        `json:"status"`
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMs int64         `json:"duration_ms"`
+	Checks     []CheckResult `json:"checks"`
+}
+
+// Passed 是否没有失败的检查
+func (r *Report) Passed() bool {
+	return r.Status == StatusPass
+}
+
+// Write 以JSON格式输出报告
+func (r *Report) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// check 单项检查
+type check struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// upstreams 检查使用的模拟上游
+type upstreams struct {
+	fixtures    *httptest.Server // 回放录制文件
+	slow        *httptest.Server // 直到请求取消才返回
+	unavailable *httptest.Server // 返回503
+	rateLimited *httptest.Server // 返回429
+}
+
+// Run 对数据源运行全部检查
+func Run(ctx context.Context, suite Suite, target Target) *Report {
+	if suite.Timeout <= 0 {
+		suite.Timeout = defaultTimeout
+	}
+
+	up := startUpstreams(suite, target.Name)
+	defer up.close()
+
+	report := &Report{Provider: target.Name, Status: StatusPass, StartedAt: time.Now()}
+	for _, c := range priceChecks(suite, target, up) {
+		report.add(ctx, c, target.NewPrice == nil)
+	}
+	for _, c := range volumeChecks(suite, target, up) {
+		report.add(ctx, c, target.NewVolume == nil)
+	}
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+// add 运行单项检查并记录结果，skip为true时只记录跳过
+func (r *Report) add(ctx context.Context, c check, skip bool) {
+	result := CheckResult{Name: c.name, Status: StatusSkip}
+	if !skip {
+		start := time.Now()
+		err := c.run(ctx)
+		result.DurationMs = time.Since(start).Milliseconds()
+		result.Status = StatusPass
+		if err != nil {
+			result.Status = StatusFail
+			result.Error = err.Error()
+			r.Status = StatusFail
+		}
+	}
+	r.Checks = append(r.Checks, result)
+}
+
+// priceChecks 价格数据源的检查
+func priceChecks(suite Suite, target Target, up *upstreams) []check {
+	newPrice := func(server *httptest.Server) provider.PriceProvider {
+		return target.NewPrice(server.URL)
+	}
+	getPrice := func(server *httptest.Server) func(ctx context.Context, symbol string) error {
+		return func(ctx context.Context, symbol string) error {
+			_, err := newPrice(server).GetPrice(ctx, symbol)
+			return err
+		}
+	}
+
+	return []check{
+		{name: "price/name", run: func(ctx context.Context) error {
+			return checkName(newPrice(up.fixtures).Name(), target.Name)
+		}},
+		{name: "price/quote", run: func(ctx context.Context) error {
+			p := newPrice(up.fixtures)
+			for _, symbol := range suite.Symbols {
+				for _, variant := range symbolVariants(symbol) {
+					quote, err := p.GetPrice(ctx, variant)
+					if err != nil {
+						return fmt.Errorf("GetPrice(%q): %w", variant, err)
+					}
+					if err := checkQuote(quote, symbol); err != nil {
+						return fmt.Errorf("GetPrice(%q): %w", variant, err)
+					}
+				}
+			}
+			return nil
+		}},
+		{name: "price/unsupported_symbol", run: func(ctx context.Context) error {
+			return checkUnsupported(ctx, getPrice(up.fixtures), suite.Unsupported)
+		}},
+		{name: "price/deadline", run: func(ctx context.Context) error {
+			return checkDeadline(ctx, getPrice(up.slow), suite)
+		}},
+		{name: "price/canceled", run: func(ctx context.Context) error {
+			return checkCanceled(ctx, getPrice(up.slow), suite)
+		}},
+		{name: "price/unavailable", run: func(ctx context.Context) error {
+			return checkTyped(ctx, getPrice(up.unavailable), suite, provider.ErrUnavailable)
+		}},
+		{name: "price/rate_limited", run: func(ctx context.Context) error {
+			return checkTyped(ctx, getPrice(up.rateLimited), suite, provider.ErrRateLimited)
+		}},
+	}
+}
+
+// volumeChecks 交易量数据源的检查
+func volumeChecks(suite Suite, target Target, up *upstreams) []check {
+	newVolume := func(server *httptest.Server) provider.VolumeProvider {
+		return target.NewVolume(server.URL)
+	}
+	getVolumes := func(server *httptest.Server) func(ctx context.Context, symbol string) error {
+		return func(ctx context.Context, symbol string) error {
+			_, err := newVolume(server).GetDailyVolumes(ctx, symbol, suite.Days)
+			return err
+		}
+	}
+
+	return []check{
+		{name: "volume/name", run: func(ctx context.Context) error {
+			return checkName(newVolume(up.fixtures).Name(), target.Name)
+		}},
+		{name: "volume/daily", run: func(ctx context.Context) error {
+			p := newVolume(up.fixtures)
+			for _, symbol := range suite.Symbols {
+				for _, variant := range symbolVariants(symbol) {
+					volumes, err := p.GetDailyVolumes(ctx, variant, suite.Days)
+					if err != nil {
+						return fmt.Errorf("GetDailyVolumes(%q): %w", variant, err)
+					}
+					if err := checkVolumes(volumes, suite.Days); err != nil {
+						return fmt.Errorf("GetDailyVolumes(%q): %w", variant, err)
+					}
+				}
+			}
+			return nil
+		}},
+		{name: "volume/unsupported_symbol", run: func(ctx context.Context) error {
+			return checkUnsupported(ctx, getVolumes(up.fixtures), suite.Unsupported)
+		}},
+		{name: "volume/deadline", run: func(ctx context.Context) error {
+			return checkDeadline(ctx, getVolumes(up.slow), suite)
+		}},
+		{name: "volume/canceled", run: func(ctx context.Context) error {
+			return checkCanceled(ctx, getVolumes(up.slow), suite)
+		}},
+		{name: "volume/unavailable", run: func(ctx context.Context) error {
+			return checkTyped(ctx, getVolumes(up.unavailable), suite, provider.ErrUnavailable)
+		}},
+	}
+}
+
+// checkName 数据源名称与提供方名称一致
+func checkName(got, want string) error {
+	if got != want {
+		return fmt.Errorf("Name() = %q, want %q", got, want)
+	}
+	return nil
+}
+
+// checkQuote 价格的币种已统一、价格为正且计价币种和时间不为空
+func checkQuote(quote *provider.Quote, symbol string) error {
+	want := provider.NormalizeSymbol(symbol)
+	switch {
+	case quote == nil:
+		return errors.New("nil quote without error")
+	case quote.Symbol != want:
+		return fmt.Errorf("symbol = %q, want %q", quote.Symbol, want)
+	case quote.Price <= 0:
+		return fmt.Errorf("price = %v, want positive", quote.Price)
+	case quote.Currency == "":
+		return errors.New("empty currency")
+	case quote.Time.IsZero():
+		return errors.New("zero time")
+	}
+	return nil
+}
+
+// checkVolumes 交易量不超过请求的天数、按日期升序且数值非负
+func checkVolumes(volumes []provider.DailyVolume, days int) error {
+	if len(volumes) == 0 || len(volumes) > days {
+		return fmt.Errorf("got %d days, want 1 to %d", len(volumes), days)
+	}
+	for i, v := range volumes {
+		if v.Volume < 0 || v.QuoteVolume < 0 {
+			return fmt.Errorf("negative volume on %s", v.Date.Format(time.DateOnly))
+		}
+		if i > 0 && !v.Date.After(volumes[i-1].Date) {
+			return fmt.Errorf("dates not ascending at %s", v.Date.Format(time.DateOnly))
+		}
+	}
+	return nil
+}
+
+// checkUnsupported 不支持的币种和空币种返回ErrUnsupportedSymbol
+func checkUnsupported(ctx context.Context, call func(context.Context, string) error, unsupported string) error {
+	for _, symbol := range []string{unsupported, "", "BTC/USDT"} {
+		if err := call(ctx, symbol); !errors.Is(err, provider.ErrUnsupportedSymbol) {
+			return fmt.Errorf("symbol %q: got error %v, want ErrUnsupportedSymbol", symbol, err)
+		}
+	}
+	return nil
+}
+
+// checkDeadline 上游无响应时在截止时间后及时返回context.DeadlineExceeded
+func checkDeadline(ctx context.Context, call func(context.Context, string) error, suite Suite) error {
+	ctx, cancel := context.WithTimeout(ctx, suite.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := call(ctx, firstSymbol(suite))
+	elapsed := time.Since(start)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed > 2*suite.Timeout {
+		return fmt.Errorf("returned after %s, deadline was %s", elapsed, suite.Timeout)
+	}
+	return nil
+}
+
+// checkCanceled 请求进行中取消时及时返回context.Canceled
+func checkCanceled(ctx context.Context, call func(context.Context, string) error, suite Suite) error {
+	ctx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(suite.Timeout/2, cancel)
+	defer timer.Stop()
+	defer cancel()
+
+	start := time.Now()
+	err := call(ctx, firstSymbol(suite))
+	elapsed := time.Since(start)
+	if !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("got error %v, want context.Canceled", err)
+	}
+	if elapsed > 2*suite.Timeout {
+		return fmt.Errorf("returned after %s, canceled after %s", elapsed, suite.Timeout/2)
+	}
+	return nil
+}
+
+// checkTyped 上游返回错误状态时返回want类型的错误，且不超出截止时间重试
+func checkTyped(ctx context.Context, call func(context.Context, string) error, suite Suite, want error) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*suite.Timeout)
+	defer cancel()
+
+	if err := call(ctx, firstSymbol(suite)); !errors.Is(err, want) {
+		return fmt.Errorf("got error %v, want %v", err, want)
+	}
+	return nil
+}
+
+// symbolVariants 同一币种的不同写法，数据源应返回相同的结果
+func symbolVariants(symbol string) []string {
+	return []string{symbol, strings.ToLower(symbol), " " + symbol + " "}
+}
+
+// firstSymbol 错误响应检查使用的币种
+func firstSymbol(suite Suite) string {
+	if len(suite.Symbols) == 0 {
+		return "BTC"
+	}
+	return suite.Symbols[0]
+}
+
+// startUpstreams 启动模拟上游
+func startUpstreams(suite Suite, name string) *upstreams {
+	stall := 10 * suite.Timeout
+	return &upstreams{
+		fixtures: httptest.NewServer(fixture.Handler(suite.FixtureDir, name)),
+		slow: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(stall):
+			}
+		})),
+		unavailable: httptest.NewServer(statusHandler(http.StatusServiceUnavailable)),
+		rateLimited: httptest.NewServer(statusHandler(http.StatusTooManyRequests)),
+	}
+}
+
+// statusHandler 始终返回指定状态码的上游
+func statusHandler(status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, http.StatusText(status), status)
+	})
+}
+
+// close 关闭模拟上游
+func (u *upstreams) close() {
+	u.fixtures.Close()
+	u.slow.Close()
+	u.unavailable.Close()
+	u.rateLimited.Close()
+}
@@ -0,0 +1,60 @@
+package providertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"crypto-info/internal/pkg/provider"
+)
+
+// brokenProvider 不访问上游、不统一币种名称且返回无类型错误的价格数据源
+type brokenProvider struct{}
+
+func (brokenProvider) Name() string { return "someone-else" }
+
+func (brokenProvider) GetPrice(ctx context.Context, symbol string) (*provider.Quote, error) {
+	if symbol == "BTC" {
+		return &provider.Quote{Symbol: "btc", Price: 1, Currency: "USDT", Time: time.Now()}, nil
+	}
+	return nil, errors.New("boom")
+}
+
+// TestRunReportsViolations 检查能发现不合规的实现，未实现的接口记为跳过
+func TestRunReportsViolations(t *testing.T) {
+	suite := Suite{FixtureDir: t.TempDir(), Symbols: []string{"BTC"}, Unsupported: "FOO", Days: 7, Timeout: 50 * time.Millisecond}
+	target := Target{
+		Name:     "broken",
+		NewPrice: func(string) provider.PriceProvider { return brokenProvider{} },
+	}
+
+	report := Run(context.Background(), suite, target)
+	if report.Passed() {
+		t.Fatal("report passed for a non-conforming provider")
+	}
+
+	want := map[string]string{
+		"price/name":                StatusFail,
+		"price/quote":               StatusFail,
+		"price/unsupported_symbol":  StatusFail,
+		"price/deadline":            StatusFail,
+		"price/canceled":            StatusFail,
+		"price/unavailable":         StatusFail,
+		"price/rate_limited":        StatusFail,
+		"volume/name":               StatusSkip,
+		"volume/daily":              StatusSkip,
+		"volume/unsupported_symbol": StatusSkip,
+		"volume/deadline":           StatusSkip,
+		"volume/canceled":           StatusSkip,
+		"volume/unavailable":        StatusSkip,
+	}
+	if len(report.Checks) != len(want) {
+		t.Fatalf("got %d checks, want %d", len(report.Checks), len(want))
+	}
+	for _, check := range report.Checks {
+		if check.Status != want[check.Name] {
+			t.Errorf("%s: status = %s, want %s (error: %s)", check.Name, check.Status, want[check.Name], check.Error)
+		}
+	}
+}
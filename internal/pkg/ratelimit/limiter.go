@@ -0,0 +1,255 @@
+// Package ratelimit 进程内按key(如客户端IP)的令牌桶限流
+//
+// 每个key的状态保存在分片map中，定时清理长时间未访问的key；清理间隔带随机抖动，
+// 且逐个分片加锁，避免多实例或大量key时清理集中占用锁导致请求延迟尖刺。
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+)
+
+// 限流器默认配置
+const (
+	defaultCleanupInterval = time.Minute
+	shardCount             = 16
+	cleanupJitter          = 0.2 // 清理间隔上下浮动的比例
+)
+
+// Limiter 按key的令牌桶限流器
+type Limiter struct {
+	rate            float64 // 每秒补充的令牌数
+	burst           float64 // 桶容量
+	cleanupInterval time.Duration
+	shards          [shardCount]shard
+	logger          logger.Logger
+
+	allowed  atomic.Uint64
+	rejected atomic.Uint64
+	evicted  atomic.Uint64
+
+	// 最近一个清理周期的拒绝比例
+	windowMu       sync.Mutex
+	windowAllowed  uint64
+	windowRejected uint64
+	rejectionRatio float64
+
+	runMutex sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// shard 分片
+type shard struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket 单个key的令牌桶
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Stats 限流器统计
+type Stats struct {
+	Keys           int     // 当前跟踪的key数量
+	Allowed        uint64  // 累计放行的请求数
+	Rejected       uint64  // 累计拒绝的请求数
+	Evicted        uint64  // 累计清理的key数量
+	RejectionRatio float64 // 最近一个清理周期内被拒绝请求的比例
+}
+
+// New 按配置创建限流器，requests_per_second无效时返回错误
+func New(cfg *config.RateLimit) (*Limiter, error) {
+	if cfg.RequestsPerSecond <= 0 {
+		return nil, fmt.Errorf("rate_limit.requests_per_second must be positive, got %d", cfg.RequestsPerSecond)
+	}
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = cfg.RequestsPerSecond
+	}
+	interval := cfg.CleanupInterval
+	if interval <= 0 {
+		interval = defaultCleanupInterval
+	}
+
+	l := &Limiter{
+		rate:            float64(cfg.RequestsPerSecond),
+		burst:           float64(burst),
+		cleanupInterval: interval,
+		logger:          logger.GetLogger(),
+	}
+	for i := range l.shards {
+		l.shards[i].buckets = make(map[string]*bucket)
+	}
+	return l, nil
+}
+
+// Allow 消耗key的一个令牌，令牌不足时返回false
+func (l *Limiter) Allow(key string) bool {
+	now := time.Now()
+	s := l.shard(key)
+
+	s.mu.Lock()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		s.buckets[key] = b
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.last = now
+	}
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	s.mu.Unlock()
+
+	if allowed {
+		l.allowed.Add(1)
+	} else {
+		l.rejected.Add(1)
+	}
+	return allowed
+}
+
+// Stats 当前统计
+func (l *Limiter) Stats() Stats {
+	keys := 0
+	for i := range l.shards {
+		s := &l.shards[i]
+		s.mu.Lock()
+		keys += len(s.buckets)
+		s.mu.Unlock()
+	}
+
+	l.windowMu.Lock()
+	ratio := l.rejectionRatio
+	l.windowMu.Unlock()
+
+	return Stats{
+		Keys:           keys,
+		Allowed:        l.allowed.Load(),
+		Rejected:       l.rejected.Load(),
+		Evicted:        l.evicted.Load(),
+		RejectionRatio: ratio,
+	}
+}
+
+// Start 启动定时清理
+func (l *Limiter) Start(ctx context.Context) error {
+	l.runMutex.Lock()
+	defer l.runMutex.Unlock()
+
+	if l.running {
+		return fmt.Errorf("rate limiter cleanup is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	l.cancel = cancel
+	l.done = make(chan struct{})
+	l.running = true
+
+	panics.Supervise(ctx, "rate_limit_cleanup", l.run, l.done)
+
+	l.logger.Infof("Rate limiter cleanup started with interval %s", l.cleanupInterval)
+	return nil
+}
+
+// Stop 停止定时清理
+func (l *Limiter) Stop() error {
+	l.runMutex.Lock()
+	defer l.runMutex.Unlock()
+
+	if !l.running {
+		return nil
+	}
+
+	l.cancel()
+	<-l.done
+	l.running = false
+
+	l.logger.Info("Rate limiter cleanup stopped")
+	return nil
+}
+
+// run 按带抖动的间隔清理过期key
+func (l *Limiter) run(ctx context.Context) {
+	for {
+		timer := time.NewTimer(l.jitteredInterval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if evicted := l.cleanup(time.Now()); evicted > 0 {
+			l.logger.Debugf("Rate limiter evicted %d idle keys", evicted)
+		}
+		l.updateRejectionRatio()
+	}
+}
+
+// cleanup 移除桶已补满的key，这些key再次访问时与新key等价；逐个分片加锁
+func (l *Limiter) cleanup(now time.Time) int {
+	idle := time.Duration(l.burst / l.rate * float64(time.Second))
+	evicted := 0
+	for i := range l.shards {
+		s := &l.shards[i]
+		s.mu.Lock()
+		for key, b := range s.buckets {
+			if now.Sub(b.last) >= idle {
+				delete(s.buckets, key)
+				evicted++
+			}
+		}
+		s.mu.Unlock()
+	}
+	l.evicted.Add(uint64(evicted))
+	return evicted
+}
+
+// updateRejectionRatio 计算上一个清理周期内的拒绝比例
+func (l *Limiter) updateRejectionRatio() {
+	allowed, rejected := l.allowed.Load(), l.rejected.Load()
+
+	l.windowMu.Lock()
+	defer l.windowMu.Unlock()
+
+	deltaAllowed := allowed - l.windowAllowed
+	deltaRejected := rejected - l.windowRejected
+	l.windowAllowed, l.windowRejected = allowed, rejected
+	if total := deltaAllowed + deltaRejected; total > 0 {
+		l.rejectionRatio = float64(deltaRejected) / float64(total)
+	} else {
+		l.rejectionRatio = 0
+	}
+}
+
+// jitteredInterval 清理间隔上下浮动cleanupJitter，避免多个实例同时清理
+func (l *Limiter) jitteredInterval() time.Duration {
+	factor := 1 + cleanupJitter*(2*rand.Float64()-1)
+	return time.Duration(float64(l.cleanupInterval) * factor)
+}
+
+// shard 按key的哈希选择分片
+func (l *Limiter) shard(key string) *shard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &l.shards[h.Sum32()%shardCount]
+}
@@ -0,0 +1,462 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+)
+
+// 调度器默认配置
+const (
+	defaultHistorySize = 20
+	everyPrefix        = "@every "
+	lockKeyPrefix      = "jobs:lock:"
+	historyKeyPrefix   = "jobs:history:"
+	// singletonLockTTL 计划执行锁的保留时间，需覆盖各实例之间的时钟偏差和随机延迟
+	singletonLockTTL = 10 * time.Minute
+)
+
+var (
+	// ErrJobNotFound 任务不存在
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobRunning 任务正在当前实例执行
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// Job 定时任务
+type Job struct {
+	Name      string
+	Spec      string        // 5段式cron表达式、@daily等预定义表达式，或@every 5m形式的固定间隔
+	Jitter    time.Duration // 每次计划执行前增加[0, jitter)的随机延迟，避免多个实例或任务同时访问上游
+	Timeout   time.Duration // 单次执行超时，0表示不限制
+	Singleton bool          // 多实例部署时每次计划执行只在抢到Redis锁的实例运行，手动触发不受限制
+	Run       func(ctx context.Context) error
+}
+
+// timing 计算下次执行时间
+type timing interface {
+	Next(t time.Time) time.Time
+}
+
+// interval 固定间隔
+type interval time.Duration
+
+// Next 实现timing接口，按间隔对齐，各实例的计划执行时间一致
+func (i interval) Next(t time.Time) time.Time {
+	d := time.Duration(i)
+	return t.Truncate(d).Add(d)
+}
+
+// parseSpec 解析任务的执行计划
+func parseSpec(spec string) (timing, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, everyPrefix) {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, everyPrefix)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval in %q: %w", spec, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("interval in %q must be at least 1s", spec)
+		}
+		return interval(d), nil
+	}
+	return Parse(spec)
+}
+
+// entry 已注册的任务
+type entry struct {
+	job     Job
+	timing  timing
+	next    time.Time // 下次计划执行时间，未启动时为零值
+	running bool
+}
+
+// Scheduler 进程内定时任务调度器，执行记录保存在Redis，多个实例共享；Redis不可用时保存在进程内
+type Scheduler struct {
+	redisClient database.RedisClient
+	logger      logger.Logger
+	historySize int
+	location    *time.Location
+	instance    string
+
+	mu      sync.Mutex
+	jobs    map[string]*entry
+	names   []string
+	history map[string][]model.JobRun // Redis不可用时的执行记录，新记录在前
+
+	runMutex sync.Mutex
+	running  bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// Options 调度器参数
+type Options struct {
+	HistorySize int            // 每个任务保留的执行记录条数
+	Location    *time.Location // 计算cron表达式的时区，为空时使用UTC
+}
+
+// New 创建调度器，redisClient可为空
+func New(redisClient database.RedisClient, opts Options) *Scheduler {
+	if opts.HistorySize <= 0 {
+		opts.HistorySize = defaultHistorySize
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	instance, _ := os.Hostname()
+	return &Scheduler{
+		redisClient: redisClient,
+		logger:      logger.GetLogger(),
+		historySize: opts.HistorySize,
+		location:    opts.Location,
+		instance:    instance,
+		jobs:        make(map[string]*entry),
+		history:     make(map[string][]model.JobRun),
+	}
+}
+
+// Register 注册任务，需在Start之前调用
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("job name and run function are required")
+	}
+	t, err := parseSpec(job.Spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+	s.jobs[job.Name] = &entry{job: job, timing: t}
+	s.names = append(s.names, job.Name)
+	return nil
+}
+
+// Start 按计划执行已注册的任务
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("scheduler is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.ctx = ctx
+	s.cancel = cancel
+	s.running = true
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range s.names {
+		e := s.jobs[name]
+		s.wg.Add(1)
+		panics.Go("job_"+name, func() {
+			defer s.wg.Done()
+			s.loop(ctx, e)
+		})
+	}
+
+	s.logger.Infof("Scheduler started with %d jobs", len(s.names))
+	return nil
+}
+
+// Stop 停止调度并等待正在执行的任务结束
+func (s *Scheduler) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	s.wg.Wait()
+	s.running = false
+
+	s.logger.Info("Scheduler stopped")
+	return nil
+}
+
+// loop 按计划循环执行单个任务
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		tick := e.timing.Next(time.Now().In(s.location))
+		if tick.IsZero() {
+			s.logger.Warnf("Job %s has no upcoming run, stopping its schedule", e.job.Name)
+			return
+		}
+		s.mu.Lock()
+		e.next = tick
+		s.mu.Unlock()
+
+		wait := time.Until(tick)
+		if e.job.Jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(e.job.Jitter)))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !s.acquire(e) {
+			s.logger.Debugf("Job %s is still running, skipping run at %s", e.job.Name, tick.Format(time.RFC3339))
+			continue
+		}
+		if e.job.Singleton && !s.lock(ctx, e.job.Name, tick) {
+			s.release(e)
+			continue
+		}
+		s.execute(ctx, e, model.JobTriggerSchedule)
+	}
+}
+
+// Trigger 立即在后台执行任务，返回执行记录，任务正在当前实例执行时返回ErrJobRunning
+func (s *Scheduler) Trigger(name string) (*model.JobRun, error) {
+	run, _, err := s.trigger(name)
+	return run, err
+}
+
+// TriggerWait 立即执行任务并等待结束，返回最终的执行记录；ctx结束时任务继续在后台执行，返回执行中的记录
+func (s *Scheduler) TriggerWait(ctx context.Context, name string) (*model.JobRun, error) {
+	run, done, err := s.trigger(name)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case finished := <-done:
+		return &finished, nil
+	case <-ctx.Done():
+		return run, nil
+	}
+}
+
+// trigger 在后台执行任务，任务结束后最终的执行记录写入返回的channel
+func (s *Scheduler) trigger(name string) (*model.JobRun, <-chan model.JobRun, error) {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	if !s.acquire(e) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrJobRunning, name)
+	}
+
+	s.runMutex.Lock()
+	ctx := context.Background()
+	if s.running {
+		ctx = s.ctx
+	}
+	s.wg.Add(1)
+	s.runMutex.Unlock()
+
+	run := s.newRun(name, model.JobTriggerManual)
+	done := make(chan model.JobRun, 1)
+	panics.Go("job_"+name, func() {
+		defer s.wg.Done()
+		done <- s.finish(ctx, e, run)
+	})
+	return &run, done, nil
+}
+
+// Jobs 已注册任务及其最近一次执行，按注册顺序
+func (s *Scheduler) Jobs(ctx context.Context) []model.JobInfo {
+	s.mu.Lock()
+	jobs := make([]model.JobInfo, 0, len(s.names))
+	for _, name := range s.names {
+		e := s.jobs[name]
+		info := model.JobInfo{
+			Name:      name,
+			Schedule:  e.job.Spec,
+			Singleton: e.job.Singleton,
+			Running:   e.running,
+		}
+		if e.job.Jitter > 0 {
+			info.Jitter = e.job.Jitter.String()
+		}
+		if e.job.Timeout > 0 {
+			info.Timeout = e.job.Timeout.String()
+		}
+		if !e.next.IsZero() {
+			next := e.next
+			info.NextRunAt = &next
+		}
+		jobs = append(jobs, info)
+	}
+	s.mu.Unlock()
+
+	for i := range jobs {
+		if runs, err := s.History(ctx, jobs[i].Name, 1); err == nil && len(runs) > 0 {
+			jobs[i].LastRun = &runs[0]
+		}
+	}
+	return jobs
+}
+
+// History 任务最近的执行记录，新记录在前，limit不超过保留的条数
+func (s *Scheduler) History(ctx context.Context, name string, limit int) ([]model.JobRun, error) {
+	s.mu.Lock()
+	_, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	if limit <= 0 || limit > s.historySize {
+		limit = s.historySize
+	}
+
+	if s.redisClient == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		runs := s.history[name]
+		if len(runs) > limit {
+			runs = runs[:limit]
+		}
+		return append([]model.JobRun(nil), runs...), nil
+	}
+
+	key := s.redisClient.KeyPrefix() + historyKeyPrefix + name
+	entries, err := s.redisClient.GetClient().LRange(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job history: %w", err)
+	}
+	runs := make([]model.JobRun, 0, len(entries))
+	for _, entry := range entries {
+		var run model.JobRun
+		if err := json.Unmarshal([]byte(entry), &run); err == nil {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// execute 执行一次任务，调用前需已通过acquire标记为执行中
+func (s *Scheduler) execute(ctx context.Context, e *entry, trigger string) {
+	s.finish(ctx, e, s.newRun(e.job.Name, trigger))
+}
+
+// finish 执行任务并保存执行记录，任务panic时记为失败，返回最终的执行记录
+func (s *Scheduler) finish(ctx context.Context, e *entry, run model.JobRun) model.JobRun {
+	defer s.release(e)
+
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+
+	err := s.call(ctx, e.job)
+	run.DurationMs = time.Since(run.StartedAt).Milliseconds()
+	run.Status = model.JobRunSuccess
+	if err != nil {
+		run.Status = model.JobRunFailed
+		run.Error = err.Error()
+		s.logger.Errorf("Job %s failed after %dms: %v", run.Job, run.DurationMs, err)
+	} else {
+		s.logger.Infof("Job %s finished in %dms", run.Job, run.DurationMs)
+	}
+
+	s.record(context.WithoutCancel(ctx), run)
+	return run
+}
+
+// call 调用任务函数，将panic转为错误
+func (s *Scheduler) call(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panics.Capture(&panics.Report{Source: "job_" + job.Name}, recovered)
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// newRun 创建执行中的记录
+func (s *Scheduler) newRun(name, trigger string) model.JobRun {
+	return model.JobRun{
+		Job:       name,
+		Trigger:   trigger,
+		Status:    model.JobRunRunning,
+		Instance:  s.instance,
+		StartedAt: time.Now(),
+	}
+}
+
+// record 保存执行记录，只保留最近historySize条
+func (s *Scheduler) record(ctx context.Context, run model.JobRun) {
+	if s.redisClient == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		runs := append([]model.JobRun{run}, s.history[run.Job]...)
+		if len(runs) > s.historySize {
+			runs = runs[:s.historySize]
+		}
+		s.history[run.Job] = runs
+		return
+	}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return
+	}
+	key := s.redisClient.KeyPrefix() + historyKeyPrefix + run.Job
+	pipe := s.redisClient.GetClient().TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, int64(s.historySize-1))
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warnf("Failed to record run of job %s: %v", run.Job, err)
+	}
+}
+
+// lock 抢占单次计划执行，Redis不可用时视为单实例部署直接执行，Redis出错时跳过本次执行
+func (s *Scheduler) lock(ctx context.Context, name string, tick time.Time) bool {
+	if s.redisClient == nil {
+		return true
+	}
+	key := s.redisClient.KeyPrefix() + lockKeyPrefix + name + ":" + strconv.FormatInt(tick.Unix(), 10)
+	acquired, err := s.redisClient.GetClient().SetNX(ctx, key, s.instance, singletonLockTTL).Result()
+	if err != nil {
+		s.logger.Warnf("Failed to lock job %s, skipping run: %v", name, err)
+		return false
+	}
+	return acquired
+}
+
+// acquire 标记任务在当前实例执行中，已在执行时返回false
+func (s *Scheduler) acquire(e *entry) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.running {
+		return false
+	}
+	e.running = true
+	return true
+}
+
+// release 清除任务的执行中标记
+func (s *Scheduler) release(e *entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.running = false
+}
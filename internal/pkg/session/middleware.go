@@ -32,7 +32,7 @@ func Middleware(manager *Manager) gin.HandlerFunc {
 			return
 		}
 
-		log := logger.GetLogger()
+		log := logger.From(c)
 
 		// 从cookie中获取session ID
 		sessionID, err := c.Cookie(config.CookieName)
@@ -65,6 +65,16 @@ func Middleware(manager *Manager) gin.HandlerFunc {
 		// 设置session ID到context
 		c.Set(SessionIDKey, sessionID)
 
+		// 已登录会话的用户信息补充到请求级日志
+		if sess, ok := GetSession(c); ok {
+			if userID, ok := sess.Data["user_id"]; ok {
+				if _, exists := c.Get("user_id"); !exists {
+					c.Set("user_id", userID)
+					c.Set(logger.ContextKey, logger.From(c).WithField("user", userID))
+				}
+			}
+		}
+
 		// 设置cookie
 		setSessionCookie(c, config, sessionID)
 
@@ -197,4 +207,4 @@ func DestroySession(c *gin.Context, manager *Manager) error {
 	c.Set(SessionIDKey, "")
 
 	return nil
-}
\ No newline at end of file
+}
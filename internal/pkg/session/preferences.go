@@ -0,0 +1,59 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreferencesKey 在session数据中存储显示偏好的key
+const PreferencesKey = "preferences"
+
+// Preferences 客户端的显示偏好，请求未指定对应参数时使用
+type Preferences struct {
+	Currency string   `json:"currency,omitempty"` // 计价币种，对应quote_currency参数
+	Locale   string   `json:"locale,omitempty"`   // 数字格式的locale，如zh-CN、de-DE
+	Symbols  []string `json:"symbols,omitempty"`  // 默认币种，单币种接口使用第一个
+}
+
+// IsEmpty 是否未设置任何偏好
+func (p *Preferences) IsEmpty() bool {
+	return p.Currency == "" && p.Locale == "" && len(p.Symbols) == 0
+}
+
+// GetPreferences 从session获取显示偏好，未设置时返回false
+//
+// Redis存储的session反序列化后偏好为通用map，这里统一经JSON转换。
+func GetPreferences(c *gin.Context) (*Preferences, bool) {
+	value, exists := GetSessionData(c, PreferencesKey)
+	if !exists || value == nil {
+		return nil, false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var prefs Preferences
+	if err := json.Unmarshal(data, &prefs); err != nil || prefs.IsEmpty() {
+		return nil, false
+	}
+	return &prefs, true
+}
+
+// SetPreferences 保存显示偏好，偏好为空时删除
+func SetPreferences(c *gin.Context, prefs *Preferences) error {
+	session, exists := GetSession(c)
+	if !exists {
+		return errors.New("session not found")
+	}
+
+	if prefs == nil || prefs.IsEmpty() {
+		delete(session.Data, PreferencesKey)
+	} else {
+		session.Data[PreferencesKey] = prefs
+	}
+	session.UpdatedAt = time.Now()
+	return nil
+}
@@ -21,12 +21,12 @@ type RedisStore struct {
 }
 
 // NewRedisStore 创建Redis存储
-func NewRedisStore(client *redis.Client, cfg *config.SessionConfig, log logger.Logger) *RedisStore {
+func NewRedisStore(client *redis.Client, cfg *config.SessionConfig, keyPrefix string, log logger.Logger) *RedisStore {
 	return &RedisStore{
 		client: client,
 		config: cfg,
 		logger: log,
-		prefix: "session:",
+		prefix: keyPrefix + "session:",
 	}
 }
 
@@ -108,4 +108,4 @@ func (r *RedisStore) Close() error {
 // getKey 获取Redis key
 func (r *RedisStore) getKey(sessionID string) string {
 	return r.prefix + sessionID
-}
\ No newline at end of file
+}
@@ -44,8 +44,8 @@ type Manager struct {
 	logger logger.Logger
 }
 
-// NewManager 创建Session管理器
-func NewManager(cfg *config.SessionConfig, redisClient *redis.Client, log logger.Logger) (*Manager, error) {
+// NewManager 创建Session管理器，keyPrefix为Redis存储的key前缀
+func NewManager(cfg *config.SessionConfig, redisClient *redis.Client, keyPrefix string, log logger.Logger) (*Manager, error) {
 	if cfg == nil {
 		return nil, errors.New("session config is required")
 	}
@@ -57,7 +57,7 @@ func NewManager(cfg *config.SessionConfig, redisClient *redis.Client, log logger
 		if redisClient == nil {
 			return nil, errors.New("redis client is required for redis store")
 		}
-		store = NewRedisStore(redisClient, cfg, log)
+		store = NewRedisStore(redisClient, cfg, keyPrefix, log)
 	case "memory":
 		store = NewMemoryStore(cfg, log)
 	default:
@@ -196,4 +196,4 @@ func (m *Manager) Close() error {
 // GetConfig 获取Session配置
 func (m *Manager) GetConfig() *config.SessionConfig {
 	return m.config
-}
\ No newline at end of file
+}
@@ -0,0 +1,134 @@
+// Package shard 多实例之间按key分配任务
+//
+// 各实例定时在Redis有序集合中登记心跳，超过存活时间未登记的实例视为下线。所有实例用同一份成员列表构建一致性哈希环，
+// 每个key只属于一个实例；实例加入或下线时只有相邻区间的key改变归属。成员列表在各实例刷新的时刻可能短暂不一致，
+// 需要严格不重复的调用方仍应配合Redis锁使用。
+package shard
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"crypto-info/internal/pkg/database"
+)
+
+// 分片默认配置
+const (
+	defaultVirtualNodes = 64
+	membersKeyPrefix    = "shard:members:"
+)
+
+// Ring 一致性哈希环，每个成员在环上放置多个虚拟节点使分配更均匀
+type Ring struct {
+	members []string
+	hashes  []uint32
+	owners  map[uint32]string
+}
+
+// NewRing 由成员列表构建哈希环，virtualNodes不为正时使用默认值
+func NewRing(members []string, virtualNodes int) *Ring {
+	if virtualNodes <= 0 {
+		virtualNodes = defaultVirtualNodes
+	}
+	r := &Ring{
+		members: append([]string(nil), members...),
+		owners:  make(map[uint32]string, len(members)*virtualNodes),
+	}
+	sort.Strings(r.members)
+	for _, member := range r.members {
+		for i := 0; i < virtualNodes; i++ {
+			h := hashKey(member + "#" + strconv.Itoa(i))
+			if _, exists := r.owners[h]; exists {
+				continue
+			}
+			r.owners[h] = member
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+// Owner key所属的成员，环为空时返回空字符串
+func (r *Ring) Owner(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := hashKey(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
+
+// Members 环上的成员，按名称排序
+func (r *Ring) Members() []string {
+	return r.members
+}
+
+// hashKey 计算key在环上的位置
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// Membership 一组实例的成员登记，成员列表保存在Redis有序集合中，分数为最近一次心跳的时间
+type Membership struct {
+	redisClient database.RedisClient
+	key         string
+	instance    string
+	ttl         time.Duration
+}
+
+// NewMembership 创建成员登记，group区分不同的任务，ttl为心跳的存活时间
+func NewMembership(redisClient database.RedisClient, group string, ttl time.Duration) *Membership {
+	return &Membership{
+		redisClient: redisClient,
+		key:         membersKeyPrefix + group,
+		instance:    Instance(),
+		ttl:         ttl,
+	}
+}
+
+// Instance 当前实例的标识，由主机名和进程号组成
+func Instance() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return host + "-" + strconv.Itoa(os.Getpid())
+}
+
+// Self 当前实例在成员列表中的名称
+func (m *Membership) Self() string {
+	return m.instance
+}
+
+// Heartbeat 登记当前实例并返回存活的成员，同时清理已过期的成员
+func (m *Membership) Heartbeat(ctx context.Context) ([]string, error) {
+	now := time.Now()
+	if err := m.redisClient.ZAdd(ctx, m.key, float64(now.UnixMilli()), m.instance); err != nil {
+		return nil, fmt.Errorf("failed to register shard member: %w", err)
+	}
+	expired := strconv.FormatInt(now.Add(-m.ttl).UnixMilli(), 10)
+	if err := m.redisClient.ZRemRangeByScore(ctx, m.key, "-inf", "("+expired); err != nil {
+		return nil, fmt.Errorf("failed to expire shard members: %w", err)
+	}
+	members, err := m.redisClient.ZRangeByScore(ctx, m.key, expired, "+inf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shard members: %w", err)
+	}
+	return members, nil
+}
+
+// Leave 注销当前实例，其余实例下一次心跳后即接管它的key
+func (m *Membership) Leave(ctx context.Context) error {
+	return m.redisClient.GetClient().ZRem(ctx, m.redisClient.KeyPrefix()+m.key, m.instance).Err()
+}
@@ -0,0 +1,312 @@
+// Package selftest 启动自检，依次检查Redis、MySQL、RocketMQ、BSC节点和上游API，输出机器可读的JSON报告
+//
+// 用于部署前或容器启动前验证配置和网络连通性：server -selftest 在任一检查失败时以非零状态退出。
+package selftest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/apikey"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/httpclient"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 检查结果状态
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip" // 对应功能未启用或未配置
+)
+
+// checkTimeout 单项检查超时
+const checkTimeout = 10 * time.Second
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report 自检报告
+type Report struct {
+	Status     string        `json:"status"`
+	Env        string        `json:"env"`
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMs int64         `json:"duration_ms"`
+	Checks     []CheckResult `json:"checks"`
+}
+
+// check 单项检查，返回的detail写入报告，返回skipError表示跳过
+type check struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// skipError 跳过检查的原因
+type skipError string
+
+// Error 实现error接口
+func (e skipError) Error() string { return string(e) }
+
+// Run 依次执行所有检查
+func Run(ctx context.Context, cfg *config.Config) *Report {
+	report := &Report{
+		Status:    StatusPass,
+		Env:       cfg.App.Env,
+		StartedAt: time.Now(),
+	}
+
+	// 每个上游使用各自的代理配置
+	newClient := func(apiCfg config.APIConfig) *http.Client {
+		return httpclient.New(httpclient.Options{Timeout: checkTimeout, Proxy: apiCfg.Proxy})
+	}
+	checks := []check{
+		{name: "redis", run: func(ctx context.Context) (string, error) { return checkRedis(ctx, cfg) }},
+		{name: "mysql", run: func(ctx context.Context) (string, error) { return checkMySQL(ctx, cfg) }},
+		{name: "rocketmq", run: func(ctx context.Context) (string, error) { return checkRocketMQ(ctx, cfg) }},
+		{name: "bsc_rpc", run: func(ctx context.Context) (string, error) { return checkBSC(ctx, cfg) }},
+		{name: "huobi", run: func(ctx context.Context) (string, error) {
+			return checkHTTP(ctx, newClient(cfg.ExternalAPI.Huobi), cfg.ExternalAPI.Huobi.BaseURL, "/v1/common/timestamp")
+		}},
+		{name: "binance", run: func(ctx context.Context) (string, error) {
+			return checkHTTP(ctx, newClient(cfg.ExternalAPI.Binance), cfg.ExternalAPI.Binance.BaseURL, "/api/v3/ping")
+		}},
+		{name: "okx", run: func(ctx context.Context) (string, error) {
+			return checkHTTP(ctx, newClient(cfg.ExternalAPI.OKX), cfg.ExternalAPI.OKX.BaseURL, "/api/v5/public/time")
+		}},
+		{name: "coinbase", run: func(ctx context.Context) (string, error) {
+			return checkHTTP(ctx, newClient(cfg.ExternalAPI.Coinbase), cfg.ExternalAPI.Coinbase.BaseURL, "/time")
+		}},
+		{name: "kraken", run: func(ctx context.Context) (string, error) {
+			return checkHTTP(ctx, newClient(cfg.ExternalAPI.Kraken), cfg.ExternalAPI.Kraken.BaseURL, "/0/public/Time")
+		}},
+		{name: "bscscan", run: func(ctx context.Context) (string, error) {
+			return checkBscScan(ctx, newClient(cfg.ExternalAPI.BscScan), cfg)
+		}},
+	}
+
+	for _, c := range checks {
+		result := runCheck(ctx, c)
+		if result.Status == StatusFail {
+			report.Status = StatusFail
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+// Passed 是否全部检查通过或跳过
+func (r *Report) Passed() bool {
+	return r.Status == StatusPass
+}
+
+// Write 以缩进JSON输出报告
+func (r *Report) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// runCheck 在超时内执行单项检查并计时
+func runCheck(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := c.run(ctx)
+	result := CheckResult{
+		Name:       c.name,
+		Status:     StatusPass,
+		DurationMs: time.Since(start).Milliseconds(),
+		Detail:     detail,
+	}
+
+	var skip skipError
+	if errors.As(err, &skip) {
+		result.Status = StatusSkip
+		result.Detail = string(skip)
+	} else if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// checkRedis 连接Redis并完成一次写入、读取和删除
+func checkRedis(ctx context.Context, cfg *config.Config) (string, error) {
+	if cfg.Database.Redis.Host == "" {
+		return "", skipError("database.redis.host not configured")
+	}
+
+	client, err := database.NewRedisClient(&cfg.Database.Redis, cfg.CacheKeyPrefix())
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	key := fmt.Sprintf("selftest:%d:%d", os.Getpid(), time.Now().UnixNano())
+	if err := client.Set(ctx, key, "ok", time.Minute); err != nil {
+		return "", fmt.Errorf("set failed: %w", err)
+	}
+	defer client.Del(context.Background(), key)
+
+	value, err := client.Get(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("get failed: %w", err)
+	}
+	if value != "ok" {
+		return "", fmt.Errorf("read back %q, expected %q", value, "ok")
+	}
+	return fmt.Sprintf("%s:%d db=%d", cfg.Database.Redis.Host, cfg.Database.Redis.Port, cfg.Database.Redis.DB), nil
+}
+
+// checkMySQL 连接MySQL并执行一次查询，返回服务器版本
+func checkMySQL(ctx context.Context, cfg *config.Config) (string, error) {
+	mysql := &cfg.Database.MySQL
+	if !mysql.Enabled {
+		return "", skipError("mysql disabled")
+	}
+
+	client, err := database.NewMySQLClient(mysql)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	var version string
+	if err := client.DB().GetContext(ctx, &version, "SELECT VERSION()"); err != nil {
+		return "", fmt.Errorf("query failed: %w", err)
+	}
+	return fmt.Sprintf("%s:%d/%s version=%s", mysql.Host, mysql.Port, mysql.Database, version), nil
+}
+
+// checkRocketMQ 确认至少一个NameServer可以建立TCP连接
+//
+// RocketMQ客户端启动时不会连接NameServer，直接拨号才能发现地址或网络配置错误。
+func checkRocketMQ(ctx context.Context, cfg *config.Config) (string, error) {
+	if !cfg.RocketMQ.Enabled {
+		return "", skipError("rocketmq disabled")
+	}
+	if len(cfg.RocketMQ.NameServers) == 0 {
+		return "", fmt.Errorf("rocketmq enabled but no name_servers configured")
+	}
+
+	var (
+		dialer    net.Dialer
+		reachable []string
+		failures  []string
+	)
+	for _, addr := range cfg.RocketMQ.NameServers {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		conn.Close()
+		reachable = append(reachable, addr)
+	}
+
+	if len(reachable) == 0 {
+		return "", fmt.Errorf("no name server reachable: %s", strings.Join(failures, "; "))
+	}
+	return fmt.Sprintf("%d/%d name servers reachable", len(reachable), len(cfg.RocketMQ.NameServers)), nil
+}
+
+// checkBSC 调用BSC节点，确认链ID与配置一致并能获取最新区块
+func checkBSC(ctx context.Context, cfg *config.Config) (string, error) {
+	if !cfg.BSC.Enabled {
+		return "", skipError("bsc disabled")
+	}
+
+	rpcClient, err := rpc.DialOptions(ctx, cfg.BSC.RPCURL,
+		rpc.WithHTTPClient(httpclient.New(httpclient.Options{Timeout: checkTimeout, Proxy: cfg.BSC.Proxy})),
+		rpc.WithWebsocketDialer(httpclient.WebsocketDialer(httpclient.Options{Proxy: cfg.BSC.Proxy})))
+	if err != nil {
+		return "", fmt.Errorf("failed to dial %s: %w", cfg.BSC.RPCURL, err)
+	}
+	client := ethclient.NewClient(rpcClient)
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("eth_chainId failed: %w", err)
+	}
+	if cfg.BSC.ChainID != 0 && chainID.Int64() != cfg.BSC.ChainID {
+		return "", fmt.Errorf("chain id mismatch: node reports %s, config expects %d", chainID, cfg.BSC.ChainID)
+	}
+
+	block, err := client.BlockNumber(ctx)
+	if err != nil {
+		return "", fmt.Errorf("eth_blockNumber failed: %w", err)
+	}
+	return fmt.Sprintf("chain_id=%s block=%d", chainID, block), nil
+}
+
+// checkHTTP 请求上游API的轻量接口
+func checkHTTP(ctx context.Context, client *http.Client, baseURL, path string) (string, error) {
+	if baseURL == "" {
+		return "", skipError("base_url not configured")
+	}
+
+	endpoint := strings.TrimRight(baseURL, "/") + path
+	var discard json.RawMessage
+	if err := httpclient.GetJSON(ctx, client, endpoint, &discard); err != nil {
+		return "", err
+	}
+	return endpoint, nil
+}
+
+// checkBscScan 使用每个配置的API Key查询最新区块高度，确认Key全部有效
+func checkBscScan(ctx context.Context, client *http.Client, cfg *config.Config) (string, error) {
+	apiCfg := cfg.ExternalAPI.BscScan
+	keys := apiCfg.Keys()
+	if len(keys) == 0 {
+		return "", skipError("external_api.bscscan.api_key not configured")
+	}
+
+	baseURL := apiCfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.bscscan.com/api"
+	}
+
+	var block string
+	for _, key := range keys {
+		params := url.Values{}
+		params.Set("module", "proxy")
+		params.Set("action", "eth_blockNumber")
+		params.Set("apikey", key)
+
+		var resp struct {
+			Message string `json:"message"`
+			Result  string `json:"result"`
+		}
+		if err := httpclient.GetJSON(ctx, client, baseURL+"?"+params.Encode(), &resp); err != nil {
+			// 错误信息包含请求地址，避免API Key出现在报告中
+			return "", errors.New(strings.ReplaceAll(err.Error(), key, apikey.Mask(key)))
+		}
+		if !strings.HasPrefix(resp.Result, "0x") {
+			return "", fmt.Errorf("bscscan error with key %s: %s %s", apikey.Mask(key), resp.Message, resp.Result)
+		}
+		block = resp.Result
+	}
+	return fmt.Sprintf("%d keys valid, block=%s", len(keys), block), nil
+}
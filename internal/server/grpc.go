@@ -4,42 +4,57 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"time"
 
+	"crypto-info/internal/bootstrap"
 	"crypto-info/internal/config"
 	"crypto-info/internal/grpc"
-	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/chaos"
+	"crypto-info/internal/pkg/deadline"
 	"crypto-info/internal/pkg/logger"
-	"crypto-info/internal/service"
 	cryptov1 "crypto-info/kitex_gen/crypto/v1/cryptopriceservice"
+	healthv1 "crypto-info/kitex_gen/grpc/health/v1"
+	"crypto-info/kitex_gen/grpc/health/v1/health"
+	"crypto-info/kitex_gen/grpc/reflection/v1alpha/serverreflection"
 
+	"github.com/cloudwego/kitex/pkg/endpoint"
 	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/transmeta"
 	"github.com/cloudwego/kitex/server"
 )
 
+// gRPC服务全名，用于健康检查和服务反射
+const (
+	priceServiceName      = "crypto.v1.CryptoPriceService"
+	healthServiceName     = "grpc.health.v1.Health"
+	reflectionServiceName = "grpc.reflection.v1alpha.ServerReflection"
+)
+
 // GRPCServer Kitex gRPC服务器
 type GRPCServer struct {
 	server server.Server
+	health *grpc.HealthServiceImpl
 	config *config.Config
 	logger logger.Logger
 }
 
 // NewGRPCServer 创建新的gRPC服务器
-func NewGRPCServer(cfg *config.Config, log logger.Logger, redisClient database.RedisClient) *GRPCServer {
-	// 创建服务层
-	bscService, err := service.NewBSCService(cfg, redisClient)
-	if err != nil {
-		log.Errorf("Failed to create BSC service: %v", err)
-	}
-	priceService := service.NewPriceService(redisClient, cfg, bscService)
+func NewGRPCServer(c *bootstrap.Container) *GRPCServer {
+	cfg, log := c.Config, c.Logger
 
 	// 创建gRPC服务实现
-	priceServiceImpl := grpc.NewCryptoPriceService(priceService)
+	priceServiceImpl := grpc.NewCryptoPriceService(c.Services.Price)
 
 	// 创建Kitex服务器
 	addr, _ := net.ResolveTCPAddr("tcp", fmt.Sprintf("%s:%d", cfg.Server.GRPC.Host, cfg.Server.GRPC.Port))
 	svr := cryptov1.NewServer(
 		priceServiceImpl,
 		server.WithServiceAddr(addr),
+		// 客户端deadline：gRPC由传输层写入上下文，TTHeader的超时由元信息处理器写入RPCInfo后派生
+		server.WithMetaHandler(transmeta.ServerTTHeaderHandler),
+		server.WithEnableContextTimeout(true),
+		server.WithMiddleware(deadlineMiddleware(cfg.GRPCRequestTimeout())),
+		server.WithMiddleware(chaosMiddleware()),
 		server.WithServerBasicInfo(&rpcinfo.EndpointBasicInfo{
 			ServiceName: "crypto-price-service",
 			Method:      "",
@@ -47,13 +62,54 @@ func NewGRPCServer(cfg *config.Config, log logger.Logger, redisClient database.R
 		}),
 	)
 
+	// 标准健康检查，空服务名代表整个服务器
+	healthImpl := grpc.NewHealthService()
+	healthImpl.SetServingStatus(priceServiceName, healthv1.HealthCheckResponse_SERVING)
+	if err := health.RegisterService(svr, healthImpl); err != nil {
+		log.Errorf("Failed to register gRPC health service: %v", err)
+	}
+
+	// 服务反射，供grpcurl等工具查询接口定义
+	if cfg.Server.GRPC.Reflection {
+		reflectionImpl, err := grpc.NewReflectionService(priceServiceName, healthServiceName, reflectionServiceName)
+		if err != nil {
+			log.Errorf("Failed to create gRPC reflection service: %v", err)
+		} else if err := serverreflection.RegisterService(svr, reflectionImpl); err != nil {
+			log.Errorf("Failed to register gRPC reflection service: %v", err)
+		}
+	}
+
 	return &GRPCServer{
 		server: svr,
+		health: healthImpl,
 		config: cfg,
 		logger: log,
 	}
 }
 
+// deadlineMiddleware 为调用上下文设置服务端处理时间上限，客户端deadline更早时保持不变
+func deadlineMiddleware(limit time.Duration) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, req, resp interface{}) error {
+			ctx, cancel := deadline.Derive(ctx, 0, limit)
+			defer cancel()
+			return next(ctx, req, resp)
+		}
+	}
+}
+
+// chaosMiddleware 启用故障注入时在调用处理前注入延迟和错误
+func chaosMiddleware() endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, req, resp interface{}) error {
+			if err := chaos.Default().Inject(ctx, chaos.TargetGRPC); err != nil {
+				return err
+			}
+			return next(ctx, req, resp)
+		}
+	}
+}
+
 // Start 启动gRPC服务器
 func (s *GRPCServer) Start() error {
 	s.logger.Info(fmt.Sprintf("gRPC server starting on %s:%d", s.config.Server.GRPC.Host, s.config.Server.GRPC.Port))
@@ -63,12 +119,14 @@ func (s *GRPCServer) Start() error {
 // Stop 停止gRPC服务器
 func (s *GRPCServer) Stop() error {
 	s.logger.Info("gRPC server stopping...")
+	s.health.Shutdown()
 	return s.server.Stop()
 }
 
 // Shutdown 优雅关闭gRPC服务器
 func (s *GRPCServer) Shutdown(ctx context.Context) error {
 	s.logger.Info("gRPC server shutting down...")
-	// Kitex服务器没有Shutdown方法，使用Stop
+	// 先将健康状态置为NOT_SERVING，Kitex服务器没有Shutdown方法，使用Stop
+	s.health.Shutdown()
 	return s.server.Stop()
 }
\ No newline at end of file
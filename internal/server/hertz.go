@@ -3,14 +3,16 @@ package server
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"crypto-info/internal/bootstrap"
 	"crypto-info/internal/config"
-	"crypto-info/internal/handler"
-	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/deadline"
 	"crypto-info/internal/pkg/logger"
-	"crypto-info/internal/pkg/session"
-	"crypto-info/internal/service"
+	"crypto-info/internal/pkg/middleware"
+	"crypto-info/internal/pkg/panics"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
@@ -20,14 +22,15 @@ import (
 
 // HertzServer Hertz HTTP服务器
 type HertzServer struct {
-	server         *server.Hertz
-	config         *config.Config
-	logger         logger.Logger
-	sessionManager *session.Manager
+	server *server.Hertz
+	config *config.Config
+	logger logger.Logger
 }
 
 // NewHertzServer 创建新的Hertz服务器
-func NewHertzServer(cfg *config.Config, log logger.Logger, redisClient database.RedisClient) *HertzServer {
+func NewHertzServer(c *bootstrap.Container) *HertzServer {
+	cfg, log := c.Config, c.Logger
+
 	// 创建Hertz服务器实例
 	h := server.Default(
 		server.WithHostPorts(fmt.Sprintf("%s:%d", cfg.Server.HTTP.Host, cfg.Server.HTTP.Port)),
@@ -36,57 +39,33 @@ func NewHertzServer(cfg *config.Config, log logger.Logger, redisClient database.
 		server.WithIdleTimeout(60*time.Second),
 	)
 
-	// 使用默认Hertz日志配置
-	// TODO: 后续可以创建适配器来集成现有的logger
-
-	// 创建session管理器
-	var sessionManager *session.Manager
-	if cfg.Security.Session.Enabled {
-		var err error
-		if redisClient != nil {
-			sessionManager, err = session.NewManager(&cfg.Security.Session, redisClient.GetClient(), log)
-		} else {
-			sessionManager, err = session.NewManager(&cfg.Security.Session, nil, log)
-		}
-		if err != nil {
-			log.Errorf("Failed to create session manager: %v", err)
-		} else {
-			log.Info("Session manager initialized for Hertz")
-		}
-	}
-
-	// 创建服务层
-	bscService, err := service.NewBSCService(cfg, redisClient)
+	// Hertz默认信任所有来源的X-Forwarded-For/X-Real-IP，改为只信任配置的反向代理
+	trustedProxies, err := cfg.TrustedProxyNets()
 	if err != nil {
-		log.Errorf("Failed to create BSC service: %v", err)
-	}
-	priceService := service.NewPriceService(redisClient, cfg, bscService)
-	volumeService := service.NewVolumeService(redisClient, cfg)
-
-	// 创建处理器
-	priceHandler := handler.NewPriceHandler(priceService)
-	volumeHandler := handler.NewVolumeHandler(volumeService)
-	bscHandler := handler.NewBSCHandler(bscService)
-	var sessionHandler *handler.SessionHandler
-	if sessionManager != nil {
-		sessionHandler = handler.NewSessionHandler(sessionManager)
+		log.Errorf("Invalid trusted proxies, forwarded client addresses are ignored: %v", err)
 	}
+	h.SetClientIPFunc(app.ClientIPWithOption(app.ClientIPOptions{
+		RemoteIPHeaders: []string{"X-Forwarded-For", "X-Real-IP"},
+		TrustedCIDRs:    trustedProxies,
+	}))
+
+	// 使用默认Hertz日志配置
+	// TODO: 后续可以创建适配器来集成现有的logger
 
 	// 设置中间件
-	setupHertzMiddleware(h, cfg, log)
+	setupHertzMiddleware(h, c)
 
 	// 设置路由
-	setupHertzRoutes(h, priceHandler, volumeHandler, bscHandler, sessionHandler)
+	setupHertzRoutes(h, cfg, c.Handlers)
 
 	return &HertzServer{
-		server:         h,
-		config:         cfg,
-		logger:         log,
-		sessionManager: sessionManager,
+		server: h,
+		config: cfg,
+		logger: log,
 	}
 }
 
-// Start 启动服务器
+// Start 启动服务器，后台任务由入口通过容器启动
 func (s *HertzServer) Start() error {
 	s.logger.Info(fmt.Sprintf("Hertz server starting on %s:%d", s.config.Server.HTTP.Host, s.config.Server.HTTP.Port))
 	return s.server.Run()
@@ -99,7 +78,9 @@ func (s *HertzServer) Shutdown(ctx context.Context) error {
 }
 
 // setupHertzMiddleware 设置Hertz中间件
-func setupHertzMiddleware(h *server.Hertz, cfg *config.Config, log logger.Logger) {
+func setupHertzMiddleware(h *server.Hertz, c *bootstrap.Container) {
+	log := c.Logger
+
 	// 请求ID中间件
 	h.Use(func(ctx context.Context, c *app.RequestContext) {
 		requestID := string(c.GetHeader("X-Request-ID"))
@@ -107,7 +88,16 @@ func setupHertzMiddleware(h *server.Hertz, cfg *config.Config, log logger.Logger
 			requestID = fmt.Sprintf("%d", time.Now().UnixNano())
 		}
 		c.Header("X-Request-ID", requestID)
-		c.Next(ctx)
+
+		// 绑定请求级日志实例
+		fields := map[string]interface{}{"request_id": requestID}
+		if tenantID := string(c.GetHeader("X-Tenant-ID")); tenantID != "" {
+			fields["tenant"] = tenantID
+		}
+		if userID := string(c.GetHeader("X-User-ID")); userID != "" {
+			fields["user"] = userID
+		}
+		c.Next(logger.NewContext(ctx, log.WithFields(fields)))
 	})
 
 	// 日志中间件
@@ -125,14 +115,31 @@ func setupHertzMiddleware(h *server.Hertz, cfg *config.Config, log logger.Logger
 		}).Info("HTTP Request")
 	})
 
+	// 请求成功率统计，注册在恢复中间件之前以统计panic转成的500
+	if c.Config.Monitoring.SLA.Enabled {
+		sla := c.Services.SLA
+		h.Use(func(ctx context.Context, c *app.RequestContext) {
+			if !strings.HasPrefix(string(c.Path()), "/api/") {
+				c.Next(ctx)
+				return
+			}
+			defer func() {
+				sla.RecordRequest(c.Response.StatusCode())
+			}()
+			c.Next(ctx)
+		})
+	}
+
 	// 恢复中间件
 	h.Use(func(ctx context.Context, c *app.RequestContext) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.WithFields(map[string]interface{}{
-					"error": err,
-					"path":  string(c.Path()),
-				}).Error("Panic recovered")
+				panics.Capture(&panics.Report{
+					Source:    "hertz",
+					RequestID: string(c.GetHeader("X-Request-ID")),
+					Method:    string(c.Method()),
+					Path:      string(c.Path()),
+				}, err)
 				c.JSON(consts.StatusInternalServerError, map[string]interface{}{
 					"error":   "Internal Server Error",
 					"message": "服务器内部错误",
@@ -148,7 +155,7 @@ func setupHertzMiddleware(h *server.Hertz, cfg *config.Config, log logger.Logger
 	h.Use(func(ctx context.Context, c *app.RequestContext) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, X-Request-Timeout")
 		c.Header("Access-Control-Max-Age", "86400")
 
 		if string(c.Method()) == "OPTIONS" {
@@ -158,10 +165,89 @@ func setupHertzMiddleware(h *server.Hertz, cfg *config.Config, log logger.Logger
 		}
 		c.Next(ctx)
 	})
+
+	// 按客户端IP限流
+	if rateLimiter := c.RateLimiter; rateLimiter != nil {
+		h.Use(func(ctx context.Context, c *app.RequestContext) {
+			if !rateLimiter.Allow(c.ClientIP()) {
+				log.WithField("client_ip", c.ClientIP()).Warn("Rate limit exceeded")
+				c.JSON(consts.StatusTooManyRequests, map[string]interface{}{
+					"error":   "Too Many Requests",
+					"message": "请求过于频繁，请稍后再试",
+					"code":    429,
+				})
+				c.Abort()
+				return
+			}
+			c.Next(ctx)
+		})
+	}
+
+	// 并发请求上限
+	if limiter := c.Concurrency; limiter != nil {
+		h.Use(func(ctx context.Context, c *app.RequestContext) {
+			if middleware.IsStreamRoute(c.FullPath()) {
+				c.Next(ctx)
+				return
+			}
+			release, group, ok := limiter.Acquire(string(c.Path()))
+			if !ok {
+				logger.From(ctx).WithField("group", group).Warn("Concurrency limit exceeded")
+				c.Header("Retry-After", strconv.Itoa(middleware.RetryAfterSeconds(limiter.RetryAfter())))
+				c.JSON(consts.StatusServiceUnavailable, map[string]interface{}{
+					"error":   "Service Unavailable",
+					"message": "服务繁忙，请稍后再试",
+					"code":    503,
+				})
+				c.Abort()
+				return
+			}
+			defer release()
+			c.Next(ctx)
+		})
+	}
+
+	// 依赖异常时拒绝低优先级请求
+	if shedder := c.LoadShedder; shedder != nil {
+		h.Use(func(ctx context.Context, c *app.RequestContext) {
+			policy, shed := shedder.Check(string(c.Path()))
+			if shed {
+				logger.From(ctx).WithField("policy", policy).Warn("Request shed due to degraded dependency")
+				c.Header("Retry-After", strconv.Itoa(middleware.RetryAfterSeconds(shedder.RetryAfter())))
+				c.Header("X-Load-Shed", policy)
+				c.JSON(consts.StatusServiceUnavailable, map[string]interface{}{
+					"error":   "Service Unavailable",
+					"message": "服务降级中，请稍后再试",
+					"code":    503,
+				})
+				c.Abort()
+				return
+			}
+			c.Next(ctx)
+		})
+	}
+
+	// 请求截止时间，客户端可通过X-Request-Timeout请求头缩短
+	requestTimeout := c.Config.HTTPRequestTimeout()
+	h.Use(func(ctx context.Context, c *app.RequestContext) {
+		requested, err := deadline.Parse(string(c.GetHeader(deadline.Header)))
+		if err != nil {
+			c.JSON(consts.StatusBadRequest, map[string]interface{}{
+				"error":   "Bad Request",
+				"message": "无效的X-Request-Timeout请求头",
+				"code":    400,
+			})
+			c.Abort()
+			return
+		}
+		ctx, cancel := deadline.Derive(ctx, requested, requestTimeout)
+		defer cancel()
+		c.Next(ctx)
+	})
 }
 
 // setupHertzRoutes 设置Hertz路由
-func setupHertzRoutes(h *server.Hertz, priceHandler *handler.PriceHandler, volumeHandler *handler.VolumeHandler, bscHandler *handler.BSCHandler, sessionHandler *handler.SessionHandler) {
+func setupHertzRoutes(h *server.Hertz, cfg *config.Config, handlers *bootstrap.Handlers) {
 	// 健康检查
 	h.GET("/health", func(ctx context.Context, c *app.RequestContext) {
 		c.JSON(consts.StatusOK, map[string]interface{}{
@@ -171,6 +257,9 @@ func setupHertzRoutes(h *server.Hertz, priceHandler *handler.PriceHandler, volum
 		})
 	})
 
+	// 就绪探针
+	h.GET("/readyz", adaptHertzHandler(handlers.Health.Readyz))
+
 	// 根路径
 	h.GET("/", func(ctx context.Context, c *app.RequestContext) {
 		c.JSON(consts.StatusOK, map[string]interface{}{
@@ -184,44 +273,150 @@ func setupHertzRoutes(h *server.Hertz, priceHandler *handler.PriceHandler, volum
 	v1 := h.Group("/api/v1")
 	{
 		// 价格相关API
-		v1.GET("/crypto/price", adaptHertzHandler(priceHandler.GetPrice))
-		v1.GET("/crypto/btc-price", adaptHertzHandler(priceHandler.GetBTCPrice))
+		v1.GET("/crypto/price", adaptHertzHandler(handlers.Price.GetPrice))
+		v1.GET("/crypto/btc-price", adaptHertzHandler(handlers.Price.GetBTCPrice))
+		v1.GET("/crypto/price/history", adaptHertzHandler(handlers.History.GetPriceHistory))
+		v1.GET("/crypto/price/at", adaptHertzHandler(handlers.History.GetPriceAt))
+		v1.GET("/crypto/klines", adaptHertzHandler(handlers.Kline.GetKlines))
+		v1.GET("/crypto/marketcap", adaptHertzHandler(handlers.Market.GetMarketCap))
+		v1.GET("/crypto/global", adaptHertzHandler(handlers.Market.GetGlobal))
+		v1.GET("/crypto/compare", adaptHertzHandler(handlers.Compare.Compare))
 
 		// 交易量相关API
-		v1.GET("/crypto/volume/analysis", adaptHertzHandler(volumeHandler.GetVolumeAnalysis))
-		v1.GET("/crypto/volume/fluctuation", adaptHertzHandler(volumeHandler.GetMarketVolumeFluctuation))
-		v1.GET("/crypto/volume/comparison", adaptHertzHandler(volumeHandler.GetVolumeComparison))
-		v1.GET("/crypto/volume/top", adaptHertzHandler(volumeHandler.GetTopVolumeCoins))
+		v1.GET("/crypto/volume/analysis", adaptHertzHandler(handlers.Volume.GetVolumeAnalysis))
+		v1.GET("/crypto/volume/fluctuation", adaptHertzHandler(handlers.Volume.GetMarketVolumeFluctuation))
+		v1.GET("/crypto/volume/comparison", adaptHertzHandler(handlers.Volume.GetVolumeComparison))
+		v1.GET("/crypto/volume/top", adaptHertzHandler(handlers.Volume.GetTopVolumeCoins))
+
+		// 投资组合API
+		v1.POST("/portfolio/pnl", adaptHertzHandler(handlers.Portfolio.CalculatePnL))
+		v1.GET("/portfolio/trades", adaptHertzHandler(handlers.Portfolio.ListTrades))
+		v1.POST("/portfolio/trades/import", adaptHertzHandler(handlers.Portfolio.ImportTrades))
+		v1.GET("/portfolio/export", adaptHertzHandler(handlers.Portfolio.ExportAccounting))
+
+		// 代币元数据API
+		v1.GET("/tokens", adaptHertzHandler(handlers.Token.ListTokens))
+		v1.GET("/tokens/sync", adaptHertzHandler(handlers.Token.GetSyncStatus))
+		v1.GET("/tokens/:address", adaptHertzHandler(handlers.Token.GetToken))
+		v1.GET("/labels/:address", adaptHertzHandler(handlers.Token.GetLabel))
+		v1.GET("/bsc/token/safety", adaptHertzHandler(handlers.Token.GetTokenSafety))
+		v1.GET("/bsc/bridges/activity", adaptHertzHandler(handlers.Bridge.GetActivity))
+		v1.GET("/bsc/farms", adaptHertzHandler(handlers.Farm.GetFarms))
+		v1.GET("/bsc/tvl", adaptHertzHandler(handlers.TVL.GetOverview))
+		v1.GET("/bsc/tvl/history", adaptHertzHandler(handlers.TVL.GetHistory))
+		v1.GET("/bsc/liquidity/events", adaptHertzHandler(handlers.Liquidity.GetEvents))
+		v1.GET("/bsc/liquidity/pairs", adaptHertzHandler(handlers.Liquidity.GetPairs))
+		v1.GET("/bsc/filters", adaptHertzHandler(handlers.Events.ListFilters))
+		v1.POST("/bsc/filters", adaptHertzHandler(handlers.Events.CreateFilter))
+		v1.GET("/bsc/filters/:id", adaptHertzHandler(handlers.Events.GetFilter))
+		v1.PUT("/bsc/filters/:id", adaptHertzHandler(handlers.Events.UpdateFilter))
+		v1.DELETE("/bsc/filters/:id", adaptHertzHandler(handlers.Events.DeleteFilter))
+		v1.GET("/bsc/filters/:id/matches", adaptHertzHandler(handlers.Events.GetMatches))
+		v1.POST("/bsc/token/snapshot", adaptHertzHandler(handlers.Snapshot.CreateSnapshot))
+		v1.GET("/bsc/token/snapshot/:id", adaptHertzHandler(handlers.Snapshot.GetSnapshot))
+		v1.GET("/bsc/token/snapshot/:id/download", adaptHertzHandler(handlers.Snapshot.DownloadSnapshot))
+		v1.GET("/bsc/address/:address/activity", adaptHertzHandler(handlers.Activity.GetActivity))
+		v1.GET("/names/resolve", adaptHertzHandler(handlers.Name.Resolve))
+		v1.GET("/names/reverse/:address", adaptHertzHandler(handlers.Name.Reverse))
+		v1.GET("/alerts/recent", adaptHertzHandler(handlers.Alert.ListAlerts))
+		v1.GET("/alerts/scheduled", adaptHertzHandler(handlers.Alert.ListScheduledAlerts))
+		v1.POST("/alerts/scheduled", adaptHertzHandler(handlers.Alert.CreateScheduledAlert))
+		v1.GET("/alerts/scheduled/:id", adaptHertzHandler(handlers.Alert.GetScheduledAlert))
+		v1.PUT("/alerts/scheduled/:id", adaptHertzHandler(handlers.Alert.UpdateScheduledAlert))
+		v1.DELETE("/alerts/scheduled/:id", adaptHertzHandler(handlers.Alert.DeleteScheduledAlert))
+		v1.GET("/alerts", adaptHertzHandler(handlers.Alert.ListRules))
+		v1.POST("/alerts", adaptHertzHandler(handlers.Alert.CreateRule))
+		v1.GET("/alerts/:id", adaptHertzHandler(handlers.Alert.GetRule))
+		v1.PUT("/alerts/:id", adaptHertzHandler(handlers.Alert.UpdateRule))
+		v1.DELETE("/alerts/:id", adaptHertzHandler(handlers.Alert.DeleteRule))
+		v1.POST("/alerts/:id/test", adaptHertzHandler(handlers.Alert.TestRule))
+		v1.GET("/alerts/:id/history", adaptHertzHandler(handlers.Alert.GetRuleHistory))
+		v1.GET("/webhooks", adaptHertzHandler(handlers.Webhook.ListUserWebhooks))
+		v1.POST("/webhooks", adaptHertzHandler(handlers.Webhook.CreateUserWebhook))
+		v1.DELETE("/webhooks/:id", adaptHertzHandler(handlers.Webhook.DeleteUserWebhook))
+		v1.GET("/webhooks/:id/deliveries", adaptHertzHandler(handlers.Webhook.ListUserDeliveries))
+		v1.POST("/webhooks/:id/deliveries/:delivery_id/redrive", adaptHertzHandler(handlers.Webhook.RedriveUserDelivery))
+		admin := v1.Group("/admin", hertzAdminAuth(cfg.Security.Admin.Token))
+		admin.GET("/budgets", adaptHertzHandler(handlers.Budget.GetUsage))
+		admin.GET("/config", adaptHertzHandler(handlers.Config.GetConfig))
+		admin.GET("/jobs", adaptHertzHandler(handlers.Jobs.ListJobs))
+		admin.POST("/jobs/:name/run", adaptHertzHandler(handlers.Jobs.RunJob))
+		admin.GET("/jobs/:name/runs", adaptHertzHandler(handlers.Jobs.ListJobRuns))
+		admin.GET("/state/export", adaptHertzHandler(handlers.Backup.ExportState))
+		admin.POST("/state/restore", adaptHertzHandler(handlers.Backup.RestoreState))
+		admin.GET("/analytics/usage", adaptHertzHandler(handlers.Analytics.GetUsage))
+		admin.GET("/webhooks/deliveries", adaptHertzHandler(handlers.Webhook.ListDeliveries))
+		admin.GET("/webhooks/deliveries/:id", adaptHertzHandler(handlers.Webhook.GetDelivery))
+		admin.POST("/webhooks/deliveries/:id/redrive", adaptHertzHandler(handlers.Webhook.RedriveDelivery))
+		admin.POST("/ingest/scan", adaptHertzHandler(handlers.Token.TriggerIngest))
+		admin.POST("/tokens/sync", adaptHertzHandler(handlers.Token.TriggerSync))
+		v1.GET("/version", adaptHertzHandler(handlers.Version.GetVersion))
+		v1.GET("/status/sla", adaptHertzHandler(handlers.Status.GetSLA))
+		v1.GET("/status/breakers", adaptHertzHandler(handlers.Status.GetBreakers))
+		v1.GET("/stream/stats", adaptHertzHandler(handlers.Stream.GetStats))
+		v1.POST("/stream/subscriptions", adaptHertzHandler(handlers.Stream.CreateSubscription))
+		v1.GET("/stream/subscriptions/:id", adaptHertzHandler(handlers.Stream.GetSubscription))
+		v1.PUT("/stream/subscriptions/:id", adaptHertzHandler(handlers.Stream.UpdateSubscription))
+		v1.DELETE("/stream/subscriptions/:id", adaptHertzHandler(handlers.Stream.DeleteSubscription))
+		v1.GET("/ingest/log", adaptHertzHandler(handlers.Token.GetIngestLog))
 
 		// BSC链上数据监控API
-		v1.GET("/bsc/status", adaptHertzHandler(bscHandler.GetStatus))
-		v1.GET("/bsc/latest-block", adaptHertzHandler(bscHandler.GetLatestBlock))
-		v1.GET("/bsc/transactions", adaptHertzHandler(bscHandler.GetTransactions))
-		v1.GET("/bsc/token-transfers", adaptHertzHandler(bscHandler.GetTokenTransfers))
-		v1.GET("/bsc/swap-events", adaptHertzHandler(bscHandler.GetSwapEvents))
-		v1.GET("/bsc/pair-info/:address", adaptHertzHandler(bscHandler.GetPairInfo))
-		v1.POST("/bsc/start-monitoring", adaptHertzHandler(bscHandler.StartMonitoring))
-		v1.POST("/bsc/stop-monitoring", adaptHertzHandler(bscHandler.StopMonitoring))
+		v1.GET("/bsc/status", adaptHertzHandler(handlers.BSC.GetStatus))
+		v1.GET("/bsc/stats/history", adaptHertzHandler(handlers.BSC.GetStatsHistory))
+		v1.GET("/bsc/latest-block", adaptHertzHandler(handlers.BSC.GetLatestBlock))
+		v1.GET("/bsc/network/congestion", adaptHertzHandler(handlers.BSC.GetCongestion))
+		v1.GET("/bsc/validators", adaptHertzHandler(handlers.BSC.GetValidators))
+		v1.GET("/bsc/transactions", adaptHertzHandler(handlers.BSC.GetTransactions))
+		v1.GET("/bsc/token-transfers", adaptHertzHandler(handlers.BSC.GetTokenTransfers))
+		v1.GET("/bsc/swap-events", adaptHertzHandler(handlers.BSC.GetSwapEvents))
+		v1.GET("/bsc/pair-info/:address", adaptHertzHandler(handlers.BSC.GetPairInfo))
+		v1.POST("/bsc/start-monitoring", adaptHertzHandler(handlers.BSC.StartMonitoring))
+		v1.POST("/bsc/stop-monitoring", adaptHertzHandler(handlers.BSC.StopMonitoring))
 
 		// Session相关API
-		if sessionHandler != nil {
-			v1.GET("/session/info", adaptHertzHandler(sessionHandler.GetSession))
-			v1.GET("/session/status", adaptHertzHandler(sessionHandler.SessionStatus))
-			v1.POST("/session/data", adaptHertzHandler(sessionHandler.SetSessionData))
-			v1.GET("/session/data/:key", adaptHertzHandler(sessionHandler.GetSessionData))
-			v1.DELETE("/session/data/:key", adaptHertzHandler(sessionHandler.RemoveSessionData))
-			v1.POST("/session/refresh", adaptHertzHandler(sessionHandler.RefreshSession))
-			v1.DELETE("/session/destroy", adaptHertzHandler(sessionHandler.DestroySession))
+		if handlers.Session != nil {
+			v1.GET("/session/info", adaptHertzHandler(handlers.Session.GetSession))
+			v1.GET("/session/status", adaptHertzHandler(handlers.Session.SessionStatus))
+			v1.POST("/session/data", adaptHertzHandler(handlers.Session.SetSessionData))
+			v1.GET("/session/data/:key", adaptHertzHandler(handlers.Session.GetSessionData))
+			v1.DELETE("/session/data/:key", adaptHertzHandler(handlers.Session.RemoveSessionData))
+			v1.POST("/session/refresh", adaptHertzHandler(handlers.Session.RefreshSession))
+			v1.DELETE("/session/destroy", adaptHertzHandler(handlers.Session.DestroySession))
+			v1.GET("/session/preferences", adaptHertzHandler(handlers.Session.GetPreferences))
+			v1.PUT("/session/preferences", adaptHertzHandler(handlers.Session.SetPreferences))
 		}
 	}
 
 	// 兼容旧路由
-	h.GET("/crypto/price", adaptHertzHandler(priceHandler.GetPrice))
-	h.GET("/btc-price", adaptHertzHandler(priceHandler.GetBTCPrice))
-	h.GET("/crypto/volume/analysis", adaptHertzHandler(volumeHandler.GetVolumeAnalysis))
-	h.GET("/crypto/volume/fluctuation", adaptHertzHandler(volumeHandler.GetMarketVolumeFluctuation))
-	h.GET("/crypto/volume/comparison", adaptHertzHandler(volumeHandler.GetVolumeComparison))
-	h.GET("/crypto/volume/top", adaptHertzHandler(volumeHandler.GetTopVolumeCoins))
+	h.GET("/crypto/price", adaptHertzHandler(handlers.Price.GetPrice))
+	h.GET("/btc-price", adaptHertzHandler(handlers.Price.GetBTCPrice))
+	h.GET("/crypto/volume/analysis", adaptHertzHandler(handlers.Volume.GetVolumeAnalysis))
+	h.GET("/crypto/volume/fluctuation", adaptHertzHandler(handlers.Volume.GetMarketVolumeFluctuation))
+	h.GET("/crypto/volume/comparison", adaptHertzHandler(handlers.Volume.GetVolumeComparison))
+	h.GET("/crypto/volume/top", adaptHertzHandler(handlers.Volume.GetTopVolumeCoins))
+}
+
+// hertzAdminAuth 管理接口鉴权，与Gin的middleware.AdminAuth规则相同
+func hertzAdminAuth(token string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		status := middleware.AdminStatus(token, string(c.GetHeader("Authorization")), string(c.GetHeader(middleware.AdminTokenHeader)))
+		if status == 0 {
+			c.Next(ctx)
+			return
+		}
+
+		logger.From(ctx).WithField("client_ip", c.ClientIP()).Warn("Admin API request rejected")
+		message := "管理接口未启用"
+		if status == consts.StatusUnauthorized {
+			message = "管理接口令牌无效"
+		}
+		c.JSON(status, map[string]interface{}{
+			"error":   consts.StatusMessage(status),
+			"message": message,
+			"code":    status,
+		})
+		c.Abort()
+	}
 }
 
 // adaptHertzHandler 适配Gin处理器到Hertz
@@ -236,4 +431,4 @@ func adaptHertzHandler(ginHandler func(*gin.Context)) func(context.Context, *app
 			"note":    "Handler adaptation in progress",
 		})
 	}
-}
\ No newline at end of file
+}
@@ -2,56 +2,41 @@ package server
 
 import (
 	"context"
-	"fmt"
 	"net/http"
-	"time"
 
+	"crypto-info/internal/bootstrap"
 	"crypto-info/internal/config"
-	"crypto-info/internal/handler"
-	"crypto-info/internal/pkg/database"
 	"crypto-info/internal/pkg/logger"
 	"crypto-info/internal/pkg/middleware"
-	"crypto-info/internal/pkg/session"
-	"crypto-info/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 // HTTPServer HTTP服务器
 type HTTPServer struct {
-	server         *http.Server
-	config         *config.Config
-	logger         logger.Logger
-	sessionManager *session.Manager
+	server *http.Server
+	config *config.Config
+	logger logger.Logger
 }
 
 // NewHTTPServer 创建HTTP服务器
-func NewHTTPServer(cfg *config.Config, redisClient database.RedisClient) (*HTTPServer, error) {
-	log := logger.GetLogger()
-
-	// 创建session管理器
-	var sessionManager *session.Manager
-	if cfg.Security.Session.Enabled {
-		var err error
-		if redisClient != nil {
-			sessionManager, err = session.NewManager(&cfg.Security.Session, redisClient.GetClient(), log)
-		} else {
-			sessionManager, err = session.NewManager(&cfg.Security.Session, nil, log)
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to create session manager: %w", err)
-		}
-		log.Info("Session manager initialized")
-	}
+func NewHTTPServer(c *bootstrap.Container) *HTTPServer {
+	cfg := c.Config
 
 	// 创建Gin引擎
 	router := gin.New()
 
+	// 只采信可信代理转发的客户端地址，默认不信任任何代理，限流按连接地址区分客户端
+	if err := router.SetTrustedProxies(cfg.Server.HTTP.TrustedProxies); err != nil {
+		c.Logger.Errorf("Invalid trusted proxies, forwarded client addresses are ignored: %v", err)
+		router.SetTrustedProxies(nil)
+	}
+
 	// 注册中间件
-	setupMiddleware(router, cfg, sessionManager)
+	setupMiddleware(router, c)
 
 	// 注册路由
-	setupRoutes(router, cfg, redisClient, sessionManager)
+	setupRoutes(router, cfg, c.Handlers)
 
 	// 创建HTTP服务器
 	server := &http.Server{
@@ -64,14 +49,13 @@ func NewHTTPServer(cfg *config.Config, redisClient database.RedisClient) (*HTTPS
 	}
 
 	return &HTTPServer{
-		server:         server,
-		config:         cfg,
-		logger:         log,
-		sessionManager: sessionManager,
-	}, nil
+		server: server,
+		config: cfg,
+		logger: c.Logger,
+	}
 }
 
-// Start 启动服务器
+// Start 启动服务器，后台任务由入口通过容器启动
 func (s *HTTPServer) Start() error {
 	return s.server.ListenAndServe()
 }
@@ -82,13 +66,28 @@ func (s *HTTPServer) Shutdown(ctx context.Context) error {
 }
 
 // setupMiddleware 设置中间件
-func setupMiddleware(router *gin.Engine, cfg *config.Config, sessionManager *session.Manager) {
+func setupMiddleware(router *gin.Engine, c *bootstrap.Container) {
+	cfg := c.Config
+
 	// 请求ID中间件
 	router.Use(middleware.RequestID())
 
+	// 请求级日志中间件
+	router.Use(middleware.RequestLogger())
+
 	// 日志中间件
 	router.Use(middleware.Logger())
 
+	// 请求成功率统计
+	if cfg.Monitoring.SLA.Enabled {
+		router.Use(middleware.SLA(c.Services.SLA))
+	}
+
+	// 匿名使用统计，缓存命中的请求也计入
+	if cfg.Analytics.Enabled {
+		router.Use(middleware.Analytics(c.Services.Analytics))
+	}
+
 	// 恢复中间件
 	router.Use(middleware.Recovery())
 
@@ -111,37 +110,43 @@ func setupMiddleware(router *gin.Engine, cfg *config.Config, sessionManager *ses
 		router.Use(middleware.HealthCheck(cfg.Monitoring.HealthCheck.Path))
 	}
 
-	// Session中间件
-	if sessionManager != nil {
-		router.Use(middleware.Session(sessionManager))
+	// 按客户端IP限流，放在健康检查之后以免探针被限流
+	if c.RateLimiter != nil {
+		router.Use(middleware.RateLimit(c.RateLimiter))
 	}
 
-	// 超时中间件
-	router.Use(middleware.Timeout(30 * time.Second))
-}
+	// 并发请求上限，被限流的请求不占用名额
+	if c.Concurrency != nil {
+		router.Use(c.Concurrency.Middleware())
+	}
 
-// setupRoutes 设置路由
-func setupRoutes(router *gin.Engine, cfg *config.Config, redisClient database.RedisClient, sessionManager *session.Manager) {
-	// 创建服务层
-	bscService, err := service.NewBSCService(cfg, redisClient)
-	if err != nil {
-		logger.GetLogger().Errorf("Failed to create BSC service: %v", err)
+	// 依赖异常时拒绝低优先级请求
+	if c.LoadShedder != nil {
+		router.Use(c.LoadShedder.Middleware())
 	}
-	priceService := service.NewPriceService(redisClient, cfg, bscService)
-	volumeService := service.NewVolumeService(redisClient, cfg)
-
-	// 创建处理器
-	priceHandler := handler.NewPriceHandler(priceService)
-	volumeHandler := handler.NewVolumeHandler(volumeService)
-	var bscHandler *handler.BSCHandler
-	if bscService != nil {
-		bscHandler = handler.NewBSCHandler(bscService)
+
+	// Session中间件
+	if c.SessionManager != nil {
+		router.Use(middleware.Session(c.SessionManager))
+		// 未指定计价币种、locale或币种时使用session中的显示偏好
+		router.Use(middleware.Preferences())
 	}
-	var sessionHandler *handler.SessionHandler
-	if sessionManager != nil {
-		sessionHandler = handler.NewSessionHandler(sessionManager)
+
+	// 超时中间件
+	router.Use(middleware.Timeout(cfg.HTTPRequestTimeout()))
+
+	// 字段选择中间件，过滤缓存命中和未命中的响应
+	router.Use(middleware.SparseFields())
+
+	// 响应缓存中间件，放在最后以便命中时仍经过日志、CORS和Session等中间件
+	if cfg.Server.HTTP.ResponseCache.Enabled {
+		cache := middleware.NewResponseCache(cfg.Server.HTTP.ResponseCache.Routes, cfg.Server.HTTP.ResponseCache.MaxEntries)
+		router.Use(cache.Middleware())
 	}
+}
 
+// setupRoutes 设置路由
+func setupRoutes(router *gin.Engine, cfg *config.Config, h *bootstrap.Handlers) {
 	// API v1 路由组
 	v1 := router.Group("/api/v1")
 	{
@@ -149,56 +154,162 @@ func setupRoutes(router *gin.Engine, cfg *config.Config, redisClient database.Re
 		crypto := v1.Group("/crypto")
 		{
 			// 价格相关路由
-			crypto.GET("/price", priceHandler.GetPrice)
-			crypto.GET("/btc-price", priceHandler.GetBTCPrice)
+			crypto.GET("/price", h.Price.GetPrice)
+			crypto.GET("/btc-price", h.Price.GetBTCPrice)
+			crypto.GET("/price/history", h.History.GetPriceHistory)
+			crypto.GET("/price/at", h.History.GetPriceAt)
+			crypto.GET("/klines", h.Kline.GetKlines)
+			crypto.GET("/marketcap", h.Market.GetMarketCap)
+			crypto.GET("/global", h.Market.GetGlobal)
+			crypto.GET("/compare", h.Compare.Compare)
 
 			// 交易量相关路由
 			volume := crypto.Group("/volume")
 			{
-				volume.GET("/analysis", volumeHandler.GetVolumeAnalysis)
-				volume.GET("/fluctuation", volumeHandler.GetMarketVolumeFluctuation)
-				volume.GET("/comparison", volumeHandler.GetVolumeComparison)
-				volume.GET("/top", volumeHandler.GetTopVolumeCoins)
+				volume.GET("/analysis", h.Volume.GetVolumeAnalysis)
+				volume.GET("/fluctuation", h.Volume.GetMarketVolumeFluctuation)
+				volume.GET("/comparison", h.Volume.GetVolumeComparison)
+				volume.GET("/top", h.Volume.GetTopVolumeCoins)
 			}
 		}
 
+		// 投资组合路由
+		portfolio := v1.Group("/portfolio")
+		{
+			portfolio.POST("/pnl", h.Portfolio.CalculatePnL)
+			portfolio.GET("/trades", h.Portfolio.ListTrades)
+			portfolio.POST("/trades/import", h.Portfolio.ImportTrades)
+			portfolio.GET("/export", h.Portfolio.ExportAccounting)
+		}
+
+		// 代币元数据路由
+		v1.GET("/tokens", h.Token.ListTokens)
+		v1.GET("/tokens/sync", h.Token.GetSyncStatus)
+		v1.GET("/tokens/:address", h.Token.GetToken)
+		v1.GET("/labels/:address", h.Token.GetLabel)
+		v1.GET("/bsc/token/safety", h.Token.GetTokenSafety)
+		v1.GET("/bsc/bridges/activity", h.Bridge.GetActivity)
+		v1.GET("/bsc/farms", h.Farm.GetFarms)
+		v1.GET("/bsc/tvl", h.TVL.GetOverview)
+		v1.GET("/bsc/tvl/history", h.TVL.GetHistory)
+		v1.GET("/bsc/liquidity/events", h.Liquidity.GetEvents)
+		v1.GET("/bsc/liquidity/pairs", h.Liquidity.GetPairs)
+		v1.GET("/bsc/filters", h.Events.ListFilters)
+		v1.POST("/bsc/filters", h.Events.CreateFilter)
+		v1.GET("/bsc/filters/:id", h.Events.GetFilter)
+		v1.PUT("/bsc/filters/:id", h.Events.UpdateFilter)
+		v1.DELETE("/bsc/filters/:id", h.Events.DeleteFilter)
+		v1.GET("/bsc/filters/:id/matches", h.Events.GetMatches)
+		v1.POST("/bsc/token/snapshot", h.Snapshot.CreateSnapshot)
+		v1.GET("/bsc/token/snapshot/:id", h.Snapshot.GetSnapshot)
+		v1.GET("/bsc/token/snapshot/:id/download", h.Snapshot.DownloadSnapshot)
+		v1.GET("/bsc/address/:address/activity", h.Activity.GetActivity)
+		v1.GET("/names/resolve", h.Name.Resolve)
+		v1.GET("/names/reverse/:address", h.Name.Reverse)
+		v1.GET("/alerts/recent", h.Alert.ListAlerts)
+		v1.GET("/alerts/scheduled", h.Alert.ListScheduledAlerts)
+		v1.POST("/alerts/scheduled", h.Alert.CreateScheduledAlert)
+		v1.GET("/alerts/scheduled/:id", h.Alert.GetScheduledAlert)
+		v1.PUT("/alerts/scheduled/:id", h.Alert.UpdateScheduledAlert)
+		v1.DELETE("/alerts/scheduled/:id", h.Alert.DeleteScheduledAlert)
+		v1.GET("/alerts", h.Alert.ListRules)
+		v1.POST("/alerts", h.Alert.CreateRule)
+		v1.GET("/alerts/:id", h.Alert.GetRule)
+		v1.PUT("/alerts/:id", h.Alert.UpdateRule)
+		v1.DELETE("/alerts/:id", h.Alert.DeleteRule)
+		v1.POST("/alerts/:id/test", h.Alert.TestRule)
+		v1.GET("/alerts/:id/history", h.Alert.GetRuleHistory)
+		v1.GET("/webhooks", h.Webhook.ListUserWebhooks)
+		v1.POST("/webhooks", h.Webhook.CreateUserWebhook)
+		v1.DELETE("/webhooks/:id", h.Webhook.DeleteUserWebhook)
+		v1.GET("/webhooks/:id/deliveries", h.Webhook.ListUserDeliveries)
+		v1.POST("/webhooks/:id/deliveries/:delivery_id/redrive", h.Webhook.RedriveUserDelivery)
+
+		// 管理路由，需要security.admin.token
+		admin := v1.Group("/admin", middleware.AdminAuth(cfg.Security.Admin.Token))
+		{
+			admin.GET("/budgets", h.Budget.GetUsage)
+			admin.GET("/config", h.Config.GetConfig)
+			admin.GET("/jobs", h.Jobs.ListJobs)
+			admin.POST("/jobs/:name/run", h.Jobs.RunJob)
+			admin.GET("/jobs/:name/runs", h.Jobs.ListJobRuns)
+			admin.GET("/state/export", h.Backup.ExportState)
+			admin.POST("/state/restore", h.Backup.RestoreState)
+			admin.GET("/analytics/usage", h.Analytics.GetUsage)
+			admin.GET("/webhooks/deliveries", h.Webhook.ListDeliveries)
+			admin.GET("/webhooks/deliveries/:id", h.Webhook.GetDelivery)
+			admin.POST("/webhooks/deliveries/:id/redrive", h.Webhook.RedriveDelivery)
+			admin.POST("/ingest/scan", h.Token.TriggerIngest)
+			admin.POST("/tokens/sync", h.Token.TriggerSync)
+		}
+
+		v1.GET("/version", h.Version.GetVersion)
+		v1.GET("/status/sla", h.Status.GetSLA)
+		v1.GET("/status/breakers", h.Status.GetBreakers)
+
+		// 实时推送路由
+		stream := v1.Group("/stream")
+		{
+			stream.GET("/ws", h.Stream.Connect)
+			stream.GET("/sse", h.Stream.Events)
+			stream.POST("/subscriptions", h.Stream.CreateSubscription)
+			stream.GET("/subscriptions/:id", h.Stream.GetSubscription)
+			stream.PUT("/subscriptions/:id", h.Stream.UpdateSubscription)
+			stream.DELETE("/subscriptions/:id", h.Stream.DeleteSubscription)
+			stream.GET("/stats", h.Stream.GetStats)
+		}
+
+		// 数据文件导入路由
+		ingest := v1.Group("/ingest")
+		{
+			ingest.GET("/log", h.Token.GetIngestLog)
+		}
+
 		// BSC链上数据监控路由
-		if bscHandler != nil {
+		if h.BSC != nil {
 			bsc := v1.Group("/bsc")
 			{
-				bsc.GET("/status", bscHandler.GetStatus)
-				bsc.GET("/block/latest", bscHandler.GetLatestBlock)
-				bsc.GET("/transactions", bscHandler.GetTransactions)
-				bsc.GET("/token/transfers", bscHandler.GetTokenTransfers)
-				bsc.GET("/swap/events", bscHandler.GetSwapEvents)
-				bsc.GET("/pair/info", bscHandler.GetPairInfo)
-				bsc.POST("/monitoring/start", bscHandler.StartMonitoring)
-				bsc.POST("/monitoring/stop", bscHandler.StopMonitoring)
+				bsc.GET("/status", h.BSC.GetStatus)
+				bsc.GET("/stats/history", h.BSC.GetStatsHistory)
+				bsc.GET("/block/latest", h.BSC.GetLatestBlock)
+				bsc.GET("/network/congestion", h.BSC.GetCongestion)
+				bsc.GET("/validators", h.BSC.GetValidators)
+				bsc.GET("/transactions", h.BSC.GetTransactions)
+				bsc.GET("/token/transfers", h.BSC.GetTokenTransfers)
+				bsc.GET("/swap/events", h.BSC.GetSwapEvents)
+				bsc.GET("/pair/info", h.BSC.GetPairInfo)
+				bsc.POST("/monitoring/start", h.BSC.StartMonitoring)
+				bsc.POST("/monitoring/stop", h.BSC.StopMonitoring)
 			}
 		}
 
 		// Session相关路由
-		if sessionHandler != nil {
+		if h.Session != nil {
 			session := v1.Group("/session")
 			{
-				session.GET("/info", sessionHandler.GetSession)
-				session.GET("/status", sessionHandler.SessionStatus)
-				session.POST("/data", sessionHandler.SetSessionData)
-				session.GET("/data/:key", sessionHandler.GetSessionData)
-				session.DELETE("/data/:key", sessionHandler.RemoveSessionData)
-				session.POST("/refresh", sessionHandler.RefreshSession)
-				session.DELETE("/destroy", sessionHandler.DestroySession)
+				session.GET("/info", h.Session.GetSession)
+				session.GET("/status", h.Session.SessionStatus)
+				session.POST("/data", h.Session.SetSessionData)
+				session.GET("/data/:key", h.Session.GetSessionData)
+				session.DELETE("/data/:key", h.Session.RemoveSessionData)
+				session.POST("/refresh", h.Session.RefreshSession)
+				session.DELETE("/destroy", h.Session.DestroySession)
+				session.GET("/preferences", h.Session.GetPreferences)
+				session.PUT("/preferences", h.Session.SetPreferences)
 			}
 		}
 	}
 
+	// 就绪探针，包含上游依赖延迟
+	router.GET("/readyz", h.Health.Readyz)
+
 	// 兼容旧版路由
-	router.GET("/crypto/price", priceHandler.GetPrice)
-	router.GET("/btc-price", priceHandler.GetBTCPrice)
-	router.GET("/crypto/volume/analysis", volumeHandler.GetVolumeAnalysis)
-	router.GET("/crypto/volume/fluctuation", volumeHandler.GetMarketVolumeFluctuation)
-	router.GET("/crypto/volume/comparison", volumeHandler.GetVolumeComparison)
-	router.GET("/crypto/volume/top", volumeHandler.GetTopVolumeCoins)
+	router.GET("/crypto/price", h.Price.GetPrice)
+	router.GET("/btc-price", h.Price.GetBTCPrice)
+	router.GET("/crypto/volume/analysis", h.Volume.GetVolumeAnalysis)
+	router.GET("/crypto/volume/fluctuation", h.Volume.GetMarketVolumeFluctuation)
+	router.GET("/crypto/volume/comparison", h.Volume.GetVolumeComparison)
+	router.GET("/crypto/volume/top", h.Volume.GetTopVolumeCoins)
 
 	// 根路径
 	router.GET("/", func(c *gin.Context) {
@@ -208,4 +319,4 @@ func setupRoutes(router *gin.Engine, cfg *config.Config, redisClient database.Re
 			"status":  "running",
 		})
 	})
-}
\ No newline at end of file
+}
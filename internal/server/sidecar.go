@@ -0,0 +1,447 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"crypto-info/internal/bootstrap"
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/apikey"
+	"crypto-info/internal/pkg/breaker"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/chaos"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/middleware"
+	"crypto-info/internal/pkg/ratelimit"
+	"crypto-info/internal/service"
+	healthv1 "crypto-info/kitex_gen/grpc/health/v1"
+)
+
+// defaultMetricsPath 未配置monitoring.metrics.path时的指标路径
+const defaultMetricsPath = "/metrics"
+
+// SidecarServer 独立端口上的HTTP探针与指标服务，gRPC-only部署时为Kubernetes探针和Prometheus提供入口
+type SidecarServer struct {
+	server        *http.Server
+	config        *config.Config
+	logger        logger.Logger
+	healthService service.HealthService
+	streamService service.StreamService
+	bscService    service.BSCService // BSC服务创建失败时为空
+	rateLimiter   *ratelimit.Limiter
+	concurrency   *middleware.ConcurrencyLimiter
+	loadShedder   *middleware.LoadShedder
+	grpcServer    *GRPCServer
+	startedAt     time.Time
+	shuttingDown  atomic.Bool
+}
+
+// NewSidecarServer 创建探针与指标服务，grpcServer为空时/healthz只反映进程状态
+func NewSidecarServer(c *bootstrap.Container, grpcServer *GRPCServer) *SidecarServer {
+	cfg := c.Config
+	s := &SidecarServer{
+		config:        cfg,
+		logger:        c.Logger,
+		healthService: c.Services.Health,
+		streamService: c.Services.Stream,
+		bscService:    c.Services.BSC,
+		rateLimiter:   c.RateLimiter,
+		concurrency:   c.Concurrency,
+		loadShedder:   c.LoadShedder,
+		grpcServer:    grpcServer,
+		startedAt:     time.Now(),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	if cfg.Monitoring.Metrics.Enabled {
+		path := cfg.Monitoring.Metrics.Path
+		if path == "" {
+			path = defaultMetricsPath
+		}
+		mux.HandleFunc(path, s.handleMetrics)
+	}
+
+	s.server = &http.Server{
+		Addr:              cfg.GetMetricsAddr(),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+	return s
+}
+
+// Start 启动探针与指标服务
+func (s *SidecarServer) Start() error {
+	s.logger.Infof("Sidecar server starting on %s", s.server.Addr)
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 关闭探针与指标服务，关闭开始后/healthz立即返回503
+func (s *SidecarServer) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
+	s.logger.Info("Sidecar server shutting down...")
+	return s.server.Shutdown(ctx)
+}
+
+// handleHealthz 进程和gRPC服务均正常时返回200，否则返回503
+func (s *SidecarServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	statusCode := http.StatusOK
+	body := map[string]interface{}{
+		"status":    "ok",
+		"service":   "crypto-info",
+		"timestamp": time.Now().Unix(),
+	}
+
+	if s.shuttingDown.Load() {
+		statusCode = http.StatusServiceUnavailable
+		body["status"] = "shutting_down"
+	}
+	if s.grpcServer != nil {
+		grpcStatus := s.grpcServer.health.Statuses()[""]
+		body["grpc"] = grpcStatus.String()
+		if grpcStatus != healthv1.HealthCheckResponse_SERVING && statusCode == http.StatusOK {
+			statusCode = http.StatusServiceUnavailable
+			body["status"] = "unavailable"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		s.logger.Errorf("Failed to write healthz response: %v", err)
+	}
+}
+
+// handleReadyz 与HTTP服务器的/readyz一致，行情来源全部不可用时返回503
+func (s *SidecarServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	readiness := s.healthService.Readiness(r.Context())
+
+	statusCode := http.StatusOK
+	if readiness.Status == model.HealthStatusDown || s.shuttingDown.Load() {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Health-Status", readiness.Status)
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(readiness); err != nil {
+		s.logger.Errorf("Failed to write readyz response: %v", err)
+	}
+}
+
+// handleMetrics 以Prometheus文本格式输出进程运行时指标、gRPC服务状态、上游API Key用量、调用预算和重试、推送连接、请求限流、并发和BSC网络拥堵
+func (s *SidecarServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var b strings.Builder
+	writeMetric(&b, "crypto_info_up", "gauge", "Whether the service process is up.", 1)
+	writeMetric(&b, "crypto_info_uptime_seconds", "gauge", "Seconds since the service process started.", time.Since(s.startedAt).Seconds())
+	writeMetric(&b, "go_goroutines", "gauge", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine()))
+	writeMetric(&b, "go_memstats_alloc_bytes", "gauge", "Number of bytes allocated and still in use.", float64(mem.Alloc))
+	writeMetric(&b, "go_memstats_sys_bytes", "gauge", "Number of bytes obtained from system.", float64(mem.Sys))
+	writeMetric(&b, "go_memstats_heap_objects", "gauge", "Number of allocated objects.", float64(mem.HeapObjects))
+	writeMetric(&b, "go_gc_cycles_total", "counter", "Number of completed GC cycles.", float64(mem.NumGC))
+
+	if s.grpcServer != nil {
+		statuses := s.grpcServer.health.Statuses()
+		services := make([]string, 0, len(statuses))
+		for name := range statuses {
+			services = append(services, name)
+		}
+		sort.Strings(services)
+
+		b.WriteString("# HELP crypto_info_grpc_serving Whether the gRPC service reports SERVING, empty service means the whole server.\n")
+		b.WriteString("# TYPE crypto_info_grpc_serving gauge\n")
+		for _, name := range services {
+			serving := 0
+			if statuses[name] == healthv1.HealthCheckResponse_SERVING {
+				serving = 1
+			}
+			fmt.Fprintf(&b, "crypto_info_grpc_serving{service=%q} %d\n", name, serving)
+		}
+	}
+
+	writeAPIKeyMetrics(&b, apikey.All())
+	writeBudgetMetrics(&b, budget.Default().Usage())
+	writeRetryMetrics(&b, httpclient.AllRetryStats())
+	writeStreamMetrics(&b, s.streamService.Metrics())
+	if s.rateLimiter != nil {
+		writeRateLimitMetrics(&b, s.rateLimiter.Stats())
+	}
+	if s.concurrency != nil {
+		writeConcurrencyMetrics(&b, s.concurrency.Stats())
+	}
+	if s.loadShedder != nil {
+		writeSheddingMetrics(&b, s.loadShedder.Stats())
+	}
+	writeChaosMetrics(&b, chaos.Default().Stats())
+	writeBreakerMetrics(&b, breaker.Default().Status())
+	if s.bscService != nil && s.config.BSC.Enabled {
+		congestion, err := s.bscService.GetCongestion(r.Context())
+		if err != nil {
+			s.logger.Warnf("Failed to get BSC network congestion for metrics: %v", err)
+		} else {
+			writeCongestionMetrics(&b, congestion)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := w.Write([]byte(b.String())); err != nil {
+		s.logger.Errorf("Failed to write metrics response: %v", err)
+	}
+}
+
+// writeAPIKeyMetrics 输出各上游API Key的请求数、限流次数和暂停状态，Key已脱敏
+func writeAPIKeyMetrics(b *strings.Builder, pools []*apikey.Pool) {
+	type keySample struct {
+		labels string
+		stats  apikey.KeyStats
+	}
+	var samples []keySample
+	for _, pool := range pools {
+		for _, stats := range pool.Stats() {
+			samples = append(samples, keySample{labels: fmt.Sprintf("provider=%q,key=%q", pool.Name(), stats.Key), stats: stats})
+		}
+	}
+	if len(samples) == 0 {
+		return
+	}
+
+	b.WriteString("# HELP crypto_info_api_key_requests_total Upstream API requests sent with each key.\n")
+	b.WriteString("# TYPE crypto_info_api_key_requests_total counter\n")
+	for _, sample := range samples {
+		fmt.Fprintf(b, "crypto_info_api_key_requests_total{%s} %d\n", sample.labels, sample.stats.Requests)
+	}
+	b.WriteString("# HELP crypto_info_api_key_rate_limited_total Upstream rate limit responses per key.\n")
+	b.WriteString("# TYPE crypto_info_api_key_rate_limited_total counter\n")
+	for _, sample := range samples {
+		fmt.Fprintf(b, "crypto_info_api_key_rate_limited_total{%s} %d\n", sample.labels, sample.stats.RateLimited)
+	}
+	b.WriteString("# HELP crypto_info_api_key_paused Whether the key is paused after hitting the upstream rate limit.\n")
+	b.WriteString("# TYPE crypto_info_api_key_paused gauge\n")
+	for _, sample := range samples {
+		paused := 0
+		if !sample.stats.PausedUntil.IsZero() {
+			paused = 1
+		}
+		fmt.Fprintf(b, "crypto_info_api_key_paused{%s} %d\n", sample.labels, paused)
+	}
+}
+
+// writeBudgetMetrics 输出各外部提供方的调用次数、预算和只读缓存模式状态
+func writeBudgetMetrics(b *strings.Builder, usage []model.ProviderUsage) {
+	if len(usage) == 0 {
+		return
+	}
+
+	b.WriteString("# HELP crypto_info_provider_calls_total External provider calls since process start.\n")
+	b.WriteString("# TYPE crypto_info_provider_calls_total counter\n")
+	for _, u := range usage {
+		fmt.Fprintf(b, "crypto_info_provider_calls_total{provider=%q} %d\n", u.Provider, u.TotalCalls)
+	}
+	b.WriteString("# HELP crypto_info_provider_calls Calls in the current budget window.\n")
+	b.WriteString("# TYPE crypto_info_provider_calls gauge\n")
+	for _, u := range usage {
+		fmt.Fprintf(b, "crypto_info_provider_calls{provider=%q,window=\"hour\"} %d\n", u.Provider, u.HourlyCalls)
+		fmt.Fprintf(b, "crypto_info_provider_calls{provider=%q,window=\"day\"} %d\n", u.Provider, u.DailyCalls)
+	}
+	b.WriteString("# HELP crypto_info_provider_budget Configured call budget per window, 0 means unlimited.\n")
+	b.WriteString("# TYPE crypto_info_provider_budget gauge\n")
+	for _, u := range usage {
+		fmt.Fprintf(b, "crypto_info_provider_budget{provider=%q,window=\"hour\"} %d\n", u.Provider, u.HourlyBudget)
+		fmt.Fprintf(b, "crypto_info_provider_budget{provider=%q,window=\"day\"} %d\n", u.Provider, u.DailyBudget)
+	}
+	b.WriteString("# HELP crypto_info_provider_rejected_total Calls rejected because the budget was exceeded.\n")
+	b.WriteString("# TYPE crypto_info_provider_rejected_total counter\n")
+	for _, u := range usage {
+		fmt.Fprintf(b, "crypto_info_provider_rejected_total{provider=%q} %d\n", u.Provider, u.Rejected)
+	}
+	b.WriteString("# HELP crypto_info_provider_cache_only Whether the provider is in cache-only mode.\n")
+	b.WriteString("# TYPE crypto_info_provider_cache_only gauge\n")
+	for _, u := range usage {
+		cacheOnly := 0
+		if u.Exceeded {
+			cacheOnly = 1
+		}
+		fmt.Fprintf(b, "crypto_info_provider_cache_only{provider=%q} %d\n", u.Provider, cacheOnly)
+	}
+}
+
+// writeRetryMetrics 输出各外部数据源的调用、实际请求、重试和重试后仍失败的次数
+func writeRetryMetrics(b *strings.Builder, stats []httpclient.RetryStats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	b.WriteString("# HELP crypto_info_upstream_calls_total External API calls, retries of one call count once.\n")
+	b.WriteString("# TYPE crypto_info_upstream_calls_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_upstream_calls_total{source=%q} %d\n", st.Source, st.Calls)
+	}
+	b.WriteString("# HELP crypto_info_upstream_attempts_total External API requests sent, including retries.\n")
+	b.WriteString("# TYPE crypto_info_upstream_attempts_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_upstream_attempts_total{source=%q} %d\n", st.Source, st.Attempts)
+	}
+	b.WriteString("# HELP crypto_info_upstream_retries_total External API requests retried after a retryable error.\n")
+	b.WriteString("# TYPE crypto_info_upstream_retries_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_upstream_retries_total{source=%q} %d\n", st.Source, st.Retries)
+	}
+	b.WriteString("# HELP crypto_info_upstream_failures_total External API calls that failed after all retries.\n")
+	b.WriteString("# TYPE crypto_info_upstream_failures_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_upstream_failures_total{source=%q} %d\n", st.Source, st.Failures)
+	}
+}
+
+// writeStreamMetrics 输出本实例的WebSocket连接数和事件计数，node标签区分实例
+func writeStreamMetrics(b *strings.Builder, m service.StreamMetrics) {
+	fmt.Fprintf(b, "# HELP crypto_info_stream_connections WebSocket connections on this node.\n# TYPE crypto_info_stream_connections gauge\ncrypto_info_stream_connections{node=%q} %d\n", m.Node, m.Connections)
+	fmt.Fprintf(b, "# HELP crypto_info_stream_published_total Stream events published by this node.\n# TYPE crypto_info_stream_published_total counter\ncrypto_info_stream_published_total{node=%q} %d\n", m.Node, m.Published)
+	fmt.Fprintf(b, "# HELP crypto_info_stream_delivered_total Stream events delivered to local connections.\n# TYPE crypto_info_stream_delivered_total counter\ncrypto_info_stream_delivered_total{node=%q} %d\n", m.Node, m.Delivered)
+	fmt.Fprintf(b, "# HELP crypto_info_stream_dropped_total Stream events dropped because a connection buffer was full.\n# TYPE crypto_info_stream_dropped_total counter\ncrypto_info_stream_dropped_total{node=%q} %d\n", m.Node, m.Dropped)
+}
+
+// writeRateLimitMetrics 输出请求限流跟踪的客户端数量、放行和拒绝次数
+func writeRateLimitMetrics(b *strings.Builder, stats ratelimit.Stats) {
+	writeMetric(b, "crypto_info_rate_limit_keys", "gauge", "Client keys currently tracked by the rate limiter.", float64(stats.Keys))
+	writeMetric(b, "crypto_info_rate_limit_allowed_total", "counter", "Requests allowed by the rate limiter.", float64(stats.Allowed))
+	writeMetric(b, "crypto_info_rate_limit_rejected_total", "counter", "Requests rejected by the rate limiter.", float64(stats.Rejected))
+	writeMetric(b, "crypto_info_rate_limit_evicted_total", "counter", "Idle client keys evicted by cleanup.", float64(stats.Evicted))
+	writeMetric(b, "crypto_info_rate_limit_rejection_ratio", "gauge", "Share of requests rejected during the last cleanup interval.", stats.RejectionRatio)
+}
+
+// writeConcurrencyMetrics 输出各路由分组正在处理的请求数、并发上限和拒绝次数，group="all"为实例全局
+func writeConcurrencyMetrics(b *strings.Builder, stats []middleware.ConcurrencyStats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	b.WriteString("# HELP crypto_info_http_in_flight HTTP requests currently being handled.\n")
+	b.WriteString("# TYPE crypto_info_http_in_flight gauge\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_http_in_flight{group=%q} %d\n", st.Group, st.InFlight)
+	}
+	b.WriteString("# HELP crypto_info_http_max_in_flight Configured concurrent request limit.\n")
+	b.WriteString("# TYPE crypto_info_http_max_in_flight gauge\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_http_max_in_flight{group=%q} %d\n", st.Group, st.MaxInFlight)
+	}
+	b.WriteString("# HELP crypto_info_http_concurrency_rejected_total Requests rejected with 503 because the group was saturated.\n")
+	b.WriteString("# TYPE crypto_info_http_concurrency_rejected_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_http_concurrency_rejected_total{group=%q} %d\n", st.Group, st.Rejected)
+	}
+}
+
+// writeSheddingMetrics 输出各降级策略是否生效和累计拒绝的请求数
+func writeSheddingMetrics(b *strings.Builder, stats []middleware.SheddingStats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	b.WriteString("# HELP crypto_info_load_shedding_active Whether the shedding policy is currently rejecting requests.\n")
+	b.WriteString("# TYPE crypto_info_load_shedding_active gauge\n")
+	for _, st := range stats {
+		active := 0
+		if st.Active {
+			active = 1
+		}
+		fmt.Fprintf(b, "crypto_info_load_shedding_active{policy=%q} %d\n", st.Policy, active)
+	}
+	b.WriteString("# HELP crypto_info_load_shedding_rejected_total Requests rejected with 503 because a dependency was degraded.\n")
+	b.WriteString("# TYPE crypto_info_load_shedding_rejected_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_load_shedding_rejected_total{policy=%q} %d\n", st.Policy, st.Shed)
+	}
+}
+
+// writeChaosMetrics 输出故障注入各目标的调用、延迟和错误次数，未启用故障注入时不输出
+func writeChaosMetrics(b *strings.Builder, stats []chaos.Stats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	b.WriteString("# HELP crypto_info_chaos_calls_total Calls that passed a fault injection point.\n")
+	b.WriteString("# TYPE crypto_info_chaos_calls_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_chaos_calls_total{target=%q} %d\n", st.Target, st.Calls)
+	}
+	b.WriteString("# HELP crypto_info_chaos_delayed_total Calls delayed by fault injection.\n")
+	b.WriteString("# TYPE crypto_info_chaos_delayed_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_chaos_delayed_total{target=%q} %d\n", st.Target, st.Delayed)
+	}
+	b.WriteString("# HELP crypto_info_chaos_failed_total Calls failed by fault injection.\n")
+	b.WriteString("# TYPE crypto_info_chaos_failed_total counter\n")
+	for _, st := range stats {
+		fmt.Fprintf(b, "crypto_info_chaos_failed_total{target=%q} %d\n", st.Target, st.Failed)
+	}
+}
+
+// writeBreakerMetrics 输出各价格数据源是否处于熔断中和进入熔断的次数
+func writeBreakerMetrics(b *strings.Builder, status *model.BreakerStatusResponse) {
+	if len(status.Sources) == 0 {
+		return
+	}
+
+	b.WriteString("# HELP crypto_info_price_source_circuit_open Whether the price source circuit breaker is open or half-open.\n")
+	b.WriteString("# TYPE crypto_info_price_source_circuit_open gauge\n")
+	for _, st := range status.Sources {
+		open := 0
+		if st.State != model.BreakerClosed {
+			open = 1
+		}
+		fmt.Fprintf(b, "crypto_info_price_source_circuit_open{source=%q} %d\n", st.Source, open)
+	}
+	b.WriteString("# HELP crypto_info_price_source_circuit_trips_total Times the price source circuit breaker opened.\n")
+	b.WriteString("# TYPE crypto_info_price_source_circuit_trips_total counter\n")
+	for _, st := range status.Sources {
+		fmt.Fprintf(b, "crypto_info_price_source_circuit_trips_total{source=%q} %d\n", st.Source, st.Trips)
+	}
+}
+
+// writeCongestionMetrics 输出BSC网络拥堵评分、区块利用率、Gas价格和待处理交易积压，节点未提供待处理交易数时不输出积压指标
+func writeCongestionMetrics(b *strings.Builder, c *model.BSCCongestion) {
+	writeMetric(b, "crypto_info_bsc_congestion_score", "gauge", "BSC network congestion score from 0 to 100.", c.Score)
+	writeMetric(b, "crypto_info_bsc_congestion_block_utilization", "gauge", "Average gasUsed/gasLimit ratio over the sampled blocks.", c.AvgUtilization)
+	writeMetric(b, "crypto_info_bsc_congestion_max_block_utilization", "gauge", "Highest gasUsed/gasLimit ratio among the sampled blocks.", c.MaxUtilization)
+	writeMetric(b, "crypto_info_bsc_congestion_full_blocks", "gauge", "Sampled blocks at least 95% full.", float64(c.FullBlocks))
+	writeMetric(b, "crypto_info_bsc_congestion_txs_per_block", "gauge", "Average transactions per sampled block.", c.AvgTxsPerBlock)
+
+	b.WriteString("# HELP crypto_info_bsc_congestion_gas_price_gwei Gas price in Gwei, average of the sampled blocks or suggested by the node.\n")
+	b.WriteString("# TYPE crypto_info_bsc_congestion_gas_price_gwei gauge\n")
+	fmt.Fprintf(b, "crypto_info_bsc_congestion_gas_price_gwei{kind=\"average\"} %g\n", c.AvgGasPriceGwei)
+	fmt.Fprintf(b, "crypto_info_bsc_congestion_gas_price_gwei{kind=\"suggested\"} %g\n", c.SuggestedGasPriceGwei)
+
+	if c.PendingTxs != nil {
+		fmt.Fprintf(b, "# HELP crypto_info_bsc_congestion_pending_txs Pending transactions reported by the node.\n# TYPE crypto_info_bsc_congestion_pending_txs gauge\ncrypto_info_bsc_congestion_pending_txs{source=%q} %d\n", c.PendingSource, *c.PendingTxs)
+	}
+	if c.BacklogBlocks != nil {
+		writeMetric(b, "crypto_info_bsc_congestion_backlog_blocks", "gauge", "Estimated blocks needed to clear pending transactions.", *c.BacklogBlocks)
+	}
+}
+
+// writeMetric 输出单个无标签指标
+func writeMetric(b *strings.Builder, name, metricType, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, metricType, name, value)
+}
@@ -0,0 +1,548 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/bscscan"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+// 地址动态默认配置
+const (
+	defaultActivityPageSize = 20
+	maxActivityPageSize     = 100
+	maxActivityDepth        = 1000 // 合并多个数据源时最多向前翻的记录数
+	activityCacheTTL        = 30 * time.Second
+	activityCacheKeyPrefix  = "activity:"
+	approveMethodID         = "0x095ea7b3"
+	nativeSymbol            = "BNB"
+	nativeDecimals          = 18
+)
+
+// unlimitedAllowance 授权额度达到该值时视为无限授权
+var unlimitedAllowance = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// ActivityService 地址动态服务接口
+type ActivityService interface {
+	// GetActivity 获取地址的转账、兑换、授权等动态，types为空时返回全部类型
+	GetActivity(ctx context.Context, address string, types []string, page, pageSize int) (*model.AddressActivityResponse, error)
+}
+
+// activityService 合并BscScan的普通交易、内部转账和代币转账，按交易归类为可读的动态
+type activityService struct {
+	redisClient  database.RedisClient
+	config       *config.Config
+	tokenService TokenService
+	nameService  NameService
+	bscscan      *bscscan.Client
+	logger       logger.Logger
+}
+
+// activityTx 同一笔交易中与地址相关的记录
+type activityTx struct {
+	hash      string
+	block     uint64
+	timestamp time.Time
+	tx        *bscscan.Transaction
+	internals []bscscan.InternalTransaction
+	transfers []bscscan.TokenTransfer
+}
+
+// activityToken 代币符号和精度
+type activityToken struct {
+	symbol   string
+	decimals int32
+	known    bool
+}
+
+// activityPage 缓存的合并结果
+type activityPage struct {
+	Items []model.ActivityItem `json:"items"` // 未生成描述的动态
+	Full  bool                 `json:"full"`  // 任一数据源返回满额记录，可能还有更早的数据
+}
+
+// NewActivityService 创建地址动态服务，tokenService用于解析代币和地址标签，nameService用于解析域名，均可为nil
+func NewActivityService(redisClient database.RedisClient, cfg *config.Config, tokenService TokenService, nameService NameService) ActivityService {
+	return &activityService{
+		redisClient:  redisClient,
+		config:       cfg,
+		tokenService: tokenService,
+		nameService:  nameService,
+		bscscan:      bscscan.NewClient(&cfg.ExternalAPI.BscScan),
+		logger:       logger.GetLogger(),
+	}
+}
+
+// GetActivity 获取地址动态，按区块倒序分页，address也可以是.bnb等域名
+func (s *activityService) GetActivity(ctx context.Context, address string, types []string, page, pageSize int) (*model.AddressActivityResponse, error) {
+	name := ""
+	if !common.IsHexAddress(address) && strings.Contains(address, ".") && s.nameService != nil {
+		record, err := s.nameService.Resolve(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		address, name = record.Address, record.Name
+	}
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidParameter, address)
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultActivityPageSize
+	}
+	if pageSize > maxActivityPageSize {
+		pageSize = maxActivityPageSize
+	}
+	depth := page * pageSize
+	if depth > maxActivityDepth {
+		return nil, fmt.Errorf("%w: only the latest %d records can be paged", ErrInvalidParameter, maxActivityDepth)
+	}
+
+	wanted := make(map[string]bool, len(types))
+	for _, t := range types {
+		switch t {
+		case model.ActivityNativeTransfer, model.ActivityTokenTransfer, model.ActivitySwap, model.ActivityApproval, model.ActivityContractCall:
+			wanted[t] = true
+		default:
+			return nil, fmt.Errorf("%w: unsupported activity type %q", ErrInvalidParameter, t)
+		}
+	}
+
+	address = strings.ToLower(common.HexToAddress(address).Hex())
+	result, err := s.load(ctx, address, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	items := result.Items
+	if len(wanted) > 0 {
+		items = make([]model.ActivityItem, 0, len(result.Items))
+		for _, item := range result.Items {
+			if wanted[item.Type] {
+				items = append(items, item)
+			}
+		}
+	}
+
+	resp := &model.AddressActivityResponse{
+		Address:  address,
+		Name:     name,
+		Items:    []model.ActivityItem{},
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  len(items) > depth || result.Full,
+		Source:   model.DataSourceBscScan,
+	}
+	if start := (page - 1) * pageSize; start < len(items) {
+		resp.Items = items[start:min(depth, len(items))]
+	}
+
+	// 只为当前页查询标签和域名
+	labels := s.resolveLabels(ctx, resp.Items)
+	names := make(map[string]string)
+	if s.nameService != nil {
+		addresses := make([]string, 0, len(resp.Items)+1)
+		if name == "" {
+			addresses = append(addresses, address)
+		}
+		for _, item := range resp.Items {
+			addresses = append(addresses, item.Counterparty)
+		}
+		names = s.nameService.ReverseMany(ctx, addresses)
+		if name == "" {
+			resp.Name = names[address]
+		}
+	}
+	for i := range resp.Items {
+		describeActivity(&resp.Items[i], labels, names)
+	}
+	return resp, nil
+}
+
+// load 读取三个数据源最近depth条记录并合并，结果短暂缓存以便翻页
+func (s *activityService) load(ctx context.Context, address string, depth int) (*activityPage, error) {
+	cacheKey := activityCacheKeyPrefix + address + ":" + strconv.Itoa(depth)
+	if s.redisClient != nil {
+		if data, err := s.redisClient.Get(ctx, cacheKey); err == nil && data != "" {
+			var cached activityPage
+			if err := json.Unmarshal([]byte(data), &cached); err == nil {
+				return &cached, nil
+			}
+		}
+	}
+
+	txs, err := s.bscscan.GetTransactions(ctx, address, 1, depth)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bscscan transactions: %w", ErrUpstreamUnavailable, err)
+	}
+	internals, err := s.bscscan.GetInternalTransactions(ctx, address, 1, depth)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bscscan internal transactions: %w", ErrUpstreamUnavailable, err)
+	}
+	transfers, err := s.bscscan.GetTokenTransfers(ctx, "", address, 1, depth)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bscscan token transfers: %w", ErrUpstreamUnavailable, err)
+	}
+
+	groups := groupActivity(txs, internals, transfers)
+	tokens := s.resolveTokens(ctx, groups)
+
+	result := &activityPage{
+		Items: []model.ActivityItem{},
+		Full:  len(txs) >= depth || len(internals) >= depth || len(transfers) >= depth,
+	}
+	for _, group := range groups {
+		result.Items = append(result.Items, buildActivity(address, group, tokens)...)
+	}
+
+	if s.redisClient != nil {
+		if data, err := json.Marshal(result); err == nil {
+			if err := s.redisClient.Set(ctx, cacheKey, string(data), activityCacheTTL); err != nil {
+				s.logger.Warnf("Failed to cache activity for %s: %v", address, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// groupActivity 按交易哈希归并记录，按区块倒序返回
+func groupActivity(txs []bscscan.Transaction, internals []bscscan.InternalTransaction, transfers []bscscan.TokenTransfer) []*activityTx {
+	groups := make(map[string]*activityTx)
+	group := func(hash, block, timestamp string) *activityTx {
+		hash = strings.ToLower(hash)
+		g, ok := groups[hash]
+		if !ok {
+			g = &activityTx{hash: hash, block: parseUint(block), timestamp: parseUnixTime(timestamp)}
+			groups[hash] = g
+		}
+		return g
+	}
+
+	for i := range txs {
+		group(txs[i].Hash, txs[i].BlockNumber, txs[i].TimeStamp).tx = &txs[i]
+	}
+	for _, internal := range internals {
+		g := group(internal.Hash, internal.BlockNumber, internal.TimeStamp)
+		g.internals = append(g.internals, internal)
+	}
+	for _, transfer := range transfers {
+		g := group(transfer.Hash, transfer.BlockNumber, transfer.TimeStamp)
+		g.transfers = append(g.transfers, transfer)
+	}
+
+	ordered := make([]*activityTx, 0, len(groups))
+	for _, g := range groups {
+		ordered = append(ordered, g)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].block != ordered[j].block {
+			return ordered[i].block > ordered[j].block
+		}
+		return ordered[i].hash < ordered[j].hash
+	})
+	return ordered
+}
+
+// buildActivity 将一笔交易归类为动态：授权、兑换、逐笔转账或合约调用
+func buildActivity(address string, g *activityTx, tokens map[string]activityToken) []model.ActivityItem {
+	base := model.ActivityItem{
+		TxHash:      g.hash,
+		BlockNumber: g.block,
+		Timestamp:   g.timestamp,
+		Success:     true,
+	}
+	initiated := false
+	if g.tx != nil {
+		base.Success = g.tx.IsError != "1" && g.tx.TxReceiptStatus != "0"
+		base.Method = methodName(g.tx.FunctionName)
+		initiated = strings.EqualFold(g.tx.From, address)
+	}
+
+	if initiated && strings.EqualFold(g.tx.MethodID, approveMethodID) && len(g.tx.Input) >= 138 {
+		if item, ok := approvalActivity(base, g.tx, tokens); ok {
+			return []model.ActivityItem{item}
+		}
+	}
+
+	type leg struct {
+		model.ActivityLeg
+		counterparty string
+		native       bool
+	}
+	var legs []leg
+	addLeg := func(from, to, token, value string, decimals int32, native bool) {
+		raw := parseBigInt(value)
+		if raw.Sign() == 0 {
+			return
+		}
+		from, to = strings.ToLower(from), strings.ToLower(to)
+		l := leg{native: native}
+		switch {
+		case from == address && to == address:
+			l.Direction, l.counterparty = model.ActivityDirectionSelf, address
+		case from == address:
+			l.Direction, l.counterparty = model.ActivityDirectionOut, to
+		case to == address:
+			l.Direction, l.counterparty = model.ActivityDirectionIn, from
+		default:
+			return
+		}
+		l.Token = token
+		l.Symbol = nativeSymbol
+		if !native {
+			l.Symbol = tokens[token].symbol
+		}
+		l.Amount = decimal.NewFromBigInt(raw, -decimals).String()
+		legs = append(legs, l)
+	}
+
+	if g.tx != nil && base.Success {
+		addLeg(g.tx.From, g.tx.To, "", g.tx.Value, nativeDecimals, true)
+	}
+	for _, internal := range g.internals {
+		if internal.IsError != "1" {
+			addLeg(internal.From, internal.To, "", internal.Value, nativeDecimals, true)
+		}
+	}
+	for _, transfer := range g.transfers {
+		token := strings.ToLower(transfer.ContractAddress)
+		addLeg(transfer.From, transfer.To, token, transfer.Value, tokens[token].decimals, false)
+	}
+
+	hasIn, hasOut := false, false
+	for _, l := range legs {
+		hasIn = hasIn || l.Direction == model.ActivityDirectionIn
+		hasOut = hasOut || l.Direction == model.ActivityDirectionOut
+	}
+
+	// 自己发起且同时有转出和转入，视为一次兑换
+	if initiated && hasIn && hasOut {
+		item := base
+		item.Type = model.ActivitySwap
+		item.Direction = model.ActivityDirectionSelf
+		item.Counterparty = strings.ToLower(g.tx.To)
+		for _, l := range legs {
+			item.Legs = append(item.Legs, l.ActivityLeg)
+		}
+		return []model.ActivityItem{item}
+	}
+
+	if len(legs) == 0 {
+		if g.tx == nil || !initiated {
+			return nil
+		}
+		item := base
+		item.Type = model.ActivityContractCall
+		item.Direction = model.ActivityDirectionOut
+		item.Counterparty = strings.ToLower(g.tx.To)
+		if item.Counterparty == "" {
+			item.Counterparty = strings.ToLower(g.tx.ContractAddress)
+		}
+		return []model.ActivityItem{item}
+	}
+
+	items := make([]model.ActivityItem, 0, len(legs))
+	for _, l := range legs {
+		item := base
+		item.Type = model.ActivityTokenTransfer
+		if l.native {
+			item.Type = model.ActivityNativeTransfer
+		}
+		item.Direction = l.Direction
+		item.Counterparty = l.counterparty
+		item.Legs = []model.ActivityLeg{l.ActivityLeg}
+		items = append(items, item)
+	}
+	return items
+}
+
+// approvalActivity 解析approve(spender, amount)调用
+func approvalActivity(base model.ActivityItem, tx *bscscan.Transaction, tokens map[string]activityToken) (model.ActivityItem, bool) {
+	input := strings.TrimPrefix(tx.Input, "0x")
+	if len(input) < 136 {
+		return base, false
+	}
+	amount, ok := new(big.Int).SetString(input[72:136], 16)
+	if !ok {
+		return base, false
+	}
+
+	token := strings.ToLower(tx.To)
+	info := tokens[token]
+	leg := model.ActivityLeg{
+		Token:     token,
+		Symbol:    info.symbol,
+		Direction: model.ActivityDirectionOut,
+	}
+	switch {
+	case amount.Cmp(unlimitedAllowance) >= 0:
+		leg.Amount = "unlimited"
+	case amount.Sign() == 0 || info.known:
+		leg.Amount = decimal.NewFromBigInt(amount, -info.decimals).String()
+	}
+
+	item := base
+	item.Type = model.ActivityApproval
+	item.Direction = model.ActivityDirectionOut
+	item.Counterparty = strings.ToLower(common.HexToAddress(input[32:72]).Hex())
+	item.Legs = []model.ActivityLeg{leg}
+	return item, true
+}
+
+// describeActivity 补充对手方标签和域名并生成可读描述，优先使用标签
+func describeActivity(item *model.ActivityItem, labels, names map[string]string) {
+	item.CounterpartyLabel = labels[item.Counterparty]
+	item.CounterpartyName = names[item.Counterparty]
+	party := item.CounterpartyLabel
+	if party == "" {
+		party = item.CounterpartyName
+	}
+	if party == "" {
+		party = shortAddress(item.Counterparty)
+	}
+
+	switch item.Type {
+	case model.ActivityApproval:
+		leg := item.Legs[0]
+		symbol := legSymbol(leg)
+		switch leg.Amount {
+		case "0":
+			item.Description = fmt.Sprintf("Revoked %s approval for %s", symbol, party)
+		case "unlimited":
+			item.Description = fmt.Sprintf("Approved %s to spend unlimited %s", party, symbol)
+		case "":
+			item.Description = fmt.Sprintf("Approved %s to spend %s", party, symbol)
+		default:
+			item.Description = fmt.Sprintf("Approved %s to spend %s %s", party, leg.Amount, symbol)
+		}
+	case model.ActivitySwap:
+		var sold, bought []string
+		for _, leg := range item.Legs {
+			switch leg.Direction {
+			case model.ActivityDirectionOut:
+				sold = append(sold, leg.Amount+" "+legSymbol(leg))
+			case model.ActivityDirectionIn:
+				bought = append(bought, leg.Amount+" "+legSymbol(leg))
+			}
+		}
+		item.Description = fmt.Sprintf("Swapped %s for %s on %s", strings.Join(sold, " + "), strings.Join(bought, " + "), party)
+	case model.ActivityNativeTransfer, model.ActivityTokenTransfer:
+		leg := item.Legs[0]
+		amount := leg.Amount + " " + legSymbol(leg)
+		switch item.Direction {
+		case model.ActivityDirectionIn:
+			item.Description = fmt.Sprintf("Received %s from %s", amount, party)
+		case model.ActivityDirectionSelf:
+			item.Description = fmt.Sprintf("Sent %s to self", amount)
+		default:
+			item.Description = fmt.Sprintf("Sent %s to %s", amount, party)
+		}
+	default:
+		switch {
+		case item.Method != "":
+			item.Description = fmt.Sprintf("Called %s on %s", item.Method, party)
+		case item.Counterparty != "":
+			item.Description = fmt.Sprintf("Interacted with %s", party)
+		default:
+			item.Description = "Contract interaction"
+		}
+	}
+
+	if !item.Success {
+		item.Description = "Failed: " + item.Description
+	}
+}
+
+// resolveTokens 收集代币符号和精度，授权的代币未出现在转账中时查询代币注册表
+func (s *activityService) resolveTokens(ctx context.Context, groups []*activityTx) map[string]activityToken {
+	tokens := make(map[string]activityToken)
+	for _, g := range groups {
+		for _, transfer := range g.transfers {
+			address := strings.ToLower(transfer.ContractAddress)
+			if _, ok := tokens[address]; ok {
+				continue
+			}
+			decimals, err := strconv.ParseInt(transfer.TokenDecimal, 10, 32)
+			tokens[address] = activityToken{symbol: transfer.TokenSymbol, decimals: int32(decimals), known: err == nil}
+		}
+	}
+
+	for _, g := range groups {
+		if g.tx == nil || !strings.EqualFold(g.tx.MethodID, approveMethodID) {
+			continue
+		}
+		address := strings.ToLower(g.tx.To)
+		if _, ok := tokens[address]; ok || s.tokenService == nil {
+			continue
+		}
+		info := activityToken{symbol: shortAddress(address)}
+		if token, err := s.tokenService.GetToken(ctx, address); err == nil {
+			info = activityToken{symbol: token.Symbol, decimals: int32(token.Decimals), known: true}
+		} else if !errors.Is(err, ErrNotFound) {
+			s.logger.Debugf("Failed to resolve token %s: %v", address, err)
+		}
+		tokens[address] = info
+	}
+	return tokens
+}
+
+// resolveLabels 查询对手方地址标签
+func (s *activityService) resolveLabels(ctx context.Context, items []model.ActivityItem) map[string]string {
+	labels := make(map[string]string)
+	if s.tokenService == nil {
+		return labels
+	}
+	for _, item := range items {
+		if item.Counterparty == "" {
+			continue
+		}
+		if _, seen := labels[item.Counterparty]; seen {
+			continue
+		}
+		labels[item.Counterparty] = ""
+		if label, err := s.tokenService.GetLabel(ctx, item.Counterparty); err == nil {
+			labels[item.Counterparty] = label.Label
+		}
+	}
+	return labels
+}
+
+// legSymbol 资产变动的代币符号，未知时使用合约地址缩写
+func legSymbol(leg model.ActivityLeg) string {
+	if leg.Symbol != "" {
+		return leg.Symbol
+	}
+	return shortAddress(leg.Token)
+}
+
+// methodName 从BscScan的函数签名中取出方法名
+func methodName(signature string) string {
+	if i := strings.Index(signature, "("); i >= 0 {
+		return signature[:i]
+	}
+	return signature
+}
+
+// shortAddress 缩写地址，如0x1234...abcd
+func shortAddress(address string) string {
+	if len(address) <= 12 {
+		return address
+	}
+	return address[:6] + "..." + address[len(address)-4:]
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+)
+
+// mysqlAlertRuleSchema 告警规则表，首次使用时创建；按用户列出和按币种评估分别走两个索引
+const mysqlAlertRuleSchema = `CREATE TABLE IF NOT EXISTS alert_rules (
+	id CHAR(36) NOT NULL,
+	user_id VARCHAR(128) NOT NULL,
+	name VARCHAR(64) NOT NULL DEFAULT '',
+	symbol VARCHAR(32) NOT NULL,
+	expression TEXT NOT NULL COMMENT '条件表达式',
+	type VARCHAR(32) NOT NULL DEFAULT '' COMMENT '按阈值类型创建时的类型',
+	threshold DOUBLE NULL,
+	cooldown_seconds INT NOT NULL,
+	enabled TINYINT(1) NOT NULL,
+	created_at BIGINT NOT NULL COMMENT '毫秒时间戳',
+	updated_at BIGINT NOT NULL COMMENT '毫秒时间戳',
+	PRIMARY KEY (id),
+	KEY idx_alert_rules_user (user_id, created_at),
+	KEY idx_alert_rules_symbol (symbol)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`
+
+// mysqlAlertRuleColumns 查询规则的列
+const mysqlAlertRuleColumns = `id, user_id, name, symbol, expression, type, threshold, cooldown_seconds, enabled, created_at, updated_at`
+
+// mysqlAlertRuleInsert 插入规则，迁移时使用INSERT IGNORE跳过已迁移的规则
+const mysqlAlertRuleInsert = ` INTO alert_rules (` + mysqlAlertRuleColumns + `)
+VALUES (:id, :user_id, :name, :symbol, :expression, :type, :threshold, :cooldown_seconds, :enabled, :created_at, :updated_at)`
+
+// mysqlAlertRuleStore 规则保存在MySQL表中，评估状态按用户保存在Redis哈希中
+//
+// 评估状态记录评估时的条件和币种，与规则不一致时(评估期间规则被修改)不使用，因此写入状态不需要读取规则；
+// 规则已删除时留下的状态不会被读取。Redis不可用时规则仍可管理，但不保存评估状态。
+type mysqlAlertRuleStore struct {
+	mysqlClient database.MySQLClient
+	redisClient database.RedisClient // 评估状态，可为空
+	legacy      *redisAlertRuleStore // 待迁移的Redis规则，redisClient为空时为空
+	logger      logger.Logger
+
+	schemaMu    sync.Mutex
+	schemaReady bool
+}
+
+// mysqlAlertRuleRow 规则表的行，JSON字段为列名，用于备份
+type mysqlAlertRuleRow struct {
+	ID              string   `db:"id" json:"id"`
+	UserID          string   `db:"user_id" json:"user_id"`
+	Name            string   `db:"name" json:"name"`
+	Symbol          string   `db:"symbol" json:"symbol"`
+	Expression      string   `db:"expression" json:"expression"`
+	Type            string   `db:"type" json:"type"`
+	Threshold       *float64 `db:"threshold" json:"threshold"`
+	CooldownSeconds int      `db:"cooldown_seconds" json:"cooldown_seconds"`
+	Enabled         bool     `db:"enabled" json:"enabled"`
+	CreatedAt       int64    `db:"created_at" json:"created_at"`
+	UpdatedAt       int64    `db:"updated_at" json:"updated_at"`
+}
+
+// alertRuleState 保存在Redis中的评估状态
+type alertRuleState struct {
+	Condition       string             `json:"condition"` // 评估时的条件和币种
+	Symbol          string             `json:"symbol"`
+	Triggered       bool               `json:"triggered"`
+	LastValues      map[string]float64 `json:"last_values,omitempty"`
+	LastEvaluatedAt *time.Time         `json:"last_evaluated_at,omitempty"`
+	LastTriggeredAt *time.Time         `json:"last_triggered_at,omitempty"`
+	LastError       string             `json:"last_error,omitempty"`
+}
+
+// newMySQLAlertRuleStore 创建MySQL告警规则存储
+func newMySQLAlertRuleStore(mysqlClient database.MySQLClient, redisClient database.RedisClient) *mysqlAlertRuleStore {
+	m := &mysqlAlertRuleStore{
+		mysqlClient: mysqlClient,
+		redisClient: redisClient,
+		logger:      logger.GetLogger(),
+	}
+	if redisClient != nil {
+		m.legacy = &redisAlertRuleStore{redisClient: redisClient, logger: m.logger}
+	}
+	return m
+}
+
+// ensureSchema 创建告警规则表，失败时下次调用重试
+func (m *mysqlAlertRuleStore) ensureSchema(ctx context.Context) error {
+	m.schemaMu.Lock()
+	defer m.schemaMu.Unlock()
+
+	if m.schemaReady {
+		return nil
+	}
+	if _, err := m.mysqlClient.DB().ExecContext(ctx, mysqlAlertRuleSchema); err != nil {
+		return fmt.Errorf("failed to create alert_rules table: %w", err)
+	}
+	m.schemaReady = true
+	return nil
+}
+
+// list 读取用户的规则和评估状态
+func (m *mysqlAlertRuleStore) list(ctx context.Context, userID string) ([]model.AlertRule, error) {
+	if err := m.ensureSchema(ctx); err != nil {
+		return nil, err
+	}
+	var rows []mysqlAlertRuleRow
+	if err := m.mysqlClient.DB().SelectContext(ctx, &rows,
+		`SELECT `+mysqlAlertRuleColumns+` FROM alert_rules WHERE user_id = ? ORDER BY created_at`, userID); err != nil {
+		return nil, err
+	}
+
+	var states map[string]string
+	if m.redisClient != nil && len(rows) > 0 {
+		entries, err := m.redisClient.HGetAll(ctx, alertRuleStateKey(userID))
+		if err != nil {
+			logger.From(ctx).Warnf("Failed to load alert rule states for user %s: %v", userID, err)
+		}
+		states = entries
+	}
+
+	rules := make([]model.AlertRule, 0, len(rows))
+	for _, row := range rows {
+		rule := row.toModel()
+		applyAlertRuleState(rule, states[rule.ID])
+		rules = append(rules, *rule)
+	}
+	return rules, nil
+}
+
+// get 读取规则和评估状态
+func (m *mysqlAlertRuleStore) get(ctx context.Context, userID, id string) (*model.AlertRule, error) {
+	if err := m.ensureSchema(ctx); err != nil {
+		return nil, err
+	}
+	var row mysqlAlertRuleRow
+	err := m.mysqlClient.DB().GetContext(ctx, &row,
+		`SELECT `+mysqlAlertRuleColumns+` FROM alert_rules WHERE id = ? AND user_id = ?`, id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rule := row.toModel()
+	if m.redisClient != nil {
+		state, err := m.redisClient.HGet(ctx, alertRuleStateKey(userID), id)
+		if err != nil {
+			logger.From(ctx).Warnf("Failed to load state of alert rule %s: %v", id, err)
+		}
+		applyAlertRuleState(rule, state)
+	}
+	return rule, nil
+}
+
+// create 插入规则，币种索引由表索引提供
+func (m *mysqlAlertRuleStore) create(ctx context.Context, rule *model.AlertRule) error {
+	if err := m.ensureSchema(ctx); err != nil {
+		return err
+	}
+	_, err := m.mysqlClient.DB().NamedExecContext(ctx, `INSERT`+mysqlAlertRuleInsert, newMySQLAlertRuleRow(rule))
+	return err
+}
+
+// update 更新规则的设置，并按规则写入评估状态(条件或币种变化时已由调用方重置)
+func (m *mysqlAlertRuleStore) update(ctx context.Context, rule *model.AlertRule, _ string) error {
+	if err := m.ensureSchema(ctx); err != nil {
+		return err
+	}
+	if _, err := m.mysqlClient.DB().NamedExecContext(ctx, `UPDATE alert_rules SET
+		name = :name, symbol = :symbol, expression = :expression, type = :type, threshold = :threshold,
+		cooldown_seconds = :cooldown_seconds, enabled = :enabled, updated_at = :updated_at
+		WHERE id = :id AND user_id = :user_id`, newMySQLAlertRuleRow(rule)); err != nil {
+		return err
+	}
+	return m.saveState(ctx, rule)
+}
+
+// delete 删除规则和评估状态
+func (m *mysqlAlertRuleStore) delete(ctx context.Context, rule *model.AlertRule) error {
+	if err := m.ensureSchema(ctx); err != nil {
+		return err
+	}
+	if _, err := m.mysqlClient.DB().ExecContext(ctx,
+		`DELETE FROM alert_rules WHERE id = ? AND user_id = ?`, rule.ID, rule.UserID); err != nil {
+		return err
+	}
+	if m.redisClient != nil {
+		if err := m.redisClient.HDel(ctx, alertRuleStateKey(rule.UserID), rule.ID); err != nil {
+			logger.From(ctx).Warnf("Failed to delete state of alert rule %s: %v", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+// symbols 有规则的币种
+func (m *mysqlAlertRuleStore) symbols(ctx context.Context) ([]string, error) {
+	if err := m.ensureSchema(ctx); err != nil {
+		return nil, err
+	}
+	var symbols []string
+	err := m.mysqlClient.DB().SelectContext(ctx, &symbols, `SELECT DISTINCT symbol FROM alert_rules ORDER BY symbol`)
+	return symbols, err
+}
+
+// bySymbol 按币种索引读取规则，评估状态通过一次pipeline读取
+func (m *mysqlAlertRuleStore) bySymbol(ctx context.Context, symbol string) ([]*model.AlertRule, error) {
+	if err := m.ensureSchema(ctx); err != nil {
+		return nil, err
+	}
+	var rows []mysqlAlertRuleRow
+	if err := m.mysqlClient.DB().SelectContext(ctx, &rows,
+		`SELECT `+mysqlAlertRuleColumns+` FROM alert_rules WHERE symbol = ? ORDER BY created_at`, symbol); err != nil {
+		return nil, err
+	}
+
+	rules := make([]*model.AlertRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, row.toModel())
+	}
+	if m.redisClient == nil || len(rules) == 0 {
+		return rules, nil
+	}
+
+	pipe := m.redisClient.GetClient().Pipeline()
+	cmds := make([]*redis.StringCmd, len(rules))
+	for i, rule := range rules {
+		cmds[i] = pipe.HGet(ctx, m.redisClient.KeyPrefix()+alertRuleStateKey(rule.UserID), rule.ID)
+	}
+	// 没有状态的规则返回redis.Nil，逐条读取结果
+	pipe.Exec(ctx)
+	for i, cmd := range cmds {
+		if value, err := cmd.Result(); err == nil {
+			applyAlertRuleState(rules[i], value)
+		}
+	}
+	return rules, nil
+}
+
+// saveState 将评估状态和评估时的条件、币种写入Redis，读取时与规则不一致的状态被忽略
+func (m *mysqlAlertRuleStore) saveState(ctx context.Context, rule *model.AlertRule) error {
+	if m.redisClient == nil {
+		return nil
+	}
+	data, err := json.Marshal(&alertRuleState{
+		Condition:       rule.Condition,
+		Symbol:          rule.Symbol,
+		Triggered:       rule.Triggered,
+		LastValues:      rule.LastValues,
+		LastEvaluatedAt: rule.LastEvaluatedAt,
+		LastTriggeredAt: rule.LastTriggeredAt,
+		LastError:       rule.LastError,
+	})
+	if err != nil {
+		return err
+	}
+	return m.redisClient.HSet(ctx, alertRuleStateKey(rule.UserID), rule.ID, string(data))
+}
+
+// migrate 将保存在Redis中的规则迁移到MySQL，评估状态一并迁移；迁移后从Redis删除
+//
+// 每轮评估前执行，Redis中没有规则时只读取一次币种索引；切换存储期间仍使用Redis存储的实例新建的规则在下一轮迁移。
+func (m *mysqlAlertRuleStore) migrate(ctx context.Context) error {
+	if m.legacy == nil {
+		return nil
+	}
+	if err := m.legacy.migrate(ctx); err != nil {
+		return err
+	}
+	symbols, err := m.legacy.symbols(ctx)
+	if err != nil || len(symbols) == 0 {
+		return err
+	}
+	if err := m.ensureSchema(ctx); err != nil {
+		return err
+	}
+
+	migrated := 0
+	for _, symbol := range symbols {
+		rules, err := m.legacy.bySymbol(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			result, err := m.mysqlClient.DB().NamedExecContext(ctx, `INSERT IGNORE`+mysqlAlertRuleInsert, newMySQLAlertRuleRow(rule))
+			if err != nil {
+				return fmt.Errorf("failed to migrate alert rule %s: %w", rule.ID, err)
+			}
+			// 已迁移过的规则以MySQL中的为准
+			if inserted, _ := result.RowsAffected(); inserted > 0 {
+				if err := m.saveState(ctx, rule); err != nil {
+					return fmt.Errorf("failed to migrate state of alert rule %s: %w", rule.ID, err)
+				}
+				migrated++
+			}
+			if err := m.legacy.delete(ctx, rule); err != nil {
+				return fmt.Errorf("failed to delete migrated alert rule %s: %w", rule.ID, err)
+			}
+		}
+	}
+	if migrated > 0 {
+		m.logger.Infof("Migrated %d alert rules from Redis to MySQL", migrated)
+	}
+	return nil
+}
+
+// exportAlertRuleRows 按创建时间读取规则表的全部行
+func exportAlertRuleRows(ctx context.Context, db *sqlx.DB) ([]json.RawMessage, error) {
+	var rows []mysqlAlertRuleRow
+	if err := db.SelectContext(ctx, &rows,
+		`SELECT `+mysqlAlertRuleColumns+` FROM alert_rules ORDER BY created_at, id`); err != nil {
+		return nil, err
+	}
+	data := make([]json.RawMessage, 0, len(rows))
+	for i := range rows {
+		row, err := json.Marshal(&rows[i])
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, row)
+	}
+	return data, nil
+}
+
+// validateAlertRuleRow 检查备份中的规则行，条件表达式在评估时编译，此处只检查必填的列
+func validateAlertRuleRow(data json.RawMessage) error {
+	var row mysqlAlertRuleRow
+	if err := json.Unmarshal(data, &row); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(row.ID); err != nil {
+		return fmt.Errorf("invalid id %q", row.ID)
+	}
+	if row.UserID == "" || row.Symbol == "" || row.Expression == "" {
+		return fmt.Errorf("rule %s requires user_id, symbol and expression", row.ID)
+	}
+	return nil
+}
+
+// restoreAlertRuleRow 写入规则行，overwrite为false时跳过已存在的规则，返回是否写入
+func restoreAlertRuleRow(ctx context.Context, db *sqlx.DB, data json.RawMessage, overwrite bool) (bool, error) {
+	var row mysqlAlertRuleRow
+	if err := json.Unmarshal(data, &row); err != nil {
+		return false, err
+	}
+	statement := `INSERT IGNORE`
+	if overwrite {
+		statement = `REPLACE`
+	}
+	result, err := db.NamedExecContext(ctx, statement+mysqlAlertRuleInsert, &row)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore alert rule %s: %w", row.ID, err)
+	}
+	written, err := result.RowsAffected()
+	return written > 0, err
+}
+
+// newMySQLAlertRuleRow 规则转换为表的行
+func newMySQLAlertRuleRow(rule *model.AlertRule) *mysqlAlertRuleRow {
+	return &mysqlAlertRuleRow{
+		ID:              rule.ID,
+		UserID:          rule.UserID,
+		Name:            rule.Name,
+		Symbol:          rule.Symbol,
+		Expression:      rule.Condition,
+		Type:            rule.Type,
+		Threshold:       rule.Threshold,
+		CooldownSeconds: rule.CooldownSeconds,
+		Enabled:         rule.Enabled,
+		CreatedAt:       rule.CreatedAt.UnixMilli(),
+		UpdatedAt:       rule.UpdatedAt.UnixMilli(),
+	}
+}
+
+// toModel 表的行转换为规则，不含评估状态
+func (row *mysqlAlertRuleRow) toModel() *model.AlertRule {
+	return &model.AlertRule{
+		ID:              row.ID,
+		UserID:          row.UserID,
+		Name:            row.Name,
+		Symbol:          row.Symbol,
+		Condition:       row.Expression,
+		Type:            row.Type,
+		Threshold:       row.Threshold,
+		CooldownSeconds: row.CooldownSeconds,
+		Enabled:         row.Enabled,
+		CreatedAt:       time.UnixMilli(row.CreatedAt),
+		UpdatedAt:       time.UnixMilli(row.UpdatedAt),
+	}
+}
+
+// applyAlertRuleState 将评估状态写入规则，状态为空、无法解析或评估时的条件、币种与规则不一致时忽略
+func applyAlertRuleState(rule *model.AlertRule, value string) {
+	if value == "" {
+		return
+	}
+	var state alertRuleState
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		return
+	}
+	if state.Condition != rule.Condition || state.Symbol != rule.Symbol {
+		return
+	}
+	rule.Triggered = state.Triggered
+	rule.LastValues = state.LastValues
+	rule.LastEvaluatedAt = state.LastEvaluatedAt
+	rule.LastTriggeredAt = state.LastTriggeredAt
+	rule.LastError = state.LastError
+}
+
+// alertRuleStateKey 用户告警规则评估状态的存储key，MySQL存储时使用
+func alertRuleStateKey(userID string) string {
+	return alertRuleStateKeyPrefix + userID
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"crypto-info/internal/model"
+)
+
+// TestMySQLAlertRuleRowRoundTrip 规则的设置写入表的行后原样读出，评估状态不写入表
+func TestMySQLAlertRuleRowRoundTrip(t *testing.T) {
+	threshold := 70000.0
+	created := time.UnixMilli(1700000000123)
+	rule := &model.AlertRule{
+		ID:              "3f1c8a52-7d0e-4b8e-9d44-2f7f1c0e6a11",
+		UserID:          "user-1",
+		Name:            "BTC突破",
+		Symbol:          "BTCUSDT",
+		Condition:       "price > 70000",
+		Type:            model.AlertThresholdAbove,
+		Threshold:       &threshold,
+		CooldownSeconds: 3600,
+		Enabled:         true,
+		CreatedAt:       created,
+		UpdatedAt:       created.Add(time.Minute),
+	}
+
+	got := newMySQLAlertRuleRow(rule).toModel()
+	if !reflect.DeepEqual(got, rule) {
+		t.Errorf("round trip = %+v, want %+v", got, rule)
+	}
+
+	rule.Triggered = true
+	if newMySQLAlertRuleRow(rule).toModel().Triggered {
+		t.Error("evaluation state must not be stored in the table")
+	}
+}
+
+// TestApplyAlertRuleState 只使用评估时条件和币种与规则一致的状态
+func TestApplyAlertRuleState(t *testing.T) {
+	evaluated := time.UnixMilli(1700000060000)
+	state := func(condition, symbol string) string {
+		data, _ := json.Marshal(&alertRuleState{
+			Condition:       condition,
+			Symbol:          symbol,
+			Triggered:       true,
+			LastValues:      map[string]float64{model.AlertSignalPrice: 71000},
+			LastEvaluatedAt: &evaluated,
+		})
+		return string(data)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		applied bool
+	}{
+		{name: "matching state", value: state("price > 70000", "BTCUSDT"), applied: true},
+		{name: "condition changed", value: state("price > 60000", "BTCUSDT")},
+		{name: "symbol changed", value: state("price > 70000", "ETHUSDT")},
+		{name: "no state", value: ""},
+		{name: "malformed state", value: "{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &model.AlertRule{Symbol: "BTCUSDT", Condition: "price > 70000"}
+			applyAlertRuleState(rule, tt.value)
+			if rule.Triggered != tt.applied {
+				t.Errorf("triggered = %v, want %v", rule.Triggered, tt.applied)
+			}
+			if tt.applied && (rule.LastEvaluatedAt == nil || rule.LastValues[model.AlertSignalPrice] != 71000) {
+				t.Errorf("state not applied: %+v", rule)
+			}
+		})
+	}
+}
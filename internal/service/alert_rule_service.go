@@ -0,0 +1,792 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/expr"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+	"crypto-info/internal/pkg/precision"
+	"crypto-info/internal/pkg/shard"
+
+	"github.com/google/uuid"
+)
+
+// 条件告警默认配置
+const (
+	defaultAlertRuleInterval   = time.Minute
+	defaultAlertRuleMaxPerUser = 50
+	defaultAlertRuleCooldown   = time.Hour
+	maxAlertRuleName           = 64
+	maxAlertRuleCooldown       = 7 * 24 * time.Hour
+	alertRuleKeyPrefix         = "alerts:rules:user:"
+	alertRuleLegacyIndexKey    = "alerts:rules:index" // 按币种索引之前的全部规则索引，评估时迁移
+	alertRuleSymbolsKey        = "alerts:rules:symbols"
+	alertRuleSymbolKeyPrefix   = "alerts:rules:symbol:"
+	alertRuleStateKeyPrefix    = "alerts:rules:state:" // MySQL存储时的评估状态
+	alertRuleLockKeyPrefix     = "alerts:rules:lock:"
+	alertRuleHistoryKeyPrefix  = "alerts:rules:history:"
+	maxAlertRuleHistory        = 1000 // 每条规则保留的触发记录数
+	defaultAlertHistoryPage    = 20
+	maxAlertHistoryPage        = 100
+	alertRuleTypeCondition     = "condition" // 条件表达式规则发布的告警类型
+	alertRuleShardGroup        = "alert_rules"
+	defaultAlertRuleMemberTTLs = 3 // 未配置member_ttl时为评估间隔的倍数
+	maxAlertRuleStateRetries   = 3 // 写入评估状态时与其他写入冲突的重试次数
+	alertRuleStorageMySQL      = "mysql"
+)
+
+// AlertRuleService 用户条件告警服务接口
+type AlertRuleService interface {
+	CreateRule(ctx context.Context, userID string, req *model.AlertRuleRequest) (*model.AlertRule, error)
+	ListRules(ctx context.Context, userID string) (*model.AlertRuleListResponse, error)
+	GetRule(ctx context.Context, userID, id string) (*model.AlertRule, error)
+	UpdateRule(ctx context.Context, userID, id string, req *model.AlertRuleRequest) (*model.AlertRule, error)
+	DeleteRule(ctx context.Context, userID, id string) error
+	// TestRule 按当前数据评估规则并发送测试通知，不改变规则状态
+	TestRule(ctx context.Context, userID, id string) (*model.AlertRuleTestResult, error)
+	// GetHistory 获取规则的触发记录，按时间倒序分页
+	GetHistory(ctx context.Context, userID, id string, page, pageSize int) (*model.AlertFiringListResponse, error)
+	// SetPublisher 设置规则触发时发布价格告警消息的发布器，为空时不发布
+	SetPublisher(publisher AlertPublisher)
+	// Start 启动定时评估
+	Start(ctx context.Context) error
+	// Stop 停止定时评估
+	Stop() error
+}
+
+// AlertPublisher 价格告警消息发布器，由 MessageService 实现
+type AlertPublisher interface {
+	PublishPriceAlert(ctx context.Context, symbol string, currentPrice, targetPrice float64, alertType, userID string) error
+}
+
+// alertRuleService 规则按 notifier.rules.storage 保存在Redis或MySQL中并按币种索引，锁、触发记录和分片成员保存在Redis中
+//
+// 多实例部署时每个实例都会定时评估，同一规则在一个评估间隔内通过Redis锁保证只评估一次。
+// 启用分片后各实例按币种的一致性哈希只读取和评估分配给自己的币种的规则，锁用于成员变化期间各实例视图不一致时避免重复触发。
+type alertRuleService struct {
+	store          alertRuleStore // 未配置存储时为空
+	redisClient    database.RedisClient
+	config         *config.Config
+	logger         logger.Logger
+	priceService   PriceService
+	volumeService  VolumeService
+	historyService HistoryService
+	notifier       Notifier
+	membership     *shard.Membership // 未启用分片时为空
+	shardMembers   []string          // 上一轮评估时的分片成员，用于记录成员变化
+
+	publisherMu sync.RWMutex
+	publisher   AlertPublisher
+
+	runMutex sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewAlertRuleService 创建条件告警服务，notifier.rules.storage为mysql时规则保存在mysqlClient中，否则保存在redisClient中
+func NewAlertRuleService(redisClient database.RedisClient, mysqlClient database.MySQLClient, cfg *config.Config, priceService PriceService, volumeService VolumeService, historyService HistoryService, notifier Notifier) AlertRuleService {
+	s := &alertRuleService{
+		redisClient:    redisClient,
+		config:         cfg,
+		logger:         logger.GetLogger(),
+		priceService:   priceService,
+		volumeService:  volumeService,
+		historyService: historyService,
+		notifier:       notifier,
+	}
+	switch {
+	case cfg.Notifier.Rules.Storage == alertRuleStorageMySQL:
+		if mysqlClient == nil {
+			s.logger.Warn("Alert rule storage is mysql but MySQL is unavailable, alert rules disabled")
+			break
+		}
+		s.store = newMySQLAlertRuleStore(mysqlClient, redisClient)
+	case redisClient != nil:
+		s.store = &redisAlertRuleStore{redisClient: redisClient, logger: s.logger}
+	}
+	if redisClient != nil && cfg.Notifier.Rules.Sharding.Enabled {
+		s.membership = shard.NewMembership(redisClient, alertRuleShardGroup, s.memberTTL())
+	}
+	return s
+}
+
+// CreateRule 创建告警规则
+func (s *alertRuleService) CreateRule(ctx context.Context, userID string, req *model.AlertRuleRequest) (*model.AlertRule, error) {
+	if err := s.checkStorage(userID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.list(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert rules: %w", err)
+	}
+	if len(existing) >= s.maxPerUser() {
+		return nil, fmt.Errorf("%w: at most %d alert rules per user", ErrInvalidParameter, s.maxPerUser())
+	}
+
+	now := time.Now()
+	rule := &model.AlertRule{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.applyRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.store.create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// ListRules 获取用户的告警规则，按创建时间排序
+func (s *alertRuleService) ListRules(ctx context.Context, userID string) (*model.AlertRuleListResponse, error) {
+	if err := s.checkStorage(userID); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.store.list(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert rules: %w", err)
+	}
+
+	resp := &model.AlertRuleListResponse{Rules: rules}
+	sort.Slice(resp.Rules, func(i, j int) bool {
+		return resp.Rules[i].CreatedAt.Before(resp.Rules[j].CreatedAt)
+	})
+	resp.Total = len(resp.Rules)
+	return resp, nil
+}
+
+// GetRule 获取告警规则，不存在时返回ErrNotFound
+func (s *alertRuleService) GetRule(ctx context.Context, userID, id string) (*model.AlertRule, error) {
+	if err := s.checkStorage(userID); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: alert rule %s", ErrNotFound, id)
+	}
+
+	rule, err := s.store.get(ctx, userID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert rule: %w", err)
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("%w: alert rule %s", ErrNotFound, id)
+	}
+	return rule, nil
+}
+
+// UpdateRule 替换告警规则的设置，条件或币种变化时重置触发状态
+func (s *alertRuleService) UpdateRule(ctx context.Context, userID, id string, req *model.AlertRuleRequest) (*model.AlertRule, error) {
+	rule, err := s.GetRule(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, symbol := rule.Condition, rule.Symbol
+	if err := s.applyRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if rule.Condition != condition || rule.Symbol != symbol {
+		rule.Triggered = false
+		rule.LastValues = nil
+		rule.LastError = ""
+	}
+	rule.UpdatedAt = time.Now()
+	if err := s.store.update(ctx, rule, symbol); err != nil {
+		return nil, fmt.Errorf("failed to save alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule 删除告警规则
+func (s *alertRuleService) DeleteRule(ctx context.Context, userID, id string) error {
+	rule, err := s.GetRule(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.store.delete(ctx, rule); err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	if s.redisClient == nil {
+		return nil
+	}
+	if err := s.redisClient.Del(ctx, alertRuleHistoryKey(id)); err != nil {
+		logger.From(ctx).Warnf("Failed to delete history of alert rule %s: %v", id, err)
+	}
+	return nil
+}
+
+// TestRule 试运行告警规则，无论条件是否成立都发送一条测试通知，便于确认通知渠道可用
+func (s *alertRuleService) TestRule(ctx context.Context, userID, id string) (*model.AlertRuleTestResult, error) {
+	rule, err := s.GetRule(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.AlertRuleTestResult{
+		RuleID:      rule.ID,
+		Symbol:      rule.Symbol,
+		Condition:   rule.Condition,
+		EvaluatedAt: time.Now(),
+	}
+	collector := newSignalCollector(s.priceService, s.volumeService, s.historyService)
+	matched, values, evalErr := evaluateRule(ctx, rule, collector)
+	result.Matched = matched
+	result.Values = values
+	if evalErr != nil {
+		result.Error = evalErr.Error()
+	}
+
+	alert := testRuleAlert(rule, matched, values, evalErr)
+	firing := s.fire(ctx, rule, alert, matched, values)
+	firing.Test = true
+	s.recordFiring(ctx, firing)
+	if firing.Error != "" {
+		logger.From(ctx).Warnf("Failed to send test notification for alert rule %s: %s", rule.ID, firing.Error)
+		result.NotifyError = firing.Error
+	} else {
+		result.Notified = true
+	}
+	result.Alert = alert
+	return result, nil
+}
+
+// GetHistory 获取规则的触发记录
+func (s *alertRuleService) GetHistory(ctx context.Context, userID, id string, page, pageSize int) (*model.AlertFiringListResponse, error) {
+	if _, err := s.GetRule(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultAlertHistoryPage
+	}
+	if pageSize > maxAlertHistoryPage {
+		pageSize = maxAlertHistoryPage
+	}
+
+	resp := &model.AlertFiringListResponse{
+		RuleID:   id,
+		Items:    []model.AlertFiring{},
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if s.redisClient == nil {
+		return resp, nil
+	}
+	client := s.redisClient.GetClient()
+	key := s.redisClient.KeyPrefix() + alertRuleHistoryKey(id)
+	total, err := client.ZCard(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count alert history: %w", err)
+	}
+
+	resp.Total = int(total)
+	start := int64((page - 1) * pageSize)
+	if start >= total {
+		return resp, nil
+	}
+	members, err := client.ZRevRange(ctx, key, start, start+int64(pageSize)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert history: %w", err)
+	}
+	for _, member := range members {
+		var firing model.AlertFiring
+		if err := json.Unmarshal([]byte(member), &firing); err != nil {
+			logger.From(ctx).Warnf("Skipping malformed history entry of alert rule %s: %v", id, err)
+			continue
+		}
+		resp.Items = append(resp.Items, firing)
+	}
+	resp.HasMore = start+int64(len(members)) < total
+	return resp, nil
+}
+
+// Start 启动定时评估
+func (s *alertRuleService) Start(ctx context.Context) error {
+	if !s.config.Notifier.Rules.Enabled || s.store == nil || s.redisClient == nil {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("alert rule evaluation is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "alert_rules", s.run, s.done)
+
+	s.logger.Infof("Alert rule evaluation started with interval %s", s.interval())
+	return nil
+}
+
+// Stop 停止定时评估
+func (s *alertRuleService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	if s.membership != nil {
+		// 主动注销，其余实例下一轮即接管本实例的币种
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.membership.Leave(ctx); err != nil {
+			s.logger.Warnf("Failed to leave alert rule shard: %v", err)
+		}
+	}
+
+	s.logger.Info("Alert rule evaluation stopped")
+	return nil
+}
+
+// run 按间隔评估所有规则
+func (s *alertRuleService) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval())
+	defer ticker.Stop()
+
+	for {
+		if err := s.evaluateAll(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("Alert rule evaluation failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// evaluateAll 评估分配给本实例的币种的启用规则，同一币种的指标只计算一次
+func (s *alertRuleService) evaluateAll(ctx context.Context) error {
+	if err := s.store.migrate(ctx); err != nil {
+		s.logger.Warnf("Failed to migrate alert rules: %v", err)
+	}
+
+	symbols, err := s.store.symbols(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load alert rule symbols: %w", err)
+	}
+
+	ring := s.shardRing(ctx)
+	collector := newSignalCollector(s.priceService, s.volumeService, s.historyService)
+	for _, symbol := range symbols {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if ring != nil && ring.Owner(symbol) != s.membership.Self() {
+			// 由其他实例评估
+			continue
+		}
+
+		rules, err := s.store.bySymbol(ctx, symbol)
+		if err != nil {
+			s.logger.Warnf("Failed to load alert rules of %s: %v", symbol, err)
+			continue
+		}
+		for _, rule := range rules {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !rule.Enabled {
+				continue
+			}
+
+			acquired, err := s.lock(ctx, rule.ID)
+			if err != nil {
+				s.logger.Warnf("Failed to lock alert rule %s: %v", rule.ID, err)
+				continue
+			}
+			if !acquired {
+				// 本轮已由其他实例评估
+				continue
+			}
+
+			s.evaluate(ctx, rule, collector)
+			if err := s.store.saveState(ctx, rule); err != nil {
+				s.logger.Errorf("Failed to save state of alert rule %s: %v", rule.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// shardRing 登记心跳并构建本轮的哈希环，未启用分片或Redis出错时返回nil，由本实例评估所有规则
+func (s *alertRuleService) shardRing(ctx context.Context) *shard.Ring {
+	if s.membership == nil {
+		return nil
+	}
+	members, err := s.membership.Heartbeat(ctx)
+	if err != nil {
+		s.logger.Warnf("Alert rule sharding unavailable, evaluating all rules: %v", err)
+		return nil
+	}
+	ring := shard.NewRing(members, s.config.Notifier.Rules.Sharding.VirtualNodes)
+	if !slices.Equal(ring.Members(), s.shardMembers) {
+		s.logger.Infof("Alert rule evaluation sharded across %d instances: %s", len(ring.Members()), strings.Join(ring.Members(), ", "))
+		s.shardMembers = ring.Members()
+	}
+	return ring
+}
+
+// evaluate 评估规则并更新状态，条件由不成立变为成立且已过冷却时间时发送告警
+func (s *alertRuleService) evaluate(ctx context.Context, rule *model.AlertRule, collector *signalCollector) {
+	now := time.Now()
+	rule.LastEvaluatedAt = &now
+
+	matched, values, err := evaluateRule(ctx, rule, collector)
+	rule.LastValues = values
+	if err != nil {
+		// 指标暂时无法获取时保持原有触发状态，避免恢复后重复告警
+		rule.LastError = err.Error()
+		return
+	}
+	rule.LastError = ""
+
+	wasTriggered := rule.Triggered
+	rule.Triggered = matched
+	if !matched || wasTriggered {
+		return
+	}
+	cooldown := time.Duration(rule.CooldownSeconds) * time.Second
+	if rule.LastTriggeredAt != nil && now.Sub(*rule.LastTriggeredAt) < cooldown {
+		s.logger.Debugf("Alert rule %s matched within cooldown", rule.ID)
+		return
+	}
+
+	rule.LastTriggeredAt = &now
+	firing := s.fire(ctx, rule, ruleAlert(rule, values), true, values)
+	s.recordFiring(ctx, firing)
+	s.publishFiring(ctx, rule, values, collector)
+	if firing.Error != "" {
+		s.logger.Warnf("Failed to notify alert rule %s: %s", rule.ID, firing.Error)
+		rule.LastError = firing.Error
+	}
+}
+
+// fire 发送告警并生成触发记录
+func (s *alertRuleService) fire(ctx context.Context, rule *model.AlertRule, alert *model.Alert, matched bool, values map[string]float64) *model.AlertFiring {
+	deliveries, err := s.notifier.Deliver(ctx, alert)
+	firing := &model.AlertFiring{
+		ID:         uuid.New().String(),
+		RuleID:     rule.ID,
+		AlertID:    alert.ID,
+		Condition:  rule.Condition,
+		Values:     values,
+		Matched:    matched,
+		Channels:   make([]string, 0, len(deliveries)),
+		Deliveries: deliveries,
+		FiredAt:    time.Now(),
+	}
+	if deliveries == nil {
+		firing.Deliveries = []model.AlertDelivery{}
+		if err == nil {
+			// 冷却期内的重复告警没有实际发送
+			firing.AlertID = ""
+		}
+	}
+	for _, d := range deliveries {
+		firing.Channels = append(firing.Channels, d.Channel)
+	}
+	if err != nil {
+		firing.Error = err.Error()
+	}
+	return firing
+}
+
+// SetPublisher 设置价格告警消息发布器
+func (s *alertRuleService) SetPublisher(publisher AlertPublisher) {
+	s.publisherMu.Lock()
+	defer s.publisherMu.Unlock()
+	s.publisher = publisher
+}
+
+// publishFiring 发布规则触发的价格告警消息，只在条件由不成立变为成立时调用，发布失败只记录日志
+//
+// 按阈值类型创建的规则以阈值作为目标值(percent_change为涨跌幅)，条件表达式规则的类型为condition、目标值为0。
+func (s *alertRuleService) publishFiring(ctx context.Context, rule *model.AlertRule, values map[string]float64, collector *signalCollector) {
+	s.publisherMu.RLock()
+	publisher := s.publisher
+	s.publisherMu.RUnlock()
+	if publisher == nil {
+		return
+	}
+
+	alertType, target := alertRuleTypeCondition, 0.0
+	if rule.Type != "" && rule.Threshold != nil {
+		alertType, target = rule.Type, *rule.Threshold
+	}
+	price, ok := values[model.AlertSignalPrice]
+	if !ok {
+		// 条件中没有价格时单独获取，同一轮评估中有缓存
+		if value, err := collector.signal(ctx, rule.Symbol, model.AlertSignalPrice); err == nil {
+			price = value
+		}
+	}
+	if err := publisher.PublishPriceAlert(ctx, rule.Symbol, price, target, alertType, rule.UserID); err != nil {
+		s.logger.Warnf("Failed to publish price alert of rule %s: %v", rule.ID, err)
+	}
+}
+
+// recordFiring 保存触发记录，只保留最近的maxAlertRuleHistory条
+func (s *alertRuleService) recordFiring(ctx context.Context, firing *model.AlertFiring) {
+	if s.redisClient == nil {
+		return
+	}
+	data, err := json.Marshal(firing)
+	if err != nil {
+		return
+	}
+	key := alertRuleHistoryKey(firing.RuleID)
+	if err := s.redisClient.ZAdd(ctx, key, float64(firing.FiredAt.UnixMilli()), string(data)); err != nil {
+		s.logger.Warnf("Failed to record history of alert rule %s: %v", firing.RuleID, err)
+		return
+	}
+	if err := s.redisClient.GetClient().ZRemRangeByRank(ctx, s.redisClient.KeyPrefix()+key, 0, -maxAlertRuleHistory-1).Err(); err != nil {
+		s.logger.Warnf("Failed to trim history of alert rule %s: %v", firing.RuleID, err)
+	}
+}
+
+// applyRequest 校验请求并更新规则
+func (s *alertRuleService) applyRequest(rule *model.AlertRule, req *model.AlertRuleRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if len(name) > maxAlertRuleName {
+		return fmt.Errorf("%w: name exceeds %d characters", ErrInvalidParameter, maxAlertRuleName)
+	}
+
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if symbol == "" {
+		return fmt.Errorf("%w: symbol is required", ErrInvalidParameter)
+	}
+
+	source, err := thresholdCondition(req)
+	if err != nil {
+		return err
+	}
+	condition, err := expr.Compile(source)
+	if err != nil {
+		return fmt.Errorf("%w: invalid condition: %v", ErrInvalidParameter, err)
+	}
+	if err := validateAlertSignals(condition.Vars()); err != nil {
+		return err
+	}
+
+	cooldown := time.Duration(req.CooldownSeconds) * time.Second
+	if cooldown < 0 || cooldown > maxAlertRuleCooldown {
+		return fmt.Errorf("%w: cooldown_seconds must be between 0 and %d", ErrInvalidParameter, int(maxAlertRuleCooldown.Seconds()))
+	}
+	if cooldown == 0 {
+		cooldown = s.defaultCooldown()
+	}
+
+	rule.Name = name
+	rule.Symbol = symbol
+	rule.Condition = condition.String()
+	rule.Type = req.Type
+	rule.Threshold = nil
+	if req.Type != "" {
+		rule.Threshold = req.Threshold
+	}
+	rule.CooldownSeconds = int(cooldown.Seconds())
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	return nil
+}
+
+// thresholdCondition 返回请求的条件表达式，指定阈值类型时由类型和阈值生成
+func thresholdCondition(req *model.AlertRuleRequest) (string, error) {
+	if req.Type == "" {
+		if strings.TrimSpace(req.Condition) == "" {
+			return "", fmt.Errorf("%w: condition or type is required", ErrInvalidParameter)
+		}
+		return req.Condition, nil
+	}
+	if strings.TrimSpace(req.Condition) != "" {
+		return "", fmt.Errorf("%w: condition and type are mutually exclusive", ErrInvalidParameter)
+	}
+	if req.Threshold == nil {
+		return "", fmt.Errorf("%w: threshold is required for type %s", ErrInvalidParameter, req.Type)
+	}
+
+	threshold := strconv.FormatFloat(*req.Threshold, 'f', -1, 64)
+	switch req.Type {
+	case model.AlertThresholdAbove:
+		if *req.Threshold <= 0 {
+			return "", fmt.Errorf("%w: threshold must be positive", ErrInvalidParameter)
+		}
+		return model.AlertSignalPrice + " > " + threshold, nil
+	case model.AlertThresholdBelow:
+		if *req.Threshold <= 0 {
+			return "", fmt.Errorf("%w: threshold must be positive", ErrInvalidParameter)
+		}
+		return model.AlertSignalPrice + " < " + threshold, nil
+	case model.AlertThresholdPercentChange:
+		if *req.Threshold == 0 {
+			return "", fmt.Errorf("%w: threshold must not be zero", ErrInvalidParameter)
+		}
+		// 正数为涨幅达到阈值，负数为跌幅达到阈值
+		if *req.Threshold > 0 {
+			return model.AlertSignalChange24h + " >= " + threshold, nil
+		}
+		return model.AlertSignalChange24h + " <= " + threshold, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported type %q, supported: %s, %s, %s", ErrInvalidParameter, req.Type,
+			model.AlertThresholdAbove, model.AlertThresholdBelow, model.AlertThresholdPercentChange)
+	}
+}
+
+// lock 获取本轮评估的锁，锁在评估间隔结束前过期
+func (s *alertRuleService) lock(ctx context.Context, id string) (bool, error) {
+	ttl := s.interval() * 9 / 10
+	return s.redisClient.GetClient().SetNX(ctx, s.redisClient.KeyPrefix()+alertRuleLockKeyPrefix+id, 1, ttl).Result()
+}
+
+// checkStorage 检查用户标识和规则存储
+func (s *alertRuleService) checkStorage(userID string) error {
+	if userID == "" {
+		return fmt.Errorf("%w: user id is required", ErrInvalidParameter)
+	}
+	if s.store == nil {
+		return fmt.Errorf("%w: alert rule storage is not configured", ErrUpstreamUnavailable)
+	}
+	return nil
+}
+
+// interval 评估间隔
+func (s *alertRuleService) interval() time.Duration {
+	if s.config.Notifier.Rules.Interval > 0 {
+		return s.config.Notifier.Rules.Interval
+	}
+	return defaultAlertRuleInterval
+}
+
+// memberTTL 分片成员的存活时间，至少为两个评估间隔，避免评估耗时较长时被其他实例视为下线
+func (s *alertRuleService) memberTTL() time.Duration {
+	ttl := s.config.Notifier.Rules.Sharding.MemberTTL
+	if ttl <= 0 {
+		ttl = s.interval() * defaultAlertRuleMemberTTLs
+	}
+	if ttl < 2*s.interval() {
+		ttl = 2 * s.interval()
+	}
+	return ttl
+}
+
+// maxPerUser 每个用户的规则上限
+func (s *alertRuleService) maxPerUser() int {
+	if s.config.Notifier.Rules.MaxPerUser > 0 {
+		return s.config.Notifier.Rules.MaxPerUser
+	}
+	return defaultAlertRuleMaxPerUser
+}
+
+// defaultCooldown 规则未指定时的冷却时间
+func (s *alertRuleService) defaultCooldown() time.Duration {
+	if s.config.Notifier.Rules.Cooldown > 0 {
+		return s.config.Notifier.Rules.Cooldown
+	}
+	return defaultAlertRuleCooldown
+}
+
+// evaluateRule 计算条件引用的指标并求值
+func evaluateRule(ctx context.Context, rule *model.AlertRule, collector *signalCollector) (bool, map[string]float64, error) {
+	condition, err := expr.Compile(rule.Condition)
+	if err != nil {
+		return false, nil, fmt.Errorf("invalid condition: %w", err)
+	}
+	values, err := collector.collect(ctx, rule.Symbol, condition.Vars())
+	if err != nil {
+		return false, values, err
+	}
+	matched, err := condition.Eval(values)
+	if err != nil {
+		return false, values, err
+	}
+	return matched, values, nil
+}
+
+// ruleAlert 生成规则触发的告警
+func ruleAlert(rule *model.AlertRule, values map[string]float64) *model.Alert {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	data := make(map[string]interface{}, len(values)+1)
+	for _, name := range names {
+		value := values[name]
+		formatted := fmt.Sprintf("%.2f", value)
+		if name == model.AlertSignalPrice {
+			formatted = precision.Format(rule.Symbol, value)
+		}
+		parts = append(parts, name+"="+formatted)
+		data[name] = value
+	}
+	data["rule_id"] = rule.ID
+
+	title := rule.Name
+	if title == "" {
+		title = rule.Symbol + " 告警条件已满足"
+	}
+	return &model.Alert{
+		Type:     model.AlertTypeRule,
+		Severity: model.AlertSeverityWarning,
+		Title:    title,
+		Message:  fmt.Sprintf("%s 满足条件 %s (%s)", rule.Symbol, rule.Condition, strings.Join(parts, ", ")),
+		Subject:  rule.Symbol,
+		UserID:   rule.UserID,
+		Data:     data,
+	}
+}
+
+// testRuleAlert 生成试运行的测试通知，不设置去重键以免被冷却期忽略
+func testRuleAlert(rule *model.AlertRule, matched bool, values map[string]float64, evalErr error) *model.Alert {
+	alert := ruleAlert(rule, values)
+	alert.Severity = model.AlertSeverityInfo
+	alert.Title = "[测试] " + alert.Title
+	switch {
+	case evalErr != nil:
+		alert.Message = fmt.Sprintf("%s 条件 %s 评估失败: %v", rule.Symbol, rule.Condition, evalErr)
+	case !matched:
+		alert.Message = strings.Replace(alert.Message, "满足条件", "当前不满足条件", 1)
+	}
+	alert.Data["test"] = true
+	alert.Data["matched"] = matched
+	return alert
+}
+
+// alertRuleHistoryKey 规则触发记录存储key
+func alertRuleHistoryKey(id string) string {
+	return alertRuleHistoryKeyPrefix + id
+}
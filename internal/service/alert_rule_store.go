@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// alertRuleStore 告警规则的存储，按 notifier.rules.storage 选择Redis或MySQL
+type alertRuleStore interface {
+	// list 用户的全部规则，不保证顺序
+	list(ctx context.Context, userID string) ([]model.AlertRule, error)
+	// get 读取规则，不存在时返回nil
+	get(ctx context.Context, userID, id string) (*model.AlertRule, error)
+	// create 保存新规则并加入币种索引
+	create(ctx context.Context, rule *model.AlertRule) error
+	// update 保存用户修改的设置，previousSymbol为修改前的币种
+	update(ctx context.Context, rule *model.AlertRule, previousSymbol string) error
+	// delete 删除规则及其评估状态
+	delete(ctx context.Context, rule *model.AlertRule) error
+	// symbols 有规则的币种
+	symbols(ctx context.Context) ([]string, error)
+	// bySymbol 币种的全部规则，同时清理失效的索引
+	bySymbol(ctx context.Context, symbol string) ([]*model.AlertRule, error)
+	// saveState 保存评估状态，不改变用户的设置；评估期间条件或币种已修改、规则已删除时丢弃
+	saveState(ctx context.Context, rule *model.AlertRule) error
+	// migrate 迁移旧版本或其他存储中的规则，每轮评估前调用
+	migrate(ctx context.Context) error
+}
+
+// redisAlertRuleStore 规则按用户保存在Redis哈希中，按币种索引在有序集合中，有规则的币种另存一个有序集合
+type redisAlertRuleStore struct {
+	redisClient database.RedisClient
+	logger      logger.Logger
+}
+
+// list 读取用户的规则哈希，跳过无法解析的规则
+func (r *redisAlertRuleStore) list(ctx context.Context, userID string) ([]model.AlertRule, error) {
+	entries, err := r.redisClient.HGetAll(ctx, alertRuleKey(userID))
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]model.AlertRule, 0, len(entries))
+	for id, value := range entries {
+		var rule model.AlertRule
+		if err := json.Unmarshal([]byte(value), &rule); err != nil {
+			logger.From(ctx).Warnf("Skipping malformed alert rule %s for user %s: %v", id, userID, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// get 读取规则
+func (r *redisAlertRuleStore) get(ctx context.Context, userID, id string) (*model.AlertRule, error) {
+	value, err := r.redisClient.HGet(ctx, alertRuleKey(userID), id)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	var rule model.AlertRule
+	if err := json.Unmarshal([]byte(value), &rule); err != nil {
+		return nil, fmt.Errorf("invalid alert rule %s: %w", id, err)
+	}
+	return &rule, nil
+}
+
+// create 保存规则并加入币种索引
+func (r *redisAlertRuleStore) create(ctx context.Context, rule *model.AlertRule) error {
+	if err := r.save(ctx, rule); err != nil {
+		return err
+	}
+	if err := r.index(ctx, rule); err != nil {
+		return fmt.Errorf("failed to index alert rule: %w", err)
+	}
+	return nil
+}
+
+// update 保存规则，币种变化时先加入新币种的索引再移出旧币种，中途失败时评估会修正索引
+func (r *redisAlertRuleStore) update(ctx context.Context, rule *model.AlertRule, previousSymbol string) error {
+	if err := r.save(ctx, rule); err != nil {
+		return err
+	}
+	if rule.Symbol == previousSymbol {
+		return nil
+	}
+	if err := r.index(ctx, rule); err != nil {
+		return fmt.Errorf("failed to index alert rule: %w", err)
+	}
+	if err := r.unindex(ctx, previousSymbol, userScopedMember(rule.UserID, rule.ID)); err != nil {
+		return fmt.Errorf("failed to unindex alert rule: %w", err)
+	}
+	return nil
+}
+
+// delete 删除规则并移出币种索引
+func (r *redisAlertRuleStore) delete(ctx context.Context, rule *model.AlertRule) error {
+	if err := r.redisClient.HDel(ctx, alertRuleKey(rule.UserID), rule.ID); err != nil {
+		return err
+	}
+	if err := r.unindex(ctx, rule.Symbol, userScopedMember(rule.UserID, rule.ID)); err != nil {
+		return fmt.Errorf("failed to unindex alert rule: %w", err)
+	}
+	return nil
+}
+
+// symbols 有规则的币种
+func (r *redisAlertRuleStore) symbols(ctx context.Context) ([]string, error) {
+	return r.redisClient.ZRangeByScore(ctx, alertRuleSymbolsKey, "-inf", "+inf")
+}
+
+// bySymbol 按币种索引读取规则，移除已删除规则的索引，修正修改币种时中途失败留下的旧索引
+func (r *redisAlertRuleStore) bySymbol(ctx context.Context, symbol string) ([]*model.AlertRule, error) {
+	members, err := r.redisClient.ZRangeByScore(ctx, alertRuleSymbolKey(symbol), "-inf", "+inf")
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		r.pruneSymbol(ctx, symbol)
+		return nil, nil
+	}
+
+	rules := make([]*model.AlertRule, 0, len(members))
+	for _, member := range members {
+		userID, id, ok := parseUserScopedMember(member)
+		if !ok {
+			r.unindex(ctx, symbol, member)
+			continue
+		}
+		rule, err := r.get(ctx, userID, id)
+		if err != nil {
+			r.logger.Warnf("Failed to load alert rule %s: %v", id, err)
+			continue
+		}
+		if rule == nil {
+			r.unindex(ctx, symbol, member)
+			continue
+		}
+		if rule.Symbol != symbol {
+			if err := r.index(ctx, rule); err == nil {
+				r.unindex(ctx, symbol, member)
+			}
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// saveState 在WATCH事务中重新读取规则，只更新评估相关的字段
+//
+// 评估期间用户可能修改或删除了规则：已删除的规则不再写回，条件或币种已修改的规则丢弃本次结果，
+// 其余设置以最新读取的为准。读取与写入之间规则被并发修改时事务失败，重新读取后重试。
+func (r *redisAlertRuleStore) saveState(ctx context.Context, rule *model.AlertRule) error {
+	key := r.redisClient.KeyPrefix() + alertRuleKey(rule.UserID)
+	for attempt := 0; attempt < maxAlertRuleStateRetries; attempt++ {
+		err := r.redisClient.GetClient().Watch(ctx, func(tx *redis.Tx) error {
+			value, err := tx.HGet(ctx, key, rule.ID).Result()
+			if errors.Is(err, redis.Nil) {
+				r.logger.Debugf("Alert rule %s deleted during evaluation, discarding result", rule.ID)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			var current model.AlertRule
+			if err := json.Unmarshal([]byte(value), &current); err != nil {
+				return fmt.Errorf("invalid alert rule %s: %w", rule.ID, err)
+			}
+			if current.Condition != rule.Condition || current.Symbol != rule.Symbol {
+				r.logger.Debugf("Alert rule %s changed during evaluation, discarding result", rule.ID)
+				return nil
+			}
+
+			copyAlertRuleState(&current, rule)
+			data, err := json.Marshal(&current)
+			if err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.HSet(ctx, key, rule.ID, string(data))
+				return nil
+			})
+			return err
+		}, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("concurrent updates after %d attempts", maxAlertRuleStateRetries)
+}
+
+// migrate 将旧版本全部规则索引中的规则按币种重新索引，兼容滚动升级期间旧实例创建的规则
+func (r *redisAlertRuleStore) migrate(ctx context.Context) error {
+	members, err := r.redisClient.ZRangeByScore(ctx, alertRuleLegacyIndexKey, "-inf", "+inf")
+	if err != nil || len(members) == 0 {
+		return err
+	}
+
+	migrated := 0
+	client := r.redisClient.GetClient()
+	legacyKey := r.redisClient.KeyPrefix() + alertRuleLegacyIndexKey
+	for _, member := range members {
+		if userID, id, ok := parseUserScopedMember(member); ok {
+			rule, err := r.get(ctx, userID, id)
+			if err != nil {
+				continue
+			}
+			if rule != nil {
+				if err := r.index(ctx, rule); err != nil {
+					r.logger.Warnf("Failed to migrate index of alert rule %s: %v", id, err)
+					continue
+				}
+				migrated++
+			}
+		}
+		client.ZRem(ctx, legacyKey, member)
+	}
+	r.logger.Infof("Migrated %d alert rules to the per-symbol index", migrated)
+	return nil
+}
+
+// save 保存规则，UpdatedAt由调用方在用户修改时设置
+func (r *redisAlertRuleStore) save(ctx context.Context, rule *model.AlertRule) error {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	return r.redisClient.HSet(ctx, alertRuleKey(rule.UserID), rule.ID, string(data))
+}
+
+// index 将规则加入币种索引，先写币种索引再登记币种，与pruneSymbol配合不会漏掉规则
+func (r *redisAlertRuleStore) index(ctx context.Context, rule *model.AlertRule) error {
+	if err := r.redisClient.ZAdd(ctx, alertRuleSymbolKey(rule.Symbol), float64(rule.CreatedAt.UnixMilli()), userScopedMember(rule.UserID, rule.ID)); err != nil {
+		return err
+	}
+	return r.redisClient.ZAdd(ctx, alertRuleSymbolsKey, 0, rule.Symbol)
+}
+
+// unindex 从币种索引中移除，币种没有规则后由pruneSymbol清理
+func (r *redisAlertRuleStore) unindex(ctx context.Context, symbol, member string) error {
+	return r.redisClient.GetClient().ZRem(ctx, r.redisClient.KeyPrefix()+alertRuleSymbolKey(symbol), member).Err()
+}
+
+// pruneSymbol 币种索引为空时移除该币种，WATCH币种索引，期间新建规则时放弃
+func (r *redisAlertRuleStore) pruneSymbol(ctx context.Context, symbol string) {
+	key := r.redisClient.KeyPrefix() + alertRuleSymbolKey(symbol)
+	err := r.redisClient.GetClient().Watch(ctx, func(tx *redis.Tx) error {
+		count, err := tx.ZCard(ctx, key).Result()
+		if err != nil || count > 0 {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRem(ctx, r.redisClient.KeyPrefix()+alertRuleSymbolsKey, symbol)
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil && !errors.Is(err, redis.TxFailedErr) {
+		r.logger.Warnf("Failed to prune alert rule symbol %s: %v", symbol, err)
+	}
+}
+
+// copyAlertRuleState 复制评估相关的字段
+func copyAlertRuleState(dst, src *model.AlertRule) {
+	dst.Triggered = src.Triggered
+	dst.LastValues = src.LastValues
+	dst.LastEvaluatedAt = src.LastEvaluatedAt
+	dst.LastTriggeredAt = src.LastTriggeredAt
+	dst.LastError = src.LastError
+}
+
+// alertRuleKey 用户告警规则存储key
+func alertRuleKey(userID string) string {
+	return alertRuleKeyPrefix + userID
+}
+
+// alertRuleSymbolKey 币种的规则索引key
+func alertRuleSymbolKey(symbol string) string {
+	return alertRuleSymbolKeyPrefix + symbol
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-info/internal/model"
+)
+
+// 告警指标计算参数
+const (
+	rsiPeriod         = 14
+	rsiInterval       = "1h"
+	rsiLookback       = 72 * time.Hour // 取足够多的K线让平滑后的RSI收敛
+	volumeRatioDays   = 8              // 当天加前7天
+	change24hLookback = 24 * time.Hour
+)
+
+// alertSignals 告警条件支持的指标
+var alertSignals = map[string]bool{
+	model.AlertSignalPrice:       true,
+	model.AlertSignalChange24h:   true,
+	model.AlertSignalVolume:      true,
+	model.AlertSignalVolumeRatio: true,
+	model.AlertSignalRSI:         true,
+}
+
+// validateAlertSignals 检查条件中的变量都是支持的指标
+func validateAlertSignals(vars []string) error {
+	for _, name := range vars {
+		if !alertSignals[name] {
+			supported := make([]string, 0, len(alertSignals))
+			for signal := range alertSignals {
+				supported = append(supported, signal)
+			}
+			sort.Strings(supported)
+			return fmt.Errorf("%w: unknown signal %q, supported: %s", ErrInvalidParameter, name, strings.Join(supported, ", "))
+		}
+	}
+	return nil
+}
+
+// signalCollector 按币种计算告警指标，同一轮评估中的规则共享计算结果
+type signalCollector struct {
+	priceService   PriceService
+	volumeService  VolumeService
+	historyService HistoryService
+	cache          map[string]signalResult // 币种|指标 -> 结果
+}
+
+// signalResult 指标计算结果
+type signalResult struct {
+	value float64
+	err   error
+}
+
+// newSignalCollector 创建指标计算器，每轮评估创建一个
+func newSignalCollector(priceService PriceService, volumeService VolumeService, historyService HistoryService) *signalCollector {
+	return &signalCollector{
+		priceService:   priceService,
+		volumeService:  volumeService,
+		historyService: historyService,
+		cache:          make(map[string]signalResult),
+	}
+}
+
+// collect 计算币种的指定指标，任一指标失败时返回错误
+func (c *signalCollector) collect(ctx context.Context, symbol string, names []string) (map[string]float64, error) {
+	values := make(map[string]float64, len(names))
+	for _, name := range names {
+		value, err := c.signal(ctx, symbol, name)
+		if err != nil {
+			return values, fmt.Errorf("%s: %w", name, err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// signal 计算单个指标，结果按币种缓存
+func (c *signalCollector) signal(ctx context.Context, symbol, name string) (float64, error) {
+	key := symbol + "|" + name
+	if result, ok := c.cache[key]; ok {
+		return result.value, result.err
+	}
+
+	var (
+		value float64
+		err   error
+	)
+	switch name {
+	case model.AlertSignalPrice:
+		var price *model.PriceResponse
+		if price, err = c.priceService.GetPrice(ctx, symbol); err == nil {
+			value = price.Price
+		}
+	case model.AlertSignalChange24h:
+		value, err = c.change24h(ctx, symbol)
+	case model.AlertSignalVolume, model.AlertSignalVolumeRatio:
+		var volume, ratio float64
+		volume, ratio, err = c.volume(ctx, symbol)
+		c.cache[symbol+"|"+model.AlertSignalVolume] = signalResult{value: volume, err: err}
+		c.cache[symbol+"|"+model.AlertSignalVolumeRatio] = signalResult{value: ratio, err: err}
+		if name == model.AlertSignalVolume {
+			return volume, err
+		}
+		return ratio, err
+	case model.AlertSignalRSI:
+		value, err = c.rsi(ctx, symbol)
+	default:
+		err = fmt.Errorf("%w: unknown signal %q", ErrInvalidParameter, name)
+	}
+
+	c.cache[key] = signalResult{value: value, err: err}
+	return value, err
+}
+
+// change24h 当前价格相对24小时前的涨跌幅(%)
+func (c *signalCollector) change24h(ctx context.Context, symbol string) (float64, error) {
+	current, err := c.signal(ctx, symbol, model.AlertSignalPrice)
+	if err != nil {
+		return 0, err
+	}
+	past, err := c.historyService.GetPriceAt(ctx, symbol, time.Now().Add(-change24hLookback), PriceAtNearest)
+	if err != nil {
+		return 0, err
+	}
+	if past.Price <= 0 {
+		return 0, fmt.Errorf("invalid price 24h ago")
+	}
+	return (current - past.Price) / past.Price * 100, nil
+}
+
+// volume 当天交易量及其与之前日均交易量之比
+func (c *signalCollector) volume(ctx context.Context, symbol string) (float64, float64, error) {
+	analysis, err := c.volumeService.GetVolumeAnalysis(ctx, symbol, volumeRatioDays)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(analysis.Data) < 2 {
+		return 0, 0, fmt.Errorf("not enough volume data")
+	}
+
+	latest := analysis.Data[len(analysis.Data)-1].Volume
+	previous := analysis.Data[:len(analysis.Data)-1]
+	var sum float64
+	for _, d := range previous {
+		sum += d.Volume
+	}
+	avg := sum / float64(len(previous))
+	if avg <= 0 {
+		return latest, 0, nil
+	}
+	return latest, latest / avg, nil
+}
+
+// rsi 按1小时收盘价计算Wilder平滑的RSI
+func (c *signalCollector) rsi(ctx context.Context, symbol string) (float64, error) {
+	to := time.Now()
+	history, err := c.historyService.GetPriceHistory(ctx, symbol, rsiInterval, to.Add(-rsiLookback), to)
+	if err != nil {
+		return 0, err
+	}
+	closes := make([]float64, len(history.Points))
+	for i, p := range history.Points {
+		closes[i] = p.Close
+	}
+	return relativeStrength(closes, rsiPeriod)
+}
+
+// relativeStrength 计算RSI，收盘价需多于period个
+func relativeStrength(closes []float64, period int) (float64, error) {
+	if len(closes) <= period {
+		return 0, fmt.Errorf("not enough price history for rsi, need %d points, got %d", period+1, len(closes))
+	}
+
+	var avgGain, avgLoss float64
+	for i := 1; i <= period; i++ {
+		change := closes[i] - closes[i-1]
+		if change > 0 {
+			avgGain += change
+		} else {
+			avgLoss -= change
+		}
+	}
+	avgGain /= float64(period)
+	avgLoss /= float64(period)
+
+	for i := period + 1; i < len(closes); i++ {
+		change := closes[i] - closes[i-1]
+		gain, loss := 0.0, 0.0
+		if change > 0 {
+			gain = change
+		} else {
+			loss = -change
+		}
+		avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+		avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+	}
+
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50, nil
+		}
+		return 100, nil
+	}
+	return 100 - 100/(1+avgGain/avgLoss), nil
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 使用统计默认配置
+const (
+	defaultAnalyticsFlushInterval = time.Minute
+	defaultAnalyticsRetention     = 30 * 24 * time.Hour
+	defaultAnalyticsMaxSymbols    = 1000
+	defaultAnalyticsHours         = 24
+	defaultAnalyticsLimit         = 20
+	maxAnalyticsLimit             = 200
+	maxAnalyticsSymbolLength      = 20
+	analyticsSymbolKeyPrefix      = "analytics:symbols:h:"
+	analyticsEndpointKeyPrefix    = "analytics:endpoints:h:"
+)
+
+// UsagePublisher 发布使用事件，由启用RocketMQ的入口程序注入
+type UsagePublisher interface {
+	PublishUsageEvent(ctx context.Context, topic string, event *model.UsageEvent) error
+}
+
+// AnalyticsService 匿名使用统计服务接口
+type AnalyticsService interface {
+	// RecordUsage 记录一次API请求的路由模板和请求的币种，只做进程内计数
+	RecordUsage(route string, symbols []string)
+	// TopUsage 最近hours小时内请求最多的币种和接口，所有实例合计
+	TopUsage(ctx context.Context, hours, limit int) (*model.UsageReport, error)
+	// SetPublisher 设置使用事件发布者，为nil时只写入Redis
+	SetPublisher(publisher UsagePublisher)
+	// Start 启动定时写入
+	Start(ctx context.Context) error
+	// Stop 停止定时写入并写入剩余计数
+	Stop() error
+}
+
+// analyticsService 使用统计实现，计数先在进程内累加，定时写入Redis小时桶并发布使用事件
+type analyticsService struct {
+	redisClient database.RedisClient
+	config      *config.Config
+	logger      logger.Logger
+
+	countMu     sync.Mutex
+	symbols     map[string]int64
+	endpoints   map[string]int64
+	periodStart time.Time
+
+	publisherMu sync.RWMutex
+	publisher   UsagePublisher
+
+	runMutex sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewAnalyticsService 创建使用统计服务，未启用时不记录任何数据
+func NewAnalyticsService(redisClient database.RedisClient, cfg *config.Config) AnalyticsService {
+	return &analyticsService{
+		redisClient: redisClient,
+		config:      cfg,
+		logger:      logger.GetLogger(),
+		symbols:     make(map[string]int64),
+		endpoints:   make(map[string]int64),
+		periodStart: time.Now(),
+	}
+}
+
+// RecordUsage 不合法的币种(非字母数字或过长)不计入，每个周期的不同币种数有上限
+func (s *analyticsService) RecordUsage(route string, symbols []string) {
+	if !s.config.Analytics.Enabled || route == "" {
+		return
+	}
+
+	s.countMu.Lock()
+	defer s.countMu.Unlock()
+
+	s.endpoints[route]++
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if !validUsageSymbol(symbol) {
+			continue
+		}
+		if _, ok := s.symbols[symbol]; !ok && len(s.symbols) >= s.maxSymbols() {
+			continue
+		}
+		s.symbols[symbol]++
+	}
+}
+
+// TopUsage 汇总最近hours个小时桶
+func (s *analyticsService) TopUsage(ctx context.Context, hours, limit int) (*model.UsageReport, error) {
+	if !s.config.Analytics.Enabled {
+		return nil, fmt.Errorf("%w: usage analytics is disabled", ErrNotFound)
+	}
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: redis is not available", ErrUpstreamUnavailable)
+	}
+
+	maxHours := int(s.retention() / time.Hour)
+	if hours <= 0 {
+		hours = defaultAnalyticsHours
+	}
+	if hours > maxHours {
+		return nil, fmt.Errorf("%w: hours must not exceed %d", ErrInvalidParameter, maxHours)
+	}
+	if limit <= 0 {
+		limit = defaultAnalyticsLimit
+	}
+	if limit > maxAnalyticsLimit {
+		limit = maxAnalyticsLimit
+	}
+
+	now := time.Now().UTC().Truncate(time.Hour)
+	from := now.Add(-time.Duration(hours-1) * time.Hour)
+
+	pipe := s.redisClient.GetClient().Pipeline()
+	symbolCmds := make([]*redis.MapStringStringCmd, 0, hours)
+	endpointCmds := make([]*redis.MapStringStringCmd, 0, hours)
+	for hour := from; !hour.After(now); hour = hour.Add(time.Hour) {
+		symbolCmds = append(symbolCmds, pipe.HGetAll(ctx, s.redisClient.KeyPrefix()+analyticsSymbolKey(hour)))
+		endpointCmds = append(endpointCmds, pipe.HGetAll(ctx, s.redisClient.KeyPrefix()+analyticsEndpointKey(hour)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to load usage analytics: %w", err)
+	}
+
+	report := &model.UsageReport{Hours: hours, From: from}
+	report.Symbols, _ = sumUsage(symbolCmds, limit)
+	report.Endpoints, report.TotalRequests = sumUsage(endpointCmds, limit)
+	return report, nil
+}
+
+// SetPublisher 设置使用事件发布者
+func (s *analyticsService) SetPublisher(publisher UsagePublisher) {
+	s.publisherMu.Lock()
+	s.publisher = publisher
+	s.publisherMu.Unlock()
+}
+
+// Start 启动定时写入
+func (s *analyticsService) Start(ctx context.Context) error {
+	if !s.config.Analytics.Enabled || s.redisClient == nil {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return errors.New("usage analytics is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "analytics", s.run, s.done)
+
+	s.logger.Infof("Usage analytics started, flush interval %s", s.flushInterval())
+	return nil
+}
+
+// Stop 停止定时写入，退出前写入尚未保存的计数
+func (s *analyticsService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.flush(ctx)
+
+	s.logger.Info("Usage analytics stopped")
+	return nil
+}
+
+// run 按间隔写入计数
+func (s *analyticsService) run(ctx context.Context) {
+	ticker := time.NewTicker(s.flushInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+// flush 将本周期的计数写入当前小时桶并发布使用事件，写入失败时计数放回下次重试
+func (s *analyticsService) flush(ctx context.Context) {
+	s.countMu.Lock()
+	event := &model.UsageEvent{
+		From:      s.periodStart,
+		To:        time.Now(),
+		Symbols:   s.symbols,
+		Endpoints: s.endpoints,
+	}
+	s.symbols = make(map[string]int64)
+	s.endpoints = make(map[string]int64)
+	s.periodStart = event.To
+	s.countMu.Unlock()
+
+	if len(event.Endpoints) == 0 {
+		return
+	}
+
+	if err := s.increment(ctx, event.To, event); err != nil {
+		s.logger.Warnf("Failed to flush usage analytics: %v", err)
+		s.restore(event)
+		return
+	}
+
+	s.publisherMu.RLock()
+	publisher := s.publisher
+	s.publisherMu.RUnlock()
+	if publisher == nil || s.config.Analytics.Topic == "" {
+		return
+	}
+	if err := publisher.PublishUsageEvent(ctx, s.config.Analytics.Topic, event); err != nil {
+		s.logger.Warnf("Failed to publish usage event: %v", err)
+	}
+}
+
+// restore 写入失败时把计数加回当前周期
+func (s *analyticsService) restore(event *model.UsageEvent) {
+	s.countMu.Lock()
+	defer s.countMu.Unlock()
+
+	s.periodStart = event.From
+	for symbol, n := range event.Symbols {
+		s.symbols[symbol] += n
+	}
+	for route, n := range event.Endpoints {
+		s.endpoints[route] += n
+	}
+}
+
+// increment 在同一事务中累加当前小时桶
+func (s *analyticsService) increment(ctx context.Context, now time.Time, event *model.UsageEvent) error {
+	symbolKey := s.redisClient.KeyPrefix() + analyticsSymbolKey(now)
+	endpointKey := s.redisClient.KeyPrefix() + analyticsEndpointKey(now)
+
+	pipe := s.redisClient.GetClient().TxPipeline()
+	for symbol, n := range event.Symbols {
+		pipe.HIncrBy(ctx, symbolKey, symbol, n)
+	}
+	for route, n := range event.Endpoints {
+		pipe.HIncrBy(ctx, endpointKey, route, n)
+	}
+	pipe.Expire(ctx, symbolKey, s.retention())
+	pipe.Expire(ctx, endpointKey, s.retention())
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// sumUsage 合计多个小时桶，返回按次数倒序的前limit项和总次数
+func sumUsage(cmds []*redis.MapStringStringCmd, limit int) ([]model.UsageCount, int64) {
+	totals := make(map[string]int64)
+	var total int64
+	for _, cmd := range cmds {
+		for name, value := range cmd.Val() {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			totals[name] += n
+			total += n
+		}
+	}
+
+	counts := make([]model.UsageCount, 0, len(totals))
+	for name, n := range totals {
+		counts = append(counts, model.UsageCount{Name: name, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts, total
+}
+
+// validUsageSymbol 只统计大写字母和数字组成的币种，避免记录任意输入
+func validUsageSymbol(symbol string) bool {
+	if symbol == "" || len(symbol) > maxAnalyticsSymbolLength {
+		return false
+	}
+	for _, r := range symbol {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// analyticsSymbolKey 币种小时桶的key
+func analyticsSymbolKey(t time.Time) string {
+	return analyticsSymbolKeyPrefix + strconv.FormatInt(t.Unix()/3600, 10)
+}
+
+// analyticsEndpointKey 接口小时桶的key
+func analyticsEndpointKey(t time.Time) string {
+	return analyticsEndpointKeyPrefix + strconv.FormatInt(t.Unix()/3600, 10)
+}
+
+// flushInterval 计数写入间隔
+func (s *analyticsService) flushInterval() time.Duration {
+	if s.config.Analytics.FlushInterval > 0 {
+		return s.config.Analytics.FlushInterval
+	}
+	return defaultAnalyticsFlushInterval
+}
+
+// retention 小时桶保留时间
+func (s *analyticsService) retention() time.Duration {
+	if s.config.Analytics.Retention >= time.Hour {
+		return s.config.Analytics.Retention
+	}
+	return defaultAnalyticsRetention
+}
+
+// maxSymbols 每个周期的不同币种上限
+func (s *analyticsService) maxSymbols() int {
+	if s.config.Analytics.MaxSymbols > 0 {
+		return s.config.Analytics.MaxSymbols
+	}
+	return defaultAnalyticsMaxSymbols
+}
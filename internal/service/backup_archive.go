@@ -0,0 +1,255 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/objectstore"
+)
+
+// 加密备份文件
+const (
+	backupFilePrefix = "state-"
+	backupFileSuffix = ".bak"
+	backupTimeLayout = "20060102T150405Z"
+)
+
+// backupMagic 备份文件头，后跟1字节格式版本、12字节nonce和AES-256-GCM加密的gzip压缩快照
+var backupMagic = []byte("CIBK")
+
+// backupFileVersion 备份文件格式版本
+const backupFileVersion byte = 1
+
+// defaultBackupCategories 未配置backup.categories时备份的用户数据分类
+var defaultBackupCategories = []string{
+	BackupCategoryAlerts,
+	BackupCategoryEventFilters,
+	BackupCategoryPortfolios,
+	BackupCategorySubscriptions,
+}
+
+// ErrBackupKey 备份密钥缺失或无效
+var ErrBackupKey = errors.New("invalid backup key")
+
+// CreateBackup 导出配置的分类，压缩加密后写入备份存储，并删除超过保留时长的备份
+func (s *backupService) CreateBackup(ctx context.Context) (*model.BackupFile, error) {
+	key, err := s.backupKey()
+	if err != nil {
+		return nil, err
+	}
+
+	categories := s.config.Backup.Categories
+	if len(categories) == 0 {
+		categories = defaultBackupCategories
+	}
+	// 备份文件整体加密，签名密钥以明文保存在快照中
+	snapshot, err := s.export(ctx, categories)
+	if err != nil {
+		return nil, err
+	}
+	file, err := s.writeBackup(ctx, key, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	logger.From(ctx).Infof("Created backup %s with %d keys and %d rows (%d bytes)", file.Path, file.Keys, file.Rows, file.Size)
+
+	s.pruneBackups(ctx)
+	return file, nil
+}
+
+// writeBackup 加密快照并按创建时间命名写入备份存储
+func (s *backupService) writeBackup(ctx context.Context, key []byte, snapshot *model.StateSnapshot) (*model.BackupFile, error) {
+	data, err := sealSnapshot(key, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.storeErr != nil {
+		return nil, s.storeErr
+	}
+	name := backupFilePrefix + snapshot.CreatedAt.UTC().Format(backupTimeLayout) + backupFileSuffix
+	if err := s.store.Put(ctx, name, data); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	file := &model.BackupFile{
+		Name:       name,
+		Path:       s.store.Location(name),
+		Size:       int64(len(data)),
+		Keys:       len(snapshot.Entries),
+		Categories: snapshot.Categories,
+		CreatedAt:  snapshot.CreatedAt,
+	}
+	for _, table := range snapshot.Tables {
+		file.Rows += len(table.Rows)
+	}
+	return file, nil
+}
+
+// RestoreBackup 解密备份并恢复到当前Redis和MySQL，密钥错误或文件被篡改时返回错误
+// path为已存在的本地文件时直接读取，否则作为名称从备份存储下载，如 state-20260101T030000Z.bak
+func (s *backupService) RestoreBackup(ctx context.Context, path string, overwrite bool) (*model.StateRestoreResult, error) {
+	snapshot, err := s.loadBackup(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return s.Restore(ctx, snapshot, overwrite)
+}
+
+// loadBackup 读取并解密备份
+func (s *backupService) loadBackup(ctx context.Context, path string) (*model.StateSnapshot, error) {
+	key, err := s.backupKey()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.readBackup(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	return openSnapshot(key, data)
+}
+
+// readBackup 读取本地备份文件，文件不存在时从备份存储按名称读取
+func (s *backupService) readBackup(ctx context.Context, path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return data, err
+	}
+	if s.storeErr != nil {
+		return nil, s.storeErr
+	}
+	return s.store.Get(ctx, filepath.Base(path))
+}
+
+// pruneBackups 按名称中的时间删除超过保留时长的备份，失败只记录日志
+func (s *backupService) pruneBackups(ctx context.Context) {
+	retention := s.config.Backup.Retention
+	if retention <= 0 {
+		return
+	}
+
+	names, err := s.store.List(ctx, backupFilePrefix)
+	if err != nil {
+		logger.From(ctx).Warnf("Failed to list backups: %v", err)
+		return
+	}
+	cutoff := time.Now().Add(-retention)
+	for _, name := range names {
+		stamp, ok := strings.CutSuffix(strings.TrimPrefix(name, backupFilePrefix), backupFileSuffix)
+		if !ok {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeLayout, stamp)
+		if err != nil || createdAt.After(cutoff) {
+			continue
+		}
+		if err := s.store.Delete(ctx, name); err != nil {
+			logger.From(ctx).Warnf("Failed to remove expired backup %s: %v", name, err)
+			continue
+		}
+		logger.From(ctx).Infof("Removed expired backup %s", name)
+	}
+}
+
+// newBackupStore 按backup.storage创建备份存储
+func newBackupStore(backup *config.Backup) (objectstore.Store, error) {
+	if backup.Storage == "s3" {
+		return objectstore.NewS3(&backup.S3)
+	}
+	return objectstore.NewDir(backup.Dir), nil
+}
+
+// backupKey 解码配置的备份密钥
+func (s *backupService) backupKey() ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s.config.Backup.Key)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%w: backup.key must be a base64 encoded 32-byte key", ErrBackupKey)
+	}
+	return key, nil
+}
+
+// sealSnapshot 将快照序列化、压缩并加密，文件头作为附加数据参与认证
+func sealSnapshot(key []byte, snapshot *model.StateSnapshot) ([]byte, error) {
+	var plain bytes.Buffer
+	zw := gzip.NewWriter(&plain)
+	if err := json.NewEncoder(zw).Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+
+	aead, err := newBackupAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := append(append([]byte{}, backupMagic...), backupFileVersion)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := append(header, nonce...)
+	return aead.Seal(out, nonce, plain.Bytes(), header), nil
+}
+
+// openSnapshot 校验文件头，解密并解压快照
+func openSnapshot(key, data []byte) (*model.StateSnapshot, error) {
+	headerSize := len(backupMagic) + 1
+	if len(data) < headerSize || !bytes.Equal(data[:len(backupMagic)], backupMagic) {
+		return nil, fmt.Errorf("%w: not a backup file", ErrInvalidParameter)
+	}
+	if data[len(backupMagic)] != backupFileVersion {
+		return nil, fmt.Errorf("%w: unsupported backup file version %d", ErrInvalidParameter, data[len(backupMagic)])
+	}
+
+	aead, err := newBackupAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize+aead.NonceSize() {
+		return nil, fmt.Errorf("%w: truncated backup file", ErrInvalidParameter)
+	}
+	header := data[:headerSize]
+	nonce := data[headerSize : headerSize+aead.NonceSize()]
+	plain, err := aead.Open(nil, nonce, data[headerSize+aead.NonceSize():], header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decrypt backup, wrong key or corrupted file", ErrBackupKey)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress backup: %w", err)
+	}
+	defer zr.Close()
+	var snapshot model.StateSnapshot
+	if err := json.NewDecoder(zr).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode backup: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// newBackupAEAD 创建AES-256-GCM
+func newBackupAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupKey, err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/objectstore"
+)
+
+// memStore 内存中的对象存储
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: make(map[string][]byte)}
+}
+
+func (m *memStore) Put(_ context.Context, name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memStore) Get(_ context.Context, name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", objectstore.ErrNotExist, name)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *memStore) Delete(_ context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, name)
+	return nil
+}
+
+func (m *memStore) List(_ context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for name := range m.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *memStore) Location(name string) string {
+	return "mem://" + name
+}
+
+// newTestBackupService 使用内存存储和固定密钥的备份服务
+func newTestBackupService(t *testing.T, store objectstore.Store) *backupService {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Backup.Key = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	cfg.Backup.Retention = 24 * time.Hour
+	return &backupService{config: cfg, store: store}
+}
+
+// testSnapshot 包含各种数据类型的key和一张告警规则表的快照
+func testSnapshot(t *testing.T) *model.StateSnapshot {
+	t.Helper()
+	threshold := 70000.0
+	row, err := json.Marshal(newMySQLAlertRuleRow(&model.AlertRule{
+		ID:              "3f1c8a52-7d0e-4b8e-9d44-2f7f1c0e6a11",
+		UserID:          "user-1",
+		Symbol:          "BTCUSDT",
+		Condition:       "price > 70000",
+		Type:            model.AlertThresholdAbove,
+		Threshold:       &threshold,
+		CooldownSeconds: 3600,
+		Enabled:         true,
+		CreatedAt:       time.UnixMilli(1700000000000),
+		UpdatedAt:       time.UnixMilli(1700000060000),
+	}))
+	if err != nil {
+		t.Fatalf("failed to encode row: %v", err)
+	}
+
+	registry := `{"BTC":{"symbol":"BTC"}}`
+	return &model.StateSnapshot{
+		Version:    backupVersion,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		Categories: []string{BackupCategoryTokens, BackupCategoryAlerts, BackupCategoryPortfolios},
+		Entries: []model.StateEntry{
+			{Category: BackupCategoryTokens, Key: tokenRegistryKey, Type: model.StateTypeString, String: &registry},
+			{Category: BackupCategoryAlerts, Key: alertRuleStateKey("user-1"), Type: model.StateTypeHash,
+				Hash: map[string]string{"3f1c8a52-7d0e-4b8e-9d44-2f7f1c0e6a11": `{"condition":"price > 70000","symbol":"BTCUSDT","triggered":true}`}},
+			{Category: BackupCategoryAlerts, Key: alertRuleHistoryKey("3f1c8a52-7d0e-4b8e-9d44-2f7f1c0e6a11"), Type: model.StateTypeZSet,
+				ZSet: []model.StateZMember{{Member: `{"id":"f1"}`, Score: 1700000000000}}, TTL: 60000},
+			{Category: BackupCategoryPortfolios, Key: portfolioTradesKeyPrefix + "user-1", Type: model.StateTypeList,
+				List: []string{`{"id":"t1"}`, `{"id":"t2"}`}},
+		},
+		Tables: []model.StateTable{
+			{Category: BackupCategoryAlerts, Table: "alert_rules", Rows: []json.RawMessage{row}},
+		},
+	}
+}
+
+// TestBackupRoundTrip 快照加密写入对象存储后按名称读回，key、表的行和过期时间保持不变，过期备份被清理
+func TestBackupRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	expired := backupFilePrefix + "20200101T000000Z" + backupFileSuffix
+	store.Put(ctx, expired, []byte("old"))
+	s := newTestBackupService(t, store)
+
+	key, err := s.backupKey()
+	if err != nil {
+		t.Fatalf("backup key: %v", err)
+	}
+	snapshot := testSnapshot(t)
+	file, err := s.writeBackup(ctx, key, snapshot)
+	if err != nil {
+		t.Fatalf("write backup: %v", err)
+	}
+	s.pruneBackups(ctx)
+
+	wantName := backupFilePrefix + snapshot.CreatedAt.Format(backupTimeLayout) + backupFileSuffix
+	if file.Name != wantName || file.Path != "mem://"+wantName {
+		t.Errorf("backup file = %s at %s", file.Name, file.Path)
+	}
+	if file.Keys != len(snapshot.Entries) || file.Rows != 1 {
+		t.Errorf("backup counts = %d keys, %d rows, want %d keys, 1 row", file.Keys, file.Rows, len(snapshot.Entries))
+	}
+	if names, _ := store.List(ctx, backupFilePrefix); !reflect.DeepEqual(names, []string{file.Name}) {
+		t.Errorf("stored backups = %v, want only %s", names, file.Name)
+	}
+
+	restored, err := s.loadBackup(ctx, file.Name)
+	if err != nil {
+		t.Fatalf("load backup: %v", err)
+	}
+	if err := validateSnapshot(restored); err != nil {
+		t.Fatalf("restored snapshot is invalid: %v", err)
+	}
+	if !restored.CreatedAt.Equal(snapshot.CreatedAt) {
+		t.Errorf("created_at = %v, want %v", restored.CreatedAt, snapshot.CreatedAt)
+	}
+	restored.CreatedAt = snapshot.CreatedAt
+	if !reflect.DeepEqual(restored, snapshot) {
+		got, _ := json.Marshal(restored)
+		want, _ := json.Marshal(snapshot)
+		t.Errorf("restored snapshot differs:\n got %s\nwant %s", got, want)
+	}
+
+	// 其他密钥、被篡改的文件和不存在的名称都无法恢复
+	other := newTestBackupService(t, store)
+	other.config.Backup.Key = base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+	if _, err := other.loadBackup(ctx, file.Name); !errors.Is(err, ErrBackupKey) {
+		t.Errorf("wrong key error = %v, want ErrBackupKey", err)
+	}
+	data, _ := store.Get(ctx, file.Name)
+	data[len(data)-1] ^= 0xff
+	store.Put(ctx, file.Name, data)
+	if _, err := s.loadBackup(ctx, file.Name); !errors.Is(err, ErrBackupKey) {
+		t.Errorf("tampered backup error = %v, want ErrBackupKey", err)
+	}
+	if _, err := s.loadBackup(ctx, "state-20990101T000000Z.bak"); !errors.Is(err, objectstore.ErrNotExist) {
+		t.Errorf("missing backup error = %v, want ErrNotExist", err)
+	}
+}
+
+// TestValidateSnapshotTables 拒绝未知的表、不属于所在分类的表和缺少必填列的行
+func TestValidateSnapshotTables(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(table *model.StateTable)
+	}{
+		{name: "unknown table", modify: func(table *model.StateTable) { table.Table = "users" }},
+		{name: "wrong category", modify: func(table *model.StateTable) { table.Category = BackupCategoryPortfolios }},
+		{name: "invalid id", modify: func(table *model.StateTable) {
+			table.Rows = []json.RawMessage{json.RawMessage(`{"id":"1","user_id":"u","symbol":"BTCUSDT","expression":"price > 1"}`)}
+		}},
+		{name: "missing user", modify: func(table *model.StateTable) {
+			table.Rows = []json.RawMessage{json.RawMessage(`{"id":"3f1c8a52-7d0e-4b8e-9d44-2f7f1c0e6a11","symbol":"BTCUSDT","expression":"price > 1"}`)}
+		}},
+		{name: "malformed row", modify: func(table *model.StateTable) { table.Rows = []json.RawMessage{json.RawMessage(`[]`)} }},
+	}
+
+	if err := validateSnapshot(testSnapshot(t)); err != nil {
+		t.Fatalf("valid snapshot rejected: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := testSnapshot(t)
+			tt.modify(&snapshot.Tables[0])
+			if err := validateSnapshot(snapshot); !errors.Is(err, ErrInvalidParameter) {
+				t.Errorf("error = %v, want ErrInvalidParameter", err)
+			}
+		})
+	}
+}
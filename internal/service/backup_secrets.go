@@ -0,0 +1,115 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"crypto-info/internal/model"
+)
+
+// backupSecretPrefix 导出接口中加密后的密钥前缀，后跟base64编码的nonce和密文
+const backupSecretPrefix = "enc:"
+
+// backupSecretFields 快照中的签名密钥，按key前缀匹配哈希，密钥为哈希值(JSON)中的字段
+var backupSecretFields = []struct {
+	prefix string
+	field  string
+}{
+	{prefix: userWebhookKeyPrefix, field: "secret"},
+	{prefix: eventFilterKeyPrefix, field: "webhook_secret"},
+}
+
+// protectSecrets 导出接口返回的快照中，配置了backup.key时加密签名密钥，否则删除
+func (s *backupService) protectSecrets(snapshot *model.StateSnapshot) error {
+	key, err := s.backupKey()
+	if err != nil {
+		snapshot.Secrets = model.StateSecretsRedacted
+		return rewriteSnapshotSecrets(snapshot, func(string) (string, error) { return "", nil })
+	}
+
+	aead, err := newBackupAEAD(key)
+	if err != nil {
+		return err
+	}
+	snapshot.Secrets = model.StateSecretsEncrypted
+	return rewriteSnapshotSecrets(snapshot, func(secret string) (string, error) {
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		return backupSecretPrefix + base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+	})
+}
+
+// revealSecrets 恢复前解密导出接口加密的签名密钥，需要导出时的backup.key
+func (s *backupService) revealSecrets(snapshot *model.StateSnapshot) error {
+	if snapshot.Secrets != model.StateSecretsEncrypted {
+		return nil
+	}
+	key, err := s.backupKey()
+	if err != nil {
+		return fmt.Errorf("%w: snapshot secrets are encrypted, backup.key is required to restore", ErrInvalidParameter)
+	}
+	aead, err := newBackupAEAD(key)
+	if err != nil {
+		return err
+	}
+	return rewriteSnapshotSecrets(snapshot, func(secret string) (string, error) {
+		encoded, ok := strings.CutPrefix(secret, backupSecretPrefix)
+		if !ok {
+			return "", fmt.Errorf("%w: snapshot secret is not encrypted", ErrInvalidParameter)
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(data) < aead.NonceSize() {
+			return "", fmt.Errorf("%w: malformed snapshot secret", ErrInvalidParameter)
+		}
+		plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+		if err != nil {
+			return "", fmt.Errorf("%w: failed to decrypt snapshot secret, wrong backup.key", ErrInvalidParameter)
+		}
+		return string(plain), nil
+	})
+}
+
+// rewriteSnapshotSecrets 对快照中每个非空签名密钥调用transform，返回空字符串时删除该字段
+func rewriteSnapshotSecrets(snapshot *model.StateSnapshot, transform func(secret string) (string, error)) error {
+	for i := range snapshot.Entries {
+		entry := &snapshot.Entries[i]
+		if entry.Type != model.StateTypeHash {
+			continue
+		}
+		for _, secretField := range backupSecretFields {
+			if !strings.HasPrefix(entry.Key, secretField.prefix) {
+				continue
+			}
+			for field, value := range entry.Hash {
+				var doc map[string]json.RawMessage
+				if err := json.Unmarshal([]byte(value), &doc); err != nil {
+					return fmt.Errorf("%w: malformed value in %s", ErrInvalidParameter, entry.Key)
+				}
+				var secret string
+				if raw, ok := doc[secretField.field]; !ok || json.Unmarshal(raw, &secret) != nil || secret == "" {
+					continue
+				}
+				replaced, err := transform(secret)
+				if err != nil {
+					return err
+				}
+				if replaced == "" {
+					delete(doc, secretField.field)
+				} else {
+					doc[secretField.field], _ = json.Marshal(replaced)
+				}
+				data, err := json.Marshal(doc)
+				if err != nil {
+					return err
+				}
+				entry.Hash[field] = string(data)
+			}
+		}
+	}
+	return nil
+}
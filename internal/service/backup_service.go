@@ -0,0 +1,495 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/objectstore"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+)
+
+// 状态快照配置
+const (
+	backupVersion   = 1   // 快照格式版本，恢复时拒绝更高的版本
+	backupScanCount = 500 // 单次SCAN返回的key数量提示
+)
+
+// 状态快照的分类
+const (
+	BackupCategoryTokens        = "tokens"        // 代币注册表、地址标签和同步状态
+	BackupCategoryAlerts        = "alerts"        // 条件告警规则及触发记录、定时通知、用户webhook
+	BackupCategoryEventFilters  = "event_filters" // 用户注册的链上事件过滤器
+	BackupCategoryPortfolios    = "portfolios"    // 用户导入的交易记录
+	BackupCategorySubscriptions = "subscriptions" // 推送的命名订阅
+	BackupCategoryCheckpoints   = "checkpoints"   // 区块监控和各扫描任务的进度
+)
+
+// backupCategory 分类包含的key，以*结尾的按前缀匹配，其他为完整key
+type backupCategory struct {
+	name     string
+	patterns []string
+}
+
+// backupCategories 可导出的分类，只包含无法从上游重新获取的状态，价格等缓存数据不导出
+var backupCategories = []backupCategory{
+	{name: BackupCategoryTokens, patterns: []string{tokenRegistryKey, addressLabelsKey, tokenSyncStatusKey}},
+	{name: BackupCategoryAlerts, patterns: []string{
+		alertRuleKeyPrefix + "*", alertRuleSymbolsKey, alertRuleSymbolKeyPrefix + "*", alertRuleStateKeyPrefix + "*", alertRuleHistoryKeyPrefix + "*",
+		scheduledAlertKeyPrefix + "*", scheduledAlertDueKey, userWebhookKeyPrefix + "*",
+	}},
+	{name: BackupCategoryEventFilters, patterns: []string{eventFilterKeyPrefix + "*", eventFilterIndexKey}},
+	{name: BackupCategoryPortfolios, patterns: []string{portfolioTradesKeyPrefix + "*"}},
+	{name: BackupCategorySubscriptions, patterns: []string{streamSubscriptionKeyPrefix + "*"}},
+	{name: BackupCategoryCheckpoints, patterns: []string{bscCheckpointKey, bridgeCursorKey, liquidityCursorKey, eventFilterCursorKey}},
+}
+
+// backupTable 分类包含的MySQL表，只有配置为保存在MySQL中时导出
+//
+// 价格历史(history.storage为mysql时的price_history)是可再采集的行情数据且体量较大，不在快照范围内。
+type backupTable struct {
+	category string
+	name     string
+	schema   string                        // 导出和恢复前执行，表不存在时创建
+	enabled  func(cfg *config.Config) bool // 当前配置是否使用该表
+	export   func(ctx context.Context, db *sqlx.DB) ([]json.RawMessage, error)
+	validate func(row json.RawMessage) error
+	restore  func(ctx context.Context, db *sqlx.DB, row json.RawMessage, overwrite bool) (bool, error)
+}
+
+// backupTables 可导出的MySQL表
+var backupTables = []backupTable{
+	{
+		category: BackupCategoryAlerts,
+		name:     "alert_rules",
+		schema:   mysqlAlertRuleSchema,
+		enabled:  func(cfg *config.Config) bool { return cfg.Notifier.Rules.Storage == alertRuleStorageMySQL },
+		export:   exportAlertRuleRows,
+		validate: validateAlertRuleRow,
+		restore:  restoreAlertRuleRow,
+	},
+}
+
+// BackupService Redis关键状态的导出和恢复服务接口
+type BackupService interface {
+	// Export 导出指定分类的状态，categories为空时导出全部分类；webhook签名密钥按backup.key加密，未配置时删除
+	Export(ctx context.Context, categories []string) (*model.StateSnapshot, error)
+	// Restore 将快照写入当前Redis和MySQL，overwrite为false时跳过已存在的key和行
+	Restore(ctx context.Context, snapshot *model.StateSnapshot, overwrite bool) (*model.StateRestoreResult, error)
+	// CreateBackup 导出backup.categories，加密后写入backup.storage指定的存储(本地目录或对象存储)，清理过期的备份
+	CreateBackup(ctx context.Context) (*model.BackupFile, error)
+	// RestoreBackup 解密备份并恢复到当前Redis和MySQL，path为本地文件路径或备份存储中的名称
+	RestoreBackup(ctx context.Context, path string, overwrite bool) (*model.StateRestoreResult, error)
+}
+
+// backupService 按分类扫描Redis key，按数据类型导出为JSON，key不含前缀以便恢复到其他环境；分类中保存在MySQL的表通过sqlx逐行导出
+type backupService struct {
+	redisClient database.RedisClient
+	mysqlClient database.MySQLClient // 未启用MySQL时为空
+	config      *config.Config
+	store       objectstore.Store // 加密备份的存储
+	storeErr    error             // 备份存储配置无效时的错误，只影响定时备份
+}
+
+// NewBackupService 创建状态导出和恢复服务
+func NewBackupService(redisClient database.RedisClient, mysqlClient database.MySQLClient, cfg *config.Config) BackupService {
+	s := &backupService{
+		redisClient: redisClient,
+		mysqlClient: mysqlClient,
+		config:      cfg,
+	}
+	s.store, s.storeErr = newBackupStore(&cfg.Backup)
+	return s
+}
+
+// Export 导出快照，快照通过接口返回给调用方，不包含明文签名密钥
+func (s *backupService) Export(ctx context.Context, categories []string) (*model.StateSnapshot, error) {
+	snapshot, err := s.export(ctx, categories)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.protectSecrets(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to protect snapshot secrets: %w", err)
+	}
+	return snapshot, nil
+}
+
+// export 依次扫描各分类的key并读取值和剩余过期时间，导出期间写入的数据可能部分包含在快照中
+func (s *backupService) export(ctx context.Context, categories []string) (*model.StateSnapshot, error) {
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: redis is not configured", ErrUpstreamUnavailable)
+	}
+	selected, err := selectBackupCategories(categories)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &model.StateSnapshot{
+		Version:   backupVersion,
+		CreatedAt: time.Now(),
+		Entries:   []model.StateEntry{},
+	}
+	for _, category := range selected {
+		snapshot.Categories = append(snapshot.Categories, category.name)
+
+		keys, err := s.categoryKeys(ctx, category)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s keys: %w", category.name, err)
+		}
+		for _, key := range keys {
+			entry, err := s.readEntry(ctx, key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export %s: %w", key, err)
+			}
+			if entry == nil {
+				continue
+			}
+			entry.Category = category.name
+			snapshot.Entries = append(snapshot.Entries, *entry)
+		}
+
+		tables, err := s.exportTables(ctx, category.name)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Tables = append(snapshot.Tables, tables...)
+	}
+
+	logger.From(ctx).Infof("Exported %d state keys and %d tables in categories %v", len(snapshot.Entries), len(snapshot.Tables), snapshot.Categories)
+	return snapshot, nil
+}
+
+// exportTables 导出分类中当前配置使用的MySQL表，MySQL不可用时返回错误，避免备份缺少用户数据
+func (s *backupService) exportTables(ctx context.Context, category string) ([]model.StateTable, error) {
+	var tables []model.StateTable
+	for _, table := range backupTables {
+		if table.category != category || !table.enabled(s.config) {
+			continue
+		}
+		if s.mysqlClient == nil {
+			return nil, fmt.Errorf("%w: mysql is not available for table %s", ErrUpstreamUnavailable, table.name)
+		}
+		db := s.mysqlClient.DB()
+		if _, err := db.ExecContext(ctx, table.schema); err != nil {
+			return nil, fmt.Errorf("failed to create %s table: %w", table.name, err)
+		}
+		rows, err := table.export(ctx, db)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", table.name, err)
+		}
+		tables = append(tables, model.StateTable{Category: category, Table: table.name, Rows: rows})
+	}
+	return tables, nil
+}
+
+// Restore 校验快照中的每个key都属于其分类后再写入，单个key的删除、写入和过期时间在一个事务中执行
+func (s *backupService) Restore(ctx context.Context, snapshot *model.StateSnapshot, overwrite bool) (*model.StateRestoreResult, error) {
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: redis is not configured", ErrUpstreamUnavailable)
+	}
+	if err := validateSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	if err := s.checkTables(snapshot); err != nil {
+		return nil, err
+	}
+	if err := s.revealSecrets(snapshot); err != nil {
+		return nil, err
+	}
+
+	client := s.redisClient.GetClient()
+	prefix := s.redisClient.KeyPrefix()
+	result := &model.StateRestoreResult{Categories: make(map[string]int)}
+
+	for _, entry := range snapshot.Entries {
+		key := prefix + entry.Key
+		if !overwrite {
+			exists, err := client.Exists(ctx, key).Result()
+			if err != nil {
+				return result, fmt.Errorf("failed to check %s: %w", entry.Key, err)
+			}
+			if exists > 0 {
+				result.Skipped++
+				result.SkippedKeys = append(result.SkippedKeys, entry.Key)
+				continue
+			}
+		}
+
+		_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			writeEntry(ctx, pipe, key, entry)
+			if entry.TTL > 0 {
+				pipe.PExpire(ctx, key, time.Duration(entry.TTL)*time.Millisecond)
+			}
+			return nil
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to restore %s: %w", entry.Key, err)
+		}
+		result.Restored++
+		result.Categories[entry.Category]++
+	}
+
+	for _, snapshotTable := range snapshot.Tables {
+		table, _ := findBackupTable(snapshotTable.Table)
+		db := s.mysqlClient.DB()
+		if _, err := db.ExecContext(ctx, table.schema); err != nil {
+			return result, fmt.Errorf("failed to create %s table: %w", table.name, err)
+		}
+		for _, row := range snapshotTable.Rows {
+			written, err := table.restore(ctx, db, row, overwrite)
+			if err != nil {
+				return result, err
+			}
+			if written {
+				result.RestoredRows++
+			} else {
+				result.SkippedRows++
+			}
+		}
+	}
+
+	logger.From(ctx).Infof("Restored %d state keys and %d rows, skipped %d existing keys and %d existing rows",
+		result.Restored, result.RestoredRows, result.Skipped, result.SkippedRows)
+	return result, nil
+}
+
+// checkTables 快照中的表需要当前配置同样保存在MySQL中，否则恢复的数据不会被使用
+func (s *backupService) checkTables(snapshot *model.StateSnapshot) error {
+	for _, snapshotTable := range snapshot.Tables {
+		table, _ := findBackupTable(snapshotTable.Table)
+		if !table.enabled(s.config) {
+			return fmt.Errorf("%w: table %s is not used by the current configuration", ErrInvalidParameter, table.name)
+		}
+		if s.mysqlClient == nil {
+			return fmt.Errorf("%w: mysql is not available for table %s", ErrUpstreamUnavailable, table.name)
+		}
+	}
+	return nil
+}
+
+// categoryKeys 分类下当前存在的key(不含前缀)，按key排序
+func (s *backupService) categoryKeys(ctx context.Context, category backupCategory) ([]string, error) {
+	client := s.redisClient.GetClient()
+	prefix := s.redisClient.KeyPrefix()
+
+	var keys []string
+	for _, pattern := range category.patterns {
+		if !strings.HasSuffix(pattern, "*") {
+			exists, err := client.Exists(ctx, prefix+pattern).Result()
+			if err != nil {
+				return nil, err
+			}
+			if exists > 0 {
+				keys = append(keys, pattern)
+			}
+			continue
+		}
+
+		iter := client.Scan(ctx, 0, escapeScanPattern(prefix+strings.TrimSuffix(pattern, "*"))+"*", backupScanCount).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, strings.TrimPrefix(iter.Val(), prefix))
+		}
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// readEntry 按数据类型读取key的值和剩余过期时间，key已被删除时返回nil
+func (s *backupService) readEntry(ctx context.Context, key string) (*model.StateEntry, error) {
+	client := s.redisClient.GetClient()
+	fullKey := s.redisClient.KeyPrefix() + key
+
+	keyType, err := client.Type(ctx, fullKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	entry := &model.StateEntry{Key: key, Type: keyType}
+
+	switch keyType {
+	case "none":
+		return nil, nil
+	case model.StateTypeString:
+		value, err := client.Get(ctx, fullKey).Result()
+		if err != nil {
+			return nil, err
+		}
+		entry.String = &value
+	case model.StateTypeHash:
+		entry.Hash, err = client.HGetAll(ctx, fullKey).Result()
+	case model.StateTypeList:
+		entry.List, err = client.LRange(ctx, fullKey, 0, -1).Result()
+	case model.StateTypeSet:
+		entry.Set, err = client.SMembers(ctx, fullKey).Result()
+	case model.StateTypeZSet:
+		var members []redis.Z
+		members, err = client.ZRangeWithScores(ctx, fullKey, 0, -1).Result()
+		for _, member := range members {
+			entry.ZSet = append(entry.ZSet, model.StateZMember{Member: fmt.Sprint(member.Member), Score: member.Score})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported redis type %s", keyType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := client.PTTL(ctx, fullKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		entry.TTL = ttl.Milliseconds()
+	}
+	return entry, nil
+}
+
+// writeEntry 按数据类型写入key，空集合不写入
+func writeEntry(ctx context.Context, pipe redis.Pipeliner, key string, entry model.StateEntry) {
+	switch entry.Type {
+	case model.StateTypeString:
+		pipe.Set(ctx, key, *entry.String, 0)
+	case model.StateTypeHash:
+		if len(entry.Hash) > 0 {
+			pipe.HSet(ctx, key, entry.Hash)
+		}
+	case model.StateTypeList:
+		if len(entry.List) > 0 {
+			values := make([]interface{}, len(entry.List))
+			for i, value := range entry.List {
+				values[i] = value
+			}
+			pipe.RPush(ctx, key, values...)
+		}
+	case model.StateTypeSet:
+		if len(entry.Set) > 0 {
+			values := make([]interface{}, len(entry.Set))
+			for i, value := range entry.Set {
+				values[i] = value
+			}
+			pipe.SAdd(ctx, key, values...)
+		}
+	case model.StateTypeZSet:
+		if len(entry.ZSet) > 0 {
+			members := make([]redis.Z, len(entry.ZSet))
+			for i, member := range entry.ZSet {
+				members[i] = redis.Z{Member: member.Member, Score: member.Score}
+			}
+			pipe.ZAdd(ctx, key, members...)
+		}
+	}
+}
+
+// selectBackupCategories 按名称选择分类，names为空时选择全部
+func selectBackupCategories(names []string) ([]backupCategory, error) {
+	if len(names) == 0 {
+		return backupCategories, nil
+	}
+
+	selected := make([]backupCategory, 0, len(names))
+	for _, name := range names {
+		category, ok := findBackupCategory(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown category %s", ErrInvalidParameter, name)
+		}
+		selected = append(selected, category)
+	}
+	return selected, nil
+}
+
+// findBackupCategory 按名称查找分类
+func findBackupCategory(name string) (backupCategory, bool) {
+	for _, category := range backupCategories {
+		if category.name == name {
+			return category, true
+		}
+	}
+	return backupCategory{}, false
+}
+
+// validateSnapshot 检查快照版本、分类、key和数据类型，避免通过恢复接口写入分类之外的key
+func validateSnapshot(snapshot *model.StateSnapshot) error {
+	if snapshot == nil {
+		return fmt.Errorf("%w: snapshot is required", ErrInvalidParameter)
+	}
+	if snapshot.Version <= 0 || snapshot.Version > backupVersion {
+		return fmt.Errorf("%w: unsupported snapshot version %d", ErrInvalidParameter, snapshot.Version)
+	}
+
+	for _, entry := range snapshot.Entries {
+		category, ok := findBackupCategory(entry.Category)
+		if !ok {
+			return fmt.Errorf("%w: unknown category %s for key %s", ErrInvalidParameter, entry.Category, entry.Key)
+		}
+		if !category.matches(entry.Key) {
+			return fmt.Errorf("%w: key %s does not belong to category %s", ErrInvalidParameter, entry.Key, entry.Category)
+		}
+		switch entry.Type {
+		case model.StateTypeString:
+			if entry.String == nil {
+				return fmt.Errorf("%w: key %s has no string value", ErrInvalidParameter, entry.Key)
+			}
+		case model.StateTypeHash, model.StateTypeList, model.StateTypeSet, model.StateTypeZSet:
+		default:
+			return fmt.Errorf("%w: unsupported type %s for key %s", ErrInvalidParameter, entry.Type, entry.Key)
+		}
+		if entry.TTL < 0 {
+			return fmt.Errorf("%w: negative ttl for key %s", ErrInvalidParameter, entry.Key)
+		}
+	}
+
+	for _, snapshotTable := range snapshot.Tables {
+		table, ok := findBackupTable(snapshotTable.Table)
+		if !ok || table.category != snapshotTable.Category {
+			return fmt.Errorf("%w: table %s does not belong to category %s", ErrInvalidParameter, snapshotTable.Table, snapshotTable.Category)
+		}
+		for i, row := range snapshotTable.Rows {
+			if err := table.validate(row); err != nil {
+				return fmt.Errorf("%w: row %d of table %s: %v", ErrInvalidParameter, i, table.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// findBackupTable 按名称查找表
+func findBackupTable(name string) (backupTable, bool) {
+	for _, table := range backupTables {
+		if table.name == name {
+			return table, true
+		}
+	}
+	return backupTable{}, false
+}
+
+// matches key是否属于该分类
+func (c backupCategory) matches(key string) bool {
+	for _, pattern := range c.patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) && len(key) > len(prefix) {
+				return true
+			}
+		} else if key == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeScanPattern 转义SCAN MATCH中的通配符，使key前缀按字面匹配
+func escapeScanPattern(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+	return replacer.Replace(s)
+}
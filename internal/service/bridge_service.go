@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/bscscan"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+
+	"github.com/shopspring/decimal"
+)
+
+// 跨链桥跟踪默认配置
+const (
+	defaultBridgeInterval      = 5 * time.Minute
+	defaultBridgeRetention     = 7 * 24 * time.Hour
+	defaultBridgePageSize      = 100
+	defaultBridgeActivityLimit = 100
+	maxBridgeActivityLimit     = 1000
+	bridgeMaxPages             = 5 // 单次扫描最多向前翻的页数
+	bridgeActivityKey          = "bridges:activity"
+	bridgeCursorKey            = "bridges:cursor"
+)
+
+// BridgeService 跨链桥活动跟踪服务接口
+type BridgeService interface {
+	Start(ctx context.Context) error
+	Stop() error
+	ScanNow(ctx context.Context) error
+	GetActivity(ctx context.Context, bridge, direction string, from, to time.Time, limit int) (*model.BridgeActivityResponse, error)
+}
+
+// bridgeService 定期读取跨链桥合约的代币转入/转出，合并为跨链转账记录存入Redis有序集合
+type bridgeService struct {
+	redisClient  database.RedisClient
+	config       *config.Config
+	priceService PriceService
+	bscscan      *bscscan.Client
+	logger       logger.Logger
+
+	runMutex  sync.Mutex
+	scanMutex sync.Mutex
+	running   bool
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewBridgeService 创建跨链桥跟踪服务，priceService用于估算美元价值，可为nil
+func NewBridgeService(redisClient database.RedisClient, cfg *config.Config, priceService PriceService) BridgeService {
+	return &bridgeService{
+		redisClient:  redisClient,
+		config:       cfg,
+		priceService: priceService,
+		bscscan:      bscscan.NewClient(&cfg.ExternalAPI.BscScan),
+		logger:       logger.GetLogger(),
+	}
+}
+
+// Start 启动定时扫描
+func (s *bridgeService) Start(ctx context.Context) error {
+	if !s.config.BSC.Bridges.Enabled || len(s.config.BSC.Bridges.Contracts) == 0 || s.redisClient == nil {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("bridge tracking is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "bridge", s.run, s.done)
+
+	s.logger.Infof("Bridge tracking started with %d contracts", len(s.config.BSC.Bridges.Contracts))
+	return nil
+}
+
+// Stop 停止定时扫描
+func (s *bridgeService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	s.logger.Info("Bridge tracking stopped")
+	return nil
+}
+
+// run 启动时立即扫描一次，之后按间隔扫描
+func (s *bridgeService) run(ctx context.Context) {
+	interval := s.config.BSC.Bridges.Interval
+	if interval <= 0 {
+		interval = defaultBridgeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ScanNow(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("Bridge scan failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScanNow 立即扫描所有跨链桥，单个桥失败不影响其他桥
+func (s *bridgeService) ScanNow(ctx context.Context) error {
+	if s.redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+
+	s.scanMutex.Lock()
+	defer s.scanMutex.Unlock()
+
+	var errs []error
+	pricer := newUSDPricer(s.priceService)
+	for _, bridge := range s.config.BSC.Bridges.Contracts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.scanBridge(ctx, bridge, pricer); err != nil {
+			s.logger.Warnf("Failed to scan bridge %s: %v", bridge.Name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", bridge.Name, err))
+		}
+	}
+
+	cutoff := time.Now().Add(-s.retention()).UnixMilli()
+	if err := s.redisClient.ZRemRangeByScore(ctx, bridgeActivityKey, "-inf", "("+strconv.FormatInt(cutoff, 10)); err != nil {
+		s.logger.Warnf("Failed to trim bridge activity: %v", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// scanBridge 拉取游标之后的新转账，合并后写入活动记录
+func (s *bridgeService) scanBridge(ctx context.Context, bridge config.BridgeContract, pricer *usdPricer) error {
+	cursorValue, err := s.redisClient.HGet(ctx, bridgeCursorKey, bridge.Name)
+	if err != nil {
+		return err
+	}
+	cursor, _ := strconv.ParseUint(cursorValue, 10, 64)
+
+	// 按区块倒序翻页，直到遇到已处理的区块
+	var fresh []bscscan.TokenTransfer
+	pageSize := s.pageSize()
+	for page := 1; page <= bridgeMaxPages; page++ {
+		items, err := s.bscscan.GetTokenTransfers(ctx, "", bridge.Address, page, pageSize)
+		if err != nil {
+			return err
+		}
+
+		reachedCursor := false
+		for _, item := range items {
+			if parseUint(item.BlockNumber) <= cursor {
+				reachedCursor = true
+				break
+			}
+			fresh = append(fresh, item)
+		}
+		// 首次扫描只取第一页，避免回溯全部历史
+		if reachedCursor || len(items) < pageSize || cursor == 0 {
+			break
+		}
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	records := correlateBridgeTransfers(bridge, fresh)
+	for i := range records {
+		if price, ok := pricer.price(ctx, records[i].Symbol); ok {
+			records[i].ValueUSD = records[i].Amount * price
+		}
+
+		data, err := json.Marshal(records[i])
+		if err != nil {
+			return err
+		}
+		if err := s.redisClient.ZAdd(ctx, bridgeActivityKey, float64(records[i].Timestamp.UnixMilli()), string(data)); err != nil {
+			return fmt.Errorf("failed to save bridge transfer: %w", err)
+		}
+	}
+
+	// fresh按区块倒序，第一条即为最新区块
+	latest := parseUint(fresh[0].BlockNumber)
+	if err := s.redisClient.HSet(ctx, bridgeCursorKey, bridge.Name, strconv.FormatUint(latest, 10)); err != nil {
+		return err
+	}
+
+	s.logger.Infof("Scanned bridge %s: transfers=%d records=%d block=%d", bridge.Name, len(fresh), len(records), latest)
+	return nil
+}
+
+// correlateBridgeTransfers 将代币转账按交易、方向和代币合并为跨链转账记录
+func correlateBridgeTransfers(bridge config.BridgeContract, transfers []bscscan.TokenTransfer) []model.BridgeTransfer {
+	bridgeAddress := strings.ToLower(bridge.Address)
+	index := make(map[string]int)
+	var records []model.BridgeTransfer
+
+	for _, item := range transfers {
+		var direction, account string
+		switch bridgeAddress {
+		case strings.ToLower(item.To):
+			direction, account = model.BridgeDirectionOutbound, item.From
+		case strings.ToLower(item.From):
+			direction, account = model.BridgeDirectionInbound, item.To
+		default:
+			continue
+		}
+
+		token := strings.ToLower(item.ContractAddress)
+		id := strings.ToLower(item.Hash) + ":" + direction + ":" + token
+		amount := tokenAmount(item.Value, item.TokenDecimal)
+
+		if i, ok := index[id]; ok {
+			records[i].Amount += amount
+			continue
+		}
+		index[id] = len(records)
+		records = append(records, model.BridgeTransfer{
+			ID:          id,
+			Bridge:      bridge.Name,
+			Direction:   direction,
+			TxHash:      item.Hash,
+			BlockNumber: parseUint(item.BlockNumber),
+			Account:     strings.ToLower(account),
+			Token:       token,
+			Symbol:      strings.ToUpper(item.TokenSymbol),
+			Amount:      amount,
+			Timestamp:   parseUnixTime(item.TimeStamp),
+		})
+	}
+	return records
+}
+
+// tokenAmount 按代币精度换算转账数量
+func tokenAmount(value, decimals string) float64 {
+	raw, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return 0
+	}
+	exp, err := strconv.ParseInt(decimals, 10, 32)
+	if err != nil {
+		exp = 18
+	}
+	return decimal.NewFromBigInt(raw, -int32(exp)).InexactFloat64()
+}
+
+// GetActivity 获取时间范围内的跨链转账及各跨链桥统计
+func (s *bridgeService) GetActivity(ctx context.Context, bridge, direction string, from, to time.Time, limit int) (*model.BridgeActivityResponse, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidParameter)
+	}
+	if direction != "" && direction != model.BridgeDirectionOutbound && direction != model.BridgeDirectionInbound {
+		return nil, fmt.Errorf("%w: unsupported direction %q", ErrInvalidParameter, direction)
+	}
+	if bridge != "" && s.findBridge(bridge) == nil {
+		return nil, fmt.Errorf("%w: unknown bridge %q", ErrNotFound, bridge)
+	}
+	if limit <= 0 {
+		limit = defaultBridgeActivityLimit
+	}
+	if limit > maxBridgeActivityLimit {
+		limit = maxBridgeActivityLimit
+	}
+
+	var transfers []model.BridgeTransfer
+	if s.redisClient != nil {
+		members, err := s.redisClient.ZRangeByScore(ctx, bridgeActivityKey,
+			strconv.FormatInt(from.UnixMilli(), 10), strconv.FormatInt(to.UnixMilli(), 10))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bridge activity: %w", err)
+		}
+		for _, member := range members {
+			var transfer model.BridgeTransfer
+			if err := json.Unmarshal([]byte(member), &transfer); err != nil {
+				continue
+			}
+			if bridge != "" && transfer.Bridge != bridge {
+				continue
+			}
+			if direction != "" && transfer.Direction != direction {
+				continue
+			}
+			transfers = append(transfers, transfer)
+		}
+	}
+
+	// 有序集合按时间升序，响应按时间倒序
+	sort.SliceStable(transfers, func(i, j int) bool {
+		return transfers[i].Timestamp.After(transfers[j].Timestamp)
+	})
+
+	resp := &model.BridgeActivityResponse{
+		From:      from,
+		To:        to,
+		Bridges:   s.bridgeStats(bridge, transfers),
+		Transfers: transfers,
+		Total:     len(transfers),
+	}
+	if len(resp.Transfers) > limit {
+		resp.Transfers = resp.Transfers[:limit]
+	}
+	if resp.Transfers == nil {
+		resp.Transfers = []model.BridgeTransfer{}
+	}
+	return resp, nil
+}
+
+// bridgeStats 按跨链桥汇总转账笔数与金额，未出现活动的桥也会列出
+func (s *bridgeService) bridgeStats(bridge string, transfers []model.BridgeTransfer) []model.BridgeStats {
+	stats := make([]model.BridgeStats, 0, len(s.config.BSC.Bridges.Contracts))
+	index := make(map[string]int)
+	accounts := make(map[string]map[string]struct{})
+	for _, contract := range s.config.BSC.Bridges.Contracts {
+		if bridge != "" && contract.Name != bridge {
+			continue
+		}
+		index[contract.Name] = len(stats)
+		accounts[contract.Name] = make(map[string]struct{})
+		stats = append(stats, model.BridgeStats{Bridge: contract.Name, Address: contract.Address})
+	}
+
+	for _, transfer := range transfers {
+		i, ok := index[transfer.Bridge]
+		if !ok {
+			continue
+		}
+		stat := &stats[i]
+		if transfer.Direction == model.BridgeDirectionOutbound {
+			stat.Deposits++
+			stat.DepositVolumeUSD += transfer.ValueUSD
+		} else {
+			stat.Withdrawals++
+			stat.WithdrawalVolumeUSD += transfer.ValueUSD
+		}
+		accounts[transfer.Bridge][transfer.Account] = struct{}{}
+		if stat.LastActivity == nil || transfer.Timestamp.After(*stat.LastActivity) {
+			ts := transfer.Timestamp
+			stat.LastActivity = &ts
+		}
+	}
+
+	for i := range stats {
+		stats[i].NetFlowUSD = stats[i].WithdrawalVolumeUSD - stats[i].DepositVolumeUSD
+		stats[i].Accounts = len(accounts[stats[i].Bridge])
+	}
+	return stats
+}
+
+// findBridge 按名称查找跨链桥配置
+func (s *bridgeService) findBridge(name string) *config.BridgeContract {
+	for i := range s.config.BSC.Bridges.Contracts {
+		if s.config.BSC.Bridges.Contracts[i].Name == name {
+			return &s.config.BSC.Bridges.Contracts[i]
+		}
+	}
+	return nil
+}
+
+// retention 记录保留时长
+func (s *bridgeService) retention() time.Duration {
+	if s.config.BSC.Bridges.Retention > 0 {
+		return s.config.BSC.Bridges.Retention
+	}
+	return defaultBridgeRetention
+}
+
+// pageSize 每页拉取的转账条数
+func (s *bridgeService) pageSize() int {
+	if s.config.BSC.Bridges.PageSize > 0 {
+		return s.config.BSC.Bridges.PageSize
+	}
+	return defaultBridgePageSize
+}
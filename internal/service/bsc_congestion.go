@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"crypto-info/internal/model"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 网络拥堵评分的默认配置
+const (
+	defaultCongestionSampleBlocks     = 20
+	defaultCongestionCacheTTL         = 15 * time.Second
+	defaultCongestionBaselineGasPrice = 0.1 // Gwei
+)
+
+// 拥堵评分的组成：区块利用率、平均Gas价格相对基准的涨幅、待处理交易积压
+const (
+	congestionUtilizationWeight = 0.5
+	congestionGasPriceWeight    = 0.3
+	congestionBacklogWeight     = 0.2
+
+	congestionFullBlockRatio  = 0.95 // 利用率达到该值视为满块
+	congestionGasPriceCeiling = 5.0  // 平均Gas价格达到基准的该倍数时该项满分
+	congestionBacklogCeiling  = 3.0  // 待处理交易需要该数量的区块才能清空时该项满分
+)
+
+// 待处理交易数的来源
+const (
+	congestionPendingTxPool = "txpool"
+	congestionPendingBlock  = "pending_block"
+)
+
+// GetCongestion 按最近sample_blocks个区块计算网络拥堵情况，结果在进程内缓存cache_ttl，API和指标共用
+//
+// 计算期间持有锁，并发请求等待同一次计算，不会放大对节点的调用。
+func (s *bscService) GetCongestion(ctx context.Context) (*model.BSCCongestion, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("BSC client not initialized")
+	}
+
+	ttl := s.config.Congestion.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultCongestionCacheTTL
+	}
+
+	s.congestionMutex.Lock()
+	defer s.congestionMutex.Unlock()
+
+	if s.congestion == nil || time.Since(s.congestion.UpdatedAt) >= ttl {
+		congestion, err := s.computeCongestion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.congestion = congestion
+	}
+
+	result := *s.congestion
+	return &result, nil
+}
+
+// computeCongestion 通过eth_feeHistory获取各区块的利用率和Gas价格，批量查询交易数，再结合待处理交易估算积压
+func (s *bscService) computeCongestion(ctx context.Context) (*model.BSCCongestion, error) {
+	sampleBlocks := s.config.Congestion.SampleBlocks
+	if sampleBlocks <= 0 {
+		sampleBlocks = defaultCongestionSampleBlocks
+	}
+
+	latest, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block number: %w", err)
+	}
+	if uint64(sampleBlocks) > latest+1 {
+		sampleBlocks = int(latest + 1)
+	}
+
+	history, err := s.client.FeeHistory(ctx, uint64(sampleBlocks), new(big.Int).SetUint64(latest), []float64{50})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	if len(history.GasUsedRatio) == 0 {
+		return nil, fmt.Errorf("fee history returned no blocks")
+	}
+	oldest := history.OldestBlock.Uint64()
+
+	txCounts := s.blockTxCounts(ctx, oldest, len(history.GasUsedRatio))
+
+	congestion := &model.BSCCongestion{
+		LatestBlock:  latest,
+		SampleBlocks: len(history.GasUsedRatio),
+		Blocks:       make([]model.BSCBlockUtilization, 0, len(history.GasUsedRatio)),
+		UpdatedAt:    time.Now(),
+	}
+
+	var utilizationSum, gasPriceSum float64
+	var txSum, txBlocks int
+	for i, ratio := range history.GasUsedRatio {
+		baseFee := new(big.Int)
+		if i < len(history.BaseFee) && history.BaseFee[i] != nil {
+			baseFee.Set(history.BaseFee[i])
+		}
+		gasPrice := new(big.Int).Set(baseFee)
+		if i < len(history.Reward) && len(history.Reward[i]) > 0 && history.Reward[i][0] != nil {
+			gasPrice.Add(gasPrice, history.Reward[i][0])
+		}
+
+		block := model.BSCBlockUtilization{
+			Number:       oldest + uint64(i),
+			Utilization:  math.Round(ratio*10000) / 10000,
+			BaseFeeGwei:  weiToGwei(baseFee),
+			GasPriceGwei: weiToGwei(gasPrice),
+			Transactions: -1,
+		}
+		if txCounts[i] >= 0 {
+			block.Transactions = txCounts[i]
+			txSum += txCounts[i]
+			txBlocks++
+		}
+		congestion.Blocks = append(congestion.Blocks, block)
+
+		utilizationSum += ratio
+		gasPriceSum += block.GasPriceGwei
+		if ratio > congestion.MaxUtilization {
+			congestion.MaxUtilization = math.Round(ratio*10000) / 10000
+		}
+		if ratio >= congestionFullBlockRatio {
+			congestion.FullBlocks++
+		}
+	}
+
+	count := float64(len(congestion.Blocks))
+	congestion.AvgUtilization = math.Round(utilizationSum/count*10000) / 10000
+	congestion.AvgGasPriceGwei = math.Round(gasPriceSum/count*10000) / 10000
+	if txBlocks > 0 {
+		congestion.AvgTxsPerBlock = math.Round(float64(txSum)/float64(txBlocks)*100) / 100
+	}
+
+	if suggested, err := s.client.SuggestGasPrice(ctx); err != nil {
+		s.logger.Warnf("Failed to get suggested gas price: %v", err)
+	} else {
+		congestion.SuggestedGasPriceGwei = weiToGwei(suggested)
+	}
+
+	if pending, source, ok := s.pendingTxCount(ctx); ok {
+		congestion.PendingTxs = &pending
+		congestion.PendingSource = source
+		if congestion.AvgTxsPerBlock > 0 {
+			backlog := math.Round(float64(pending)/congestion.AvgTxsPerBlock*100) / 100
+			congestion.BacklogBlocks = &backlog
+		}
+	}
+
+	congestion.Score = s.congestionScore(congestion)
+	congestion.Level = congestionLevel(congestion.Score)
+	return congestion, nil
+}
+
+// blockTxCounts 一次批量请求获取连续count个区块的交易数，单个区块查询失败时对应位置为-1
+func (s *bscService) blockTxCounts(ctx context.Context, from uint64, count int) []int {
+	counts := make([]int, count)
+	results := make([]hexutil.Uint, count)
+	batch := make([]rpc.BatchElem, count)
+	for i := range batch {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBlockTransactionCountByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(from + uint64(i))},
+			Result: &results[i],
+		}
+	}
+
+	if err := s.client.Client().BatchCallContext(ctx, batch); err != nil {
+		s.logger.Warnf("Failed to get transaction counts of blocks %d-%d: %v", from, from+uint64(count)-1, err)
+		for i := range counts {
+			counts[i] = -1
+		}
+		return counts
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			counts[i] = -1
+			continue
+		}
+		counts[i] = int(results[i])
+	}
+	return counts
+}
+
+// pendingTxCount 获取节点的待处理交易数，优先使用txpool_status，节点未开放txpool接口时使用pending区块的交易数
+func (s *bscService) pendingTxCount(ctx context.Context) (uint64, string, bool) {
+	var status struct {
+		Pending hexutil.Uint64 `json:"pending"`
+	}
+	err := s.client.Client().CallContext(ctx, &status, "txpool_status")
+	if err == nil {
+		return uint64(status.Pending), congestionPendingTxPool, true
+	}
+	s.logger.Debugf("txpool_status unavailable, falling back to pending block: %v", err)
+
+	pending, err := s.client.PendingTransactionCount(ctx)
+	if err != nil {
+		s.logger.Debugf("Failed to get pending transaction count: %v", err)
+		return 0, "", false
+	}
+	return uint64(pending), congestionPendingBlock, true
+}
+
+// congestionScore 按权重合成0~100的拥堵评分，节点未提供待处理交易数时积压项的权重按比例分给其余两项
+func (s *bscService) congestionScore(c *model.BSCCongestion) float64 {
+	baseline := s.config.Congestion.BaselineGasPrice
+	if baseline <= 0 {
+		baseline = defaultCongestionBaselineGasPrice
+	}
+
+	gasPriceScore := clampUnit((c.AvgGasPriceGwei/baseline - 1) / (congestionGasPriceCeiling - 1))
+	score := congestionUtilizationWeight*clampUnit(c.AvgUtilization) + congestionGasPriceWeight*gasPriceScore
+	weight := congestionUtilizationWeight + congestionGasPriceWeight
+	if c.BacklogBlocks != nil {
+		score += congestionBacklogWeight * clampUnit(*c.BacklogBlocks/congestionBacklogCeiling)
+		weight += congestionBacklogWeight
+	}
+	return math.Round(score/weight*1000) / 10
+}
+
+// congestionLevel 按评分划分拥堵等级
+func congestionLevel(score float64) string {
+	switch {
+	case score >= 75:
+		return model.BSCCongestionSevere
+	case score >= 50:
+		return model.BSCCongestionHigh
+	case score >= 25:
+		return model.BSCCongestionModerate
+	default:
+		return model.BSCCongestionLow
+	}
+}
+
+// clampUnit 将数值限制在0~1
+func clampUnit(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// weiToGwei 将wei换算为Gwei，保留4位小数
+func weiToGwei(wei *big.Int) float64 {
+	if wei == nil {
+		return 0
+	}
+	gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Float64()
+	return math.Round(gwei*10000) / 10000
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/bscscan"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fallbackTokenTransfers 本地索引尚无数据时从BscScan获取代币转账记录
+func (s *bscService) fallbackTokenTransfers(ctx context.Context, tokenAddress common.Address, page, pageSize int) (*model.BSCTokenTransferResponse, error) {
+	items, err := s.bscscan.GetTokenTransfers(ctx, tokenAddress.Hex(), "", page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bscscan token transfers: %w", ErrUpstreamUnavailable, err)
+	}
+
+	transfers := make([]model.BSCTokenTransfer, 0, len(items))
+	for _, item := range items {
+		transfer := model.BSCTokenTransfer{
+			TxHash:      common.HexToHash(item.Hash),
+			BlockNumber: parseBigInt(item.BlockNumber),
+			LogIndex:    uint(parseUint(item.LogIndex)),
+			Token:       common.HexToAddress(item.ContractAddress),
+			From:        common.HexToAddress(item.From),
+			To:          common.HexToAddress(item.To),
+			AmountRaw:   parseBigInt(item.Value),
+			Timestamp:   parseUnixTime(item.TimeStamp),
+		}
+		// BscScan返回了代币精度时直接使用，否则按代币注册表或合约换算
+		if decimals, err := strconv.ParseUint(item.TokenDecimal, 10, 8); err == nil {
+			d := uint8(decimals)
+			transfer.Amount, transfer.Decimals = formatUnits(transfer.AmountRaw, d), &d
+		} else {
+			transfer.Amount, transfer.Decimals = s.units.format(ctx, transfer.Token, transfer.AmountRaw)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return &model.BSCTokenTransferResponse{
+		Transfers: transfers,
+		Total:     fallbackTotal(page, pageSize, len(transfers)),
+		Page:      page,
+		PageSize:  pageSize,
+		Source:    model.DataSourceBscScan,
+	}, nil
+}
+
+// fallbackAddressTransactions 本地索引尚无数据时从BscScan获取地址交易记录
+func (s *bscService) fallbackAddressTransactions(ctx context.Context, address common.Address, page, pageSize int) (*model.BSCTransactionResponse, error) {
+	items, err := s.bscscan.GetTransactions(ctx, address.Hex(), page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bscscan transactions: %w", ErrUpstreamUnavailable, err)
+	}
+
+	transactions := make([]model.BSCTransaction, 0, len(items))
+	for _, item := range items {
+		transactions = append(transactions, normalizeBscScanTransaction(item))
+	}
+
+	return &model.BSCTransactionResponse{
+		Transactions: transactions,
+		Total:        fallbackTotal(page, pageSize, len(transactions)),
+		Page:         page,
+		PageSize:     pageSize,
+		Source:       model.DataSourceBscScan,
+	}, nil
+}
+
+// normalizeBscScanTransaction 将BscScan交易记录转换为内部模型
+func normalizeBscScanTransaction(item bscscan.Transaction) model.BSCTransaction {
+	tx := model.BSCTransaction{
+		Hash:        common.HexToHash(item.Hash),
+		BlockNumber: parseBigInt(item.BlockNumber),
+		From:        common.HexToAddress(item.From),
+		ValueRaw:    parseBigInt(item.Value),
+		Value:       formatUnits(parseBigInt(item.Value), nativeDecimals),
+		GasPrice:    parseBigInt(item.GasPrice),
+		GasUsed:     parseUint(item.GasUsed),
+		Timestamp:   parseUnixTime(item.TimeStamp),
+	}
+
+	// 合约创建交易的to为空，与本地模型保持一致
+	if item.To != "" {
+		to := common.HexToAddress(item.To)
+		tx.To = &to
+	}
+
+	// 与交易回执一致：1成功，0失败
+	switch {
+	case item.TxReceiptStatus != "":
+		tx.Status = parseUint(item.TxReceiptStatus)
+	case item.IsError == "0":
+		tx.Status = 1
+	}
+
+	return tx
+}
+
+// fallbackTotal BscScan不返回总数，按已翻过的页数估算
+func fallbackTotal(page, pageSize, count int) int {
+	return (page-1)*pageSize + count
+}
+
+// parseBigInt 解析十进制大整数，失败时返回0
+func parseBigInt(value string) *big.Int {
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return big.NewInt(0)
+	}
+	return n
+}
+
+// parseUint 解析十进制无符号整数，失败时返回0
+func parseUint(value string) uint64 {
+	n, _ := strconv.ParseUint(value, 10, 64)
+	return n
+}
+
+// parseUnixTime 解析Unix秒级时间戳
+func parseUnixTime(value string) time.Time {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
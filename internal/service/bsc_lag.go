@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"crypto-info/internal/model"
+)
+
+// AlertTypeBSCMonitorLag 区块监控落后或停滞的系统告警
+const AlertTypeBSCMonitorLag = "bsc_monitor_lag"
+
+// checkLag 每轮处理后按lag_alert检查监控是否落后或停滞，进入滞后状态时发送一次告警，恢复后清除标记
+func (s *bscService) checkLag(ctx context.Context, progressed bool) {
+	lagAlert := s.config.Monitoring.LagAlert
+
+	var (
+		stats            model.BSCMonitoringStats
+		reason           string
+		entered, cleared bool
+	)
+	s.updateStats(func(current *model.BSCMonitoringStats) {
+		if progressed {
+			current.StalledIntervals = 0
+		} else {
+			current.StalledIntervals++
+		}
+
+		switch {
+		case lagAlert.MaxLagBlocks > 0 && current.LagBlocks > uint64(lagAlert.MaxLagBlocks):
+			reason = fmt.Sprintf("%d blocks behind the latest block", current.LagBlocks)
+		case lagAlert.StallIntervals > 0 && current.StalledIntervals >= lagAlert.StallIntervals:
+			reason = fmt.Sprintf("no block processed for %d intervals", current.StalledIntervals)
+		}
+
+		entered = reason != "" && !current.Lagging
+		cleared = reason == "" && current.Lagging
+		current.Lagging = reason != ""
+		current.LagReason = reason
+		stats = *current
+	})
+
+	switch {
+	case entered:
+		s.logger.Warnf("BSC block monitoring is lagging: %s (checkpoint %d)", reason, stats.Checkpoint)
+		s.sendLagAlert(ctx, reason, stats)
+	case cleared:
+		s.logger.Infof("BSC block monitoring caught up at block %d", stats.Checkpoint)
+	}
+}
+
+// sendLagAlert 发送区块监控滞后告警，同一滞后期间只发送一次
+func (s *bscService) sendLagAlert(ctx context.Context, reason string, stats model.BSCMonitoringStats) {
+	if s.notifier == nil {
+		return
+	}
+
+	var latest string
+	if stats.LatestBlock != nil {
+		latest = stats.LatestBlock.String()
+	}
+	alert := &model.Alert{
+		Type:     AlertTypeBSCMonitorLag,
+		Severity: model.AlertSeverityWarning,
+		Title:    "BSC block monitoring is lagging",
+		Message:  fmt.Sprintf("BSC block monitoring is %s, checkpoint at block %d", reason, stats.Checkpoint),
+		DedupKey: AlertTypeBSCMonitorLag,
+		Data: map[string]interface{}{
+			"reason":            reason,
+			"checkpoint":        stats.Checkpoint,
+			"latest_block":      latest,
+			"lag_blocks":        stats.LagBlocks,
+			"stalled_intervals": stats.StalledIntervals,
+			"last_update_time":  stats.LastUpdateTime,
+		},
+	}
+	if err := s.notifier.Notify(ctx, alert); err != nil {
+		s.logger.Warnf("Failed to send BSC monitoring lag alert: %v", err)
+	}
+}
@@ -4,19 +4,26 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"crypto-info/internal/config"
 	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/bscscan"
+	"crypto-info/internal/pkg/budget"
 	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/httpclient"
 	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/shopspring/decimal"
 )
 
@@ -26,12 +33,22 @@ type BSCService interface {
 	Start(ctx context.Context) error
 	// 停止监控
 	Stop() error
+	// 暂停监控并保存检查点，进程重启后保持暂停
+	Pause() error
+	// 恢复监控，从检查点继续处理
+	Resume(ctx context.Context) error
 	// 获取监控状态
 	GetStatus() *model.BSCMonitoringResponse
+	// 按小时或天获取区块监控的处理量历史
+	GetStatsHistory(ctx context.Context, granularity string, from, to time.Time) (*model.BSCStatsHistoryResponse, error)
 	// 获取最新区块信息
 	GetLatestBlock(ctx context.Context) (*model.BSCBlock, error)
+	// 获取最新区块高度，只发起一次RPC调用
+	BlockNumber(ctx context.Context) (uint64, error)
 	// 获取交易信息
 	GetTransactions(ctx context.Context, blockNumber *big.Int, page, pageSize int) (*model.BSCTransactionResponse, error)
+	// 获取地址的交易历史
+	GetAddressTransactions(ctx context.Context, address common.Address, page, pageSize int) (*model.BSCTransactionResponse, error)
 	// 获取代币转账记录
 	GetTokenTransfers(ctx context.Context, tokenAddress common.Address, page, pageSize int) (*model.BSCTokenTransferResponse, error)
 	// 获取交换事件
@@ -42,6 +59,18 @@ type BSCService interface {
 	GetTokenPriceFromLiquidity(ctx context.Context, tokenAddress common.Address) (decimal.Decimal, error)
 	// 获取代币对USDT的价格
 	GetTokenPriceInUSDT(ctx context.Context, tokenSymbol string) (decimal.Decimal, error)
+	// 获取代币对USDT的价格及计算价格使用的区块，该区块之后至少有confirmations个区块
+	GetTokenPriceAtDepth(ctx context.Context, tokenSymbol string, confirmations uint64) (decimal.Decimal, *model.PriceBlock, error)
+	// 检查地址是否为合约
+	IsContract(ctx context.Context, address common.Address) (bool, error)
+	// 调用合约只读方法，返回ABI编码的结果
+	CallContract(ctx context.Context, contract common.Address, data []byte) ([]byte, error)
+	// 按条件查询事件日志
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+	// 按最近区块的利用率、Gas价格和待处理交易积压计算网络拥堵情况
+	GetCongestion(ctx context.Context) (*model.BSCCongestion, error)
+	// 统计最近epochs个epoch的出块验证者分布，epochs不大于0时使用配置的默认值
+	GetValidatorStats(ctx context.Context, epochs int) (*model.BSCValidatorsResponse, error)
 }
 
 // bscService BSC服务实现
@@ -56,46 +85,82 @@ type bscService struct {
 	running     bool
 	runMutex    sync.RWMutex
 	cancel      context.CancelFunc
+	done        chan struct{} // 区块监控退出时关闭
+
+	checkpoint      bscCheckpoint
+	checkpointMutex sync.Mutex
+
+	congestion      *model.BSCCongestion // 最近一次计算的网络拥堵情况
+	congestionMutex sync.Mutex
+
+	validators      map[int]*model.BSCValidatorsResponse // 按epoch数缓存的出块验证者统计
+	validatorsMutex sync.Mutex
+
+	negativeCache *negativeCache
+	tokenService  TokenService
+	notifier      Notifier        // 发送区块监控滞后告警
+	bscscan       *bscscan.Client // 本地索引未就绪时的回退数据源，未启用时为nil
+	units         *unitConverter  // 按代币精度换算链上数量
 }
 
-// NewBSCService 创建BSC服务
-func NewBSCService(cfg *config.Config, redisClient database.RedisClient) (BSCService, error) {
+// NewBSCService 创建BSC服务，tokenService用于解析内置映射之外的代币符号，可为nil；notifier用于发送区块监控滞后告警
+func NewBSCService(cfg *config.Config, redisClient database.RedisClient, tokenService TokenService, notifier Notifier) (BSCService, error) {
+	var fallback *bscscan.Client
+	if cfg.BSC.Fallback.Enabled {
+		fallback = bscscan.NewClient(&cfg.ExternalAPI.BscScan)
+	}
+
 	if !cfg.BSC.Enabled {
-		return &bscService{
-			config: &cfg.BSC,
-			logger: logger.GetLogger(),
+		s := &bscService{
+			config:       &cfg.BSC,
+			logger:       logger.GetLogger(),
+			tokenService: tokenService,
+			bscscan:      fallback,
 			stats: &model.BSCMonitoringStats{
 				Status: "disabled",
 			},
-		}, nil
+		}
+		s.units = newUnitConverter(tokenService, s)
+		return s, nil
 	}
 
-	// 连接BSC节点
-	client, err := ethclient.Dial(cfg.BSC.RPCURL)
+	// 连接BSC节点，请求经过配置的代理，HTTP请求计入调用预算
+	httpClient := &http.Client{Transport: httpclient.NewTransport(httpclient.Options{Provider: budget.ProviderBSCRPC, Proxy: cfg.BSC.Proxy})}
+	wsDialer := rpc.WithWebsocketDialer(httpclient.WebsocketDialer(httpclient.Options{Proxy: cfg.BSC.Proxy}))
+	rpcClient, err := rpc.DialOptions(context.Background(), cfg.BSC.RPCURL, rpc.WithHTTPClient(httpClient), wsDialer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to BSC RPC: %w", err)
 	}
+	client := ethclient.NewClient(rpcClient)
 
 	// 连接WebSocket（可选）
 	var wsClient *ethclient.Client
 	if cfg.BSC.WebSocketURL != "" {
-		wsClient, err = ethclient.Dial(cfg.BSC.WebSocketURL)
+		wsRPCClient, err := rpc.DialOptions(context.Background(), cfg.BSC.WebSocketURL, wsDialer)
 		if err != nil {
 			logger.GetLogger().Warnf("Failed to connect to BSC WebSocket: %v", err)
+		} else {
+			wsClient = ethclient.NewClient(wsRPCClient)
 		}
 	}
 
-	return &bscService{
-		client:      client,
-		wsClient:    wsClient,
-		config:      &cfg.BSC,
-		redisClient: redisClient,
-		logger:      logger.GetLogger(),
+	s := &bscService{
+		client:        client,
+		wsClient:      wsClient,
+		config:        &cfg.BSC,
+		redisClient:   redisClient,
+		logger:        logger.GetLogger(),
+		negativeCache: newNegativeCache(redisClient, cfg.Cache.NegativeTTL),
+		tokenService:  tokenService,
+		notifier:      notifier,
+		bscscan:       fallback,
 		stats: &model.BSCMonitoringStats{
 			StartTime: time.Now(),
 			Status:    "initialized",
 		},
-	}, nil
+	}
+	s.units = newUnitConverter(tokenService, s)
+	return s, nil
 }
 
 // Start 启动BSC监控
@@ -111,44 +176,74 @@ func (s *bscService) Start(ctx context.Context) error {
 		return fmt.Errorf("BSC monitoring is already running")
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
+	// 通过API暂停后不随进程启动自动恢复，Redis不可用时使用进程内的检查点
+	checkpoint, err := s.loadCheckpoint(ctx)
+	if err != nil {
+		s.logger.Warnf("Failed to load BSC monitoring checkpoint, using in-memory checkpoint: %v", err)
+	}
+	if checkpoint.Paused {
+		s.logger.Info("BSC monitoring is paused, resume it via /api/v1/bsc/monitoring/start")
+		s.updateStats(func(stats *model.BSCMonitoringStats) {
+			stats.Status = "paused"
+			stats.Checkpoint = checkpoint.Block
+		})
+		return nil
+	}
+
+	// 监控的生命周期独立于发起请求的上下文，仅通过Stop取消
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
 	s.cancel = cancel
+	s.done = make(chan struct{})
 	s.running = true
 
 	s.updateStats(func(stats *model.BSCMonitoringStats) {
 		stats.Status = "running"
 		stats.StartTime = time.Now()
+		stats.Checkpoint = checkpoint.Block
+		stats.ResumedFrom = checkpoint.Block
+		stats.StalledIntervals = 0
 	})
 
-	s.logger.Info("Starting BSC monitoring service")
+	if checkpoint.Block > 0 {
+		s.logger.Infof("Starting BSC monitoring service, resuming after block %d", checkpoint.Block)
+	} else {
+		s.logger.Info("Starting BSC monitoring service")
+	}
 
 	// 启动区块监控
-	go s.monitorBlocks(ctx)
+	panics.Supervise(ctx, "bsc_blocks", s.monitorBlocks, s.done)
 
 	// 如果有WebSocket连接，启动实时事件监控
 	if s.wsClient != nil {
-		go s.monitorEvents(ctx)
+		panics.Supervise(ctx, "bsc_events", s.monitorEvents, nil)
 	}
 
 	return nil
 }
 
-// Stop 停止BSC监控
+// Stop 停止BSC监控，未运行时直接返回
 func (s *bscService) Stop() error {
 	s.runMutex.Lock()
 	defer s.runMutex.Unlock()
 
 	if !s.running {
-		return fmt.Errorf("BSC monitoring is not running")
+		return nil
 	}
 
+	// 等待当前批次处理完，保证检查点已保存
 	if s.cancel != nil {
 		s.cancel()
 	}
+	if s.done != nil {
+		<-s.done
+	}
 
+	// 主动停止不视为滞后
 	s.running = false
 	s.updateStats(func(stats *model.BSCMonitoringStats) {
 		stats.Status = "stopped"
+		stats.Lagging = false
+		stats.LagReason = ""
 	})
 
 	s.logger.Info("BSC monitoring service stopped")
@@ -167,6 +262,14 @@ func (s *bscService) GetStatus() *model.BSCMonitoringResponse {
 	}
 }
 
+// BlockNumber 获取最新区块高度
+func (s *bscService) BlockNumber(ctx context.Context) (uint64, error) {
+	if s.client == nil {
+		return 0, fmt.Errorf("BSC client not initialized")
+	}
+	return s.client.BlockNumber(ctx)
+}
+
 // GetLatestBlock 获取最新区块信息
 func (s *bscService) GetLatestBlock(ctx context.Context) (*model.BSCBlock, error) {
 	if s.client == nil {
@@ -218,6 +321,7 @@ func (s *bscService) GetTransactions(ctx context.Context, blockNumber *big.Int,
 			Total:        total,
 			Page:         page,
 			PageSize:     pageSize,
+			Source:       model.DataSourceLocal,
 		}, nil
 	}
 	if end > total {
@@ -226,6 +330,10 @@ func (s *bscService) GetTransactions(ctx context.Context, blockNumber *big.Int,
 
 	var transactions []model.BSCTransaction
 	for i := start; i < end; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		tx := txs[i]
 		receipt, err := s.client.TransactionReceipt(ctx, tx.Hash())
 		if err != nil {
@@ -237,7 +345,8 @@ func (s *bscService) GetTransactions(ctx context.Context, blockNumber *big.Int,
 			BlockNumber: block.Number(),
 			From:        s.getTransactionSender(tx),
 			To:          tx.To(),
-			Value:       tx.Value(),
+			ValueRaw:    tx.Value(),
+			Value:       formatUnits(tx.Value(), nativeDecimals),
 			GasPrice:    tx.GasPrice(),
 			GasUsed:     receipt.GasUsed,
 			Status:      receipt.Status,
@@ -250,6 +359,24 @@ func (s *bscService) GetTransactions(ctx context.Context, blockNumber *big.Int,
 		Total:        total,
 		Page:         page,
 		PageSize:     pageSize,
+		Source:       model.DataSourceLocal,
+	}, nil
+}
+
+// GetAddressTransactions 获取地址的交易历史，本地索引尚未回填时回退到BscScan
+func (s *bscService) GetAddressTransactions(ctx context.Context, address common.Address, page, pageSize int) (*model.BSCTransactionResponse, error) {
+	// 本地索引暂未记录地址维度的交易，视为未回填
+	if s.bscscan != nil {
+		s.logger.Debugf("Local index has no transactions for %s, falling back to BscScan", address.Hex())
+		return s.fallbackAddressTransactions(ctx, address, page, pageSize)
+	}
+
+	return &model.BSCTransactionResponse{
+		Transactions: []model.BSCTransaction{},
+		Total:        0,
+		Page:         page,
+		PageSize:     pageSize,
+		Source:       model.DataSourceLocal,
 	}, nil
 }
 
@@ -257,41 +384,73 @@ func (s *bscService) GetTransactions(ctx context.Context, blockNumber *big.Int,
 func (s *bscService) GetTokenTransfers(ctx context.Context, tokenAddress common.Address, page, pageSize int) (*model.BSCTokenTransferResponse, error) {
 	// 这里应该从缓存或数据库中获取代币转账记录
 	// 为了演示，返回空结果
-	return &model.BSCTokenTransferResponse{
+	local := &model.BSCTokenTransferResponse{
 		Transfers: []model.BSCTokenTransfer{},
 		Total:     0,
 		Page:      page,
 		PageSize:  pageSize,
-	}, nil
+		Source:    model.DataSourceLocal,
+	}
+
+	// 本地索引尚未回填时回退到BscScan
+	if local.Total == 0 && s.bscscan != nil {
+		s.logger.Debugf("Local index has no transfers for %s, falling back to BscScan", tokenAddress.Hex())
+		return s.fallbackTokenTransfers(ctx, tokenAddress, page, pageSize)
+	}
+
+	return local, nil
 }
 
 // GetSwapEvents 获取交换事件
 func (s *bscService) GetSwapEvents(ctx context.Context, pairAddress common.Address, page, pageSize int) (*model.BSCSwapEventResponse, error) {
 	// 这里应该从缓存或数据库中获取交换事件
 	// 为了演示，返回空结果
-	return &model.BSCSwapEventResponse{
+	resp := &model.BSCSwapEventResponse{
 		Swaps:    []model.BSCSwapEvent{},
 		Total:    0,
 		Page:     page,
 		PageSize: pageSize,
-	}, nil
+	}
+	// 没有事件时不必查询交易对代币
+	if len(resp.Swaps) > 0 {
+		s.formatSwapAmounts(ctx, pairAddress, resp.Swaps)
+	}
+	return resp, nil
+}
+
+// formatSwapAmounts 按交易对两个代币的精度换算交换数量
+func (s *bscService) formatSwapAmounts(ctx context.Context, pairAddress common.Address, swaps []model.BSCSwapEvent) {
+	pair, err := s.GetPairInfo(ctx, pairAddress)
+	if err != nil {
+		s.logger.Debugf("Failed to get pair %s for swap amounts: %v", pairAddress.Hex(), err)
+		return
+	}
+	for i := range swaps {
+		swaps[i].Amount0In, _ = s.units.format(ctx, pair.Token0, swaps[i].Amount0InRaw)
+		swaps[i].Amount0Out, _ = s.units.format(ctx, pair.Token0, swaps[i].Amount0OutRaw)
+		swaps[i].Amount1In, _ = s.units.format(ctx, pair.Token1, swaps[i].Amount1InRaw)
+		swaps[i].Amount1Out, _ = s.units.format(ctx, pair.Token1, swaps[i].Amount1OutRaw)
+	}
 }
 
 // GetPairInfo 获取交易对信息
 func (s *bscService) GetPairInfo(ctx context.Context, pairAddress common.Address) (*model.BSCPairInfo, error) {
 	// 这里应该调用PancakeSwap合约获取交易对信息
 	// 为了演示，返回模拟数据
-	return &model.BSCPairInfo{
+	pair := &model.BSCPairInfo{
 		Address:     pairAddress,
 		Token0:      common.HexToAddress(s.config.Contracts.WBNB),
 		Token1:      common.HexToAddress(s.config.Contracts.USDT),
-		Reserve0:    big.NewInt(1000000),
-		Reserve1:    big.NewInt(300000000),
+		Reserve0Raw: big.NewInt(1000000),
+		Reserve1Raw: big.NewInt(300000000),
 		TotalSupply: big.NewInt(17320508),
 		Price0:      decimal.NewFromFloat(300.0),
 		Price1:      decimal.NewFromFloat(0.00333),
 		UpdatedAt:   time.Now(),
-	}, nil
+	}
+	pair.Reserve0, _ = s.units.format(ctx, pair.Token0, pair.Reserve0Raw)
+	pair.Reserve1, _ = s.units.format(ctx, pair.Token1, pair.Reserve1Raw)
+	return pair, nil
 }
 
 // monitorBlocks 监控区块
@@ -304,9 +463,16 @@ func (s *bscService) monitorBlocks(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			if err := s.processLatestBlocks(ctx); err != nil {
+			// 单轮处理不超过一个监控周期，避免RPC阻塞导致任务堆积
+			tickCtx, cancel := context.WithTimeout(ctx, s.config.Monitoring.Interval)
+			checkpoint := s.currentCheckpoint()
+			if err := s.processLatestBlocks(tickCtx); err != nil && ctx.Err() == nil {
 				s.logger.Errorf("Failed to process latest blocks: %v", err)
 			}
+			cancel()
+			if ctx.Err() == nil {
+				s.checkLag(ctx, s.currentCheckpoint() != checkpoint)
+			}
 		}
 	}
 }
@@ -315,25 +481,95 @@ func (s *bscService) monitorBlocks(ctx context.Context) {
 func (s *bscService) monitorEvents(ctx context.Context) {
 	// 实现WebSocket事件监控
 	s.logger.Info("Starting real-time event monitoring")
-	
+
 	// 这里可以实现具体的事件监控逻辑
 	// 例如监控Transfer、Swap等事件
 }
 
-// processLatestBlocks 处理最新区块
+// processLatestBlocks 从检查点的下一个区块处理到最新区块，每轮最多batch_size个，处理后保存检查点并累加处理量
 func (s *bscService) processLatestBlocks(ctx context.Context) error {
-	header, err := s.client.HeaderByNumber(ctx, nil)
+	latest, err := s.client.BlockNumber(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get latest block header: %w", err)
+		return fmt.Errorf("failed to get latest block number: %w", err)
+	}
+
+	s.checkpointMutex.Lock()
+	saved := s.checkpoint.Block
+	s.checkpointMutex.Unlock()
+
+	// 没有检查点时从最新区块开始
+	last := saved
+	if last == 0 && latest > 0 {
+		last = latest - 1
+	}
+	if last > latest {
+		last = latest
+	}
+
+	// 停机时间超出max_catchup时跳过更早的区块，并记录跳过的数量
+	var skipped uint64
+	if maxCatchup := uint64(s.config.Monitoring.MaxCatchup); s.config.Monitoring.MaxCatchup > 0 && latest-last > maxCatchup {
+		skipped = latest - last - maxCatchup
+		s.logger.Warnf("BSC monitoring is %d blocks behind, skipping blocks %d-%d (max_catchup %d)", latest-last, last+1, last+skipped, maxCatchup)
+		last += skipped
+	}
+
+	end := latest
+	if batchSize := uint64(s.config.Monitoring.BatchSize); s.config.Monitoring.BatchSize > 0 && end-last > batchSize {
+		end = last + batchSize
+	}
+
+	var (
+		processErr error
+		counts     model.BSCStatsCounts
+	)
+	processed := last
+	for number := last + 1; number <= end; number++ {
+		txs, err := s.blockTxCount(ctx, number)
+		if err != nil {
+			processErr = fmt.Errorf("failed to get block %d: %w", number, err)
+			break
+		}
+		processed = number
+		counts.Blocks++
+		counts.Transactions += uint64(txs)
+	}
+	if processed > last {
+		events, err := s.countEvents(ctx, last+1, processed)
+		if err != nil {
+			s.logger.Warnf("Failed to count events in blocks %d-%d: %v", last+1, processed, err)
+		}
+		counts.Transfers, counts.Swaps, counts.Liquidity = events.Transfers, events.Swaps, events.Liquidity
+	}
+
+	// 请求取消后仍保存已处理的进度
+	if processed != saved {
+		if err := s.updateCheckpoint(context.WithoutCancel(ctx), func(cp *bscCheckpoint) {
+			cp.Block = processed
+		}); err != nil {
+			s.logger.Warnf("Failed to save BSC monitoring checkpoint at block %d: %v", processed, err)
+		}
+	}
+	if counts.Blocks > 0 {
+		if err := s.recordStats(context.WithoutCancel(ctx), time.Now(), counts); err != nil {
+			s.logger.Warnf("Failed to record BSC monitoring stats: %v", err)
+		}
 	}
 
 	s.updateStats(func(stats *model.BSCMonitoringStats) {
-		stats.LatestBlock = header.Number
-		stats.ProcessedBlocks++
+		stats.LatestBlock = new(big.Int).SetUint64(latest)
+		stats.ProcessedBlocks += processed - last
+		stats.TotalTransactions += counts.Transactions
+		stats.TotalTransfers += counts.Transfers
+		stats.TotalSwaps += counts.Swaps
+		stats.TotalLiquidity += counts.Liquidity
+		stats.SkippedBlocks += skipped
+		stats.Checkpoint = processed
+		stats.LagBlocks = latest - processed
 		stats.LastUpdateTime = time.Now()
 	})
 
-	return nil
+	return processErr
 }
 
 // updateStats 更新统计信息
@@ -402,30 +638,34 @@ func (s *bscService) GetTokenPriceFromLiquidity(ctx context.Context, tokenAddres
 	if s.client == nil {
 		return decimal.Zero, fmt.Errorf("BSC client not initialized")
 	}
+	return s.liquidityPrice(ctx, tokenAddress, nil)
+}
 
+// liquidityPrice 按指定区块的流动性池储备量计算代币价格，block为空时使用最新区块
+func (s *bscService) liquidityPrice(ctx context.Context, tokenAddress common.Address, block *big.Int) (decimal.Decimal, error) {
 	// 查找代币对应的流动性池
 	// 这里假设使用PancakeSwap V2的工厂合约来查找交易对
 	// 实际实现中需要调用PancakeSwap Factory合约的getPair方法
-	
+
 	// 为演示目的，返回模拟价格计算
 	// 实际应该通过以下步骤：
 	// 1. 调用PancakeSwap Factory合约获取token/USDT交易对地址
-	// 2. 调用交易对合约获取储备量(reserves)
+	// 2. 调用交易对合约获取block处的储备量(reserves)
 	// 3. 根据储备量计算价格: price = reserve_usdt / reserve_token
-	
+
 	// 模拟价格数据
 	prices := map[string]float64{
-		"0x55d398326f99059fF775485246999027B3197955": 1.0,    // USDT
-		"0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c": 300.0,  // WBNB
-		"0x2170Ed0880ac9A755fd29B2688956BD959F933F8": 3000.0, // ETH
+		"0x55d398326f99059fF775485246999027B3197955": 1.0,     // USDT
+		"0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c": 300.0,   // WBNB
+		"0x2170Ed0880ac9A755fd29B2688956BD959F933F8": 3000.0,  // ETH
 		"0x7130d2A12B9BCbFAe4f2634d864A1Ee1Ce3Ead9c": 45000.0, // BTCB
 	}
-	
+
 	tokenAddressStr := tokenAddress.Hex()
 	if price, exists := prices[tokenAddressStr]; exists {
 		return decimal.NewFromFloat(price), nil
 	}
-	
+
 	// 默认返回1.0作为未知代币的价格
 	return decimal.NewFromFloat(1.0), nil
 }
@@ -436,6 +676,46 @@ func (s *bscService) GetTokenPriceInUSDT(ctx context.Context, tokenSymbol string
 		return decimal.Zero, fmt.Errorf("BSC client not initialized")
 	}
 
+	tokenAddress, err := s.resolveTokenAddress(ctx, tokenSymbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return s.liquidityPrice(ctx, tokenAddress, nil)
+}
+
+// GetTokenPriceAtDepth 获取代币对USDT的价格，使用最新区块之前confirmations个区块处的储备量计算
+func (s *bscService) GetTokenPriceAtDepth(ctx context.Context, tokenSymbol string, confirmations uint64) (decimal.Decimal, *model.PriceBlock, error) {
+	if s.client == nil {
+		return decimal.Zero, nil, fmt.Errorf("BSC client not initialized")
+	}
+
+	tokenAddress, err := s.resolveTokenAddress(ctx, tokenSymbol)
+	if err != nil {
+		return decimal.Zero, nil, err
+	}
+
+	latest, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return decimal.Zero, nil, fmt.Errorf("failed to get latest block number: %w", err)
+	}
+	if confirmations > latest {
+		return decimal.Zero, nil, fmt.Errorf("%w: confirmations %d exceeds chain height %d", ErrInvalidParameter, confirmations, latest)
+	}
+
+	block := &model.PriceBlock{
+		Number:        latest - confirmations,
+		LatestBlock:   latest,
+		Confirmations: confirmations,
+	}
+	price, err := s.liquidityPrice(ctx, tokenAddress, new(big.Int).SetUint64(block.Number))
+	if err != nil {
+		return decimal.Zero, nil, err
+	}
+	return price, block, nil
+}
+
+// resolveTokenAddress 解析代币符号对应的合约地址，内置映射之外的代币从代币注册表查找，不支持的代币写入负缓存
+func (s *bscService) resolveTokenAddress(ctx context.Context, tokenSymbol string) (common.Address, error) {
 	// 代币符号到合约地址的映射
 	tokenAddresses := map[string]string{
 		"USDT": "0x55d398326f99059fF775485246999027B3197955",
@@ -449,11 +729,90 @@ func (s *bscService) GetTokenPriceInUSDT(ctx context.Context, tokenSymbol string
 		"BCH":  "0x8fF795a6F4D97E7887C79beA79aba5cc76444aDf",
 	}
 
-	addressStr, exists := tokenAddresses[tokenSymbol]
+	if addressStr, exists := tokenAddresses[tokenSymbol]; exists {
+		return common.HexToAddress(addressStr), nil
+	}
+
+	// 内置映射之外的代币才查负缓存和代币注册表
+	negativeKey := "bsc:token:" + tokenSymbol
+	if err := s.negativeCache.get(ctx, negativeKey); err != nil {
+		return common.Address{}, err
+	}
+
+	addressStr, exists := s.lookupTokenAddress(ctx, tokenSymbol)
 	if !exists {
-		return decimal.Zero, fmt.Errorf("unsupported token symbol: %s", tokenSymbol)
+		err := fmt.Errorf("%w: token %s", ErrUnsupportedSymbol, tokenSymbol)
+		if cacheErr := s.negativeCache.set(ctx, negativeKey, err); cacheErr != nil {
+			s.logger.Warnf("Failed to set negative cache for token %s: %v", tokenSymbol, cacheErr)
+		}
+		return common.Address{}, err
 	}
 
-	tokenAddress := common.HexToAddress(addressStr)
-	return s.GetTokenPriceFromLiquidity(ctx, tokenAddress)
-}
\ No newline at end of file
+	return common.HexToAddress(addressStr), nil
+}
+
+// lookupTokenAddress 从代币注册表中查找已在链上确认的代币地址，来源最多的优先
+func (s *bscService) lookupTokenAddress(ctx context.Context, symbol string) (string, bool) {
+	if s.tokenService == nil {
+		return "", false
+	}
+
+	tokens, err := s.tokenService.ListTokens(ctx, symbol)
+	if err != nil {
+		s.logger.Warnf("Failed to look up token %s in registry: %v", symbol, err)
+		return "", false
+	}
+
+	var best *model.TokenMetadata
+	for i := range tokens {
+		if !tokens[i].OnChain {
+			continue
+		}
+		if best == nil || len(tokens[i].Sources) > len(best.Sources) {
+			best = &tokens[i]
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	return best.Address, true
+}
+
+// IsContract 检查地址上是否部署了合约代码
+func (s *bscService) IsContract(ctx context.Context, address common.Address) (bool, error) {
+	if s.client == nil {
+		return false, fmt.Errorf("BSC client not initialized")
+	}
+
+	code, err := s.client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get code at %s: %w", address.Hex(), err)
+	}
+	return len(code) > 0, nil
+}
+
+// CallContract 在最新区块上调用合约只读方法
+func (s *bscService) CallContract(ctx context.Context, contract common.Address, data []byte) ([]byte, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("BSC client not initialized")
+	}
+
+	result, err := s.client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call contract %s: %w", contract.Hex(), err)
+	}
+	return result, nil
+}
+
+// FilterLogs 按条件查询事件日志
+func (s *bscService) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("BSC client not initialized")
+	}
+
+	logs, err := s.client.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs: %w", err)
+	}
+	return logs, nil
+}
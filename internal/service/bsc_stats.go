@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"crypto-info/internal/model"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/redis/go-redis/v9"
+)
+
+// 区块监控处理量汇总配置
+const (
+	bscStatsHourKeyPrefix = "bsc:stats:h:"
+	bscStatsDayKeyPrefix  = "bsc:stats:d:"
+	bscStatsHourRetention = 7 * 24 * time.Hour
+	bscStatsDayRetention  = 366 * 24 * time.Hour
+)
+
+// 处理量汇总的时间粒度
+const (
+	BSCStatsGranularityHour = "hour"
+	BSCStatsGranularityDay  = "day"
+)
+
+// 处理量汇总的哈希字段
+const (
+	bscStatsFieldBlocks       = "blocks"
+	bscStatsFieldTransactions = "transactions"
+	bscStatsFieldTransfers    = "transfers"
+	bscStatsFieldSwaps        = "swaps"
+	bscStatsFieldLiquidity    = "liquidity"
+)
+
+var (
+	// pairSwapTopic 交易对Swap事件签名
+	pairSwapTopic = crypto.Keccak256Hash([]byte("Swap(address,uint256,uint256,uint256,uint256,address)"))
+	// pairMintTopic 交易对Mint事件签名
+	pairMintTopic = crypto.Keccak256Hash([]byte("Mint(address,uint256,uint256)"))
+)
+
+// blockTxCount 获取区块的交易数，只请求交易哈希而不下载完整交易
+func (s *bscService) blockTxCount(ctx context.Context, number uint64) (int, error) {
+	var block *struct {
+		Transactions []common.Hash `json:"transactions"`
+	}
+	if err := s.client.Client().CallContext(ctx, &block, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false); err != nil {
+		return 0, err
+	}
+	if block == nil {
+		return 0, ethereum.NotFound
+	}
+	return len(block.Transactions), nil
+}
+
+// countEvents 统计区块范围内的代币转账、兑换和流动性事件数，只统计 bsc.events 中启用的类型
+func (s *bscService) countEvents(ctx context.Context, from, to uint64) (model.BSCStatsCounts, error) {
+	var counts model.BSCStatsCounts
+
+	var topics []common.Hash
+	if s.config.Events.Transfer {
+		topics = append(topics, tokenTransferTopic)
+	}
+	if s.config.Events.Swap {
+		topics = append(topics, pairSwapTopic)
+	}
+	if s.config.Events.Liquidity {
+		topics = append(topics, pairMintTopic, pairBurnTopic)
+	}
+	if len(topics) == 0 || from > to {
+		return counts, nil
+	}
+
+	logs, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Topics:    [][]common.Hash{topics},
+	})
+	if err != nil {
+		return counts, err
+	}
+	for _, entry := range logs {
+		if len(entry.Topics) == 0 {
+			continue
+		}
+		switch entry.Topics[0] {
+		case tokenTransferTopic:
+			counts.Transfers++
+		case pairSwapTopic:
+			counts.Swaps++
+		case pairMintTopic, pairBurnTopic:
+			counts.Liquidity++
+		}
+	}
+	return counts, nil
+}
+
+// recordStats 在同一事务中将处理量累加到当前的小时桶和天桶，多个实例写入同一个桶
+func (s *bscService) recordStats(ctx context.Context, now time.Time, counts model.BSCStatsCounts) error {
+	if s.redisClient == nil {
+		return nil
+	}
+
+	hourKey := s.redisClient.KeyPrefix() + bscStatsHourKey(now)
+	dayKey := s.redisClient.KeyPrefix() + bscStatsDayKey(now)
+	fields := map[string]uint64{
+		bscStatsFieldBlocks:       counts.Blocks,
+		bscStatsFieldTransactions: counts.Transactions,
+		bscStatsFieldTransfers:    counts.Transfers,
+		bscStatsFieldSwaps:        counts.Swaps,
+		bscStatsFieldLiquidity:    counts.Liquidity,
+	}
+
+	pipe := s.redisClient.GetClient().TxPipeline()
+	for field, n := range fields {
+		if n == 0 {
+			continue
+		}
+		pipe.HIncrBy(ctx, hourKey, field, int64(n))
+		pipe.HIncrBy(ctx, dayKey, field, int64(n))
+	}
+	pipe.Expire(ctx, hourKey, bscStatsHourRetention)
+	pipe.Expire(ctx, dayKey, bscStatsDayRetention)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetStatsHistory 按小时或天(UTC)返回时间范围内的处理量，没有数据的桶计为0
+func (s *bscService) GetStatsHistory(ctx context.Context, granularity string, from, to time.Time) (*model.BSCStatsHistoryResponse, error) {
+	var (
+		step      time.Duration
+		retention time.Duration
+		bucketKey func(time.Time) string
+	)
+	switch granularity {
+	case "", BSCStatsGranularityHour:
+		granularity = BSCStatsGranularityHour
+		step, retention, bucketKey = time.Hour, bscStatsHourRetention, bscStatsHourKey
+	case BSCStatsGranularityDay:
+		step, retention, bucketKey = 24*time.Hour, bscStatsDayRetention, bscStatsDayKey
+	default:
+		return nil, fmt.Errorf("%w: unsupported granularity %q", ErrInvalidParameter, granularity)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidParameter)
+	}
+	if to.Sub(from) > retention {
+		return nil, fmt.Errorf("%w: %s stats are kept for %s", ErrInvalidParameter, granularity, retention)
+	}
+
+	resp := &model.BSCStatsHistoryResponse{
+		Granularity: granularity,
+		From:        from,
+		To:          to,
+		Buckets:     []model.BSCStatsBucket{},
+	}
+	for start := from.UTC().Truncate(step); start.Before(to); start = start.Add(step) {
+		resp.Buckets = append(resp.Buckets, model.BSCStatsBucket{Start: start})
+	}
+	if s.redisClient == nil {
+		return resp, nil
+	}
+
+	pipe := s.redisClient.GetClient().Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(resp.Buckets))
+	for i, bucket := range resp.Buckets {
+		cmds[i] = pipe.HGetAll(ctx, s.redisClient.KeyPrefix()+bucketKey(bucket.Start))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to load BSC stats history: %w", err)
+	}
+
+	for i, cmd := range cmds {
+		counts := parseBSCStatsCounts(cmd.Val())
+		resp.Buckets[i].BSCStatsCounts = counts
+		resp.Total.Blocks += counts.Blocks
+		resp.Total.Transactions += counts.Transactions
+		resp.Total.Transfers += counts.Transfers
+		resp.Total.Swaps += counts.Swaps
+		resp.Total.Liquidity += counts.Liquidity
+	}
+	return resp, nil
+}
+
+// parseBSCStatsCounts 解析桶中的计数，缺失或无效的字段计为0
+func parseBSCStatsCounts(fields map[string]string) model.BSCStatsCounts {
+	value := func(field string) uint64 {
+		n, _ := strconv.ParseUint(fields[field], 10, 64)
+		return n
+	}
+	return model.BSCStatsCounts{
+		Blocks:       value(bscStatsFieldBlocks),
+		Transactions: value(bscStatsFieldTransactions),
+		Transfers:    value(bscStatsFieldTransfers),
+		Swaps:        value(bscStatsFieldSwaps),
+		Liquidity:    value(bscStatsFieldLiquidity),
+	}
+}
+
+// bscStatsHourKey 小时桶的key
+func bscStatsHourKey(t time.Time) string {
+	return bscStatsHourKeyPrefix + strconv.FormatInt(t.Unix()/3600, 10)
+}
+
+// bscStatsDayKey 天桶(UTC)的key
+func bscStatsDayKey(t time.Time) string {
+	return bscStatsDayKeyPrefix + strconv.FormatInt(t.Unix()/86400, 10)
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"crypto-info/internal/model"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 出块验证者统计的默认配置
+const (
+	defaultValidatorEpochLength  = 1000
+	defaultValidatorEpochs       = 1
+	defaultValidatorMaxEpochs    = 5
+	defaultValidatorRecentBlocks = 20
+	defaultValidatorCacheTTL     = 30 * time.Second
+)
+
+// validatorBatchSize 单次批量请求的区块头数量
+const validatorBatchSize = 100
+
+// outOfTurnDifficulty Parlia共识中非轮值验证者出块的difficulty，轮值验证者为2
+const outOfTurnDifficulty = 1
+
+// validatorHeader 统计出块验证者需要的区块头字段
+type validatorHeader struct {
+	Number     hexutil.Uint64 `json:"number"`
+	Miner      common.Address `json:"miner"`
+	Difficulty *hexutil.Big   `json:"difficulty"`
+}
+
+// GetValidatorStats 统计包含最新区块的epoch及之前共epochs个epoch的出块验证者分布，结果按epochs在进程内缓存cache_ttl
+func (s *bscService) GetValidatorStats(ctx context.Context, epochs int) (*model.BSCValidatorsResponse, error) {
+	cfg := s.config.Validators
+	maxEpochs := cfg.MaxEpochs
+	if maxEpochs <= 0 {
+		maxEpochs = defaultValidatorMaxEpochs
+	}
+	if epochs <= 0 {
+		epochs = cfg.DefaultEpochs
+	}
+	if epochs <= 0 {
+		epochs = defaultValidatorEpochs
+	}
+	if epochs > maxEpochs {
+		return nil, fmt.Errorf("%w: epochs must be at most %d", ErrInvalidParameter, maxEpochs)
+	}
+
+	if s.client == nil {
+		return nil, fmt.Errorf("BSC client not initialized")
+	}
+
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultValidatorCacheTTL
+	}
+
+	// 计算期间持有锁，相同请求等待同一次计算
+	s.validatorsMutex.Lock()
+	defer s.validatorsMutex.Unlock()
+
+	if cached, ok := s.validators[epochs]; ok && time.Since(cached.UpdatedAt) < ttl {
+		return cached, nil
+	}
+
+	stats, err := s.computeValidatorStats(ctx, epochs)
+	if err != nil {
+		return nil, err
+	}
+	if s.validators == nil {
+		s.validators = make(map[int]*model.BSCValidatorsResponse)
+	}
+	s.validators[epochs] = stats
+	return stats, nil
+}
+
+// computeValidatorStats 批量获取范围内的区块头，按miner汇总出块数，并按活跃验证者平均分配估算漏块
+func (s *bscService) computeValidatorStats(ctx context.Context, epochs int) (*model.BSCValidatorsResponse, error) {
+	epochLength := s.config.Validators.EpochLength
+	if epochLength <= 0 {
+		epochLength = defaultValidatorEpochLength
+	}
+	recentBlocks := s.config.Validators.RecentBlocks
+	if recentBlocks <= 0 {
+		recentBlocks = defaultValidatorRecentBlocks
+	}
+
+	latest, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block number: %w", err)
+	}
+
+	// 从当前epoch的起点向前推epochs-1个epoch
+	span := uint64(epochs-1) * uint64(epochLength)
+	from := latest - latest%uint64(epochLength)
+	if from > span {
+		from -= span
+	} else {
+		from = 0
+	}
+
+	headers, failed, err := s.validatorHeaders(ctx, from, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.BSCValidatorsResponse{
+		FromBlock:    from,
+		ToBlock:      latest,
+		Epochs:       epochs,
+		EpochLength:  epochLength,
+		TotalBlocks:  len(headers),
+		FailedBlocks: failed,
+		Validators:   []model.BSCValidatorStats{},
+		UpdatedAt:    time.Now(),
+	}
+
+	byMiner := make(map[common.Address]*model.BSCValidatorStats)
+	for _, header := range headers {
+		number := uint64(header.Number)
+		stats, ok := byMiner[header.Miner]
+		if !ok {
+			stats = &model.BSCValidatorStats{Address: header.Miner, FirstBlock: number}
+			byMiner[header.Miner] = stats
+		}
+		stats.Blocks++
+		stats.LastBlock = number
+		if header.Difficulty != nil && header.Difficulty.ToInt().Int64() == outOfTurnDifficulty {
+			stats.OutOfTurnBlocks++
+			resp.OutOfTurnBlocks++
+		}
+		// 区块按升序遍历，只保留最近的recent_blocks个
+		stats.RecentBlocks = append(stats.RecentBlocks, number)
+		if len(stats.RecentBlocks) > recentBlocks {
+			stats.RecentBlocks = stats.RecentBlocks[1:]
+		}
+	}
+
+	resp.ActiveValidators = len(byMiner)
+	if resp.ActiveValidators == 0 {
+		return resp, nil
+	}
+
+	expected := float64(resp.TotalBlocks) / float64(resp.ActiveValidators)
+	for _, stats := range byMiner {
+		stats.Share = math.Round(float64(stats.Blocks)/float64(resp.TotalBlocks)*10000) / 10000
+		stats.ExpectedBlocks = math.Round(expected*100) / 100
+		stats.MissedEstimate = max(int(math.Floor(expected))-stats.Blocks, 0)
+		sort.Slice(stats.RecentBlocks, func(i, j int) bool { return stats.RecentBlocks[i] > stats.RecentBlocks[j] })
+		resp.Validators = append(resp.Validators, *stats)
+	}
+	sort.Slice(resp.Validators, func(i, j int) bool {
+		a, b := resp.Validators[i], resp.Validators[j]
+		if a.Blocks != b.Blocks {
+			return a.Blocks > b.Blocks
+		}
+		return a.Address.Hex() < b.Address.Hex()
+	})
+	return resp, nil
+}
+
+// validatorHeaders 分批获取[from, to]的区块头，按区块号升序返回成功获取的区块头和失败的区块数，整批请求失败时返回错误
+func (s *bscService) validatorHeaders(ctx context.Context, from, to uint64) ([]validatorHeader, int, error) {
+	headers := make([]validatorHeader, 0, to-from+1)
+	failed := 0
+
+	for start := from; start <= to; start += validatorBatchSize {
+		end := min(start+validatorBatchSize-1, to)
+
+		results := make([]*validatorHeader, end-start+1)
+		batch := make([]rpc.BatchElem, len(results))
+		for i := range batch {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeUint64(start + uint64(i)), false},
+				Result: &results[i],
+			}
+		}
+		if err := s.client.Client().BatchCallContext(ctx, batch); err != nil {
+			return nil, 0, fmt.Errorf("failed to get block headers %d-%d: %w", start, end, err)
+		}
+
+		for i, elem := range batch {
+			if elem.Error != nil || results[i] == nil {
+				failed++
+				continue
+			}
+			headers = append(headers, *results[i])
+		}
+	}
+
+	if failed > 0 {
+		s.logger.Warnf("Failed to get %d of %d block headers for validator stats", failed, to-from+1)
+	}
+	return headers, failed, nil
+}
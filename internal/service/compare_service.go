@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// 多币种对比配置
+const (
+	maxCompareSymbols         = 20
+	compareKlineCacheTTL      = time.Minute
+	minCorrelationSamples     = 3 // 计算相关系数所需的最少共同收益率数
+	compareKlineCacheMaxItems = 1000
+)
+
+// compareMetrics 支持的对比指标，未指定时全部返回
+var compareMetrics = []string{
+	model.CompareMetricPrice,
+	model.CompareMetricVolume,
+	model.CompareMetricVolatility,
+	model.CompareMetricCorrelation,
+}
+
+// CompareService 多币种对比服务接口
+type CompareService interface {
+	Compare(ctx context.Context, symbols, metrics []string, days int, interval string) (*model.CompareResponse, error)
+}
+
+// compareService 多币种对比服务实现
+type compareService struct {
+	config         *config.Config
+	priceService   PriceService
+	volumeService  VolumeService
+	historyService HistoryService
+	klines         *klineCache
+}
+
+// symbolComparison 单个币种的对比结果
+type symbolComparison struct {
+	row     model.CompareRow
+	returns map[int64]float64 // K线开始时间(毫秒) -> 对数收益率
+	errors  []model.SymbolError
+}
+
+// NewCompareService 创建多币种对比服务
+func NewCompareService(cfg *config.Config, priceService PriceService, volumeService VolumeService, historyService HistoryService) CompareService {
+	return &compareService{
+		config:         cfg,
+		priceService:   priceService,
+		volumeService:  volumeService,
+		historyService: historyService,
+		klines:         newKlineCache(compareKlineCacheTTL),
+	}
+}
+
+// Compare 并发获取各币种的指标，波动率和相关性共用同一份K线
+func (s *compareService) Compare(ctx context.Context, symbols, metrics []string, days int, interval string) (*model.CompareResponse, error) {
+	symbols = normalizeList(symbols, strings.ToUpper)
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("%w: symbols is required", ErrInvalidParameter)
+	}
+	if len(symbols) > maxCompareSymbols {
+		return nil, fmt.Errorf("%w: at most %d symbols", ErrInvalidParameter, maxCompareSymbols)
+	}
+
+	metrics = normalizeList(metrics, strings.ToLower)
+	if len(metrics) == 0 {
+		metrics = compareMetrics
+	}
+	selected := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		if !containsMetric(metric) {
+			return nil, fmt.Errorf("%w: unsupported metric %q", ErrInvalidParameter, metric)
+		}
+		selected[metric] = true
+	}
+
+	if days <= 0 {
+		days = s.config.Business.DefaultAnalysisDays
+	}
+	if days > s.config.Business.MaxAnalysisDays {
+		days = s.config.Business.MaxAnalysisDays
+	}
+	if interval == "" {
+		interval = compareInterval(days)
+	}
+	if _, ok := historyIntervals[interval]; !ok {
+		return nil, fmt.Errorf("%w: unsupported interval %q", ErrInvalidParameter, interval)
+	}
+
+	to := time.Now()
+	from := to.Add(-time.Duration(days) * 24 * time.Hour)
+	needKlines := selected[model.CompareMetricVolatility] || selected[model.CompareMetricCorrelation]
+
+	results := fanOut(ctx, symbols, defaultFanOutConcurrency, func(ctx context.Context, symbol string) (*symbolComparison, error) {
+		result := &symbolComparison{row: model.CompareRow{Symbol: symbol}}
+		fail := func(metric string, err error) {
+			logger.From(ctx).Warnf("Failed to get %s for %s: %v", metric, symbol, err)
+			result.errors = append(result.errors, model.SymbolError{Symbol: symbol, Error: metric + ": " + err.Error()})
+		}
+
+		if selected[model.CompareMetricPrice] {
+			if price, err := s.priceService.GetPrice(ctx, symbol); err != nil {
+				fail(model.CompareMetricPrice, err)
+			} else {
+				result.row.Price = &price.Price
+				result.row.Precision = price.Precision
+			}
+		}
+		if selected[model.CompareMetricVolume] {
+			if analysis, err := s.volumeService.GetVolumeAnalysis(ctx, symbol, days); err != nil {
+				fail(model.CompareMetricVolume, err)
+			} else {
+				result.row.Volume = &analysis.TotalVolume
+			}
+		}
+		if needKlines {
+			key := fmt.Sprintf("%s|%s|%d", symbol, interval, days)
+			points, err := s.klines.get(key, func() ([]model.PricePoint, error) {
+				history, err := s.historyService.GetPriceHistory(ctx, symbol, interval, from, to)
+				if err != nil {
+					return nil, err
+				}
+				return history.Points, nil
+			})
+			if err != nil {
+				fail("klines", err)
+			} else {
+				result.returns = logReturns(points)
+				result.row.Samples = len(points)
+				if selected[model.CompareMetricVolatility] {
+					result.row.Volatility = volatility(result.returns)
+				}
+			}
+		}
+		return result, nil
+	})
+
+	resp := &model.CompareResponse{
+		Symbols:     symbols,
+		Metrics:     metrics,
+		Days:        days,
+		Interval:    interval,
+		Rows:        make([]model.CompareRow, 0, len(results)),
+		GeneratedAt: time.Now().Format(time.RFC3339),
+	}
+	returns := make([]map[int64]float64, 0, len(results))
+	for _, result := range results {
+		if result.Err != nil {
+			resp.Errors = append(resp.Errors, model.SymbolError{Symbol: result.Key, Error: result.Err.Error()})
+			resp.Rows = append(resp.Rows, model.CompareRow{Symbol: result.Key})
+			returns = append(returns, nil)
+			continue
+		}
+		resp.Rows = append(resp.Rows, result.Value.row)
+		resp.Errors = append(resp.Errors, result.Value.errors...)
+		returns = append(returns, result.Value.returns)
+	}
+
+	// 全部失败时才返回错误
+	if len(resp.Errors) > 0 && !hasCompareData(resp.Rows) {
+		return nil, fmt.Errorf("%w: failed to compare all %d symbols", ErrUpstreamUnavailable, len(symbols))
+	}
+
+	if selected[model.CompareMetricCorrelation] {
+		resp.Correlation = correlationMatrix(returns)
+	}
+	return resp, nil
+}
+
+// containsMetric 是否为支持的对比指标
+func containsMetric(metric string) bool {
+	for _, m := range compareMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// compareInterval 按统计天数选择K线间隔，控制在历史查询的点数上限内
+func compareInterval(days int) string {
+	switch {
+	case days <= 7:
+		return "1h"
+	case days <= 60:
+		return "4h"
+	default:
+		return "1d"
+	}
+}
+
+// hasCompareData 是否至少有一个币种取到了任一指标
+func hasCompareData(rows []model.CompareRow) bool {
+	for _, row := range rows {
+		if row.Price != nil || row.Volume != nil || row.Samples > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// logReturns 相邻K线收盘价的对数收益率，按后一根K线的开始时间索引
+func logReturns(points []model.PricePoint) map[int64]float64 {
+	returns := make(map[int64]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		prev, cur := points[i-1].Close, points[i].Close
+		if prev <= 0 || cur <= 0 {
+			continue
+		}
+		returns[points[i].Timestamp.UnixMilli()] = math.Log(cur / prev)
+	}
+	return returns
+}
+
+// volatility 收益率的样本标准差(%)，收益率不足两个时返回nil
+func volatility(returns map[int64]float64) *float64 {
+	n := float64(len(returns))
+	if n < 2 {
+		return nil
+	}
+	var sum float64
+	for _, r := range returns {
+		sum += r
+	}
+	mean := sum / n
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	value := math.Sqrt(variance/(n-1)) * 100
+	return &value
+}
+
+// correlationMatrix 两两计算共同时间点收益率的皮尔逊相关系数，数据不足时为nil
+func correlationMatrix(returns []map[int64]float64) [][]*float64 {
+	matrix := make([][]*float64, len(returns))
+	for i := range matrix {
+		matrix[i] = make([]*float64, len(returns))
+	}
+	for i := range returns {
+		for j := i; j < len(returns); j++ {
+			value := pearson(returns[i], returns[j])
+			matrix[i][j] = value
+			matrix[j][i] = value
+		}
+	}
+	return matrix
+}
+
+// pearson 按时间对齐后的皮尔逊相关系数
+func pearson(a, b map[int64]float64) *float64 {
+	var xs, ys []float64
+	for ts, x := range a {
+		if y, ok := b[ts]; ok {
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+	n := float64(len(xs))
+	if len(xs) < minCorrelationSamples {
+		return nil
+	}
+
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+	value := cov / math.Sqrt(varX*varY)
+	return &value
+}
+
+// klineCache 进程内K线缓存，同一币种和周期的并发请求只查询一次
+type klineCache struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	items map[string]klineEntry
+	group singleflight.Group
+}
+
+// klineEntry 缓存的K线
+type klineEntry struct {
+	points    []model.PricePoint
+	expiresAt time.Time
+}
+
+// newKlineCache 创建K线缓存
+func newKlineCache(ttl time.Duration) *klineCache {
+	return &klineCache{ttl: ttl, items: make(map[string]klineEntry)}
+}
+
+// get 返回未过期的缓存，否则调用fetch并缓存结果，失败不缓存
+func (c *klineCache) get(key string, fetch func() ([]model.PricePoint, error)) ([]model.PricePoint, error) {
+	c.mu.Lock()
+	entry, ok := c.items[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.points, nil
+	}
+
+	value, err, _ := c.group.Do(key, func() (interface{}, error) {
+		points, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		c.set(key, points)
+		return points, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]model.PricePoint), nil
+}
+
+// set 写入缓存，条目过多时先清理过期条目
+func (c *klineCache) set(key string, points []model.PricePoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.items) >= compareKlineCacheMaxItems {
+		for k, e := range c.items {
+			if now.After(e.expiresAt) {
+				delete(c.items, k)
+			}
+		}
+	}
+	c.items[key] = klineEntry{points: points, expiresAt: now.Add(c.ttl)}
+}
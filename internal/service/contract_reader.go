@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+// pairPricePasses 通过交易对储备推导价格的轮数
+const pairPricePasses = 3
+
+// lpTokenABI 交易对合约ABI，同时覆盖普通BEP20代币的方法
+const lpTokenABI = `[
+	{"constant": true, "inputs": [], "name": "token0", "outputs": [{"name": "", "type": "address"}], "type": "function"},
+	{"constant": true, "inputs": [], "name": "token1", "outputs": [{"name": "", "type": "address"}], "type": "function"},
+	{"constant": true, "inputs": [], "name": "getReserves", "outputs": [
+		{"name": "reserve0", "type": "uint112"},
+		{"name": "reserve1", "type": "uint112"},
+		{"name": "blockTimestampLast", "type": "uint32"}
+	], "type": "function"},
+	{"constant": true, "inputs": [], "name": "totalSupply", "outputs": [{"name": "", "type": "uint256"}], "type": "function"},
+	{"constant": true, "inputs": [{"name": "owner", "type": "address"}], "name": "balanceOf", "outputs": [{"name": "", "type": "uint256"}], "type": "function"},
+	{"constant": true, "inputs": [], "name": "symbol", "outputs": [{"name": "", "type": "string"}], "type": "function"},
+	{"constant": true, "inputs": [], "name": "decimals", "outputs": [{"name": "", "type": "uint8"}], "type": "function"}
+]`
+
+// chainToken 代币基本信息
+type chainToken struct {
+	address  common.Address
+	symbol   string
+	decimals uint8
+}
+
+// pairState 交易对当前状态，数量均已按精度换算
+type pairState struct {
+	address     common.Address
+	token       chainToken // LP代币本身
+	tokens      [2]chainToken
+	reserves    [2]float64
+	totalSupply float64
+}
+
+// contractReader 通过BSC节点读取代币与交易对合约
+type contractReader struct {
+	bscService BSCService
+	lpABI      abi.ABI
+}
+
+// newContractReader 创建合约读取器
+func newContractReader(bscService BSCService) *contractReader {
+	parsed, err := abi.JSON(strings.NewReader(lpTokenABI))
+	if err != nil {
+		logger.GetLogger().Errorf("Failed to parse LP token ABI: %v", err)
+	}
+	return &contractReader{
+		bscService: bscService,
+		lpABI:      parsed,
+	}
+}
+
+// call 调用合约只读方法并解码返回值
+func (r *contractReader) call(ctx context.Context, contract abi.ABI, address common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	if r.bscService == nil {
+		return nil, fmt.Errorf("BSC service not initialized")
+	}
+
+	data, err := contract.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := r.bscService.CallContract(ctx, address, data)
+	if err != nil {
+		return nil, err
+	}
+	return contract.Unpack(method, out)
+}
+
+// callUint 调用返回单个uint256的方法
+func (r *contractReader) callUint(ctx context.Context, contract abi.ABI, address common.Address, method string, args ...interface{}) (*big.Int, error) {
+	out, err := r.call(ctx, contract, address, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%s returned no value", method)
+	}
+	value, ok := out[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("%s returned unexpected type %T", method, out[0])
+	}
+	return value, nil
+}
+
+// readToken 读取代币符号和精度
+func (r *contractReader) readToken(ctx context.Context, address common.Address) (chainToken, error) {
+	token := chainToken{address: address}
+	symbol, err := r.call(ctx, r.lpABI, address, "symbol")
+	if err != nil {
+		return token, err
+	}
+	decimals, err := r.call(ctx, r.lpABI, address, "decimals")
+	if err != nil {
+		return token, err
+	}
+	token.symbol = symbol[0].(string)
+	token.decimals = decimals[0].(uint8)
+	return token, nil
+}
+
+// balanceOf 读取地址持有的代币数量
+func (r *contractReader) balanceOf(ctx context.Context, token chainToken, owner common.Address) (float64, error) {
+	balance, err := r.callUint(ctx, r.lpABI, token.address, "balanceOf", owner)
+	if err != nil {
+		return 0, err
+	}
+	return toFloat(balance, token.decimals), nil
+}
+
+// readPair 读取交易对的两种代币、储备量和LP总量，非交易对合约返回错误
+func (r *contractReader) readPair(ctx context.Context, lpToken chainToken) (*pairState, error) {
+	token0, err := r.call(ctx, r.lpABI, lpToken.address, "token0")
+	if err != nil {
+		return nil, err
+	}
+	token1, err := r.call(ctx, r.lpABI, lpToken.address, "token1")
+	if err != nil {
+		return nil, err
+	}
+	reserves, err := r.call(ctx, r.lpABI, lpToken.address, "getReserves")
+	if err != nil {
+		return nil, err
+	}
+	totalSupply, err := r.callUint(ctx, r.lpABI, lpToken.address, "totalSupply")
+	if err != nil {
+		return nil, err
+	}
+
+	pair := &pairState{
+		address:     lpToken.address,
+		token:       lpToken,
+		totalSupply: toFloat(totalSupply, lpToken.decimals),
+	}
+	for i, address := range []common.Address{token0[0].(common.Address), token1[0].(common.Address)} {
+		token, err := r.readToken(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		pair.tokens[i] = token
+		pair.reserves[i] = toFloat(reserves[i].(*big.Int), token.decimals)
+	}
+	return pair, nil
+}
+
+// value 交易对的美元价值，只有一侧可定价时按两倍估算
+func (p *pairState) value(prices map[common.Address]float64) float64 {
+	value0 := p.reserves[0] * prices[p.tokens[0].address]
+	value1 := p.reserves[1] * prices[p.tokens[1].address]
+	switch {
+	case value0 > 0 && value1 > 0:
+		return value0 + value1
+	case value0 > 0:
+		return 2 * value0
+	default:
+		return 2 * value1
+	}
+}
+
+// derivePrices 通过交易对储备比例为未定价代币推导价格，
+// 每轮选择已知一侧价值最大的交易对，避免被小池子扭曲
+func derivePrices(pairs []*pairState, prices map[common.Address]float64) {
+	type candidate struct{ price, depth float64 }
+
+	for pass := 0; pass < pairPricePasses; pass++ {
+		best := make(map[common.Address]candidate)
+		for _, pair := range pairs {
+			for known := 0; known < 2; known++ {
+				other := 1 - known
+				knownPrice := prices[pair.tokens[known].address]
+				if knownPrice <= 0 || prices[pair.tokens[other].address] > 0 || pair.reserves[other] <= 0 {
+					continue
+				}
+				depth := pair.reserves[known] * knownPrice
+				if c, ok := best[pair.tokens[other].address]; !ok || depth > c.depth {
+					best[pair.tokens[other].address] = candidate{price: depth / pair.reserves[other], depth: depth}
+				}
+			}
+		}
+		if len(best) == 0 {
+			return
+		}
+		for address, c := range best {
+			prices[address] = c.price
+		}
+	}
+}
+
+// toFloat 按精度将链上整数换算为数量
+func toFloat(value *big.Int, decimals uint8) float64 {
+	return decimal.NewFromBigInt(value, -int32(decimals)).InexactFloat64()
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/precision"
+)
+
+// Discord推送默认配置
+const (
+	defaultDiscordTimeout     = 10 * time.Second
+	defaultDiscordExplorerURL = "https://bscscan.com"
+	maxDiscordResponse        = 4096
+)
+
+// Discord嵌入消息的长度上限
+const (
+	maxDiscordTitle       = 256
+	maxDiscordDescription = 4096
+	maxDiscordFields      = 25
+	maxDiscordFieldName   = 256
+	maxDiscordFieldValue  = 1024
+)
+
+// discordSeverityColors 按告警级别的嵌入消息颜色
+var discordSeverityColors = map[string]int{
+	model.AlertSeverityInfo:     0x3498DB,
+	model.AlertSeverityWarning:  0xF1C40F,
+	model.AlertSeverityCritical: 0xE74C3C,
+}
+
+// discordMessage webhook请求体
+type discordMessage struct {
+	Username        string                 `json:"username,omitempty"`
+	AvatarURL       string                 `json:"avatar_url,omitempty"`
+	Embeds          []discordEmbed         `json:"embeds"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+// discordAllowedMentions 告警标题可能来自用户输入，不解析任何@提及
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// discordEmbed 嵌入消息
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+// discordField 嵌入消息的字段
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// discordFooter 嵌入消息的页脚
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+// discordChannel 通过Discord频道webhook推送嵌入格式的告警
+type discordChannel struct {
+	config   *config.DiscordNotifications
+	client   *http.Client
+	logger   logger.Logger
+	explorer string
+}
+
+// newDiscordChannel 创建Discord推送渠道，未启用时返回nil
+func newDiscordChannel(cfg *config.Config) *discordChannel {
+	discord := &cfg.Notifications.Discord
+	if !discord.Enabled {
+		return nil
+	}
+
+	timeout := discord.Timeout
+	if timeout <= 0 {
+		timeout = defaultDiscordTimeout
+	}
+	explorer := discord.ExplorerURL
+	if explorer == "" {
+		explorer = defaultDiscordExplorerURL
+	}
+	return &discordChannel{
+		config:   discord,
+		client:   httpclient.New(httpclient.Options{Timeout: timeout}),
+		logger:   logger.GetLogger(),
+		explorer: strings.TrimRight(explorer, "/"),
+	}
+}
+
+// accepts 是否推送该类型的告警
+func (d *discordChannel) accepts(alert *model.Alert) bool {
+	return acceptsAlertType(d.config.Types, alert)
+}
+
+// send 生成嵌入消息并调用webhook，失败只记录在投递结果中；投递目标不含webhook令牌
+func (d *discordChannel) send(ctx context.Context, alert *model.Alert) []model.AlertDelivery {
+	delivery := model.AlertDelivery{Channel: model.AlertChannelDiscord}
+	start := time.Now()
+	err := d.execute(ctx, alert)
+	delivery.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		d.logger.Warnf("Discord delivery of alert %s failed: %v", alert.ID, err)
+		delivery.Error = err.Error()
+	} else {
+		delivery.Success = true
+	}
+	return []model.AlertDelivery{delivery}
+}
+
+// execute 调用webhook，错误中不包含带令牌的webhook地址
+func (d *discordChannel) execute(ctx context.Context, alert *model.Alert) error {
+	body, err := json.Marshal(&discordMessage{
+		Username:        d.config.Username,
+		AvatarURL:       d.config.AvatarURL,
+		Embeds:          []discordEmbed{d.embed(alert)},
+		AllowedMentions: discordAllowedMentions{Parse: []string{}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid discord webhook url")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("discord request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	var result struct {
+		Message    string  `json:"message"`
+		RetryAfter float64 `json:"retry_after"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxDiscordResponse))
+	_ = json.Unmarshal(data, &result)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("discord rate limited, retry after %.1fs", result.RetryAfter)
+	case result.Message != "":
+		return fmt.Errorf("discord api status %d: %s", resp.StatusCode, result.Message)
+	default:
+		return fmt.Errorf("discord api status %d", resp.StatusCode)
+	}
+}
+
+// embed 按告警类型生成嵌入消息：条件告警按指标格式化数值，带交易哈希的链上告警链接到区块浏览器
+func (d *discordChannel) embed(alert *model.Alert) discordEmbed {
+	color, ok := discordSeverityColors[alert.Severity]
+	if !ok {
+		color = discordSeverityColors[model.AlertSeverityInfo]
+	}
+	createdAt := alert.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	embed := discordEmbed{
+		Title:       truncateRunes(alert.Title, maxDiscordTitle),
+		Description: truncateRunes(alert.Message, maxDiscordDescription),
+		Color:       color,
+		Footer:      &discordFooter{Text: alert.Type},
+		Timestamp:   createdAt.UTC().Format(time.RFC3339),
+	}
+
+	txHash, _ := alert.Data["tx_hash"].(string)
+	switch {
+	case alert.Type == model.AlertTypeRule:
+		embed.Fields = d.ruleFields(alert)
+	case txHash != "":
+		embed.URL = d.explorer + "/tx/" + txHash
+		embed.Fields = d.chainFields(alert, txHash)
+	default:
+		if alert.Subject != "" {
+			embed.Fields = append(embed.Fields, discordField{Name: "subject", Value: alert.Subject, Inline: true})
+		}
+		embed.Fields = append(embed.Fields, discordDataFields(alert.Data, nil)...)
+	}
+	if len(embed.Fields) > maxDiscordFields {
+		embed.Fields = embed.Fields[:maxDiscordFields]
+	}
+	return embed
+}
+
+// ruleFields 条件告警：币种和各指标的当前值，价格按币种精度显示
+func (d *discordChannel) ruleFields(alert *model.Alert) []discordField {
+	fields := []discordField{{Name: "symbol", Value: alert.Subject, Inline: true}}
+	fields = append(fields, discordDataFields(alert.Data, func(name string, value interface{}) (string, bool) {
+		v, ok := value.(float64)
+		if !ok {
+			return "", false
+		}
+		switch name {
+		case model.AlertSignalPrice:
+			return precision.Format(alert.Subject, v), true
+		case model.AlertSignalChange24h:
+			return fmt.Sprintf("%+.2f%%", v), true
+		case model.AlertSignalVolume, model.AlertSignalVolumeRatio, model.AlertSignalRSI:
+			return fmt.Sprintf("%.2f", v), true
+		}
+		return "", false
+	})...)
+	return fields
+}
+
+// chainFields 链上告警：交易对和交易链接到区块浏览器，金额和占比带单位
+func (d *discordChannel) chainFields(alert *model.Alert, txHash string) []discordField {
+	var fields []discordField
+	if alert.Subject != "" {
+		fields = append(fields, discordField{
+			Name:   "pair",
+			Value:  fmt.Sprintf("[%s](%s/address/%s)", discordShortHex(alert.Subject), d.explorer, alert.Subject),
+			Inline: true,
+		})
+	}
+	fields = append(fields, discordDataFields(alert.Data, func(name string, value interface{}) (string, bool) {
+		switch name {
+		case "tx_hash":
+			return fmt.Sprintf("[%s](%s/tx/%s)", discordShortHex(txHash), d.explorer, txHash), true
+		case "value_usd":
+			if v, ok := value.(float64); ok {
+				return fmt.Sprintf("$%.2f", v), true
+			}
+		case "share_pct":
+			if v, ok := value.(float64); ok {
+				return fmt.Sprintf("%.1f%%", v), true
+			}
+		}
+		return "", false
+	})...)
+	return fields
+}
+
+// discordDataFields 附加数据按键排序生成字段，format返回false时使用默认格式
+func discordDataFields(data map[string]interface{}, format func(name string, value interface{}) (string, bool)) []discordField {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]discordField, 0, len(names))
+	for _, name := range names {
+		value, ok := "", false
+		if format != nil {
+			value, ok = format(name, data[name])
+		}
+		if !ok {
+			value = fmt.Sprint(data[name])
+		}
+		if value == "" {
+			continue
+		}
+		fields = append(fields, discordField{
+			Name:   truncateRunes(name, maxDiscordFieldName),
+			Value:  truncateRunes(value, maxDiscordFieldValue),
+			Inline: true,
+		})
+	}
+	return fields
+}
+
+// discordShortHex 缩写地址或交易哈希，如0x1234…abcd
+func discordShortHex(s string) string {
+	if len(s) <= 14 {
+		return s
+	}
+	return s[:6] + "…" + s[len(s)-4:]
+}
+
+// truncateRunes 按字符数截断
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// 邮件告警默认配置
+const (
+	defaultEmailTimeout       = 15 * time.Second
+	defaultEmailSubjectPrefix = "[crypto-info]"
+	emailTemplateName         = "default" // 未按类型配置模板时使用的模板名
+	emailLineLength           = 76        // base64正文的行长度
+)
+
+// defaultEmailTemplate 未配置default模板时使用，附加数据按键排序
+const defaultEmailTemplate = `<!DOCTYPE html>
+<html><body style="font-family:sans-serif">
+<h2>{{.Title}}</h2>
+<p>{{.Message}}</p>
+<table cellpadding="4" style="border-collapse:collapse">
+<tr><td><b>Type</b></td><td>{{.Type}}</td></tr>
+<tr><td><b>Severity</b></td><td>{{.Severity}}</td></tr>
+{{if .Subject}}<tr><td><b>Subject</b></td><td>{{.Subject}}</td></tr>{{end}}
+{{range $key, $value := .Data}}<tr><td><b>{{$key}}</b></td><td>{{$value}}</td></tr>
+{{end}}<tr><td><b>Time</b></td><td>{{.CreatedAt.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+</table>
+</body></html>`
+
+// emailChannel 通过SMTP发送HTML邮件告警
+type emailChannel struct {
+	config    *config.EmailNotifications
+	logger    logger.Logger
+	templates map[string]*template.Template
+}
+
+// newEmailChannel 创建邮件告警渠道，未启用时返回nil；模板已在加载配置时校验
+func newEmailChannel(cfg *config.Config) *emailChannel {
+	email := &cfg.Notifications.Email
+	if !email.Enabled {
+		return nil
+	}
+
+	e := &emailChannel{
+		config:    email,
+		logger:    logger.GetLogger(),
+		templates: make(map[string]*template.Template, len(email.Templates)+1),
+	}
+	e.templates[emailTemplateName] = template.Must(template.New(emailTemplateName).Parse(defaultEmailTemplate))
+	for name, text := range email.Templates {
+		tmpl, err := template.New(name).Parse(text)
+		if err != nil {
+			e.logger.Errorf("Invalid email template %s: %v", name, err)
+			continue
+		}
+		e.templates[name] = tmpl
+	}
+	return e
+}
+
+// accepts 是否发送该类型的告警
+func (e *emailChannel) accepts(alert *model.Alert) bool {
+	return acceptsAlertType(e.config.Types, alert)
+}
+
+// send 渲染模板并发送邮件，失败只记录在投递结果中
+func (e *emailChannel) send(ctx context.Context, alert *model.Alert) []model.AlertDelivery {
+	delivery := model.AlertDelivery{Channel: model.AlertChannelEmail, Target: strings.Join(e.config.To, ",")}
+	start := time.Now()
+	err := e.sendMail(ctx, alert)
+	delivery.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		e.logger.Warnf("Email delivery of alert %s failed: %v", alert.ID, err)
+		delivery.Error = err.Error()
+	} else {
+		delivery.Success = true
+	}
+	return []model.AlertDelivery{delivery}
+}
+
+// sendMail 连接SMTP服务器发送邮件，超时取配置和ctx截止时间中较早的一个
+func (e *emailChannel) sendMail(ctx context.Context, alert *model.Alert) error {
+	msg, err := e.compose(alert)
+	if err != nil {
+		return err
+	}
+
+	timeout := e.config.Timeout
+	if timeout <= 0 {
+		timeout = defaultEmailTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(e.config.Port))
+	tlsConfig := &tls.Config{ServerName: e.config.Host, MinVersion: tls.VersionTLS12}
+	var conn net.Conn
+	if e.config.TLS == "tls" {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake failed: %w", err)
+	}
+	defer client.Close()
+
+	if e.config.TLS == "" || e.config.TLS == "starttls" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp starttls failed: %w", err)
+		}
+	}
+	if e.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+
+	from, _ := mail.ParseAddress(e.config.From)
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	for _, to := range e.config.To {
+		rcpt, _ := mail.ParseAddress(to)
+		if err := client.Rcpt(rcpt.Address); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s failed: %w", rcpt.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp server rejected email: %w", err)
+	}
+	return client.Quit()
+}
+
+// compose 生成MIME邮件，标题按RFC 2047编码，正文为base64编码的HTML
+func (e *emailChannel) compose(alert *model.Alert) ([]byte, error) {
+	tmpl, ok := e.templates[alert.Type]
+	if !ok {
+		tmpl = e.templates[emailTemplateName]
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, alert); err != nil {
+		return nil, fmt.Errorf("failed to render email template %s: %w", tmpl.Name(), err)
+	}
+
+	prefix := e.config.SubjectPrefix
+	if prefix == "" {
+		prefix = defaultEmailSubjectPrefix
+	}
+	// 告警标题可能来自用户输入，去掉换行避免注入邮件头
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(fmt.Sprintf("%s [%s] %s", prefix, alert.Severity, alert.Title))
+	domain := "localhost"
+	if from, err := mail.ParseAddress(e.config.From); err == nil {
+		if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+			domain = from.Address[at+1:]
+		}
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", alert.CreatedAt.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%s@%s>\r\n", uuid.New().String(), domain)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString(body.Bytes())
+	for len(encoded) > emailLineLength {
+		msg.WriteString(encoded[:emailLineLength] + "\r\n")
+		encoded = encoded[emailLineLength:]
+	}
+	msg.WriteString(encoded + "\r\n")
+	return msg.Bytes(), nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+
+	"crypto-info/internal/pkg/binance"
+	"crypto-info/internal/pkg/breaker"
+	"crypto-info/internal/pkg/huobi"
+	"crypto-info/internal/pkg/okx"
+)
+
+var (
+	// ErrUnsupportedSymbol 不支持的币种
+	ErrUnsupportedSymbol = errors.New("unsupported symbol")
+	// ErrUpstreamUnavailable 上游数据源不可用
+	ErrUpstreamUnavailable = errors.New("upstream data unavailable")
+	// ErrInvalidParameter 请求参数无效
+	ErrInvalidParameter = errors.New("invalid parameter")
+	// ErrNotFound 数据不存在
+	ErrNotFound = errors.New("not found")
+)
+
+func init() {
+	// 请求不支持的币种或无效参数不说明数据源异常，不应触发熔断
+	breaker.RegisterClientErrors(ErrUnsupportedSymbol, ErrInvalidParameter, ErrNotFound,
+		binance.ErrInvalidSymbol, huobi.ErrInvalidSymbol, okx.ErrInvalidSymbol)
+}
+
+// NegativeCacheError 命中负缓存时返回的错误
+type NegativeCacheError struct {
+	Err    error  // 错误类别，ErrUnsupportedSymbol或ErrUpstreamUnavailable
+	Reason string // 首次失败时的错误信息
+}
+
+// Error 实现error接口
+func (e *NegativeCacheError) Error() string {
+	return e.Reason + " (negative cached)"
+}
+
+// Unwrap 返回错误类别以支持errors.Is
+func (e *NegativeCacheError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/pkg/binance"
+	"crypto-info/internal/pkg/breaker"
+	"crypto-info/internal/pkg/provider"
+)
+
+// TestBadSymbolsNeverTripBreaker 请求不存在的币种或无效参数不应使数据源熔断
+func TestBadSymbolsNeverTripBreaker(t *testing.T) {
+	clientErrors := map[string]error{
+		"service unsupported symbol":  fmt.Errorf("%w: %s", ErrUnsupportedSymbol, "NOPE"),
+		"provider unsupported symbol": fmt.Errorf("%w: %w", provider.ErrUnsupportedSymbol, binance.ErrInvalidSymbol),
+		"binance invalid symbol":      fmt.Errorf("%w: %s", binance.ErrInvalidSymbol, "Invalid symbol."),
+		"invalid parameter":           fmt.Errorf("%w: confirmations too large", ErrInvalidParameter),
+		"not found":                   fmt.Errorf("%w: token", ErrNotFound),
+	}
+
+	for name, err := range clientErrors {
+		t.Run(name, func(t *testing.T) {
+			set := breaker.New(&config.Breaker{Enabled: true, FailureThreshold: 2})
+			for i := 0; i < 10; i++ {
+				if allowErr := set.Allow(priceSourceBinance); allowErr != nil {
+					t.Fatalf("breaker opened after %d bad-symbol requests: %v", i, allowErr)
+				}
+				set.Record(priceSourceBinance, err)
+			}
+		})
+	}
+
+	t.Run("upstream failure still trips", func(t *testing.T) {
+		set := breaker.New(&config.Breaker{Enabled: true, FailureThreshold: 2})
+		for i := 0; i < 2; i++ {
+			if err := set.Allow(priceSourceBinance); err != nil {
+				t.Fatalf("breaker opened too early: %v", err)
+			}
+			set.Record(priceSourceBinance, errors.New("connection reset by peer"))
+		}
+		if err := set.Allow(priceSourceBinance); !errors.Is(err, breaker.ErrOpen) {
+			t.Fatalf("expected breaker to open after upstream failures, got %v", err)
+		}
+	})
+}
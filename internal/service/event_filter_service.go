@@ -0,0 +1,808 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 事件过滤默认配置
+const (
+	defaultEventFilterInterval   = 10 * time.Second
+	defaultEventFilterMaxBlocks  = 500
+	defaultEventFilterMaxPerUser = 20
+	defaultEventFilterMaxMatches = 500
+	defaultEventMatchLimit       = 50
+	maxEventFilterName           = 64
+	maxEventFilterTopics         = 3  // topic1~topic3
+	maxEventFilterTopicValues    = 20 // 每个topic位置可接受的值
+	eventFilterKeyPrefix         = "events:filters:user:"
+	eventFilterIndexKey          = "events:filters:index"
+	eventFilterMatchesKeyPrefix  = "events:filters:matches:"
+	eventFilterCursorKey         = "events:filters:cursor"
+	eventFilterLockKey           = "events:filters:lock"
+	eventFilterWebhookPrefix     = "event_filter:" // 投递记录中的webhook名称前缀
+)
+
+// EventTypeFilterMatch 事件过滤器匹配，用作webhook投递记录的类型
+const EventTypeFilterMatch = "event_match"
+
+// eventSignaturePattern 规范形式的事件签名，参数只写类型，如 Transfer(address,address,uint256)
+var eventSignaturePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\([A-Za-z0-9_,\[\]()]*\)$`)
+
+// EventFilterService 用户自定义事件过滤服务接口
+type EventFilterService interface {
+	CreateFilter(ctx context.Context, userID string, req *model.EventFilterRequest) (*model.EventFilter, error)
+	ListFilters(ctx context.Context, userID string) (*model.EventFilterListResponse, error)
+	GetFilter(ctx context.Context, userID, id string) (*model.EventFilter, error)
+	UpdateFilter(ctx context.Context, userID, id string, req *model.EventFilterRequest) (*model.EventFilter, error)
+	DeleteFilter(ctx context.Context, userID, id string) error
+	// GetMatches 获取过滤器最近的匹配记录，按时间倒序
+	GetMatches(ctx context.Context, userID, id string, limit int) (*model.EventMatchListResponse, error)
+	// ScanNow 扫描游标之后的区块并投递匹配的事件
+	ScanNow(ctx context.Context) error
+	Start(ctx context.Context) error
+	Stop() error
+}
+
+// eventFilterService 过滤器按用户保存在Redis哈希中，所有过滤器的索引保存在有序集合中
+//
+// 每轮扫描把所有启用的过滤器合并为一次日志查询，再逐条按topic条件匹配；
+// 多实例部署时通过Redis锁保证同一轮只有一个实例扫描，扫描进度保存在共享游标中。
+type eventFilterService struct {
+	redisClient   database.RedisClient
+	config        *config.Config
+	logger        logger.Logger
+	bscService    BSCService
+	streamService StreamService
+	webhooks      WebhookService
+
+	runMutex  sync.Mutex
+	scanMutex sync.Mutex
+	running   bool
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// compiledEventFilter 解析后的过滤条件
+type compiledEventFilter struct {
+	filter   *model.EventFilter
+	contract common.Address
+	topic0   common.Hash
+	topics   [][]common.Hash
+}
+
+// NewEventFilterService 创建事件过滤服务，streamService和webhooks可为nil
+func NewEventFilterService(redisClient database.RedisClient, cfg *config.Config, bscService BSCService, streamService StreamService, webhooks WebhookService) EventFilterService {
+	return &eventFilterService{
+		redisClient:   redisClient,
+		config:        cfg,
+		logger:        logger.GetLogger(),
+		bscService:    bscService,
+		streamService: streamService,
+		webhooks:      webhooks,
+	}
+}
+
+// CreateFilter 创建事件过滤器，从创建后的下一轮扫描开始匹配
+func (s *eventFilterService) CreateFilter(ctx context.Context, userID string, req *model.EventFilterRequest) (*model.EventFilter, error) {
+	if err := s.checkStorage(userID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.redisClient.HGetAll(ctx, eventFilterKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event filters: %w", err)
+	}
+	if len(existing) >= s.maxPerUser() {
+		return nil, fmt.Errorf("%w: at most %d event filters per user", ErrInvalidParameter, s.maxPerUser())
+	}
+
+	filter := &model.EventFilter{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	if err := applyEventFilterRequest(filter, req); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, filter); err != nil {
+		return nil, err
+	}
+	if err := s.redisClient.ZAdd(ctx, eventFilterIndexKey, float64(filter.CreatedAt.UnixMilli()), userScopedMember(userID, filter.ID)); err != nil {
+		return nil, fmt.Errorf("failed to index event filter: %w", err)
+	}
+	return redactEventFilter(filter), nil
+}
+
+// ListFilters 获取用户的事件过滤器，按创建时间排序
+func (s *eventFilterService) ListFilters(ctx context.Context, userID string) (*model.EventFilterListResponse, error) {
+	if err := s.checkStorage(userID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.redisClient.HGetAll(ctx, eventFilterKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event filters: %w", err)
+	}
+
+	resp := &model.EventFilterListResponse{Filters: make([]model.EventFilter, 0, len(entries))}
+	for id, value := range entries {
+		var filter model.EventFilter
+		if err := json.Unmarshal([]byte(value), &filter); err != nil {
+			logger.From(ctx).Warnf("Skipping malformed event filter %s for user %s: %v", id, userID, err)
+			continue
+		}
+		resp.Filters = append(resp.Filters, *redactEventFilter(&filter))
+	}
+	sort.Slice(resp.Filters, func(i, j int) bool {
+		return resp.Filters[i].CreatedAt.Before(resp.Filters[j].CreatedAt)
+	})
+	resp.Total = len(resp.Filters)
+	return resp, nil
+}
+
+// GetFilter 获取事件过滤器，不存在时返回ErrNotFound
+func (s *eventFilterService) GetFilter(ctx context.Context, userID, id string) (*model.EventFilter, error) {
+	filter, err := s.load(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return redactEventFilter(filter), nil
+}
+
+// UpdateFilter 替换事件过滤器的设置，请求未带webhook_secret且地址不变时保留原密钥
+func (s *eventFilterService) UpdateFilter(ctx context.Context, userID, id string, req *model.EventFilterRequest) (*model.EventFilter, error) {
+	filter, err := s.load(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookURL, secret := filter.WebhookURL, filter.WebhookSecret
+	if err := applyEventFilterRequest(filter, req); err != nil {
+		return nil, err
+	}
+	if filter.WebhookSecret == "" && filter.WebhookURL == webhookURL {
+		filter.WebhookSecret = secret
+	}
+	if err := s.save(ctx, filter); err != nil {
+		return nil, err
+	}
+	return redactEventFilter(filter), nil
+}
+
+// DeleteFilter 删除事件过滤器及其匹配记录
+func (s *eventFilterService) DeleteFilter(ctx context.Context, userID, id string) error {
+	if _, err := s.load(ctx, userID, id); err != nil {
+		return err
+	}
+	if err := s.redisClient.HDel(ctx, eventFilterKey(userID), id); err != nil {
+		return fmt.Errorf("failed to delete event filter: %w", err)
+	}
+	if err := s.unindex(ctx, userScopedMember(userID, id)); err != nil {
+		return fmt.Errorf("failed to unindex event filter: %w", err)
+	}
+	if err := s.redisClient.Del(ctx, eventFilterMatchesKey(id)); err != nil {
+		logger.From(ctx).Warnf("Failed to delete matches of event filter %s: %v", id, err)
+	}
+	return nil
+}
+
+// GetMatches 获取过滤器最近的匹配记录
+func (s *eventFilterService) GetMatches(ctx context.Context, userID, id string, limit int) (*model.EventMatchListResponse, error) {
+	if _, err := s.load(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultEventMatchLimit
+	}
+	if limit > s.maxMatches() {
+		limit = s.maxMatches()
+	}
+
+	client := s.redisClient.GetClient()
+	key := s.redisClient.KeyPrefix() + eventFilterMatchesKey(id)
+	total, err := client.ZCard(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count event matches: %w", err)
+	}
+	members, err := client.ZRevRange(ctx, key, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event matches: %w", err)
+	}
+
+	resp := &model.EventMatchListResponse{
+		FilterID: id,
+		Matches:  make([]model.EventMatch, 0, len(members)),
+		Total:    int(total),
+	}
+	for _, member := range members {
+		var match model.EventMatch
+		if err := json.Unmarshal([]byte(member), &match); err != nil {
+			logger.From(ctx).Warnf("Skipping malformed match of event filter %s: %v", id, err)
+			continue
+		}
+		resp.Matches = append(resp.Matches, match)
+	}
+	return resp, nil
+}
+
+// Start 启动定时扫描
+func (s *eventFilterService) Start(ctx context.Context) error {
+	if !s.config.BSC.Enabled || !s.config.BSC.EventFilters.Enabled || s.redisClient == nil || s.bscService == nil {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("event filter scanning is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "event_filters", s.run, s.done)
+
+	s.logger.Infof("Event filter scanning started with interval %s", s.interval())
+	return nil
+}
+
+// Stop 停止定时扫描
+func (s *eventFilterService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	s.logger.Info("Event filter scanning stopped")
+	return nil
+}
+
+// run 按间隔扫描，本轮已由其他实例扫描时跳过
+func (s *eventFilterService) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval())
+	defer ticker.Stop()
+
+	for {
+		acquired, err := s.lock(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				s.logger.Warnf("Failed to lock event filter scan: %v", err)
+			}
+		case acquired:
+			if err := s.ScanNow(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Errorf("Event filter scan failed: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScanNow 扫描游标之后的区块，首次扫描或没有启用的过滤器时只把游标移到最新区块，不回溯历史事件
+func (s *eventFilterService) ScanNow(ctx context.Context) error {
+	if s.redisClient == nil || s.bscService == nil {
+		return fmt.Errorf("event filters are not available")
+	}
+
+	s.scanMutex.Lock()
+	defer s.scanMutex.Unlock()
+
+	latestBlock, err := s.bscService.GetLatestBlock(ctx)
+	if err != nil {
+		return err
+	}
+	latest := latestBlock.Number.Uint64()
+
+	cursorValue, err := s.redisClient.Get(ctx, eventFilterCursorKey)
+	if err != nil {
+		return err
+	}
+	cursor, _ := strconv.ParseUint(cursorValue, 10, 64)
+
+	filters, err := s.activeFilters(ctx)
+	if err != nil {
+		return err
+	}
+	if cursor == 0 || len(filters) == 0 {
+		return s.redisClient.Set(ctx, eventFilterCursorKey, strconv.FormatUint(latest, 10), 0)
+	}
+
+	from := cursor + 1
+	if from > latest {
+		return nil
+	}
+	// 落后较多时分批追赶
+	to := latest
+	if maxBlocks := s.maxBlocks(); to-from+1 > maxBlocks {
+		to = from + maxBlocks - 1
+	}
+
+	logs, err := s.bscService.FilterLogs(ctx, buildEventFilterQuery(filters, from, to))
+	if err != nil {
+		return err
+	}
+
+	// 投递webhook前推进游标：投递较慢时扫描锁可能过期，其他实例从新游标继续，不会重复投递同一批事件
+	claimed, err := s.claimRange(ctx, cursor, to)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		s.logger.Debugf("Event filter blocks %d-%d already scanned by another run", from, to)
+		return nil
+	}
+
+	matched := make(map[*model.EventFilter]int) // 各过滤器本轮的匹配数
+	for i := range logs {
+		entry := &logs[i]
+		if entry.Removed || len(entry.Topics) == 0 {
+			continue
+		}
+		for _, filter := range filters {
+			if !filter.match(entry) {
+				continue
+			}
+			s.deliver(ctx, filter.filter, newEventMatch(filter.filter, entry))
+			matched[filter.filter]++
+		}
+	}
+	for filter, count := range matched {
+		s.recordMatchCount(ctx, filter, count)
+	}
+
+	s.logger.Debugf("Scanned event filters in blocks %d-%d: filters=%d logs=%d matched_filters=%d", from, to, len(filters), len(logs), len(matched))
+	return nil
+}
+
+// claimRange 游标仍为cursor时推进到to，返回false表示游标已被其他扫描推进，本轮的区块由对方处理
+func (s *eventFilterService) claimRange(ctx context.Context, cursor, to uint64) (bool, error) {
+	key := s.redisClient.KeyPrefix() + eventFilterCursorKey
+	claimed := false
+	err := s.redisClient.GetClient().Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, key).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		if current != strconv.FormatUint(cursor, 10) {
+			return nil
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, strconv.FormatUint(to, 10), 0)
+			return nil
+		})
+		if errors.Is(err, redis.TxFailedErr) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		claimed = true
+		return nil
+	}, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance event filter cursor: %w", err)
+	}
+	return claimed, nil
+}
+
+// activeFilters 读取所有启用的过滤器
+func (s *eventFilterService) activeFilters(ctx context.Context) ([]*compiledEventFilter, error) {
+	members, err := s.redisClient.ZRangeByScore(ctx, eventFilterIndexKey, "-inf", "+inf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event filter index: %w", err)
+	}
+
+	var filters []*compiledEventFilter
+	for _, member := range members {
+		userID, id, ok := parseUserScopedMember(member)
+		if !ok {
+			s.unindex(ctx, member)
+			continue
+		}
+		filter, err := s.load(ctx, userID, id)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				s.unindex(ctx, member)
+			}
+			continue
+		}
+		if !filter.Enabled {
+			continue
+		}
+		compiled, err := compileEventFilter(filter)
+		if err != nil {
+			s.logger.Warnf("Skipping invalid event filter %s: %v", filter.ID, err)
+			continue
+		}
+		filters = append(filters, compiled)
+	}
+	return filters, nil
+}
+
+// deliver 保存匹配记录，并推送到用户的实时推送连接和过滤器的webhook
+func (s *eventFilterService) deliver(ctx context.Context, filter *model.EventFilter, match *model.EventMatch) {
+	data, err := json.Marshal(match)
+	if err != nil {
+		return
+	}
+
+	key := eventFilterMatchesKey(filter.ID)
+	if err := s.redisClient.ZAdd(ctx, key, float64(match.MatchedAt.UnixMilli()), string(data)); err != nil {
+		s.logger.Warnf("Failed to record match of event filter %s: %v", filter.ID, err)
+	} else if err := s.redisClient.GetClient().ZRemRangeByRank(ctx, s.redisClient.KeyPrefix()+key, 0, int64(-s.maxMatches()-1)).Err(); err != nil {
+		s.logger.Warnf("Failed to trim matches of event filter %s: %v", filter.ID, err)
+	}
+
+	if filter.Stream && s.streamService != nil && s.streamService.Enabled() {
+		event := &model.StreamEvent{Type: model.StreamEventChain, UserID: filter.UserID, Data: data}
+		if err := s.streamService.Publish(ctx, event); err != nil {
+			s.logger.Warnf("Failed to publish match of event filter %s: %v", filter.ID, err)
+		}
+	}
+
+	if filter.WebhookURL != "" && s.webhooks != nil {
+		webhook := config.Webhook{
+			Name:   eventFilterWebhookPrefix + filter.ID,
+			URL:    filter.WebhookURL,
+			Secret: filter.WebhookSecret,
+		}
+		s.webhooks.Send(ctx, webhook, match.ID, EventTypeFilterMatch, data)
+	}
+}
+
+// recordMatchCount 累加过滤器的匹配次数，重新读取后保存以免覆盖用户在扫描期间的修改
+func (s *eventFilterService) recordMatchCount(ctx context.Context, filter *model.EventFilter, count int) {
+	current, err := s.load(ctx, filter.UserID, filter.ID)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	current.MatchCount += int64(count)
+	current.LastMatchedAt = &now
+	if err := s.save(ctx, current); err != nil {
+		s.logger.Warnf("Failed to update event filter %s: %v", filter.ID, err)
+	}
+}
+
+// load 读取过滤器，包含webhook密钥
+func (s *eventFilterService) load(ctx context.Context, userID, id string) (*model.EventFilter, error) {
+	if err := s.checkStorage(userID); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: event filter %s", ErrNotFound, id)
+	}
+
+	value, err := s.redisClient.HGet(ctx, eventFilterKey(userID), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event filter: %w", err)
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%w: event filter %s", ErrNotFound, id)
+	}
+
+	var filter model.EventFilter
+	if err := json.Unmarshal([]byte(value), &filter); err != nil {
+		return nil, fmt.Errorf("invalid event filter %s: %w", id, err)
+	}
+	return &filter, nil
+}
+
+// save 保存过滤器
+func (s *eventFilterService) save(ctx context.Context, filter *model.EventFilter) error {
+	filter.UpdatedAt = time.Now()
+	filter.WebhookSigned = false
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return err
+	}
+	if err := s.redisClient.HSet(ctx, eventFilterKey(filter.UserID), filter.ID, string(data)); err != nil {
+		return fmt.Errorf("failed to save event filter: %w", err)
+	}
+	return nil
+}
+
+// lock 获取本轮扫描的锁，锁在扫描间隔结束前过期
+func (s *eventFilterService) lock(ctx context.Context) (bool, error) {
+	ttl := s.interval() * 9 / 10
+	return s.redisClient.GetClient().SetNX(ctx, s.redisClient.KeyPrefix()+eventFilterLockKey, 1, ttl).Result()
+}
+
+// unindex 从过滤器索引中移除
+func (s *eventFilterService) unindex(ctx context.Context, member string) error {
+	return s.redisClient.GetClient().ZRem(ctx, s.redisClient.KeyPrefix()+eventFilterIndexKey, member).Err()
+}
+
+// checkStorage 检查用户标识和过滤器存储
+func (s *eventFilterService) checkStorage(userID string) error {
+	if userID == "" {
+		return fmt.Errorf("%w: user id is required", ErrInvalidParameter)
+	}
+	if s.redisClient == nil {
+		return fmt.Errorf("%w: event filter storage is not configured", ErrUpstreamUnavailable)
+	}
+	return nil
+}
+
+// interval 扫描间隔
+func (s *eventFilterService) interval() time.Duration {
+	if s.config.BSC.EventFilters.Interval > 0 {
+		return s.config.BSC.EventFilters.Interval
+	}
+	return defaultEventFilterInterval
+}
+
+// maxBlocks 单次扫描的最大区块跨度
+func (s *eventFilterService) maxBlocks() uint64 {
+	if s.config.BSC.EventFilters.MaxBlocks > 0 {
+		return s.config.BSC.EventFilters.MaxBlocks
+	}
+	return defaultEventFilterMaxBlocks
+}
+
+// maxPerUser 每个用户的过滤器上限
+func (s *eventFilterService) maxPerUser() int {
+	if s.config.BSC.EventFilters.MaxPerUser > 0 {
+		return s.config.BSC.EventFilters.MaxPerUser
+	}
+	return defaultEventFilterMaxPerUser
+}
+
+// maxMatches 每个过滤器保留的匹配记录数
+func (s *eventFilterService) maxMatches() int {
+	if s.config.BSC.EventFilters.MaxMatches > 0 {
+		return s.config.BSC.EventFilters.MaxMatches
+	}
+	return defaultEventFilterMaxMatches
+}
+
+// applyEventFilterRequest 校验请求并更新过滤器，地址和topic统一为小写十六进制
+func applyEventFilterRequest(filter *model.EventFilter, req *model.EventFilterRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if len(name) > maxEventFilterName {
+		return fmt.Errorf("%w: name exceeds %d characters", ErrInvalidParameter, maxEventFilterName)
+	}
+
+	contract := strings.TrimSpace(req.Contract)
+	if !common.IsHexAddress(contract) {
+		return fmt.Errorf("%w: invalid contract address %q", ErrInvalidParameter, req.Contract)
+	}
+
+	event, topic0, err := parseEventSignature(req.Event)
+	if err != nil {
+		return err
+	}
+
+	if len(req.Topics) > maxEventFilterTopics {
+		return fmt.Errorf("%w: at most %d topics besides the event signature", ErrInvalidParameter, maxEventFilterTopics)
+	}
+	var topics [][]string
+	for i, values := range req.Topics {
+		if len(values) > maxEventFilterTopicValues {
+			return fmt.Errorf("%w: at most %d values for topic%d", ErrInvalidParameter, maxEventFilterTopicValues, i+1)
+		}
+		normalized := make([]string, 0, len(values))
+		for _, value := range values {
+			topic, err := parseTopicValue(value)
+			if err != nil {
+				return fmt.Errorf("%w: invalid topic%d value %q", ErrInvalidParameter, i+1, value)
+			}
+			normalized = append(normalized, topic.Hex())
+		}
+		topics = append(topics, normalized)
+	}
+	// 末尾不限制的位置不需要保存
+	for len(topics) > 0 && len(topics[len(topics)-1]) == 0 {
+		topics = topics[:len(topics)-1]
+	}
+
+	webhookURL := strings.TrimSpace(req.WebhookURL)
+	if webhookURL != "" {
+		if err := validateWebhookURL(webhookURL); err != nil {
+			return err
+		}
+	}
+
+	filter.Name = name
+	filter.Contract = strings.ToLower(common.HexToAddress(contract).Hex())
+	filter.Event = event
+	filter.Topic0 = topic0.Hex()
+	filter.Topics = topics
+	filter.WebhookURL = webhookURL
+	filter.WebhookSecret = req.WebhookSecret
+	filter.Stream = req.Stream == nil || *req.Stream
+	filter.Enabled = req.Enabled == nil || *req.Enabled
+	return nil
+}
+
+// parseEventSignature 解析事件签名，也可直接传入32字节的签名哈希
+func parseEventSignature(value string) (string, common.Hash, error) {
+	event := strings.Join(strings.Fields(value), "")
+	if hash, err := hexutil.Decode(event); err == nil && len(hash) == common.HashLength {
+		topic0 := common.BytesToHash(hash)
+		return topic0.Hex(), topic0, nil
+	}
+	if !eventSignaturePattern.MatchString(event) {
+		return "", common.Hash{}, fmt.Errorf("%w: invalid event signature %q, expected a form like Transfer(address,address,uint256)", ErrInvalidParameter, value)
+	}
+	return event, crypto.Keccak256Hash([]byte(event)), nil
+}
+
+// parseTopicValue 解析topic值，地址左侧补零为32字节
+func parseTopicValue(value string) (common.Hash, error) {
+	data, err := hexutil.Decode(strings.TrimSpace(value))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	switch len(data) {
+	case common.AddressLength, common.HashLength:
+		return common.BytesToHash(data), nil
+	default:
+		return common.Hash{}, fmt.Errorf("expected 20 or 32 bytes, got %d", len(data))
+	}
+}
+
+// validateWebhookURL 只允许http(s)地址，注册时拒绝明显的本地和内网地址以便及早提示
+// 域名解析到的地址在每次投递时由httpclient.NewPublic检查，避免用户通过webhook访问内部服务
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: webhook_url must be an http(s) url", ErrInvalidParameter)
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("%w: webhook_url must not point to a local or private address", ErrInvalidParameter)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !httpclient.IsPublicAddr(addr) {
+		return fmt.Errorf("%w: webhook_url must not point to a local or private address", ErrInvalidParameter)
+	}
+	return nil
+}
+
+// compileEventFilter 将保存的过滤器转换为匹配条件
+func compileEventFilter(filter *model.EventFilter) (*compiledEventFilter, error) {
+	if !common.IsHexAddress(filter.Contract) {
+		return nil, fmt.Errorf("invalid contract address %q", filter.Contract)
+	}
+	compiled := &compiledEventFilter{
+		filter:   filter,
+		contract: common.HexToAddress(filter.Contract),
+		topic0:   common.HexToHash(filter.Topic0),
+		topics:   make([][]common.Hash, len(filter.Topics)),
+	}
+	for i, values := range filter.Topics {
+		for _, value := range values {
+			compiled.topics[i] = append(compiled.topics[i], common.HexToHash(value))
+		}
+	}
+	return compiled, nil
+}
+
+// match 日志是否满足过滤条件，条件为空的topic位置匹配任意值，日志缺少有条件的位置时不匹配
+func (f *compiledEventFilter) match(entry *types.Log) bool {
+	if entry.Address != f.contract || entry.Topics[0] != f.topic0 {
+		return false
+	}
+	for i, values := range f.topics {
+		if len(values) == 0 {
+			continue
+		}
+		if i+1 >= len(entry.Topics) {
+			return false
+		}
+		found := false
+		for _, value := range values {
+			if entry.Topics[i+1] == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// buildEventFilterQuery 合并所有过滤器的合约和事件签名，topic条件在本地匹配
+func buildEventFilterQuery(filters []*compiledEventFilter, from, to uint64) ethereum.FilterQuery {
+	addresses := make(map[common.Address]bool)
+	topics := make(map[common.Hash]bool)
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Topics:    [][]common.Hash{{}},
+	}
+	for _, filter := range filters {
+		if !addresses[filter.contract] {
+			addresses[filter.contract] = true
+			query.Addresses = append(query.Addresses, filter.contract)
+		}
+		if !topics[filter.topic0] {
+			topics[filter.topic0] = true
+			query.Topics[0] = append(query.Topics[0], filter.topic0)
+		}
+	}
+	return query
+}
+
+// newEventMatch 由日志生成匹配记录
+func newEventMatch(filter *model.EventFilter, entry *types.Log) *model.EventMatch {
+	topics := make([]string, len(entry.Topics))
+	for i, topic := range entry.Topics {
+		topics[i] = topic.Hex()
+	}
+	return &model.EventMatch{
+		ID:          entry.TxHash.Hex() + ":" + strconv.FormatUint(uint64(entry.Index), 10),
+		FilterID:    filter.ID,
+		FilterName:  filter.Name,
+		Contract:    strings.ToLower(entry.Address.Hex()),
+		Event:       filter.Event,
+		BlockNumber: entry.BlockNumber,
+		BlockHash:   entry.BlockHash.Hex(),
+		TxHash:      entry.TxHash.Hex(),
+		LogIndex:    entry.Index,
+		Topics:      topics,
+		Data:        hexutil.Encode(entry.Data),
+		MatchedAt:   time.Now(),
+	}
+}
+
+// redactEventFilter 响应中不返回webhook密钥，只标记是否设置
+func redactEventFilter(filter *model.EventFilter) *model.EventFilter {
+	redacted := *filter
+	redacted.WebhookSigned = filter.WebhookSecret != ""
+	redacted.WebhookSecret = ""
+	return &redacted
+}
+
+// eventFilterKey 用户过滤器存储key
+func eventFilterKey(userID string) string {
+	return eventFilterKeyPrefix + userID
+}
+
+// eventFilterMatchesKey 过滤器匹配记录存储key
+func eventFilterMatchesKey(id string) string {
+	return eventFilterMatchesKeyPrefix + id
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultFanOutConcurrency 并发扇出的默认协程数
+const defaultFanOutConcurrency = 4
+
+// fanOutResult 单个任务的执行结果
+type fanOutResult[T any] struct {
+	Key   string
+	Value T
+	Err   error
+}
+
+// fanOut 使用有界协程池并发执行任务，结果顺序与keys保持一致
+func fanOut[T any](ctx context.Context, keys []string, concurrency int, fn func(ctx context.Context, key string) (T, error)) []fanOutResult[T] {
+	if concurrency <= 0 {
+		concurrency = defaultFanOutConcurrency
+	}
+
+	results := make([]fanOutResult[T], len(keys))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			results[i].Key = key
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i].Err = ctx.Err()
+				return
+			}
+
+			results[i].Value, results[i].Err = fn(ctx, key)
+		}(i, key)
+	}
+	wg.Wait()
+
+	return results
+}
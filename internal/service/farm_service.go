@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 挖矿池默认配置
+const (
+	defaultFarmCacheTTL      = 10 * time.Minute
+	defaultFarmBlocksPerYear = 10512000 // 按3秒出块估算
+	defaultFarmMaxPools      = 500
+	defaultFarmConcurrency   = 8
+	defaultFarmRewardMethod  = "cakePerBlock"
+	defaultFarmLimit         = 50
+	farmCacheKeyPrefix       = "farms:"
+)
+
+// 挖矿池排序字段
+const (
+	FarmSortAPR    = "apr"
+	FarmSortTVL    = "tvl"
+	FarmSortWeight = "weight"
+)
+
+// masterChefABITemplate MasterChef合约ABI，%s为每区块奖励的读取方法
+const masterChefABITemplate = `[
+	{"constant": true, "inputs": [], "name": "poolLength", "outputs": [{"name": "", "type": "uint256"}], "type": "function"},
+	{"constant": true, "inputs": [], "name": "totalAllocPoint", "outputs": [{"name": "", "type": "uint256"}], "type": "function"},
+	{"constant": true, "inputs": [], "name": "%s", "outputs": [{"name": "", "type": "uint256"}], "type": "function"},
+	{"constant": true, "inputs": [{"name": "", "type": "uint256"}], "name": "poolInfo", "outputs": [
+		{"name": "lpToken", "type": "address"},
+		{"name": "allocPoint", "type": "uint256"},
+		{"name": "lastRewardBlock", "type": "uint256"},
+		{"name": "accCakePerShare", "type": "uint256"}
+	], "type": "function"}
+]`
+
+// FarmService 流动性挖矿APR服务接口
+type FarmService interface {
+	GetFarms(ctx context.Context, chef, sortBy, order string, limit int) (*model.FarmListResponse, error)
+}
+
+// farmService 读取MasterChef风格合约，根据奖励发放速度与池TVL计算APR
+type farmService struct {
+	redisClient  database.RedisClient
+	config       *config.Config
+	priceService PriceService
+	reader       *contractReader
+	logger       logger.Logger
+}
+
+// cachedFarms 单个挖矿合约的计算结果缓存
+type cachedFarms struct {
+	Pools     []model.FarmPool `json:"pools"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// farmPoolInfo 从链上读取的池原始数据
+type farmPoolInfo struct {
+	pid        int
+	stakeToken chainToken
+	allocPoint uint64
+	staked     float64
+	pair       *pairState // 单币质押池为nil
+}
+
+// NewFarmService 创建挖矿池服务，priceService用于为基础代币定价，可为nil
+func NewFarmService(redisClient database.RedisClient, cfg *config.Config, bscService BSCService, priceService PriceService) FarmService {
+	return &farmService{
+		redisClient:  redisClient,
+		config:       cfg,
+		priceService: priceService,
+		reader:       newContractReader(bscService),
+		logger:       logger.GetLogger(),
+	}
+}
+
+// GetFarms 获取挖矿池APR与TVL，支持按apr、tvl、weight排序
+func (s *farmService) GetFarms(ctx context.Context, chef, sortBy, order string, limit int) (*model.FarmListResponse, error) {
+	if !s.config.BSC.Farms.Enabled {
+		return nil, fmt.Errorf("%w: farm tracking is disabled", ErrNotFound)
+	}
+	if sortBy == "" {
+		sortBy = FarmSortAPR
+	}
+	if sortBy != FarmSortAPR && sortBy != FarmSortTVL && sortBy != FarmSortWeight {
+		return nil, fmt.Errorf("%w: unsupported sort field %q", ErrInvalidParameter, sortBy)
+	}
+	if order == "" {
+		order = "desc"
+	}
+	if order != "asc" && order != "desc" {
+		return nil, fmt.Errorf("%w: unsupported order %q", ErrInvalidParameter, order)
+	}
+	if limit <= 0 {
+		limit = defaultFarmLimit
+	}
+
+	resp := &model.FarmListResponse{
+		Farms:  []model.FarmPool{},
+		SortBy: sortBy,
+		Order:  order,
+	}
+	found := false
+	for _, contract := range s.config.BSC.Farms.Chefs {
+		if chef != "" && contract.Name != chef {
+			continue
+		}
+		found = true
+
+		farms, err := s.loadChef(ctx, contract)
+		if err != nil {
+			return nil, err
+		}
+		resp.Farms = append(resp.Farms, farms.Pools...)
+		if resp.UpdatedAt.IsZero() || farms.UpdatedAt.Before(resp.UpdatedAt) {
+			resp.UpdatedAt = farms.UpdatedAt
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: unknown farm contract %q", ErrNotFound, chef)
+	}
+
+	sortFarms(resp.Farms, sortBy, order == "asc")
+	resp.Total = len(resp.Farms)
+	if len(resp.Farms) > limit {
+		resp.Farms = resp.Farms[:limit]
+	}
+	return resp, nil
+}
+
+// sortFarms 按指定字段排序，无法计算APR的池始终排在最后
+func sortFarms(pools []model.FarmPool, sortBy string, asc bool) {
+	value := func(p model.FarmPool) float64 {
+		switch sortBy {
+		case FarmSortTVL:
+			return p.TVL
+		case FarmSortWeight:
+			return p.Weight
+		default:
+			if p.APR == nil {
+				return math.NaN()
+			}
+			return *p.APR
+		}
+	}
+
+	sort.SliceStable(pools, func(i, j int) bool {
+		vi, vj := value(pools[i]), value(pools[j])
+		if math.IsNaN(vi) || math.IsNaN(vj) {
+			return !math.IsNaN(vi) && math.IsNaN(vj)
+		}
+		if asc {
+			return vi < vj
+		}
+		return vi > vj
+	})
+}
+
+// loadChef 读取单个挖矿合约的池数据，结果在缓存时间内复用
+func (s *farmService) loadChef(ctx context.Context, contract config.FarmContract) (*cachedFarms, error) {
+	cacheKey := farmCacheKeyPrefix + contract.Name
+	if s.redisClient != nil {
+		if data, err := s.redisClient.Get(ctx, cacheKey); err == nil && data != "" {
+			var cached cachedFarms
+			if err := json.Unmarshal([]byte(data), &cached); err == nil {
+				return &cached, nil
+			}
+		}
+	}
+
+	pools, err := s.computeChef(ctx, contract)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read farm contract %s: %w", ErrUpstreamUnavailable, contract.Name, err)
+	}
+	farms := &cachedFarms{Pools: pools, UpdatedAt: time.Now()}
+
+	if s.redisClient != nil {
+		if data, err := json.Marshal(farms); err == nil {
+			if err := s.redisClient.Set(ctx, cacheKey, data, s.cacheTTL()); err != nil {
+				s.logger.Warnf("Failed to cache farms for %s: %v", contract.Name, err)
+			}
+		}
+	}
+	return farms, nil
+}
+
+// computeChef 读取合约全局参数和各池数据，计算TVL与APR
+func (s *farmService) computeChef(ctx context.Context, contract config.FarmContract) ([]model.FarmPool, error) {
+	rewardMethod := contract.RewardMethod
+	if rewardMethod == "" {
+		rewardMethod = defaultFarmRewardMethod
+	}
+	chefABI, err := abi.JSON(strings.NewReader(fmt.Sprintf(masterChefABITemplate, rewardMethod)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid reward method %q: %w", rewardMethod, err)
+	}
+	chefAddress := common.HexToAddress(contract.Address)
+
+	totalAlloc, err := s.reader.callUint(ctx, chefABI, chefAddress, "totalAllocPoint")
+	if err != nil {
+		return nil, err
+	}
+	rewardPerBlock, err := s.reader.callUint(ctx, chefABI, chefAddress, rewardMethod)
+	if err != nil {
+		return nil, err
+	}
+	poolLength, err := s.reader.callUint(ctx, chefABI, chefAddress, "poolLength")
+	if err != nil {
+		return nil, err
+	}
+	rewardToken, err := s.reader.readToken(ctx, common.HexToAddress(contract.RewardToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reward token: %w", err)
+	}
+
+	count := int(poolLength.Int64())
+	if count > s.maxPools() {
+		count = s.maxPools()
+	}
+	pids := make([]string, count)
+	for i := range pids {
+		pids[i] = strconv.Itoa(i)
+	}
+
+	var infos []*farmPoolInfo
+	results := fanOut(ctx, pids, s.concurrency(), func(ctx context.Context, key string) (*farmPoolInfo, error) {
+		pid, _ := strconv.Atoi(key)
+		return s.readPool(ctx, chefABI, chefAddress, pid)
+	})
+	for _, result := range results {
+		if result.Err != nil {
+			s.logger.Debugf("Skipping pool %s of %s: %v", result.Key, contract.Name, result.Err)
+			continue
+		}
+		if result.Value != nil {
+			infos = append(infos, result.Value)
+		}
+	}
+
+	prices := s.discoverPrices(ctx, infos, rewardToken)
+	rewardPrice := prices[rewardToken.address]
+	rewardPerYear := toFloat(rewardPerBlock, rewardToken.decimals) * float64(s.blocksPerYear())
+
+	pools := make([]model.FarmPool, 0, len(infos))
+	for _, info := range infos {
+		pool := model.FarmPool{
+			Chef:        contract.Name,
+			PID:         info.pid,
+			Name:        info.stakeToken.symbol,
+			StakeToken:  info.stakeToken.address.Hex(),
+			AllocPoint:  info.allocPoint,
+			RewardPrice: rewardPrice,
+		}
+		if totalAlloc.Sign() > 0 {
+			pool.Weight = float64(info.allocPoint) / float64(totalAlloc.Uint64())
+		}
+		pool.RewardPerYear = rewardPerYear * pool.Weight
+
+		if info.pair != nil {
+			pool.Name = info.pair.tokens[0].symbol + "-" + info.pair.tokens[1].symbol
+			pool.Token0 = info.pair.tokens[0].address.Hex()
+			pool.Token1 = info.pair.tokens[1].address.Hex()
+		}
+		pool.TVL = poolTVL(info, prices)
+		pool.Priced = pool.TVL > 0
+
+		if pool.Priced && rewardPrice > 0 {
+			apr := pool.RewardPerYear * rewardPrice / pool.TVL * 100
+			pool.APR = &apr
+		}
+		pools = append(pools, pool)
+	}
+
+	s.logger.Infof("Computed %d active farms for %s", len(pools), contract.Name)
+	return pools, nil
+}
+
+// readPool 读取池配置及质押代币信息，未分配奖励的池返回nil
+func (s *farmService) readPool(ctx context.Context, chefABI abi.ABI, chef common.Address, pid int) (*farmPoolInfo, error) {
+	out, err := s.reader.call(ctx, chefABI, chef, "poolInfo", big.NewInt(int64(pid)))
+	if err != nil {
+		return nil, err
+	}
+	stakeAddress := out[0].(common.Address)
+	allocPoint := out[1].(*big.Int)
+	if allocPoint.Sign() == 0 {
+		return nil, nil
+	}
+
+	stakeToken, err := s.reader.readToken(ctx, stakeAddress)
+	if err != nil {
+		return nil, err
+	}
+	staked, err := s.reader.balanceOf(ctx, stakeToken, chef)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &farmPoolInfo{
+		pid:        pid,
+		stakeToken: stakeToken,
+		allocPoint: allocPoint.Uint64(),
+		staked:     staked,
+	}
+
+	// 单币质押池不是交易对合约
+	if pair, err := s.reader.readPair(ctx, stakeToken); err == nil {
+		info.pair = pair
+	}
+	return info, nil
+}
+
+// discoverPrices 为基础代币定价，再通过LP储备比例推导其余代币价格
+func (s *farmService) discoverPrices(ctx context.Context, infos []*farmPoolInfo, rewardToken chainToken) map[common.Address]float64 {
+	pricer := newUSDPricer(s.priceService)
+	prices := make(map[common.Address]float64)
+	seed := func(token chainToken) {
+		if _, seen := prices[token.address]; seen {
+			return
+		}
+		price, _ := pricer.price(ctx, token.symbol)
+		prices[token.address] = price
+	}
+
+	seed(rewardToken)
+	var pairs []*pairState
+	for _, info := range infos {
+		if info.pair == nil {
+			seed(info.stakeToken)
+			continue
+		}
+		seed(info.pair.tokens[0])
+		seed(info.pair.tokens[1])
+		pairs = append(pairs, info.pair)
+	}
+
+	derivePrices(pairs, prices)
+	return prices
+}
+
+// poolTVL 计算池锁仓价值
+func poolTVL(info *farmPoolInfo, prices map[common.Address]float64) float64 {
+	if info.pair == nil {
+		return info.staked * prices[info.stakeToken.address]
+	}
+	if info.pair.totalSupply <= 0 {
+		return 0
+	}
+	return info.staked * info.pair.value(prices) / info.pair.totalSupply
+}
+
+// cacheTTL 计算结果缓存时间
+func (s *farmService) cacheTTL() time.Duration {
+	if s.config.BSC.Farms.CacheTTL > 0 {
+		return s.config.BSC.Farms.CacheTTL
+	}
+	return defaultFarmCacheTTL
+}
+
+// blocksPerYear 每年出块数
+func (s *farmService) blocksPerYear() int64 {
+	if s.config.BSC.Farms.BlocksPerYear > 0 {
+		return s.config.BSC.Farms.BlocksPerYear
+	}
+	return defaultFarmBlocksPerYear
+}
+
+// maxPools 每个合约最多读取的池数量
+func (s *farmService) maxPools() int {
+	if s.config.BSC.Farms.MaxPools > 0 {
+		return s.config.BSC.Farms.MaxPools
+	}
+	return defaultFarmMaxPools
+}
+
+// concurrency 读取池信息的并发数
+func (s *farmService) concurrency() int {
+	if s.config.BSC.Farms.Concurrency > 0 {
+		return s.config.BSC.Farms.Concurrency
+	}
+	return defaultFarmConcurrency
+}
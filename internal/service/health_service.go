@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+)
+
+// 就绪探测默认配置
+const (
+	defaultProbeTimeout  = 3 * time.Second
+	defaultLatencyBudget = 800 * time.Millisecond
+	defaultHealthTTL     = 5 * time.Second
+	defaultProbeInterval = 30 * time.Second
+)
+
+// 被探测的依赖名称
+const (
+	dependencyRedis    = "redis"
+	dependencyMySQL    = "mysql"
+	dependencyHuobi    = "huobi"
+	dependencyBinance  = "binance"
+	dependencyOKX      = "okx"
+	dependencyCoinbase = "coinbase"
+	dependencyKraken   = "kraken"
+	dependencyBSC      = "bsc"
+
+	// workerDependencyPrefix 后台循环在就绪结果中的名称前缀
+	workerDependencyPrefix = "worker:"
+)
+
+// upstreamDependencies 行情数据来源，全部不可用时实例判定为down
+var upstreamDependencies = map[string]bool{
+	dependencyHuobi:    true,
+	dependencyBinance:  true,
+	dependencyOKX:      true,
+	dependencyCoinbase: true,
+	dependencyKraken:   true,
+	dependencyBSC:      true,
+}
+
+// errDependencyDegraded 依赖可用但工作异常，探测结果标记为degraded而不是down
+var errDependencyDegraded = errors.New("degraded")
+
+// HealthService 依赖健康探测服务接口
+type HealthService interface {
+	// Readiness 探测Redis、MySQL和上游API的可用性与延迟，结果短时间缓存
+	Readiness(ctx context.Context) *model.ReadinessResponse
+	// Snapshot 最近一次探测结果，不发起探测，尚未探测时返回空
+	Snapshot() *model.ReadinessResponse
+	// Start 启动后台定时探测，启用负载降级时才需要
+	Start(ctx context.Context) error
+	// Stop 停止后台探测
+	Stop() error
+}
+
+// healthService 依赖健康探测服务实现
+type healthService struct {
+	redisClient database.RedisClient
+	mysqlClient database.MySQLClient // 未启用MySQL或连接失败时为空
+	config      *config.Config
+	logger      logger.Logger
+	bscService  BSCService
+	httpClients map[string]*http.Client // 按上游分别创建，使用各自的代理配置
+	checks      []string
+
+	mu        sync.Mutex
+	cached    *model.ReadinessResponse
+	expiresAt time.Time
+
+	runMutex sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewHealthService 创建依赖健康探测服务，未配置地址的上游和未启用的MySQL不参与探测
+func NewHealthService(redisClient database.RedisClient, mysqlClient database.MySQLClient, cfg *config.Config, bscService BSCService) HealthService {
+	s := &healthService{
+		redisClient: redisClient,
+		mysqlClient: mysqlClient,
+		config:      cfg,
+		logger:      logger.GetLogger(),
+		bscService:  bscService,
+		httpClients: make(map[string]*http.Client),
+	}
+
+	s.checks = append(s.checks, dependencyRedis)
+	if cfg.Database.MySQL.Enabled {
+		s.checks = append(s.checks, dependencyMySQL)
+	}
+	if cfg.ExternalAPI.Huobi.BaseURL != "" {
+		s.checks = append(s.checks, dependencyHuobi)
+		s.httpClients[dependencyHuobi] = httpclient.New(httpclient.Options{Timeout: s.probeTimeout(), Proxy: cfg.ExternalAPI.Huobi.Proxy})
+	}
+	if cfg.ExternalAPI.Binance.BaseURL != "" {
+		s.checks = append(s.checks, dependencyBinance)
+		s.httpClients[dependencyBinance] = httpclient.New(httpclient.Options{Timeout: s.probeTimeout(), Proxy: cfg.ExternalAPI.Binance.Proxy})
+	}
+	if cfg.ExternalAPI.OKX.BaseURL != "" {
+		s.checks = append(s.checks, dependencyOKX)
+		s.httpClients[dependencyOKX] = httpclient.New(httpclient.Options{Timeout: s.probeTimeout(), Proxy: cfg.ExternalAPI.OKX.Proxy})
+	}
+	if cfg.ExternalAPI.Coinbase.BaseURL != "" {
+		s.checks = append(s.checks, dependencyCoinbase)
+		s.httpClients[dependencyCoinbase] = httpclient.New(httpclient.Options{Timeout: s.probeTimeout(), Proxy: cfg.ExternalAPI.Coinbase.Proxy})
+	}
+	if cfg.ExternalAPI.Kraken.BaseURL != "" {
+		s.checks = append(s.checks, dependencyKraken)
+		s.httpClients[dependencyKraken] = httpclient.New(httpclient.Options{Timeout: s.probeTimeout(), Proxy: cfg.ExternalAPI.Kraken.Proxy})
+	}
+	if cfg.BSC.Enabled {
+		s.checks = append(s.checks, dependencyBSC)
+	}
+	return s
+}
+
+// Readiness 探测全部依赖，缓存期内直接返回上次结果，并发请求共享同一次探测
+func (s *healthService) Readiness(ctx context.Context) *model.ReadinessResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && time.Now().Before(s.expiresAt) {
+		return s.cached
+	}
+
+	// 探测结果被多个请求共享，不随单个探针请求取消
+	ctx = context.WithoutCancel(ctx)
+	budget := s.latencyBudget()
+	results := fanOut(ctx, s.checks, len(s.checks), func(ctx context.Context, name string) (model.DependencyHealth, error) {
+		return s.probe(ctx, name, budget), nil
+	})
+
+	resp := &model.ReadinessResponse{
+		Dependencies: make([]model.DependencyHealth, 0, len(results)),
+		CheckedAt:    time.Now(),
+	}
+	for _, r := range results {
+		dep := r.Value
+		if r.Err != nil {
+			dep = model.DependencyHealth{Name: r.Key, Status: model.HealthStatusDown, BudgetMs: budget.Milliseconds(), Error: r.Err.Error()}
+		}
+		resp.Dependencies = append(resp.Dependencies, dep)
+	}
+	resp.Dependencies = append(resp.Dependencies, unhealthyWorkers()...)
+	resp.Status = overallStatus(resp.Dependencies)
+
+	s.cached = resp
+	s.expiresAt = time.Now().Add(s.cacheTTL())
+	return resp
+}
+
+// Snapshot 最近一次探测结果，可能已超出缓存时间
+func (s *healthService) Snapshot() *model.ReadinessResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cached
+}
+
+// Start 启动后台定时探测，使负载降级不依赖外部探针的调用频率
+func (s *healthService) Start(ctx context.Context) error {
+	if !s.config.Server.HTTP.LoadShedding.Enabled {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return errors.New("health prober is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "health_prober", s.run, s.done)
+
+	s.logger.Infof("Health prober started with interval %s", s.probeInterval())
+	return nil
+}
+
+// Stop 停止后台探测
+func (s *healthService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	s.logger.Info("Health prober stopped")
+	return nil
+}
+
+// run 按间隔探测依赖，结果供Readiness和Snapshot使用
+func (s *healthService) run(ctx context.Context) {
+	ticker := time.NewTicker(s.probeInterval())
+	defer ticker.Stop()
+
+	for {
+		s.Readiness(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe 探测单个依赖并按延迟预算评估状态
+func (s *healthService) probe(ctx context.Context, name string, budget time.Duration) model.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, s.probeTimeout())
+	defer cancel()
+
+	start := time.Now()
+	err := s.check(ctx, name)
+	latency := time.Since(start)
+
+	dep := model.DependencyHealth{
+		Name:      name,
+		Status:    model.HealthStatusOK,
+		LatencyMs: latency.Milliseconds(),
+		BudgetMs:  budget.Milliseconds(),
+	}
+	switch {
+	case errors.Is(err, errDependencyDegraded):
+		dep.Status = model.HealthStatusDegraded
+		dep.Error = err.Error()
+	case err != nil:
+		dep.Status = model.HealthStatusDown
+		dep.Error = err.Error()
+	case latency > budget:
+		dep.Status = model.HealthStatusDegraded
+	}
+	return dep
+}
+
+// check 对依赖发起一次最轻量的请求
+func (s *healthService) check(ctx context.Context, name string) error {
+	var discard json.RawMessage
+	switch name {
+	case dependencyRedis:
+		if s.redisClient == nil {
+			return errors.New("redis client not initialized")
+		}
+		return s.redisClient.Ping(ctx)
+	case dependencyMySQL:
+		if s.mysqlClient == nil {
+			return errors.New("mysql client not initialized")
+		}
+		return s.mysqlClient.Ping(ctx)
+	case dependencyHuobi:
+		return httpclient.GetJSON(ctx, s.httpClients[name], strings.TrimRight(s.config.ExternalAPI.Huobi.BaseURL, "/")+"/v1/common/timestamp", &discard)
+	case dependencyBinance:
+		return httpclient.GetJSON(ctx, s.httpClients[name], strings.TrimRight(s.config.ExternalAPI.Binance.BaseURL, "/")+"/api/v3/ping", &discard)
+	case dependencyOKX:
+		return httpclient.GetJSON(ctx, s.httpClients[name], strings.TrimRight(s.config.ExternalAPI.OKX.BaseURL, "/")+"/api/v5/public/time", &discard)
+	case dependencyCoinbase:
+		return httpclient.GetJSON(ctx, s.httpClients[name], strings.TrimRight(s.config.ExternalAPI.Coinbase.BaseURL, "/")+"/time", &discard)
+	case dependencyKraken:
+		return httpclient.GetJSON(ctx, s.httpClients[name], strings.TrimRight(s.config.ExternalAPI.Kraken.BaseURL, "/")+"/0/public/Time", &discard)
+	case dependencyBSC:
+		if s.bscService == nil {
+			return errors.New("BSC service not initialized")
+		}
+		if _, err := s.bscService.BlockNumber(ctx); err != nil {
+			return err
+		}
+		// 节点可用但区块监控落后或停滞
+		if stats := s.bscService.GetStatus().Stats; stats.Lagging {
+			return fmt.Errorf("%w: block monitoring is %s", errDependencyDegraded, stats.LagReason)
+		}
+		return nil
+	}
+	return nil
+}
+
+// unhealthyWorkers panic后正在重启或刚恢复不久的后台循环，标记为degraded
+func unhealthyWorkers() []model.DependencyHealth {
+	var deps []model.DependencyHealth
+	for _, w := range panics.Workers() {
+		if w.Healthy {
+			continue
+		}
+		deps = append(deps, model.DependencyHealth{
+			Name:   workerDependencyPrefix + w.Source,
+			Status: model.HealthStatusDegraded,
+			Error:  fmt.Sprintf("panicked %d times, last at %s: %s", w.Panics, w.LastPanic.Format(time.RFC3339), w.LastError),
+		})
+	}
+	return deps
+}
+
+// overallStatus 汇总依赖状态：行情来源全部不可用时为down，任一依赖异常或超出预算时为degraded
+func overallStatus(deps []model.DependencyHealth) string {
+	status := model.HealthStatusOK
+	upstreams, upstreamsDown := 0, 0
+	for _, dep := range deps {
+		if upstreamDependencies[dep.Name] {
+			upstreams++
+			if dep.Status == model.HealthStatusDown {
+				upstreamsDown++
+			}
+		}
+		if dep.Status != model.HealthStatusOK {
+			status = model.HealthStatusDegraded
+		}
+	}
+	if upstreams > 0 && upstreamsDown == upstreams {
+		return model.HealthStatusDown
+	}
+	return status
+}
+
+// probeTimeout 单个依赖的探测超时
+func (s *healthService) probeTimeout() time.Duration {
+	if s.config.Monitoring.HealthCheck.ProbeTimeout > 0 {
+		return s.config.Monitoring.HealthCheck.ProbeTimeout
+	}
+	return defaultProbeTimeout
+}
+
+// latencyBudget 依赖延迟预算
+func (s *healthService) latencyBudget() time.Duration {
+	if s.config.Monitoring.HealthCheck.LatencyBudget > 0 {
+		return s.config.Monitoring.HealthCheck.LatencyBudget
+	}
+	return defaultLatencyBudget
+}
+
+// probeInterval 后台探测间隔
+func (s *healthService) probeInterval() time.Duration {
+	if s.config.Monitoring.HealthCheck.Interval > 0 {
+		return s.config.Monitoring.HealthCheck.Interval
+	}
+	return defaultProbeInterval
+}
+
+// cacheTTL 探测结果缓存时间
+func (s *healthService) cacheTTL() time.Duration {
+	if s.config.Monitoring.HealthCheck.CacheTTL > 0 {
+		return s.config.Monitoring.HealthCheck.CacheTTL
+	}
+	return defaultHealthTTL
+}
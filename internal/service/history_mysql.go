@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+)
+
+// mysqlHistorySchema 价格历史表，首次使用时创建；主键按币种和时间排列，范围查询和清理都走主键
+const mysqlHistorySchema = `CREATE TABLE IF NOT EXISTS price_history (
+	symbol VARCHAR(32) NOT NULL,
+	ts BIGINT NOT NULL COMMENT '毫秒时间戳',
+	price DOUBLE NOT NULL,
+	source VARCHAR(32) NOT NULL DEFAULT '',
+	PRIMARY KEY (symbol, ts)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`
+
+// mysqlHistoryAggregate 在数据库中按间隔聚合，开盘价和收盘价取桶内第一个和最后一个采样
+const mysqlHistoryAggregate = `SELECT b.bucket, o.price AS open, b.high, b.low, c.price AS close, b.samples
+FROM (
+	SELECT (ts DIV ?) * ? AS bucket, MIN(ts) AS first_ts, MAX(ts) AS last_ts,
+		MAX(price) AS high, MIN(price) AS low, COUNT(*) AS samples
+	FROM price_history
+	WHERE symbol = ? AND ts BETWEEN ? AND ?
+	GROUP BY bucket
+) b
+JOIN price_history o ON o.symbol = ? AND o.ts = b.first_ts
+JOIN price_history c ON c.symbol = ? AND c.ts = b.last_ts
+ORDER BY b.bucket`
+
+// mysqlHistoryStore 使用MySQL表存储价格采样，适合较长的保留时间；同一币种同一毫秒的采样只保留最后一个
+type mysqlHistoryStore struct {
+	mysqlClient database.MySQLClient
+
+	schemaMu    sync.Mutex
+	schemaReady bool
+}
+
+// mysqlHistoryBucket 聚合查询的结果行
+type mysqlHistoryBucket struct {
+	Bucket  int64   `db:"bucket"`
+	Open    float64 `db:"open"`
+	High    float64 `db:"high"`
+	Low     float64 `db:"low"`
+	Close   float64 `db:"close"`
+	Samples int     `db:"samples"`
+}
+
+// newMySQLHistoryStore 创建MySQL价格历史存储
+func newMySQLHistoryStore(mysqlClient database.MySQLClient) *mysqlHistoryStore {
+	return &mysqlHistoryStore{mysqlClient: mysqlClient}
+}
+
+// ensureSchema 创建价格历史表，失败时下次调用重试
+func (m *mysqlHistoryStore) ensureSchema(ctx context.Context) error {
+	m.schemaMu.Lock()
+	defer m.schemaMu.Unlock()
+
+	if m.schemaReady {
+		return nil
+	}
+	if _, err := m.mysqlClient.DB().ExecContext(ctx, mysqlHistorySchema); err != nil {
+		return fmt.Errorf("failed to create price_history table: %w", err)
+	}
+	m.schemaReady = true
+	return nil
+}
+
+// add 保存采样
+func (m *mysqlHistoryStore) add(ctx context.Context, symbol string, sample priceSample) error {
+	if err := m.ensureSchema(ctx); err != nil {
+		return err
+	}
+	_, err := m.mysqlClient.DB().ExecContext(ctx,
+		`INSERT INTO price_history (symbol, ts, price, source) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE price = VALUES(price), source = VALUES(source)`,
+		symbol, sample.Timestamp, sample.Price, sample.Source)
+	return err
+}
+
+// load 读取时间范围内的采样
+func (m *mysqlHistoryStore) load(ctx context.Context, symbol string, from, to time.Time) ([]priceSample, error) {
+	if err := m.ensureSchema(ctx); err != nil {
+		return nil, err
+	}
+	samples := make([]priceSample, 0)
+	err := m.mysqlClient.DB().SelectContext(ctx, &samples,
+		`SELECT ts, price, source FROM price_history WHERE symbol = ? AND ts BETWEEN ? AND ? ORDER BY ts`,
+		symbol, from.UnixMilli(), to.UnixMilli())
+	return samples, err
+}
+
+// aggregate 在数据库中聚合，只返回数据点，不读取全部采样
+func (m *mysqlHistoryStore) aggregate(ctx context.Context, symbol string, from, to time.Time, step time.Duration) ([]model.PricePoint, error) {
+	if err := m.ensureSchema(ctx); err != nil {
+		return nil, err
+	}
+	stepMs := step.Milliseconds()
+	var buckets []mysqlHistoryBucket
+	if err := m.mysqlClient.DB().SelectContext(ctx, &buckets, mysqlHistoryAggregate,
+		stepMs, stepMs, symbol, from.UnixMilli(), to.UnixMilli(), symbol, symbol); err != nil {
+		return nil, err
+	}
+
+	points := make([]model.PricePoint, 0, len(buckets))
+	for _, b := range buckets {
+		points = append(points, model.PricePoint{
+			Timestamp: time.UnixMilli(b.Bucket).UTC(),
+			Open:      b.Open,
+			High:      b.High,
+			Low:       b.Low,
+			Close:     b.Close,
+			Samples:   b.Samples,
+		})
+	}
+	return points, nil
+}
+
+// prune 删除cutoff之前的采样
+func (m *mysqlHistoryStore) prune(ctx context.Context, symbol string, cutoff time.Time) error {
+	if err := m.ensureSchema(ctx); err != nil {
+		return err
+	}
+	_, err := m.mysqlClient.DB().ExecContext(ctx,
+		`DELETE FROM price_history WHERE symbol = ? AND ts < ?`, symbol, cutoff.UnixMilli())
+	return err
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/precision"
+)
+
+// historyIntervals 支持的聚合间隔
+var historyIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// 价格历史默认配置
+const (
+	defaultHistoryRetention = 30 * 24 * time.Hour
+	defaultHistoryMaxPoints = 1000
+	defaultHistoryMaxGap    = time.Hour
+	historyStorageMySQL     = "mysql"
+)
+
+// 按时间查询价格的解析方式
+const (
+	PriceAtNearest     = "nearest"
+	PriceAtInterpolate = "interpolate"
+)
+
+// HistoryService 价格历史服务接口
+type HistoryService interface {
+	RecordPrice(ctx context.Context, price *model.PriceResponse) error
+	GetPriceHistory(ctx context.Context, symbol, interval string, from, to time.Time) (*model.PriceHistoryResponse, error)
+	GetPriceAt(ctx context.Context, symbol string, at time.Time, method string) (*model.PriceAtResponse, error)
+	// Prune 清理所有支持币种超出保留期的数据，不再有新采样的币种只能由此清理
+	Prune(ctx context.Context) error
+}
+
+// historyService 价格历史服务实现，采样按 history.storage 保存在Redis有序集合或MySQL表中
+type historyService struct {
+	store  historyStore // 未配置存储时为空，不记录历史
+	config *config.Config
+}
+
+// historyStore 价格采样的存储
+type historyStore interface {
+	// add 保存一个采样
+	add(ctx context.Context, symbol string, sample priceSample) error
+	// load 读取时间范围内的采样，按时间升序
+	load(ctx context.Context, symbol string, from, to time.Time) ([]priceSample, error)
+	// aggregate 将时间范围内的采样按间隔聚合为OHLC数据点
+	aggregate(ctx context.Context, symbol string, from, to time.Time, step time.Duration) ([]model.PricePoint, error)
+	// prune 删除cutoff之前的采样
+	prune(ctx context.Context, symbol string, cutoff time.Time) error
+}
+
+// priceSample 价格采样
+type priceSample struct {
+	Timestamp int64   `json:"t" db:"ts"` // 毫秒时间戳
+	Price     float64 `json:"p" db:"price"`
+	Source    string  `json:"s,omitempty" db:"source"`
+}
+
+// NewHistoryService 创建价格历史服务，history.storage为mysql时使用mysqlClient，否则使用redisClient
+func NewHistoryService(redisClient database.RedisClient, mysqlClient database.MySQLClient, cfg *config.Config) HistoryService {
+	s := &historyService{config: cfg}
+	switch {
+	case cfg.History.Storage == historyStorageMySQL:
+		if mysqlClient == nil {
+			logger.GetLogger().Warn("Price history storage is mysql but MySQL is unavailable, history disabled")
+			break
+		}
+		s.store = newMySQLHistoryStore(mysqlClient)
+	case redisClient != nil:
+		s.store = &redisHistoryStore{redisClient: redisClient}
+	}
+	return s
+}
+
+// RecordPrice 记录价格采样，并清理超出保留期的数据
+func (s *historyService) RecordPrice(ctx context.Context, price *model.PriceResponse) error {
+	if s.store == nil || !s.config.History.Enabled || price == nil {
+		return nil
+	}
+
+	now := time.Now()
+	sample := priceSample{
+		Timestamp: now.UnixMilli(),
+		Price:     price.Price,
+		Source:    price.Source,
+	}
+	if err := s.store.add(ctx, price.Symbol, sample); err != nil {
+		return fmt.Errorf("failed to record price history: %w", err)
+	}
+
+	if err := s.store.prune(ctx, price.Symbol, now.Add(-s.retention())); err != nil {
+		logger.From(ctx).Warnf("Failed to trim price history for %s: %v", price.Symbol, err)
+	}
+
+	return nil
+}
+
+// Prune 清理所有支持币种超出保留期的数据
+func (s *historyService) Prune(ctx context.Context) error {
+	if s.store == nil || !s.config.History.Enabled {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-s.retention())
+	var errs []error
+	for _, symbol := range s.config.Business.SupportedSymbols {
+		if err := s.store.prune(ctx, symbol, cutoff); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetPriceHistory 获取按间隔聚合的价格历史
+func (s *historyService) GetPriceHistory(ctx context.Context, symbol, interval string, from, to time.Time) (*model.PriceHistoryResponse, error) {
+	if symbol == "" {
+		symbol = s.config.Business.DefaultSymbol
+	}
+	if !s.isSupportedSymbol(symbol) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSymbol, symbol)
+	}
+
+	step, ok := historyIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported interval %q", ErrInvalidParameter, interval)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidParameter)
+	}
+	if buckets := int(to.Sub(from) / step); buckets > s.maxPoints() {
+		return nil, fmt.Errorf("%w: range too large for interval %s (%d points, max %d)", ErrInvalidParameter, interval, buckets, s.maxPoints())
+	}
+
+	points := make([]model.PricePoint, 0)
+	if s.store != nil {
+		var err error
+		if points, err = s.store.aggregate(ctx, symbol, from, to, step); err != nil {
+			return nil, fmt.Errorf("failed to load price history: %w", err)
+		}
+	}
+	resp := &model.PriceHistoryResponse{
+		Symbol:   symbol,
+		Interval: interval,
+		From:     from,
+		To:       to,
+		Points:   points,
+	}
+	if len(points) > 0 {
+		resp.Precision = precision.Decimals(symbol, points[len(points)-1].Close)
+	}
+	return resp, nil
+}
+
+// GetPriceAt 获取指定时间的价格，支持最近邻和线性插值
+func (s *historyService) GetPriceAt(ctx context.Context, symbol string, at time.Time, method string) (*model.PriceAtResponse, error) {
+	if symbol == "" {
+		symbol = s.config.Business.DefaultSymbol
+	}
+	if !s.isSupportedSymbol(symbol) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSymbol, symbol)
+	}
+	if method == "" {
+		method = PriceAtNearest
+	}
+	if method != PriceAtNearest && method != PriceAtInterpolate {
+		return nil, fmt.Errorf("%w: unsupported method %q", ErrInvalidParameter, method)
+	}
+
+	maxGap := s.maxGap()
+	samples, err := s.loadSamples(ctx, symbol, at.Add(-maxGap), at.Add(maxGap))
+	if err != nil {
+		return nil, err
+	}
+
+	// 找到查询时间前后最近的采样
+	target := at.UnixMilli()
+	var before, after *priceSample
+	for i := range samples {
+		if samples[i].Timestamp <= target {
+			before = &samples[i]
+		}
+		if samples[i].Timestamp >= target && after == nil {
+			after = &samples[i]
+		}
+	}
+	if before == nil && after == nil {
+		return nil, fmt.Errorf("%w: no price history for %s within %s of %s", ErrNotFound, symbol, maxGap, at.Format(time.RFC3339))
+	}
+
+	resp := &model.PriceAtResponse{
+		Symbol: symbol,
+		Time:   at,
+		Before: before.toModel(),
+		After:  after.toModel(),
+	}
+
+	switch {
+	case method == PriceAtInterpolate && before != nil && after != nil && before.Timestamp != after.Timestamp:
+		ratio := float64(target-before.Timestamp) / float64(after.Timestamp-before.Timestamp)
+		resp.Price = before.Price + (after.Price-before.Price)*ratio
+		resp.Method = PriceAtInterpolate
+	case before != nil && (after == nil || target-before.Timestamp <= after.Timestamp-target):
+		resp.Price = before.Price
+		resp.Method = PriceAtNearest
+	default:
+		resp.Price = after.Price
+		resp.Method = PriceAtNearest
+	}
+	resp.Precision = precision.Decimals(symbol, resp.Price)
+
+	return resp, nil
+}
+
+// loadSamples 读取时间范围内的价格采样，按时间升序
+func (s *historyService) loadSamples(ctx context.Context, symbol string, from, to time.Time) ([]priceSample, error) {
+	if s.store == nil {
+		return nil, nil
+	}
+
+	samples, err := s.store.load(ctx, symbol, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load price history: %w", err)
+	}
+	return samples, nil
+}
+
+// maxGap 获取按时间查询价格时允许的最大采样间隔
+func (s *historyService) maxGap() time.Duration {
+	if s.config.History.MaxGap > 0 {
+		return s.config.History.MaxGap
+	}
+	return defaultHistoryMaxGap
+}
+
+// isSupportedSymbol 检查是否支持该币种
+func (s *historyService) isSupportedSymbol(symbol string) bool {
+	for _, supported := range s.config.Business.SupportedSymbols {
+		if supported == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// retention 获取历史数据保留时间
+func (s *historyService) retention() time.Duration {
+	if s.config.History.Retention > 0 {
+		return s.config.History.Retention
+	}
+	return defaultHistoryRetention
+}
+
+// maxPoints 获取单次查询的最大数据点数
+func (s *historyService) maxPoints() int {
+	if s.config.History.MaxPoints > 0 {
+		return s.config.History.MaxPoints
+	}
+	return defaultHistoryMaxPoints
+}
+
+// toModel 转换为对外的采样结构
+func (p *priceSample) toModel() *model.PriceSample {
+	if p == nil {
+		return nil
+	}
+	return &model.PriceSample{
+		Timestamp: time.UnixMilli(p.Timestamp).UTC(),
+		Price:     p.Price,
+		Source:    p.Source,
+	}
+}
+
+// aggregateSamples 将升序采样按间隔聚合为OHLC数据点
+func aggregateSamples(samples []priceSample, step time.Duration) []model.PricePoint {
+	points := make([]model.PricePoint, 0)
+	for _, sample := range samples {
+		bucket := time.UnixMilli(sample.Timestamp).UTC().Truncate(step)
+		if n := len(points); n > 0 && points[n-1].Timestamp.Equal(bucket) {
+			p := &points[n-1]
+			if sample.Price > p.High {
+				p.High = sample.Price
+			}
+			if sample.Price < p.Low {
+				p.Low = sample.Price
+			}
+			p.Close = sample.Price
+			p.Samples++
+			continue
+		}
+		points = append(points, model.PricePoint{
+			Timestamp: bucket,
+			Open:      sample.Price,
+			High:      sample.Price,
+			Low:       sample.Price,
+			Close:     sample.Price,
+			Samples:   1,
+		})
+	}
+	return points
+}
+
+// redisHistoryStore 使用Redis有序集合按时间存储价格采样，分数为毫秒时间戳
+type redisHistoryStore struct {
+	redisClient database.RedisClient
+}
+
+// add 保存采样
+func (r *redisHistoryStore) add(ctx context.Context, symbol string, sample priceSample) error {
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	return r.redisClient.ZAdd(ctx, historyKey(symbol), float64(sample.Timestamp), string(data))
+}
+
+// load 读取时间范围内的采样
+func (r *redisHistoryStore) load(ctx context.Context, symbol string, from, to time.Time) ([]priceSample, error) {
+	members, err := r.redisClient.ZRangeByScore(ctx, historyKey(symbol),
+		strconv.FormatInt(from.UnixMilli(), 10), strconv.FormatInt(to.UnixMilli(), 10))
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]priceSample, 0, len(members))
+	for _, member := range members {
+		var sample priceSample
+		if err := json.Unmarshal([]byte(member), &sample); err != nil {
+			logger.From(ctx).Warnf("Skipping malformed price history entry for %s: %v", symbol, err)
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// aggregate 读取采样后在内存中聚合
+func (r *redisHistoryStore) aggregate(ctx context.Context, symbol string, from, to time.Time, step time.Duration) ([]model.PricePoint, error) {
+	samples, err := r.load(ctx, symbol, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return aggregateSamples(samples, step), nil
+}
+
+// prune 删除cutoff之前的采样
+func (r *redisHistoryStore) prune(ctx context.Context, symbol string, cutoff time.Time) error {
+	return r.redisClient.ZRemRangeByScore(ctx, historyKey(symbol), "-inf", "("+strconv.FormatInt(cutoff.UnixMilli(), 10))
+}
+
+// historyKey 价格历史缓存key
+func historyKey(symbol string) string {
+	return fmt.Sprintf("history:price:%s", symbol)
+}
@@ -0,0 +1,647 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// 导入数据类型，对应监听目录下的子目录
+const (
+	IngestKindTokens = "tokens"
+	IngestKindLabels = "labels"
+	IngestKindTrades = "trades"
+)
+
+// 导入状态
+const (
+	IngestStatusSuccess = "success"
+	IngestStatusPartial = "partial"
+	IngestStatusFailed  = "failed"
+)
+
+// 导入默认配置
+const (
+	defaultIngestInterval     = time.Minute
+	defaultIngestSettleTime   = 5 * time.Second
+	defaultIngestMaxFileSize  = 20 << 20
+	defaultIngestLogRetention = 7 * 24 * time.Hour
+	maxIngestRecordErrors     = 20
+	ingestLogKey              = "ingest:log"
+)
+
+// IngestService 数据文件导入服务接口
+type IngestService interface {
+	Start(ctx context.Context) error
+	Stop() error
+	ScanNow(ctx context.Context) ([]model.IngestRecord, error)
+	GetLog(ctx context.Context, limit int) ([]model.IngestRecord, error)
+}
+
+// ingestService 监听目录并自动导入代币列表、地址标签和交易导出文件
+//
+// 目录结构:
+//
+//	<dir>/tokens/*.json|*.csv    代币列表
+//	<dir>/labels/*.json|*.csv    地址标签
+//	<dir>/trades/<user>/*.csv    交易所成交记录
+//
+// 处理完成的文件移动到<dir>/processed，失败的文件移动到<dir>/failed
+type ingestService struct {
+	redisClient      database.RedisClient
+	config           *config.Config
+	tokenService     TokenService
+	portfolioService PortfolioService
+	logger           logger.Logger
+
+	runMutex sync.Mutex
+	scanMu   sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewIngestService 创建数据文件导入服务
+func NewIngestService(redisClient database.RedisClient, cfg *config.Config, tokenService TokenService, portfolioService PortfolioService) IngestService {
+	return &ingestService{
+		redisClient:      redisClient,
+		config:           cfg,
+		tokenService:     tokenService,
+		portfolioService: portfolioService,
+		logger:           logger.GetLogger(),
+	}
+}
+
+// Start 启动目录监听
+func (s *ingestService) Start(ctx context.Context) error {
+	if !s.config.Ingest.Enabled {
+		return nil
+	}
+	if s.config.Ingest.Dir == "" {
+		return fmt.Errorf("ingest directory is not configured")
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("ingest worker is already running")
+	}
+
+	for _, kind := range []string{IngestKindTokens, IngestKindLabels, IngestKindTrades} {
+		if err := os.MkdirAll(filepath.Join(s.config.Ingest.Dir, kind), 0o755); err != nil {
+			return fmt.Errorf("failed to create ingest directory: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "ingest", s.run, s.done)
+
+	s.logger.Infof("Ingest worker started, watching %s", s.config.Ingest.Dir)
+	return nil
+}
+
+// Stop 停止目录监听
+func (s *ingestService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	s.logger.Info("Ingest worker stopped")
+	return nil
+}
+
+// run 定时扫描目录，并在文件变化时提前触发扫描
+func (s *ingestService) run(ctx context.Context) {
+	interval := s.config.Ingest.Interval
+	if interval <= 0 {
+		interval = defaultIngestInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	trigger := make(chan struct{}, 1)
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		s.logger.Warnf("File watcher unavailable, falling back to periodic scan: %v", err)
+	} else {
+		defer watcher.Close()
+		for _, kind := range []string{IngestKindTokens, IngestKindLabels, IngestKindTrades} {
+			if err := watcher.Add(filepath.Join(s.config.Ingest.Dir, kind)); err != nil {
+				s.logger.Warnf("Failed to watch %s directory: %v", kind, err)
+			}
+		}
+		panics.Supervise(ctx, "ingest_events", func(ctx context.Context) { s.forwardEvents(ctx, watcher, trigger) }, nil)
+	}
+
+	s.scan(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.scan(ctx)
+		case <-trigger:
+			// 等待文件写入完成后再扫描
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.settleTime()):
+			}
+			s.scan(ctx)
+		}
+	}
+}
+
+// forwardEvents 将文件系统事件转换为扫描信号
+func (s *ingestService) forwardEvents(ctx context.Context, watcher *fsnotify.Watcher, trigger chan<- struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Rename) == 0 {
+				continue
+			}
+			// 新建的用户子目录也需要监听
+			if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+				_ = watcher.Add(event.Name)
+			}
+			select {
+			case trigger <- struct{}{}:
+			default:
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			s.logger.Warnf("File watcher error: %v", err)
+		}
+	}
+}
+
+// ScanNow 立即扫描并导入目录中的文件
+func (s *ingestService) ScanNow(ctx context.Context) ([]model.IngestRecord, error) {
+	if s.config.Ingest.Dir == "" {
+		return nil, fmt.Errorf("%w: ingest directory is not configured", ErrInvalidParameter)
+	}
+	return s.scan(ctx), nil
+}
+
+// scan 扫描各数据目录，处理已写入完成的文件
+func (s *ingestService) scan(ctx context.Context) []model.IngestRecord {
+	s.scanMu.Lock()
+	defer s.scanMu.Unlock()
+
+	records := make([]model.IngestRecord, 0)
+	for _, job := range s.pendingFiles() {
+		if ctx.Err() != nil {
+			break
+		}
+		record := s.ingestFile(ctx, job)
+		records = append(records, record)
+		s.appendLog(ctx, record)
+	}
+	return records
+}
+
+// ingestJob 待导入文件
+type ingestJob struct {
+	path   string
+	kind   string
+	userID string
+}
+
+// pendingFiles 列出待处理的文件，跳过最近仍在修改的文件
+func (s *ingestService) pendingFiles() []ingestJob {
+	root := s.config.Ingest.Dir
+	settled := time.Now().Add(-s.settleTime())
+	var jobs []ingestJob
+
+	collect := func(dir, kind, userID string) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || info.ModTime().After(settled) {
+				continue
+			}
+			jobs = append(jobs, ingestJob{path: filepath.Join(dir, entry.Name()), kind: kind, userID: userID})
+		}
+	}
+
+	collect(filepath.Join(root, IngestKindTokens), IngestKindTokens, "")
+	collect(filepath.Join(root, IngestKindLabels), IngestKindLabels, "")
+
+	tradesDir := filepath.Join(root, IngestKindTrades)
+	if users, err := os.ReadDir(tradesDir); err == nil {
+		for _, user := range users {
+			if user.IsDir() {
+				collect(filepath.Join(tradesDir, user.Name()), IngestKindTrades, user.Name())
+			}
+		}
+	}
+
+	return jobs
+}
+
+// ingestFile 导入单个文件并移动到processed或failed目录
+func (s *ingestService) ingestFile(ctx context.Context, job ingestJob) model.IngestRecord {
+	record := model.IngestRecord{
+		File:      job.path,
+		Kind:      job.kind,
+		StartedAt: time.Now(),
+	}
+
+	var errs []string
+	err := func() error {
+		info, err := os.Stat(job.path)
+		if err != nil {
+			return err
+		}
+		if info.Size() > s.maxFileSize() {
+			return fmt.Errorf("file size %d exceeds limit %d", info.Size(), s.maxFileSize())
+		}
+
+		f, err := os.Open(job.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		switch job.kind {
+		case IngestKindTokens:
+			record.Records, record.Loaded, errs, err = s.ingestTokens(ctx, job.path, f)
+		case IngestKindLabels:
+			record.Records, record.Loaded, errs, err = s.ingestLabels(ctx, job.path, f)
+		case IngestKindTrades:
+			record.Records, record.Loaded, errs, err = s.ingestTrades(ctx, job.userID, f)
+		}
+		return err
+	}()
+	if err != nil {
+		errs = append([]string{err.Error()}, errs...)
+	}
+
+	switch {
+	case err != nil || (record.Records > 0 && record.Loaded == 0 && len(errs) > 0):
+		record.Status = IngestStatusFailed
+	case len(errs) > 0:
+		record.Status = IngestStatusPartial
+	default:
+		record.Status = IngestStatusSuccess
+	}
+	if len(errs) > maxIngestRecordErrors {
+		errs = append(errs[:maxIngestRecordErrors], fmt.Sprintf("... %d more errors", len(errs)-maxIngestRecordErrors))
+	}
+	record.Errors = errs
+	record.DurationMs = time.Since(record.StartedAt).Milliseconds()
+
+	target := "processed"
+	if record.Status == IngestStatusFailed {
+		target = "failed"
+	}
+	if moved, err := s.moveFile(job, target); err != nil {
+		s.logger.Errorf("Failed to move ingested file %s: %v", job.path, err)
+	} else {
+		record.File = moved
+	}
+
+	s.logger.Infof("Ingested %s file %s: status=%s records=%d loaded=%d errors=%d",
+		job.kind, filepath.Base(job.path), record.Status, record.Records, record.Loaded, len(errs))
+	return record
+}
+
+// ingestTokens 导入代币列表，支持Token List JSON格式和CSV(address,symbol,name,decimals,logo_uri)
+func (s *ingestService) ingestTokens(ctx context.Context, path string, r io.Reader) (int, int, []string, error) {
+	var tokens []model.TokenMetadata
+	var errs []string
+	total := 0
+	source := "file:" + filepath.Base(path)
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		list, err := decodeTokenList(r)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		// 只导入当前链的代币
+		for _, t := range list {
+			if t.ChainID != 0 && s.config.BSC.ChainID != 0 && t.ChainID != s.config.BSC.ChainID {
+				continue
+			}
+			tokens = append(tokens, t)
+		}
+		total = len(tokens)
+	} else {
+		rows, err := readCSVRows(r, "address", "symbol")
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		total = len(rows)
+		for _, row := range rows {
+			decimals, err := strconv.Atoi(row.get("decimals", "18"))
+			if err != nil || decimals < 0 || decimals > maxTokenDecimals {
+				errs = append(errs, fmt.Sprintf("row %d: invalid decimals %q", row.line, row.get("decimals", "")))
+				continue
+			}
+			tokens = append(tokens, model.TokenMetadata{
+				Address:  row.get("address", ""),
+				Symbol:   row.get("symbol", ""),
+				Name:     row.get("name", ""),
+				Decimals: uint8(decimals),
+				LogoURI:  row.get("logo_uri", ""),
+				ChainID:  s.config.BSC.ChainID,
+			})
+		}
+	}
+
+	valid := make([]model.TokenMetadata, 0, len(tokens))
+	for i, token := range tokens {
+		token.Sources = []string{source}
+		if token.ChainID == 0 {
+			token.ChainID = s.config.BSC.ChainID
+		}
+		normalized, err := ValidateToken(token)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("token %d: %v", i+1, err))
+			continue
+		}
+		valid = append(valid, normalized)
+	}
+
+	loaded, err := s.tokenService.UpsertTokens(ctx, valid)
+	return total, loaded, errs, err
+}
+
+// ingestLabels 导入地址标签，支持JSON数组和CSV(address,label,category)
+func (s *ingestService) ingestLabels(ctx context.Context, path string, r io.Reader) (int, int, []string, error) {
+	var labels []model.AddressLabel
+	source := "file:" + filepath.Base(path)
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.NewDecoder(r).Decode(&labels); err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid label list json: %w", err)
+		}
+	} else {
+		rows, err := readCSVRows(r, "address", "label")
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		for _, row := range rows {
+			labels = append(labels, model.AddressLabel{
+				Address:  row.get("address", ""),
+				Label:    row.get("label", ""),
+				Category: row.get("category", ""),
+			})
+		}
+	}
+
+	var errs []string
+	valid := make([]model.AddressLabel, 0, len(labels))
+	for i, label := range labels {
+		label.Source = source
+		normalized, err := ValidateLabel(label)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("label %d: %v", i+1, err))
+			continue
+		}
+		valid = append(valid, normalized)
+	}
+
+	loaded, err := s.tokenService.UpsertLabels(ctx, valid)
+	return len(labels), loaded, errs, err
+}
+
+// ingestTrades 导入交易所成交记录到用户投资组合
+func (s *ingestService) ingestTrades(ctx context.Context, userID string, r io.Reader) (int, int, []string, error) {
+	report, err := s.portfolioService.ImportTrades(ctx, userID, "", r)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	errs := make([]string, 0, len(report.Errors))
+	for _, e := range report.Errors {
+		errs = append(errs, fmt.Sprintf("row %d: %s", e.Row, e.Message))
+	}
+	return report.Rows, report.Imported + report.Duplicates, errs, nil
+}
+
+// moveFile 将处理完成的文件移动到目标目录，保留原有的相对路径
+func (s *ingestService) moveFile(job ingestJob, target string) (string, error) {
+	rel, err := filepath.Rel(s.config.Ingest.Dir, job.path)
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(s.config.Ingest.Dir, target, filepath.Dir(rel),
+		time.Now().Format("20060102-150405")+"-"+filepath.Base(job.path))
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", err
+	}
+	return dest, os.Rename(job.path, dest)
+}
+
+// appendLog 写入导入日志
+func (s *ingestService) appendLog(ctx context.Context, record model.IngestRecord) {
+	if s.redisClient == nil {
+		return
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	score := float64(record.StartedAt.UnixMilli())
+	if err := s.redisClient.ZAdd(ctx, ingestLogKey, score, string(data)); err != nil {
+		s.logger.Warnf("Failed to write ingest log: %v", err)
+		return
+	}
+
+	retention := s.config.Ingest.LogRetention
+	if retention <= 0 {
+		retention = defaultIngestLogRetention
+	}
+	cutoff := time.Now().Add(-retention).UnixMilli()
+	_ = s.redisClient.ZRemRangeByScore(ctx, ingestLogKey, "-inf", "("+strconv.FormatInt(cutoff, 10))
+}
+
+// GetLog 获取最近的导入日志，按时间倒序
+func (s *ingestService) GetLog(ctx context.Context, limit int) ([]model.IngestRecord, error) {
+	records := make([]model.IngestRecord, 0)
+	if s.redisClient == nil {
+		return records, nil
+	}
+
+	members, err := s.redisClient.ZRangeByScore(ctx, ingestLogKey, "-inf", "+inf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ingest log: %w", err)
+	}
+
+	for i := len(members) - 1; i >= 0 && (limit <= 0 || len(records) < limit); i-- {
+		var record model.IngestRecord
+		if err := json.Unmarshal([]byte(members[i]), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// settleTime 获取文件写入完成的判定时间
+func (s *ingestService) settleTime() time.Duration {
+	if s.config.Ingest.SettleTime > 0 {
+		return s.config.Ingest.SettleTime
+	}
+	return defaultIngestSettleTime
+}
+
+// maxFileSize 获取单个文件大小上限
+func (s *ingestService) maxFileSize() int64 {
+	if s.config.Ingest.MaxFileSize > 0 {
+		return s.config.Ingest.MaxFileSize
+	}
+	return defaultIngestMaxFileSize
+}
+
+// tokenListEntry Token List标准中的代币条目
+type tokenListEntry struct {
+	ChainID  int64    `json:"chainId"`
+	Address  string   `json:"address"`
+	Name     string   `json:"name"`
+	Symbol   string   `json:"symbol"`
+	Decimals int      `json:"decimals"`
+	LogoURI  string   `json:"logoURI"`
+	Tags     []string `json:"tags"`
+}
+
+// decodeTokenList 解析Token List标准JSON，也支持直接的代币数组
+func decodeTokenList(r io.Reader) ([]model.TokenMetadata, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Tokens []tokenListEntry `json:"tokens"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil || list.Tokens == nil {
+		if arrErr := json.Unmarshal(raw, &list.Tokens); arrErr != nil {
+			if err == nil {
+				err = arrErr
+			}
+			return nil, fmt.Errorf("invalid token list json: %w", err)
+		}
+	}
+
+	tokens := make([]model.TokenMetadata, 0, len(list.Tokens))
+	for _, t := range list.Tokens {
+		if t.Decimals < 0 || t.Decimals > maxTokenDecimals {
+			t.Decimals = maxTokenDecimals + 1
+		}
+		tokens = append(tokens, model.TokenMetadata{
+			Address:  t.Address,
+			ChainID:  t.ChainID,
+			Symbol:   t.Symbol,
+			Name:     t.Name,
+			Decimals: uint8(t.Decimals),
+			LogoURI:  t.LogoURI,
+			Tags:     t.Tags,
+		})
+	}
+	return tokens, nil
+}
+
+// csvRow 按表头访问的CSV行
+type csvRow struct {
+	line   int
+	fields map[string]string
+}
+
+// get 获取字段值，为空时返回默认值
+func (r csvRow) get(name, def string) string {
+	if v := r.fields[name]; v != "" {
+		return v
+	}
+	return def
+}
+
+// readCSVRows 读取带表头的CSV并校验必需字段
+func readCSVRows(r io.Reader, required ...string) ([]csvRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+	}
+	for _, name := range required {
+		found := false
+		for _, h := range header {
+			if h == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("csv header missing required column %q", name)
+		}
+	}
+
+	var rows []csvRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		fields := make(map[string]string, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				fields[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, csvRow{line: line, fields: fields})
+	}
+	return rows, nil
+}
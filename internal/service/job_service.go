@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/precision"
+	"crypto-info/internal/pkg/scheduler"
+)
+
+// 内置定时任务名称，见 jobs.tasks
+const (
+	JobPriceRefresh   = "price_refresh"
+	JobDailyReport    = "daily_report"
+	JobHistoryCleanup = "history_cleanup"
+	JobAlertDigest    = "alert_digest"
+	JobStateBackup    = "state_backup"
+)
+
+// reportLookback 日报涨跌幅的比较时间
+const reportLookback = 24 * time.Hour
+
+// ErrJobRunning 任务正在当前实例执行
+var ErrJobRunning = errors.New("job is already running")
+
+// jobDefaults 内置任务的默认执行计划，alert_digest的默认间隔取自notifier.rate_limit.digest_interval
+var jobDefaults = map[string]config.JobConfig{
+	JobPriceRefresh:   {Schedule: "@every 1m", Jitter: 5 * time.Second, Timeout: 45 * time.Second},
+	JobDailyReport:    {Schedule: "0 9 * * *", Timeout: 2 * time.Minute},
+	JobHistoryCleanup: {Schedule: "@hourly", Jitter: 5 * time.Minute, Timeout: 5 * time.Minute},
+	JobAlertDigest:    {Timeout: time.Minute},
+	JobStateBackup:    {Schedule: "0 3 * * *", Timeout: 10 * time.Minute},
+}
+
+// JobService 进程内定时任务服务
+type JobService interface {
+	// ListJobs 已注册的任务及其下次执行时间和最近一次执行
+	ListJobs(ctx context.Context) *model.JobListResponse
+	// RunJob 立即在后台执行任务，不受singleton锁限制；wait为true时等待执行结束，返回最终的执行记录
+	RunJob(ctx context.Context, name string, wait bool) (*model.JobRun, error)
+	// JobHistory 任务最近的执行记录，新记录在前
+	JobHistory(ctx context.Context, name string, limit int) (*model.JobHistoryResponse, error)
+	// Start 按计划执行任务
+	Start(ctx context.Context) error
+	// Stop 停止调度并等待正在执行的任务结束
+	Stop() error
+}
+
+// jobService 定时任务服务实现，任务本身由各业务服务提供
+type jobService struct {
+	config         *config.Config
+	logger         logger.Logger
+	scheduler      *scheduler.Scheduler
+	priceService   PriceService
+	historyService HistoryService
+	notifier       Notifier
+	backupService  BackupService
+}
+
+// NewJobService 创建定时任务服务并注册内置任务，执行计划无效的任务只记录错误，不影响其他任务
+func NewJobService(redisClient database.RedisClient, cfg *config.Config, priceService PriceService, historyService HistoryService, notifier Notifier, backupService BackupService) JobService {
+	location := time.UTC
+	if cfg.Jobs.Timezone != "" {
+		if loc, err := time.LoadLocation(cfg.Jobs.Timezone); err == nil {
+			location = loc
+		}
+	}
+
+	s := &jobService{
+		config:         cfg,
+		logger:         logger.GetLogger(),
+		scheduler:      scheduler.New(redisClient, scheduler.Options{HistorySize: cfg.Jobs.HistorySize, Location: location}),
+		priceService:   priceService,
+		historyService: historyService,
+		notifier:       notifier,
+		backupService:  backupService,
+	}
+
+	jobs := []scheduler.Job{
+		{Name: JobPriceRefresh, Run: priceService.RefreshPrices},
+		{Name: JobDailyReport, Run: s.sendDailyReport},
+		{Name: JobHistoryCleanup, Run: historyService.Prune},
+	}
+	if cfg.Notifier.RateLimit.Enabled && redisClient != nil {
+		jobs = append(jobs, scheduler.Job{Name: JobAlertDigest, Run: notifier.FlushDigests})
+	}
+	if cfg.Backup.Enabled && redisClient != nil {
+		jobs = append(jobs, scheduler.Job{Name: JobStateBackup, Run: s.runBackup})
+	}
+	for _, job := range jobs {
+		task, ok := s.task(job.Name)
+		if !ok {
+			continue
+		}
+		job.Spec = task.Schedule
+		job.Jitter = task.Jitter
+		job.Timeout = task.Timeout
+		job.Singleton = true
+		if err := s.scheduler.Register(job); err != nil {
+			s.logger.Errorf("Failed to register job %s: %v", job.Name, err)
+		}
+	}
+	return s
+}
+
+// ListJobs 已注册的任务及其下次执行时间和最近一次执行
+func (s *jobService) ListJobs(ctx context.Context) *model.JobListResponse {
+	return &model.JobListResponse{
+		Enabled: s.config.Jobs.Enabled,
+		Jobs:    s.scheduler.Jobs(ctx),
+	}
+}
+
+// RunJob 立即在后台执行任务，等待执行结束时请求取消或超时则返回执行中的记录
+func (s *jobService) RunJob(ctx context.Context, name string, wait bool) (*model.JobRun, error) {
+	var run *model.JobRun
+	var err error
+	if wait {
+		run, err = s.scheduler.TriggerWait(ctx, name)
+	} else {
+		run, err = s.scheduler.Trigger(name)
+	}
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		return nil, fmt.Errorf("%w: job %s", ErrNotFound, name)
+	case errors.Is(err, scheduler.ErrJobRunning):
+		return nil, fmt.Errorf("%w: %s", ErrJobRunning, name)
+	case err != nil:
+		return nil, err
+	}
+	logger.From(ctx).Infof("Job %s triggered manually, status: %s", name, run.Status)
+	return run, nil
+}
+
+// JobHistory 任务最近的执行记录
+func (s *jobService) JobHistory(ctx context.Context, name string, limit int) (*model.JobHistoryResponse, error) {
+	runs, err := s.scheduler.History(ctx, name, limit)
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		return nil, fmt.Errorf("%w: job %s", ErrNotFound, name)
+	case err != nil:
+		return nil, err
+	}
+	return &model.JobHistoryResponse{Job: name, Runs: runs}, nil
+}
+
+// Start 按计划执行任务，未启用时只支持手动触发
+func (s *jobService) Start(ctx context.Context) error {
+	if !s.config.Jobs.Enabled {
+		return nil
+	}
+	return s.scheduler.Start(ctx)
+}
+
+// Stop 停止调度并等待正在执行的任务结束
+func (s *jobService) Stop() error {
+	return s.scheduler.Stop()
+}
+
+// task 合并配置和默认值，任务被禁用时返回false
+func (s *jobService) task(name string) (config.JobConfig, bool) {
+	task := jobDefaults[name]
+	if name == JobAlertDigest {
+		interval := s.config.Notifier.RateLimit.DigestInterval
+		if interval <= 0 {
+			interval = defaultNotifyDigestInterval
+		}
+		task.Schedule = "@every " + interval.String()
+	}
+
+	override, ok := s.config.Jobs.Tasks[name]
+	if !ok {
+		return task, true
+	}
+	if override.Disabled {
+		return task, false
+	}
+	if override.Schedule != "" {
+		task.Schedule = override.Schedule
+	}
+	if override.Jitter > 0 {
+		task.Jitter = override.Jitter
+	}
+	if override.Timeout > 0 {
+		task.Timeout = override.Timeout
+	}
+	return task, true
+}
+
+// runBackup 执行一次加密备份
+func (s *jobService) runBackup(ctx context.Context) error {
+	_, err := s.backupService.CreateBackup(ctx)
+	return err
+}
+
+// sendDailyReport 发送支持币种的价格和24小时涨跌，全部币种获取价格失败时返回错误
+func (s *jobService) sendDailyReport(ctx context.Context) error {
+	symbols := s.config.Business.SupportedSymbols
+	lines := make([]string, 0, len(symbols))
+	prices := make(map[string]interface{}, len(symbols))
+	changes := make(map[string]interface{}, len(symbols))
+	var lastErr error
+	for _, symbol := range symbols {
+		price, err := s.priceService.GetPrice(ctx, symbol)
+		if err != nil {
+			lastErr = err
+			lines = append(lines, fmt.Sprintf("%s: 获取价格失败", symbol))
+			continue
+		}
+		prices[symbol] = price.Price
+
+		line := fmt.Sprintf("%s: %s %s", symbol, precision.Format(symbol, price.Price), price.Currency)
+		if past, err := s.historyService.GetPriceAt(ctx, symbol, time.Now().Add(-reportLookback), PriceAtNearest); err == nil && past.Price > 0 {
+			change := (price.Price - past.Price) / past.Price * 100
+			changes[symbol] = change
+			line += fmt.Sprintf(" (24h %+.2f%%)", change)
+		}
+		lines = append(lines, line)
+	}
+	if len(prices) == 0 {
+		return fmt.Errorf("failed to get prices: %w", lastErr)
+	}
+
+	return s.notifier.Notify(ctx, &model.Alert{
+		Type:     model.AlertTypeReport,
+		Severity: model.AlertSeverityInfo,
+		Title:    "每日行情报告",
+		Message:  strings.Join(lines, "\n"),
+		Subject:  strings.Join(symbols, ","),
+		Data: map[string]interface{}{
+			"prices":     prices,
+			"change_24h": changes,
+		},
+	})
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/binance"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/huobi"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/okx"
+	"crypto-info/internal/pkg/precision"
+	"crypto-info/internal/pkg/provider"
+)
+
+// KlineService K线服务接口
+type KlineService interface {
+	// GetKlines 获取币种最近limit根K线，interval为K线周期(1m、5m、15m、30m、1h、4h、1d、1w)
+	GetKlines(ctx context.Context, symbol, interval string, limit int) (*model.KlineResponse, error)
+}
+
+// K线数量限制
+const (
+	defaultKlineLimit = 500
+	maxKlineLimit     = 1000
+)
+
+// klineIntervals 支持的K线周期，各数据源均支持
+var klineIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// klineService K线服务实现
+type klineService struct {
+	redisClient   database.RedisClient
+	config        *config.Config
+	providers     map[string]provider.CandleProvider
+	displayNames  map[string]string
+	negativeCache *negativeCache
+}
+
+// cachedKlines K线缓存条目
+type cachedKlines struct {
+	Klines   *model.KlineResponse `json:"klines"`
+	CachedAt time.Time            `json:"cached_at"`
+}
+
+// NewKlineService 创建K线服务，按 business.kline_sources 的顺序从交易所获取K线
+func NewKlineService(redisClient database.RedisClient, cfg *config.Config) KlineService {
+	return &klineService{
+		redisClient: redisClient,
+		config:      cfg,
+		providers: map[string]provider.CandleProvider{
+			priceSourceBinance: binance.NewProvider(binance.NewClient(&cfg.ExternalAPI.Binance)),
+			priceSourceHuobi:   huobi.NewProvider(huobi.NewClient(&cfg.ExternalAPI.Huobi)),
+			priceSourceOKX:     okx.NewProvider(okx.NewClient(&cfg.ExternalAPI.OKX)),
+		},
+		displayNames: map[string]string{
+			priceSourceBinance: "Binance",
+			priceSourceHuobi:   "Huobi",
+			priceSourceOKX:     "OKX",
+		},
+		negativeCache: newNegativeCache(redisClient, cfg.Cache.NegativeTTL),
+	}
+}
+
+// GetKlines 获取K线，结果按币种、周期和数量缓存
+func (s *klineService) GetKlines(ctx context.Context, symbol, interval string, limit int) (*model.KlineResponse, error) {
+	// 参数验证
+	if symbol == "" {
+		symbol = s.config.Business.DefaultSymbol
+	}
+	if interval == "" {
+		interval = "1h"
+	}
+	period, ok := klineIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported interval %s", ErrInvalidParameter, interval)
+	}
+	if limit == 0 {
+		limit = defaultKlineLimit
+	}
+	if limit < 0 || limit > maxKlineLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidParameter, maxKlineLimit)
+	}
+
+	// 检查是否支持该币种
+	if !s.isSupportedSymbol(symbol) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSymbol, symbol)
+	}
+
+	cacheKey := fmt.Sprintf("klines:%s:%s:%d", symbol, interval, limit)
+
+	// 尝试从缓存获取
+	if s.redisClient != nil {
+		if cached, err := s.getKlinesFromCache(ctx, cacheKey, period); err == nil && cached != nil {
+			logger.From(ctx).Debugf("Kline cache hit for symbol: %s, interval: %s", symbol, interval)
+			return cached, nil
+		}
+	}
+
+	// 检查负缓存
+	if err := s.negativeCache.get(ctx, cacheKey); err != nil {
+		logger.From(ctx).Debugf("Kline negative cache hit for symbol: %s, interval: %s", symbol, interval)
+		return nil, err
+	}
+
+	// 获取K线数据
+	klines, err := s.fetchKlines(ctx, symbol, interval, period, limit)
+	if err != nil {
+		logger.From(ctx).Errorf("Failed to fetch klines for %s: %v", symbol, err)
+		err = fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+		if cacheErr := s.negativeCache.set(ctx, cacheKey, err); cacheErr != nil {
+			logger.From(ctx).Warnf("Failed to set negative cache for %s: %v", symbol, cacheErr)
+		}
+		return nil, err
+	}
+
+	// 缓存结果
+	if s.redisClient != nil {
+		if err := s.setKlinesCache(ctx, cacheKey, period, klines); err != nil {
+			logger.From(ctx).Warnf("Failed to cache klines for %s: %v", symbol, err)
+		}
+	}
+
+	return klines, nil
+}
+
+// fetchKlines 依次尝试配置的数据源，请求已取消或超时时不再尝试
+func (s *klineService) fetchKlines(ctx context.Context, symbol, interval string, period time.Duration, limit int) (*model.KlineResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// 如果启用了模拟数据，返回模拟K线
+	if s.config.Business.MockDataEnabled {
+		return s.generateMockKlines(symbol, interval, period, limit), nil
+	}
+
+	var lastErr error
+	for _, source := range s.sources() {
+		p, ok := s.providers[source]
+		if !ok {
+			continue
+		}
+		candles, err := p.GetCandles(ctx, symbol, period, limit)
+		if err == nil {
+			return s.newResponse(symbol, interval, period, s.displayNames[source], candles), nil
+		}
+		logger.From(ctx).Warnf("Failed to get klines from %s for %s: %v", source, symbol, err)
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no kline source configured")
+	}
+	return nil, lastErr
+}
+
+// sources 配置的K线数据源，未配置时依次使用币安、OKX和火币
+func (s *klineService) sources() []string {
+	if len(s.config.Business.KlineSources) == 0 {
+		return []string{priceSourceBinance, priceSourceOKX, priceSourceHuobi}
+	}
+	return s.config.Business.KlineSources
+}
+
+// newResponse 将数据源K线转换为响应
+func (s *klineService) newResponse(symbol, interval string, period time.Duration, source string, candles []provider.Candle) *model.KlineResponse {
+	klines := make([]model.Kline, len(candles))
+	for i, candle := range candles {
+		klines[i] = model.Kline{
+			OpenTime:    candle.OpenTime,
+			CloseTime:   candle.OpenTime.Add(period),
+			Open:        candle.Open,
+			High:        candle.High,
+			Low:         candle.Low,
+			Close:       candle.Close,
+			Volume:      candle.Volume,
+			QuoteVolume: candle.QuoteVolume,
+		}
+	}
+
+	var last float64
+	if len(klines) > 0 {
+		last = klines[len(klines)-1].Close
+	}
+	return &model.KlineResponse{
+		Symbol:    symbol,
+		Interval:  interval,
+		Currency:  "USDT",
+		Source:    source,
+		Klines:    klines,
+		Precision: precision.Decimals(symbol, last),
+	}
+}
+
+// generateMockKlines 生成模拟K线数据
+func (s *klineService) generateMockKlines(symbol, interval string, period time.Duration, limit int) *model.KlineResponse {
+	basePrices := map[string]float64{
+		"BTC":  45000.0,
+		"ETH":  3000.0,
+		"LTC":  150.0,
+		"BCH":  400.0,
+		"ADA":  0.5,
+		"DOT":  25.0,
+		"LINK": 20.0,
+		"XRP":  0.6,
+	}
+
+	basePrice, exists := basePrices[symbol]
+	if !exists {
+		basePrice = 100.0
+	}
+
+	// 从最近一根K线向前生成，添加一些随机波动
+	current := time.Now().UTC().Truncate(period)
+	candles := make([]provider.Candle, limit)
+	for i := range candles {
+		openTime := current.Add(-time.Duration(limit-1-i) * period)
+		variation := float64(openTime.Unix()/int64(period.Seconds())%100-50) * 0.001
+		open := basePrice * (1 + variation)
+		closePrice := open * (1 + variation/2)
+		candles[i] = provider.Candle{
+			OpenTime:    openTime,
+			Open:        open,
+			High:        max(open, closePrice) * 1.002,
+			Low:         min(open, closePrice) * 0.998,
+			Close:       closePrice,
+			Volume:      1000 * (1 + variation),
+			QuoteVolume: 1000 * (1 + variation) * closePrice,
+		}
+	}
+	return s.newResponse(symbol, interval, period, "Mock Data", candles)
+}
+
+// isSupportedSymbol 检查是否支持该币种
+func (s *klineService) isSupportedSymbol(symbol string) bool {
+	for _, supported := range s.config.Business.SupportedSymbols {
+		if supported == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// klineTTL K线缓存时间，取kline_ttl和K线周期中较小的一个
+func (s *klineService) klineTTL(period time.Duration) time.Duration {
+	ttl := s.config.Cache.KlineTTL
+	if ttl <= 0 {
+		ttl = s.config.Cache.DefaultTTL
+	}
+	return min(ttl, period)
+}
+
+// getKlinesFromCache 从缓存获取K线，并附带缓存信息
+func (s *klineService) getKlinesFromCache(ctx context.Context, cacheKey string, period time.Duration) (*model.KlineResponse, error) {
+	cachedData, err := s.redisClient.Get(ctx, cacheKey)
+	if err != nil || cachedData == "" {
+		return nil, fmt.Errorf("cache miss")
+	}
+
+	var entry cachedKlines
+	if err := json.Unmarshal([]byte(cachedData), &entry); err != nil {
+		return nil, err
+	}
+	if entry.Klines == nil || entry.CachedAt.IsZero() {
+		return nil, fmt.Errorf("cache miss")
+	}
+
+	klines := *entry.Klines
+	klines.Cache = &model.CacheInfo{
+		Cached:    true,
+		CachedAt:  entry.CachedAt,
+		ExpiresAt: entry.CachedAt.Add(s.klineTTL(period)),
+	}
+	return &klines, nil
+}
+
+// setKlinesCache 设置K线缓存
+func (s *klineService) setKlinesCache(ctx context.Context, cacheKey string, period time.Duration, klines *model.KlineResponse) error {
+	data, err := json.Marshal(cachedKlines{Klines: klines, CachedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	return s.redisClient.Set(ctx, cacheKey, data, s.klineTTL(period))
+}
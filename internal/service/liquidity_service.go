@@ -0,0 +1,502 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 流动性监控默认配置
+const (
+	defaultLiquidityInterval       = 30 * time.Second
+	defaultLiquidityRetention      = 7 * 24 * time.Hour
+	defaultLiquidityShareThreshold = 0.2
+	defaultLiquidityMaxBlocks      = 2000
+	defaultLiquidityEventLimit     = 100
+	liquidityEventsKey             = "liquidity:events"
+	liquidityCursorKey             = "liquidity:cursor"
+)
+
+// AlertTypeLiquidityRemoval 大额流动性撤出或LP解锁告警
+const AlertTypeLiquidityRemoval = "liquidity_removal"
+
+var (
+	// pairBurnTopic 交易对Burn事件签名
+	pairBurnTopic = crypto.Keccak256Hash([]byte("Burn(address,uint256,uint256,address)"))
+	// tokenTransferTopic BEP20 Transfer事件签名
+	tokenTransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// LiquidityService 流动性撤出监控服务接口
+type LiquidityService interface {
+	Start(ctx context.Context) error
+	Stop() error
+	ScanNow(ctx context.Context) error
+	GetEvents(ctx context.Context, pair string, limit int) (*model.LiquidityEventListResponse, error)
+	// GetPairs 监控中的交易对，包括配置的和自动发现的
+	GetPairs(ctx context.Context) (*model.MonitoredPairListResponse, error)
+}
+
+// liquidityService 扫描监控交易对的Burn事件与锁仓合约转出的LP，发现大额撤出时告警
+type liquidityService struct {
+	redisClient  database.RedisClient
+	config       *config.Config
+	bscService   BSCService
+	priceService PriceService
+	notifier     Notifier
+	discovery    PairDiscoveryService // 自动发现的交易对同样加入监控，可为nil
+	reader       *contractReader
+	logger       logger.Logger
+
+	runMutex  sync.Mutex
+	scanMutex sync.Mutex
+	running   bool
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// watchedPair 监控中的交易对及其当前状态
+type watchedPair struct {
+	name  string
+	state *pairState
+}
+
+// NewLiquidityService 创建流动性撤出监控服务，notifier和discovery可为nil
+func NewLiquidityService(redisClient database.RedisClient, cfg *config.Config, bscService BSCService, priceService PriceService, notifier Notifier, discovery PairDiscoveryService) LiquidityService {
+	return &liquidityService{
+		redisClient:  redisClient,
+		config:       cfg,
+		bscService:   bscService,
+		priceService: priceService,
+		notifier:     notifier,
+		discovery:    discovery,
+		reader:       newContractReader(bscService),
+		logger:       logger.GetLogger(),
+	}
+}
+
+// Start 启动定时扫描，启用自动发现时即使尚未发现交易对也启动
+func (s *liquidityService) Start(ctx context.Context) error {
+	discovering := s.discovery != nil && s.discovery.Enabled()
+	if !s.config.BSC.Liquidity.Enabled || (len(s.pairs()) == 0 && !discovering) || s.redisClient == nil || s.bscService == nil {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("liquidity monitoring is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "liquidity", s.run, s.done)
+
+	s.logger.Infof("Liquidity monitoring started with %d pairs", len(s.pairs()))
+	return nil
+}
+
+// Stop 停止定时扫描
+func (s *liquidityService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	s.logger.Info("Liquidity monitoring stopped")
+	return nil
+}
+
+// run 启动时立即扫描一次，之后按间隔扫描
+func (s *liquidityService) run(ctx context.Context) {
+	interval := s.config.BSC.Liquidity.Interval
+	if interval <= 0 {
+		interval = defaultLiquidityInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ScanNow(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("Liquidity scan failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScanNow 扫描游标之后的区块，首次扫描只回看max_blocks个区块
+func (s *liquidityService) ScanNow(ctx context.Context) error {
+	if s.redisClient == nil || s.bscService == nil {
+		return fmt.Errorf("liquidity monitoring is not available")
+	}
+
+	s.scanMutex.Lock()
+	defer s.scanMutex.Unlock()
+
+	// 等待自动发现
+	if len(s.pairs()) == 0 {
+		return nil
+	}
+
+	latestBlock, err := s.bscService.GetLatestBlock(ctx)
+	if err != nil {
+		return err
+	}
+	latest := latestBlock.Number.Uint64()
+
+	cursorValue, err := s.redisClient.Get(ctx, liquidityCursorKey)
+	if err != nil {
+		return err
+	}
+	cursor, _ := strconv.ParseUint(cursorValue, 10, 64)
+
+	maxBlocks := s.config.BSC.Liquidity.MaxBlocks
+	if maxBlocks == 0 {
+		maxBlocks = defaultLiquidityMaxBlocks
+	}
+	from := cursor + 1
+	if cursor == 0 && latest >= maxBlocks {
+		from = latest - maxBlocks + 1
+	}
+	if from > latest {
+		return nil
+	}
+	// 落后较多时分批追赶
+	to := latest
+	if to-from+1 > maxBlocks {
+		to = from + maxBlocks - 1
+	}
+
+	pairs := s.readPairs(ctx)
+	if len(pairs) == 0 {
+		return fmt.Errorf("%w: no pair could be read", ErrUpstreamUnavailable)
+	}
+
+	addresses := make([]common.Address, 0, len(pairs))
+	for address := range pairs {
+		addresses = append(addresses, address)
+	}
+	logs, err := s.bscService.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: addresses,
+		Topics:    [][]common.Hash{{pairBurnTopic, tokenTransferTopic}},
+	})
+	if err != nil {
+		return err
+	}
+
+	prices := s.priceTokens(ctx, pairs)
+	lockers := s.lockers()
+	threshold := s.config.BSC.Liquidity.ShareThreshold
+	if threshold <= 0 {
+		threshold = defaultLiquidityShareThreshold
+	}
+
+	suspicious := 0
+	for _, entry := range logs {
+		pair, ok := pairs[entry.Address]
+		if !ok || entry.Removed {
+			continue
+		}
+		event := decodeLiquidityEvent(entry, pair, lockers, prices)
+		if event == nil || event.SharePct < threshold*100 {
+			continue
+		}
+		suspicious++
+		s.record(ctx, event)
+	}
+
+	if err := s.redisClient.Set(ctx, liquidityCursorKey, strconv.FormatUint(to, 10), 0); err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-s.retention()).UnixMilli()
+	if err := s.redisClient.ZRemRangeByScore(ctx, liquidityEventsKey, "-inf", "("+strconv.FormatInt(cutoff, 10)); err != nil {
+		s.logger.Warnf("Failed to trim liquidity events: %v", err)
+	}
+
+	s.logger.Debugf("Scanned liquidity events in blocks %d-%d: logs=%d suspicious=%d", from, to, len(logs), suspicious)
+	return nil
+}
+
+// readPairs 读取所有监控交易对的当前状态，读取失败的交易对本轮跳过
+func (s *liquidityService) readPairs(ctx context.Context) map[common.Address]*watchedPair {
+	pairs := make(map[common.Address]*watchedPair)
+	for _, contract := range s.pairs() {
+		address := common.HexToAddress(contract.Address)
+		lpToken, err := s.reader.readToken(ctx, address)
+		if err != nil {
+			s.logger.Warnf("Failed to read pair %s: %v", contract.Name, err)
+			continue
+		}
+		state, err := s.reader.readPair(ctx, lpToken)
+		if err != nil {
+			s.logger.Warnf("Failed to read pair %s: %v", contract.Name, err)
+			continue
+		}
+		pairs[address] = &watchedPair{name: contract.Name, state: state}
+	}
+	return pairs
+}
+
+// priceTokens 为交易对中的代币定价
+func (s *liquidityService) priceTokens(ctx context.Context, pairs map[common.Address]*watchedPair) map[common.Address]float64 {
+	pricer := newUSDPricer(s.priceService)
+	prices := make(map[common.Address]float64)
+	states := make([]*pairState, 0, len(pairs))
+	for _, pair := range pairs {
+		for _, token := range pair.state.tokens {
+			if _, seen := prices[token.address]; !seen {
+				prices[token.address], _ = pricer.price(ctx, token.symbol)
+			}
+		}
+		states = append(states, pair.state)
+	}
+	derivePrices(states, prices)
+	return prices
+}
+
+// decodeLiquidityEvent 解析Burn事件或锁仓合约转出LP的Transfer事件，其他日志返回nil
+func decodeLiquidityEvent(entry types.Log, pair *watchedPair, lockers map[common.Address]bool, prices map[common.Address]float64) *model.LiquidityEvent {
+	if len(entry.Topics) < 3 {
+		return nil
+	}
+
+	state := pair.state
+	event := &model.LiquidityEvent{
+		ID:          fmt.Sprintf("%s:%d", strings.ToLower(entry.TxHash.Hex()), entry.Index),
+		Pair:        strings.ToLower(entry.Address.Hex()),
+		PairName:    pair.name,
+		TxHash:      entry.TxHash.Hex(),
+		BlockNumber: entry.BlockNumber,
+		Account:     strings.ToLower(common.BytesToAddress(entry.Topics[2].Bytes()).Hex()),
+		DetectedAt:  time.Now(),
+	}
+
+	switch entry.Topics[0] {
+	case pairBurnTopic:
+		if len(entry.Data) < 64 {
+			return nil
+		}
+		event.Type = model.LiquidityEventRemoval
+		event.Amount0 = toFloat(new(big.Int).SetBytes(entry.Data[:32]), state.tokens[0].decimals)
+		event.Amount1 = toFloat(new(big.Int).SetBytes(entry.Data[32:64]), state.tokens[1].decimals)
+
+		// 当前储备为撤出后的余额，撤出比例按撤出量/(撤出量+当前储备)估算
+		for i, amount := range []float64{event.Amount0, event.Amount1} {
+			if total := amount + state.reserves[i]; total > 0 {
+				if share := amount / total * 100; share > event.SharePct {
+					event.SharePct = share
+				}
+			}
+		}
+		event.ValueUSD = event.Amount0*prices[state.tokens[0].address] + event.Amount1*prices[state.tokens[1].address]
+	case tokenTransferTopic:
+		locker := common.BytesToAddress(entry.Topics[1].Bytes())
+		if !lockers[locker] || len(entry.Data) < 32 {
+			return nil
+		}
+		event.Type = model.LiquidityEventUnlock
+		event.Locker = strings.ToLower(locker.Hex())
+		event.LPAmount = toFloat(new(big.Int).SetBytes(entry.Data[:32]), state.token.decimals)
+		if state.totalSupply > 0 {
+			event.SharePct = event.LPAmount / state.totalSupply * 100
+			event.ValueUSD = event.LPAmount / state.totalSupply * state.value(prices)
+		}
+	default:
+		return nil
+	}
+	return event
+}
+
+// record 保存可疑事件并发送告警
+func (s *liquidityService) record(ctx context.Context, event *model.LiquidityEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err := s.redisClient.ZAdd(ctx, liquidityEventsKey, float64(event.DetectedAt.UnixMilli()), string(data)); err != nil {
+		s.logger.Warnf("Failed to save liquidity event %s: %v", event.ID, err)
+	}
+
+	if s.notifier == nil {
+		return
+	}
+
+	title := fmt.Sprintf("%.1f%% of %s liquidity removed", event.SharePct, event.PairName)
+	message := fmt.Sprintf("Transaction %s removed %.4f/%.4f from pair %s (%s) to %s, worth about $%.2f",
+		event.TxHash, event.Amount0, event.Amount1, event.PairName, event.Pair, event.Account, event.ValueUSD)
+	if event.Type == model.LiquidityEventUnlock {
+		title = fmt.Sprintf("%.1f%% of %s LP unlocked", event.SharePct, event.PairName)
+		message = fmt.Sprintf("Transaction %s moved %.4f LP of pair %s (%s) out of locker %s to %s, worth about $%.2f",
+			event.TxHash, event.LPAmount, event.PairName, event.Pair, event.Locker, event.Account, event.ValueUSD)
+	}
+
+	alert := &model.Alert{
+		Type:     AlertTypeLiquidityRemoval,
+		Severity: model.AlertSeverityCritical,
+		Title:    title,
+		Message:  message,
+		Subject:  event.Pair,
+		DedupKey: AlertTypeLiquidityRemoval + ":" + event.ID,
+		Data: map[string]interface{}{
+			"event_type": event.Type,
+			"tx_hash":    event.TxHash,
+			"share_pct":  event.SharePct,
+			"value_usd":  event.ValueUSD,
+		},
+	}
+	if err := s.notifier.Notify(ctx, alert); err != nil {
+		s.logger.Warnf("Failed to send liquidity alert for %s: %v", event.ID, err)
+	}
+}
+
+// GetEvents 获取最近的可疑流动性事件，按时间倒序
+func (s *liquidityService) GetEvents(ctx context.Context, pair string, limit int) (*model.LiquidityEventListResponse, error) {
+	if pair != "" && !common.IsHexAddress(pair) {
+		return nil, fmt.Errorf("%w: invalid pair address %q", ErrInvalidParameter, pair)
+	}
+	if limit <= 0 {
+		limit = defaultLiquidityEventLimit
+	}
+	pair = strings.ToLower(pair)
+
+	resp := &model.LiquidityEventListResponse{Events: []model.LiquidityEvent{}}
+	if s.redisClient == nil {
+		return resp, nil
+	}
+
+	members, err := s.redisClient.ZRangeByScore(ctx, liquidityEventsKey, "-inf", "+inf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load liquidity events: %w", err)
+	}
+	for i := len(members) - 1; i >= 0; i-- {
+		var event model.LiquidityEvent
+		if err := json.Unmarshal([]byte(members[i]), &event); err != nil {
+			continue
+		}
+		if pair != "" && event.Pair != pair {
+			continue
+		}
+		resp.Total++
+		if len(resp.Events) < limit {
+			resp.Events = append(resp.Events, event)
+		}
+	}
+	return resp, nil
+}
+
+// GetPairs 监控中的交易对，配置的在前
+func (s *liquidityService) GetPairs(ctx context.Context) (*model.MonitoredPairListResponse, error) {
+	discovered := make(map[string]model.DiscoveredPair)
+	if s.discovery != nil {
+		for _, pair := range s.discovery.Pairs() {
+			discovered[strings.ToLower(pair.Address)] = pair
+		}
+	}
+
+	pairs := s.pairs()
+	resp := &model.MonitoredPairListResponse{
+		Pairs: make([]model.MonitoredPair, 0, len(pairs)),
+		Total: len(pairs),
+	}
+	for _, contract := range pairs {
+		pair := model.MonitoredPair{Name: contract.Name, Address: contract.Address, Source: model.MonitoredPairSourceConfig}
+		if d, ok := discovered[strings.ToLower(contract.Address)]; ok && !s.configured(contract.Address) {
+			pair.Source = model.MonitoredPairSourceDiscovery
+			pair.Discovery = &d
+		}
+		resp.Pairs = append(resp.Pairs, pair)
+	}
+	return resp, nil
+}
+
+// pairs 监控的交易对，未单独配置时沿用TVL跟踪的交易对，再加上自动发现的交易对
+func (s *liquidityService) pairs() []config.PairContract {
+	configured := s.configuredPairs()
+	if s.discovery == nil {
+		return configured
+	}
+
+	// 多个关注代币可能发现同一个交易对
+	pairs := append([]config.PairContract(nil), configured...)
+	seen := make(map[string]bool)
+	for _, pair := range s.discovery.Pairs() {
+		address := strings.ToLower(pair.Address)
+		if seen[address] || s.configured(pair.Address) {
+			continue
+		}
+		seen[address] = true
+		pairs = append(pairs, config.PairContract{Name: pair.Name, Address: pair.Address})
+	}
+	return pairs
+}
+
+// configuredPairs 配置的监控交易对
+func (s *liquidityService) configuredPairs() []config.PairContract {
+	if len(s.config.BSC.Liquidity.Pairs) > 0 {
+		return s.config.BSC.Liquidity.Pairs
+	}
+	return s.config.BSC.TVL.Pairs
+}
+
+// configured 交易对是否已在配置中
+func (s *liquidityService) configured(address string) bool {
+	for _, pair := range s.configuredPairs() {
+		if strings.EqualFold(pair.Address, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// lockers LP锁仓合约地址集合
+func (s *liquidityService) lockers() map[common.Address]bool {
+	lockers := make(map[common.Address]bool, len(s.config.BSC.Liquidity.Lockers))
+	for _, address := range s.config.BSC.Liquidity.Lockers {
+		if common.IsHexAddress(address) {
+			lockers[common.HexToAddress(address)] = true
+		}
+	}
+	return lockers
+}
+
+// retention 事件保留时长
+func (s *liquidityService) retention() time.Duration {
+	if s.config.BSC.Liquidity.Retention > 0 {
+		return s.config.BSC.Liquidity.Retention
+	}
+	return defaultLiquidityRetention
+}
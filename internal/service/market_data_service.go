@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/coingecko"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+)
+
+// MarketDataService 市值数据服务接口
+type MarketDataService interface {
+	// GetMarketCaps 获取币种的市值、流通量和市值排名，symbols为空时返回所有支持的币种
+	GetMarketCaps(ctx context.Context, symbols []string) (*model.MarketCapResponse, error)
+	// GetGlobal 获取全市场总市值、24小时成交额和BTC/ETH市值占比
+	GetGlobal(ctx context.Context) (*model.GlobalMarketData, error)
+}
+
+// 市值查询配置
+const (
+	maxMarketCapSymbols  = 50
+	marketCapCurrency    = "USD"
+	marketCapSource      = "CoinGecko"
+	marketCapCachePrefix = "marketcap:"
+	globalMarketCacheKey = "market:global"
+)
+
+// marketDataService 市值数据服务实现，数据来自CoinGecko，按币种缓存
+type marketDataService struct {
+	redisClient   database.RedisClient
+	config        *config.Config
+	client        *coingecko.Client
+	ids           map[string]string
+	negativeCache *negativeCache
+}
+
+// NewMarketDataService 创建市值数据服务，business.coingecko_ids 覆盖内置的币种ID映射
+func NewMarketDataService(redisClient database.RedisClient, cfg *config.Config) MarketDataService {
+	ids := make(map[string]string, len(coingecko.DefaultIDs)+len(cfg.Business.CoinGeckoIDs))
+	for symbol, id := range coingecko.DefaultIDs {
+		ids[symbol] = id
+	}
+	// 配置文件的map key会被转为小写，统一按大写币种匹配
+	for symbol, id := range cfg.Business.CoinGeckoIDs {
+		ids[strings.ToUpper(symbol)] = id
+	}
+
+	return &marketDataService{
+		redisClient:   redisClient,
+		config:        cfg,
+		client:        coingecko.NewClient(&cfg.ExternalAPI.CoinGecko),
+		ids:           ids,
+		negativeCache: newNegativeCache(redisClient, cfg.Cache.NegativeTTL),
+	}
+}
+
+// GetMarketCaps 先读取各币种的缓存，未命中的币种合并为一次上游请求；部分币种失败时在errors中列出，全部失败时返回错误
+func (s *marketDataService) GetMarketCaps(ctx context.Context, symbols []string) (*model.MarketCapResponse, error) {
+	if len(symbols) == 0 {
+		symbols = s.config.Business.SupportedSymbols
+	}
+	symbols = uniqueSymbols(symbols)
+	if len(symbols) > maxMarketCapSymbols {
+		return nil, fmt.Errorf("%w: at most %d symbols", ErrInvalidParameter, maxMarketCapSymbols)
+	}
+
+	resp := &model.MarketCapResponse{
+		Currency: marketCapCurrency,
+		Source:   marketCapSource,
+		Coins:    []model.MarketCapData{},
+	}
+	if s.config.Business.MockDataEnabled {
+		resp.Source = "Mock Data"
+	}
+
+	var (
+		firstErr error
+		missing  = make(map[string]string) // CoinGecko ID -> 币种
+	)
+	fail := func(symbol string, err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+		resp.Errors = append(resp.Errors, model.SymbolError{Symbol: symbol, Error: err.Error()})
+	}
+
+	for _, symbol := range symbols {
+		id, ok := s.ids[symbol]
+		if !s.isSupportedSymbol(symbol) || !ok {
+			fail(symbol, fmt.Errorf("%w: %s", ErrUnsupportedSymbol, symbol))
+			continue
+		}
+		if err := s.negativeCache.get(ctx, marketCapCachePrefix+symbol); err != nil {
+			fail(symbol, err)
+			continue
+		}
+		if s.redisClient != nil {
+			if data, err := s.getFromCache(ctx, symbol); err == nil {
+				resp.Coins = append(resp.Coins, *data)
+				continue
+			}
+		}
+		missing[id] = symbol
+	}
+
+	if len(missing) > 0 {
+		fetched, err := s.fetch(ctx, missing)
+		if err != nil {
+			logger.From(ctx).Errorf("Failed to fetch market caps: %v", err)
+			err = fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+		}
+		for id, symbol := range missing {
+			data, ok := fetched[id]
+			if !ok {
+				symbolErr := err
+				if symbolErr == nil {
+					symbolErr = fmt.Errorf("%w: no market data for %s", ErrUnsupportedSymbol, symbol)
+				}
+				if cacheErr := s.negativeCache.set(ctx, marketCapCachePrefix+symbol, symbolErr); cacheErr != nil {
+					logger.From(ctx).Warnf("Failed to set negative cache for %s: %v", symbol, cacheErr)
+				}
+				fail(symbol, symbolErr)
+				continue
+			}
+			if cacheErr := s.setCache(ctx, data); cacheErr != nil {
+				logger.From(ctx).Warnf("Failed to cache market cap for %s: %v", symbol, cacheErr)
+			}
+			resp.Coins = append(resp.Coins, *data)
+		}
+	}
+
+	if len(resp.Coins) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+
+	sort.Slice(resp.Coins, func(i, j int) bool {
+		a, b := resp.Coins[i], resp.Coins[j]
+		if (a.Rank == 0) != (b.Rank == 0) {
+			return b.Rank == 0
+		}
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		return a.Symbol < b.Symbol
+	})
+	sort.Slice(resp.Errors, func(i, j int) bool { return resp.Errors[i].Symbol < resp.Errors[j].Symbol })
+	resp.GeneratedAt = time.Now().Format(time.RFC3339)
+	return resp, nil
+}
+
+// GetGlobal 优先读取缓存，未命中时请求CoinGecko并按global_ttl缓存，失败结果写入负缓存
+func (s *marketDataService) GetGlobal(ctx context.Context) (*model.GlobalMarketData, error) {
+	if err := s.negativeCache.get(ctx, globalMarketCacheKey); err != nil {
+		return nil, err
+	}
+	if s.redisClient != nil {
+		if cached, err := s.redisClient.Get(ctx, globalMarketCacheKey); err == nil && cached != "" {
+			var data model.GlobalMarketData
+			if err := json.Unmarshal([]byte(cached), &data); err == nil {
+				return &data, nil
+			}
+		}
+	}
+
+	data, err := s.fetchGlobal(ctx)
+	if err != nil {
+		logger.From(ctx).Errorf("Failed to fetch global market data: %v", err)
+		err = fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+		if cacheErr := s.negativeCache.set(ctx, globalMarketCacheKey, err); cacheErr != nil {
+			logger.From(ctx).Warnf("Failed to set negative cache for global market data: %v", cacheErr)
+		}
+		return nil, err
+	}
+
+	if s.redisClient != nil {
+		if encoded, err := json.Marshal(data); err == nil {
+			if err := s.redisClient.Set(ctx, globalMarketCacheKey, encoded, s.globalTTL()); err != nil {
+				logger.From(ctx).Warnf("Failed to cache global market data: %v", err)
+			}
+		}
+	}
+	return data, nil
+}
+
+// fetchGlobal 从CoinGecko获取全市场数据，只取美元计价的金额
+func (s *marketDataService) fetchGlobal(ctx context.Context) (*model.GlobalMarketData, error) {
+	if s.config.Business.MockDataEnabled {
+		return &model.GlobalMarketData{
+			Currency:               marketCapCurrency,
+			Source:                 "Mock Data",
+			TotalMarketCap:         1_700_000_000_000,
+			TotalVolume24h:         60_000_000_000,
+			MarketCapChange24h:     1.2,
+			BTCDominance:           52.0,
+			ETHDominance:           17.0,
+			ActiveCryptocurrencies: 10_000,
+			Markets:                900,
+			UpdatedAt:              time.Now(),
+		}, nil
+	}
+
+	global, err := s.client.GetGlobal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := strings.ToLower(marketCapCurrency)
+	totalMarketCap, ok := global.TotalMarketCap[currency]
+	if !ok {
+		return nil, fmt.Errorf("no %s total market cap in global data", marketCapCurrency)
+	}
+
+	data := &model.GlobalMarketData{
+		Currency:               marketCapCurrency,
+		Source:                 marketCapSource,
+		TotalMarketCap:         totalMarketCap,
+		TotalVolume24h:         global.TotalVolume[currency],
+		MarketCapChange24h:     global.MarketCapChangePercentage24hUSD,
+		BTCDominance:           global.MarketCapPercentage["btc"],
+		ETHDominance:           global.MarketCapPercentage["eth"],
+		ActiveCryptocurrencies: global.ActiveCryptocurrencies,
+		Markets:                global.Markets,
+		UpdatedAt:              time.Unix(global.UpdatedAt, 0).UTC(),
+	}
+	if global.UpdatedAt == 0 {
+		data.UpdatedAt = time.Now()
+	}
+	return data, nil
+}
+
+// fetch 批量获取币种市值，返回CoinGecko ID到市值的映射
+func (s *marketDataService) fetch(ctx context.Context, symbols map[string]string) (map[string]*model.MarketCapData, error) {
+	if s.config.Business.MockDataEnabled {
+		result := make(map[string]*model.MarketCapData, len(symbols))
+		for id, symbol := range symbols {
+			result[id] = s.generateMockMarketCap(symbol)
+		}
+		return result, nil
+	}
+
+	ids := make([]string, 0, len(symbols))
+	for id := range symbols {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	markets, err := s.client.GetMarkets(ctx, ids, marketCapCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*model.MarketCapData, len(markets))
+	for _, market := range markets {
+		symbol, ok := symbols[market.ID]
+		if !ok {
+			continue
+		}
+		data := &model.MarketCapData{
+			Symbol:            symbol,
+			Name:              market.Name,
+			Price:             valueOf(market.CurrentPrice),
+			MarketCap:         valueOf(market.MarketCap),
+			CirculatingSupply: valueOf(market.CirculatingSupply),
+			TotalSupply:       market.TotalSupply,
+			MaxSupply:         market.MaxSupply,
+			FullyDilutedValue: market.FullyDilutedValue,
+			Volume24h:         valueOf(market.TotalVolume),
+			UpdatedAt:         market.LastUpdated,
+		}
+		if market.MarketCapRank != nil {
+			data.Rank = *market.MarketCapRank
+		}
+		if data.UpdatedAt.IsZero() {
+			data.UpdatedAt = time.Now()
+		}
+		result[market.ID] = data
+	}
+	return result, nil
+}
+
+// generateMockMarketCap 生成模拟市值数据
+func (s *marketDataService) generateMockMarketCap(symbol string) *model.MarketCapData {
+	mockData := map[string]struct {
+		name   string
+		rank   int
+		price  float64
+		supply float64
+	}{
+		"BTC":  {"Bitcoin", 1, 45000.0, 19_700_000},
+		"ETH":  {"Ethereum", 2, 3000.0, 120_000_000},
+		"BNB":  {"BNB", 4, 300.0, 150_000_000},
+		"XRP":  {"XRP", 5, 0.6, 54_000_000_000},
+		"ADA":  {"Cardano", 9, 0.5, 35_000_000_000},
+		"DOT":  {"Polkadot", 14, 25.0, 1_300_000_000},
+		"LINK": {"Chainlink", 15, 20.0, 580_000_000},
+		"BCH":  {"Bitcoin Cash", 17, 400.0, 19_700_000},
+		"LTC":  {"Litecoin", 20, 150.0, 74_000_000},
+	}
+
+	mock, exists := mockData[symbol]
+	if !exists {
+		mock.name, mock.price, mock.supply = symbol, 100.0, 1_000_000
+	}
+	return &model.MarketCapData{
+		Symbol:            symbol,
+		Name:              mock.name,
+		Rank:              mock.rank,
+		Price:             mock.price,
+		MarketCap:         mock.price * mock.supply,
+		CirculatingSupply: mock.supply,
+		Volume24h:         mock.price * mock.supply * 0.02,
+		UpdatedAt:         time.Now(),
+	}
+}
+
+// isSupportedSymbol 检查是否支持该币种
+func (s *marketDataService) isSupportedSymbol(symbol string) bool {
+	for _, supported := range s.config.Business.SupportedSymbols {
+		if supported == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// marketCapTTL 市值缓存时间，未配置时使用default_ttl
+func (s *marketDataService) marketCapTTL() time.Duration {
+	if s.config.Cache.MarketCapTTL > 0 {
+		return s.config.Cache.MarketCapTTL
+	}
+	return s.config.Cache.DefaultTTL
+}
+
+// globalTTL 全市场数据缓存时间，未配置时使用default_ttl
+func (s *marketDataService) globalTTL() time.Duration {
+	if s.config.Cache.GlobalTTL > 0 {
+		return s.config.Cache.GlobalTTL
+	}
+	return s.config.Cache.DefaultTTL
+}
+
+// getFromCache 从缓存获取币种市值
+func (s *marketDataService) getFromCache(ctx context.Context, symbol string) (*model.MarketCapData, error) {
+	cachedData, err := s.redisClient.Get(ctx, marketCapCachePrefix+symbol)
+	if err != nil || cachedData == "" {
+		return nil, fmt.Errorf("cache miss")
+	}
+
+	var data model.MarketCapData
+	if err := json.Unmarshal([]byte(cachedData), &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// setCache 缓存币种市值
+func (s *marketDataService) setCache(ctx context.Context, data *model.MarketCapData) error {
+	if s.redisClient == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return s.redisClient.Set(ctx, marketCapCachePrefix+data.Symbol, encoded, s.marketCapTTL())
+}
+
+// uniqueSymbols 转为大写并去除空值和重复值，保留顺序
+func uniqueSymbols(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	result := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		result = append(result, symbol)
+	}
+	return result
+}
+
+// valueOf 数据源未提供的数值计为0
+func valueOf(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
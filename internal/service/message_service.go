@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto-info/internal/model"
 	"crypto-info/internal/pkg/mq"
+	"crypto-info/internal/pkg/precision"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -17,13 +18,15 @@ import (
 type MessageService struct {
 	mqClient *mq.RocketMQClient
 	logger   *logrus.Logger
+	webhooks WebhookService
 }
 
-// NewMessageService 创建消息服务
-func NewMessageService(mqClient *mq.RocketMQClient, logger *logrus.Logger) *MessageService {
+// NewMessageService 创建消息服务，消费到带用户的价格警报时投递到该用户注册的webhook，webhooks可为nil
+func NewMessageService(mqClient *mq.RocketMQClient, logger *logrus.Logger, webhooks WebhookService) *MessageService {
 	return &MessageService{
 		mqClient: mqClient,
 		logger:   logger,
+		webhooks: webhooks,
 	}
 }
 
@@ -42,13 +45,14 @@ const (
 	TagPriceAlert     = "price_alert"
 	TagSystemStartup  = "system_startup"
 	TagSystemShutdown = "system_shutdown"
+	TagUsageEvent     = "usage_event"
 )
 
 // PriceUpdateMessage 价格更新消息
 type PriceUpdateMessage struct {
 	Symbol    string  `json:"symbol"`
 	Price     float64 `json:"price"`
-	Change    float64 `json:"change"`
+	Change    float64 `json:"change"` // 24小时涨跌幅(%)
 	Timestamp int64   `json:"timestamp"`
 	Source    string  `json:"source"`
 }
@@ -68,7 +72,7 @@ type PriceAlertMessage struct {
 	Symbol      string  `json:"symbol"`
 	CurrentPrice float64 `json:"current_price"`
 	TargetPrice  float64 `json:"target_price"`
-	AlertType    string  `json:"alert_type"` // "above", "below"
+	AlertType    string  `json:"alert_type"` // "above", "below", "percent_change", "condition"
 	Timestamp    int64   `json:"timestamp"`
 	UserID       string  `json:"user_id,omitempty"`
 }
@@ -143,7 +147,7 @@ func (s *MessageService) subscribeTopics() error {
 }
 
 // PublishPriceUpdate 发布价格更新消息
-func (s *MessageService) PublishPriceUpdate(priceResp *model.PriceResponse) error {
+func (s *MessageService) PublishPriceUpdate(ctx context.Context, priceResp *model.PriceResponse) error {
 	if s.mqClient == nil || !s.mqClient.IsStarted() {
 		s.logger.Debug("MQ client not available, skipping price update message")
 		return nil
@@ -152,21 +156,24 @@ func (s *MessageService) PublishPriceUpdate(priceResp *model.PriceResponse) erro
 	msg := PriceUpdateMessage{
 		Symbol:    priceResp.Symbol,
 		Price:     priceResp.Price,
-		Change:    0.0, // 暂时设为0，需要从其他地方获取变化率
 		Timestamp: time.Now().Unix(),
 		Source:    priceResp.Source,
 	}
+	// 数据源不提供24小时统计时涨跌幅为0
+	if priceResp.Stats24h != nil {
+		msg.Change = priceResp.Stats24h.ChangePct
+	}
 
 	body, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal price update message: %w", err)
 	}
 
-	return s.mqClient.SendMessage(TopicPriceUpdate, TagPriceChange, body)
+	return s.mqClient.SendMessage(ctx, TopicPriceUpdate, TagPriceChange, body)
 }
 
 // PublishVolumeUpdate 发布交易量更新消息
-func (s *MessageService) PublishVolumeUpdate(volumeResp *model.VolumeAnalysisResponse) error {
+func (s *MessageService) PublishVolumeUpdate(ctx context.Context, volumeResp *model.VolumeAnalysisResponse) error {
 	if s.mqClient == nil || !s.mqClient.IsStarted() {
 		s.logger.Debug("MQ client not available, skipping volume update message")
 		return nil
@@ -186,11 +193,11 @@ func (s *MessageService) PublishVolumeUpdate(volumeResp *model.VolumeAnalysisRes
 		return fmt.Errorf("failed to marshal volume update message: %w", err)
 	}
 
-	return s.mqClient.SendMessage(TopicVolumeUpdate, TagVolumeSpike, body)
+	return s.mqClient.SendMessage(ctx, TopicVolumeUpdate, TagVolumeSpike, body)
 }
 
 // PublishPriceAlert 发布价格警报消息
-func (s *MessageService) PublishPriceAlert(symbol string, currentPrice, targetPrice float64, alertType, userID string) error {
+func (s *MessageService) PublishPriceAlert(ctx context.Context, symbol string, currentPrice, targetPrice float64, alertType, userID string) error {
 	if s.mqClient == nil || !s.mqClient.IsStarted() {
 		s.logger.Debug("MQ client not available, skipping price alert message")
 		return nil
@@ -210,11 +217,26 @@ func (s *MessageService) PublishPriceAlert(symbol string, currentPrice, targetPr
 		return fmt.Errorf("failed to marshal price alert message: %w", err)
 	}
 
-	return s.mqClient.SendMessage(TopicPriceAlert, TagPriceAlert, body)
+	return s.mqClient.SendMessage(ctx, TopicPriceAlert, TagPriceAlert, body)
+}
+
+// PublishUsageEvent 发布使用统计事件，主题由analytics.topic配置
+func (s *MessageService) PublishUsageEvent(ctx context.Context, topic string, event *model.UsageEvent) error {
+	if s.mqClient == nil || !s.mqClient.IsStarted() {
+		s.logger.Debug("MQ client not available, skipping usage event message")
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage event message: %w", err)
+	}
+
+	return s.mqClient.SendMessage(ctx, topic, TagUsageEvent, body)
 }
 
 // PublishSystemEvent 发布系统事件消息
-func (s *MessageService) PublishSystemEvent(eventType, message string, metadata map[string]interface{}) error {
+func (s *MessageService) PublishSystemEvent(ctx context.Context, eventType, message string, metadata map[string]interface{}) error {
 	if s.mqClient == nil || !s.mqClient.IsStarted() {
 		s.logger.Debug("MQ client not available, skipping system event message")
 		return nil
@@ -237,7 +259,7 @@ func (s *MessageService) PublishSystemEvent(eventType, message string, metadata
 		tag = TagSystemShutdown
 	}
 
-	return s.mqClient.SendMessage(TopicSystemEvent, tag, body)
+	return s.mqClient.SendMessage(ctx, TopicSystemEvent, tag, body)
 }
 
 // handlePriceUpdate 处理价格更新消息
@@ -249,8 +271,8 @@ func (s *MessageService) handlePriceUpdate(ctx context.Context, msgs ...*primiti
 			continue
 		}
 
-		s.logger.Infof("Received price update: %s = $%.2f (%.2f%%)", 
-			priceMsg.Symbol, priceMsg.Price, priceMsg.Change)
+		s.logger.Infof("Received price update: %s = $%s (%.2f%%)", 
+			priceMsg.Symbol, precision.Format(priceMsg.Symbol, priceMsg.Price), priceMsg.Change)
 
 		// 这里可以添加价格更新的业务逻辑
 		// 例如：更新缓存、触发警报、记录历史等
@@ -286,11 +308,14 @@ func (s *MessageService) handlePriceAlert(ctx context.Context, msgs ...*primitiv
 			continue
 		}
 
-		s.logger.Warnf("Price alert triggered: %s current price $%.2f %s target $%.2f", 
-			alertMsg.Symbol, alertMsg.CurrentPrice, alertMsg.AlertType, alertMsg.TargetPrice)
+		s.logger.Warnf("Price alert triggered: %s current price $%s %s target $%s", 
+			alertMsg.Symbol, precision.Format(alertMsg.Symbol, alertMsg.CurrentPrice), alertMsg.AlertType,
+			precision.Format(alertMsg.Symbol, alertMsg.TargetPrice))
 
-		// 这里可以添加价格警报的业务逻辑
-		// 例如：发送通知、邮件、短信等
+		// 投递失败已记录在投递记录中，可通过redrive接口重新投递，不重新消费
+		if alertMsg.UserID != "" && s.webhooks != nil {
+			s.webhooks.DeliverToUser(ctx, alertMsg.UserID, msg.MsgId, EventTypePriceAlert, msg.Body)
+		}
 	}
 
 	return consumer.ConsumeSuccess, nil
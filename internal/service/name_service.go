@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// 域名解析默认配置
+const (
+	defaultNameCacheTTL    = 6 * time.Hour
+	defaultNameNegativeTTL = 30 * time.Minute
+	nameLookupConcurrency  = 8
+	nameResolveKeyPrefix   = "names:resolve:"
+	nameReverseKeyPrefix   = "names:reverse:"
+)
+
+// nameRegistryABI ENS风格注册表与解析器的ABI
+const nameRegistryABI = `[
+	{"constant": true, "inputs": [{"name": "node", "type": "bytes32"}], "name": "resolver", "outputs": [{"name": "", "type": "address"}], "type": "function"},
+	{"constant": true, "inputs": [{"name": "node", "type": "bytes32"}], "name": "addr", "outputs": [{"name": "", "type": "address"}], "type": "function"},
+	{"constant": true, "inputs": [{"name": "node", "type": "bytes32"}], "name": "name", "outputs": [{"name": "", "type": "string"}], "type": "function"}
+]`
+
+// NameService 链上域名解析服务接口
+type NameService interface {
+	// Resolve 将域名解析为地址
+	Resolve(ctx context.Context, name string) (*model.NameRecord, error)
+	// Reverse 查询地址的主域名，域名需正向解析回同一地址
+	Reverse(ctx context.Context, address string) (*model.NameRecord, error)
+	// ReverseMany 批量查询主域名，只返回查到的地址，查询失败的地址忽略
+	ReverseMany(ctx context.Context, addresses []string) map[string]string
+}
+
+// contractCaller 调用合约只读方法
+type contractCaller func(ctx context.Context, contract common.Address, data []byte) ([]byte, error)
+
+// nameRegistry 单个域名服务的注册表
+type nameRegistry struct {
+	service  string
+	tld      string
+	registry common.Address
+	call     contractCaller
+}
+
+// nameService 通过Space ID和ENS注册表解析域名，结果缓存在Redis中
+type nameService struct {
+	redisClient database.RedisClient
+	config      *config.Config
+	registries  []*nameRegistry
+	abi         abi.ABI
+	logger      logger.Logger
+}
+
+// NewNameService 创建域名解析服务，注册表未配置RPC地址时通过bscService调用
+func NewNameService(redisClient database.RedisClient, cfg *config.Config, bscService BSCService) NameService {
+	log := logger.GetLogger()
+	parsed, err := abi.JSON(strings.NewReader(nameRegistryABI))
+	if err != nil {
+		log.Errorf("Failed to parse name registry ABI: %v", err)
+	}
+
+	s := &nameService{
+		redisClient: redisClient,
+		config:      cfg,
+		abi:         parsed,
+		logger:      log,
+	}
+	if !cfg.Names.Enabled {
+		return s
+	}
+
+	for _, entry := range []struct {
+		service string
+		config  config.NameRegistry
+	}{
+		{model.NameServiceSpaceID, cfg.Names.SpaceID},
+		{model.NameServiceENS, cfg.Names.ENS},
+	} {
+		if !entry.config.Enabled || entry.config.TLD == "" || !common.IsHexAddress(entry.config.Registry) {
+			continue
+		}
+
+		var call contractCaller
+		if entry.config.RPCURL != "" {
+			client, err := ethclient.Dial(entry.config.RPCURL)
+			if err != nil {
+				log.Warnf("Failed to connect to %s RPC, name resolution disabled: %v", entry.service, err)
+				continue
+			}
+			call = func(ctx context.Context, contract common.Address, data []byte) ([]byte, error) {
+				return client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+			}
+		} else if bscService != nil {
+			call = bscService.CallContract
+		} else {
+			continue
+		}
+
+		s.registries = append(s.registries, &nameRegistry{
+			service:  entry.service,
+			tld:      strings.ToLower(strings.TrimPrefix(entry.config.TLD, ".")),
+			registry: common.HexToAddress(entry.config.Registry),
+			call:     call,
+		})
+	}
+	return s
+}
+
+// Resolve 将域名解析为地址
+func (s *nameService) Resolve(ctx context.Context, name string) (*model.NameRecord, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") || strings.Contains(name, "..") || !strings.Contains(name, ".") {
+		return nil, fmt.Errorf("%w: invalid name %q", ErrInvalidParameter, name)
+	}
+	if len(s.registries) == 0 {
+		return nil, fmt.Errorf("%w: name resolution is not enabled", ErrUpstreamUnavailable)
+	}
+
+	tld := name[strings.LastIndex(name, ".")+1:]
+	var registry *nameRegistry
+	for _, r := range s.registries {
+		if r.tld == tld {
+			registry = r
+			break
+		}
+	}
+	if registry == nil {
+		return nil, fmt.Errorf("%w: unsupported name suffix .%s", ErrInvalidParameter, tld)
+	}
+
+	cacheKey := nameResolveKeyPrefix + name
+	if record, ok := s.cached(ctx, cacheKey); ok {
+		if record.Address == "" {
+			return nil, fmt.Errorf("%w: name %s", ErrNotFound, name)
+		}
+		return record, nil
+	}
+
+	address, err := s.lookupAddress(ctx, registry, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: resolve %s: %v", ErrUpstreamUnavailable, name, err)
+	}
+
+	record := &model.NameRecord{Name: name, Service: registry.service}
+	if address == (common.Address{}) {
+		s.cache(ctx, cacheKey, record, s.negativeTTL())
+		return nil, fmt.Errorf("%w: name %s", ErrNotFound, name)
+	}
+	record.Address = strings.ToLower(address.Hex())
+	s.cache(ctx, cacheKey, record, s.cacheTTL())
+	return record, nil
+}
+
+// Reverse 依次查询各域名服务的反向记录
+func (s *nameService) Reverse(ctx context.Context, address string) (*model.NameRecord, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidParameter, address)
+	}
+	if len(s.registries) == 0 {
+		return nil, fmt.Errorf("%w: name resolution is not enabled", ErrUpstreamUnavailable)
+	}
+
+	owner := common.HexToAddress(address)
+	address = strings.ToLower(owner.Hex())
+	cacheKey := nameReverseKeyPrefix + address
+	if record, ok := s.cached(ctx, cacheKey); ok {
+		if record.Name == "" {
+			return nil, fmt.Errorf("%w: no name for %s", ErrNotFound, address)
+		}
+		return record, nil
+	}
+
+	var lastErr error
+	failed := 0
+	for _, registry := range s.registries {
+		name, err := s.lookupName(ctx, registry, owner)
+		if err != nil {
+			lastErr = err
+			failed++
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		record := &model.NameRecord{Name: name, Address: address, Service: registry.service}
+		s.cache(ctx, cacheKey, record, s.cacheTTL())
+		return record, nil
+	}
+
+	// 全部注册表查询失败时不缓存，避免把节点故障当成未注册
+	if failed == len(s.registries) {
+		return nil, fmt.Errorf("%w: reverse %s: %v", ErrUpstreamUnavailable, address, lastErr)
+	}
+	s.cache(ctx, cacheKey, &model.NameRecord{Address: address}, s.negativeTTL())
+	return nil, fmt.Errorf("%w: no name for %s", ErrNotFound, address)
+}
+
+// ReverseMany 并发查询多个地址的主域名
+func (s *nameService) ReverseMany(ctx context.Context, addresses []string) map[string]string {
+	names := make(map[string]string)
+	if len(s.registries) == 0 {
+		return names
+	}
+
+	seen := make(map[string]bool, len(addresses))
+	unique := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if address == "" || seen[address] || !common.IsHexAddress(address) {
+			continue
+		}
+		seen[address] = true
+		unique = append(unique, address)
+	}
+
+	results := fanOut(ctx, unique, nameLookupConcurrency, func(ctx context.Context, address string) (*model.NameRecord, error) {
+		return s.Reverse(ctx, address)
+	})
+	for _, result := range results {
+		if result.Err == nil {
+			names[result.Key] = result.Value.Name
+		}
+	}
+	return names
+}
+
+// lookupAddress 查询域名的解析器再读取地址，未注册时返回零地址
+func (s *nameService) lookupAddress(ctx context.Context, registry *nameRegistry, name string) (common.Address, error) {
+	node := namehash(name)
+	resolver, err := s.resolver(ctx, registry, node)
+	if err != nil || resolver == (common.Address{}) {
+		return common.Address{}, err
+	}
+
+	// 解析器未实现addr时按未设置处理
+	out, err := s.call(ctx, registry, resolver, "addr", node)
+	if err != nil || len(out) == 0 {
+		return common.Address{}, nil
+	}
+	address, _ := out[0].(common.Address)
+	return address, nil
+}
+
+// lookupName 查询地址的反向记录，并确认域名正向解析回该地址
+func (s *nameService) lookupName(ctx context.Context, registry *nameRegistry, owner common.Address) (string, error) {
+	node := namehash(strings.ToLower(strings.TrimPrefix(owner.Hex(), "0x")) + ".addr.reverse")
+	resolver, err := s.resolver(ctx, registry, node)
+	if err != nil || resolver == (common.Address{}) {
+		return "", err
+	}
+
+	out, err := s.call(ctx, registry, resolver, "name", node)
+	if err != nil || len(out) == 0 {
+		return "", nil
+	}
+	name, _ := out[0].(string)
+	name = strings.ToLower(name)
+	if name == "" || !strings.HasSuffix(name, "."+registry.tld) {
+		return "", nil
+	}
+
+	// 反向记录可由任何人设置，必须正向校验
+	address, err := s.lookupAddress(ctx, registry, name)
+	if err != nil {
+		return "", err
+	}
+	if address != owner {
+		return "", nil
+	}
+	return name, nil
+}
+
+// resolver 从注册表读取节点的解析器地址
+func (s *nameService) resolver(ctx context.Context, registry *nameRegistry, node common.Hash) (common.Address, error) {
+	out, err := s.call(ctx, registry, registry.registry, "resolver", node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(out) == 0 {
+		return common.Address{}, fmt.Errorf("resolver returned no value")
+	}
+	resolver, _ := out[0].(common.Address)
+	return resolver, nil
+}
+
+// call 调用注册表或解析器的只读方法
+func (s *nameService) call(ctx context.Context, registry *nameRegistry, contract common.Address, method string, node common.Hash) ([]interface{}, error) {
+	data, err := s.abi.Pack(method, node)
+	if err != nil {
+		return nil, err
+	}
+	out, err := registry.call(ctx, contract, data)
+	if err != nil {
+		return nil, err
+	}
+	return s.abi.Unpack(method, out)
+}
+
+// cached 读取缓存的解析结果，未注册的名称或地址缓存为空记录
+func (s *nameService) cached(ctx context.Context, key string) (*model.NameRecord, bool) {
+	if s.redisClient == nil {
+		return nil, false
+	}
+	data, err := s.redisClient.Get(ctx, key)
+	if err != nil || data == "" {
+		return nil, false
+	}
+	var record model.NameRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, false
+	}
+	return &record, true
+}
+
+// cache 缓存解析结果
+func (s *nameService) cache(ctx context.Context, key string, record *model.NameRecord, ttl time.Duration) {
+	if s.redisClient == nil {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	if err := s.redisClient.Set(ctx, key, string(data), ttl); err != nil {
+		s.logger.Warnf("Failed to cache name record %s: %v", key, err)
+	}
+}
+
+// cacheTTL 解析结果缓存时间
+func (s *nameService) cacheTTL() time.Duration {
+	if s.config.Names.CacheTTL > 0 {
+		return s.config.Names.CacheTTL
+	}
+	return defaultNameCacheTTL
+}
+
+// negativeTTL 未注册结果的缓存时间
+func (s *nameService) negativeTTL() time.Duration {
+	if s.config.Names.NegativeTTL > 0 {
+		return s.config.Names.NegativeTTL
+	}
+	return defaultNameNegativeTTL
+}
+
+// namehash 按EIP-137计算域名节点哈希
+func namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := crypto.Keccak256Hash([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node.Bytes(), label.Bytes())
+	}
+	return node
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"crypto-info/internal/pkg/database"
+)
+
+// defaultNegativeTTL 负缓存默认过期时间
+const defaultNegativeTTL = 30 * time.Second
+
+// 负缓存记录类别
+const (
+	negativeKindUnsupported = "unsupported"
+	negativeKindUpstream    = "upstream"
+)
+
+// negativeEntry 负缓存记录
+type negativeEntry struct {
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+// negativeCache 缓存上游获取失败或查不到数据的结果，避免重复请求上游
+type negativeCache struct {
+	redisClient database.RedisClient
+	ttl         time.Duration
+}
+
+// newNegativeCache 创建负缓存
+func newNegativeCache(redisClient database.RedisClient, ttl time.Duration) *negativeCache {
+	if ttl <= 0 {
+		ttl = defaultNegativeTTL
+	}
+	return &negativeCache{
+		redisClient: redisClient,
+		ttl:         ttl,
+	}
+}
+
+// get 查询负缓存，命中时返回*NegativeCacheError
+func (n *negativeCache) get(ctx context.Context, key string) error {
+	if n == nil || n.redisClient == nil {
+		return nil
+	}
+
+	data, err := n.redisClient.Get(ctx, "neg:"+key)
+	if err != nil || data == "" {
+		return nil
+	}
+
+	var entry negativeEntry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		return nil
+	}
+
+	kind := ErrUpstreamUnavailable
+	if entry.Kind == negativeKindUnsupported {
+		kind = ErrUnsupportedSymbol
+	}
+	return &NegativeCacheError{Err: kind, Reason: entry.Reason}
+}
+
+// set 写入负缓存
+func (n *negativeCache) set(ctx context.Context, key string, cause error) error {
+	if n == nil || n.redisClient == nil || cause == nil {
+		return nil
+	}
+
+	// 请求取消不代表上游失败，不做缓存
+	if errors.Is(cause, context.Canceled) || errors.Is(cause, context.DeadlineExceeded) {
+		return nil
+	}
+
+	entry := negativeEntry{Kind: negativeKindUpstream, Reason: cause.Error()}
+	if errors.Is(cause, ErrUnsupportedSymbol) {
+		entry.Kind = negativeKindUnsupported
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return n.redisClient.Set(ctx, "neg:"+key, data, n.ttl)
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+)
+
+// 告警通知默认配置
+const (
+	defaultAlertRetention = 7 * 24 * time.Hour
+	defaultAlertCooldown  = 30 * time.Minute
+	defaultAlertLimit     = 100
+	alertLogKey           = "alerts:recent"
+	alertDedupKeyPrefix   = "alerts:dedup:"
+)
+
+// Notifier 告警通知接口
+type Notifier interface {
+	// Notify 发送告警，冷却期内重复的告警会被忽略
+	Notify(ctx context.Context, alert *model.Alert) error
+	// Deliver 发送告警并返回各通知渠道的投递结果，重复告警被忽略时返回空结果
+	Deliver(ctx context.Context, alert *model.Alert) ([]model.AlertDelivery, error)
+	// ListAlerts 获取最近的告警，alertType为空时返回全部类型
+	ListAlerts(ctx context.Context, alertType string, limit int) (*model.AlertListResponse, error)
+	// FlushDigests 发送被限流告警的摘要
+	FlushDigests(ctx context.Context) error
+}
+
+// alertChannel 告警通知渠道，服务日志不作为渠道，始终记录
+type alertChannel struct {
+	name    string
+	accepts func(alert *model.Alert) bool
+	send    func(ctx context.Context, alert *model.Alert) []model.AlertDelivery
+}
+
+// notifier 记录告警日志并保存到Redis有序集合
+type notifier struct {
+	redisClient   database.RedisClient
+	config        *config.Config
+	logger        logger.Logger
+	streamService StreamService
+	webhooks      WebhookService
+	telegram      *telegramChannel // 未启用时为空
+	email         *emailChannel    // 未启用时为空
+	discord       *discordChannel  // 未启用时为空
+}
+
+// NewNotifier 创建告警通知器，告警同时通过streamService推送并投递到配置的webhook、Telegram、邮件和Discord
+func NewNotifier(redisClient database.RedisClient, cfg *config.Config, streamService StreamService, webhooks WebhookService) Notifier {
+	return &notifier{
+		redisClient:   redisClient,
+		config:        cfg,
+		logger:        logger.GetLogger(),
+		streamService: streamService,
+		webhooks:      webhooks,
+		telegram:      newTelegramChannel(cfg),
+		email:         newEmailChannel(cfg),
+		discord:       newDiscordChannel(cfg),
+	}
+}
+
+// Notify 发送告警
+func (n *notifier) Notify(ctx context.Context, alert *model.Alert) error {
+	_, err := n.Deliver(ctx, alert)
+	return err
+}
+
+// Deliver 发送告警，渠道投递失败只记录在结果中，保存告警失败时返回错误
+func (n *notifier) Deliver(ctx context.Context, alert *model.Alert) ([]model.AlertDelivery, error) {
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+	if alert.ID == "" {
+		alert.ID = alert.Type + "-" + strconv.FormatInt(alert.CreatedAt.UnixNano(), 36)
+	}
+
+	if alert.DedupKey != "" && n.redisClient != nil {
+		dedupKey := alertDedupKeyPrefix + alert.DedupKey
+		exists, err := n.redisClient.Exists(ctx, dedupKey)
+		if err != nil {
+			return nil, err
+		}
+		if exists > 0 {
+			n.logger.Debugf("Suppressed duplicate alert %s", alert.DedupKey)
+			return nil, nil
+		}
+		if err := n.redisClient.Set(ctx, dedupKey, alert.ID, n.cooldown()); err != nil {
+			return nil, err
+		}
+	}
+
+	switch alert.Severity {
+	case model.AlertSeverityCritical:
+		n.logger.Errorf("[ALERT] %s: %s", alert.Title, alert.Message)
+	case model.AlertSeverityWarning:
+		n.logger.Warnf("[ALERT] %s: %s", alert.Title, alert.Message)
+	default:
+		n.logger.Infof("[ALERT] %s: %s", alert.Title, alert.Message)
+	}
+	deliveries := []model.AlertDelivery{{Channel: model.AlertChannelLog, Success: true}}
+	deliveries = append(deliveries, n.dispatch(ctx, alert)...)
+
+	if n.redisClient == nil {
+		return deliveries, nil
+	}
+
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return deliveries, err
+	}
+	if err := n.redisClient.ZAdd(ctx, alertLogKey, float64(alert.CreatedAt.UnixMilli()), string(data)); err != nil {
+		return deliveries, fmt.Errorf("failed to save alert: %w", err)
+	}
+
+	cutoff := time.Now().Add(-n.retention()).UnixMilli()
+	if err := n.redisClient.ZRemRangeByScore(ctx, alertLogKey, "-inf", "("+strconv.FormatInt(cutoff, 10)); err != nil {
+		n.logger.Warnf("Failed to trim alert log: %v", err)
+	}
+	return deliveries, nil
+}
+
+// ListAlerts 获取最近的告警，按时间倒序
+func (n *notifier) ListAlerts(ctx context.Context, alertType string, limit int) (*model.AlertListResponse, error) {
+	if limit <= 0 {
+		limit = defaultAlertLimit
+	}
+
+	resp := &model.AlertListResponse{Alerts: []model.Alert{}}
+	if n.redisClient == nil {
+		return resp, nil
+	}
+
+	members, err := n.redisClient.ZRangeByScore(ctx, alertLogKey, "-inf", "+inf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alerts: %w", err)
+	}
+	for i := len(members) - 1; i >= 0; i-- {
+		var alert model.Alert
+		if err := json.Unmarshal([]byte(members[i]), &alert); err != nil {
+			continue
+		}
+		if alertType != "" && alert.Type != alertType {
+			continue
+		}
+		resp.Total++
+		if len(resp.Alerts) < limit {
+			resp.Alerts = append(resp.Alerts, alert)
+		}
+	}
+	return resp, nil
+}
+
+// channels 已配置的通知渠道
+func (n *notifier) channels() []alertChannel {
+	var channels []alertChannel
+	if n.streamService != nil {
+		channels = append(channels, alertChannel{
+			name: model.AlertChannelStream,
+			// panic告警包含调用栈，只发往运维渠道
+			accepts: func(alert *model.Alert) bool { return alert.Type != AlertTypePanic },
+			send: func(ctx context.Context, alert *model.Alert) []model.AlertDelivery {
+				return []model.AlertDelivery{n.publish(ctx, alert)}
+			},
+		})
+	}
+	if n.webhooks != nil {
+		channels = append(channels, alertChannel{
+			name:    model.AlertChannelWebhook,
+			accepts: n.webhooks.Accepts,
+			send:    n.webhooks.Deliver,
+		})
+	}
+	if n.telegram != nil {
+		channels = append(channels, alertChannel{
+			name:    model.AlertChannelTelegram,
+			accepts: n.telegram.accepts,
+			send:    n.telegram.send,
+		})
+	}
+	if n.email != nil {
+		channels = append(channels, alertChannel{
+			name:    model.AlertChannelEmail,
+			accepts: n.email.accepts,
+			send:    n.email.send,
+		})
+	}
+	if n.discord != nil {
+		channels = append(channels, alertChannel{
+			name:    model.AlertChannelDiscord,
+			accepts: n.discord.accepts,
+			send:    n.discord.send,
+		})
+	}
+	return channels
+}
+
+// dispatch 通过各渠道发送告警，超出发送频率限制的渠道改为加入摘要
+func (n *notifier) dispatch(ctx context.Context, alert *model.Alert) []model.AlertDelivery {
+	var channels []alertChannel
+	for _, channel := range n.channels() {
+		if channel.accepts(alert) {
+			channels = append(channels, channel)
+		}
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+
+	userAllowed := n.allowUser(ctx, alert.UserID)
+	var deliveries []model.AlertDelivery
+	for _, channel := range channels {
+		if !userAllowed || !n.allowChannel(ctx, channel.name) {
+			deliveries = append(deliveries, n.digest(ctx, channel.name, alert))
+			continue
+		}
+		deliveries = append(deliveries, channel.send(ctx, alert)...)
+	}
+	return deliveries
+}
+
+// publish 通过实时推送渠道发送告警
+func (n *notifier) publish(ctx context.Context, alert *model.Alert) model.AlertDelivery {
+	delivery := model.AlertDelivery{Channel: model.AlertChannelStream}
+	start := time.Now()
+	data, err := json.Marshal(alert)
+	if err == nil {
+		err = n.streamService.Publish(ctx, &model.StreamEvent{Type: model.StreamEventAlert, Data: data})
+	}
+	delivery.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		n.logger.Warnf("Failed to publish alert %s: %v", alert.ID, err)
+		delivery.Error = err.Error()
+		return delivery
+	}
+	delivery.Success = true
+	return delivery
+}
+
+// retention 告警记录保留时间
+func (n *notifier) retention() time.Duration {
+	if n.config.Notifier.Retention > 0 {
+		return n.config.Notifier.Retention
+	}
+	return defaultAlertRetention
+}
+
+// cooldown 重复告警的最小间隔
+func (n *notifier) cooldown() time.Duration {
+	if n.config.Notifier.Cooldown > 0 {
+		return n.config.Notifier.Cooldown
+	}
+	return defaultAlertCooldown
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/model"
+)
+
+// 告警发送频率限制默认配置
+const (
+	defaultNotifyRateWindow     = time.Minute
+	defaultNotifyDigestInterval = 5 * time.Minute
+	maxDigestEntries            = 500 // 单个摘要保留的告警数，超出时丢弃最早的
+	maxDigestLines              = 20  // 摘要正文列出的告警数
+	notifyRateKeyPrefix         = "alerts:ratelimit:"
+	notifyDigestKeyPrefix       = "alerts:digest:"
+	notifyDigestPendingKey      = "alerts:digest:pending"
+)
+
+// allowUser 用户在当前窗口内是否还有告警额度，系统告警不受限制
+func (n *notifier) allowUser(ctx context.Context, userID string) bool {
+	limit := n.config.Notifier.RateLimit.PerUser
+	if userID == "" || limit <= 0 {
+		return true
+	}
+	return n.allow(ctx, "user:"+userID, limit)
+}
+
+// allowChannel 渠道在当前窗口内是否还有发送额度
+func (n *notifier) allowChannel(ctx context.Context, channel string) bool {
+	return n.allow(ctx, "channel:"+channel, n.config.Notifier.RateLimit.Channels[channel])
+}
+
+// allow 固定窗口计数，Redis不可用时放行，避免限流故障导致告警丢失
+func (n *notifier) allow(ctx context.Context, name string, limit int) bool {
+	if !n.config.Notifier.RateLimit.Enabled || n.redisClient == nil || limit <= 0 {
+		return true
+	}
+
+	window := n.rateWindow()
+	bucket := time.Now().UnixNano() / int64(window)
+	key := n.redisClient.KeyPrefix() + notifyRateKeyPrefix + name + ":" + strconv.FormatInt(bucket, 10)
+
+	pipe := n.redisClient.GetClient().TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, 2*window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		n.logger.Warnf("Failed to check alert rate limit %s: %v", name, err)
+		return true
+	}
+	return count.Val() <= int64(limit)
+}
+
+// digest 将告警加入渠道的摘要，稍后合并发送
+func (n *notifier) digest(ctx context.Context, channel string, alert *model.Alert) model.AlertDelivery {
+	delivery := model.AlertDelivery{Channel: channel, Digested: true}
+	data, err := json.Marshal(alert)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	member := digestMember(channel, alert.UserID)
+	key := n.redisClient.KeyPrefix() + notifyDigestKeyPrefix + member
+	pipe := n.redisClient.GetClient().TxPipeline()
+	pipe.RPush(ctx, key, data)
+	pipe.LTrim(ctx, key, -maxDigestEntries, -1)
+	pipe.SAdd(ctx, n.redisClient.KeyPrefix()+notifyDigestPendingKey, member)
+	if _, err := pipe.Exec(ctx); err != nil {
+		n.logger.Warnf("Failed to add alert %s to %s digest: %v", alert.ID, channel, err)
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	n.logger.Debugf("Alert %s rate limited on %s, added to digest", alert.ID, channel)
+	delivery.Success = true
+	return delivery
+}
+
+// FlushDigests 发送所有待发送的摘要，由定时任务alert_digest调用
+func (n *notifier) FlushDigests(ctx context.Context) error {
+	if n.redisClient == nil {
+		return nil
+	}
+	client := n.redisClient.GetClient()
+	prefix := n.redisClient.KeyPrefix()
+
+	members, err := client.SMembers(ctx, prefix+notifyDigestPendingKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to load pending digests: %w", err)
+	}
+
+	channels := make(map[string]alertChannel)
+	for _, channel := range n.channels() {
+		channels[channel.name] = channel
+	}
+	for _, member := range members {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// 取出摘要内容并移出待发送集合，之后新加入的告警会重新加入集合
+		key := prefix + notifyDigestKeyPrefix + member
+		pipe := client.TxPipeline()
+		pipe.SRem(ctx, prefix+notifyDigestPendingKey, member)
+		entries := pipe.LRange(ctx, key, 0, -1)
+		pipe.Del(ctx, key)
+		if _, err := pipe.Exec(ctx); err != nil {
+			n.logger.Warnf("Failed to load alert digest %s: %v", member, err)
+			continue
+		}
+
+		name, userID := parseDigestMember(member)
+		channel, ok := channels[name]
+		if !ok {
+			n.logger.Warnf("Dropping %d digested alerts for removed channel %s", len(entries.Val()), name)
+			continue
+		}
+
+		alerts := make([]model.Alert, 0, len(entries.Val()))
+		for _, entry := range entries.Val() {
+			var alert model.Alert
+			if err := json.Unmarshal([]byte(entry), &alert); err == nil {
+				alerts = append(alerts, alert)
+			}
+		}
+		if len(alerts) == 0 {
+			continue
+		}
+
+		for _, delivery := range channel.send(ctx, digestAlert(name, userID, alerts)) {
+			if !delivery.Success {
+				n.logger.Warnf("Failed to send alert digest to %s: %s", name, delivery.Error)
+			}
+		}
+	}
+	return nil
+}
+
+// rateWindow 发送频率的计数窗口
+func (n *notifier) rateWindow() time.Duration {
+	if n.config.Notifier.RateLimit.Window > 0 {
+		return n.config.Notifier.RateLimit.Window
+	}
+	return defaultNotifyRateWindow
+}
+
+// digestAlert 将被限流的告警合并为一条摘要，级别取其中最高的
+func digestAlert(channel, userID string, alerts []model.Alert) *model.Alert {
+	severity := model.AlertSeverityInfo
+	typeSet := make(map[string]bool)
+	lines := make([]string, 0, maxDigestLines+1)
+	for i, alert := range alerts {
+		switch {
+		case alert.Severity == model.AlertSeverityCritical:
+			severity = model.AlertSeverityCritical
+		case alert.Severity == model.AlertSeverityWarning && severity == model.AlertSeverityInfo:
+			severity = model.AlertSeverityWarning
+		}
+		typeSet[alert.Type] = true
+		if i < maxDigestLines {
+			lines = append(lines, fmt.Sprintf("%s %s", alert.CreatedAt.Format("15:04:05"), alert.Title))
+		}
+	}
+	if len(alerts) > maxDigestLines {
+		lines = append(lines, fmt.Sprintf("另有%d条告警", len(alerts)-maxDigestLines))
+	}
+
+	types := make([]string, 0, len(typeSet))
+	for t := range typeSet {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	now := time.Now()
+	return &model.Alert{
+		ID:       model.AlertTypeDigest + "-" + strconv.FormatInt(now.UnixNano(), 36),
+		Type:     model.AlertTypeDigest,
+		Severity: severity,
+		Title:    fmt.Sprintf("告警摘要: %d条告警因发送频率限制合并发送", len(alerts)),
+		Message:  strings.Join(lines, "\n"),
+		UserID:   userID,
+		Data: map[string]interface{}{
+			"channel": channel,
+			"count":   len(alerts),
+			"types":   types,
+			"alerts":  alerts,
+		},
+		CreatedAt: now,
+	}
+}
+
+// digestMember 待发送集合中的成员，渠道名不含分隔符，系统告警的用户为空
+func digestMember(channel, userID string) string {
+	return channel + "|" + userID
+}
+
+// parseDigestMember 解析digestMember生成的成员
+func parseDigestMember(member string) (string, string) {
+	channel, userID, _ := strings.Cut(member, "|")
+	return channel, userID
+}
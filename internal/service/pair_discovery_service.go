@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 交易对自动发现默认配置
+const (
+	defaultDiscoveryInterval = time.Hour
+	defaultDiscoveryMaxPairs = 3
+	defaultPancakeFactory    = "0xcA143Ce32Fe78f1f7019d7d551a6402fC5350c73"
+	discoveredPairsKey       = "liquidity:discovered_pairs"
+)
+
+// factoryABI DEX Factory合约ABI，只包含按代币查询交易对的方法
+const factoryABI = `[
+	{"constant": true, "inputs": [{"name": "tokenA", "type": "address"}, {"name": "tokenB", "type": "address"}], "name": "getPair", "outputs": [{"name": "pair", "type": "address"}], "type": "function"}
+]`
+
+// PairDiscoveryService 交易对自动发现服务接口
+type PairDiscoveryService interface {
+	Start(ctx context.Context) error
+	Stop() error
+	// DiscoverNow 立即重新发现所有关注代币的交易对
+	DiscoverNow(ctx context.Context) error
+	// Pairs 最近一次发现的交易对
+	Pairs() []model.DiscoveredPair
+	// Enabled 是否启用了交易对自动发现
+	Enabled() bool
+}
+
+// pairDiscoveryService 通过Factory查找关注代币与计价代币的交易对，按关注代币在池中的储备量取前max_pairs个
+type pairDiscoveryService struct {
+	redisClient database.RedisClient
+	config      *config.Config
+	reader      *contractReader
+	factoryABI  abi.ABI
+	logger      logger.Logger
+
+	mu    sync.RWMutex
+	pairs []model.DiscoveredPair
+
+	runMutex sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewPairDiscoveryService 创建交易对自动发现服务
+func NewPairDiscoveryService(redisClient database.RedisClient, cfg *config.Config, bscService BSCService) PairDiscoveryService {
+	parsed, err := abi.JSON(strings.NewReader(factoryABI))
+	if err != nil {
+		logger.GetLogger().Errorf("Failed to parse factory ABI: %v", err)
+	}
+	return &pairDiscoveryService{
+		redisClient: redisClient,
+		config:      cfg,
+		reader:      newContractReader(bscService),
+		factoryABI:  parsed,
+		logger:      logger.GetLogger(),
+	}
+}
+
+// Enabled 启用了自动发现且配置了关注代币
+func (s *pairDiscoveryService) Enabled() bool {
+	discovery := s.config.BSC.Liquidity.Discovery
+	return s.config.BSC.Enabled && discovery.Enabled && len(discovery.Tokens) > 0
+}
+
+// Start 加载上次发现的交易对后启动定时发现，使流动性监控在首次发现完成前即可使用
+func (s *pairDiscoveryService) Start(ctx context.Context) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("pair discovery is already running")
+	}
+
+	if err := s.load(ctx); err != nil {
+		s.logger.Warnf("Failed to load discovered pairs: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "pair_discovery", s.run, s.done)
+
+	s.logger.Infof("Pair discovery started for %d tokens", len(s.config.BSC.Liquidity.Discovery.Tokens))
+	return nil
+}
+
+// Stop 停止定时发现
+func (s *pairDiscoveryService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	s.logger.Info("Pair discovery stopped")
+	return nil
+}
+
+// run 启动时立即发现一次，之后按间隔重新发现
+func (s *pairDiscoveryService) run(ctx context.Context) {
+	interval := s.config.BSC.Liquidity.Discovery.Interval
+	if interval <= 0 {
+		interval = defaultDiscoveryInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.DiscoverNow(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("Pair discovery failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DiscoverNow 查找所有关注代币的交易对，单个代币失败时保留其上次发现的结果
+func (s *pairDiscoveryService) DiscoverNow(ctx context.Context) error {
+	previous := make(map[string][]model.DiscoveredPair)
+	for _, pair := range s.Pairs() {
+		previous[pair.Token] = append(previous[pair.Token], pair)
+	}
+
+	var (
+		pairs         []model.DiscoveredPair
+		valid, failed int
+	)
+	for _, token := range s.config.BSC.Liquidity.Discovery.Tokens {
+		if !common.IsHexAddress(token) {
+			s.logger.Warnf("Skipping invalid discovery token address %q", token)
+			continue
+		}
+		valid++
+		address := common.HexToAddress(token)
+		found, err := s.discoverToken(ctx, address)
+		if err != nil {
+			failed++
+			s.logger.Warnf("Failed to discover pairs for token %s: %v", address.Hex(), err)
+			pairs = append(pairs, previous[address.Hex()]...)
+			continue
+		}
+		pairs = append(pairs, found...)
+	}
+	if failed > 0 && failed == valid {
+		return fmt.Errorf("%w: no token could be discovered", ErrUpstreamUnavailable)
+	}
+
+	s.mu.Lock()
+	s.pairs = pairs
+	s.mu.Unlock()
+
+	if err := s.save(ctx, pairs); err != nil {
+		s.logger.Warnf("Failed to save discovered pairs: %v", err)
+	}
+	s.logger.Infof("Discovered %d pairs for %d tokens", len(pairs), len(s.config.BSC.Liquidity.Discovery.Tokens))
+	return nil
+}
+
+// discoverToken 查询代币与各计价代币的交易对，按代币储备量降序取前max_pairs个，没有储备的交易对忽略
+func (s *pairDiscoveryService) discoverToken(ctx context.Context, token common.Address) ([]model.DiscoveredPair, error) {
+	factory := common.HexToAddress(s.factory())
+
+	var (
+		pairs   []model.DiscoveredPair
+		lastErr error
+	)
+	for _, quote := range s.quoteTokens() {
+		if quote == token {
+			continue
+		}
+		out, err := s.reader.call(ctx, s.factoryABI, factory, "getPair", token, quote)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		address, ok := out[0].(common.Address)
+		if !ok || address == (common.Address{}) {
+			continue
+		}
+
+		lpToken, err := s.reader.readToken(ctx, address)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		state, err := s.reader.readPair(ctx, lpToken)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		side := 0
+		if state.tokens[1].address == token {
+			side = 1
+		}
+		if state.reserves[side] <= 0 {
+			continue
+		}
+		pairs = append(pairs, model.DiscoveredPair{
+			Name:         state.tokens[0].symbol + "-" + state.tokens[1].symbol,
+			Address:      address.Hex(),
+			Token:        token.Hex(),
+			QuoteToken:   quote.Hex(),
+			Reserve:      state.reserves[side],
+			DiscoveredAt: time.Now(),
+		})
+	}
+	if len(pairs) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Reserve > pairs[j].Reserve })
+	if maxPairs := s.maxPairs(); len(pairs) > maxPairs {
+		pairs = pairs[:maxPairs]
+	}
+	return pairs, nil
+}
+
+// Pairs 最近一次发现的交易对
+func (s *pairDiscoveryService) Pairs() []model.DiscoveredPair {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]model.DiscoveredPair(nil), s.pairs...)
+}
+
+// load 读取上次保存的发现结果
+func (s *pairDiscoveryService) load(ctx context.Context) error {
+	if s.redisClient == nil {
+		return nil
+	}
+	data, err := s.redisClient.Get(ctx, discoveredPairsKey)
+	if err != nil || data == "" {
+		return err
+	}
+	var pairs []model.DiscoveredPair
+	if err := json.Unmarshal([]byte(data), &pairs); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.pairs = pairs
+	s.mu.Unlock()
+	return nil
+}
+
+// save 保存发现结果，进程重启后直接沿用
+func (s *pairDiscoveryService) save(ctx context.Context, pairs []model.DiscoveredPair) error {
+	if s.redisClient == nil {
+		return nil
+	}
+	data, err := json.Marshal(pairs)
+	if err != nil {
+		return err
+	}
+	return s.redisClient.Set(ctx, discoveredPairsKey, data, 0)
+}
+
+// factory 查询交易对使用的Factory合约地址
+func (s *pairDiscoveryService) factory() string {
+	if s.config.BSC.Contracts.PancakeFactory != "" {
+		return s.config.BSC.Contracts.PancakeFactory
+	}
+	return defaultPancakeFactory
+}
+
+// quoteTokens 与关注代币组成交易对的代币，未配置时使用WBNB、USDT和BUSD
+func (s *pairDiscoveryService) quoteTokens() []common.Address {
+	candidates := s.config.BSC.Liquidity.Discovery.QuoteTokens
+	if len(candidates) == 0 {
+		contracts := s.config.BSC.Contracts
+		candidates = []string{contracts.WBNB, contracts.USDT, contracts.BUSD}
+	}
+	quotes := make([]common.Address, 0, len(candidates))
+	for _, candidate := range candidates {
+		if common.IsHexAddress(candidate) {
+			quotes = append(quotes, common.HexToAddress(candidate))
+		}
+	}
+	return quotes
+}
+
+// maxPairs 每个代币加入监控的交易对数量
+func (s *pairDiscoveryService) maxPairs() int {
+	if s.config.BSC.Liquidity.Discovery.MaxPairs > 0 {
+		return s.config.BSC.Liquidity.Discovery.MaxPairs
+	}
+	return defaultDiscoveryMaxPairs
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+)
+
+// panic告警默认配置
+const (
+	defaultPanicStackLimit = 8 << 10
+	panicNotifyTimeout     = 10 * time.Second
+	maxPanicTitleValue     = 120
+)
+
+// AlertTypePanic 请求处理或后台任务发生panic的系统告警，只发往运维渠道
+const AlertTypePanic = "panic"
+
+// NewPanicReporter 创建panic上报处理函数，将panic转为critical告警，同一位置的panic在冷却期内只发送一次
+func NewPanicReporter(cfg *config.Config, notifier Notifier) panics.Handler {
+	log := logger.GetLogger()
+	stackLimit := cfg.Notifier.Panics.StackLimit
+	if stackLimit <= 0 {
+		stackLimit = defaultPanicStackLimit
+	}
+	host, _ := os.Hostname()
+
+	return func(report *panics.Report) {
+		ctx, cancel := context.WithTimeout(context.Background(), panicNotifyTimeout)
+		defer cancel()
+
+		if err := notifier.Notify(ctx, panicAlert(report, host, stackLimit)); err != nil {
+			log.Errorf("Failed to send panic alert: %v", err)
+		}
+	}
+}
+
+// panicAlert 构造panic告警，调用栈超出stackLimit时截断尾部
+func panicAlert(report *panics.Report, host string, stackLimit int) *model.Alert {
+	location := panicLocation(report.Stack)
+	stack := report.Stack
+	if len(stack) > stackLimit {
+		stack = stack[:stackLimit] + "\n...(truncated)"
+	}
+
+	value := report.Value
+	if len(value) > maxPanicTitleValue {
+		value = value[:maxPanicTitleValue] + "..."
+	}
+	message := fmt.Sprintf("Panic recovered in %s on %s: %s", report.Source, host, report.Value)
+	if location != "" {
+		message += " at " + location
+	}
+	if report.Path != "" {
+		message += fmt.Sprintf(" (%s %s)", report.Method, report.Path)
+	}
+
+	data := map[string]interface{}{
+		"source":   report.Source,
+		"panic":    report.Value,
+		"location": location,
+		"host":     host,
+		"stack":    stack,
+	}
+	if report.RequestID != "" {
+		data["request_id"] = report.RequestID
+	}
+	if report.Path != "" {
+		data["method"] = report.Method
+		data["path"] = report.Path
+	}
+
+	dedupLocation := location
+	if dedupLocation == "" {
+		dedupLocation = report.Value
+	}
+	return &model.Alert{
+		Type:      AlertTypePanic,
+		Severity:  model.AlertSeverityCritical,
+		Title:     fmt.Sprintf("Panic in %s: %s", report.Source, value),
+		Message:   message,
+		Subject:   report.Source,
+		DedupKey:  AlertTypePanic + ":" + report.Source + ":" + dedupLocation,
+		Data:      data,
+		CreatedAt: report.Time,
+	}
+}
+
+// panicLocation 从调用栈中找出触发panic的代码位置(文件:行号)，即panic(...)之后第一个非runtime的栈帧
+// 调用栈每个栈帧占两行：函数名，以及"\t文件:行号 +偏移"
+func panicLocation(stack string) string {
+	lines := strings.Split(stack, "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic(") {
+			start = i + 2
+			break
+		}
+	}
+	if start < 0 {
+		return ""
+	}
+	for i := start; i+1 < len(lines); i += 2 {
+		if strings.HasPrefix(lines[i], "runtime.") {
+			continue
+		}
+		if fields := strings.Fields(lines[i+1]); len(fields) > 0 {
+			return fields[0]
+		}
+		return ""
+	}
+	return ""
+}
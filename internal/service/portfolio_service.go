@@ -0,0 +1,518 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/importer"
+	"crypto-info/internal/pkg/logger"
+)
+
+// quantityEpsilon 数量比较精度，避免浮点误差导致的超卖判断
+const quantityEpsilon = 1e-12
+
+// longTermHolding 超过该持有时间的卖出视为长期
+const longTermHolding = 365 * 24 * time.Hour
+
+// portfolioTradesKeyPrefix 用户交易存储key前缀
+const portfolioTradesKeyPrefix = "portfolio:trades:"
+
+// 会计汇总周期
+const (
+	AccountingPeriodMonth   = "month"
+	AccountingPeriodQuarter = "quarter"
+	AccountingPeriodYear    = "year"
+)
+
+// PortfolioService 投资组合服务接口
+type PortfolioService interface {
+	CalculatePnL(ctx context.Context, userID string, req *model.PnLRequest) (*model.PnLResponse, error)
+	ImportTrades(ctx context.Context, userID, format string, r io.Reader) (*model.ImportReport, error)
+	ListTrades(ctx context.Context, userID string) ([]model.Trade, error)
+	ExportAccounting(ctx context.Context, userID, method, period string, from, to time.Time) (*model.AccountingReport, error)
+}
+
+// portfolioService 投资组合服务实现
+type portfolioService struct {
+	redisClient    database.RedisClient
+	config         *config.Config
+	priceService   PriceService
+	historyService HistoryService
+}
+
+// lot 持仓批次
+type lot struct {
+	quantity   float64
+	unitCost   float64
+	acquiredAt time.Time
+}
+
+// NewPortfolioService 创建投资组合服务
+func NewPortfolioService(redisClient database.RedisClient, cfg *config.Config, priceService PriceService, historyService HistoryService) PortfolioService {
+	return &portfolioService{
+		redisClient:    redisClient,
+		config:         cfg,
+		priceService:   priceService,
+		historyService: historyService,
+	}
+}
+
+// CalculatePnL 按FIFO/LIFO计算成本与已实现、未实现盈亏
+func (s *portfolioService) CalculatePnL(ctx context.Context, userID string, req *model.PnLRequest) (*model.PnLResponse, error) {
+	method := req.Method
+	if method == "" {
+		method = model.CostBasisFIFO
+	}
+	if method != model.CostBasisFIFO && method != model.CostBasisLIFO {
+		return nil, fmt.Errorf("%w: unsupported cost basis method %q", ErrInvalidParameter, method)
+	}
+
+	source := req.Trades
+	if len(source) == 0 {
+		stored, err := s.ListTrades(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(stored) == 0 {
+			return nil, fmt.Errorf("%w: no trades provided or imported", ErrInvalidParameter)
+		}
+		source = stored
+	}
+
+	trades, err := s.resolveTradePrices(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按币种分组并按时间排序
+	bySymbol := make(map[string][]model.Trade)
+	for _, trade := range trades {
+		bySymbol[trade.Symbol] = append(bySymbol[trade.Symbol], trade)
+	}
+	symbols := make([]string, 0, len(bySymbol))
+	for symbol := range bySymbol {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	resp := &model.PnLResponse{
+		Method:  method,
+		Symbols: make([]model.SymbolPnL, 0, len(symbols)),
+	}
+	for _, symbol := range symbols {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		symbolTrades := bySymbol[symbol]
+		sort.SliceStable(symbolTrades, func(i, j int) bool {
+			return symbolTrades[i].Timestamp.Before(symbolTrades[j].Timestamp)
+		})
+
+		pnl, err := matchLots(symbol, symbolTrades, method)
+		if err != nil {
+			return nil, err
+		}
+		s.applyMarketPrice(ctx, pnl)
+
+		resp.TotalCostBasis += pnl.CostBasis
+		resp.TotalRealized += pnl.RealizedPnL
+		resp.TotalUnrealized += pnl.UnrealizedPnL
+		resp.Symbols = append(resp.Symbols, *pnl)
+	}
+
+	return resp, nil
+}
+
+// ImportTrades 解析交易所导出的CSV并保存交易，已导入的交易会被跳过
+func (s *portfolioService) ImportTrades(ctx context.Context, userID, format string, r io.Reader) (*model.ImportReport, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user id is required", ErrInvalidParameter)
+	}
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: trade storage is not configured", ErrUpstreamUnavailable)
+	}
+
+	result, err := importer.Parse(r, format)
+	if err != nil {
+		if errors.Is(err, importer.ErrUnknownFormat) {
+			return nil, fmt.Errorf("%w: %v (supported: %v)", ErrInvalidParameter, err, importer.Formats())
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParameter, err)
+	}
+
+	report := &model.ImportReport{
+		Format:   result.Format,
+		Rows:     result.Rows,
+		Errors:   toImportRowErrors(result.Errors),
+		Warnings: toImportRowErrors(result.Warnings),
+	}
+
+	key := tradesKey(userID)
+	for _, trade := range result.Trades {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		exists, err := s.redisClient.HExists(ctx, key, trade.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check trade %s: %w", trade.ID, err)
+		}
+		if exists {
+			report.Duplicates++
+			continue
+		}
+
+		data, err := json.Marshal(trade)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.redisClient.HSet(ctx, key, trade.ID, string(data)); err != nil {
+			return nil, fmt.Errorf("failed to save trade %s: %w", trade.ID, err)
+		}
+		report.Imported++
+	}
+
+	logger.From(ctx).Infof("Imported %d trades (%d duplicates, %d errors) from %s export for user %s",
+		report.Imported, report.Duplicates, len(report.Errors), report.Format, userID)
+
+	return report, nil
+}
+
+// ListTrades 获取用户已导入的交易，按时间升序
+func (s *portfolioService) ListTrades(ctx context.Context, userID string) ([]model.Trade, error) {
+	if userID == "" || s.redisClient == nil {
+		return []model.Trade{}, nil
+	}
+
+	entries, err := s.redisClient.HGetAll(ctx, tradesKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trades: %w", err)
+	}
+
+	trades := make([]model.Trade, 0, len(entries))
+	for id, data := range entries {
+		var trade model.Trade
+		if err := json.Unmarshal([]byte(data), &trade); err != nil {
+			logger.From(ctx).Warnf("Skipping malformed trade %s for user %s: %v", id, userID, err)
+			continue
+		}
+		trades = append(trades, trade)
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		if trades[i].Timestamp.Equal(trades[j].Timestamp) {
+			return trades[i].ID < trades[j].ID
+		}
+		return trades[i].Timestamp.Before(trades[j].Timestamp)
+	})
+
+	return trades, nil
+}
+
+// ExportAccounting 生成期间内买入、卖出明细及按周期汇总的已实现盈亏，公允价格取自价格历史
+func (s *portfolioService) ExportAccounting(ctx context.Context, userID, method, period string, from, to time.Time) (*model.AccountingReport, error) {
+	if method == "" {
+		method = model.CostBasisFIFO
+	}
+	if method != model.CostBasisFIFO && method != model.CostBasisLIFO {
+		return nil, fmt.Errorf("%w: unsupported cost basis method %q", ErrInvalidParameter, method)
+	}
+	if period == "" {
+		period = AccountingPeriodYear
+	}
+	if period != AccountingPeriodMonth && period != AccountingPeriodQuarter && period != AccountingPeriodYear {
+		return nil, fmt.Errorf("%w: unsupported period %q", ErrInvalidParameter, period)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidParameter)
+	}
+
+	stored, err := s.ListTrades(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := s.resolveTradePrices(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.AccountingReport{
+		Method:  method,
+		Period:  period,
+		From:    from,
+		To:      to,
+		Entries: make([]model.AccountingEntry, 0),
+		Periods: make([]model.AccountingPeriodSummary, 0),
+	}
+
+	// 成本配对需要使用完整历史，再按期间筛选
+	bySymbol := make(map[string][]model.Trade)
+	tradesByID := make(map[string]model.Trade, len(trades))
+	for _, trade := range trades {
+		bySymbol[trade.Symbol] = append(bySymbol[trade.Symbol], trade)
+		tradesByID[trade.ID] = trade
+	}
+
+	inRange := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+
+	for symbol, symbolTrades := range bySymbol {
+		sort.SliceStable(symbolTrades, func(i, j int) bool {
+			return symbolTrades[i].Timestamp.Before(symbolTrades[j].Timestamp)
+		})
+
+		for _, trade := range symbolTrades {
+			if trade.Side != model.TradeSideBuy || !inRange(trade.Timestamp) {
+				continue
+			}
+			fairValue, source := s.fairValue(ctx, symbol, trade.Timestamp, trade.Price)
+			report.Entries = append(report.Entries, model.AccountingEntry{
+				Date:            trade.Timestamp,
+				Type:            model.AccountingAcquisition,
+				Symbol:          symbol,
+				Quantity:        trade.Quantity,
+				UnitPrice:       trade.Price,
+				FairValue:       fairValue,
+				FairValueSource: source,
+				CostBasis:       trade.Quantity*trade.Price + trade.Fee,
+				TradeID:         trade.ID,
+				Exchange:        trade.Exchange,
+			})
+		}
+
+		pnl, err := matchLots(symbol, symbolTrades, method)
+		if err != nil {
+			return nil, err
+		}
+		for _, disposal := range pnl.Disposals {
+			if !inRange(disposal.DisposedAt) {
+				continue
+			}
+			trade := tradesByID[disposal.TradeID]
+			fairValue, source := s.fairValue(ctx, symbol, disposal.DisposedAt, trade.Price)
+			acquiredAt := disposal.AcquiredAt
+			holding := disposal.DisposedAt.Sub(acquiredAt)
+			term := "short"
+			if holding > longTermHolding {
+				term = "long"
+			}
+			report.Entries = append(report.Entries, model.AccountingEntry{
+				Date:            disposal.DisposedAt,
+				Type:            model.AccountingDisposal,
+				Symbol:          symbol,
+				Quantity:        disposal.Quantity,
+				UnitPrice:       trade.Price,
+				FairValue:       fairValue,
+				FairValueSource: source,
+				CostBasis:       disposal.CostBasis,
+				Proceeds:        disposal.Proceeds,
+				Gain:            disposal.Gain,
+				AcquiredAt:      &acquiredAt,
+				HoldingDays:     int(holding / (24 * time.Hour)),
+				Term:            term,
+				TradeID:         disposal.TradeID,
+				Exchange:        trade.Exchange,
+			})
+		}
+	}
+
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		if report.Entries[i].Date.Equal(report.Entries[j].Date) {
+			return report.Entries[i].Symbol < report.Entries[j].Symbol
+		}
+		return report.Entries[i].Date.Before(report.Entries[j].Date)
+	})
+	report.Periods = summarizePeriods(report.Entries, period)
+
+	return report, nil
+}
+
+// fairValue 获取指定时间的公允价格，无历史数据时使用成交价
+func (s *portfolioService) fairValue(ctx context.Context, symbol string, at time.Time, tradePrice float64) (float64, string) {
+	if s.historyService != nil {
+		if price, err := s.historyService.GetPriceAt(ctx, symbol, at, PriceAtNearest); err == nil {
+			return price.Price, "history"
+		}
+	}
+	return tradePrice, "trade"
+}
+
+// resolveTradePrices 为未提供成交价的交易补全历史价格
+func (s *portfolioService) resolveTradePrices(ctx context.Context, trades []model.Trade) ([]model.Trade, error) {
+	resolved := make([]model.Trade, len(trades))
+	for i, trade := range trades {
+		if trade.Price <= 0 {
+			if s.historyService == nil {
+				return nil, fmt.Errorf("%w: trade %d has no price and price history is unavailable", ErrInvalidParameter, i)
+			}
+			at, err := s.historyService.GetPriceAt(ctx, trade.Symbol, trade.Timestamp, PriceAtInterpolate)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve price for trade %d (%s at %s): %w", i, trade.Symbol, trade.Timestamp.Format(time.RFC3339), err)
+			}
+			trade.Price = at.Price
+		}
+		resolved[i] = trade
+	}
+	return resolved, nil
+}
+
+// applyMarketPrice 使用当前价格计算持仓市值与未实现盈亏
+func (s *portfolioService) applyMarketPrice(ctx context.Context, pnl *model.SymbolPnL) {
+	if pnl.Quantity <= quantityEpsilon || s.priceService == nil {
+		return
+	}
+
+	price, err := s.priceService.GetPrice(ctx, pnl.Symbol)
+	if err != nil {
+		logger.From(ctx).Warnf("Failed to get market price for %s, unrealized P&L omitted: %v", pnl.Symbol, err)
+		return
+	}
+
+	pnl.MarketPrice = price.Price
+	pnl.MarketValue = pnl.Quantity * price.Price
+	pnl.UnrealizedPnL = pnl.MarketValue - pnl.CostBasis
+}
+
+// tradesKey 用户交易存储key
+func tradesKey(userID string) string {
+	return portfolioTradesKeyPrefix + userID
+}
+
+// summarizePeriods 按周期汇总会计明细
+func summarizePeriods(entries []model.AccountingEntry, period string) []model.AccountingPeriodSummary {
+	summaries := make([]model.AccountingPeriodSummary, 0)
+	index := make(map[string]int)
+	for _, entry := range entries {
+		key := periodKey(entry.Date, period)
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, model.AccountingPeriodSummary{Period: key})
+		}
+
+		summary := &summaries[i]
+		switch entry.Type {
+		case model.AccountingAcquisition:
+			summary.Acquisitions += entry.CostBasis
+			summary.Acquired++
+		case model.AccountingDisposal:
+			summary.Proceeds += entry.Proceeds
+			summary.CostBasis += entry.CostBasis
+			summary.Gain += entry.Gain
+			if entry.Term == "long" {
+				summary.LongTerm += entry.Gain
+			} else {
+				summary.ShortTerm += entry.Gain
+			}
+			summary.Disposals++
+		}
+	}
+	return summaries
+}
+
+// periodKey 获取时间所属的汇总周期
+func periodKey(t time.Time, period string) string {
+	t = t.UTC()
+	switch period {
+	case AccountingPeriodMonth:
+		return t.Format("2006-01")
+	case AccountingPeriodQuarter:
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	default:
+		return t.Format("2006")
+	}
+}
+
+// toImportRowErrors 转换导入行级错误
+func toImportRowErrors(rows []importer.RowError) []model.ImportRowError {
+	result := make([]model.ImportRowError, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, model.ImportRowError{Row: row.Row, Message: row.Error})
+	}
+	return result
+}
+
+// matchLots 按指定方式将卖出与买入批次配对，计算单个币种的盈亏
+func matchLots(symbol string, trades []model.Trade, method string) (*model.SymbolPnL, error) {
+	pnl := &model.SymbolPnL{
+		Symbol:    symbol,
+		Disposals: make([]model.Disposal, 0),
+	}
+
+	var lots []lot
+	for _, trade := range trades {
+		switch trade.Side {
+		case model.TradeSideBuy:
+			// 买入手续费计入成本
+			lots = append(lots, lot{
+				quantity:   trade.Quantity,
+				unitCost:   (trade.Quantity*trade.Price + trade.Fee) / trade.Quantity,
+				acquiredAt: trade.Timestamp,
+			})
+
+		case model.TradeSideSell:
+			remaining := trade.Quantity
+			// 卖出手续费按数量比例从所得中扣除
+			netUnitProceeds := (trade.Quantity*trade.Price - trade.Fee) / trade.Quantity
+
+			for remaining > quantityEpsilon {
+				if len(lots) == 0 {
+					return nil, fmt.Errorf("%w: %s sell at %s exceeds holdings by %g",
+						ErrInvalidParameter, symbol, trade.Timestamp.Format(time.RFC3339), remaining)
+				}
+
+				idx := 0
+				if method == model.CostBasisLIFO {
+					idx = len(lots) - 1
+				}
+				current := &lots[idx]
+
+				qty := remaining
+				if current.quantity < qty {
+					qty = current.quantity
+				}
+				disposal := model.Disposal{
+					TradeID:    trade.ID,
+					Symbol:     symbol,
+					Quantity:   qty,
+					AcquiredAt: current.acquiredAt,
+					DisposedAt: trade.Timestamp,
+					CostBasis:  qty * current.unitCost,
+					Proceeds:   qty * netUnitProceeds,
+				}
+				disposal.Gain = disposal.Proceeds - disposal.CostBasis
+				pnl.Disposals = append(pnl.Disposals, disposal)
+				pnl.RealizedPnL += disposal.Gain
+
+				current.quantity -= qty
+				remaining -= qty
+				if current.quantity <= quantityEpsilon {
+					lots = append(lots[:idx], lots[idx+1:]...)
+				}
+			}
+
+		default:
+			return nil, fmt.Errorf("%w: unsupported trade side %q", ErrInvalidParameter, trade.Side)
+		}
+	}
+
+	for _, l := range lots {
+		pnl.Quantity += l.quantity
+		pnl.CostBasis += l.quantity * l.unitCost
+	}
+	if pnl.Quantity > quantityEpsilon {
+		pnl.AverageCost = pnl.CostBasis / pnl.Quantity
+	}
+
+	return pnl, nil
+}
@@ -3,73 +3,270 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"crypto-info/internal/config"
 	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/binance"
+	"crypto-info/internal/pkg/breaker"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/coinbase"
 	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/huobi"
+	"crypto-info/internal/pkg/kraken"
 	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/okx"
+	"crypto-info/internal/pkg/popularity"
+	"crypto-info/internal/pkg/precision"
+	"crypto-info/internal/pkg/provider"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // PriceService 价格服务接口
 type PriceService interface {
 	GetPrice(ctx context.Context, symbol string) (*model.PriceResponse, error)
+	// GetQuote 获取指定计价币种的价格，currency为空或USDT时同GetPrice，USD时使用美元计价数据源
+	GetQuote(ctx context.Context, symbol, currency string) (*model.PriceResponse, error)
+	// GetConfirmedPrice 只使用BSC链上数据源，获取所在区块至少有minConfirmations个确认的USDT价格
+	GetConfirmedPrice(ctx context.Context, symbol string, minConfirmations uint64) (*model.PriceResponse, error)
 	GetBTCPrice(ctx context.Context) (*model.PriceResponse, error)
+	// RefreshPrices 从上游获取所有支持币种的价格并更新缓存
+	RefreshPrices(ctx context.Context) error
 }
 
 // priceService 价格服务实现
 type priceService struct {
-	redisClient database.RedisClient
-	config      *config.Config
-	logger      logger.Logger
-	bscService  BSCService
+	redisClient    database.RedisClient
+	config         *config.Config
+	bscService     BSCService
+	historyService HistoryService
+	streamService  StreamService
+	binance        provider.PriceProvider
+	huobi          provider.PriceProvider
+	okx            provider.PriceProvider
+	coinbase       provider.PriceProvider
+	kraken         provider.PriceProvider
+	negativeCache  *negativeCache
+	refreshing     sync.Map           // 正在后台刷新的价格缓存key
+	fetchGroup     singleflight.Group // 按价格缓存key合并并发的上游请求
 }
 
-// NewPriceService 创建价格服务
-func NewPriceService(redisClient database.RedisClient, cfg *config.Config, bscService BSCService) PriceService {
+// priceRefreshTimeout 后台刷新价格的超时时间
+const priceRefreshTimeout = 10 * time.Second
+
+// 价格数据源，见 business.price_source
+const (
+	priceSourceBSC     = "bsc"
+	priceSourceBinance = "binance"
+	priceSourceHuobi   = "huobi"
+	priceSourceOKX     = "okx"
+)
+
+// 美元计价数据源，见 business.usd_price_sources
+const (
+	priceSourceCoinbase = "coinbase"
+	priceSourceKraken   = "kraken"
+)
+
+// maxPriceConfirmations 链上价格可要求的最大确认数
+const maxPriceConfirmations = 1000
+
+// 价格计价币种，见 /api/v1/crypto/price 的quote_currency参数
+const (
+	quoteCurrencyUSDT = "USDT"
+	quoteCurrencyUSD  = "USD"
+)
+
+// cachedPrice 价格缓存条目
+type cachedPrice struct {
+	Price    *model.PriceResponse `json:"price"`
+	CachedAt time.Time            `json:"cached_at"`
+}
+
+// NewPriceService 创建价格服务，获取到新价格时通过streamService推送
+func NewPriceService(redisClient database.RedisClient, cfg *config.Config, bscService BSCService, historyService HistoryService, streamService StreamService) PriceService {
 	return &priceService{
-		redisClient: redisClient,
-		config:      cfg,
-		logger:      logger.GetLogger(),
-		bscService:  bscService,
+		redisClient:    redisClient,
+		config:         cfg,
+		bscService:     bscService,
+		historyService: historyService,
+		streamService:  streamService,
+		binance:        binance.NewProvider(binance.NewClient(&cfg.ExternalAPI.Binance)),
+		huobi:          huobi.NewProvider(huobi.NewClient(&cfg.ExternalAPI.Huobi)),
+		okx:            okx.NewProvider(okx.NewClient(&cfg.ExternalAPI.OKX)),
+		coinbase:       coinbase.NewProvider(coinbase.NewClient(&cfg.ExternalAPI.Coinbase)),
+		kraken:         kraken.NewProvider(kraken.NewClient(&cfg.ExternalAPI.Kraken)),
+		negativeCache:  newNegativeCache(redisClient, cfg.Cache.NegativeTTL),
 	}
 }
 
 // GetPrice 获取加密货币价格
 func (s *priceService) GetPrice(ctx context.Context, symbol string) (*model.PriceResponse, error) {
+	return s.GetQuote(ctx, symbol, quoteCurrencyUSDT)
+}
+
+// GetQuote 获取指定计价币种的价格，美元价格单独缓存，不记录历史也不推送
+func (s *priceService) GetQuote(ctx context.Context, symbol, currency string) (*model.PriceResponse, error) {
 	// 参数验证
 	if symbol == "" {
 		symbol = s.config.Business.DefaultSymbol
 	}
+	currency = strings.ToUpper(currency)
+	switch currency {
+	case "":
+		currency = quoteCurrencyUSDT
+	case quoteCurrencyUSDT, quoteCurrencyUSD:
+	default:
+		return nil, fmt.Errorf("%w: unsupported quote_currency %s, must be USDT or USD", ErrInvalidParameter, currency)
+	}
 
-	// 检查是否支持该币种
+	// 检查是否支持该币种，只查配置不访问Redis
 	if !s.isSupportedSymbol(symbol) {
-		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSymbol, symbol)
 	}
+	popularity.Default().Record(symbol)
+
+	cacheKey := priceCacheKey(symbol, currency)
 
-	// 尝试从缓存获取
+	// 尝试从缓存获取，处于陈旧窗口内时先返回旧值再后台刷新
 	if s.redisClient != nil {
-		if cached, err := s.getPriceFromCache(ctx, symbol); err == nil && cached != nil {
-			s.logger.Debugf("Price cache hit for symbol: %s", symbol)
+		if cached, err := s.getPriceFromCache(ctx, cacheKey); err == nil && cached != nil {
+			if cached.Cache.Stale && s.cacheOnly(currency) {
+				logger.From(ctx).Debugf("Price cache stale for symbol: %s, upstream budget exceeded, skipping refresh", symbol)
+			} else if cached.Cache.Stale && s.sourcesOpen(currency) {
+				logger.From(ctx).Debugf("Price cache stale for symbol: %s, all price sources circuit open, skipping refresh", symbol)
+			} else if cached.Cache.Stale {
+				logger.From(ctx).Debugf("Price cache stale for symbol: %s, refreshing in background", symbol)
+				s.refreshInBackground(ctx, symbol, currency)
+			} else {
+				logger.From(ctx).Debugf("Price cache hit for symbol: %s", symbol)
+			}
 			return cached, nil
 		}
 	}
 
-	// 获取价格数据
-	price, err := s.fetchPrice(ctx, symbol)
+	// 获取价格数据，并发的相同请求共享一次上游请求
+	price, err := s.loadPrice(ctx, symbol, currency)
 	if err != nil {
-		s.logger.Errorf("Failed to fetch price for %s: %v", symbol, err)
+		logger.From(ctx).Errorf("Failed to fetch price for %s: %v", symbol, err)
 		return nil, err
 	}
 
-	// 缓存结果
+	return price, nil
+}
+
+// loadPrice 获取价格并写入缓存，USDT价格同时记录历史并推送
+//
+// 同一币种和计价币种同时只有一个上游请求，其他调用等待并共享结果。共享的请求不随单个调用方取消，
+// 最长执行priceRefreshTimeout；调用方的ctx结束时直接返回，不影响其他等待者。
+// 上游失败或查不到价格时写入负缓存，负缓存有效期内不再请求上游。
+func (s *priceService) loadPrice(ctx context.Context, symbol, currency string) (*model.PriceResponse, error) {
+	cacheKey := priceCacheKey(symbol, currency)
+	if err := s.negativeCache.get(ctx, cacheKey); err != nil {
+		logger.From(ctx).Debugf("Price negative cache hit for symbol: %s", symbol)
+		return nil, err
+	}
+
+	ch := s.fetchGroup.DoChan(cacheKey, func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), priceRefreshTimeout)
+		defer cancel()
+
+		price, err := s.fetchPrice(fetchCtx, symbol, currency)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidParameter) && !errors.Is(err, ErrUnsupportedSymbol) {
+				err = fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+			}
+			if cacheErr := s.negativeCache.set(fetchCtx, cacheKey, err); cacheErr != nil {
+				logger.From(ctx).Warnf("Failed to set negative cache for %s: %v", symbol, cacheErr)
+			}
+			return nil, err
+		}
+		if s.redisClient != nil {
+			if err := s.setPriceCache(fetchCtx, cacheKey, price); err != nil {
+				logger.From(ctx).Warnf("Failed to cache price for %s: %v", symbol, err)
+			}
+		}
+		if currency == quoteCurrencyUSDT {
+			s.recordHistory(fetchCtx, price)
+			s.publishPrice(fetchCtx, price)
+		}
+		return price, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		if result.Shared {
+			logger.From(ctx).Debugf("Price fetch for %s shared with concurrent requests", cacheKey)
+		}
+		// 各调用方拿到独立的副本
+		price := *result.Val.(*model.PriceResponse)
+		return &price, nil
+	}
+}
+
+// GetConfirmedPrice 使用最新区块之前minConfirmations个区块处的流动性计算价格，按确认数单独缓存，不记录历史也不推送
+//
+// 交易所价格没有区块确认的概念，因此不回退到交易所或模拟数据；缓存过期后直接重新计算，不返回陈旧值。
+func (s *priceService) GetConfirmedPrice(ctx context.Context, symbol string, minConfirmations uint64) (*model.PriceResponse, error) {
+	if symbol == "" {
+		symbol = s.config.Business.DefaultSymbol
+	}
+	if minConfirmations > maxPriceConfirmations {
+		return nil, fmt.Errorf("%w: min_confirmations must be at most %d", ErrInvalidParameter, maxPriceConfirmations)
+	}
+	if s.bscService == nil || !s.config.BSC.Enabled {
+		return nil, fmt.Errorf("%w: min_confirmations requires the bsc price source, which is disabled", ErrInvalidParameter)
+	}
+
+	if !s.isSupportedSymbol(symbol) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSymbol, symbol)
+	}
+	popularity.Default().Record(symbol)
+
+	cacheKey := confirmedPriceCacheKey(symbol, minConfirmations)
 	if s.redisClient != nil {
-		if err := s.setPriceCache(ctx, symbol, price); err != nil {
-			s.logger.Warnf("Failed to cache price for %s: %v", symbol, err)
+		if cached, err := s.getPriceFromCache(ctx, cacheKey); err == nil && cached != nil && !cached.Cache.Stale {
+			logger.From(ctx).Debugf("Confirmed price cache hit for symbol: %s, confirmations: %d", symbol, minConfirmations)
+			return cached, nil
+		}
+	}
+
+	if err := s.negativeCache.get(ctx, cacheKey); err != nil {
+		logger.From(ctx).Debugf("Confirmed price negative cache hit for symbol: %s", symbol)
+		return nil, err
+	}
+
+	if err := breaker.Default().Allow(priceSourceBSC); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+	}
+	price, err := s.fetchBSCPrice(ctx, symbol, minConfirmations)
+	breaker.Default().Record(priceSourceBSC, err)
+	if err != nil {
+		logger.From(ctx).Errorf("Failed to fetch confirmed price for %s: %v", symbol, err)
+		if !errors.Is(err, ErrInvalidParameter) && !errors.Is(err, ErrUnsupportedSymbol) {
+			err = fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
 		}
+		if cacheErr := s.negativeCache.set(ctx, cacheKey, err); cacheErr != nil {
+			logger.From(ctx).Warnf("Failed to set negative cache for %s: %v", symbol, cacheErr)
+		}
+		return nil, err
 	}
 
+	if s.redisClient != nil {
+		if err := s.setPriceCache(ctx, cacheKey, price); err != nil {
+			logger.From(ctx).Warnf("Failed to cache confirmed price for %s: %v", symbol, err)
+		}
+	}
 	return price, nil
 }
 
@@ -78,44 +275,194 @@ func (s *priceService) GetBTCPrice(ctx context.Context) (*model.PriceResponse, e
 	return s.GetPrice(ctx, "BTC")
 }
 
-// fetchPrice 获取价格数据
-func (s *priceService) fetchPrice(ctx context.Context, symbol string) (*model.PriceResponse, error) {
+// fetchPrice 获取指定计价币种的价格数据
+func (s *priceService) fetchPrice(ctx context.Context, symbol, currency string) (*model.PriceResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// 如果启用了模拟数据，返回模拟价格
 	if s.config.Business.MockDataEnabled {
 		return s.generateMockPrice(symbol), nil
 	}
 
-	// 优先使用BSC链上流动性数据计算价格
-	if s.bscService != nil && s.config.BSC.Enabled {
-		price, err := s.bscService.GetTokenPriceInUSDT(ctx, symbol)
+	// 超出调用预算时只返回缓存数据，不回退到模拟数据
+	if s.cacheOnly(currency) {
+		return nil, fmt.Errorf("%w: %s", budget.ErrExceeded, s.budgetProvider(currency))
+	}
+
+	// 依次尝试主数据源和备用数据源，跳过熔断中的数据源，请求已取消或超时时不再尝试
+	for _, source := range s.priceSources(currency) {
+		if err := breaker.Default().Allow(source); err != nil {
+			logger.From(ctx).Debugf("Skipping price source %s for %s: %v", source, symbol, err)
+			continue
+		}
+		price, err := s.fetchFromSource(ctx, source, symbol)
+		breaker.Default().Record(source, err)
 		if err == nil {
-			priceFloat, _ := price.Float64()
-			return &model.PriceResponse{
-				Symbol:    symbol,
-				Price:     priceFloat,
-				Currency:  "USDT",
-				UpdatedAt: time.Now().Format(time.RFC3339),
-				Source:    "BSC_Liquidity",
-			}, nil
+			return price, nil
+		}
+		logger.From(ctx).Warnf("Failed to get price from %s for %s: %v", source, symbol, err)
+		if ctx.Err() != nil {
+			break
 		}
-		s.logger.Warnf("Failed to get price from BSC for %s: %v, falling back to mock data", symbol, err)
 	}
 
-	// 如果BSC服务不可用，回退到模拟数据
+	// 所有数据源都不可用时回退到模拟数据
+	logger.From(ctx).Warnf("No price source available for %s, falling back to mock data", symbol)
 	return s.generateMockPrice(symbol), nil
 }
 
+// fetchFromSource 从指定数据源获取价格
+func (s *priceService) fetchFromSource(ctx context.Context, source, symbol string) (*model.PriceResponse, error) {
+	switch source {
+	case priceSourceBinance:
+		return s.fetchProviderPrice(ctx, s.binance, symbol, "Binance")
+	case priceSourceHuobi:
+		return s.fetchProviderPrice(ctx, s.huobi, symbol, "Huobi")
+	case priceSourceOKX:
+		return s.fetchProviderPrice(ctx, s.okx, symbol, "OKX")
+	case priceSourceCoinbase:
+		return s.fetchProviderPrice(ctx, s.coinbase, symbol, "Coinbase")
+	case priceSourceKraken:
+		return s.fetchProviderPrice(ctx, s.kraken, symbol, "Kraken")
+	}
+
+	// 使用BSC链上流动性数据计算价格
+	return s.fetchBSCPrice(ctx, symbol, 0)
+}
+
+// fetchBSCPrice 使用最新区块之前confirmations个区块处的BSC链上流动性计算价格，并标注所在区块
+func (s *priceService) fetchBSCPrice(ctx context.Context, symbol string, confirmations uint64) (*model.PriceResponse, error) {
+	price, block, err := s.bscService.GetTokenPriceAtDepth(ctx, symbol, confirmations)
+	if err != nil {
+		return nil, err
+	}
+	priceFloat, _ := price.Float64()
+	return &model.PriceResponse{
+		Symbol:    symbol,
+		Price:     priceFloat,
+		Currency:  "USDT",
+		UpdatedAt: time.Now().Format(time.RFC3339),
+		Source:    "BSC_Liquidity",
+		Precision: precision.Decimals(symbol, priceFloat),
+		Block:     block,
+	}, nil
+}
+
+// priceSources 依次尝试的数据源：主数据源和备用数据源，去除重复项和未启用的BSC；美元计价时为美元数据源
+func (s *priceService) priceSources(currency string) []string {
+	if currency == quoteCurrencyUSD {
+		return s.usdPriceSources()
+	}
+	candidates := append([]string{s.priceSource()}, s.config.Business.PriceFallbacks...)
+	sources := make([]string, 0, len(candidates))
+	seen := make(map[string]bool, len(candidates))
+	for _, source := range candidates {
+		if seen[source] {
+			continue
+		}
+		seen[source] = true
+		if source == priceSourceBSC && (s.bscService == nil || !s.config.BSC.Enabled) {
+			continue
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// fetchProviderPrice 从数据源获取最新价格，source为响应中的数据来源名称
+func (s *priceService) fetchProviderPrice(ctx context.Context, p provider.PriceProvider, symbol, source string) (*model.PriceResponse, error) {
+	quote, err := p.GetPrice(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	price := &model.PriceResponse{
+		Symbol:    symbol,
+		Price:     quote.Price,
+		Currency:  quote.Currency,
+		UpdatedAt: quote.Time.Format(time.RFC3339),
+		Source:    source,
+		Precision: precision.Decimals(symbol, quote.Price),
+	}
+	if quote.Stats != nil {
+		price.Stats24h = &model.PriceStats24h{
+			ChangePct:   quote.Stats.ChangePct(quote.Price),
+			Open:        quote.Stats.Open,
+			High:        quote.Stats.High,
+			Low:         quote.Stats.Low,
+			Volume:      quote.Stats.Volume,
+			QuoteVolume: quote.Stats.QuoteVolume,
+		}
+	}
+	return price, nil
+}
+
+// sourcesOpen 计价币种的所有数据源是否都处于熔断中
+func (s *priceService) sourcesOpen(currency string) bool {
+	sources := s.priceSources(currency)
+	for _, source := range sources {
+		if !breaker.Default().Open(source) {
+			return false
+		}
+	}
+	return len(sources) > 0
+}
+
+// usdPriceSources 配置的美元计价数据源，未配置时依次使用Coinbase和Kraken
+func (s *priceService) usdPriceSources() []string {
+	if len(s.config.Business.USDPriceSources) == 0 {
+		return []string{priceSourceCoinbase, priceSourceKraken}
+	}
+	return s.config.Business.USDPriceSources
+}
+
+// priceSource 配置的价格数据源，未配置时使用BSC链上流动性
+func (s *priceService) priceSource() string {
+	if s.config.Business.PriceSource == "" {
+		return priceSourceBSC
+	}
+	return s.config.Business.PriceSource
+}
+
+// budgetProvider 计价币种的主数据源对应的调用预算提供方，不计预算时为空
+func (s *priceService) budgetProvider(currency string) string {
+	if currency == quoteCurrencyUSD {
+		if s.usdPriceSources()[0] == priceSourceKraken {
+			return budget.ProviderKraken
+		}
+		return budget.ProviderCoinbase
+	}
+	switch {
+	case s.priceSource() == priceSourceBinance:
+		return budget.ProviderBinance
+	case s.priceSource() == priceSourceHuobi:
+		return budget.ProviderHuobi
+	case s.priceSource() == priceSourceOKX:
+		return budget.ProviderOKX
+	case s.bscService != nil && s.config.BSC.Enabled:
+		return budget.ProviderBSCRPC
+	}
+	return ""
+}
+
+// cacheOnly 价格数据源调用超出预算时进入只读缓存模式
+func (s *priceService) cacheOnly(currency string) bool {
+	provider := s.budgetProvider(currency)
+	return provider != "" && budget.Default().Exceeded(provider)
+}
+
 // generateMockPrice 生成模拟价格数据
 func (s *priceService) generateMockPrice(symbol string) *model.PriceResponse {
 	prices := map[string]float64{
-		"BTC": 45000.0,
-		"ETH": 3000.0,
-		"LTC": 150.0,
-		"BCH": 400.0,
-		"ADA": 0.5,
-		"DOT": 25.0,
+		"BTC":  45000.0,
+		"ETH":  3000.0,
+		"LTC":  150.0,
+		"BCH":  400.0,
+		"ADA":  0.5,
+		"DOT":  25.0,
 		"LINK": 20.0,
-		"XRP": 0.6,
+		"XRP":  0.6,
 	}
 
 	basePrice, exists := prices[symbol]
@@ -123,7 +470,7 @@ func (s *priceService) generateMockPrice(symbol string) *model.PriceResponse {
 		basePrice = 100.0
 	}
 
-	// 添加一些随机波动
+	// 添加一些随机波动，24小时统计以基准价为开盘价
 	variation := (time.Now().Unix() % 100) - 50
 	finalPrice := basePrice + float64(variation)*basePrice*0.001
 
@@ -133,9 +480,42 @@ func (s *priceService) generateMockPrice(symbol string) *model.PriceResponse {
 		Source:    "Mock Data",
 		UpdatedAt: time.Now().Format(time.RFC3339),
 		Currency:  "USD",
+		Precision: precision.Decimals(symbol, finalPrice),
+		Stats24h: &model.PriceStats24h{
+			ChangePct:   (finalPrice - basePrice) / basePrice * 100,
+			Open:        basePrice,
+			High:        max(basePrice, finalPrice) * 1.01,
+			Low:         min(basePrice, finalPrice) * 0.99,
+			Volume:      1000,
+			QuoteVolume: 1000 * finalPrice,
+		},
 	}
 }
 
+// RefreshPrices 从上游获取所有支持币种的价格并更新缓存，超出调用预算时跳过，正在后台刷新的币种不重复获取
+func (s *priceService) RefreshPrices(ctx context.Context) error {
+	if s.cacheOnly(quoteCurrencyUSDT) {
+		return fmt.Errorf("%w: %s", budget.ErrExceeded, s.budgetProvider(quoteCurrencyUSDT))
+	}
+
+	var errs []error
+	for _, symbol := range s.config.Business.SupportedSymbols {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		cacheKey := priceCacheKey(symbol, quoteCurrencyUSDT)
+		if _, loaded := s.refreshing.LoadOrStore(cacheKey, struct{}{}); loaded {
+			continue
+		}
+
+		if _, err := s.loadPrice(ctx, symbol, quoteCurrencyUSDT); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+		}
+		s.refreshing.Delete(cacheKey)
+	}
+	return errors.Join(errs...)
+}
+
 // isSupportedSymbol 检查是否支持该币种
 func (s *priceService) isSupportedSymbol(symbol string) bool {
 	for _, supported := range s.config.Business.SupportedSymbols {
@@ -146,29 +526,101 @@ func (s *priceService) isSupportedSymbol(symbol string) bool {
 	return false
 }
 
-// getPriceFromCache 从缓存获取价格
-func (s *priceService) getPriceFromCache(ctx context.Context, symbol string) (*model.PriceResponse, error) {
-	cacheKey := fmt.Sprintf("price:%s", symbol)
+// refreshInBackground 后台刷新价格缓存，同一币种和计价币种同时只有一个刷新任务
+func (s *priceService) refreshInBackground(ctx context.Context, symbol, currency string) {
+	cacheKey := priceCacheKey(symbol, currency)
+	if _, loaded := s.refreshing.LoadOrStore(cacheKey, struct{}{}); loaded {
+		return
+	}
+
+	log := logger.From(ctx)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), priceRefreshTimeout)
+
+	go func() {
+		defer cancel()
+		defer s.refreshing.Delete(cacheKey)
+
+		if _, err := s.loadPrice(ctx, symbol, currency); err != nil {
+			log.Warnf("Background price refresh failed for %s: %v", symbol, err)
+		}
+	}()
+}
+
+// publishPrice 推送价格更新
+func (s *priceService) publishPrice(ctx context.Context, price *model.PriceResponse) {
+	if s.streamService == nil {
+		return
+	}
+	data, err := json.Marshal(price)
+	if err != nil {
+		return
+	}
+	event := &model.StreamEvent{Type: model.StreamEventPrice, Symbol: price.Symbol, Data: data}
+	if err := s.streamService.Publish(ctx, event); err != nil {
+		logger.From(ctx).Warnf("Failed to publish price update for %s: %v", price.Symbol, err)
+	}
+}
+
+// recordHistory 记录价格历史
+func (s *priceService) recordHistory(ctx context.Context, price *model.PriceResponse) {
+	if s.historyService == nil {
+		return
+	}
+	if err := s.historyService.RecordPrice(ctx, price); err != nil {
+		logger.From(ctx).Warnf("Failed to record price history for %s: %v", price.Symbol, err)
+	}
+}
+
+// priceCacheKey 价格缓存key，USDT价格沿用price:{symbol}，其他计价币种追加币种后缀
+func priceCacheKey(symbol, currency string) string {
+	if currency == quoteCurrencyUSDT {
+		return fmt.Sprintf("price:%s", symbol)
+	}
+	return fmt.Sprintf("price:%s:%s", symbol, currency)
+}
+
+// confirmedPriceCacheKey 要求最少确认数的链上价格缓存key
+func confirmedPriceCacheKey(symbol string, confirmations uint64) string {
+	return fmt.Sprintf("price:%s:confirmations:%d", symbol, confirmations)
+}
+
+// getPriceFromCache 从缓存获取价格，并附带新鲜度信息；新鲜期按读取时的币种热度计算
+func (s *priceService) getPriceFromCache(ctx context.Context, cacheKey string) (*model.PriceResponse, error) {
 	cachedData, err := s.redisClient.Get(ctx, cacheKey)
 	if err != nil || cachedData == "" {
 		return nil, fmt.Errorf("cache miss")
 	}
 
-	var price model.PriceResponse
-	if err := json.Unmarshal([]byte(cachedData), &price); err != nil {
+	var entry cachedPrice
+	if err := json.Unmarshal([]byte(cachedData), &entry); err != nil {
 		return nil, err
 	}
+	if entry.Price == nil || entry.CachedAt.IsZero() {
+		return nil, fmt.Errorf("cache miss")
+	}
 
+	expiresAt := entry.CachedAt.Add(popularity.Default().TTL(entry.Price.Symbol, s.config.Cache.PriceTTL))
+	now := time.Now()
+	if now.After(expiresAt.Add(s.config.Cache.PriceStaleTTL)) {
+		return nil, fmt.Errorf("cache miss")
+	}
+
+	price := *entry.Price
+	price.Cache = &model.CacheInfo{
+		Cached:    true,
+		CachedAt:  entry.CachedAt,
+		ExpiresAt: expiresAt,
+		Stale:     now.After(expiresAt),
+	}
 	return &price, nil
 }
 
-// setPriceCache 设置价格缓存
-func (s *priceService) setPriceCache(ctx context.Context, symbol string, price *model.PriceResponse) error {
-	cacheKey := fmt.Sprintf("price:%s", symbol)
-	data, err := json.Marshal(price)
+// setPriceCache 设置价格缓存，缓存保留时间为可能的最长新鲜期加陈旧窗口
+func (s *priceService) setPriceCache(ctx context.Context, cacheKey string, price *model.PriceResponse) error {
+	data, err := json.Marshal(cachedPrice{Price: price, CachedAt: time.Now()})
 	if err != nil {
 		return err
 	}
 
-	return s.redisClient.Set(ctx, cacheKey, data, s.config.Cache.PriceTTL)
-}
\ No newline at end of file
+	return s.redisClient.Set(ctx, cacheKey, data, popularity.Default().MaxTTL(s.config.Cache.PriceTTL)+s.config.Cache.PriceStaleTTL)
+}
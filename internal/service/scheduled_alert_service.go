@@ -0,0 +1,505 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+	"crypto-info/internal/pkg/precision"
+	"crypto-info/internal/pkg/scheduler"
+
+	"github.com/google/uuid"
+)
+
+// 定时通知默认配置
+const (
+	defaultScheduledPollInterval = 30 * time.Second
+	defaultScheduledMaxPerUser   = 20
+	defaultScheduledMaxDelay     = time.Hour
+	defaultScheduledTimezone     = "UTC"
+	maxScheduledAlertName        = 64
+	maxScheduledAlertSymbols     = 10
+	scheduledAlertLockTTL        = time.Hour
+	scheduledAlertKeyPrefix      = "alerts:scheduled:user:"
+	scheduledAlertDueKey         = "alerts:scheduled:due"
+	scheduledAlertLockKeyPrefix  = "alerts:scheduled:lock:"
+)
+
+// ScheduledAlertService 用户定时通知服务接口
+type ScheduledAlertService interface {
+	CreateScheduledAlert(ctx context.Context, userID string, req *model.ScheduledAlertRequest) (*model.ScheduledAlert, error)
+	ListScheduledAlerts(ctx context.Context, userID string) (*model.ScheduledAlertListResponse, error)
+	GetScheduledAlert(ctx context.Context, userID, id string) (*model.ScheduledAlert, error)
+	UpdateScheduledAlert(ctx context.Context, userID, id string, req *model.ScheduledAlertRequest) (*model.ScheduledAlert, error)
+	DeleteScheduledAlert(ctx context.Context, userID, id string) error
+	// Start 启动到期规则的定时检查
+	Start(ctx context.Context) error
+	// Stop 停止定时检查
+	Stop() error
+}
+
+// scheduledAlertService 规则按用户保存在Redis哈希中，到期时间保存在有序集合中
+//
+// 多实例部署时每个实例都会检查到期规则，同一次执行通过Redis锁保证只发送一次。
+type scheduledAlertService struct {
+	redisClient  database.RedisClient
+	config       *config.Config
+	logger       logger.Logger
+	priceService PriceService
+	notifier     Notifier
+
+	runMutex sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewScheduledAlertService 创建定时通知服务
+func NewScheduledAlertService(redisClient database.RedisClient, cfg *config.Config, priceService PriceService, notifier Notifier) ScheduledAlertService {
+	return &scheduledAlertService{
+		redisClient:  redisClient,
+		config:       cfg,
+		logger:       logger.GetLogger(),
+		priceService: priceService,
+		notifier:     notifier,
+	}
+}
+
+// CreateScheduledAlert 创建定时通知
+func (s *scheduledAlertService) CreateScheduledAlert(ctx context.Context, userID string, req *model.ScheduledAlertRequest) (*model.ScheduledAlert, error) {
+	if err := s.checkStorage(userID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.redisClient.HGetAll(ctx, scheduledAlertKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scheduled alerts: %w", err)
+	}
+	if len(existing) >= s.maxPerUser() {
+		return nil, fmt.Errorf("%w: at most %d scheduled alerts per user", ErrInvalidParameter, s.maxPerUser())
+	}
+
+	alert := &model.ScheduledAlert{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.applyRequest(alert, req); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// ListScheduledAlerts 获取用户的定时通知，按创建时间排序
+func (s *scheduledAlertService) ListScheduledAlerts(ctx context.Context, userID string) (*model.ScheduledAlertListResponse, error) {
+	if err := s.checkStorage(userID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.redisClient.HGetAll(ctx, scheduledAlertKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scheduled alerts: %w", err)
+	}
+
+	resp := &model.ScheduledAlertListResponse{Alerts: make([]model.ScheduledAlert, 0, len(entries))}
+	for id, value := range entries {
+		var alert model.ScheduledAlert
+		if err := json.Unmarshal([]byte(value), &alert); err != nil {
+			logger.From(ctx).Warnf("Skipping malformed scheduled alert %s for user %s: %v", id, userID, err)
+			continue
+		}
+		resp.Alerts = append(resp.Alerts, alert)
+	}
+	sort.Slice(resp.Alerts, func(i, j int) bool {
+		return resp.Alerts[i].CreatedAt.Before(resp.Alerts[j].CreatedAt)
+	})
+	resp.Total = len(resp.Alerts)
+	return resp, nil
+}
+
+// GetScheduledAlert 获取定时通知，不存在时返回ErrNotFound
+func (s *scheduledAlertService) GetScheduledAlert(ctx context.Context, userID, id string) (*model.ScheduledAlert, error) {
+	if err := s.checkStorage(userID); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: scheduled alert %s", ErrNotFound, id)
+	}
+
+	value, err := s.redisClient.HGet(ctx, scheduledAlertKey(userID), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scheduled alert: %w", err)
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%w: scheduled alert %s", ErrNotFound, id)
+	}
+
+	var alert model.ScheduledAlert
+	if err := json.Unmarshal([]byte(value), &alert); err != nil {
+		return nil, fmt.Errorf("invalid scheduled alert %s: %w", id, err)
+	}
+	return &alert, nil
+}
+
+// UpdateScheduledAlert 替换定时通知的设置并重新计算下次执行时间
+func (s *scheduledAlertService) UpdateScheduledAlert(ctx context.Context, userID, id string, req *model.ScheduledAlertRequest) (*model.ScheduledAlert, error) {
+	alert, err := s.GetScheduledAlert(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRequest(alert, req); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// DeleteScheduledAlert 删除定时通知
+func (s *scheduledAlertService) DeleteScheduledAlert(ctx context.Context, userID, id string) error {
+	if _, err := s.GetScheduledAlert(ctx, userID, id); err != nil {
+		return err
+	}
+	if err := s.redisClient.HDel(ctx, scheduledAlertKey(userID), id); err != nil {
+		return fmt.Errorf("failed to delete scheduled alert: %w", err)
+	}
+	if err := s.unschedule(ctx, userScopedMember(userID, id)); err != nil {
+		return fmt.Errorf("failed to unschedule alert: %w", err)
+	}
+	return nil
+}
+
+// Start 启动到期规则的定时检查
+func (s *scheduledAlertService) Start(ctx context.Context) error {
+	if !s.config.Notifier.Scheduled.Enabled || s.redisClient == nil {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("scheduled alerts are already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "scheduled_alerts", s.run, s.done)
+
+	s.logger.Infof("Scheduled alerts started with poll interval %s", s.pollInterval())
+	return nil
+}
+
+// Stop 停止定时检查
+func (s *scheduledAlertService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	s.logger.Info("Scheduled alerts stopped")
+	return nil
+}
+
+// run 按间隔执行到期的规则
+func (s *scheduledAlertService) run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval())
+	defer ticker.Stop()
+
+	for {
+		if err := s.runDue(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("Scheduled alert check failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDue 执行所有到期的规则
+func (s *scheduledAlertService) runDue(ctx context.Context) error {
+	now := time.Now()
+	members, err := s.redisClient.ZRangeByScore(ctx, scheduledAlertDueKey, "-inf", strconv.FormatInt(now.UnixMilli(), 10))
+	if err != nil {
+		return fmt.Errorf("failed to load due alerts: %w", err)
+	}
+
+	for _, member := range members {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		userID, id, ok := parseUserScopedMember(member)
+		if !ok {
+			s.unschedule(ctx, member)
+			continue
+		}
+
+		alert, err := s.GetScheduledAlert(ctx, userID, id)
+		if err != nil || !alert.Enabled || alert.NextRunAt == nil {
+			// 规则已删除或停用
+			s.unschedule(ctx, member)
+			continue
+		}
+		if alert.NextRunAt.After(now) {
+			continue
+		}
+
+		acquired, err := s.lock(ctx, alert)
+		if err != nil {
+			s.logger.Warnf("Failed to lock scheduled alert %s: %v", id, err)
+			continue
+		}
+		if !acquired {
+			// 其他实例正在执行
+			continue
+		}
+
+		if delay := now.Sub(*alert.NextRunAt); delay > s.maxDelay() {
+			s.logger.Warnf("Skipping scheduled alert %s for user %s, %s late", id, userID, delay.Round(time.Second))
+		} else {
+			ranAt := time.Now()
+			alert.LastRunAt = &ranAt
+			alert.LastError = ""
+			if err := s.execute(ctx, alert); err != nil {
+				s.logger.Warnf("Scheduled alert %s for user %s failed: %v", id, userID, err)
+				alert.LastError = err.Error()
+			}
+		}
+
+		if err := s.save(ctx, alert); err != nil {
+			s.logger.Errorf("Failed to reschedule alert %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// execute 获取规则中各币种的当前价格并发送通知，全部币种获取失败时返回错误
+func (s *scheduledAlertService) execute(ctx context.Context, alert *model.ScheduledAlert) error {
+	lines := make([]string, 0, len(alert.Symbols))
+	prices := make(map[string]interface{}, len(alert.Symbols))
+	var lastErr error
+	for _, symbol := range alert.Symbols {
+		price, err := s.priceService.GetPrice(ctx, symbol)
+		if err != nil {
+			lastErr = err
+			lines = append(lines, fmt.Sprintf("%s: 获取价格失败", symbol))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s %s", symbol, precision.Format(symbol, price.Price), price.Currency))
+		prices[symbol] = price.Price
+	}
+	if len(prices) == 0 {
+		return fmt.Errorf("failed to get prices: %w", lastErr)
+	}
+
+	title := alert.Name
+	if title == "" {
+		title = "定时价格通知"
+	}
+	return s.notifier.Notify(ctx, &model.Alert{
+		Type:     model.AlertTypeScheduled,
+		Severity: model.AlertSeverityInfo,
+		Title:    title,
+		Message:  strings.Join(lines, "\n"),
+		Subject:  strings.Join(alert.Symbols, ","),
+		UserID:   alert.UserID,
+		Data: map[string]interface{}{
+			"rule_id": alert.ID,
+			"prices":  prices,
+		},
+	})
+}
+
+// applyRequest 校验请求并更新规则，启用时计算下次执行时间
+func (s *scheduledAlertService) applyRequest(alert *model.ScheduledAlert, req *model.ScheduledAlertRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if len(name) > maxScheduledAlertName {
+		return fmt.Errorf("%w: name exceeds %d characters", ErrInvalidParameter, maxScheduledAlertName)
+	}
+
+	symbols := normalizeList(req.Symbols, strings.ToUpper)
+	if len(symbols) == 0 {
+		return fmt.Errorf("%w: symbols is required", ErrInvalidParameter)
+	}
+	if len(symbols) > maxScheduledAlertSymbols {
+		return fmt.Errorf("%w: at most %d symbols per scheduled alert", ErrInvalidParameter, maxScheduledAlertSymbols)
+	}
+
+	spec := strings.TrimSpace(req.Schedule)
+	schedule, err := scheduler.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidParameter, err)
+	}
+
+	timezone := strings.TrimSpace(req.Timezone)
+	if timezone == "" {
+		timezone = s.defaultTimezone()
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidParameter, timezone)
+	}
+
+	enabled := req.Enabled == nil || *req.Enabled
+	var next *time.Time
+	if enabled {
+		at := schedule.Next(time.Now().In(loc))
+		if at.IsZero() {
+			return fmt.Errorf("%w: schedule %q never fires", ErrInvalidParameter, spec)
+		}
+		next = &at
+	}
+
+	alert.Name = name
+	alert.Symbols = symbols
+	alert.Schedule = spec
+	alert.Timezone = timezone
+	alert.Enabled = enabled
+	alert.NextRunAt = next
+	return nil
+}
+
+// save 保存规则并更新到期时间，已执行过的规则先计算下一次执行时间
+func (s *scheduledAlertService) save(ctx context.Context, alert *model.ScheduledAlert) error {
+	if alert.Enabled && alert.NextRunAt != nil && !alert.NextRunAt.After(time.Now()) {
+		next, err := nextScheduledRun(alert)
+		if err != nil {
+			return err
+		}
+		alert.NextRunAt = next
+	}
+	alert.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	if err := s.redisClient.HSet(ctx, scheduledAlertKey(alert.UserID), alert.ID, string(data)); err != nil {
+		return fmt.Errorf("failed to save scheduled alert: %w", err)
+	}
+
+	member := userScopedMember(alert.UserID, alert.ID)
+	if !alert.Enabled || alert.NextRunAt == nil {
+		return s.unschedule(ctx, member)
+	}
+	if err := s.redisClient.ZAdd(ctx, scheduledAlertDueKey, float64(alert.NextRunAt.UnixMilli()), member); err != nil {
+		return fmt.Errorf("failed to schedule alert: %w", err)
+	}
+	return nil
+}
+
+// lock 获取本次执行的锁，锁以规则和计划执行时间为键
+func (s *scheduledAlertService) lock(ctx context.Context, alert *model.ScheduledAlert) (bool, error) {
+	key := fmt.Sprintf("%s%s%s:%d", s.redisClient.KeyPrefix(), scheduledAlertLockKeyPrefix, alert.ID, alert.NextRunAt.Unix())
+	return s.redisClient.GetClient().SetNX(ctx, key, 1, scheduledAlertLockTTL).Result()
+}
+
+// unschedule 从到期集合中移除规则
+func (s *scheduledAlertService) unschedule(ctx context.Context, member string) error {
+	return s.redisClient.GetClient().ZRem(ctx, s.redisClient.KeyPrefix()+scheduledAlertDueKey, member).Err()
+}
+
+// checkStorage 检查用户标识和规则存储
+func (s *scheduledAlertService) checkStorage(userID string) error {
+	if userID == "" {
+		return fmt.Errorf("%w: user id is required", ErrInvalidParameter)
+	}
+	if s.redisClient == nil {
+		return fmt.Errorf("%w: scheduled alert storage is not configured", ErrUpstreamUnavailable)
+	}
+	return nil
+}
+
+// pollInterval 检查到期规则的间隔
+func (s *scheduledAlertService) pollInterval() time.Duration {
+	if s.config.Notifier.Scheduled.PollInterval > 0 {
+		return s.config.Notifier.Scheduled.PollInterval
+	}
+	return defaultScheduledPollInterval
+}
+
+// maxPerUser 每个用户的规则上限
+func (s *scheduledAlertService) maxPerUser() int {
+	if s.config.Notifier.Scheduled.MaxPerUser > 0 {
+		return s.config.Notifier.Scheduled.MaxPerUser
+	}
+	return defaultScheduledMaxPerUser
+}
+
+// maxDelay 允许的最大延迟
+func (s *scheduledAlertService) maxDelay() time.Duration {
+	if s.config.Notifier.Scheduled.MaxDelay > 0 {
+		return s.config.Notifier.Scheduled.MaxDelay
+	}
+	return defaultScheduledMaxDelay
+}
+
+// defaultTimezone 请求未指定时区时使用的时区
+func (s *scheduledAlertService) defaultTimezone() string {
+	if s.config.Notifier.Scheduled.DefaultTimezone != "" {
+		return s.config.Notifier.Scheduled.DefaultTimezone
+	}
+	return defaultScheduledTimezone
+}
+
+// nextScheduledRun 按规则的时区计算当前时间之后的下一次执行时间
+func nextScheduledRun(alert *model.ScheduledAlert) (*time.Time, error) {
+	schedule, err := scheduler.Parse(alert.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule of alert %s: %w", alert.ID, err)
+	}
+	loc, err := time.LoadLocation(alert.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone of alert %s: %w", alert.ID, err)
+	}
+	next := schedule.Next(time.Now().In(loc))
+	if next.IsZero() {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// scheduledAlertKey 用户定时通知存储key
+func scheduledAlertKey(userID string) string {
+	return scheduledAlertKeyPrefix + userID
+}
+
+// userScopedMember 按用户区分的集合成员，ID为UUID，不含分隔符
+func userScopedMember(userID, id string) string {
+	return userID + "|" + id
+}
+
+// parseUserScopedMember 解析userScopedMember生成的成员
+func parseUserScopedMember(member string) (string, string, bool) {
+	i := strings.LastIndex(member, "|")
+	if i <= 0 || i == len(member)-1 {
+		return "", "", false
+	}
+	return member[:i], member[i+1:], true
+}
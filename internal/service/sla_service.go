@@ -0,0 +1,428 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SLA统计默认配置
+const (
+	defaultSLAObjective      = 99.9
+	defaultSLAFlushInterval  = 10 * time.Second
+	defaultSLASampleInterval = time.Minute
+	defaultSLACacheTTL       = 30 * time.Second
+	slaMinuteKeyPrefix       = "sla:m:"
+	slaHourKeyPrefix         = "sla:h:"
+	slaMinuteRetention       = 2 * time.Hour
+	slaHourRetention         = 31 * 24 * time.Hour
+)
+
+// SLA统计的哈希字段
+const (
+	slaFieldRequests      = "requests"
+	slaFieldErrors        = "errors"
+	slaFieldServicePrefix = "status:"
+	slaFieldDepPrefix     = "dep:"
+	slaFieldSamples       = "samples"
+	slaFieldUp            = "up"
+)
+
+// slaWindow 滚动窗口定义
+type slaWindow struct {
+	name    string
+	buckets int  // 桶数量，含当前未结束的桶
+	hourly  bool // 按小时桶统计，否则按分钟桶
+}
+
+// slaWindows 对外提供的滚动窗口
+var slaWindows = []slaWindow{
+	{name: "1h", buckets: 60},
+	{name: "24h", buckets: 24, hourly: true},
+	{name: "30d", buckets: 30 * 24, hourly: true},
+}
+
+// SLAService 请求成功率和依赖可用性统计服务接口
+type SLAService interface {
+	// RecordRequest 记录一次API请求的响应状态码，5xx计为失败
+	RecordRequest(status int)
+	// Status 各滚动窗口的请求成功率和依赖可用性
+	Status(ctx context.Context) (*model.SLAStatusResponse, error)
+	// Start 启动计数写入和依赖采样
+	Start(ctx context.Context) error
+	// Stop 停止后台任务并写入剩余计数
+	Stop() error
+}
+
+// slaService 请求成功率和依赖可用性统计实现，请求计数先在进程内累加，定时写入Redis
+type slaService struct {
+	redisClient   database.RedisClient
+	config        *config.Config
+	logger        logger.Logger
+	healthService HealthService
+	startedAt     time.Time
+
+	requests atomic.Int64
+	failures atomic.Int64
+
+	cacheMu   sync.Mutex
+	cached    *model.SLAStatusResponse
+	expiresAt time.Time
+
+	runMutex sync.Mutex
+	running  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewSLAService 创建SLA统计服务，依赖可用性取自healthService的探测结果
+func NewSLAService(redisClient database.RedisClient, cfg *config.Config, healthService HealthService) SLAService {
+	return &slaService{
+		redisClient:   redisClient,
+		config:        cfg,
+		logger:        logger.GetLogger(),
+		healthService: healthService,
+		startedAt:     time.Now(),
+	}
+}
+
+// RecordRequest 只做进程内计数，不访问Redis
+func (s *slaService) RecordRequest(status int) {
+	s.requests.Add(1)
+	if status >= 500 {
+		s.failures.Add(1)
+	}
+}
+
+// Status 汇总分钟桶和小时桶，结果短时间缓存
+func (s *slaService) Status(ctx context.Context) (*model.SLAStatusResponse, error) {
+	if !s.config.Monitoring.SLA.Enabled {
+		return nil, fmt.Errorf("%w: sla tracking is disabled", ErrNotFound)
+	}
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: redis is not available", ErrUpstreamUnavailable)
+	}
+
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	now := time.Now()
+	if s.cached != nil && now.Before(s.expiresAt) {
+		return s.cached, nil
+	}
+
+	buckets, err := s.loadBuckets(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	objective := s.objective()
+	resp := &model.SLAStatusResponse{
+		Objective:     objective,
+		Windows:       make([]model.SLAWindow, 0, len(slaWindows)),
+		StartedAt:     s.startedAt,
+		UptimeSeconds: int64(now.Sub(s.startedAt).Seconds()),
+		GeneratedAt:   now,
+	}
+	for _, w := range slaWindows {
+		source := buckets.minutes
+		if w.hourly {
+			source = buckets.hours
+		}
+		resp.Windows = append(resp.Windows, summarizeSLAWindow(w.name, source[:w.buckets], objective))
+	}
+
+	s.cached = resp
+	s.expiresAt = now.Add(s.cacheTTL())
+	return resp, nil
+}
+
+// slaBuckets 从新到旧排列的分钟桶和小时桶
+type slaBuckets struct {
+	minutes []map[string]string
+	hours   []map[string]string
+}
+
+// loadBuckets 用一次pipeline读取所有窗口需要的桶
+func (s *slaService) loadBuckets(ctx context.Context, now time.Time) (*slaBuckets, error) {
+	minuteCount, hourCount := 0, 0
+	for _, w := range slaWindows {
+		if w.hourly && w.buckets > hourCount {
+			hourCount = w.buckets
+		} else if !w.hourly && w.buckets > minuteCount {
+			minuteCount = w.buckets
+		}
+	}
+
+	pipe := s.redisClient.GetClient().Pipeline()
+	minuteCmds := make([]*redis.MapStringStringCmd, minuteCount)
+	for i := range minuteCmds {
+		minuteCmds[i] = pipe.HGetAll(ctx, s.redisClient.KeyPrefix()+slaMinuteKey(now.Add(-time.Duration(i)*time.Minute)))
+	}
+	hourCmds := make([]*redis.MapStringStringCmd, hourCount)
+	for i := range hourCmds {
+		hourCmds[i] = pipe.HGetAll(ctx, s.redisClient.KeyPrefix()+slaHourKey(now.Add(-time.Duration(i)*time.Hour)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to load sla buckets: %w", err)
+	}
+
+	buckets := &slaBuckets{
+		minutes: make([]map[string]string, minuteCount),
+		hours:   make([]map[string]string, hourCount),
+	}
+	for i, cmd := range minuteCmds {
+		buckets.minutes[i] = cmd.Val()
+	}
+	for i, cmd := range hourCmds {
+		buckets.hours[i] = cmd.Val()
+	}
+	return buckets, nil
+}
+
+// summarizeSLAWindow 累加窗口内各桶的计数
+func summarizeSLAWindow(name string, buckets []map[string]string, objective float64) model.SLAWindow {
+	var requests, errorCount, serviceSamples, serviceUp int64
+	depSamples := make(map[string]int64)
+	depUp := make(map[string]int64)
+
+	for _, bucket := range buckets {
+		for field, value := range bucket {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case field == slaFieldRequests:
+				requests += n
+			case field == slaFieldErrors:
+				errorCount += n
+			case field == slaFieldServicePrefix+slaFieldSamples:
+				serviceSamples += n
+			case field == slaFieldServicePrefix+slaFieldUp:
+				serviceUp += n
+			case strings.HasPrefix(field, slaFieldDepPrefix):
+				dep, kind, ok := strings.Cut(strings.TrimPrefix(field, slaFieldDepPrefix), ":")
+				if !ok {
+					continue
+				}
+				if kind == slaFieldSamples {
+					depSamples[dep] += n
+				} else if kind == slaFieldUp {
+					depUp[dep] += n
+				}
+			}
+		}
+	}
+
+	window := model.SLAWindow{
+		Window:       name,
+		Requests:     requests,
+		Errors:       errorCount,
+		SuccessRate:  slaPercent(requests-errorCount, requests),
+		Availability: slaPercent(serviceUp, serviceSamples),
+		Dependencies: make([]model.SLADependency, 0, len(depSamples)),
+	}
+	window.MeetsObjective = window.SuccessRate >= objective
+	for dep, samples := range depSamples {
+		window.Dependencies = append(window.Dependencies, model.SLADependency{
+			Name:         dep,
+			Samples:      samples,
+			Availability: slaPercent(depUp[dep], samples),
+		})
+	}
+	sort.Slice(window.Dependencies, func(i, j int) bool {
+		return window.Dependencies[i].Name < window.Dependencies[j].Name
+	})
+	return window
+}
+
+// slaPercent 百分比，保留三位小数，分母为0时视为100%
+func slaPercent(part, total int64) float64 {
+	if total <= 0 {
+		return 100
+	}
+	return float64(int64(float64(part)/float64(total)*100000+0.5)) / 1000
+}
+
+// Start 启动计数写入和依赖采样
+func (s *slaService) Start(ctx context.Context) error {
+	if !s.config.Monitoring.SLA.Enabled || s.redisClient == nil {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return errors.New("sla tracker is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "sla", s.run, s.done)
+
+	s.logger.Infof("SLA tracker started, flush interval %s, sample interval %s", s.flushInterval(), s.sampleInterval())
+	return nil
+}
+
+// Stop 停止后台任务，退出前写入尚未保存的请求计数
+func (s *slaService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.flush(ctx)
+
+	s.logger.Info("SLA tracker stopped")
+	return nil
+}
+
+// run 按间隔写入请求计数和采样依赖状态
+func (s *slaService) run(ctx context.Context) {
+	flushTicker := time.NewTicker(s.flushInterval())
+	defer flushTicker.Stop()
+	sampleTicker := time.NewTicker(s.sampleInterval())
+	defer sampleTicker.Stop()
+
+	s.sample(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-flushTicker.C:
+			s.flush(ctx)
+		case <-sampleTicker.C:
+			s.sample(ctx)
+		}
+	}
+}
+
+// flush 将进程内累加的请求计数写入当前的分钟桶和小时桶，写入失败时计数放回下次重试
+func (s *slaService) flush(ctx context.Context) {
+	requests := s.requests.Swap(0)
+	errorCount := s.failures.Swap(0)
+	if requests == 0 {
+		return
+	}
+
+	fields := map[string]int64{slaFieldRequests: requests}
+	if errorCount > 0 {
+		fields[slaFieldErrors] = errorCount
+	}
+	if err := s.increment(ctx, time.Now(), fields); err != nil {
+		s.requests.Add(requests)
+		s.failures.Add(errorCount)
+		s.logger.Warnf("Failed to flush sla request counters: %v", err)
+	}
+}
+
+// sample 记录一次依赖探测结果，degraded视为可用
+func (s *slaService) sample(ctx context.Context) {
+	if s.healthService == nil {
+		return
+	}
+	readiness := s.healthService.Readiness(ctx)
+	if readiness == nil {
+		return
+	}
+
+	fields := map[string]int64{slaFieldServicePrefix + slaFieldSamples: 1}
+	if readiness.Status != model.HealthStatusDown {
+		fields[slaFieldServicePrefix+slaFieldUp] = 1
+	}
+	for _, dep := range readiness.Dependencies {
+		prefix := slaFieldDepPrefix + dep.Name + ":"
+		fields[prefix+slaFieldSamples] = 1
+		if dep.Status != model.HealthStatusDown {
+			fields[prefix+slaFieldUp] = 1
+		}
+	}
+	if err := s.increment(ctx, time.Now(), fields); err != nil {
+		s.logger.Warnf("Failed to record sla dependency sample: %v", err)
+	}
+}
+
+// increment 在同一事务中累加分钟桶和小时桶的字段
+func (s *slaService) increment(ctx context.Context, now time.Time, fields map[string]int64) error {
+	minuteKey := s.redisClient.KeyPrefix() + slaMinuteKey(now)
+	hourKey := s.redisClient.KeyPrefix() + slaHourKey(now)
+
+	pipe := s.redisClient.GetClient().TxPipeline()
+	for field, n := range fields {
+		pipe.HIncrBy(ctx, minuteKey, field, n)
+		pipe.HIncrBy(ctx, hourKey, field, n)
+	}
+	pipe.Expire(ctx, minuteKey, slaMinuteRetention)
+	pipe.Expire(ctx, hourKey, slaHourRetention)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// slaMinuteKey 分钟桶的key
+func slaMinuteKey(t time.Time) string {
+	return slaMinuteKeyPrefix + strconv.FormatInt(t.Unix()/60, 10)
+}
+
+// slaHourKey 小时桶的key
+func slaHourKey(t time.Time) string {
+	return slaHourKeyPrefix + strconv.FormatInt(t.Unix()/3600, 10)
+}
+
+// objective 请求成功率目标(%)
+func (s *slaService) objective() float64 {
+	if s.config.Monitoring.SLA.Objective > 0 {
+		return s.config.Monitoring.SLA.Objective
+	}
+	return defaultSLAObjective
+}
+
+// flushInterval 请求计数写入间隔
+func (s *slaService) flushInterval() time.Duration {
+	if s.config.Monitoring.SLA.FlushInterval > 0 {
+		return s.config.Monitoring.SLA.FlushInterval
+	}
+	return defaultSLAFlushInterval
+}
+
+// sampleInterval 依赖采样间隔
+func (s *slaService) sampleInterval() time.Duration {
+	if s.config.Monitoring.SLA.SampleInterval > 0 {
+		return s.config.Monitoring.SLA.SampleInterval
+	}
+	return defaultSLASampleInterval
+}
+
+// cacheTTL 统计结果缓存时间
+func (s *slaService) cacheTTL() time.Duration {
+	if s.config.Monitoring.SLA.CacheTTL > 0 {
+		return s.config.Monitoring.SLA.CacheTTL
+	}
+	return defaultSLACacheTTL
+}
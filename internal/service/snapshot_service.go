@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
+)
+
+// 持币快照默认配置
+const (
+	defaultSnapshotDir       = "./data/snapshots"
+	defaultSnapshotChunkSize = 5000
+	defaultSnapshotMaxJobs   = 2
+	defaultSnapshotRetention = 7 * 24 * time.Hour
+	snapshotJobsKey          = "snapshots:jobs"
+)
+
+// SnapshotService 持币快照导出服务接口
+type SnapshotService interface {
+	Start(ctx context.Context) error
+	Stop() error
+	// CreateSnapshot 创建快照任务并异步执行
+	CreateSnapshot(ctx context.Context, req *model.SnapshotRequest) (*model.SnapshotJob, error)
+	// GetJob 获取快照任务状态
+	GetJob(ctx context.Context, id string) (*model.SnapshotJob, error)
+	// ResultPath 获取已完成任务的导出文件路径
+	ResultPath(ctx context.Context, id string) (string, *model.SnapshotJob, error)
+}
+
+// snapshotService 回放代币的Transfer事件计算指定区块的持币余额，结果导出为CSV
+type snapshotService struct {
+	redisClient database.RedisClient
+	config      *config.Config
+	bscService  BSCService
+	reader      *contractReader
+	logger      logger.Logger
+
+	runMutex sync.Mutex
+	running  bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	jobs     sync.WaitGroup
+	slots    chan struct{}
+}
+
+// NewSnapshotService 创建持币快照导出服务
+func NewSnapshotService(redisClient database.RedisClient, cfg *config.Config, bscService BSCService) SnapshotService {
+	return &snapshotService{
+		redisClient: redisClient,
+		config:      cfg,
+		bscService:  bscService,
+		reader:      newContractReader(bscService),
+		logger:      logger.GetLogger(),
+	}
+}
+
+// Start 标记上次进程退出时中断的任务并清理过期任务
+func (s *snapshotService) Start(ctx context.Context) error {
+	if !s.config.BSC.Snapshot.Enabled || s.redisClient == nil || s.bscService == nil {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("snapshot service is already running")
+	}
+
+	if err := os.MkdirAll(s.dir(), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot dir: %w", err)
+	}
+
+	maxJobs := s.config.BSC.Snapshot.MaxJobs
+	if maxJobs <= 0 {
+		maxJobs = defaultSnapshotMaxJobs
+	}
+	s.ctx, s.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	s.slots = make(chan struct{}, maxJobs)
+	s.running = true
+
+	s.recover(ctx)
+
+	s.logger.Infof("Snapshot service started with %d job slots", maxJobs)
+	return nil
+}
+
+// Stop 取消执行中的任务并等待退出
+func (s *snapshotService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	s.jobs.Wait()
+	s.running = false
+
+	s.logger.Info("Snapshot service stopped")
+	return nil
+}
+
+// CreateSnapshot 校验参数后保存任务，由后台协程回放转账
+func (s *snapshotService) CreateSnapshot(ctx context.Context, req *model.SnapshotRequest) (*model.SnapshotJob, error) {
+	if !common.IsHexAddress(req.Token) {
+		return nil, fmt.Errorf("%w: invalid token address %q", ErrInvalidParameter, req.Token)
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil, fmt.Errorf("%w: holder snapshots are not enabled", ErrUpstreamUnavailable)
+	}
+
+	latestBlock, err := s.bscService.GetLatestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest := latestBlock.Number.Uint64()
+
+	block := req.Block
+	if block == 0 {
+		block = latest
+	}
+	if block > latest {
+		return nil, fmt.Errorf("%w: block %d is ahead of latest block %d", ErrInvalidParameter, block, latest)
+	}
+	if req.StartBlock > block {
+		return nil, fmt.Errorf("%w: start_block %d is after block %d", ErrInvalidParameter, req.StartBlock, block)
+	}
+	if maxBlocks := s.config.BSC.Snapshot.MaxBlocks; maxBlocks > 0 && block-req.StartBlock+1 > maxBlocks {
+		return nil, fmt.Errorf("%w: block range exceeds %d blocks, set a later start_block", ErrInvalidParameter, maxBlocks)
+	}
+
+	token, err := s.reader.readToken(ctx, common.HexToAddress(req.Token))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read token %s: %v", ErrInvalidParameter, req.Token, err)
+	}
+
+	now := time.Now()
+	job := &model.SnapshotJob{
+		ID:         "snap-" + strconv.FormatInt(now.UnixNano(), 36),
+		Token:      strings.ToLower(token.address.Hex()),
+		Symbol:     token.symbol,
+		Decimals:   int(token.decimals),
+		Block:      block,
+		StartBlock: req.StartBlock,
+		Status:     model.SnapshotStatusPending,
+		CreatedAt:  now,
+	}
+	if err := s.saveJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.prune(ctx)
+
+	s.jobs.Add(1)
+	panics.Go("snapshot", func() { s.execute(s.ctx, job, token) })
+
+	s.logger.Infof("Created snapshot %s for %s at block %d", job.ID, job.Token, job.Block)
+	return job, nil
+}
+
+// GetJob 获取快照任务状态
+func (s *snapshotService) GetJob(ctx context.Context, id string) (*model.SnapshotJob, error) {
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: snapshot %s", ErrNotFound, id)
+	}
+
+	data, err := s.redisClient.HGet(ctx, snapshotJobsKey, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	if data == "" {
+		return nil, fmt.Errorf("%w: snapshot %s", ErrNotFound, id)
+	}
+
+	var job model.SnapshotJob
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// ResultPath 获取已完成任务的导出文件路径
+func (s *snapshotService) ResultPath(ctx context.Context, id string) (string, *model.SnapshotJob, error) {
+	job, err := s.GetJob(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if job.Status != model.SnapshotStatusCompleted {
+		return "", nil, fmt.Errorf("%w: snapshot %s is %s", ErrNotFound, id, job.Status)
+	}
+
+	path := s.resultPath(id)
+	if _, err := os.Stat(path); err != nil {
+		return "", nil, fmt.Errorf("%w: snapshot %s result file is missing", ErrNotFound, id)
+	}
+	return path, job, nil
+}
+
+// execute 排队等待执行槽位后回放转账并导出结果
+func (s *snapshotService) execute(ctx context.Context, job *model.SnapshotJob, token chainToken) {
+	defer s.jobs.Done()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		s.fail(job, ctx.Err())
+		return
+	}
+
+	job.Status = model.SnapshotStatusRunning
+	if err := s.saveJob(ctx, job); err != nil {
+		s.logger.Warnf("Failed to update snapshot %s: %v", job.ID, err)
+	}
+
+	balances, err := s.replay(ctx, job)
+	if err != nil {
+		s.fail(job, err)
+		return
+	}
+
+	holders, supply, err := s.export(job, token, balances)
+	if err != nil {
+		s.fail(job, err)
+		return
+	}
+
+	completedAt := time.Now()
+	job.Status = model.SnapshotStatusCompleted
+	job.Progress = 100
+	job.Holders = holders
+	job.TotalSupply = supply
+	job.DownloadURL = "/api/v1/bsc/token/snapshot/" + job.ID + "/download"
+	job.CompletedAt = &completedAt
+	if err := s.saveJob(context.WithoutCancel(ctx), job); err != nil {
+		s.logger.Warnf("Failed to update snapshot %s: %v", job.ID, err)
+	}
+
+	s.logger.Infof("Snapshot %s completed: holders=%d transfers=%d", job.ID, job.Holders, job.Transfers)
+}
+
+// replay 按区块分段回放Transfer事件，节点拒绝过大的查询时缩小分段重试
+func (s *snapshotService) replay(ctx context.Context, job *model.SnapshotJob) (map[common.Address]*big.Int, error) {
+	chunk := s.config.BSC.Snapshot.ChunkSize
+	if chunk == 0 {
+		chunk = defaultSnapshotChunkSize
+	}
+
+	balances := make(map[common.Address]*big.Int)
+	token := common.HexToAddress(job.Token)
+	span := job.Block - job.StartBlock + 1
+
+	for from := job.StartBlock; from <= job.Block; {
+		to := from + chunk - 1
+		if to > job.Block || to < from {
+			to = job.Block
+		}
+
+		logs, err := s.bscService.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{token},
+			Topics:    [][]common.Hash{{tokenTransferTopic}},
+		})
+		if err != nil {
+			if ctx.Err() != nil || to == from {
+				return nil, err
+			}
+			chunk = (to - from + 1) / 2
+			s.logger.Debugf("Snapshot %s: log query for blocks %d-%d failed, retrying with %d blocks: %v", job.ID, from, to, chunk, err)
+			continue
+		}
+
+		job.Transfers += applyTransfers(balances, logs)
+		job.Progress = float64(to-job.StartBlock+1) / float64(span) * 100
+		if err := s.saveJob(ctx, job); err != nil {
+			s.logger.Warnf("Failed to update snapshot %s: %v", job.ID, err)
+		}
+
+		from = to + 1
+	}
+	return balances, nil
+}
+
+// applyTransfers 将Transfer事件计入余额，铸造和销毁不计入零地址，返回处理的转账数
+func applyTransfers(balances map[common.Address]*big.Int, logs []types.Log) int {
+	applied := 0
+	for _, entry := range logs {
+		// ERC721的Transfer有4个topic，与BEP20签名相同但不表示数量
+		if entry.Removed || len(entry.Topics) != 3 || len(entry.Data) < 32 {
+			continue
+		}
+		value := new(big.Int).SetBytes(entry.Data[:32])
+		from := common.BytesToAddress(entry.Topics[1].Bytes())
+		to := common.BytesToAddress(entry.Topics[2].Bytes())
+
+		if from != (common.Address{}) {
+			if balance, ok := balances[from]; ok {
+				balance.Sub(balance, value)
+			} else {
+				balances[from] = new(big.Int).Neg(value)
+			}
+		}
+		if to != (common.Address{}) {
+			if balance, ok := balances[to]; ok {
+				balance.Add(balance, value)
+			} else {
+				balances[to] = new(big.Int).Set(value)
+			}
+		}
+		applied++
+	}
+	return applied
+}
+
+// export 按余额倒序写出CSV，返回持币地址数和持币总量
+func (s *snapshotService) export(job *model.SnapshotJob, token chainToken, balances map[common.Address]*big.Int) (int, string, error) {
+	type holder struct {
+		address common.Address
+		balance *big.Int
+	}
+	holders := make([]holder, 0, len(balances))
+	supply := new(big.Int)
+	for address, balance := range balances {
+		if balance.Sign() <= 0 {
+			continue
+		}
+		holders = append(holders, holder{address: address, balance: balance})
+		supply.Add(supply, balance)
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		if cmp := holders[i].balance.Cmp(holders[j].balance); cmp != 0 {
+			return cmp > 0
+		}
+		return holders[i].address.Hex() < holders[j].address.Hex()
+	})
+
+	path := s.resultPath(job.ID)
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+
+	w := csv.NewWriter(file)
+	_ = w.Write([]string{"address", "balance", "balance_raw"})
+	for _, h := range holders {
+		_ = w.Write([]string{
+			strings.ToLower(h.address.Hex()),
+			decimal.NewFromBigInt(h.balance, -int32(token.decimals)).String(),
+			h.balance.String(),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return 0, "", fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return 0, "", fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, "", fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+
+	return len(holders), decimal.NewFromBigInt(supply, -int32(token.decimals)).String(), nil
+}
+
+// fail 将任务标记为失败
+func (s *snapshotService) fail(job *model.SnapshotJob, err error) {
+	completedAt := time.Now()
+	job.Status = model.SnapshotStatusFailed
+	job.Error = err.Error()
+	job.CompletedAt = &completedAt
+	if saveErr := s.saveJob(context.Background(), job); saveErr != nil {
+		s.logger.Warnf("Failed to update snapshot %s: %v", job.ID, saveErr)
+	}
+	s.logger.Errorf("Snapshot %s failed: %v", job.ID, err)
+}
+
+// recover 将上次进程退出时未完成的任务标记为失败
+func (s *snapshotService) recover(ctx context.Context) {
+	jobs, err := s.loadJobs(ctx)
+	if err != nil {
+		s.logger.Warnf("Failed to load snapshot jobs: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		if job.Status == model.SnapshotStatusPending || job.Status == model.SnapshotStatusRunning {
+			s.fail(job, fmt.Errorf("interrupted by service restart"))
+		}
+	}
+	s.prune(ctx)
+}
+
+// prune 删除超过保留时长的已结束任务及其导出文件
+func (s *snapshotService) prune(ctx context.Context) {
+	retention := s.config.BSC.Snapshot.Retention
+	if retention <= 0 {
+		retention = defaultSnapshotRetention
+	}
+	cutoff := time.Now().Add(-retention)
+
+	jobs, err := s.loadJobs(ctx)
+	if err != nil {
+		s.logger.Warnf("Failed to load snapshot jobs: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		if job.CompletedAt == nil || job.CreatedAt.After(cutoff) {
+			continue
+		}
+		if err := s.redisClient.HDel(ctx, snapshotJobsKey, job.ID); err != nil {
+			s.logger.Warnf("Failed to delete snapshot %s: %v", job.ID, err)
+			continue
+		}
+		if err := os.Remove(s.resultPath(job.ID)); err != nil && !os.IsNotExist(err) {
+			s.logger.Warnf("Failed to delete snapshot file %s: %v", job.ID, err)
+		}
+	}
+}
+
+// loadJobs 读取全部快照任务
+func (s *snapshotService) loadJobs(ctx context.Context) ([]*model.SnapshotJob, error) {
+	values, err := s.redisClient.HGetAll(ctx, snapshotJobsKey)
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*model.SnapshotJob, 0, len(values))
+	for _, data := range values {
+		var job model.SnapshotJob
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// saveJob 保存任务状态
+func (s *snapshotService) saveJob(ctx context.Context, job *model.SnapshotJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err := s.redisClient.HSet(ctx, snapshotJobsKey, job.ID, string(data)); err != nil {
+		return fmt.Errorf("failed to save snapshot %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// resultPath 导出文件路径
+func (s *snapshotService) resultPath(id string) string {
+	return filepath.Join(s.dir(), id+".csv")
+}
+
+// dir 导出文件目录
+func (s *snapshotService) dir() string {
+	if s.config.BSC.Snapshot.Dir != "" {
+		return s.config.BSC.Snapshot.Dir
+	}
+	return defaultSnapshotDir
+}
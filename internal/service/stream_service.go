@@ -0,0 +1,615 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// 推送服务默认配置
+const (
+	defaultStreamChannel    = "stream:events"
+	defaultStreamBufferSize = 64
+	streamNodesKey          = "stream:nodes"
+	streamReplayKey         = "stream:replay"
+	streamHeartbeatInterval = 15 * time.Second
+	streamNodeTTL           = 3 * streamHeartbeatInterval // 超过该时间未上报的实例视为下线
+
+	streamSubscriptionKeyPrefix  = "stream:subscription:"
+	defaultStreamSubscriptionTTL = 7 * 24 * time.Hour
+	maxStreamSubscriptionSymbols = 200
+	maxStreamSubscriptionName    = 64
+	maxStreamRate                = 100 // 每个币种每秒推送上限的最大值
+)
+
+// ErrTooManyConnections 推送连接数达到单实例上限
+var ErrTooManyConnections = errors.New("too many stream connections")
+
+// StreamService WebSocket推送服务接口
+type StreamService interface {
+	// Start 订阅Redis频道并定期上报本实例连接数
+	Start(ctx context.Context) error
+	// Stop 停止订阅并关闭所有本地订阅者
+	Stop() error
+	// Publish 发布事件，所有实例的匹配连接都会收到
+	Publish(ctx context.Context, event *model.StreamEvent) error
+	// Replay 最近的n条匹配事件，按时间正序
+	Replay(ctx context.Context, filter model.StreamFilter, n int) ([]*model.StreamEvent, error)
+	// Subscribe 注册本地订阅者，连接断开时调用 StreamSubscriber.Close
+	Subscribe(filter model.StreamFilter) (*StreamSubscriber, error)
+	// SubscribeByID 按命名订阅的过滤条件注册本地订阅者，并顺延订阅有效期，userID为连接的用户
+	SubscribeByID(ctx context.Context, id, userID string) (*StreamSubscriber, *model.StreamSubscription, error)
+	// CreateSubscription 创建命名订阅
+	CreateSubscription(ctx context.Context, req *model.StreamSubscriptionRequest) (*model.StreamSubscription, error)
+	// GetSubscription 获取命名订阅
+	GetSubscription(ctx context.Context, id string) (*model.StreamSubscription, error)
+	// UpdateSubscription 替换命名订阅的过滤条件，已连接的客户端重连后生效
+	UpdateSubscription(ctx context.Context, id string, req *model.StreamSubscriptionRequest) (*model.StreamSubscription, error)
+	// DeleteSubscription 删除命名订阅
+	DeleteSubscription(ctx context.Context, id string) error
+	// Stats 获取所有实例的连接数
+	Stats(ctx context.Context) (*model.StreamStatsResponse, error)
+	// Metrics 本实例的连接数和事件计数
+	Metrics() StreamMetrics
+	// Enabled 是否启用推送
+	Enabled() bool
+}
+
+// StreamMetrics 本实例的推送统计
+type StreamMetrics struct {
+	Node        string
+	Connections int
+	Published   int64 // 本实例发布的事件数
+	Delivered   int64 // 投递给本地连接的事件数
+	Dropped     int64 // 因连接发送缓冲已满丢弃的事件数
+}
+
+// StreamSubscriber 本地订阅者，对应一个WebSocket连接
+type StreamSubscriber struct {
+	filter    model.StreamFilter
+	events    chan *model.StreamEvent
+	service   *streamService
+	closeOnce sync.Once
+}
+
+// Events 匹配过滤条件的事件，订阅者关闭后通道关闭
+func (s *StreamSubscriber) Events() <-chan *model.StreamEvent {
+	return s.events
+}
+
+// Filter 订阅者的过滤条件
+func (s *StreamSubscriber) Filter() model.StreamFilter {
+	return s.filter
+}
+
+// Close 取消订阅，可重复调用
+func (s *StreamSubscriber) Close() {
+	s.closeOnce.Do(func() {
+		s.service.remove(s)
+	})
+}
+
+// streamService 推送服务实现
+type streamService struct {
+	redisClient database.RedisClient
+	config      *config.Config
+	logger      logger.Logger
+	node        string
+
+	mu          sync.RWMutex
+	subscribers map[*StreamSubscriber]struct{}
+	recent      []*model.StreamEvent // 未配置Redis时保留的最近事件，按时间正序
+
+	published atomic.Int64
+	delivered atomic.Int64
+	dropped   atomic.Int64
+
+	running  bool
+	runMutex sync.Mutex
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewStreamService 创建推送服务，redisClient为空时事件只推送给本实例的连接
+func NewStreamService(redisClient database.RedisClient, cfg *config.Config) StreamService {
+	node, err := os.Hostname()
+	if err != nil || node == "" {
+		node = "unknown"
+	}
+	return &streamService{
+		redisClient: redisClient,
+		config:      cfg,
+		logger:      logger.GetLogger(),
+		node:        node + "-" + strconv.Itoa(os.Getpid()),
+		subscribers: make(map[*StreamSubscriber]struct{}),
+	}
+}
+
+// Enabled 是否启用推送
+func (s *streamService) Enabled() bool {
+	return s.config.Stream.Enabled
+}
+
+// Start 订阅Redis频道，收到的事件分发给本地订阅者
+func (s *streamService) Start(ctx context.Context) error {
+	if !s.config.Stream.Enabled || s.redisClient == nil {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("stream service is already running")
+	}
+
+	ctx, s.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	pubsub := s.redisClient.GetClient().Subscribe(ctx, s.channel())
+	if _, err := pubsub.Receive(ctx); err != nil {
+		s.cancel()
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe stream channel: %w", err)
+	}
+	s.running = true
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event model.StreamEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					s.logger.Warnf("Invalid stream event: %v", err)
+					continue
+				}
+				s.broadcast(&event)
+			}
+		}
+	}()
+	go func() {
+		defer s.wg.Done()
+		s.heartbeat(ctx)
+	}()
+
+	s.logger.Infof("Stream service started on node %s", s.node)
+	return nil
+}
+
+// Stop 停止订阅，关闭本地订阅者并移除本实例的连接数记录
+func (s *streamService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	s.wg.Wait()
+	s.running = false
+
+	s.mu.Lock()
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.redisClient.HDel(ctx, streamNodesKey, s.node); err != nil {
+		s.logger.Warnf("Failed to remove stream node stats: %v", err)
+	}
+
+	s.logger.Info("Stream service stopped")
+	return nil
+}
+
+// Publish 发布事件，有Redis时经pub/sub分发(包括本实例)，否则直接推送给本地订阅者
+func (s *streamService) Publish(ctx context.Context, event *model.StreamEvent) error {
+	if !s.config.Stream.Enabled {
+		return nil
+	}
+
+	event.Node = s.node
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	s.published.Add(1)
+
+	if s.redisClient == nil {
+		s.remember(event)
+		s.broadcast(event)
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	pipe := s.redisClient.GetClient().TxPipeline()
+	pipe.Publish(ctx, s.channel(), data)
+	if size := s.config.Stream.ReplaySize; size > 0 {
+		key := s.redisClient.KeyPrefix() + streamReplayKey
+		pipe.LPush(ctx, key, data)
+		pipe.LTrim(ctx, key, 0, int64(size-1))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish stream event: %w", err)
+	}
+	return nil
+}
+
+// Replay 从最近事件中取出匹配过滤条件的最后n条
+func (s *streamService) Replay(ctx context.Context, filter model.StreamFilter, n int) ([]*model.StreamEvent, error) {
+	size := s.config.Stream.ReplaySize
+	if n <= 0 || size <= 0 {
+		return nil, nil
+	}
+
+	var events []*model.StreamEvent
+	if s.redisClient == nil {
+		s.mu.RLock()
+		events = append(events, s.recent...)
+		s.mu.RUnlock()
+	} else {
+		values, err := s.redisClient.GetClient().LRange(ctx, s.redisClient.KeyPrefix()+streamReplayKey, 0, int64(size-1)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load recent stream events: %w", err)
+		}
+		// 列表按时间倒序保存
+		for i := len(values) - 1; i >= 0; i-- {
+			var event model.StreamEvent
+			if err := json.Unmarshal([]byte(values[i]), &event); err != nil {
+				continue
+			}
+			events = append(events, &event)
+		}
+	}
+
+	matched := make([]*model.StreamEvent, 0, n)
+	for i := len(events) - 1; i >= 0 && len(matched) < n; i-- {
+		if filter.Match(events[i]) {
+			matched = append(matched, events[i])
+		}
+	}
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	return matched, nil
+}
+
+// Subscribe 注册本地订阅者
+func (s *streamService) Subscribe(filter model.StreamFilter) (*StreamSubscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit := s.config.Stream.MaxConnections; limit > 0 && len(s.subscribers) >= limit {
+		return nil, ErrTooManyConnections
+	}
+
+	sub := &StreamSubscriber{
+		filter:  filter,
+		events:  make(chan *model.StreamEvent, s.bufferSize()),
+		service: s,
+	}
+	s.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+// SubscribeByID 按命名订阅注册本地订阅者
+func (s *streamService) SubscribeByID(ctx context.Context, id, userID string) (*StreamSubscriber, *model.StreamSubscription, error) {
+	sub, err := s.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.saveSubscription(ctx, sub); err != nil {
+		return nil, nil, err
+	}
+	filter := sub.Filter()
+	filter.UserID = userID
+	subscriber, err := s.Subscribe(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return subscriber, sub, nil
+}
+
+// CreateSubscription 创建命名订阅，保存在Redis中供所有实例使用
+func (s *streamService) CreateSubscription(ctx context.Context, req *model.StreamSubscriptionRequest) (*model.StreamSubscription, error) {
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: subscription storage is not configured", ErrUpstreamUnavailable)
+	}
+
+	sub := &model.StreamSubscription{
+		ID:        uuid.New().String(),
+		CreatedAt: time.Now(),
+	}
+	if err := applySubscriptionRequest(sub, req); err != nil {
+		return nil, err
+	}
+	if err := s.saveSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// GetSubscription 获取命名订阅，不存在或已过期时返回ErrNotFound
+func (s *streamService) GetSubscription(ctx context.Context, id string) (*model.StreamSubscription, error) {
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: subscription storage is not configured", ErrUpstreamUnavailable)
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, id)
+	}
+
+	value, err := s.redisClient.Get(ctx, streamSubscriptionKeyPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load subscription: %w", err)
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, id)
+	}
+
+	var sub model.StreamSubscription
+	if err := json.Unmarshal([]byte(value), &sub); err != nil {
+		return nil, fmt.Errorf("invalid subscription %s: %w", id, err)
+	}
+	return &sub, nil
+}
+
+// UpdateSubscription 替换命名订阅的名称和过滤条件
+func (s *streamService) UpdateSubscription(ctx context.Context, id string, req *model.StreamSubscriptionRequest) (*model.StreamSubscription, error) {
+	sub, err := s.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySubscriptionRequest(sub, req); err != nil {
+		return nil, err
+	}
+	if err := s.saveSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// DeleteSubscription 删除命名订阅
+func (s *streamService) DeleteSubscription(ctx context.Context, id string) error {
+	if _, err := s.GetSubscription(ctx, id); err != nil {
+		return err
+	}
+	if err := s.redisClient.Del(ctx, streamSubscriptionKeyPrefix+id); err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	return nil
+}
+
+// saveSubscription 保存订阅并顺延有效期
+func (s *streamService) saveSubscription(ctx context.Context, sub *model.StreamSubscription) error {
+	ttl := s.subscriptionTTL()
+	sub.UpdatedAt = time.Now()
+	sub.ExpiresAt = sub.UpdatedAt.Add(ttl)
+
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	if err := s.redisClient.Set(ctx, streamSubscriptionKeyPrefix+sub.ID, data, ttl); err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return nil
+}
+
+// applySubscriptionRequest 校验请求并写入订阅，事件类型转为小写、币种转为大写并去重
+func applySubscriptionRequest(sub *model.StreamSubscription, req *model.StreamSubscriptionRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if len(name) > maxStreamSubscriptionName {
+		return fmt.Errorf("%w: name exceeds %d characters", ErrInvalidParameter, maxStreamSubscriptionName)
+	}
+
+	types := normalizeList(req.Types, strings.ToLower)
+	for _, t := range types {
+		if t != model.StreamEventPrice && t != model.StreamEventAlert && t != model.StreamEventChain {
+			return fmt.Errorf("%w: unsupported event type %q", ErrInvalidParameter, t)
+		}
+	}
+	symbols := normalizeList(req.Symbols, strings.ToUpper)
+	if len(symbols) > maxStreamSubscriptionSymbols {
+		return fmt.Errorf("%w: at most %d symbols per subscription", ErrInvalidParameter, maxStreamSubscriptionSymbols)
+	}
+
+	if err := ValidateStreamOptions(req.StreamOptions); err != nil {
+		return err
+	}
+
+	sub.Name = name
+	sub.Types = types
+	sub.Symbols = symbols
+	sub.StreamOptions = req.StreamOptions
+	return nil
+}
+
+// ValidateStreamOptions 校验推送方式参数
+func ValidateStreamOptions(opts model.StreamOptions) error {
+	if opts.MaxRate < 0 || opts.MaxRate > maxStreamRate {
+		return fmt.Errorf("%w: max_rate must be between 0 and %d", ErrInvalidParameter, maxStreamRate)
+	}
+	return nil
+}
+
+// normalizeList 去除空值和重复值
+func normalizeList(values []string, normalize func(string) string) []string {
+	var result []string
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = normalize(strings.TrimSpace(value))
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
+
+// Stats 汇总各实例上报的连接数，忽略超时未上报的实例
+func (s *streamService) Stats(ctx context.Context) (*model.StreamStatsResponse, error) {
+	local := s.Metrics()
+	resp := &model.StreamStatsResponse{Node: s.node, Nodes: []model.StreamNodeStats{}}
+	if s.redisClient == nil {
+		resp.TotalConnections = local.Connections
+		resp.Nodes = append(resp.Nodes, model.StreamNodeStats{Node: s.node, Connections: local.Connections, UpdatedAt: time.Now()})
+		return resp, nil
+	}
+
+	values, err := s.redisClient.HGetAll(ctx, streamNodesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stream node stats: %w", err)
+	}
+
+	cutoff := time.Now().Add(-streamNodeTTL)
+	for node, value := range values {
+		var stats model.StreamNodeStats
+		if err := json.Unmarshal([]byte(value), &stats); err != nil || stats.UpdatedAt.Before(cutoff) {
+			// 实例异常退出时不会清理自己的记录，由读取方清理
+			if err := s.redisClient.HDel(ctx, streamNodesKey, node); err != nil {
+				s.logger.Warnf("Failed to remove stale stream node %s: %v", node, err)
+			}
+			continue
+		}
+		resp.Nodes = append(resp.Nodes, stats)
+		resp.TotalConnections += stats.Connections
+	}
+	sort.Slice(resp.Nodes, func(i, j int) bool { return resp.Nodes[i].Node < resp.Nodes[j].Node })
+	return resp, nil
+}
+
+// Metrics 本实例的推送统计
+func (s *streamService) Metrics() StreamMetrics {
+	s.mu.RLock()
+	connections := len(s.subscribers)
+	s.mu.RUnlock()
+
+	return StreamMetrics{
+		Node:        s.node,
+		Connections: connections,
+		Published:   s.published.Load(),
+		Delivered:   s.delivered.Load(),
+		Dropped:     s.dropped.Load(),
+	}
+}
+
+// broadcast 推送给匹配的本地订阅者，发送缓冲已满时丢弃该事件，不阻塞其他连接
+func (s *streamService) broadcast(event *model.StreamEvent) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for sub := range s.subscribers {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+			s.delivered.Add(1)
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// remember 未配置Redis时在内存中保留最近事件
+func (s *streamService) remember(event *model.StreamEvent) {
+	size := s.config.Stream.ReplaySize
+	if size <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recent = append(s.recent, event)
+	if len(s.recent) > size {
+		s.recent = append(s.recent[:0], s.recent[len(s.recent)-size:]...)
+	}
+}
+
+// remove 移除订阅者并关闭其事件通道
+func (s *streamService) remove(sub *StreamSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// heartbeat 定期上报本实例连接数
+func (s *streamService) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(streamHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		stats := model.StreamNodeStats{Node: s.node, Connections: s.Metrics().Connections, UpdatedAt: time.Now()}
+		if data, err := json.Marshal(stats); err == nil {
+			if err := s.redisClient.HSet(ctx, streamNodesKey, s.node, string(data)); err != nil && ctx.Err() == nil {
+				s.logger.Warnf("Failed to report stream node stats: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// channel Redis pub/sub频道，添加缓存key前缀以隔离不同环境
+func (s *streamService) channel() string {
+	channel := s.config.Stream.Channel
+	if channel == "" {
+		channel = defaultStreamChannel
+	}
+	return s.redisClient.KeyPrefix() + channel
+}
+
+// subscriptionTTL 命名订阅未被使用时的保留时间
+func (s *streamService) subscriptionTTL() time.Duration {
+	if s.config.Stream.SubscriptionTTL > 0 {
+		return s.config.Stream.SubscriptionTTL
+	}
+	return defaultStreamSubscriptionTTL
+}
+
+// bufferSize 单个连接的发送缓冲
+func (s *streamService) bufferSize() int {
+	if s.config.Stream.BufferSize > 0 {
+		return s.config.Stream.BufferSize
+	}
+	return defaultStreamBufferSize
+}
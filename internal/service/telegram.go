@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/logger"
+)
+
+// Telegram推送默认配置
+const (
+	defaultTelegramAPIURL  = "https://api.telegram.org"
+	defaultTelegramTimeout = 10 * time.Second
+	telegramTemplateName   = "default" // 未按类型配置模板时使用的模板名
+	maxTelegramMessage     = 4096      // Bot API单条消息的字符上限
+	maxTelegramResponse    = 4096
+)
+
+// defaultTelegramTemplate 未配置default模板时使用，纯文本格式
+const defaultTelegramTemplate = `[{{.Severity}}] {{.Title}}
+{{.Message}}{{if .Subject}}
+{{.Subject}}{{end}}`
+
+// telegramChannel 通过Telegram Bot API推送告警到配置的聊天
+type telegramChannel struct {
+	config    *config.Telegram
+	client    *http.Client
+	logger    logger.Logger
+	templates map[string]*template.Template
+}
+
+// newTelegramChannel 创建Telegram推送渠道，未启用时返回nil；模板已在加载配置时校验
+func newTelegramChannel(cfg *config.Config) *telegramChannel {
+	telegram := &cfg.Notifier.Telegram
+	if !telegram.Enabled {
+		return nil
+	}
+
+	timeout := telegram.Timeout
+	if timeout <= 0 {
+		timeout = defaultTelegramTimeout
+	}
+	t := &telegramChannel{
+		config:    telegram,
+		client:    httpclient.New(httpclient.Options{Timeout: timeout}),
+		logger:    logger.GetLogger(),
+		templates: make(map[string]*template.Template, len(telegram.Templates)+1),
+	}
+	t.templates[telegramTemplateName] = template.Must(template.New(telegramTemplateName).Parse(defaultTelegramTemplate))
+	for name, text := range telegram.Templates {
+		tmpl, err := template.New(name).Parse(text)
+		if err != nil {
+			t.logger.Errorf("Invalid telegram template %s: %v", name, err)
+			continue
+		}
+		t.templates[name] = tmpl
+	}
+	return t
+}
+
+// accepts 是否推送该类型的告警
+func (t *telegramChannel) accepts(alert *model.Alert) bool {
+	return acceptsAlertType(t.config.Types, alert)
+}
+
+// send 渲染模板并发送消息，失败只记录在投递结果中
+func (t *telegramChannel) send(ctx context.Context, alert *model.Alert) []model.AlertDelivery {
+	delivery := model.AlertDelivery{Channel: model.AlertChannelTelegram, Target: t.config.ChatID}
+	start := time.Now()
+	err := t.sendMessage(ctx, alert)
+	delivery.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		t.logger.Warnf("Telegram delivery of alert %s failed: %v", alert.ID, err)
+		delivery.Error = err.Error()
+	} else {
+		delivery.Success = true
+	}
+	return []model.AlertDelivery{delivery}
+}
+
+// sendMessage 调用sendMessage接口，错误中不包含带机器人令牌的请求地址
+func (t *telegramChannel) sendMessage(ctx context.Context, alert *model.Alert) error {
+	text, err := t.render(alert)
+	if err != nil {
+		return err
+	}
+	payload := map[string]interface{}{
+		"chat_id":                  t.config.ChatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}
+	if t.config.ParseMode != "" {
+		payload["parse_mode"] = t.config.ParseMode
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	apiURL := t.config.APIURL
+	if apiURL == "" {
+		apiURL = defaultTelegramAPIURL
+	}
+	endpoint := strings.TrimRight(apiURL, "/") + "/bot" + t.config.BotToken + "/sendMessage"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid telegram api url")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxTelegramResponse))
+	if err := json.Unmarshal(data, &result); err != nil || !result.OK {
+		if result.Description != "" {
+			return fmt.Errorf("telegram api status %d: %s", resp.StatusCode, result.Description)
+		}
+		return fmt.Errorf("telegram api status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// render 按告警类型选择模板，超出长度上限时截断
+func (t *telegramChannel) render(alert *model.Alert) (string, error) {
+	tmpl, ok := t.templates[alert.Type]
+	if !ok {
+		tmpl = t.templates[telegramTemplateName]
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alert); err != nil {
+		return "", fmt.Errorf("failed to render telegram template %s: %w", tmpl.Name(), err)
+	}
+
+	text := []rune(buf.String())
+	if len(text) > maxTelegramMessage {
+		text = text[:maxTelegramMessage]
+	}
+	return string(text), nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/bscscan"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 合约验证状态
+const (
+	VerificationVerified   = "verified"
+	VerificationUnverified = "unverified"
+	VerificationUnknown    = "unknown"
+)
+
+// 代币风险提示
+const (
+	RiskNotContract             = "not_contract"
+	RiskUnverifiedSource        = "unverified_source"
+	RiskProxyContract           = "proxy_contract"
+	RiskNotInTrustedLists       = "not_in_trusted_lists"
+	RiskFlaggedLabel            = "flagged_label"
+	RiskVerificationUnavailable = "verification_unavailable"
+)
+
+// 合约验证结果缓存时间，已验证合约很少变化，未验证合约可能随时提交验证
+const (
+	verifiedSourceCacheTTL   = 24 * time.Hour
+	unverifiedSourceCacheTTL = time.Hour
+)
+
+// flaggedLabelCategories 视为风险的地址标签分类
+var flaggedLabelCategories = map[string]bool{
+	"scam":     true,
+	"phishing": true,
+	"exploit":  true,
+	"hack":     true,
+	"rugpull":  true,
+}
+
+// TokenSafetyService 代币安全检查服务接口
+type TokenSafetyService interface {
+	GetTokenSafety(ctx context.Context, address string) (*model.TokenSafetyReport, error)
+}
+
+// tokenSafetyService 代币安全检查服务实现
+type tokenSafetyService struct {
+	redisClient  database.RedisClient
+	config       *config.Config
+	tokenService TokenService
+	bscService   BSCService
+	bscscan      *bscscan.Client
+}
+
+// NewTokenSafetyService 创建代币安全检查服务
+func NewTokenSafetyService(redisClient database.RedisClient, cfg *config.Config, tokenService TokenService, bscService BSCService) TokenSafetyService {
+	return &tokenSafetyService{
+		redisClient:  redisClient,
+		config:       cfg,
+		tokenService: tokenService,
+		bscService:   bscService,
+		bscscan:      bscscan.NewClient(&cfg.ExternalAPI.BscScan),
+	}
+}
+
+// GetTokenSafety 汇总合约部署、源码验证、代币列表和地址标签信息生成安全报告
+func (s *tokenSafetyService) GetTokenSafety(ctx context.Context, address string) (*model.TokenSafetyReport, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidParameter, address)
+	}
+	addr := common.HexToAddress(address)
+
+	report := &model.TokenSafetyReport{
+		Address:   addr.Hex(),
+		Risks:     make([]string, 0),
+		CheckedAt: time.Now(),
+	}
+
+	if s.bscService != nil {
+		if isContract, err := s.bscService.IsContract(ctx, addr); err == nil {
+			report.IsContract = &isContract
+			if !isContract {
+				report.Risks = append(report.Risks, RiskNotContract)
+			}
+		} else {
+			logger.From(ctx).Warnf("Failed to check contract code for %s: %v", addr.Hex(), err)
+		}
+	}
+
+	if token, err := s.tokenService.GetToken(ctx, addr.Hex()); err == nil {
+		report.Token = token
+	} else {
+		report.Risks = append(report.Risks, RiskNotInTrustedLists)
+	}
+
+	if label, err := s.tokenService.GetLabel(ctx, addr.Hex()); err == nil {
+		report.Label = label
+		if flaggedLabelCategories[label.Category] {
+			report.Risks = append(report.Risks, RiskFlaggedLabel)
+		}
+	}
+
+	report.Verification = s.getVerification(ctx, addr.Hex())
+	switch {
+	case report.Verification.Status == VerificationUnknown:
+		report.Risks = append(report.Risks, RiskVerificationUnavailable)
+	case report.Verification.Status == VerificationUnverified && (report.IsContract == nil || *report.IsContract):
+		report.Risks = append(report.Risks, RiskUnverifiedSource)
+	}
+	if report.Verification.Proxy {
+		report.Risks = append(report.Risks, RiskProxyContract)
+	}
+
+	return report, nil
+}
+
+// getVerification 获取合约源码验证信息，优先使用缓存，限流时返回unknown
+func (s *tokenSafetyService) getVerification(ctx context.Context, address string) *model.ContractVerification {
+	cacheKey := "bscscan:source:" + strings.ToLower(address)
+	if s.redisClient != nil {
+		if cached, err := s.redisClient.Get(ctx, cacheKey); err == nil && cached != "" {
+			var verification model.ContractVerification
+			if err := json.Unmarshal([]byte(cached), &verification); err == nil {
+				return &verification
+			}
+		}
+	}
+
+	source, err := s.bscscan.GetSourceCode(ctx, address)
+	if err != nil {
+		if !errors.Is(err, bscscan.ErrRateLimited) {
+			logger.From(ctx).Warnf("Failed to get contract source for %s: %v", address, err)
+		}
+		return &model.ContractVerification{
+			Status:    VerificationUnknown,
+			Source:    "bscscan",
+			CheckedAt: time.Now(),
+			Error:     err.Error(),
+		}
+	}
+
+	verification := &model.ContractVerification{
+		Status:    VerificationUnverified,
+		Source:    "bscscan",
+		CheckedAt: time.Now(),
+	}
+	ttl := unverifiedSourceCacheTTL
+	if source.Verified() {
+		verification.Status = VerificationVerified
+		verification.ContractName = source.ContractName
+		verification.CompilerVersion = source.CompilerVersion
+		verification.LicenseType = source.LicenseType
+		verification.OptimizationUsed = source.OptimizationUsed == "1"
+		verification.Runs, _ = strconv.Atoi(source.Runs)
+		verification.Proxy = source.IsProxy()
+		verification.Implementation = source.Implementation
+		ttl = verifiedSourceCacheTTL
+	}
+
+	if s.redisClient != nil {
+		if data, err := json.Marshal(verification); err == nil {
+			if err := s.redisClient.Set(ctx, cacheKey, data, ttl); err != nil {
+				logger.From(ctx).Warnf("Failed to cache contract source for %s: %v", address, err)
+			}
+		}
+	}
+
+	return verification
+}
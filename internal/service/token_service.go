@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 代币注册表存储key
+const (
+	tokenRegistryKey = "tokens:registry"
+	addressLabelsKey = "tokens:labels"
+)
+
+// maxTokenDecimals 代币精度上限
+const maxTokenDecimals = 36
+
+// TokenService 代币元数据与地址标签服务接口
+type TokenService interface {
+	UpsertTokens(ctx context.Context, tokens []model.TokenMetadata) (int, error)
+	GetToken(ctx context.Context, address string) (*model.TokenMetadata, error)
+	ListTokens(ctx context.Context, symbol string) ([]model.TokenMetadata, error)
+	UpsertLabels(ctx context.Context, labels []model.AddressLabel) (int, error)
+	GetLabel(ctx context.Context, address string) (*model.AddressLabel, error)
+}
+
+// tokenService 代币服务实现，数据保存在Redis哈希中
+type tokenService struct {
+	redisClient database.RedisClient
+	config      *config.Config
+}
+
+// NewTokenService 创建代币服务
+func NewTokenService(redisClient database.RedisClient, cfg *config.Config) TokenService {
+	return &tokenService{
+		redisClient: redisClient,
+		config:      cfg,
+	}
+}
+
+// UpsertTokens 写入代币元数据，已存在的代币合并数据来源
+func (s *tokenService) UpsertTokens(ctx context.Context, tokens []model.TokenMetadata) (int, error) {
+	if s.redisClient == nil {
+		return 0, fmt.Errorf("%w: token registry is not configured", ErrUpstreamUnavailable)
+	}
+
+	saved := 0
+	for _, token := range tokens {
+		if err := ctx.Err(); err != nil {
+			return saved, err
+		}
+
+		normalized, err := ValidateToken(token)
+		if err != nil {
+			return saved, err
+		}
+
+		if existing, err := s.GetToken(ctx, normalized.Address); err == nil {
+			normalized.Sources = mergeStrings(existing.Sources, normalized.Sources)
+			normalized.Tags = mergeStrings(existing.Tags, normalized.Tags)
+			if normalized.LogoURI == "" {
+				normalized.LogoURI = existing.LogoURI
+			}
+			normalized.OnChain = normalized.OnChain || existing.OnChain
+		}
+		normalized.UpdatedAt = time.Now()
+
+		data, err := json.Marshal(normalized)
+		if err != nil {
+			return saved, err
+		}
+		if err := s.redisClient.HSet(ctx, tokenRegistryKey, strings.ToLower(normalized.Address), string(data)); err != nil {
+			return saved, fmt.Errorf("failed to save token %s: %w", normalized.Address, err)
+		}
+		saved++
+	}
+
+	return saved, nil
+}
+
+// GetToken 根据合约地址获取代币元数据
+func (s *tokenService) GetToken(ctx context.Context, address string) (*model.TokenMetadata, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidParameter, address)
+	}
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: token %s", ErrNotFound, address)
+	}
+
+	data, err := s.redisClient.HGet(ctx, tokenRegistryKey, strings.ToLower(common.HexToAddress(address).Hex()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token %s: %w", address, err)
+	}
+	if data == "" {
+		return nil, fmt.Errorf("%w: token %s", ErrNotFound, address)
+	}
+
+	var token model.TokenMetadata
+	if err := json.Unmarshal([]byte(data), &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListTokens 获取代币列表，symbol不为空时按符号过滤
+func (s *tokenService) ListTokens(ctx context.Context, symbol string) ([]model.TokenMetadata, error) {
+	tokens := make([]model.TokenMetadata, 0)
+	if s.redisClient == nil {
+		return tokens, nil
+	}
+
+	entries, err := s.redisClient.HGetAll(ctx, tokenRegistryKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token registry: %w", err)
+	}
+
+	for address, data := range entries {
+		var token model.TokenMetadata
+		if err := json.Unmarshal([]byte(data), &token); err != nil {
+			logger.From(ctx).Warnf("Skipping malformed token entry %s: %v", address, err)
+			continue
+		}
+		if symbol != "" && !strings.EqualFold(token.Symbol, symbol) {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].Symbol == tokens[j].Symbol {
+			return tokens[i].Address < tokens[j].Address
+		}
+		return tokens[i].Symbol < tokens[j].Symbol
+	})
+
+	return tokens, nil
+}
+
+// UpsertLabels 写入地址标签
+func (s *tokenService) UpsertLabels(ctx context.Context, labels []model.AddressLabel) (int, error) {
+	if s.redisClient == nil {
+		return 0, fmt.Errorf("%w: label registry is not configured", ErrUpstreamUnavailable)
+	}
+
+	saved := 0
+	for _, label := range labels {
+		if err := ctx.Err(); err != nil {
+			return saved, err
+		}
+
+		normalized, err := ValidateLabel(label)
+		if err != nil {
+			return saved, err
+		}
+		normalized.UpdatedAt = time.Now()
+
+		data, err := json.Marshal(normalized)
+		if err != nil {
+			return saved, err
+		}
+		if err := s.redisClient.HSet(ctx, addressLabelsKey, strings.ToLower(normalized.Address), string(data)); err != nil {
+			return saved, fmt.Errorf("failed to save label %s: %w", normalized.Address, err)
+		}
+		saved++
+	}
+
+	return saved, nil
+}
+
+// GetLabel 获取地址标签
+func (s *tokenService) GetLabel(ctx context.Context, address string) (*model.AddressLabel, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidParameter, address)
+	}
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: label %s", ErrNotFound, address)
+	}
+
+	data, err := s.redisClient.HGet(ctx, addressLabelsKey, strings.ToLower(common.HexToAddress(address).Hex()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load label %s: %w", address, err)
+	}
+	if data == "" {
+		return nil, fmt.Errorf("%w: label %s", ErrNotFound, address)
+	}
+
+	var label model.AddressLabel
+	if err := json.Unmarshal([]byte(data), &label); err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// ValidateToken 校验并标准化代币元数据
+func ValidateToken(token model.TokenMetadata) (model.TokenMetadata, error) {
+	if !common.IsHexAddress(token.Address) {
+		return token, fmt.Errorf("%w: invalid token address %q", ErrInvalidParameter, token.Address)
+	}
+	token.Address = common.HexToAddress(token.Address).Hex()
+	token.Symbol = strings.TrimSpace(token.Symbol)
+	token.Name = strings.TrimSpace(token.Name)
+	if token.Symbol == "" {
+		return token, fmt.Errorf("%w: token %s has empty symbol", ErrInvalidParameter, token.Address)
+	}
+	if token.Decimals > maxTokenDecimals {
+		return token, fmt.Errorf("%w: token %s has invalid decimals %d", ErrInvalidParameter, token.Address, token.Decimals)
+	}
+	return token, nil
+}
+
+// ValidateLabel 校验并标准化地址标签
+func ValidateLabel(label model.AddressLabel) (model.AddressLabel, error) {
+	if !common.IsHexAddress(label.Address) {
+		return label, fmt.Errorf("%w: invalid address %q", ErrInvalidParameter, label.Address)
+	}
+	label.Address = common.HexToAddress(label.Address).Hex()
+	label.Label = strings.TrimSpace(label.Label)
+	label.Category = strings.ToLower(strings.TrimSpace(label.Category))
+	if label.Label == "" {
+		return label, fmt.Errorf("%w: address %s has empty label", ErrInvalidParameter, label.Address)
+	}
+	return label, nil
+}
+
+// mergeStrings 合并去重字符串列表，保持原有顺序
+func mergeStrings(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, v := range list {
+			if v != "" && !seen[v] {
+				seen[v] = true
+				merged = append(merged, v)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/budget"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 代币列表同步默认配置
+const (
+	defaultTokenSyncInterval    = 6 * time.Hour
+	defaultTokenSyncConcurrency = 8
+	tokenSyncStatusKey          = "tokens:sync"
+)
+
+// TokenSyncService 代币列表同步服务接口
+type TokenSyncService interface {
+	Start(ctx context.Context) error
+	Stop() error
+	SyncNow(ctx context.Context) ([]model.TokenSyncStatus, error)
+	GetStatus(ctx context.Context) ([]model.TokenSyncStatus, error)
+}
+
+// tokenSyncService 定期从可信来源同步BEP20代币列表，校验合约后写入代币注册表
+type tokenSyncService struct {
+	redisClient  database.RedisClient
+	config       *config.Config
+	tokenService TokenService
+	bscService   BSCService
+	httpClient   *http.Client
+	logger       logger.Logger
+
+	runMutex  sync.Mutex
+	syncMutex sync.Mutex
+	running   bool
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewTokenSyncService 创建代币列表同步服务
+func NewTokenSyncService(redisClient database.RedisClient, cfg *config.Config, tokenService TokenService, bscService BSCService) TokenSyncService {
+	return &tokenSyncService{
+		redisClient:  redisClient,
+		config:       cfg,
+		tokenService: tokenService,
+		bscService:   bscService,
+		httpClient:   httpclient.New(httpclient.Options{Timeout: cfg.BSC.TokenSync.Timeout, Provider: budget.ProviderTokenSync}),
+		logger:       logger.GetLogger(),
+	}
+}
+
+// Start 启动定时同步
+func (s *tokenSyncService) Start(ctx context.Context) error {
+	if !s.config.BSC.TokenSync.Enabled || len(s.config.BSC.TokenSync.Sources) == 0 {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("token sync is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "token_sync", s.run, s.done)
+
+	s.logger.Infof("Token list sync started with %d sources", len(s.config.BSC.TokenSync.Sources))
+	return nil
+}
+
+// Stop 停止定时同步
+func (s *tokenSyncService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	s.logger.Info("Token list sync stopped")
+	return nil
+}
+
+// run 启动时立即同步一次，之后按间隔同步
+func (s *tokenSyncService) run(ctx context.Context) {
+	interval := s.config.BSC.TokenSync.Interval
+	if interval <= 0 {
+		interval = defaultTokenSyncInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SyncNow(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("Token list sync failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncNow 立即同步所有来源
+func (s *tokenSyncService) SyncNow(ctx context.Context) ([]model.TokenSyncStatus, error) {
+	s.syncMutex.Lock()
+	defer s.syncMutex.Unlock()
+
+	statuses := make([]model.TokenSyncStatus, 0, len(s.config.BSC.TokenSync.Sources))
+	for _, source := range s.config.BSC.TokenSync.Sources {
+		if err := ctx.Err(); err != nil {
+			return statuses, err
+		}
+
+		status := s.syncSource(ctx, source)
+		statuses = append(statuses, status)
+		s.saveStatus(ctx, status)
+	}
+	return statuses, nil
+}
+
+// syncSource 同步单个代币列表
+func (s *tokenSyncService) syncSource(ctx context.Context, source config.TokenListSource) model.TokenSyncStatus {
+	status := model.TokenSyncStatus{
+		Source:     source.Name,
+		URL:        source.URL,
+		LastSyncAt: time.Now(),
+	}
+	defer func() {
+		status.DurationMs = time.Since(status.LastSyncAt).Milliseconds()
+	}()
+
+	var raw json.RawMessage
+	if err := httpclient.GetJSON(ctx, s.httpClient, source.URL, &raw); err != nil {
+		status.Error = err.Error()
+		s.logger.Warnf("Failed to download token list %s: %v", source.Name, err)
+		return status
+	}
+	list, err := decodeTokenList(bytes.NewReader(raw))
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	candidates := make([]model.TokenMetadata, 0, len(list))
+	for _, token := range list {
+		if token.ChainID != 0 && token.ChainID != s.config.BSC.ChainID {
+			continue
+		}
+		token.ChainID = s.config.BSC.ChainID
+		token.Sources = []string{"list:" + source.Name}
+		normalized, err := ValidateToken(token)
+		if err != nil {
+			status.Rejected++
+			continue
+		}
+		candidates = append(candidates, normalized)
+	}
+	status.Tokens = len(candidates) + status.Rejected
+
+	verified := s.verifyOnChain(ctx, candidates, &status)
+
+	saved, err := s.tokenService.UpsertTokens(ctx, verified)
+	status.Saved = saved
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	s.logger.Infof("Synced token list %s: tokens=%d saved=%d rejected=%d", source.Name, status.Tokens, status.Saved, status.Rejected)
+	return status
+}
+
+// verifyOnChain 在链上确认代币合约存在，已确认过的代币不再重复查询
+func (s *tokenSyncService) verifyOnChain(ctx context.Context, tokens []model.TokenMetadata, status *model.TokenSyncStatus) []model.TokenMetadata {
+	if !s.config.BSC.TokenSync.VerifyOnChain || s.bscService == nil {
+		return tokens
+	}
+
+	pending := make([]string, 0, len(tokens))
+	byAddress := make(map[string]model.TokenMetadata, len(tokens))
+	verified := make([]model.TokenMetadata, 0, len(tokens))
+	for _, token := range tokens {
+		if existing, err := s.tokenService.GetToken(ctx, token.Address); err == nil && existing.OnChain {
+			token.OnChain = true
+			verified = append(verified, token)
+			continue
+		}
+		byAddress[token.Address] = token
+		pending = append(pending, token.Address)
+	}
+
+	concurrency := s.config.BSC.TokenSync.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultTokenSyncConcurrency
+	}
+	results := fanOut(ctx, pending, concurrency, func(ctx context.Context, address string) (bool, error) {
+		return s.bscService.IsContract(ctx, common.HexToAddress(address))
+	})
+
+	for _, result := range results {
+		token := byAddress[result.Key]
+		switch {
+		case result.Err != nil:
+			// 链上查询失败时保留代币但不标记为已确认，下次同步重试
+			verified = append(verified, token)
+		case result.Value:
+			token.OnChain = true
+			verified = append(verified, token)
+		default:
+			status.Rejected++
+		}
+	}
+	return verified
+}
+
+// saveStatus 保存同步状态
+func (s *tokenSyncService) saveStatus(ctx context.Context, status model.TokenSyncStatus) {
+	if s.redisClient == nil {
+		return
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+	if err := s.redisClient.HSet(ctx, tokenSyncStatusKey, status.Source, string(data)); err != nil {
+		s.logger.Warnf("Failed to save token sync status for %s: %v", status.Source, err)
+	}
+}
+
+// GetStatus 获取各来源的最近同步状态
+func (s *tokenSyncService) GetStatus(ctx context.Context) ([]model.TokenSyncStatus, error) {
+	statuses := make([]model.TokenSyncStatus, 0, len(s.config.BSC.TokenSync.Sources))
+	if s.redisClient == nil {
+		return statuses, nil
+	}
+
+	for _, source := range s.config.BSC.TokenSync.Sources {
+		data, err := s.redisClient.HGet(ctx, tokenSyncStatusKey, source.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load token sync status: %w", err)
+		}
+		status := model.TokenSyncStatus{Source: source.Name, URL: source.URL}
+		if data != "" {
+			_ = json.Unmarshal([]byte(data), &status)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
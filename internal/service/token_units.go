@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+// unitConverter 按代币精度把链上最小单位的数量换算为可读数量
+// 精度先查代币注册表，未收录时读取合约的decimals()；代币精度不会变化，查到后在进程内缓存
+type unitConverter struct {
+	tokenService TokenService
+	reader       *contractReader
+	decimals     sync.Map // common.Address -> uint8
+}
+
+// newUnitConverter 创建数量换算器，tokenService和bscService均可为nil
+func newUnitConverter(tokenService TokenService, bscService BSCService) *unitConverter {
+	return &unitConverter{
+		tokenService: tokenService,
+		reader:       newContractReader(bscService),
+	}
+}
+
+// format 按代币精度换算数量，精度未知或数量为空时返回空字符串
+func (c *unitConverter) format(ctx context.Context, token common.Address, raw *big.Int) (string, *uint8) {
+	if raw == nil {
+		return "", nil
+	}
+	decimals, ok := c.lookup(ctx, token)
+	if !ok {
+		return "", nil
+	}
+	return formatUnits(raw, decimals), &decimals
+}
+
+// lookup 查询代币精度
+func (c *unitConverter) lookup(ctx context.Context, token common.Address) (uint8, bool) {
+	if value, ok := c.decimals.Load(token); ok {
+		return value.(uint8), true
+	}
+
+	if c.tokenService != nil {
+		metadata, err := c.tokenService.GetToken(ctx, token.Hex())
+		if err == nil {
+			c.decimals.Store(token, metadata.Decimals)
+			return metadata.Decimals, true
+		}
+		if !errors.Is(err, ErrNotFound) {
+			logger.From(ctx).Debugf("Failed to resolve token %s: %v", token.Hex(), err)
+		}
+	}
+
+	info, err := c.reader.readToken(ctx, token)
+	if err != nil {
+		logger.From(ctx).Debugf("Failed to read decimals of token %s: %v", token.Hex(), err)
+		return 0, false
+	}
+	c.decimals.Store(token, info.decimals)
+	return info.decimals, true
+}
+
+// formatUnits 将最小单位数量按精度换算为十进制字符串，数量为空时返回空字符串
+func formatUnits(raw *big.Int, decimals uint8) string {
+	if raw == nil {
+		return ""
+	}
+	return decimal.NewFromBigInt(raw, -int32(decimals)).String()
+}
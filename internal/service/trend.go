@@ -0,0 +1,199 @@
+package service
+
+import (
+	"math"
+
+	"crypto-info/internal/model"
+)
+
+// 趋势强度分类
+const (
+	TrendStrengthStrongUp   = "strong_up"
+	TrendStrengthWeakUp     = "weak_up"
+	TrendStrengthFlat       = "flat"
+	TrendStrengthWeakDown   = "weak_down"
+	TrendStrengthStrongDown = "strong_down"
+)
+
+const (
+	// trendMinConfidence 低于该置信度视为无明显趋势
+	trendMinConfidence = 0.80
+	// trendStrongConfidence 强趋势所需的最低置信度
+	trendStrongConfidence = 0.95
+	// trendMinRelativeChange 窗口内拟合变化量占均值的最小比例
+	trendMinRelativeChange = 0.02
+	// trendStrongRelativeChange 强趋势所需的拟合变化量占比
+	trendStrongRelativeChange = 0.10
+)
+
+// trendResult 趋势分析结果
+type trendResult struct {
+	Label      string  // 上升/下降/稳定
+	Slope      float64 // 每个周期的交易量变化（线性回归斜率）
+	Confidence float64 // 斜率显著性置信度 (1 - 双侧p值)
+	Strength   string  // 趋势强度分类
+}
+
+// analyzeTrend 对交易量序列做最小二乘线性回归，
+// 根据斜率的t统计量计算置信度并给出趋势强度分类
+func analyzeTrend(data []model.VolumeData) trendResult {
+	result := trendResult{Label: "稳定", Strength: TrendStrengthFlat}
+
+	n := len(data)
+	if n < 3 {
+		return result
+	}
+
+	var sumX, sumY float64
+	for i, d := range data {
+		sumX += float64(i)
+		sumY += d.Volume
+	}
+	meanX := sumX / float64(n)
+	meanY := sumY / float64(n)
+
+	var sxx, sxy float64
+	for i, d := range data {
+		dx := float64(i) - meanX
+		sxx += dx * dx
+		sxy += dx * (d.Volume - meanY)
+	}
+	if sxx == 0 {
+		return result
+	}
+
+	slope := sxy / sxx
+	intercept := meanY - slope*meanX
+
+	// 残差平方和
+	var sse float64
+	for i, d := range data {
+		residual := d.Volume - (intercept + slope*float64(i))
+		sse += residual * residual
+	}
+
+	df := float64(n - 2)
+	confidence := 1.0
+	if sse > 0 {
+		stdErr := math.Sqrt(sse / df / sxx)
+		t := slope / stdErr
+		confidence = 1 - studentTTwoTailedP(t, df)
+	} else if slope == 0 {
+		confidence = 0
+	}
+
+	result.Slope = slope
+	result.Confidence = confidence
+
+	var relativeChange float64
+	if meanY != 0 {
+		relativeChange = math.Abs(slope*float64(n-1)) / math.Abs(meanY)
+	}
+
+	if confidence < trendMinConfidence || relativeChange < trendMinRelativeChange {
+		return result
+	}
+
+	strong := confidence >= trendStrongConfidence && relativeChange >= trendStrongRelativeChange
+	if slope > 0 {
+		result.Label = "上升"
+		result.Strength = TrendStrengthWeakUp
+		if strong {
+			result.Strength = TrendStrengthStrongUp
+		}
+	} else {
+		result.Label = "下降"
+		result.Strength = TrendStrengthWeakDown
+		if strong {
+			result.Strength = TrendStrengthStrongDown
+		}
+	}
+
+	return result
+}
+
+// studentTTwoTailedP 计算自由度为df的t分布双侧p值
+func studentTTwoTailedP(t, df float64) float64 {
+	if math.IsInf(t, 0) {
+		return 0
+	}
+	x := df / (df + t*t)
+	return regularizedIncompleteBeta(df/2, 0.5, x)
+}
+
+// regularizedIncompleteBeta 正则化不完全Beta函数 I_x(a, b)
+func regularizedIncompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+
+	// 利用对称性保证连分式收敛
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+	return 1 - front*betaContinuedFraction(b, a, 1-x)/b
+}
+
+// betaContinuedFraction 不完全Beta函数的连分式展开 (Lentz算法)
+func betaContinuedFraction(a, b, x float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 1e-12
+		tiny          = 1e-300
+	)
+
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+
+	return h
+}
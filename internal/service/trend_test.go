@@ -0,0 +1,137 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"crypto-info/internal/model"
+)
+
+// volumeSeries 按顺序生成交易量序列
+func volumeSeries(volumes ...float64) []model.VolumeData {
+	data := make([]model.VolumeData, len(volumes))
+	for i, v := range volumes {
+		data[i] = model.VolumeData{Volume: v}
+	}
+	return data
+}
+
+// linearSeries 生成 start + step*i 的交易量序列
+func linearSeries(n int, start, step float64) []model.VolumeData {
+	volumes := make([]float64, n)
+	for i := range volumes {
+		volumes[i] = start + step*float64(i)
+	}
+	return volumeSeries(volumes...)
+}
+
+// TestAnalyzeTrend 已知斜率、样本不足、零残差和常数序列的趋势判断
+func TestAnalyzeTrend(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           []model.VolumeData
+		wantLabel      string
+		wantStrength   string
+		wantSlope      float64
+		wantConfidence float64
+	}{
+		{
+			name:         "empty",
+			data:         nil,
+			wantLabel:    "稳定",
+			wantStrength: TrendStrengthFlat,
+		},
+		{
+			name:         "fewer than 3 points",
+			data:         volumeSeries(100, 1000),
+			wantLabel:    "稳定",
+			wantStrength: TrendStrengthFlat,
+		},
+		{
+			name:           "constant series",
+			data:           volumeSeries(500, 500, 500, 500, 500),
+			wantLabel:      "稳定",
+			wantStrength:   TrendStrengthFlat,
+			wantSlope:      0,
+			wantConfidence: 0,
+		},
+		{
+			name:           "exact line up has zero residual variance",
+			data:           linearSeries(10, 100, 10),
+			wantLabel:      "上升",
+			wantStrength:   TrendStrengthStrongUp,
+			wantSlope:      10,
+			wantConfidence: 1,
+		},
+		{
+			name:           "exact line down",
+			data:           linearSeries(5, 1000, -50),
+			wantLabel:      "下降",
+			wantStrength:   TrendStrengthStrongDown,
+			wantSlope:      -50,
+			wantConfidence: 1,
+		},
+		{
+			// 1000+5i加上与i正交的±1噪声，斜率显著但拟合变化量只占均值的约3.4%
+			name:           "small significant rise is weak",
+			data:           volumeSeries(1001, 1004, 1009, 1016, 1021, 1024, 1029, 1036),
+			wantLabel:      "上升",
+			wantStrength:   TrendStrengthWeakUp,
+			wantSlope:      5,
+			wantConfidence: 1,
+		},
+		{
+			// 斜率200/21，t≈0.548、自由度6，双侧p值≈0.604，置信度低于trendMinConfidence
+			name:           "noisy series without trend",
+			data:           volumeSeries(100, 300, 100, 300, 100, 300, 100, 300),
+			wantLabel:      "稳定",
+			wantStrength:   TrendStrengthFlat,
+			wantSlope:      200.0 / 21,
+			wantConfidence: 0.396,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := analyzeTrend(tt.data)
+			if got.Label != tt.wantLabel || got.Strength != tt.wantStrength {
+				t.Errorf("trend = %s/%s, want %s/%s", got.Label, got.Strength, tt.wantLabel, tt.wantStrength)
+			}
+			if math.Abs(got.Slope-tt.wantSlope) > 1e-9 {
+				t.Errorf("slope = %v, want %v", got.Slope, tt.wantSlope)
+			}
+			if math.Abs(got.Confidence-tt.wantConfidence) > 0.01 {
+				t.Errorf("confidence = %v, want about %v", got.Confidence, tt.wantConfidence)
+			}
+		})
+	}
+}
+
+// TestStudentTTwoTailedP 与t分布表和闭式解对照双侧p值
+func TestStudentTTwoTailedP(t *testing.T) {
+	tests := []struct {
+		name string
+		t    float64
+		df   float64
+		want float64
+	}{
+		{name: "t=0", t: 0, df: 8, want: 1},
+		{name: "cauchy df=1", t: 1, df: 1, want: 0.5},
+		{name: "closed form df=2", t: 2, df: 2, want: 1 - 2/math.Sqrt(6)},
+		{name: "negative t is symmetric", t: -2, df: 2, want: 1 - 2/math.Sqrt(6)},
+		{name: "critical 0.05 df=10", t: 2.228139, df: 10, want: 0.05},
+		{name: "critical 0.05 df=30", t: 2.042272, df: 30, want: 0.05},
+		{name: "critical 0.01 df=5", t: 4.032143, df: 5, want: 0.01},
+		{name: "critical 0.001 df=20", t: 3.849516, df: 20, want: 0.001},
+		{name: "infinite t", t: math.Inf(1), df: 3, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := studentTTwoTailedP(tt.t, tt.df)
+			if math.Abs(got-tt.want) > 1e-5 {
+				t.Errorf("studentTTwoTailedP(%v, %v) = %v, want %v", tt.t, tt.df, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/panics"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 锁仓价值跟踪默认配置
+const (
+	defaultTVLInterval      = time.Minute
+	defaultTVLRetention     = 7 * 24 * time.Hour
+	defaultTVLDropThreshold = 0.3
+	defaultTVLDropWindow    = time.Hour
+	tvlLatestKey            = "tvl:latest"
+	tvlPairKeyPrefix        = "tvl:pair:"
+	tvlTokenKeyPrefix       = "tvl:token:"
+)
+
+// 锁仓价值历史类型
+const (
+	TVLKindPair  = "pair"
+	TVLKindToken = "token"
+)
+
+// AlertTypeTVLDrop 锁仓价值骤降告警
+const AlertTypeTVLDrop = "tvl_drop"
+
+// TVLService 锁仓价值跟踪服务接口
+type TVLService interface {
+	Start(ctx context.Context) error
+	Stop() error
+	RefreshNow(ctx context.Context) (*model.TVLOverview, error)
+	GetOverview(ctx context.Context) (*model.TVLOverview, error)
+	GetHistory(ctx context.Context, kind, address string, from, to time.Time) (*model.TVLHistoryResponse, error)
+}
+
+// tvlService 定期读取交易对储备计算锁仓价值，保存时间序列并在骤降时告警
+type tvlService struct {
+	redisClient  database.RedisClient
+	config       *config.Config
+	priceService PriceService
+	notifier     Notifier
+	reader       *contractReader
+	logger       logger.Logger
+
+	runMutex     sync.Mutex
+	refreshMutex sync.Mutex
+	running      bool
+	cancel       context.CancelFunc
+	done         chan struct{}
+}
+
+// tvlSample 锁仓价值采样
+type tvlSample struct {
+	Timestamp int64   `json:"t"` // 毫秒时间戳
+	TVL       float64 `json:"v"`
+}
+
+// NewTVLService 创建锁仓价值跟踪服务，notifier可为nil
+func NewTVLService(redisClient database.RedisClient, cfg *config.Config, bscService BSCService, priceService PriceService, notifier Notifier) TVLService {
+	return &tvlService{
+		redisClient:  redisClient,
+		config:       cfg,
+		priceService: priceService,
+		notifier:     notifier,
+		reader:       newContractReader(bscService),
+		logger:       logger.GetLogger(),
+	}
+}
+
+// Start 启动定时刷新
+func (s *tvlService) Start(ctx context.Context) error {
+	if !s.config.BSC.TVL.Enabled || len(s.config.BSC.TVL.Pairs) == 0 {
+		return nil
+	}
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("TVL tracking is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	panics.Supervise(ctx, "tvl", s.run, s.done)
+
+	s.logger.Infof("TVL tracking started with %d pairs", len(s.config.BSC.TVL.Pairs))
+	return nil
+}
+
+// Stop 停止定时刷新
+func (s *tvlService) Stop() error {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	s.running = false
+
+	s.logger.Info("TVL tracking stopped")
+	return nil
+}
+
+// run 启动时立即刷新一次，之后按间隔刷新
+func (s *tvlService) run(ctx context.Context) {
+	interval := s.config.BSC.TVL.Interval
+	if interval <= 0 {
+		interval = defaultTVLInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RefreshNow(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("TVL refresh failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshNow 立即计算所有跟踪交易对的锁仓价值，读取失败的交易对会被跳过
+func (s *tvlService) RefreshNow(ctx context.Context) (*model.TVLOverview, error) {
+	s.refreshMutex.Lock()
+	defer s.refreshMutex.Unlock()
+
+	now := time.Now()
+	var pairs []*pairState
+	var names []string
+	for _, contract := range s.config.BSC.TVL.Pairs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pair, err := s.readPair(ctx, common.HexToAddress(contract.Address))
+		if err != nil {
+			s.logger.Warnf("Failed to read pair %s: %v", contract.Name, err)
+			continue
+		}
+		pairs = append(pairs, pair)
+		names = append(names, contract.Name)
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("%w: no pair could be read", ErrUpstreamUnavailable)
+	}
+
+	prices := s.priceTokens(ctx, pairs)
+	overview := buildTVLOverview(pairs, names, prices, now)
+
+	if s.redisClient != nil {
+		s.store(ctx, overview)
+	}
+	for _, pair := range overview.Pairs {
+		s.checkDrop(ctx, pair)
+	}
+	return overview, nil
+}
+
+// readPair 读取交易对合约
+func (s *tvlService) readPair(ctx context.Context, address common.Address) (*pairState, error) {
+	lpToken, err := s.reader.readToken(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	return s.reader.readPair(ctx, lpToken)
+}
+
+// priceTokens 为基础代币定价，再通过交易对储备推导其余代币价格
+func (s *tvlService) priceTokens(ctx context.Context, pairs []*pairState) map[common.Address]float64 {
+	pricer := newUSDPricer(s.priceService)
+	prices := make(map[common.Address]float64)
+	for _, pair := range pairs {
+		for _, token := range pair.tokens {
+			if _, seen := prices[token.address]; !seen {
+				prices[token.address], _ = pricer.price(ctx, token.symbol)
+			}
+		}
+	}
+	derivePrices(pairs, prices)
+	return prices
+}
+
+// buildTVLOverview 汇总交易对与代币维度的锁仓价值
+func buildTVLOverview(pairs []*pairState, names []string, prices map[common.Address]float64, now time.Time) *model.TVLOverview {
+	overview := &model.TVLOverview{UpdatedAt: now}
+	tokens := make(map[common.Address]*model.TokenTVL)
+
+	for i, pair := range pairs {
+		item := model.PairTVL{
+			Name:      names[i],
+			Address:   strings.ToLower(pair.address.Hex()),
+			Token0:    strings.ToLower(pair.tokens[0].address.Hex()),
+			Token1:    strings.ToLower(pair.tokens[1].address.Hex()),
+			Symbol0:   pair.tokens[0].symbol,
+			Symbol1:   pair.tokens[1].symbol,
+			Reserve0:  pair.reserves[0],
+			Reserve1:  pair.reserves[1],
+			Price0:    prices[pair.tokens[0].address],
+			Price1:    prices[pair.tokens[1].address],
+			UpdatedAt: now,
+		}
+		item.TVL = pair.value(prices)
+		overview.Pairs = append(overview.Pairs, item)
+		overview.TotalTVL += item.TVL
+
+		for j, token := range pair.tokens {
+			entry, ok := tokens[token.address]
+			if !ok {
+				entry = &model.TokenTVL{Token: strings.ToLower(token.address.Hex()), Symbol: token.symbol}
+				tokens[token.address] = entry
+			}
+			entry.Amount += pair.reserves[j]
+			entry.TVL += pair.reserves[j] * prices[token.address]
+			entry.Pairs++
+		}
+	}
+
+	for _, entry := range tokens {
+		overview.Tokens = append(overview.Tokens, *entry)
+	}
+	sort.Slice(overview.Tokens, func(i, j int) bool {
+		return overview.Tokens[i].TVL > overview.Tokens[j].TVL
+	})
+	return overview
+}
+
+// store 保存最新概览并追加时间序列，同时清理超出保留期的采样
+func (s *tvlService) store(ctx context.Context, overview *model.TVLOverview) {
+	if data, err := json.Marshal(overview); err == nil {
+		if err := s.redisClient.Set(ctx, tvlLatestKey, data, 0); err != nil {
+			s.logger.Warnf("Failed to save TVL overview: %v", err)
+		}
+	}
+
+	cutoff := "(" + strconv.FormatInt(overview.UpdatedAt.Add(-s.retention()).UnixMilli(), 10)
+	appendSample := func(key string, tvl float64) {
+		data, err := json.Marshal(tvlSample{Timestamp: overview.UpdatedAt.UnixMilli(), TVL: tvl})
+		if err != nil {
+			return
+		}
+		if err := s.redisClient.ZAdd(ctx, key, float64(overview.UpdatedAt.UnixMilli()), string(data)); err != nil {
+			s.logger.Warnf("Failed to record TVL sample for %s: %v", key, err)
+			return
+		}
+		if err := s.redisClient.ZRemRangeByScore(ctx, key, "-inf", cutoff); err != nil {
+			s.logger.Warnf("Failed to trim TVL samples for %s: %v", key, err)
+		}
+	}
+
+	// 无法定价时不记录采样，避免把定价失败误判为锁仓价值归零
+	for _, pair := range overview.Pairs {
+		if pairPriced(pair) {
+			appendSample(tvlPairKeyPrefix+pair.Address, pair.TVL)
+		}
+	}
+	for _, token := range overview.Tokens {
+		if token.TVL > 0 {
+			appendSample(tvlTokenKeyPrefix+token.Token, token.TVL)
+		}
+	}
+}
+
+// checkDrop 与窗口内的峰值比较，下跌超过阈值时发送告警
+func (s *tvlService) checkDrop(ctx context.Context, pair model.PairTVL) {
+	if s.notifier == nil || s.redisClient == nil || !pairPriced(pair) {
+		return
+	}
+
+	window := s.config.BSC.TVL.DropWindow
+	if window <= 0 {
+		window = defaultTVLDropWindow
+	}
+	threshold := s.config.BSC.TVL.DropThreshold
+	if threshold <= 0 {
+		threshold = defaultTVLDropThreshold
+	}
+
+	samples, err := s.loadSamples(ctx, tvlPairKeyPrefix+pair.Address, pair.UpdatedAt.Add(-window), pair.UpdatedAt)
+	if err != nil {
+		s.logger.Warnf("Failed to load TVL samples for %s: %v", pair.Name, err)
+		return
+	}
+	var peak float64
+	for _, sample := range samples {
+		if sample.TVL > peak {
+			peak = sample.TVL
+		}
+	}
+	if peak <= 0 {
+		return
+	}
+
+	drop := (peak - pair.TVL) / peak
+	if drop < threshold {
+		return
+	}
+
+	alert := &model.Alert{
+		Type:     AlertTypeTVLDrop,
+		Severity: model.AlertSeverityCritical,
+		Title:    fmt.Sprintf("TVL of %s dropped %.1f%%", pair.Name, drop*100),
+		Message: fmt.Sprintf("TVL of pair %s (%s) fell from $%.2f to $%.2f within %s, possible liquidity removal",
+			pair.Name, pair.Address, peak, pair.TVL, window),
+		Subject:  pair.Address,
+		DedupKey: AlertTypeTVLDrop + ":" + pair.Address,
+		Data: map[string]interface{}{
+			"pair":     pair.Name,
+			"peak_tvl": peak,
+			"tvl":      pair.TVL,
+			"drop_pct": drop * 100,
+			"window":   window.String(),
+		},
+	}
+	if err := s.notifier.Notify(ctx, alert); err != nil {
+		s.logger.Warnf("Failed to send TVL drop alert for %s: %v", pair.Name, err)
+	}
+}
+
+// pairPriced 交易对至少一侧代币可定价
+func pairPriced(pair model.PairTVL) bool {
+	return pair.Price0 > 0 || pair.Price1 > 0
+}
+
+// GetOverview 获取最近一次计算的锁仓价值，尚未计算时立即刷新
+func (s *tvlService) GetOverview(ctx context.Context) (*model.TVLOverview, error) {
+	if !s.config.BSC.TVL.Enabled {
+		return nil, fmt.Errorf("%w: TVL tracking is disabled", ErrNotFound)
+	}
+
+	if s.redisClient != nil {
+		if data, err := s.redisClient.Get(ctx, tvlLatestKey); err == nil && data != "" {
+			var overview model.TVLOverview
+			if err := json.Unmarshal([]byte(data), &overview); err == nil {
+				return &overview, nil
+			}
+		}
+	}
+	return s.RefreshNow(ctx)
+}
+
+// GetHistory 获取交易对或代币的锁仓价值历史
+func (s *tvlService) GetHistory(ctx context.Context, kind, address string, from, to time.Time) (*model.TVLHistoryResponse, error) {
+	var prefix string
+	switch kind {
+	case TVLKindPair:
+		prefix = tvlPairKeyPrefix
+	case TVLKindToken:
+		prefix = tvlTokenKeyPrefix
+	default:
+		return nil, fmt.Errorf("%w: unsupported kind %q", ErrInvalidParameter, kind)
+	}
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidParameter, address)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidParameter)
+	}
+
+	address = strings.ToLower(address)
+	samples, err := s.loadSamples(ctx, prefix+address, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.TVLHistoryResponse{
+		Kind:    kind,
+		Address: address,
+		From:    from,
+		To:      to,
+		Points:  make([]model.TVLPoint, 0, len(samples)),
+	}
+	for _, sample := range samples {
+		resp.Points = append(resp.Points, model.TVLPoint{
+			Timestamp: time.UnixMilli(sample.Timestamp),
+			TVL:       sample.TVL,
+		})
+	}
+	if n := len(samples); n > 1 && samples[0].TVL > 0 {
+		resp.ChangePct = (samples[n-1].TVL - samples[0].TVL) / samples[0].TVL * 100
+	}
+	return resp, nil
+}
+
+// loadSamples 读取时间范围内的采样，按时间升序
+func (s *tvlService) loadSamples(ctx context.Context, key string, from, to time.Time) ([]tvlSample, error) {
+	if s.redisClient == nil {
+		return nil, nil
+	}
+
+	members, err := s.redisClient.ZRangeByScore(ctx, key,
+		strconv.FormatInt(from.UnixMilli(), 10), strconv.FormatInt(to.UnixMilli(), 10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TVL history: %w", err)
+	}
+
+	samples := make([]tvlSample, 0, len(members))
+	for _, member := range members {
+		var sample tvlSample
+		if err := json.Unmarshal([]byte(member), &sample); err != nil {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// retention 时间序列保留时长
+func (s *tvlService) retention() time.Duration {
+	if s.config.BSC.TVL.Retention > 0 {
+		return s.config.BSC.TVL.Retention
+	}
+	return defaultTVLRetention
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// usdStablecoins 按1美元计价的稳定币
+var usdStablecoins = map[string]bool{
+	"USDT": true, "USDC": true, "BUSD": true, "DAI": true, "TUSD": true, "FDUSD": true,
+}
+
+// usdWrappedSymbols 包装代币对应的行情符号
+var usdWrappedSymbols = map[string]string{
+	"WBNB": "BNB",
+	"BTCB": "BTC",
+	"WETH": "ETH",
+}
+
+// usdPricer 按代币符号估算美元价格，在一次计算内缓存查询结果
+type usdPricer struct {
+	priceService PriceService
+
+	mu     sync.Mutex
+	prices map[string]float64
+}
+
+// newUSDPricer 创建美元价格估算器，priceService可为nil，此时只能为稳定币定价
+func newUSDPricer(priceService PriceService) *usdPricer {
+	return &usdPricer{
+		priceService: priceService,
+		prices:       make(map[string]float64),
+	}
+}
+
+// price 获取代币的美元价格，无法定价时返回false
+func (p *usdPricer) price(ctx context.Context, symbol string) (float64, bool) {
+	symbol = strings.ToUpper(symbol)
+	if usdStablecoins[symbol] {
+		return 1, true
+	}
+	if wrapped, ok := usdWrappedSymbols[symbol]; ok {
+		symbol = wrapped
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	price, ok := p.prices[symbol]
+	if !ok {
+		if p.priceService != nil {
+			if resp, err := p.priceService.GetPrice(ctx, symbol); err == nil {
+				price = resp.Price
+			}
+		}
+		p.prices[symbol] = price
+	}
+	return price, price > 0
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// 用户webhook默认配置
+const (
+	defaultUserWebhookMaxPerUser = 5
+	defaultWebhookRetryAttempts  = 4
+	defaultWebhookInitialBackoff = time.Second
+	defaultWebhookMaxBackoff     = 30 * time.Second
+	maxUserWebhookName           = 64
+	userWebhookKeyPrefix         = "alerts:webhooks:user:"
+	userWebhookPrefix            = "user_webhook:" // 投递记录中的webhook名称前缀
+	userWebhookSecretBytes       = 32
+)
+
+// EventTypePriceAlert 价格告警消息，用作用户webhook投递记录的类型
+const EventTypePriceAlert = "price_alert"
+
+// CreateUserWebhook 注册用户webhook，未指定密钥时生成随机密钥，密钥只在创建时返回
+func (s *webhookService) CreateUserWebhook(ctx context.Context, userID string, req *model.UserWebhookRequest) (*model.UserWebhook, error) {
+	if err := s.checkUserStorage(userID); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if len(name) > maxUserWebhookName {
+		return nil, fmt.Errorf("%w: name exceeds %d characters", ErrInvalidParameter, maxUserWebhookName)
+	}
+	webhookURL := strings.TrimSpace(req.URL)
+	if err := validateWebhookURL(webhookURL); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.redisClient.HGetAll(ctx, userWebhookKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhooks: %w", err)
+	}
+	if len(existing) >= s.maxPerUser() {
+		return nil, fmt.Errorf("%w: at most %d webhooks per user", ErrInvalidParameter, s.maxPerUser())
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, userWebhookSecretBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	webhook := &model.UserWebhook{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		URL:       webhookURL,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+	data, err := json.Marshal(webhook)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redisClient.HSet(ctx, userWebhookKey(userID), webhook.ID, string(data)); err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// ListUserWebhooks 获取用户的webhook，按创建时间排序，不含密钥
+func (s *webhookService) ListUserWebhooks(ctx context.Context, userID string) (*model.UserWebhookListResponse, error) {
+	webhooks, err := s.userWebhooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &model.UserWebhookListResponse{Webhooks: make([]model.UserWebhook, 0, len(webhooks))}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+		resp.Webhooks = append(resp.Webhooks, *webhook)
+	}
+	resp.Total = len(resp.Webhooks)
+	return resp, nil
+}
+
+// DeleteUserWebhook 删除用户webhook，已有的投递记录保留到过期
+func (s *webhookService) DeleteUserWebhook(ctx context.Context, userID, id string) error {
+	if _, err := s.getUserWebhook(ctx, userID, id); err != nil {
+		return err
+	}
+	if err := s.redisClient.HDel(ctx, userWebhookKey(userID), id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// ListUserDeliveries 获取用户webhook的投递记录，按时间倒序
+func (s *webhookService) ListUserDeliveries(ctx context.Context, userID, id string, limit int) (*model.WebhookDeliveryListResponse, error) {
+	if _, err := s.getUserWebhook(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.ListDeliveries(ctx, userWebhookPrefix+id, "", limit)
+}
+
+// RedriveUserDelivery 重新投递用户webhook的投递记录，只能重新投递自己的记录
+func (s *webhookService) RedriveUserDelivery(ctx context.Context, userID, id, deliveryID string) (*model.WebhookDelivery, error) {
+	if err := s.checkUserStorage(userID); err != nil {
+		return nil, err
+	}
+	original, err := s.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.UserID != userID || original.Webhook != userWebhookPrefix+id {
+		return nil, fmt.Errorf("%w: webhook delivery %s", ErrNotFound, deliveryID)
+	}
+	return s.Redrive(ctx, deliveryID)
+}
+
+// DeliverToUser 向用户的所有webhook投递事件，失败时按配置重试，每个webhook只保存最后一次尝试的记录
+func (s *webhookService) DeliverToUser(ctx context.Context, userID, eventID, eventType string, payload []byte) []model.AlertDelivery {
+	if userID == "" || s.redisClient == nil {
+		return nil
+	}
+	webhooks, err := s.userWebhooks(ctx, userID)
+	if err != nil {
+		s.logger.Warnf("Failed to load webhooks of user %s: %v", userID, err)
+		return nil
+	}
+
+	deliveries := make([]model.AlertDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		record := s.sendWithRetry(ctx, userWebhookConfig(webhook), eventID, eventType, payload)
+		record.UserID = userID
+		s.record(ctx, record)
+		deliveries = append(deliveries, model.AlertDelivery{
+			Channel:    model.AlertChannelWebhook,
+			Target:     record.Webhook,
+			Success:    record.Success,
+			Error:      record.Error,
+			LatencyMs:  record.LatencyMs,
+			DeliveryID: record.ID,
+		})
+	}
+	return deliveries
+}
+
+// sendWithRetry 投递并在网络错误、5xx和429时按指数退避重试，ctx结束时停止重试
+func (s *webhookService) sendWithRetry(ctx context.Context, webhook config.Webhook, eventID, eventType string, payload []byte) *model.WebhookDelivery {
+	retry := s.config.Notifier.Webhooks.Retry
+	attempts := retry.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultWebhookRetryAttempts
+	}
+	backoff := retry.InitialBackoff
+	if backoff <= 0 {
+		backoff = defaultWebhookInitialBackoff
+	}
+	maxBackoff := retry.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultWebhookMaxBackoff
+	}
+
+	var record *model.WebhookDelivery
+	for attempt := 1; ; attempt++ {
+		record = s.send(ctx, webhook, eventID, eventType, payload)
+		record.Attempts = attempt
+		if record.Success || attempt >= attempts || !webhookRetryable(record) {
+			return record
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return record
+		case <-timer.C:
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// webhookRetryable 请求未完成、接收方5xx或限流时重试，其他4xx重试也不会成功
+func webhookRetryable(record *model.WebhookDelivery) bool {
+	return record.StatusCode == 0 || record.StatusCode >= http.StatusInternalServerError || record.StatusCode == http.StatusTooManyRequests
+}
+
+// userWebhooks 读取用户的webhook，包含密钥
+func (s *webhookService) userWebhooks(ctx context.Context, userID string) ([]*model.UserWebhook, error) {
+	if err := s.checkUserStorage(userID); err != nil {
+		return nil, err
+	}
+	entries, err := s.redisClient.HGetAll(ctx, userWebhookKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	webhooks := make([]*model.UserWebhook, 0, len(entries))
+	for id, value := range entries {
+		var webhook model.UserWebhook
+		if err := json.Unmarshal([]byte(value), &webhook); err != nil {
+			s.logger.Warnf("Skipping malformed webhook %s for user %s: %v", id, userID, err)
+			continue
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+// getUserWebhook 获取用户webhook，包含密钥，不存在时返回ErrNotFound
+func (s *webhookService) getUserWebhook(ctx context.Context, userID, id string) (*model.UserWebhook, error) {
+	if err := s.checkUserStorage(userID); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: webhook %s", ErrNotFound, id)
+	}
+
+	value, err := s.redisClient.HGet(ctx, userWebhookKey(userID), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook: %w", err)
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%w: webhook %s", ErrNotFound, id)
+	}
+
+	var webhook model.UserWebhook
+	if err := json.Unmarshal([]byte(value), &webhook); err != nil {
+		return nil, fmt.Errorf("invalid webhook %s: %w", id, err)
+	}
+	return &webhook, nil
+}
+
+// checkUserStorage 检查用户标识和存储是否可用
+func (s *webhookService) checkUserStorage(userID string) error {
+	if userID == "" {
+		return fmt.Errorf("%w: user id is required", ErrInvalidParameter)
+	}
+	if s.redisClient == nil {
+		return fmt.Errorf("%w: webhook storage is not configured", ErrUpstreamUnavailable)
+	}
+	return nil
+}
+
+// maxPerUser 每个用户的webhook上限
+func (s *webhookService) maxPerUser() int {
+	if s.config.Notifier.Webhooks.MaxPerUser > 0 {
+		return s.config.Notifier.Webhooks.MaxPerUser
+	}
+	return defaultUserWebhookMaxPerUser
+}
+
+// userWebhookConfig 转换为投递使用的webhook，名称带前缀以便按webhook筛选投递记录
+func userWebhookConfig(webhook *model.UserWebhook) config.Webhook {
+	return config.Webhook{
+		Name:   userWebhookPrefix + webhook.ID,
+		URL:    webhook.URL,
+		Secret: webhook.Secret,
+	}
+}
+
+// userWebhookKey 用户webhook存储key
+func userWebhookKey(userID string) string {
+	return userWebhookKeyPrefix + userID
+}
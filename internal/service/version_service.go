@@ -0,0 +1,122 @@
+package service
+
+import (
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/buildinfo"
+)
+
+// 数据提供方名称
+const (
+	providerHuobi    = "huobi"
+	providerBinance  = "binance"
+	providerOKX      = "okx"
+	providerCoinbase = "coinbase"
+	providerKraken   = "kraken"
+	providerBSCRPC   = "bsc_rpc"
+	providerBscScan  = "bscscan"
+	providerMock     = "mock"
+)
+
+// VersionService 实例构建信息与功能查询接口
+type VersionService interface {
+	// Version 构建信息、已启用的功能和数据提供方
+	Version() *model.VersionResponse
+}
+
+// versionService 实例构建信息与功能查询实现
+type versionService struct {
+	config    *config.Config
+	startedAt time.Time
+}
+
+// NewVersionService 创建实例构建信息与功能查询服务
+func NewVersionService(cfg *config.Config) VersionService {
+	return &versionService{
+		config:    cfg,
+		startedAt: time.Now(),
+	}
+}
+
+// Version 构建信息取自ldflags注入的值，功能和提供方按当前配置计算
+func (s *versionService) Version() *model.VersionResponse {
+	info := buildinfo.Get()
+	return &model.VersionResponse{
+		Version:   info.Version,
+		BuildTime: info.BuildTime,
+		GitCommit: info.GitCommit,
+		GoVersion: info.GoVersion,
+		Env:       s.config.App.Env,
+		StartedAt: s.startedAt,
+		Features:  s.features(),
+		Providers: s.providers(),
+	}
+}
+
+// features 功能开关，名称与配置项对应
+func (s *versionService) features() map[string]bool {
+	cfg := s.config
+	bsc := cfg.BSC.Enabled
+	return map[string]bool{
+		"stream":            cfg.Stream.Enabled,
+		"history":           cfg.History.Enabled,
+		"ingest":            cfg.Ingest.Enabled,
+		"names":             cfg.Names.Enabled,
+		"alert_rules":       cfg.Notifier.Rules.Enabled,
+		"scheduled_alerts":  cfg.Notifier.Scheduled.Enabled,
+		"alert_webhooks":    len(cfg.Notifier.Webhooks.Endpoints) > 0,
+		"alert_rate_limit":  cfg.Notifier.RateLimit.Enabled,
+		"panic_alerts":      cfg.Notifier.Panics.Enabled,
+		"bsc":               bsc,
+		"bsc_token_sync":    bsc && cfg.BSC.TokenSync.Enabled,
+		"bsc_bridges":       bsc && cfg.BSC.Bridges.Enabled,
+		"bsc_farms":         bsc && cfg.BSC.Farms.Enabled,
+		"bsc_tvl":           bsc && cfg.BSC.TVL.Enabled,
+		"bsc_liquidity":     bsc && cfg.BSC.Liquidity.Enabled,
+		"bsc_snapshot":      bsc && cfg.BSC.Snapshot.Enabled,
+		"response_cache":    cfg.Server.HTTP.ResponseCache.Enabled,
+		"rate_limit":        cfg.RateLimit.Enabled,
+		"concurrency_limit": cfg.Server.HTTP.Concurrency.Enabled,
+		"load_shedding":     cfg.Server.HTTP.LoadShedding.Enabled,
+		"session":           cfg.Security.Session.Enabled,
+		"grpc_reflection":   cfg.Server.GRPC.Reflection,
+		"rocketmq":          cfg.RocketMQ.Enabled,
+		"api_budget":        cfg.ExternalAPI.Budget.Enabled,
+		"dns_cache":         cfg.ExternalAPI.DNS.Enabled,
+		"metrics":           cfg.Monitoring.Metrics.Enabled,
+		"tracing":           cfg.Monitoring.Tracing.Enabled,
+	}
+}
+
+// providers 已配置地址的数据提供方，启用模拟数据时包含mock
+func (s *versionService) providers() []string {
+	cfg := s.config
+	providers := []string{}
+	if cfg.ExternalAPI.Huobi.BaseURL != "" {
+		providers = append(providers, providerHuobi)
+	}
+	if cfg.ExternalAPI.Binance.BaseURL != "" {
+		providers = append(providers, providerBinance)
+	}
+	if cfg.ExternalAPI.OKX.BaseURL != "" {
+		providers = append(providers, providerOKX)
+	}
+	if cfg.ExternalAPI.Coinbase.BaseURL != "" {
+		providers = append(providers, providerCoinbase)
+	}
+	if cfg.ExternalAPI.Kraken.BaseURL != "" {
+		providers = append(providers, providerKraken)
+	}
+	if cfg.BSC.Enabled {
+		providers = append(providers, providerBSCRPC)
+	}
+	if cfg.ExternalAPI.BscScan.BaseURL != "" {
+		providers = append(providers, providerBscScan)
+	}
+	if cfg.Business.MockDataEnabled {
+		providers = append(providers, providerMock)
+	}
+	return providers
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	"crypto-info/internal/config"
 	"crypto-info/internal/model"
 	"crypto-info/internal/pkg/database"
 	"crypto-info/internal/pkg/logger"
+	"crypto-info/internal/pkg/popularity"
 )
 
 // VolumeService 交易量服务接口
@@ -21,19 +23,22 @@ type VolumeService interface {
 	GetTopVolumeCoins(ctx context.Context, days, limit int) (*model.TopVolumeCoinsResponse, error)
 }
 
+// maxVolumeFetchConcurrency 并发获取交易量数据的最大协程数
+const maxVolumeFetchConcurrency = 4
+
 // volumeService 交易量服务实现
 type volumeService struct {
-	redisClient database.RedisClient
-	config      *config.Config
-	logger      logger.Logger
+	redisClient   database.RedisClient
+	config        *config.Config
+	negativeCache *negativeCache
 }
 
 // NewVolumeService 创建交易量服务
 func NewVolumeService(redisClient database.RedisClient, cfg *config.Config) VolumeService {
 	return &volumeService{
-		redisClient: redisClient,
-		config:      cfg,
-		logger:      logger.GetLogger(),
+		redisClient:   redisClient,
+		config:        cfg,
+		negativeCache: newNegativeCache(redisClient, cfg.Cache.NegativeTTL),
 	}
 }
 
@@ -52,28 +57,40 @@ func (s *volumeService) GetVolumeAnalysis(ctx context.Context, symbol string, da
 
 	// 检查是否支持该币种
 	if !s.isSupportedSymbol(symbol) {
-		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSymbol, symbol)
 	}
+	popularity.Default().Record(symbol)
 
 	// 尝试从缓存获取
 	if s.redisClient != nil {
 		if cached, err := s.getVolumeFromCache(ctx, symbol, days); err == nil && cached != nil {
-			s.logger.Debugf("Volume analysis cache hit for symbol: %s, days: %d", symbol, days)
+			logger.From(ctx).Debugf("Volume analysis cache hit for symbol: %s, days: %d", symbol, days)
 			return cached, nil
 		}
 	}
 
+	// 检查负缓存
+	negativeKey := fmt.Sprintf("volume:%s:%d", symbol, days)
+	if err := s.negativeCache.get(ctx, negativeKey); err != nil {
+		logger.From(ctx).Debugf("Volume negative cache hit for symbol: %s, days: %d", symbol, days)
+		return nil, err
+	}
+
 	// 获取交易量数据
 	analysis, err := s.fetchVolumeAnalysis(ctx, symbol, days)
 	if err != nil {
-		s.logger.Errorf("Failed to fetch volume analysis for %s: %v", symbol, err)
+		logger.From(ctx).Errorf("Failed to fetch volume analysis for %s: %v", symbol, err)
+		err = fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+		if cacheErr := s.negativeCache.set(ctx, negativeKey, err); cacheErr != nil {
+			logger.From(ctx).Warnf("Failed to set negative cache for %s: %v", symbol, cacheErr)
+		}
 		return nil, err
 	}
 
 	// 缓存结果
 	if s.redisClient != nil {
 		if err := s.setVolumeCache(ctx, symbol, days, analysis); err != nil {
-			s.logger.Warnf("Failed to cache volume analysis for %s: %v", symbol, err)
+			logger.From(ctx).Warnf("Failed to cache volume analysis for %s: %v", symbol, err)
 		}
 	}
 
@@ -94,20 +111,34 @@ func (s *volumeService) GetVolumeComparison(ctx context.Context, symbols []strin
 		days = s.config.Business.DefaultAnalysisDays
 	}
 
+	results := fanOut(ctx, symbols, maxVolumeFetchConcurrency, func(ctx context.Context, symbol string) (*model.VolumeAnalysisResponse, error) {
+		return s.GetVolumeAnalysis(ctx, symbol, days)
+	})
+
 	var comparison []model.VolumeAnalysisResponse
-	for _, symbol := range symbols {
-		analysis, err := s.GetVolumeAnalysis(ctx, symbol, days)
-		if err != nil {
-			s.logger.Warnf("Failed to get volume analysis for %s: %v", symbol, err)
+	var symbolErrors []model.SymbolError
+	for _, result := range results {
+		if result.Err != nil {
+			logger.From(ctx).Warnf("Failed to get volume analysis for %s: %v", result.Key, result.Err)
+			symbolErrors = append(symbolErrors, model.SymbolError{
+				Symbol: result.Key,
+				Error:  result.Err.Error(),
+			})
 			continue
 		}
-		comparison = append(comparison, *analysis)
+		comparison = append(comparison, *result.Value)
+	}
+
+	// 全部失败时才返回错误
+	if len(comparison) == 0 {
+		return nil, fmt.Errorf("failed to get volume analysis for all %d symbols", len(symbols))
 	}
 
 	return &model.VolumeComparisonResponse{
 		Symbols:     symbols,
 		Period:      fmt.Sprintf("%d days", days),
 		Comparison:  comparison,
+		Errors:      symbolErrors,
 		GeneratedAt: time.Now().Format(time.RFC3339),
 	}, nil
 }
@@ -117,22 +148,39 @@ func (s *volumeService) GetTopVolumeCoins(ctx context.Context, days, limit int)
 	if days <= 0 {
 		days = s.config.Business.DefaultAnalysisDays
 	}
+	if days > s.config.Business.MaxAnalysisDays {
+		days = s.config.Business.MaxAnalysisDays
+	}
 	if limit <= 0 {
 		limit = 10
 	}
 
-	// 获取所有支持的币种数据
-	var topCoins []model.VolumeAnalysisResponse
-	for i, symbol := range s.config.Business.SupportedSymbols {
-		if i >= limit {
-			break
+	// 尝试从缓存获取完整排行
+	var ranking []model.VolumeAnalysisResponse
+	if s.redisClient != nil {
+		if cached, err := s.getTopVolumeFromCache(ctx, days); err == nil && cached != nil {
+			logger.From(ctx).Debugf("Top volume cache hit for days: %d", days)
+			ranking = cached
 		}
-		analysis, err := s.GetVolumeAnalysis(ctx, symbol, days)
+	}
+
+	if ranking == nil {
+		var err error
+		ranking, err = s.computeVolumeRanking(ctx, days)
 		if err != nil {
-			s.logger.Warnf("Failed to get volume analysis for %s: %v", symbol, err)
-			continue
+			return nil, err
+		}
+
+		if s.redisClient != nil {
+			if err := s.setTopVolumeCache(ctx, days, ranking); err != nil {
+				logger.From(ctx).Warnf("Failed to cache top volume ranking for %d days: %v", days, err)
+			}
 		}
-		topCoins = append(topCoins, *analysis)
+	}
+
+	topCoins := ranking
+	if len(topCoins) > limit {
+		topCoins = topCoins[:limit]
 	}
 
 	return &model.TopVolumeCoinsResponse{
@@ -143,8 +191,38 @@ func (s *volumeService) GetTopVolumeCoins(ctx context.Context, days, limit int)
 	}, nil
 }
 
+// computeVolumeRanking 并发获取所有支持币种的交易量并按周期总交易量降序排序
+func (s *volumeService) computeVolumeRanking(ctx context.Context, days int) ([]model.VolumeAnalysisResponse, error) {
+	symbols := s.config.Business.SupportedSymbols
+	results := fanOut(ctx, symbols, maxVolumeFetchConcurrency, func(ctx context.Context, symbol string) (*model.VolumeAnalysisResponse, error) {
+		return s.GetVolumeAnalysis(ctx, symbol, days)
+	})
+
+	ranking := make([]model.VolumeAnalysisResponse, 0, len(results))
+	for _, result := range results {
+		if result.Err != nil {
+			logger.From(ctx).Warnf("Failed to get volume analysis for %s: %v", result.Key, result.Err)
+			continue
+		}
+		ranking = append(ranking, *result.Value)
+	}
+	if len(ranking) == 0 && len(symbols) > 0 {
+		return nil, fmt.Errorf("failed to get volume data for all %d symbols", len(symbols))
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return ranking[i].TotalVolume > ranking[j].TotalVolume
+	})
+
+	return ranking, nil
+}
+
 // fetchVolumeAnalysis 获取交易量分析数据
 func (s *volumeService) fetchVolumeAnalysis(ctx context.Context, symbol string, days int) (*model.VolumeAnalysisResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// 如果启用了模拟数据，返回模拟数据
 	if s.config.Business.MockDataEnabled {
 		return s.generateMockVolumeAnalysis(symbol, days), nil
@@ -203,41 +281,24 @@ func (s *volumeService) generateMockVolumeAnalysis(symbol string, days int) *mod
 	avgVolume := totalVolume / float64(days)
 	volatility := (maxVolume - minVolume) / avgVolume * 100
 
-	// 简单趋势判断
-	trend := "稳定"
-	if len(data) >= 2 {
-		firstHalf := data[:len(data)/2]
-		secondHalf := data[len(data)/2:]
-		
-		var firstAvg, secondAvg float64
-		for _, d := range firstHalf {
-			firstAvg += d.Volume
-		}
-		firstAvg /= float64(len(firstHalf))
-		
-		for _, d := range secondHalf {
-			secondAvg += d.Volume
-		}
-		secondAvg /= float64(len(secondHalf))
-		
-		if secondAvg > firstAvg*1.05 {
-			trend = "上升"
-		} else if secondAvg < firstAvg*0.95 {
-			trend = "下降"
-		}
-	}
+	// 线性回归趋势判断
+	trend := analyzeTrend(data)
 
 	return &model.VolumeAnalysisResponse{
-		Symbol:      symbol,
-		Period:      fmt.Sprintf("%d days", days),
-		Data:        data,
-		AvgVolume:   math.Round(avgVolume),
-		MaxVolume:   math.Round(maxVolume),
-		MinVolume:   math.Round(minVolume),
-		Volatility:  math.Round(volatility*100) / 100,
-		Trend:       trend,
-		Source:      "Mock Data",
-		GeneratedAt: time.Now().Format(time.RFC3339),
+		Symbol:          symbol,
+		Period:          fmt.Sprintf("%d days", days),
+		Data:            data,
+		AvgVolume:       math.Round(avgVolume),
+		TotalVolume:     math.Round(totalVolume),
+		MaxVolume:       math.Round(maxVolume),
+		MinVolume:       math.Round(minVolume),
+		Volatility:      math.Round(volatility*100) / 100,
+		Trend:           trend.Label,
+		TrendSlope:      math.Round(trend.Slope*100) / 100,
+		TrendConfidence: math.Round(trend.Confidence*10000) / 10000,
+		TrendStrength:   trend.Strength,
+		Source:          "Mock Data",
+		GeneratedAt:     time.Now().Format(time.RFC3339),
 	}
 }
 
@@ -275,5 +336,36 @@ func (s *volumeService) setVolumeCache(ctx context.Context, symbol string, days
 		return err
 	}
 
-	return s.redisClient.Set(ctx, cacheKey, data, s.config.Cache.VolumeTTL)
+	return s.redisClient.Set(ctx, cacheKey, data, popularity.Default().TTL(symbol, s.config.Cache.VolumeTTL))
+}
+
+// getTopVolumeFromCache 从缓存获取交易量排行
+func (s *volumeService) getTopVolumeFromCache(ctx context.Context, days int) ([]model.VolumeAnalysisResponse, error) {
+	cacheKey := fmt.Sprintf("volume:top:%d", days)
+	cachedData, err := s.redisClient.Get(ctx, cacheKey)
+	if err != nil || cachedData == "" {
+		return nil, fmt.Errorf("cache miss")
+	}
+
+	var ranking []model.VolumeAnalysisResponse
+	if err := json.Unmarshal([]byte(cachedData), &ranking); err != nil {
+		return nil, err
+	}
+
+	return ranking, nil
+}
+
+// setTopVolumeCache 设置交易量排行缓存
+func (s *volumeService) setTopVolumeCache(ctx context.Context, days int, ranking []model.VolumeAnalysisResponse) error {
+	cacheKey := fmt.Sprintf("volume:top:%d", days)
+	data, err := json.Marshal(ranking)
+	if err != nil {
+		return err
+	}
+
+	ttl := s.config.Cache.TopVolumeTTL
+	if ttl <= 0 {
+		ttl = s.config.Cache.VolumeTTL
+	}
+	return s.redisClient.Set(ctx, cacheKey, data, ttl)
 }
\ No newline at end of file
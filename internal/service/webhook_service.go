@@ -0,0 +1,471 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-info/internal/config"
+	"crypto-info/internal/model"
+	"crypto-info/internal/pkg/database"
+	"crypto-info/internal/pkg/httpclient"
+	"crypto-info/internal/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// webhook投递默认配置
+const (
+	defaultWebhookTimeout      = 5 * time.Second
+	defaultWebhookLogRetention = 7 * 24 * time.Hour
+	defaultWebhookListLimit    = 50
+	maxWebhookListLimit        = 200
+	maxWebhookResponse         = 512 // 投递记录保留的响应体长度，只保留配置文件中webhook的响应
+	webhookBatchSize           = 100 // 批量读取投递记录的数量
+	webhookDeliveryKeyPrefix   = "alerts:webhooks:delivery:"
+	webhookDeliveryIndexKey    = "alerts:webhooks:deliveries"
+	webhookFailureIndexKey     = "alerts:webhooks:failures"
+)
+
+// WebhookService 告警webhook投递服务接口
+type WebhookService interface {
+	// Accepts 是否有webhook订阅该告警
+	Accepts(alert *model.Alert) bool
+	// Deliver 向订阅该类型告警的webhook投递告警，返回各webhook的投递结果
+	Deliver(ctx context.Context, alert *model.Alert) []model.AlertDelivery
+	// ListDeliveries 获取投递记录，webhook和status为空时不筛选
+	ListDeliveries(ctx context.Context, webhook, status string, limit int) (*model.WebhookDeliveryListResponse, error)
+	// GetDelivery 获取投递记录，包含请求体
+	GetDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error)
+	// Send 向指定的webhook投递JSON请求体并记录投递结果，用于用户在事件过滤器中设置的接收地址
+	Send(ctx context.Context, webhook config.Webhook, eventID, eventType string, payload []byte) model.AlertDelivery
+	// Redrive 将投递记录的请求体重新投递到同名webhook的当前地址
+	Redrive(ctx context.Context, id string) (*model.WebhookDelivery, error)
+
+	// CreateUserWebhook 注册用户webhook，返回的记录包含签名密钥
+	CreateUserWebhook(ctx context.Context, userID string, req *model.UserWebhookRequest) (*model.UserWebhook, error)
+	// ListUserWebhooks 获取用户的webhook，不含签名密钥
+	ListUserWebhooks(ctx context.Context, userID string) (*model.UserWebhookListResponse, error)
+	// DeleteUserWebhook 删除用户webhook
+	DeleteUserWebhook(ctx context.Context, userID, id string) error
+	// ListUserDeliveries 获取用户webhook的投递记录
+	ListUserDeliveries(ctx context.Context, userID, id string, limit int) (*model.WebhookDeliveryListResponse, error)
+	// RedriveUserDelivery 重新投递用户webhook的投递记录
+	RedriveUserDelivery(ctx context.Context, userID, id, deliveryID string) (*model.WebhookDelivery, error)
+	// DeliverToUser 向用户注册的所有webhook投递事件，失败时按配置重试
+	DeliverToUser(ctx context.Context, userID, eventID, eventType string, payload []byte) []model.AlertDelivery
+}
+
+// webhookService 每次投递的记录单独保存并设置过期时间，全部记录和失败记录分别用有序集合索引
+type webhookService struct {
+	redisClient database.RedisClient
+	config      *config.Config
+	logger      logger.Logger
+	client      *http.Client // 配置文件中的webhook
+	userClient  *http.Client // 用户提供的接收地址，只允许访问公网地址且不跟随重定向
+}
+
+// NewWebhookService 创建webhook投递服务
+func NewWebhookService(redisClient database.RedisClient, cfg *config.Config) WebhookService {
+	timeout := cfg.Notifier.Webhooks.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &webhookService{
+		redisClient: redisClient,
+		config:      cfg,
+		logger:      logger.GetLogger(),
+		client:      httpclient.New(httpclient.Options{Timeout: timeout}),
+		userClient:  httpclient.NewPublic(httpclient.Options{Timeout: timeout}),
+	}
+}
+
+// Accepts 是否有webhook订阅该告警
+func (s *webhookService) Accepts(alert *model.Alert) bool {
+	for _, webhook := range s.config.Notifier.Webhooks.Endpoints {
+		if webhookAccepts(webhook, alert) {
+			return true
+		}
+	}
+	return false
+}
+
+// Deliver 投递告警，投递失败只记录不重试，可通过Redrive手动重新投递
+func (s *webhookService) Deliver(ctx context.Context, alert *model.Alert) []model.AlertDelivery {
+	var deliveries []model.AlertDelivery
+	var payload []byte
+	for _, webhook := range s.config.Notifier.Webhooks.Endpoints {
+		if !webhookAccepts(webhook, alert) {
+			continue
+		}
+		if payload == nil {
+			data, err := json.Marshal(alert)
+			if err != nil {
+				s.logger.Warnf("Failed to encode alert %s for webhooks: %v", alert.ID, err)
+				return nil
+			}
+			payload = data
+		}
+
+		record := s.send(ctx, webhook, alert.ID, alert.Type, payload)
+		s.record(ctx, record)
+		deliveries = append(deliveries, model.AlertDelivery{
+			Channel:    model.AlertChannelWebhook,
+			Target:     webhook.Name,
+			Success:    record.Success,
+			Error:      record.Error,
+			LatencyMs:  record.LatencyMs,
+			DeliveryID: record.ID,
+		})
+	}
+	return deliveries
+}
+
+// Send 投递一次，失败只记录不重试；不在配置中的webhook不能通过Redrive重新投递
+func (s *webhookService) Send(ctx context.Context, webhook config.Webhook, eventID, eventType string, payload []byte) model.AlertDelivery {
+	record := s.send(ctx, webhook, eventID, eventType, payload)
+	s.record(ctx, record)
+	return model.AlertDelivery{
+		Channel:    model.AlertChannelWebhook,
+		Target:     webhook.Name,
+		Success:    record.Success,
+		Error:      record.Error,
+		LatencyMs:  record.LatencyMs,
+		DeliveryID: record.ID,
+	}
+}
+
+// ListDeliveries 获取投递记录，按时间倒序
+func (s *webhookService) ListDeliveries(ctx context.Context, webhook, status string, limit int) (*model.WebhookDeliveryListResponse, error) {
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: webhook delivery log is not configured", ErrUpstreamUnavailable)
+	}
+	if limit <= 0 {
+		limit = defaultWebhookListLimit
+	}
+	if limit > maxWebhookListLimit {
+		limit = maxWebhookListLimit
+	}
+
+	indexKey := webhookDeliveryIndexKey
+	switch status {
+	case "":
+	case model.WebhookDeliveryFailed:
+		indexKey = webhookFailureIndexKey
+	case model.WebhookDeliverySucceeded:
+	default:
+		return nil, fmt.Errorf("%w: status must be %s or %s", ErrInvalidParameter, model.WebhookDeliveryFailed, model.WebhookDeliverySucceeded)
+	}
+
+	s.trim(ctx)
+	ids, err := s.redisClient.ZRangeByScore(ctx, indexKey, "-inf", "+inf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook deliveries: %w", err)
+	}
+
+	resp := &model.WebhookDeliveryListResponse{Deliveries: []model.WebhookDelivery{}}
+	// 从最新的记录开始分批读取
+	for end := len(ids); end > 0; end -= webhookBatchSize {
+		start := end - webhookBatchSize
+		if start < 0 {
+			start = 0
+		}
+		batch := make([]string, 0, end-start)
+		for i := end - 1; i >= start; i-- {
+			batch = append(batch, ids[i])
+		}
+		records, err := s.load(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if webhook != "" && record.Webhook != webhook {
+				continue
+			}
+			if status == model.WebhookDeliverySucceeded && !record.Success {
+				continue
+			}
+			resp.Total++
+			if len(resp.Deliveries) < limit {
+				record.Payload = nil
+				resp.Deliveries = append(resp.Deliveries, *record)
+			}
+		}
+	}
+	return resp, nil
+}
+
+// GetDelivery 获取投递记录，不存在或已过期时返回ErrNotFound
+func (s *webhookService) GetDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	if s.redisClient == nil {
+		return nil, fmt.Errorf("%w: webhook delivery log is not configured", ErrUpstreamUnavailable)
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: webhook delivery %s", ErrNotFound, id)
+	}
+
+	value, err := s.redisClient.Get(ctx, webhookDeliveryKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook delivery: %w", err)
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%w: webhook delivery %s", ErrNotFound, id)
+	}
+
+	var record model.WebhookDelivery
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, fmt.Errorf("invalid webhook delivery %s: %w", id, err)
+	}
+	return &record, nil
+}
+
+// Redrive 重新投递，成功后原记录不再出现在失败列表中；用户webhook投递到其当前地址
+func (s *webhookService) Redrive(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	original, err := s.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.redriveTarget(ctx, original)
+	if err != nil {
+		return nil, err
+	}
+
+	record := s.send(ctx, webhook, original.AlertID, original.AlertType, original.Payload)
+	record.UserID = original.UserID
+	record.RedriveOf = original.ID
+	s.record(ctx, record)
+
+	original.RedrivenBy = record.ID
+	if err := s.save(ctx, original); err != nil {
+		s.logger.Warnf("Failed to update webhook delivery %s: %v", original.ID, err)
+	}
+	if record.Success {
+		if err := s.redisClient.GetClient().ZRem(ctx, s.redisClient.KeyPrefix()+webhookFailureIndexKey, original.ID).Err(); err != nil {
+			s.logger.Warnf("Failed to clear webhook failure %s: %v", original.ID, err)
+		}
+	}
+	return record, nil
+}
+
+// redriveTarget 查找投递记录对应webhook的当前配置，用户webhook按ID从存储中读取
+func (s *webhookService) redriveTarget(ctx context.Context, original *model.WebhookDelivery) (config.Webhook, error) {
+	if id, ok := strings.CutPrefix(original.Webhook, userWebhookPrefix); ok && original.UserID != "" {
+		webhook, err := s.getUserWebhook(ctx, original.UserID, id)
+		if errors.Is(err, ErrNotFound) {
+			return config.Webhook{}, fmt.Errorf("%w: webhook %q has been deleted", ErrInvalidParameter, original.Webhook)
+		}
+		if err != nil {
+			return config.Webhook{}, err
+		}
+		return userWebhookConfig(webhook), nil
+	}
+	for _, webhook := range s.config.Notifier.Webhooks.Endpoints {
+		if webhook.Name == original.Webhook {
+			return webhook, nil
+		}
+	}
+	return config.Webhook{}, fmt.Errorf("%w: webhook %q is no longer configured", ErrInvalidParameter, original.Webhook)
+}
+
+// send 投递一次并生成记录，用户提供的接收地址只能访问公网地址，记录中不保留其响应体
+func (s *webhookService) send(ctx context.Context, webhook config.Webhook, alertID, alertType string, payload []byte) *model.WebhookDelivery {
+	record := &model.WebhookDelivery{
+		ID:        uuid.New().String(),
+		Webhook:   webhook.Name,
+		URL:       redactURL(webhook.URL),
+		AlertID:   alertID,
+		AlertType: alertType,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		record.Error = fmt.Sprintf("invalid webhook url: %v", err)
+		return record
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Alert-ID", alertID)
+	req.Header.Set("X-Delivery-ID", record.ID)
+	if webhook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(webhook.Secret))
+		mac.Write(payload)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client, userOwned := s.client, s.userOwned(webhook)
+	if userOwned {
+		client = s.userClient
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		record.LatencyMs = time.Since(start).Milliseconds()
+		record.Error = err.Error()
+		if userOwned && errors.Is(err, httpclient.ErrNonPublicAddress) {
+			// 不向用户暴露域名解析到的内网地址
+			record.Error = httpclient.ErrNonPublicAddress.Error()
+		}
+		return record
+	}
+	defer resp.Body.Close()
+	record.LatencyMs = time.Since(start).Milliseconds()
+	record.StatusCode = resp.StatusCode
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponse))
+	if !userOwned {
+		record.Response = string(body)
+	}
+	record.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !record.Success {
+		record.Error = "unexpected status " + strconv.Itoa(resp.StatusCode)
+	}
+	return record
+}
+
+// userOwned 是否为用户提供的接收地址(用户webhook、事件过滤器的webhook_url)，只有配置文件中的webhook视为可信
+func (s *webhookService) userOwned(webhook config.Webhook) bool {
+	for _, endpoint := range s.config.Notifier.Webhooks.Endpoints {
+		if endpoint.Name == webhook.Name && endpoint.URL == webhook.URL {
+			return false
+		}
+	}
+	return true
+}
+
+// record 保存投递记录并加入索引
+func (s *webhookService) record(ctx context.Context, record *model.WebhookDelivery) {
+	if !record.Success {
+		s.logger.Warnf("Webhook %s delivery %s failed: %s", record.Webhook, record.ID, record.Error)
+	}
+	if s.redisClient == nil {
+		return
+	}
+	if err := s.save(ctx, record); err != nil {
+		s.logger.Warnf("Failed to save webhook delivery %s: %v", record.ID, err)
+		return
+	}
+
+	score := float64(record.CreatedAt.UnixMilli())
+	if err := s.redisClient.ZAdd(ctx, webhookDeliveryIndexKey, score, record.ID); err != nil {
+		s.logger.Warnf("Failed to index webhook delivery %s: %v", record.ID, err)
+	}
+	if !record.Success {
+		if err := s.redisClient.ZAdd(ctx, webhookFailureIndexKey, score, record.ID); err != nil {
+			s.logger.Warnf("Failed to index webhook failure %s: %v", record.ID, err)
+		}
+	}
+}
+
+// save 保存投递记录，过期时间从投递时间算起
+func (s *webhookService) save(ctx context.Context, record *model.WebhookDelivery) error {
+	ttl := s.retention() - time.Since(record.CreatedAt)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.redisClient.Set(ctx, webhookDeliveryKey(record.ID), string(data), ttl)
+}
+
+// load 批量读取投递记录，已过期的记录被跳过
+func (s *webhookService) load(ctx context.Context, ids []string) ([]*model.WebhookDelivery, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = s.redisClient.KeyPrefix() + webhookDeliveryKey(id)
+	}
+	values, err := s.redisClient.GetClient().MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook deliveries: %w", err)
+	}
+
+	records := make([]*model.WebhookDelivery, 0, len(values))
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var record model.WebhookDelivery
+		if err := json.Unmarshal([]byte(str), &record); err != nil {
+			s.logger.Warnf("Skipping malformed webhook delivery %s: %v", ids[i], err)
+			continue
+		}
+		records = append(records, &record)
+	}
+	return records, nil
+}
+
+// trim 从索引中移除超过保留时间的记录
+func (s *webhookService) trim(ctx context.Context) {
+	cutoff := "(" + strconv.FormatInt(time.Now().Add(-s.retention()).UnixMilli(), 10)
+	for _, key := range []string{webhookDeliveryIndexKey, webhookFailureIndexKey} {
+		if err := s.redisClient.ZRemRangeByScore(ctx, key, "-inf", cutoff); err != nil {
+			s.logger.Warnf("Failed to trim webhook delivery index: %v", err)
+		}
+	}
+}
+
+// retention 投递记录保留时间
+func (s *webhookService) retention() time.Duration {
+	if s.config.Notifier.Webhooks.LogRetention > 0 {
+		return s.config.Notifier.Webhooks.LogRetention
+	}
+	return defaultWebhookLogRetention
+}
+
+// webhookDeliveryKey 投递记录存储key
+func webhookDeliveryKey(id string) string {
+	return webhookDeliveryKeyPrefix + id
+}
+
+// webhookAccepts webhook是否订阅该类型的告警
+func webhookAccepts(webhook config.Webhook, alert *model.Alert) bool {
+	return acceptsAlertType(webhook.Types, alert)
+}
+
+// acceptsAlertType 告警类型是否在订阅列表中，列表为空时订阅全部，摘要包含任一订阅类型的告警即投递
+func acceptsAlertType(subscribed []string, alert *model.Alert) bool {
+	if len(subscribed) == 0 {
+		return true
+	}
+	types := []string{alert.Type}
+	if alert.Type == model.AlertTypeDigest {
+		types, _ = alert.Data["types"].([]string)
+	}
+	for _, t := range subscribed {
+		for _, alertType := range types {
+			if t == alertType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactURL 去掉地址中的账号密码和查询参数，避免在投递记录中泄露凭据
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
@@ -0,0 +1,87 @@
+// Code generated by Kitex v0.14.1. DO NOT EDIT.
+
+package healthv1
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cloudwego/kitex/pkg/streaming"
+	"github.com/cloudwego/prutal"
+)
+
+type HealthCheckResponse_ServingStatus int32
+
+const (
+	HealthCheckResponse_UNKNOWN         HealthCheckResponse_ServingStatus = 0
+	HealthCheckResponse_SERVING         HealthCheckResponse_ServingStatus = 1
+	HealthCheckResponse_NOT_SERVING     HealthCheckResponse_ServingStatus = 2
+	HealthCheckResponse_SERVICE_UNKNOWN HealthCheckResponse_ServingStatus = 3 // Used only by the Watch method.
+)
+
+// Enum value maps for HealthCheckResponse_ServingStatus.
+var HealthCheckResponse_ServingStatus_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "SERVING",
+	2: "NOT_SERVING",
+	3: "SERVICE_UNKNOWN",
+}
+
+var HealthCheckResponse_ServingStatus_value = map[string]int32{
+	"UNKNOWN":         0,
+	"SERVING":         1,
+	"NOT_SERVING":     2,
+	"SERVICE_UNKNOWN": 3,
+}
+
+func (x HealthCheckResponse_ServingStatus) String() string {
+	s, ok := HealthCheckResponse_ServingStatus_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+
+type HealthCheckRequest struct {
+	Service string `protobuf:"bytes,1,opt,name=service" json:"service,omitempty"`
+}
+
+func (x *HealthCheckRequest) Reset() { *x = HealthCheckRequest{} }
+
+func (x *HealthCheckRequest) Marshal(in []byte) ([]byte, error) { return prutal.MarshalAppend(in, x) }
+
+func (x *HealthCheckRequest) Unmarshal(in []byte) error { return prutal.Unmarshal(in, x) }
+
+func (x *HealthCheckRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+type HealthCheckResponse struct {
+	Status HealthCheckResponse_ServingStatus `protobuf:"varint,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (x *HealthCheckResponse) Reset() { *x = HealthCheckResponse{} }
+
+func (x *HealthCheckResponse) Marshal(in []byte) ([]byte, error) { return prutal.MarshalAppend(in, x) }
+
+func (x *HealthCheckResponse) Unmarshal(in []byte) error { return prutal.Unmarshal(in, x) }
+
+func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_ServingStatus {
+	if x != nil {
+		return x.Status
+	}
+	return HealthCheckResponse_UNKNOWN
+}
+
+type Health interface {
+	Check(ctx context.Context, req *HealthCheckRequest) (res *HealthCheckResponse, err error)
+	Watch(req *HealthCheckRequest, stream Health_WatchServer) (err error)
+}
+
+type Health_WatchServer interface {
+	streaming.Stream
+	Send(*HealthCheckResponse) error
+}
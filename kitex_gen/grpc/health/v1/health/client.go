@@ -0,0 +1,106 @@
+// Code generated by Kitex v0.14.1. DO NOT EDIT.
+
+package health
+
+import (
+	"context"
+	v1 "crypto-info/kitex_gen/grpc/health/v1"
+	client "github.com/cloudwego/kitex/client"
+	callopt "github.com/cloudwego/kitex/client/callopt"
+	streamcall "github.com/cloudwego/kitex/client/callopt/streamcall"
+	streamclient "github.com/cloudwego/kitex/client/streamclient"
+	streaming "github.com/cloudwego/kitex/pkg/streaming"
+	transport "github.com/cloudwego/kitex/transport"
+)
+
+// Client is designed to provide IDL-compatible methods with call-option parameter for kitex framework.
+type Client interface {
+	Check(ctx context.Context, Req *v1.HealthCheckRequest, callOptions ...callopt.Option) (r *v1.HealthCheckResponse, err error)
+	Watch(ctx context.Context, Req *v1.HealthCheckRequest, callOptions ...callopt.Option) (stream Health_WatchClient, err error)
+}
+
+// StreamClient is designed to provide Interface for Streaming APIs.
+type StreamClient interface {
+	Watch(ctx context.Context, Req *v1.HealthCheckRequest, callOptions ...streamcall.Option) (stream Health_WatchClient, err error)
+}
+
+type Health_WatchClient interface {
+	streaming.Stream
+	Recv() (*v1.HealthCheckResponse, error)
+}
+
+// NewClient creates a client for the service defined in IDL.
+func NewClient(destService string, opts ...client.Option) (Client, error) {
+	var options []client.Option
+	options = append(options, client.WithDestService(destService))
+
+	options = append(options, client.WithTransportProtocol(transport.GRPC))
+
+	options = append(options, opts...)
+
+	kc, err := client.NewClient(serviceInfo(), options...)
+	if err != nil {
+		return nil, err
+	}
+	return &kHealthClient{
+		kClient: newServiceClient(kc),
+	}, nil
+}
+
+// MustNewClient creates a client for the service defined in IDL. It panics if any error occurs.
+func MustNewClient(destService string, opts ...client.Option) Client {
+	kc, err := NewClient(destService, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return kc
+}
+
+type kHealthClient struct {
+	*kClient
+}
+
+func (p *kHealthClient) Check(ctx context.Context, Req *v1.HealthCheckRequest, callOptions ...callopt.Option) (r *v1.HealthCheckResponse, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.Check(ctx, Req)
+}
+
+func (p *kHealthClient) Watch(ctx context.Context, Req *v1.HealthCheckRequest, callOptions ...callopt.Option) (stream Health_WatchClient, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.Watch(ctx, Req)
+}
+
+// NewStreamClient creates a stream client for the service's streaming APIs defined in IDL.
+func NewStreamClient(destService string, opts ...streamclient.Option) (StreamClient, error) {
+	var options []client.Option
+	options = append(options, client.WithDestService(destService))
+	options = append(options, client.WithTransportProtocol(transport.GRPC))
+	options = append(options, streamclient.GetClientOptions(opts)...)
+
+	kc, err := client.NewClient(serviceInfoForStreamClient(), options...)
+	if err != nil {
+		return nil, err
+	}
+	return &kHealthStreamClient{
+		kClient: newServiceClient(kc),
+	}, nil
+}
+
+// MustNewStreamClient creates a stream client for the service's streaming APIs defined in IDL.
+// It panics if any error occurs.
+func MustNewStreamClient(destService string, opts ...streamclient.Option) StreamClient {
+	kc, err := NewStreamClient(destService, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return kc
+}
+
+type kHealthStreamClient struct {
+	*kClient
+}
+
+func (p *kHealthStreamClient) Watch(ctx context.Context, Req *v1.HealthCheckRequest, callOptions ...streamcall.Option) (stream Health_WatchClient, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, streamcall.GetCallOptions(callOptions))
+	return p.kClient.Watch(ctx, Req)
+}
@@ -0,0 +1,101 @@
+// Code generated by Kitex v0.14.1. DO NOT EDIT.
+
+package serverreflection
+
+import (
+	"context"
+	v1alpha "crypto-info/kitex_gen/grpc/reflection/v1alpha"
+	client "github.com/cloudwego/kitex/client"
+	callopt "github.com/cloudwego/kitex/client/callopt"
+	streamcall "github.com/cloudwego/kitex/client/callopt/streamcall"
+	streamclient "github.com/cloudwego/kitex/client/streamclient"
+	streaming "github.com/cloudwego/kitex/pkg/streaming"
+	transport "github.com/cloudwego/kitex/transport"
+)
+
+// Client is designed to provide IDL-compatible methods with call-option parameter for kitex framework.
+type Client interface {
+	ServerReflectionInfo(ctx context.Context, callOptions ...callopt.Option) (stream ServerReflection_ServerReflectionInfoClient, err error)
+}
+
+// StreamClient is designed to provide Interface for Streaming APIs.
+type StreamClient interface {
+	ServerReflectionInfo(ctx context.Context, callOptions ...streamcall.Option) (stream ServerReflection_ServerReflectionInfoClient, err error)
+}
+
+type ServerReflection_ServerReflectionInfoClient interface {
+	streaming.Stream
+	Send(*v1alpha.ServerReflectionRequest) error
+	Recv() (*v1alpha.ServerReflectionResponse, error)
+}
+
+// NewClient creates a client for the service defined in IDL.
+func NewClient(destService string, opts ...client.Option) (Client, error) {
+	var options []client.Option
+	options = append(options, client.WithDestService(destService))
+
+	options = append(options, client.WithTransportProtocol(transport.GRPC))
+
+	options = append(options, opts...)
+
+	kc, err := client.NewClient(serviceInfo(), options...)
+	if err != nil {
+		return nil, err
+	}
+	return &kServerReflectionClient{
+		kClient: newServiceClient(kc),
+	}, nil
+}
+
+// MustNewClient creates a client for the service defined in IDL. It panics if any error occurs.
+func MustNewClient(destService string, opts ...client.Option) Client {
+	kc, err := NewClient(destService, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return kc
+}
+
+type kServerReflectionClient struct {
+	*kClient
+}
+
+func (p *kServerReflectionClient) ServerReflectionInfo(ctx context.Context, callOptions ...callopt.Option) (stream ServerReflection_ServerReflectionInfoClient, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.ServerReflectionInfo(ctx)
+}
+
+// NewStreamClient creates a stream client for the service's streaming APIs defined in IDL.
+func NewStreamClient(destService string, opts ...streamclient.Option) (StreamClient, error) {
+	var options []client.Option
+	options = append(options, client.WithDestService(destService))
+	options = append(options, client.WithTransportProtocol(transport.GRPC))
+	options = append(options, streamclient.GetClientOptions(opts)...)
+
+	kc, err := client.NewClient(serviceInfoForStreamClient(), options...)
+	if err != nil {
+		return nil, err
+	}
+	return &kServerReflectionStreamClient{
+		kClient: newServiceClient(kc),
+	}, nil
+}
+
+// MustNewStreamClient creates a stream client for the service's streaming APIs defined in IDL.
+// It panics if any error occurs.
+func MustNewStreamClient(destService string, opts ...streamclient.Option) StreamClient {
+	kc, err := NewStreamClient(destService, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return kc
+}
+
+type kServerReflectionStreamClient struct {
+	*kClient
+}
+
+func (p *kServerReflectionStreamClient) ServerReflectionInfo(ctx context.Context, callOptions ...streamcall.Option) (stream ServerReflection_ServerReflectionInfoClient, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, streamcall.GetCallOptions(callOptions))
+	return p.kClient.ServerReflectionInfo(ctx)
+}
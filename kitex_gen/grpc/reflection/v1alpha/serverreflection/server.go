@@ -0,0 +1,24 @@
+// Code generated by Kitex v0.14.1. DO NOT EDIT.
+package serverreflection
+
+import (
+	reflectionv1alpha "crypto-info/kitex_gen/grpc/reflection/v1alpha"
+	server "github.com/cloudwego/kitex/server"
+)
+
+// NewServer creates a server.Server with the given handler and options.
+func NewServer(handler reflectionv1alpha.ServerReflection, opts ...server.Option) server.Server {
+	var options []server.Option
+
+	options = append(options, opts...)
+
+	svr := server.NewServer(options...)
+	if err := svr.RegisterService(serviceInfo(), handler); err != nil {
+		panic(err)
+	}
+	return svr
+}
+
+func RegisterService(svr server.Server, handler reflectionv1alpha.ServerReflection, opts ...server.RegisterOption) error {
+	return svr.RegisterService(serviceInfo(), handler, opts...)
+}
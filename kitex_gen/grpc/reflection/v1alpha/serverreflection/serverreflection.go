@@ -0,0 +1,243 @@
+// Code generated by Kitex v0.14.1. DO NOT EDIT.
+
+package serverreflection
+
+import (
+	"context"
+	reflectionv1alpha "crypto-info/kitex_gen/grpc/reflection/v1alpha"
+	v1alpha "crypto-info/kitex_gen/grpc/reflection/v1alpha"
+	"errors"
+	"fmt"
+	client "github.com/cloudwego/kitex/client"
+	kitex "github.com/cloudwego/kitex/pkg/serviceinfo"
+	streaming "github.com/cloudwego/kitex/pkg/streaming"
+	proto "github.com/cloudwego/prutal"
+)
+
+var errInvalidMessageType = errors.New("invalid message type for service method handler")
+
+var serviceMethods = map[string]kitex.MethodInfo{
+	"ServerReflectionInfo": kitex.NewMethodInfo(
+		serverReflectionInfoHandler,
+		newServerReflectionInfoArgs,
+		newServerReflectionInfoResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingBidirectional),
+	),
+}
+
+var (
+	serverReflectionServiceInfo                = NewServiceInfo()
+	serverReflectionServiceInfoForClient       = NewServiceInfoForClient()
+	serverReflectionServiceInfoForStreamClient = NewServiceInfoForStreamClient()
+)
+
+// for server
+func serviceInfo() *kitex.ServiceInfo {
+	return serverReflectionServiceInfo
+}
+
+// for stream client
+func serviceInfoForStreamClient() *kitex.ServiceInfo {
+	return serverReflectionServiceInfoForStreamClient
+}
+
+// for client
+func serviceInfoForClient() *kitex.ServiceInfo {
+	return serverReflectionServiceInfoForClient
+}
+
+// NewServiceInfo creates a new ServiceInfo containing all methods
+func NewServiceInfo() *kitex.ServiceInfo {
+	return newServiceInfo(true, true, true)
+}
+
+// NewServiceInfo creates a new ServiceInfo containing non-streaming methods
+func NewServiceInfoForClient() *kitex.ServiceInfo {
+	return newServiceInfo(false, false, true)
+}
+func NewServiceInfoForStreamClient() *kitex.ServiceInfo {
+	return newServiceInfo(true, true, false)
+}
+
+func newServiceInfo(hasStreaming bool, keepStreamingMethods bool, keepNonStreamingMethods bool) *kitex.ServiceInfo {
+	serviceName := "ServerReflection"
+	handlerType := (*reflectionv1alpha.ServerReflection)(nil)
+	methods := map[string]kitex.MethodInfo{}
+	for name, m := range serviceMethods {
+		if m.IsStreaming() && !keepStreamingMethods {
+			continue
+		}
+		if !m.IsStreaming() && !keepNonStreamingMethods {
+			continue
+		}
+		methods[name] = m
+	}
+	extra := map[string]interface{}{
+		"PackageName": "grpc.reflection.v1alpha",
+	}
+	if hasStreaming {
+		extra["streaming"] = hasStreaming
+	}
+	svcInfo := &kitex.ServiceInfo{
+		ServiceName:     serviceName,
+		HandlerType:     handlerType,
+		Methods:         methods,
+		PayloadCodec:    kitex.Protobuf,
+		KiteXGenVersion: "v0.14.1",
+		Extra:           extra,
+	}
+	return svcInfo
+}
+
+func serverReflectionInfoHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	streamingArgs, ok := arg.(*streaming.Args)
+	if !ok {
+		return errInvalidMessageType
+	}
+	st := streamingArgs.Stream
+	stream := &serverReflectionServerReflectionInfoServer{st}
+	return handler.(reflectionv1alpha.ServerReflection).ServerReflectionInfo(stream)
+}
+
+type serverReflectionServerReflectionInfoClient struct {
+	streaming.Stream
+}
+
+func (x *serverReflectionServerReflectionInfoClient) DoFinish(err error) {
+	if finisher, ok := x.Stream.(streaming.WithDoFinish); ok {
+		finisher.DoFinish(err)
+	} else {
+		panic(fmt.Sprintf("streaming.WithDoFinish is not implemented by %T", x.Stream))
+	}
+}
+func (x *serverReflectionServerReflectionInfoClient) Send(m *v1alpha.ServerReflectionRequest) error {
+	return x.Stream.SendMsg(m)
+}
+func (x *serverReflectionServerReflectionInfoClient) Recv() (*v1alpha.ServerReflectionResponse, error) {
+	m := new(v1alpha.ServerReflectionResponse)
+	return m, x.Stream.RecvMsg(m)
+}
+
+type serverReflectionServerReflectionInfoServer struct {
+	streaming.Stream
+}
+
+func (x *serverReflectionServerReflectionInfoServer) Send(m *v1alpha.ServerReflectionResponse) error {
+	return x.Stream.SendMsg(m)
+}
+
+func (x *serverReflectionServerReflectionInfoServer) Recv() (*v1alpha.ServerReflectionRequest, error) {
+	m := new(v1alpha.ServerReflectionRequest)
+	return m, x.Stream.RecvMsg(m)
+}
+
+func newServerReflectionInfoArgs() interface{} {
+	return &ServerReflectionInfoArgs{}
+}
+
+func newServerReflectionInfoResult() interface{} {
+	return &ServerReflectionInfoResult{}
+}
+
+type ServerReflectionInfoArgs struct {
+	Req *v1alpha.ServerReflectionRequest
+}
+
+func (p *ServerReflectionInfoArgs) Marshal(out []byte) ([]byte, error) {
+	if !p.IsSetReq() {
+		return out, nil
+	}
+	return proto.Marshal(p.Req)
+}
+
+func (p *ServerReflectionInfoArgs) Unmarshal(in []byte) error {
+	msg := new(v1alpha.ServerReflectionRequest)
+	if err := proto.Unmarshal(in, msg); err != nil {
+		return err
+	}
+	p.Req = msg
+	return nil
+}
+
+var ServerReflectionInfoArgs_Req_DEFAULT *v1alpha.ServerReflectionRequest
+
+func (p *ServerReflectionInfoArgs) GetReq() *v1alpha.ServerReflectionRequest {
+	if !p.IsSetReq() {
+		return ServerReflectionInfoArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+
+func (p *ServerReflectionInfoArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *ServerReflectionInfoArgs) GetFirstArgument() interface{} {
+	return p.Req
+}
+
+type ServerReflectionInfoResult struct {
+	Success *v1alpha.ServerReflectionResponse
+}
+
+var ServerReflectionInfoResult_Success_DEFAULT *v1alpha.ServerReflectionResponse
+
+func (p *ServerReflectionInfoResult) Marshal(out []byte) ([]byte, error) {
+	if !p.IsSetSuccess() {
+		return out, nil
+	}
+	return proto.Marshal(p.Success)
+}
+
+func (p *ServerReflectionInfoResult) Unmarshal(in []byte) error {
+	msg := new(v1alpha.ServerReflectionResponse)
+	if err := proto.Unmarshal(in, msg); err != nil {
+		return err
+	}
+	p.Success = msg
+	return nil
+}
+
+func (p *ServerReflectionInfoResult) GetSuccess() *v1alpha.ServerReflectionResponse {
+	if !p.IsSetSuccess() {
+		return ServerReflectionInfoResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+func (p *ServerReflectionInfoResult) SetSuccess(x interface{}) {
+	p.Success = x.(*v1alpha.ServerReflectionResponse)
+}
+
+func (p *ServerReflectionInfoResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *ServerReflectionInfoResult) GetResult() interface{} {
+	return p.Success
+}
+
+type kClient struct {
+	c client.Client
+}
+
+func newServiceClient(c client.Client) *kClient {
+	return &kClient{
+		c: c,
+	}
+}
+
+func (p *kClient) ServerReflectionInfo(ctx context.Context) (ServerReflection_ServerReflectionInfoClient, error) {
+	streamClient, ok := p.c.(client.Streaming)
+	if !ok {
+		return nil, fmt.Errorf("client not support streaming")
+	}
+	res := new(streaming.Result)
+	err := streamClient.Stream(ctx, "ServerReflectionInfo", nil, res)
+	if err != nil {
+		return nil, err
+	}
+	stream := &serverReflectionServerReflectionInfoClient{res.Stream}
+	return stream, nil
+}
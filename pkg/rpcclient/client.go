@@ -0,0 +1,144 @@
+package rpcclient
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"crypto-info/kitex_gen/crypto/v1/cryptopriceservice"
+	"crypto-info/kitex_gen/crypto/v1/cryptovolumeservice"
+
+	"github.com/cloudwego/kitex/client"
+	"github.com/cloudwego/kitex/client/callopt"
+	"github.com/cloudwego/kitex/pkg/circuitbreak"
+	"github.com/cloudwego/kitex/pkg/connpool"
+	"github.com/cloudwego/kitex/pkg/kerrors"
+	"github.com/cloudwego/kitex/pkg/loadbalance"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/codes"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/status"
+	"github.com/cloudwego/kitex/pkg/retry"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/transmeta"
+	"github.com/cloudwego/kitex/transport"
+)
+
+// NewPriceClient 创建价格服务客户端，opts追加在配置生成的选项之后
+func NewPriceClient(cfg Config, opts ...client.Option) (cryptopriceservice.Client, error) {
+	cfg = cfg.withDefaults()
+	options, err := Options(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cryptopriceservice.NewClient(cfg.DestService, append(options, opts...)...)
+}
+
+// NewVolumeClient 创建成交量服务客户端，opts追加在配置生成的选项之后
+func NewVolumeClient(cfg Config, opts ...client.Option) (cryptovolumeservice.Client, error) {
+	cfg = cfg.withDefaults()
+	options, err := Options(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cryptovolumeservice.NewClient(cfg.DestService, append(options, opts...)...)
+}
+
+// Options 将配置转换为Kitex客户端选项，可用于其他生成的客户端
+func Options(cfg Config) ([]client.Option, error) {
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	options := []client.Option{
+		client.WithHostPorts(cfg.Addresses...),
+		client.WithConnectTimeout(cfg.ConnectTimeout),
+		client.WithRPCTimeout(cfg.RPCTimeout),
+	}
+
+	// 连接池：gRPC在每个地址上复用固定数量的HTTP/2连接，TTHeader使用长连接池
+	if cfg.Transport == TransportGRPC {
+		options = append(options,
+			client.WithTransportProtocol(transport.GRPC),
+			client.WithGRPCConnPoolSize(cfg.Pool.Size),
+		)
+	} else {
+		options = append(options,
+			client.WithTransportProtocol(transport.TTHeader),
+			// 在请求头中携带调用超时，服务端据此设置处理截止时间；gRPC通过grpc-timeout携带
+			client.WithMetaHandler(transmeta.ClientTTHeaderHandler),
+			client.WithLongConnection(connpool.IdleConfig{
+				MinIdlePerAddress: cfg.Pool.MinIdlePerAddress,
+				MaxIdlePerAddress: cfg.Pool.MaxIdlePerAddress,
+				MaxIdleGlobal:     cfg.Pool.MaxIdleGlobal,
+				MaxIdleTimeout:    cfg.Pool.MaxIdleTimeout,
+			}),
+		)
+	}
+
+	switch cfg.LoadBalancer {
+	case LoadBalanceRoundRobin:
+		options = append(options, client.WithLoadBalancer(loadbalance.NewWeightedRoundRobinBalancer()))
+	default:
+		options = append(options, client.WithLoadBalancer(loadbalance.NewWeightedRandomBalancer()))
+	}
+
+	if cfg.Retry.Enabled {
+		policy := retry.NewFailurePolicyWithResultRetry(&retry.ShouldResultRetry{ErrorRetryWithCtx: isTransient})
+		policy.WithMaxRetryTimes(cfg.Retry.MaxTimes)
+		policy.WithMaxDurationMS(uint32(cfg.Retry.MaxDuration.Milliseconds()))
+		policy.WithRandomBackOff(int(cfg.Retry.MinBackoff.Milliseconds()), int(cfg.Retry.MaxBackoff.Milliseconds()))
+		policy.WithRetryBreaker(cfg.Retry.ErrorRate)
+		policy.WithDDLStop()
+		options = append(options, client.WithFailureRetry(policy))
+	}
+
+	if cfg.CircuitBreaker.Enabled {
+		key := cfg.DestService
+		suite := circuitbreak.NewCBSuite(func(rpcinfo.RPCInfo) string { return key })
+		suite.UpdateServiceCBConfig(key, circuitbreak.CBConfig{
+			Enable:    true,
+			ErrRate:   cfg.CircuitBreaker.ErrorRate,
+			MinSample: cfg.CircuitBreaker.MinSample,
+		})
+		options = append(options,
+			client.WithCircuitBreaker(suite),
+			client.WithCloseCallbacks(suite.Close),
+		)
+	}
+
+	return options, nil
+}
+
+// CallOptions 按ctx的剩余时间缩短本次调用的超时，使服务端在调用方放弃后及时停止处理
+// ctx未设置截止时间或剩余时间不少于rpc_timeout时返回空，用法：client.GetPrice(ctx, req, cfg.CallOptions(ctx)...)
+func (c Config) CallOptions(ctx context.Context) []callopt.Option {
+	ddl, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	remaining := time.Until(ddl)
+	if remaining >= c.withDefaults().RPCTimeout {
+		return nil
+	}
+	if remaining <= 0 {
+		remaining = time.Millisecond
+	}
+	return []callopt.Option{callopt.WithRPCTimeout(remaining)}
+}
+
+// isTransient 判断错误是否值得重试：连接失败、超时和服务端返回的临时状态码，熔断拒绝不重试
+func isTransient(_ context.Context, err error, _ rpcinfo.RPCInfo) bool {
+	if err == nil || errors.Is(err, kerrors.ErrCircuitBreak) {
+		return false
+	}
+	if errors.Is(err, kerrors.ErrGetConnection) || kerrors.IsTimeoutError(err) {
+		return true
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+			return true
+		}
+	}
+	return false
+}
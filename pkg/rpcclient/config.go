@@ -0,0 +1,169 @@
+// Package rpcclient 价格与成交量RPC的Kitex客户端，内置连接池、负载均衡、失败重试和熔断
+package rpcclient
+
+import (
+	"fmt"
+	"time"
+)
+
+// 传输协议
+const (
+	TransportGRPC     = "grpc"
+	TransportTTHeader = "ttheader"
+)
+
+// 负载均衡策略
+const (
+	LoadBalanceWeightedRandom = "weighted_random"
+	LoadBalanceRoundRobin     = "round_robin"
+)
+
+// Config 客户端配置
+type Config struct {
+	Addresses      []string             `mapstructure:"addresses"`       // 服务地址列表(host:port)
+	DestService    string               `mapstructure:"dest_service"`    // 目标服务名，也用作熔断键
+	Transport      string               `mapstructure:"transport"`       // 传输协议(grpc,ttheader)
+	LoadBalancer   string               `mapstructure:"load_balancer"`   // 负载均衡策略(weighted_random,round_robin)
+	ConnectTimeout time.Duration        `mapstructure:"connect_timeout"` // 建立连接超时
+	RPCTimeout     time.Duration        `mapstructure:"rpc_timeout"`     // 单次调用超时，不含重试
+	Pool           PoolConfig           `mapstructure:"pool"`
+	Retry          RetryConfig          `mapstructure:"retry"`
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+}
+
+// PoolConfig 连接池配置
+type PoolConfig struct {
+	Size              uint32        `mapstructure:"size"`                 // gRPC每个地址的连接数
+	MinIdlePerAddress int           `mapstructure:"min_idle_per_address"` // TTHeader每个地址保留的最少空闲连接
+	MaxIdlePerAddress int           `mapstructure:"max_idle_per_address"` // TTHeader每个地址的最多空闲连接
+	MaxIdleGlobal     int           `mapstructure:"max_idle_global"`      // TTHeader全局最多空闲连接
+	MaxIdleTimeout    time.Duration `mapstructure:"max_idle_timeout"`     // 空闲连接回收时间
+}
+
+// RetryConfig 失败重试配置，只重试连接失败、超时和服务不可用等临时错误
+type RetryConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	MaxTimes    int           `mapstructure:"max_times"`    // 最多重试次数，不含首次调用，上限5
+	MaxDuration time.Duration `mapstructure:"max_duration"` // 首次调用加重试的总时长上限，0表示不限制
+	MinBackoff  time.Duration `mapstructure:"min_backoff"`  // 随机退避下限
+	MaxBackoff  time.Duration `mapstructure:"max_backoff"`  // 随机退避上限
+	ErrorRate   float64       `mapstructure:"error_rate"`   // 重试熔断错误率(0-0.3]，超过后停止重试避免放大故障
+}
+
+// CircuitBreakerConfig 服务级熔断配置
+type CircuitBreakerConfig struct {
+	Enabled   bool    `mapstructure:"enabled"`
+	ErrorRate float64 `mapstructure:"error_rate"` // 触发熔断的错误率(0-1]
+	MinSample int64   `mapstructure:"min_sample"` // 统计窗口内的最少请求数
+}
+
+// DefaultConfig 默认配置，只需补充服务地址
+func DefaultConfig() Config {
+	return Config{
+		DestService:    "crypto-price-service",
+		Transport:      TransportGRPC,
+		LoadBalancer:   LoadBalanceWeightedRandom,
+		ConnectTimeout: 500 * time.Millisecond,
+		RPCTimeout:     3 * time.Second,
+		Pool: PoolConfig{
+			Size:              2,
+			MinIdlePerAddress: 2,
+			MaxIdlePerAddress: 10,
+			MaxIdleGlobal:     100,
+			MaxIdleTimeout:    time.Minute,
+		},
+		Retry: RetryConfig{
+			Enabled:     true,
+			MaxTimes:    2,
+			MaxDuration: 5 * time.Second,
+			MinBackoff:  50 * time.Millisecond,
+			MaxBackoff:  300 * time.Millisecond,
+			ErrorRate:   0.1,
+		},
+		CircuitBreaker: CircuitBreakerConfig{
+			Enabled:   true,
+			ErrorRate: 0.5,
+			MinSample: 200,
+		},
+	}
+}
+
+// withDefaults 未设置的字段使用默认值
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.DestService == "" {
+		c.DestService = d.DestService
+	}
+	if c.Transport == "" {
+		c.Transport = d.Transport
+	}
+	if c.LoadBalancer == "" {
+		c.LoadBalancer = d.LoadBalancer
+	}
+	if c.ConnectTimeout <= 0 {
+		c.ConnectTimeout = d.ConnectTimeout
+	}
+	if c.RPCTimeout <= 0 {
+		c.RPCTimeout = d.RPCTimeout
+	}
+	if c.Pool.Size == 0 {
+		c.Pool.Size = d.Pool.Size
+	}
+	if c.Pool.MaxIdlePerAddress <= 0 {
+		c.Pool.MaxIdlePerAddress = d.Pool.MaxIdlePerAddress
+	}
+	if c.Pool.MaxIdleGlobal <= 0 {
+		c.Pool.MaxIdleGlobal = d.Pool.MaxIdleGlobal
+	}
+	if c.Pool.MaxIdleTimeout <= 0 {
+		c.Pool.MaxIdleTimeout = d.Pool.MaxIdleTimeout
+	}
+	if c.Retry.MaxTimes <= 0 {
+		c.Retry.MaxTimes = d.Retry.MaxTimes
+	}
+	if c.Retry.MaxBackoff <= 0 {
+		c.Retry.MinBackoff, c.Retry.MaxBackoff = d.Retry.MinBackoff, d.Retry.MaxBackoff
+	}
+	if c.Retry.ErrorRate <= 0 {
+		c.Retry.ErrorRate = d.Retry.ErrorRate
+	}
+	if c.CircuitBreaker.ErrorRate <= 0 {
+		c.CircuitBreaker.ErrorRate = d.CircuitBreaker.ErrorRate
+	}
+	if c.CircuitBreaker.MinSample <= 0 {
+		c.CircuitBreaker.MinSample = d.CircuitBreaker.MinSample
+	}
+	return c
+}
+
+// validate 校验配置，避免Kitex在创建重试策略时panic
+func (c Config) validate() error {
+	if len(c.Addresses) == 0 {
+		return fmt.Errorf("rpcclient: at least one address is required")
+	}
+	switch c.Transport {
+	case TransportGRPC, TransportTTHeader:
+	default:
+		return fmt.Errorf("rpcclient: unsupported transport %q", c.Transport)
+	}
+	switch c.LoadBalancer {
+	case LoadBalanceWeightedRandom, LoadBalanceRoundRobin:
+	default:
+		return fmt.Errorf("rpcclient: unsupported load balancer %q", c.LoadBalancer)
+	}
+	if c.Retry.Enabled {
+		if c.Retry.MaxTimes > 5 {
+			return fmt.Errorf("rpcclient: retry max_times must not exceed 5, got %d", c.Retry.MaxTimes)
+		}
+		if c.Retry.MinBackoff < 0 || c.Retry.MaxBackoff <= c.Retry.MinBackoff {
+			return fmt.Errorf("rpcclient: retry max_backoff must be greater than min_backoff")
+		}
+		if c.Retry.ErrorRate > 0.3 {
+			return fmt.Errorf("rpcclient: retry error_rate must be in (0, 0.3], got %.2f", c.Retry.ErrorRate)
+		}
+	}
+	if c.CircuitBreaker.Enabled && c.CircuitBreaker.ErrorRate > 1 {
+		return fmt.Errorf("rpcclient: circuit breaker error_rate must be in (0, 1], got %.2f", c.CircuitBreaker.ErrorRate)
+	}
+	return nil
+}